@@ -20,26 +20,27 @@ type Cache = cache.Cache
 
 const (
 	BackendMemcached = "memcached"
+	BackendRedis     = "redis"
 )
 
 type BackendConfig struct {
 	Backend   string          `yaml:"backend"`
 	Memcached MemcachedConfig `yaml:"memcached"`
+	Redis     RedisConfig     `yaml:"redis"`
 }
 
 // Validate the config.
 func (cfg *BackendConfig) Validate() error {
-	if cfg.Backend != "" && cfg.Backend != BackendMemcached {
+	switch cfg.Backend {
+	case "":
+		return nil
+	case BackendMemcached:
+		return cfg.Memcached.Validate()
+	case BackendRedis:
+		return cfg.Redis.Validate()
+	default:
 		return fmt.Errorf("unsupported cache backend: %s", cfg.Backend)
 	}
-
-	if cfg.Backend == BackendMemcached {
-		if err := cfg.Memcached.Validate(); err != nil {
-			return err
-		}
-	}
-
-	return nil
 }
 
 func CreateClient(cacheName string, cfg BackendConfig, logger log.Logger, reg prometheus.Registerer) (cache.Cache, error) {
@@ -55,6 +56,13 @@ func CreateClient(cacheName string, cfg BackendConfig, logger log.Logger, reg pr
 		}
 		return cache.NewMemcachedCache(cacheName, logger, client, reg), nil
 
+	case BackendRedis:
+		client, err := NewRedisClient(logger, cacheName, cfg.Redis, reg)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to create redis client")
+		}
+		return NewRedisCache(cacheName, logger, client, reg), nil
+
 	default:
 		return nil, errors.Errorf("unsupported cache type for cache %s: %s", cacheName, cfg.Backend)
 	}
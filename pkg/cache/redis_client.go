@@ -0,0 +1,431 @@
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package cache
+
+import (
+	"context"
+	"net"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/go-redis/redis/v8"
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/thanos-io/thanos/pkg/cacheutil"
+	"github.com/thanos-io/thanos/pkg/extprom"
+	"github.com/thanos-io/thanos/pkg/gate"
+)
+
+const (
+	opSet      = "set"
+	opGetMulti = "getmulti"
+
+	reasonMaxItemSize     = "max-item-size"
+	reasonAsyncBufferFull = "async-buffer-full"
+	reasonTimeout         = "timeout"
+	reasonServerError     = "server-error"
+	reasonNetworkError    = "network-error"
+	reasonOther           = "other"
+)
+
+var (
+	errRedisAsyncBufferFull = errors.New("the async buffer is full")
+
+	_ cacheutil.RemoteCacheClient = (*RedisClient)(nil)
+)
+
+// redisBackend is the interface used to run operations against Redis. It allows to mock Redis in tests.
+type redisBackend interface {
+	// getMulti returns the values of the input keys. Missing keys are not included in the result.
+	getMulti(ctx context.Context, keys []string) (map[string][]byte, error)
+	set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+	close() error
+}
+
+// RedisClient is a Redis client implementing the same interface and exposing the same metrics
+// as the Memcached client. It supports Redis Server, Redis Cluster and Redis Sentinel.
+type RedisClient struct {
+	logger  log.Logger
+	config  RedisConfig
+	backend redisBackend
+
+	// Channel used to notify internal goroutines when they should quit.
+	stop chan struct{}
+
+	// Channel used to enqueue async operations.
+	asyncQueue chan func()
+
+	// Gate used to enforce the max number of concurrent GetMulti() operations.
+	getMultiGate gate.Gate
+
+	// Wait group used to wait all workers on stopping.
+	workers sync.WaitGroup
+
+	// Tracked metrics.
+	clientInfo prometheus.GaugeFunc
+	operations *prometheus.CounterVec
+	failures   *prometheus.CounterVec
+	skipped    *prometheus.CounterVec
+	duration   *prometheus.HistogramVec
+	dataSize   *prometheus.HistogramVec
+}
+
+// NewRedisClient makes a new RedisClient.
+func NewRedisClient(logger log.Logger, name string, config RedisConfig, reg prometheus.Registerer) (*RedisClient, error) {
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+
+	opts := &redis.UniversalOptions{
+		Addrs:        config.GetEndpoints(),
+		Username:     config.Username,
+		Password:     config.Password.String(),
+		DB:           config.DB,
+		MasterName:   config.MasterName,
+		DialTimeout:  config.DialTimeout,
+		ReadTimeout:  config.ReadTimeout,
+		WriteTimeout: config.WriteTimeout,
+		PoolSize:     config.ConnectionPoolSize,
+		PoolTimeout:  config.ConnectionPoolTimeout,
+		MinIdleConns: config.MinIdleConnections,
+		IdleTimeout:  config.IdleTimeout,
+		MaxConnAge:   config.MaxConnectionAge,
+	}
+
+	if config.TLSEnabled {
+		tlsConfig, err := config.TLS.GetTLSConfig()
+		if err != nil {
+			return nil, errors.Wrap(err, "redis TLS config")
+		}
+		opts.TLSConfig = tlsConfig
+	}
+
+	if reg != nil {
+		reg = prometheus.WrapRegistererWith(prometheus.Labels{"name": name}, reg)
+	}
+
+	return newRedisClient(log.With(logger, "name", name), &universalRedisBackend{client: redis.NewUniversalClient(opts)}, config, reg), nil
+}
+
+func newRedisClient(logger log.Logger, backend redisBackend, config RedisConfig, reg prometheus.Registerer) *RedisClient {
+	c := &RedisClient{
+		logger:     logger,
+		config:     config,
+		backend:    backend,
+		asyncQueue: make(chan func(), config.MaxAsyncBufferSize),
+		stop:       make(chan struct{}, 1),
+		getMultiGate: gate.New(
+			extprom.WrapRegistererWithPrefix("thanos_redis_getmulti_", reg),
+			config.MaxGetMultiConcurrency,
+		),
+	}
+
+	c.clientInfo = promauto.With(reg).NewGaugeFunc(prometheus.GaugeOpts{
+		Name: "thanos_redis_client_info",
+		Help: "A metric with a constant '1' value labeled by configuration options from which redis client was configured.",
+		ConstLabels: prometheus.Labels{
+			"read_timeout":              config.ReadTimeout.String(),
+			"write_timeout":             config.WriteTimeout.String(),
+			"connection_pool_size":      strconv.Itoa(config.ConnectionPoolSize),
+			"max_async_concurrency":     strconv.Itoa(config.MaxAsyncConcurrency),
+			"max_async_buffer_size":     strconv.Itoa(config.MaxAsyncBufferSize),
+			"max_item_size":             strconv.Itoa(config.MaxItemSize),
+			"max_get_multi_concurrency": strconv.Itoa(config.MaxGetMultiConcurrency),
+			"max_get_multi_batch_size":  strconv.Itoa(config.MaxGetMultiBatchSize),
+		},
+	},
+		func() float64 { return 1 },
+	)
+
+	c.operations = promauto.With(reg).NewCounterVec(prometheus.CounterOpts{
+		Name: "thanos_redis_operations_total",
+		Help: "Total number of operations against redis.",
+	}, []string{"operation"})
+	c.operations.WithLabelValues(opGetMulti)
+	c.operations.WithLabelValues(opSet)
+
+	c.failures = promauto.With(reg).NewCounterVec(prometheus.CounterOpts{
+		Name: "thanos_redis_operation_failures_total",
+		Help: "Total number of operations against redis that failed.",
+	}, []string{"operation", "reason"})
+	for _, op := range []string{opGetMulti, opSet} {
+		for _, reason := range []string{reasonTimeout, reasonServerError, reasonNetworkError, reasonOther} {
+			c.failures.WithLabelValues(op, reason)
+		}
+	}
+
+	c.skipped = promauto.With(reg).NewCounterVec(prometheus.CounterOpts{
+		Name: "thanos_redis_operation_skipped_total",
+		Help: "Total number of operations against redis that have been skipped.",
+	}, []string{"operation", "reason"})
+	c.skipped.WithLabelValues(opGetMulti, reasonMaxItemSize)
+	c.skipped.WithLabelValues(opSet, reasonMaxItemSize)
+	c.skipped.WithLabelValues(opSet, reasonAsyncBufferFull)
+
+	c.duration = promauto.With(reg).NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "thanos_redis_operation_duration_seconds",
+		Help:    "Duration of operations against redis.",
+		Buckets: []float64{0.001, 0.005, 0.01, 0.025, 0.05, 0.1, 0.2, 0.5, 1, 3, 6, 10},
+	}, []string{"operation"})
+	c.duration.WithLabelValues(opGetMulti)
+	c.duration.WithLabelValues(opSet)
+
+	c.dataSize = promauto.With(reg).NewHistogramVec(prometheus.HistogramOpts{
+		Name: "thanos_redis_operation_data_size_bytes",
+		Help: "Tracks the size of the data stored in and fetched from redis.",
+		Buckets: []float64{
+			32, 256, 512, 1024, 32 * 1024, 256 * 1024, 512 * 1024, 1024 * 1024, 32 * 1024 * 1024, 256 * 1024 * 1024, 512 * 1024 * 1024,
+		},
+	},
+		[]string{"operation"},
+	)
+	c.dataSize.WithLabelValues(opGetMulti)
+	c.dataSize.WithLabelValues(opSet)
+
+	// Start a number of goroutines - processing async operations - equal
+	// to the max concurrency we have.
+	c.workers.Add(c.config.MaxAsyncConcurrency)
+	for i := 0; i < c.config.MaxAsyncConcurrency; i++ {
+		go c.asyncQueueProcessLoop()
+	}
+
+	return c
+}
+
+// Stop implements cacheutil.RemoteCacheClient.
+func (c *RedisClient) Stop() {
+	close(c.stop)
+
+	// Wait until all workers have terminated.
+	c.workers.Wait()
+
+	if err := c.backend.close(); err != nil {
+		level.Warn(c.logger).Log("msg", "failed to close redis client", "err", err)
+	}
+}
+
+// SetAsync implements cacheutil.RemoteCacheClient.
+func (c *RedisClient) SetAsync(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	// Skip hitting redis at all if the item is bigger than the max allowed size.
+	if c.config.MaxItemSize > 0 && len(value) > c.config.MaxItemSize {
+		c.skipped.WithLabelValues(opSet, reasonMaxItemSize).Inc()
+		return nil
+	}
+
+	err := c.enqueueAsync(func() {
+		start := time.Now()
+		c.operations.WithLabelValues(opSet).Inc()
+
+		// The caller context may be canceled by the time the operation runs.
+		if err := c.backend.set(context.Background(), key, value, ttl); err != nil {
+			level.Debug(c.logger).Log("msg", "failed to store item to redis", "key", key, "sizeBytes", len(value), "err", err)
+			c.trackError(opSet, err)
+			return
+		}
+
+		c.dataSize.WithLabelValues(opSet).Observe(float64(len(value)))
+		c.duration.WithLabelValues(opSet).Observe(time.Since(start).Seconds())
+	})
+
+	if errors.Is(err, errRedisAsyncBufferFull) {
+		c.skipped.WithLabelValues(opSet, reasonAsyncBufferFull).Inc()
+		level.Debug(c.logger).Log("msg", "failed to store item to redis because the async buffer is full", "err", err, "size", len(c.asyncQueue))
+		return nil
+	}
+	return err
+}
+
+// GetMulti implements cacheutil.RemoteCacheClient.
+func (c *RedisClient) GetMulti(ctx context.Context, keys []string) map[string][]byte {
+	if len(keys) == 0 {
+		return nil
+	}
+
+	batchSize := c.config.MaxGetMultiBatchSize
+	if batchSize <= 0 {
+		batchSize = len(keys)
+	}
+
+	var (
+		wg      sync.WaitGroup
+		mtx     sync.Mutex
+		hits    = make(map[string][]byte, len(keys))
+		lastErr error
+		failed  int
+		batches int
+	)
+
+	for start := 0; start < len(keys); start += batchSize {
+		end := start + batchSize
+		if end > len(keys) {
+			end = len(keys)
+		}
+		batches++
+
+		if c.config.MaxGetMultiConcurrency > 0 {
+			if err := c.getMultiGate.Start(ctx); err != nil {
+				mtx.Lock()
+				lastErr = errors.Wrap(err, "failed to wait for turn")
+				failed++
+				mtx.Unlock()
+				continue
+			}
+		}
+
+		wg.Add(1)
+		go func(batch []string) {
+			defer wg.Done()
+			if c.config.MaxGetMultiConcurrency > 0 {
+				defer c.getMultiGate.Done()
+			}
+
+			items, err := c.getMultiSingle(ctx, batch)
+
+			mtx.Lock()
+			defer mtx.Unlock()
+			if err != nil {
+				lastErr = err
+				failed++
+				return
+			}
+			for k, v := range items {
+				hits[k] = v
+			}
+		}(keys[start:end])
+	}
+
+	wg.Wait()
+
+	if lastErr != nil {
+		level.Warn(c.logger).Log("msg", "failed to fetch items from redis", "numKeys", len(keys), "firstKey", keys[0], "err", lastErr)
+
+		// In case some batch requests failed and other succeeded, we prefer to return
+		// the results we have, given returning some results from the cache is better
+		// than returning nothing.
+		if failed == batches {
+			return nil
+		}
+	}
+
+	return hits
+}
+
+func (c *RedisClient) getMultiSingle(ctx context.Context, keys []string) (map[string][]byte, error) {
+	start := time.Now()
+	c.operations.WithLabelValues(opGetMulti).Inc()
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	items, err := c.backend.getMulti(ctx, keys)
+	if err != nil {
+		level.Debug(c.logger).Log("msg", "failed to get multiple items from redis", "err", err)
+		c.trackError(opGetMulti, err)
+		return nil, err
+	}
+
+	var total int
+	for key, value := range items {
+		// Items bigger than the max allowed size may have been stored by another client, and
+		// are not returned to be consistent with the memcached client.
+		if c.config.MaxItemSize > 0 && len(value) > c.config.MaxItemSize {
+			c.skipped.WithLabelValues(opGetMulti, reasonMaxItemSize).Inc()
+			delete(items, key)
+			continue
+		}
+		total += len(value)
+	}
+	c.dataSize.WithLabelValues(opGetMulti).Observe(float64(total))
+	c.duration.WithLabelValues(opGetMulti).Observe(time.Since(start).Seconds())
+
+	return items, nil
+}
+
+func (c *RedisClient) trackError(op string, err error) {
+	var netErr net.Error
+	var redisErr redis.Error
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		c.failures.WithLabelValues(op, reasonTimeout).Inc()
+	case errors.As(err, &netErr):
+		if netErr.Timeout() {
+			c.failures.WithLabelValues(op, reasonTimeout).Inc()
+		} else {
+			c.failures.WithLabelValues(op, reasonNetworkError).Inc()
+		}
+	case errors.As(err, &redisErr):
+		c.failures.WithLabelValues(op, reasonServerError).Inc()
+	default:
+		c.failures.WithLabelValues(op, reasonOther).Inc()
+	}
+}
+
+func (c *RedisClient) enqueueAsync(op func()) error {
+	select {
+	case c.asyncQueue <- op:
+		return nil
+	default:
+		return errRedisAsyncBufferFull
+	}
+}
+
+func (c *RedisClient) asyncQueueProcessLoop() {
+	defer c.workers.Done()
+
+	for {
+		select {
+		case op := <-c.asyncQueue:
+			op()
+		case <-c.stop:
+			return
+		}
+	}
+}
+
+// universalRedisBackend runs operations against Redis Server, Redis Cluster or Redis Sentinel,
+// depending on the configuration.
+type universalRedisBackend struct {
+	client redis.UniversalClient
+}
+
+func (b *universalRedisBackend) getMulti(ctx context.Context, keys []string) (map[string][]byte, error) {
+	// We pipeline GET commands instead of running a MGET, because the keys may be
+	// sharded to different nodes when running Redis Cluster.
+	cmds := make([]*redis.StringCmd, 0, len(keys))
+	_, err := b.client.Pipelined(ctx, func(p redis.Pipeliner) error {
+		for _, key := range keys {
+			cmds = append(cmds, p.Get(ctx, key))
+		}
+		return nil
+	})
+	if err != nil && !errors.Is(err, redis.Nil) {
+		return nil, err
+	}
+
+	items := make(map[string][]byte, len(keys))
+	for i, cmd := range cmds {
+		value, err := cmd.Bytes()
+		if errors.Is(err, redis.Nil) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		items[keys[i]] = value
+	}
+
+	return items, nil
+}
+
+func (b *universalRedisBackend) set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	return b.client.Set(ctx, key, value, ttl).Err()
+}
+
+func (b *universalRedisBackend) close() error {
+	return b.client.Close()
+}
@@ -0,0 +1,251 @@
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package cache
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/atomic"
+)
+
+func TestRedisConfig_Validate(t *testing.T) {
+	tests := map[string]struct {
+		cfg      RedisConfig
+		expected error
+	}{
+		"no endpoint": {
+			cfg:      RedisConfig{MaxAsyncConcurrency: 1},
+			expected: ErrNoRedisEndpoints,
+		},
+		"no async concurrency": {
+			cfg:      RedisConfig{Endpoint: "localhost:6379"},
+			expected: ErrRedisMaxAsyncConcurrencyNotPositive,
+		},
+		"valid": {
+			cfg: RedisConfig{Endpoint: "redis-1:6379,redis-2:6379", MaxAsyncConcurrency: 1},
+		},
+	}
+
+	for testName, testData := range tests {
+		t.Run(testName, func(t *testing.T) {
+			assert.Equal(t, testData.expected, testData.cfg.Validate())
+		})
+	}
+
+	assert.Equal(t, []string{"redis-1:6379", "redis-2:6379"}, (&RedisConfig{Endpoint: "redis-1:6379,redis-2:6379"}).GetEndpoints())
+}
+
+func TestRedisClient_GetMulti(t *testing.T) {
+	tests := map[string]struct {
+		maxBatchSize       int
+		maxConcurrency     int
+		maxItemSize        int
+		data               map[string][]byte
+		getMultiErrOnBatch int
+		keys               []string
+		expectedHits       map[string][]byte
+		expectedGetMulti   int
+	}{
+		"should return no hits on empty cache": {
+			keys:             []string{"a", "b"},
+			expectedHits:     map[string][]byte{},
+			expectedGetMulti: 1,
+		},
+		"should return hits and skip missing keys": {
+			data:             map[string][]byte{"a": []byte("1"), "c": []byte("3")},
+			keys:             []string{"a", "b", "c"},
+			expectedHits:     map[string][]byte{"a": []byte("1"), "c": []byte("3")},
+			expectedGetMulti: 1,
+		},
+		"should split keys in batches": {
+			maxBatchSize:     2,
+			maxConcurrency:   1,
+			data:             map[string][]byte{"a": []byte("1"), "b": []byte("2"), "c": []byte("3"), "d": []byte("4"), "e": []byte("5")},
+			keys:             []string{"a", "b", "c", "d", "e"},
+			expectedHits:     map[string][]byte{"a": []byte("1"), "b": []byte("2"), "c": []byte("3"), "d": []byte("4"), "e": []byte("5")},
+			expectedGetMulti: 3,
+		},
+		"should return partial results if a batch fails": {
+			maxBatchSize:       2,
+			maxConcurrency:     1,
+			data:               map[string][]byte{"a": []byte("1"), "b": []byte("2"), "c": []byte("3")},
+			getMultiErrOnBatch: 2,
+			keys:               []string{"a", "b", "c"},
+			expectedHits:       map[string][]byte{"a": []byte("1"), "b": []byte("2")},
+			expectedGetMulti:   2,
+		},
+		"should return no hits if all batches fail": {
+			data:               map[string][]byte{"a": []byte("1")},
+			getMultiErrOnBatch: 1,
+			keys:               []string{"a"},
+			expectedHits:       nil,
+			expectedGetMulti:   1,
+		},
+		"should skip items bigger than the max item size": {
+			maxItemSize:      1,
+			data:             map[string][]byte{"a": []byte("1"), "b": []byte("22")},
+			keys:             []string{"a", "b"},
+			expectedHits:     map[string][]byte{"a": []byte("1")},
+			expectedGetMulti: 1,
+		},
+	}
+
+	for testName, testData := range tests {
+		t.Run(testName, func(t *testing.T) {
+			backend := newMockRedisBackend()
+			backend.getMultiErrOnCall = testData.getMultiErrOnBatch
+			for k, v := range testData.data {
+				backend.data[k] = v
+			}
+
+			client := newRedisClient(log.NewNopLogger(), backend, RedisConfig{
+				MaxAsyncConcurrency:    1,
+				MaxAsyncBufferSize:     10,
+				MaxItemSize:            testData.maxItemSize,
+				MaxGetMultiBatchSize:   testData.maxBatchSize,
+				MaxGetMultiConcurrency: testData.maxConcurrency,
+			}, prometheus.NewPedanticRegistry())
+			t.Cleanup(client.Stop)
+
+			assert.Equal(t, testData.expectedHits, client.GetMulti(context.Background(), testData.keys))
+			assert.Equal(t, float64(testData.expectedGetMulti), testutil.ToFloat64(client.operations.WithLabelValues(opGetMulti)))
+		})
+	}
+}
+
+func TestRedisClient_SetAsync(t *testing.T) {
+	backend := newMockRedisBackend()
+	reg := prometheus.NewPedanticRegistry()
+	client := newRedisClient(log.NewNopLogger(), backend, RedisConfig{
+		MaxAsyncConcurrency: 1,
+		MaxAsyncBufferSize:  10,
+		MaxItemSize:         2,
+	}, reg)
+	t.Cleanup(client.Stop)
+
+	ctx := context.Background()
+	require.NoError(t, client.SetAsync(ctx, "a", []byte("1"), time.Minute))
+	require.NoError(t, client.SetAsync(ctx, "b", []byte("too big"), time.Minute))
+	require.Eventually(t, func() bool {
+		return len(client.GetMulti(ctx, []string{"a"})) == 1
+	}, time.Second, 10*time.Millisecond)
+
+	backend.setError(errors.New("server error"))
+	require.NoError(t, client.SetAsync(ctx, "c", []byte("3"), time.Minute))
+	require.Eventually(t, func() bool {
+		return testutil.ToFloat64(client.failures.WithLabelValues(opSet, reasonOther)) == 1
+	}, time.Second, 10*time.Millisecond)
+
+	assert.Equal(t, map[string][]byte{"a": []byte("1")}, client.GetMulti(ctx, []string{"a", "b", "c"}))
+
+	assert.NoError(t, testutil.GatherAndCompare(reg, strings.NewReader(`
+		# HELP thanos_redis_operation_skipped_total Total number of operations against redis that have been skipped.
+		# TYPE thanos_redis_operation_skipped_total counter
+		thanos_redis_operation_skipped_total{operation="getmulti",reason="max-item-size"} 0
+		thanos_redis_operation_skipped_total{operation="set",reason="async-buffer-full"} 0
+		thanos_redis_operation_skipped_total{operation="set",reason="max-item-size"} 1
+		# HELP thanos_redis_operation_failures_total Total number of operations against redis that failed.
+		# TYPE thanos_redis_operation_failures_total counter
+		thanos_redis_operation_failures_total{operation="getmulti",reason="network-error"} 0
+		thanos_redis_operation_failures_total{operation="getmulti",reason="other"} 0
+		thanos_redis_operation_failures_total{operation="getmulti",reason="server-error"} 0
+		thanos_redis_operation_failures_total{operation="getmulti",reason="timeout"} 0
+		thanos_redis_operation_failures_total{operation="set",reason="network-error"} 0
+		thanos_redis_operation_failures_total{operation="set",reason="other"} 1
+		thanos_redis_operation_failures_total{operation="set",reason="server-error"} 0
+		thanos_redis_operation_failures_total{operation="set",reason="timeout"} 0
+	`), "thanos_redis_operation_skipped_total", "thanos_redis_operation_failures_total"))
+}
+
+func TestRedisClient_SetAsyncBufferFull(t *testing.T) {
+	backend := newMockRedisBackend()
+	backend.setBlock = make(chan struct{})
+
+	client := newRedisClient(log.NewNopLogger(), backend, RedisConfig{
+		MaxAsyncConcurrency: 1,
+		MaxAsyncBufferSize:  1,
+	}, nil)
+	t.Cleanup(func() {
+		close(backend.setBlock)
+		client.Stop()
+	})
+
+	// The first item is picked up by the worker, which blocks. The second one fills
+	// the buffer, so subsequent ones are skipped.
+	ctx := context.Background()
+	require.NoError(t, client.SetAsync(ctx, "a", []byte("1"), time.Minute))
+	require.Eventually(t, func() bool { return backend.setCalls.Load() == 1 }, time.Second, 10*time.Millisecond)
+	require.NoError(t, client.SetAsync(ctx, "b", []byte("2"), time.Minute))
+	require.NoError(t, client.SetAsync(ctx, "c", []byte("3"), time.Minute))
+
+	assert.Equal(t, float64(1), testutil.ToFloat64(client.skipped.WithLabelValues(opSet, reasonAsyncBufferFull)))
+}
+
+type mockRedisBackend struct {
+	mtx               sync.Mutex
+	data              map[string][]byte
+	getMultiCalls     int
+	getMultiErrOnCall int
+	setErr            error
+	setBlock          chan struct{}
+	setCalls          atomic.Int64
+}
+
+func newMockRedisBackend() *mockRedisBackend {
+	return &mockRedisBackend{data: map[string][]byte{}}
+}
+
+func (m *mockRedisBackend) setError(err error) {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+
+	m.setErr = err
+}
+
+func (m *mockRedisBackend) getMulti(_ context.Context, keys []string) (map[string][]byte, error) {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+
+	m.getMultiCalls++
+	if m.getMultiCalls == m.getMultiErrOnCall {
+		return nil, errors.New("mocked error")
+	}
+
+	items := map[string][]byte{}
+	for _, key := range keys {
+		if value, ok := m.data[key]; ok {
+			items[key] = value
+		}
+	}
+	return items, nil
+}
+
+func (m *mockRedisBackend) set(_ context.Context, key string, value []byte, _ time.Duration) error {
+	m.setCalls.Inc()
+	if m.setBlock != nil {
+		<-m.setBlock
+	}
+
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+
+	if m.setErr != nil {
+		return m.setErr
+	}
+	m.data[key] = value
+	return nil
+}
+
+func (m *mockRedisBackend) close() error {
+	return nil
+}
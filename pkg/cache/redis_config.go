@@ -0,0 +1,85 @@
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package cache
+
+import (
+	"errors"
+	"flag"
+	"strings"
+	"time"
+
+	"github.com/grafana/dskit/crypto/tls"
+	"github.com/grafana/dskit/flagext"
+)
+
+var (
+	ErrNoRedisEndpoints                    = errors.New("no redis endpoints configured")
+	ErrRedisMaxAsyncConcurrencyNotPositive = errors.New("redis max async concurrency must be positive")
+)
+
+type RedisConfig struct {
+	Endpoint               string           `yaml:"endpoint"`
+	Username               string           `yaml:"username"`
+	Password               flagext.Secret   `yaml:"password"`
+	DB                     int              `yaml:"db"`
+	MasterName             string           `yaml:"master_name" category:"advanced"`
+	DialTimeout            time.Duration    `yaml:"dial_timeout" category:"advanced"`
+	ReadTimeout            time.Duration    `yaml:"read_timeout" category:"advanced"`
+	WriteTimeout           time.Duration    `yaml:"write_timeout" category:"advanced"`
+	ConnectionPoolSize     int              `yaml:"connection_pool_size" category:"advanced"`
+	ConnectionPoolTimeout  time.Duration    `yaml:"connection_pool_timeout" category:"advanced"`
+	MinIdleConnections     int              `yaml:"min_idle_connections" category:"advanced"`
+	IdleTimeout            time.Duration    `yaml:"idle_timeout" category:"advanced"`
+	MaxConnectionAge       time.Duration    `yaml:"max_connection_age" category:"advanced"`
+	MaxItemSize            int              `yaml:"max_item_size" category:"advanced"`
+	MaxAsyncConcurrency    int              `yaml:"max_async_concurrency" category:"advanced"`
+	MaxAsyncBufferSize     int              `yaml:"max_async_buffer_size" category:"advanced"`
+	MaxGetMultiConcurrency int              `yaml:"max_get_multi_concurrency" category:"advanced"`
+	MaxGetMultiBatchSize   int              `yaml:"max_get_multi_batch_size" category:"advanced"`
+	TLSEnabled             bool             `yaml:"tls_enabled" category:"advanced"`
+	TLS                    tls.ClientConfig `yaml:",inline"`
+}
+
+func (cfg *RedisConfig) RegisterFlagsWithPrefix(f *flag.FlagSet, prefix string) {
+	f.StringVar(&cfg.Endpoint, prefix+"endpoint", "", "Redis Server or Cluster configuration endpoint to use for caching. A comma-separated list of endpoints for Redis Cluster or Redis Sentinel.")
+	f.StringVar(&cfg.Username, prefix+"username", "", "Username to use when connecting to Redis.")
+	f.Var(&cfg.Password, prefix+"password", "Password to use when connecting to Redis.")
+	f.IntVar(&cfg.DB, prefix+"db", 0, "Database index.")
+	f.StringVar(&cfg.MasterName, prefix+"master-name", "", "Redis Sentinel master name. An empty value for Redis Server or Redis Cluster.")
+	f.DurationVar(&cfg.DialTimeout, prefix+"dial-timeout", 5*time.Second, "Client dial timeout.")
+	f.DurationVar(&cfg.ReadTimeout, prefix+"read-timeout", 3*time.Second, "Client read timeout.")
+	f.DurationVar(&cfg.WriteTimeout, prefix+"write-timeout", 3*time.Second, "Client write timeout.")
+	f.IntVar(&cfg.ConnectionPoolSize, prefix+"connection-pool-size", 100, "Maximum number of connections in the pool.")
+	f.DurationVar(&cfg.ConnectionPoolTimeout, prefix+"connection-pool-timeout", 4*time.Second, "Amount of time the client waits for a connection if all connections are busy before returning an error.")
+	f.IntVar(&cfg.MinIdleConnections, prefix+"min-idle-connections", 10, "Minimum number of idle connections.")
+	f.DurationVar(&cfg.IdleTimeout, prefix+"idle-timeout", 5*time.Minute, "Amount of time after which client closes idle connections.")
+	f.DurationVar(&cfg.MaxConnectionAge, prefix+"max-connection-age", 0, "Connection age at which client retires (closes) the connection. 0 to not close aged connections.")
+	f.IntVar(&cfg.MaxItemSize, prefix+"max-item-size", 16*1024*1024, "The maximum size of an item stored in Redis. Bigger items are not stored. If set to 0, no maximum size is enforced.")
+	f.IntVar(&cfg.MaxAsyncConcurrency, prefix+"max-async-concurrency", 50, "The maximum number of concurrent asynchronous operations can occur.")
+	f.IntVar(&cfg.MaxAsyncBufferSize, prefix+"max-async-buffer-size", 25000, "The maximum number of enqueued asynchronous operations allowed.")
+	f.IntVar(&cfg.MaxGetMultiConcurrency, prefix+"max-get-multi-concurrency", 100, "The maximum number of concurrent connections running get operations. If set to 0, concurrency is unlimited.")
+	f.IntVar(&cfg.MaxGetMultiBatchSize, prefix+"max-get-multi-batch-size", 100, "The maximum number of keys a single underlying get operation should run. If more keys are specified, internally keys are split into multiple batches and fetched concurrently, honoring the max concurrency. If set to 0, the max batch size is unlimited.")
+	f.BoolVar(&cfg.TLSEnabled, prefix+"tls-enabled", false, "Enable connecting to Redis with TLS.")
+	cfg.TLS.RegisterFlagsWithPrefix(strings.TrimSuffix(prefix, "."), f)
+}
+
+func (cfg *RedisConfig) GetEndpoints() []string {
+	if cfg.Endpoint == "" {
+		return []string{}
+	}
+
+	return strings.Split(cfg.Endpoint, ",")
+}
+
+// Validate the config.
+func (cfg *RedisConfig) Validate() error {
+	if len(cfg.GetEndpoints()) == 0 {
+		return ErrNoRedisEndpoints
+	}
+
+	if cfg.MaxAsyncConcurrency <= 0 {
+		return ErrRedisMaxAsyncConcurrencyNotPositive
+	}
+
+	return nil
+}
@@ -26,6 +26,7 @@ import (
 	"github.com/grafana/mimir/pkg/storage/bucket"
 	mimir_tsdb "github.com/grafana/mimir/pkg/storage/tsdb"
 	"github.com/grafana/mimir/pkg/storage/tsdb/bucketindex"
+	"github.com/grafana/mimir/pkg/storage/tsdb/metricmetadata"
 	"github.com/grafana/mimir/pkg/util"
 	util_log "github.com/grafana/mimir/pkg/util/log"
 )
@@ -40,6 +41,9 @@ type BlocksCleanerConfig struct {
 	CleanupConcurrency      int
 	TenantCleanupDelay      time.Duration // Delay before removing tenant deletion mark and "debug".
 	DeleteBlocksConcurrency int
+	SeriesDeletionEnabled   bool
+	SeriesDeletionDelay     time.Duration // Delay since the end of the deleted time range before blocks are rewritten.
+	SeriesDeletionDataDir   string        // Directory used to rewrite blocks.
 }
 
 type BlocksCleaner struct {
@@ -68,6 +72,11 @@ type BlocksCleaner struct {
 	tenantMarkedBlocks             *prometheus.GaugeVec
 	tenantPartialBlocks            *prometheus.GaugeVec
 	tenantBucketIndexLastUpdate    *prometheus.GaugeVec
+
+	seriesDeletionRequestsProcessed       prometheus.Counter
+	seriesDeletionBlocksRewritten         prometheus.Counter
+	seriesDeletionBlocksFailed            prometheus.Counter
+	seriesDeletionBlocksMarkedForDeletion prometheus.Counter
 }
 
 func NewBlocksCleaner(cfg BlocksCleanerConfig, bucketClient objstore.Bucket, ownUser func(userID string) (bool, error), cfgProvider ConfigProvider, logger log.Logger, reg prometheus.Registerer) *BlocksCleaner {
@@ -112,6 +121,23 @@ func NewBlocksCleaner(cfg BlocksCleanerConfig, bucketClient objstore.Bucket, own
 			Help:        blocksMarkedForDeletionHelp,
 			ConstLabels: prometheus.Labels{"reason": "partial"},
 		}),
+		seriesDeletionBlocksMarkedForDeletion: promauto.With(reg).NewCounter(prometheus.CounterOpts{
+			Name:        blocksMarkedForDeletionName,
+			Help:        blocksMarkedForDeletionHelp,
+			ConstLabels: prometheus.Labels{"reason": "series-deletion"},
+		}),
+		seriesDeletionRequestsProcessed: promauto.With(reg).NewCounter(prometheus.CounterOpts{
+			Name: "cortex_compactor_series_deletion_requests_processed_total",
+			Help: "Total number of series deletion requests processed.",
+		}),
+		seriesDeletionBlocksRewritten: promauto.With(reg).NewCounter(prometheus.CounterOpts{
+			Name: "cortex_compactor_series_deletion_blocks_rewritten_total",
+			Help: "Total number of blocks rewritten to apply series deletion requests.",
+		}),
+		seriesDeletionBlocksFailed: promauto.With(reg).NewCounter(prometheus.CounterOpts{
+			Name: "cortex_compactor_series_deletion_blocks_failures_total",
+			Help: "Total number of blocks failed to be rewritten to apply series deletion requests.",
+		}),
 
 		// The following metrics don't have the "cortex_compactor" prefix because not strictly related to
 		// the compactor. They're just tracked by the compactor because it's the most logical place where these
@@ -307,6 +333,12 @@ func (c *BlocksCleaner) deleteUserMarkedForDeletion(ctx context.Context, userID
 		level.Info(userLogger).Log("msg", "deleted marker files for tenant marked for deletion", "count", deleted)
 	}
 
+	if deleted, err := bucket.DeletePrefix(ctx, userBucket, metricmetadata.Pathname, userLogger); err != nil {
+		return errors.Wrap(err, "failed to delete metric metadata files")
+	} else if deleted > 0 {
+		level.Info(userLogger).Log("msg", "deleted metric metadata files for tenant marked for deletion", "count", deleted)
+	}
+
 	return nil
 }
 
@@ -352,6 +384,14 @@ func (c *BlocksCleaner) cleanUser(ctx context.Context, userID string) (returnErr
 
 	c.deleteBlocksMarkedForDeletion(ctx, idx, userBucket, userLogger)
 
+	// Rewriting blocks to apply series deletion requests is a best effort, so we don't return
+	// error if it fails. Requests are kept pending and retried at the next run.
+	if c.cfg.SeriesDeletionEnabled {
+		if err := c.applySeriesDeletionRequests(ctx, userID, idx, userBucket, userLogger); err != nil {
+			level.Warn(userLogger).Log("msg", "failed to apply series deletion requests", "err", err)
+		}
+	}
+
 	// Partial blocks with a deletion mark can be cleaned up. This is a best effort, so we don't return
 	// error if the cleanup of partial blocks fail.
 	if len(partials) > 0 {
@@ -373,6 +413,66 @@ func (c *BlocksCleaner) cleanUser(ctx context.Context, userID string) (returnErr
 	c.tenantPartialBlocks.WithLabelValues(userID).Set(float64(len(partials)))
 	c.tenantBucketIndexLastUpdate.WithLabelValues(userID).SetToCurrentTime()
 
+	// Merging the metric metadata uploaded by ingesters is a best effort, so we don't return
+	// error if it fails. The ingesters files are kept and merged again at the next run.
+	if err := c.updateMetricMetadataIndex(ctx, userID, userLogger); err != nil {
+		level.Warn(userLogger).Log("msg", "failed to update metric metadata index", "err", err)
+	}
+
+	return nil
+}
+
+// updateMetricMetadataIndex merges the metric metadata uploaded by ingesters into the tenant's metric metadata
+// index, removes the metadata not seen within the retention period and deletes the stale ingesters files.
+func (c *BlocksCleaner) updateMetricMetadataIndex(ctx context.Context, userID string, userLogger log.Logger) error {
+	files, err := metricmetadata.ReadIngesterFiles(ctx, c.bucketClient, userID, c.cfgProvider, userLogger)
+	if err != nil {
+		return err
+	}
+
+	idx, err := metricmetadata.ReadIndex(ctx, c.bucketClient, userID, c.cfgProvider, userLogger)
+	if errors.Is(err, metricmetadata.ErrIndexNotFound) {
+		// Nothing to do if ingesters haven't uploaded any metadata yet.
+		if len(files) == 0 {
+			return nil
+		}
+	} else if errors.Is(err, metricmetadata.ErrIndexCorrupted) {
+		level.Warn(userLogger).Log("msg", "found a corrupted metric metadata index, recreating it")
+	} else if err != nil {
+		return err
+	}
+
+	merged := make([]*metricmetadata.File, 0, len(files)+1)
+	merged = append(merged, idx)
+	for _, f := range files {
+		merged = append(merged, f)
+	}
+
+	now := time.Now()
+	updated := metricmetadata.Merge(now, merged...)
+	if retention := c.cfgProvider.CompactorBlocksRetentionPeriod(userID); retention > 0 {
+		if removed := updated.Purge(now.Add(-retention)); removed > 0 {
+			level.Info(userLogger).Log("msg", "removed metric metadata outside of the retention period", "count", removed)
+		}
+	}
+
+	if err := metricmetadata.WriteIndex(ctx, c.bucketClient, userID, c.cfgProvider, updated); err != nil {
+		return err
+	}
+
+	// The files not updated for a long time belong to ingesters which have been shut down or scaled
+	// down, and their content has already been merged into the index.
+	for name, f := range files {
+		if time.Since(f.GetUpdatedAt()) <= c.cfg.DeletionDelay {
+			continue
+		}
+		if err := metricmetadata.DeleteFile(ctx, c.bucketClient, userID, c.cfgProvider, name); err != nil {
+			level.Warn(userLogger).Log("msg", "failed to delete stale ingester metric metadata", "file", name, "err", err)
+			continue
+		}
+		level.Info(userLogger).Log("msg", "deleted stale ingester metric metadata", "file", name)
+	}
+
 	return nil
 }
 
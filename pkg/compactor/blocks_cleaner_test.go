@@ -26,8 +26,10 @@ import (
 	"github.com/thanos-io/thanos/pkg/block/metadata"
 	"github.com/thanos-io/thanos/pkg/objstore"
 
+	"github.com/grafana/mimir/pkg/mimirpb"
 	"github.com/grafana/mimir/pkg/storage/tsdb"
 	"github.com/grafana/mimir/pkg/storage/tsdb/bucketindex"
+	"github.com/grafana/mimir/pkg/storage/tsdb/metricmetadata"
 	mimir_testutil "github.com/grafana/mimir/pkg/storage/tsdb/testutil"
 	"github.com/grafana/mimir/pkg/util"
 	"github.com/grafana/mimir/pkg/util/test"
@@ -566,6 +568,7 @@ func TestBlocksCleaner_ShouldRemoveBlocksOutsideRetentionPeriod(t *testing.T) {
 			# TYPE cortex_compactor_blocks_marked_for_deletion_total counter
 			cortex_compactor_blocks_marked_for_deletion_total{reason="partial"} 0
 			cortex_compactor_blocks_marked_for_deletion_total{reason="retention"} 0
+			cortex_compactor_blocks_marked_for_deletion_total{reason="series-deletion"} 0
 			`),
 			"cortex_bucket_blocks_count",
 			"cortex_bucket_blocks_marked_for_deletion_count",
@@ -608,6 +611,7 @@ func TestBlocksCleaner_ShouldRemoveBlocksOutsideRetentionPeriod(t *testing.T) {
 			# TYPE cortex_compactor_blocks_marked_for_deletion_total counter
 			cortex_compactor_blocks_marked_for_deletion_total{reason="partial"} 0
 			cortex_compactor_blocks_marked_for_deletion_total{reason="retention"} 1
+			cortex_compactor_blocks_marked_for_deletion_total{reason="series-deletion"} 0
 			`),
 			"cortex_bucket_blocks_count",
 			"cortex_bucket_blocks_marked_for_deletion_count",
@@ -647,6 +651,7 @@ func TestBlocksCleaner_ShouldRemoveBlocksOutsideRetentionPeriod(t *testing.T) {
 			# TYPE cortex_compactor_blocks_marked_for_deletion_total counter
 			cortex_compactor_blocks_marked_for_deletion_total{reason="partial"} 0
 			cortex_compactor_blocks_marked_for_deletion_total{reason="retention"} 1
+			cortex_compactor_blocks_marked_for_deletion_total{reason="series-deletion"} 0
 			`),
 			"cortex_bucket_blocks_count",
 			"cortex_bucket_blocks_marked_for_deletion_count",
@@ -677,6 +682,7 @@ func TestBlocksCleaner_ShouldRemoveBlocksOutsideRetentionPeriod(t *testing.T) {
 			# TYPE cortex_compactor_blocks_marked_for_deletion_total counter
 			cortex_compactor_blocks_marked_for_deletion_total{reason="partial"} 0
 			cortex_compactor_blocks_marked_for_deletion_total{reason="retention"} 3
+			cortex_compactor_blocks_marked_for_deletion_total{reason="series-deletion"} 0
 			`),
 			"cortex_bucket_blocks_count",
 			"cortex_bucket_blocks_marked_for_deletion_count",
@@ -744,6 +750,7 @@ func TestBlocksCleaner_ShouldRemovePartialBlocksOutsideDelayPeriod(t *testing.T)
 			# TYPE cortex_compactor_blocks_marked_for_deletion_total counter
 			cortex_compactor_blocks_marked_for_deletion_total{reason="partial"} 1
 			cortex_compactor_blocks_marked_for_deletion_total{reason="retention"} 0
+			cortex_compactor_blocks_marked_for_deletion_total{reason="series-deletion"} 0
 			`),
 		"cortex_bucket_blocks_count",
 		"cortex_bucket_blocks_marked_for_deletion_count",
@@ -823,6 +830,7 @@ func TestBlocksCleaner_ShouldNotRemovePartialBlocksInsideDelayPeriod(t *testing.
 			# TYPE cortex_compactor_blocks_marked_for_deletion_total counter
 			cortex_compactor_blocks_marked_for_deletion_total{reason="partial"} 0
 			cortex_compactor_blocks_marked_for_deletion_total{reason="retention"} 0
+			cortex_compactor_blocks_marked_for_deletion_total{reason="series-deletion"} 0
 			`),
 		"cortex_bucket_blocks_count",
 		"cortex_bucket_blocks_marked_for_deletion_count",
@@ -830,6 +838,68 @@ func TestBlocksCleaner_ShouldNotRemovePartialBlocksInsideDelayPeriod(t *testing.
 	))
 }
 
+func TestBlocksCleaner_ShouldMergeIngestersMetricMetadata(t *testing.T) {
+	const userID = "user-1"
+
+	bucketClient, _ := mimir_testutil.PrepareFilesystemBucket(t)
+	bucketClient = bucketindex.BucketWithGlobalMarkers(bucketClient)
+
+	ctx := context.Background()
+	logger := log.NewNopLogger()
+	now := time.Now()
+
+	createTSDBBlock(t, bucketClient, userID, 10, 20, 2, nil)
+
+	// The metadata uploaded by an active ingester, and by an ingester which has been scaled down.
+	active := metricmetadata.NewFile(map[mimirpb.MetricMetadata]time.Time{
+		{MetricFamilyName: "a", Type: mimirpb.COUNTER, Help: "a help"}: now,
+		{MetricFamilyName: "b", Type: mimirpb.GAUGE, Help: "b help"}:   now.Add(-48 * time.Hour),
+	}, now)
+	stale := metricmetadata.NewFile(map[mimirpb.MetricMetadata]time.Time{
+		{MetricFamilyName: "c", Type: mimirpb.HISTOGRAM, Help: "c help"}: now.Add(-2 * time.Hour),
+	}, now.Add(-2*time.Hour))
+	require.NoError(t, metricmetadata.WriteIngesterFile(ctx, bucketClient, userID, "ingester-1", nil, active))
+	require.NoError(t, metricmetadata.WriteIngesterFile(ctx, bucketClient, userID, "ingester-2", nil, stale))
+
+	cfg := BlocksCleanerConfig{
+		DeletionDelay:           time.Hour,
+		CleanupInterval:         time.Minute,
+		CleanupConcurrency:      1,
+		DeleteBlocksConcurrency: 1,
+	}
+
+	cfgProvider := newMockConfigProvider()
+	cfgProvider.userRetentionPeriods[userID] = 24 * time.Hour
+
+	cleaner := NewBlocksCleaner(cfg, bucketClient, tsdb.AllUsers, cfgProvider, logger, nil)
+	require.NoError(t, cleaner.cleanUser(ctx, userID))
+
+	// The metadata outside of the retention period has been removed from the index.
+	idx, err := metricmetadata.ReadIndex(ctx, bucketClient, userID, nil, logger)
+	require.NoError(t, err)
+	assert.Equal(t, []*mimirpb.MetricMetadata{
+		{MetricFamilyName: "a", Type: mimirpb.COUNTER, Help: "a help"},
+		{MetricFamilyName: "c", Type: mimirpb.HISTOGRAM, Help: "c help"},
+	}, idx.MetricMetadata())
+
+	// The stale ingester file has been deleted.
+	files, err := metricmetadata.ReadIngesterFiles(ctx, bucketClient, userID, nil, logger)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]*metricmetadata.File{
+		metricmetadata.IngesterFilePath("ingester-1"): active,
+	}, files)
+
+	// The index keeps the metadata of the deleted ingester file on the next run.
+	require.NoError(t, cleaner.cleanUser(ctx, userID))
+
+	idx, err = metricmetadata.ReadIndex(ctx, bucketClient, userID, nil, logger)
+	require.NoError(t, err)
+	assert.Equal(t, []*mimirpb.MetricMetadata{
+		{MetricFamilyName: "a", Type: mimirpb.COUNTER, Help: "a help"},
+		{MetricFamilyName: "c", Type: mimirpb.HISTOGRAM, Help: "c help"},
+	}, idx.MetricMetadata())
+}
+
 type mockBucketFailure struct {
 	objstore.Bucket
 
@@ -36,6 +36,7 @@ import (
 	"github.com/grafana/mimir/pkg/storage/sharding"
 	mimit_tsdb "github.com/grafana/mimir/pkg/storage/tsdb"
 	"github.com/grafana/mimir/pkg/storage/tsdb/bucketindex"
+	"github.com/grafana/mimir/pkg/storage/tsdb/exemplars"
 )
 
 type ResolutionLevel int64
@@ -356,6 +357,9 @@ func (c *BucketCompactor) runCompactionJob(ctx context.Context, job *Job) (shoul
 		blocksToCompactDirs[ix] = filepath.Join(subDir, meta.ULID.String())
 	}
 
+	// Exemplars of the source blocks, if any, are uploaded alongside the compacted blocks.
+	sourceExemplars := mergeBlocksExemplars(blocksToCompactDirs, jobLogger)
+
 	elapsed := time.Since(downloadBegin)
 	level.Info(jobLogger).Log("msg", "downloaded and verified blocks; compacting blocks", "blocks", len(blocksToCompactDirs), "plan", fmt.Sprintf("%v", blocksToCompactDirs), "duration", elapsed, "duration_ms", elapsed.Milliseconds())
 
@@ -426,6 +430,23 @@ func (c *BucketCompactor) runCompactionJob(ctx context.Context, job *Job) (shoul
 			return errors.Wrapf(err, "invalid result block %s", bdir)
 		}
 
+		// Upload the exemplars in the time range and shard of the result block. Failing to upload
+		// them doesn't fail the compaction, given exemplars are best-effort anyway.
+		if sourceExemplars != nil {
+			var keep func(labels.Labels) bool
+			if job.UseSplitting() {
+				keep = func(lbls labels.Labels) bool {
+					return lbls.Hash()%uint64(job.SplittingShards()) == uint64(blockToUpload.shardIndex)
+				}
+			}
+
+			if blockExemplars := sourceExemplars.Filter(newMeta.MinTime, newMeta.MaxTime-1, keep); len(blockExemplars.Series) > 0 {
+				if err := exemplars.Upload(ctx, c.bkt, blockToUpload.ulid, blockExemplars); err != nil {
+					level.Warn(jobLogger).Log("msg", "failed to upload block exemplars", "result_block", blockToUpload.ulid, "err", err)
+				}
+			}
+		}
+
 		begin := time.Now()
 		if err := mimit_tsdb.UploadBlock(ctx, jobLogger, c.bkt, bdir, nil); err != nil {
 			return errors.Wrapf(err, "upload of %s failed", blockToUpload.ulid)
@@ -454,6 +475,27 @@ func (c *BucketCompactor) runCompactionJob(ctx context.Context, job *Job) (shoul
 	return true, compIDs, nil
 }
 
+// mergeBlocksExemplars returns the merged exemplars of the input local blocks, or nil if none of them
+// has exemplars. Exemplars files which can't be read are skipped.
+func mergeBlocksExemplars(blockDirs []string, logger log.Logger) *exemplars.File {
+	var files []*exemplars.File
+	for _, dir := range blockDirs {
+		f, err := exemplars.ReadFromDir(dir, logger)
+		if err != nil {
+			level.Warn(logger).Log("msg", "skipped exemplars of source block", "block", dir, "err", err)
+			continue
+		}
+		if f != nil {
+			files = append(files, f)
+		}
+	}
+
+	if len(files) == 0 {
+		return nil
+	}
+	return exemplars.Merge(files...)
+}
+
 // convertCompactionResultToForEachJobs filters out empty ULIDs.
 // When handling result of split compactions, shard index is index in the slice returned by compaction.
 func convertCompactionResultToForEachJobs(compactedBlocks []ulid.ULID, splitJob bool, jobLogger log.Logger) []ulidWithShardIndex {
@@ -90,6 +90,9 @@ type Config struct {
 	TenantCleanupDelay    time.Duration           `yaml:"tenant_cleanup_delay" category:"advanced"`
 	MaxCompactionTime     time.Duration           `yaml:"max_compaction_time" category:"advanced"`
 
+	SeriesDeletionEnabled bool          `yaml:"series_deletion_enabled" category:"experimental"`
+	SeriesDeletionDelay   time.Duration `yaml:"series_deletion_delay" category:"experimental"`
+
 	// Compactor concurrency options
 	MaxOpeningBlocksConcurrency int `yaml:"max_opening_blocks_concurrency" category:"advanced"` // Number of goroutines opening blocks before compaction.
 	MaxClosingBlocksConcurrency int `yaml:"max_closing_blocks_concurrency" category:"advanced"` // Max number of blocks that can be closed concurrently during split compaction. Note that closing of newly compacted block uses a lot of memory for writing index.
@@ -138,6 +141,8 @@ func (cfg *Config) RegisterFlags(f *flag.FlagSet, logger log.Logger) {
 		"If not 0, blocks will be marked for deletion and compactor component will permanently delete blocks marked for deletion from the bucket. "+
 		"If 0, blocks will be deleted straight away. Note that deleting blocks immediately can cause query failures.")
 	f.DurationVar(&cfg.TenantCleanupDelay, "compactor.tenant-cleanup-delay", 6*time.Hour, "For tenants marked for deletion, this is time between deleting of last block, and doing final cleanup (marker files, debug files) of the tenant.")
+	f.BoolVar(&cfg.SeriesDeletionEnabled, "compactor.series-deletion-enabled", false, "True to rewrite blocks to physically remove the data matching the tenants' series deletion requests. The blocks containing series matching pending series deletion requests are not compacted until the requests have been applied to them.")
+	f.DurationVar(&cfg.SeriesDeletionDelay, "compactor.series-deletion-delay", 24*time.Hour, "Time to wait since a series deletion request was created, and since the end of its time range, before rewriting the affected blocks. It should be greater than the time it takes for ingesters to upload the blocks containing the deleted time range.")
 	// compactor concurrency options
 	f.IntVar(&cfg.MaxOpeningBlocksConcurrency, "compactor.max-opening-blocks-concurrency", 1, "Number of goroutines opening blocks before compaction.")
 	f.IntVar(&cfg.MaxClosingBlocksConcurrency, "compactor.max-closing-blocks-concurrency", 1, "Max number of blocks that can be closed concurrently during split compaction. Note that closing of newly compacted block uses a lot of memory for writing index.")
@@ -446,6 +451,9 @@ func (c *MultitenantCompactor) starting(ctx context.Context) error {
 		CleanupConcurrency:      c.compactorCfg.CleanupConcurrency,
 		TenantCleanupDelay:      c.compactorCfg.TenantCleanupDelay,
 		DeleteBlocksConcurrency: defaultDeleteBlocksConcurrency,
+		SeriesDeletionEnabled:   c.compactorCfg.SeriesDeletionEnabled,
+		SeriesDeletionDelay:     c.compactorCfg.SeriesDeletionDelay,
+		SeriesDeletionDataDir:   filepath.Join(c.compactorCfg.DataDir, "series-deletion"),
 	}, c.bucketClient, c.shardingStrategy.blocksCleanerOwnUser, c.cfgProvider, c.parentLogger, c.registerer)
 
 	// Start blocks cleaner asynchronously, don't wait until initial cleanup is finished.
@@ -650,6 +658,10 @@ func (c *MultitenantCompactor) compactUser(ctx context.Context, userID string) e
 		// removes blocks that should not be compacted due to being marked so.
 		NewNoCompactionMarkFilter(bucket, true),
 	}
+	if c.compactorCfg.SeriesDeletionEnabled {
+		// Removes blocks which are going to be rewritten to apply the pending series deletion requests.
+		fetcherFilters = append(fetcherFilters, NewPendingSeriesDeletionFilter(c.bucketClient, bucket, userID, filepath.Join(c.metaSyncDirForUser(userID), pendingSeriesDeletionDir), ulogger))
+	}
 
 	fetcher, err := block.NewMetaFetcher(
 		ulogger,
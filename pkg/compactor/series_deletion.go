@@ -0,0 +1,419 @@
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package compactor
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/oklog/ulid"
+	"github.com/pkg/errors"
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/prometheus/prometheus/tsdb"
+	"github.com/prometheus/prometheus/tsdb/tombstones"
+	"github.com/thanos-io/thanos/pkg/block"
+	"github.com/thanos-io/thanos/pkg/block/metadata"
+	"github.com/thanos-io/thanos/pkg/extprom"
+	"github.com/thanos-io/thanos/pkg/objstore"
+	"github.com/thanos-io/thanos/pkg/runutil"
+
+	mimir_tsdb "github.com/grafana/mimir/pkg/storage/tsdb"
+	"github.com/grafana/mimir/pkg/storage/tsdb/bucketindex"
+	"github.com/grafana/mimir/pkg/storegateway/indexheader"
+)
+
+// applySeriesDeletionRequests rewrites the blocks containing data matching the tenant's pending
+// series deletion requests. A request is processed only once it's older than the configured delay,
+// so that all the data it affects has been uploaded to the storage by ingesters, and it's marked as
+// processed once all the affected blocks have been rewritten.
+func (c *BlocksCleaner) applySeriesDeletionRequests(ctx context.Context, userID string, idx *bucketindex.Index, userBucket objstore.Bucket, userLogger log.Logger) error {
+	all, err := mimir_tsdb.ReadTombstones(ctx, c.bucketClient, userID)
+	if err != nil {
+		return errors.Wrap(err, "failed to read series deletion requests")
+	}
+
+	var pending []*mimir_tsdb.Tombstone
+	for _, t := range all {
+		if t.State == mimir_tsdb.TombstonePending && c.isSeriesDeletionRequestReady(t, time.Now()) {
+			pending = append(pending, t)
+		}
+	}
+	if len(pending) == 0 {
+		return nil
+	}
+
+	marked := make(map[ulid.ULID]struct{}, len(idx.BlockDeletionMarks))
+	for _, m := range idx.BlockDeletionMarks {
+		marked[m.ID] = struct{}{}
+	}
+
+	failed := false
+	for _, b := range idx.Blocks {
+		if _, ok := marked[b.ID]; ok {
+			continue
+		}
+
+		// The block max time is exclusive.
+		var overlapping []*mimir_tsdb.Tombstone
+		for _, t := range pending {
+			if t.Overlaps(b.MinTime, b.MaxTime-1) {
+				overlapping = append(overlapping, t)
+			}
+		}
+		if len(overlapping) == 0 {
+			continue
+		}
+
+		if err := c.rewriteBlockWithSeriesDeletions(ctx, userID, b.ID, overlapping, userBucket, userLogger); err != nil {
+			failed = true
+			c.seriesDeletionBlocksFailed.Inc()
+			level.Warn(userLogger).Log("msg", "failed to apply series deletion requests to block", "block", b.ID, "err", err)
+			continue
+		}
+	}
+
+	// A request is processed only if all the blocks have been successfully rewritten.
+	// Otherwise, we'll retry at the next run and skip the blocks already rewritten.
+	if failed {
+		return errors.New("failed to apply series deletion requests to some blocks")
+	}
+
+	for _, t := range pending {
+		t.UpdateState(mimir_tsdb.TombstoneProcessed, time.Now())
+		if err := mimir_tsdb.WriteTombstone(ctx, c.bucketClient, userID, c.cfgProvider, t); err != nil {
+			return errors.Wrapf(err, "failed to mark series deletion request %s as processed", t.RequestID)
+		}
+
+		c.seriesDeletionRequestsProcessed.Inc()
+		level.Info(userLogger).Log("msg", "series deletion request processed", "request_id", t.RequestID)
+	}
+
+	return nil
+}
+
+// isSeriesDeletionRequestReady returns whether the series deletion request is old enough to be applied.
+func (c *BlocksCleaner) isSeriesDeletionRequestReady(t *mimir_tsdb.Tombstone, now time.Time) bool {
+	readyAt := t.GetRequestCreatedAt()
+	if endTime := time.UnixMilli(t.EndTime); endTime.After(readyAt) {
+		readyAt = endTime
+	}
+
+	return now.Sub(readyAt) >= c.cfg.SeriesDeletionDelay
+}
+
+// rewriteBlockWithSeriesDeletions uploads a copy of the block without the data matching the input
+// series deletion requests, and marks the original block for deletion. Requests which have already
+// been applied to the block are skipped.
+func (c *BlocksCleaner) rewriteBlockWithSeriesDeletions(ctx context.Context, userID string, blockID ulid.ULID, requests []*mimir_tsdb.Tombstone, userBucket objstore.Bucket, userLogger log.Logger) error {
+	meta, err := block.DownloadMeta(ctx, userLogger, userBucket, blockID)
+	if err != nil {
+		return err
+	}
+
+	requests = filterSeriesDeletionRequestsNotApplied(meta, requests)
+	if len(requests) == 0 {
+		return nil
+	}
+
+	workDir := filepath.Join(c.cfg.SeriesDeletionDataDir, userID)
+	if err := os.RemoveAll(workDir); err != nil {
+		return errors.Wrap(err, "failed to clean up working directory")
+	}
+	if err := os.MkdirAll(workDir, os.ModePerm); err != nil {
+		return errors.Wrap(err, "failed to create working directory")
+	}
+	defer func() {
+		if err := os.RemoveAll(workDir); err != nil {
+			level.Warn(userLogger).Log("msg", "failed to remove working directory", "dir", workDir, "err", err)
+		}
+	}()
+
+	srcDir := filepath.Join(workDir, blockID.String())
+	if err := block.Download(ctx, userLogger, userBucket, blockID, srcDir); err != nil {
+		return errors.Wrapf(err, "download block %s", blockID)
+	}
+
+	src, err := tsdb.OpenBlock(userLogger, srcDir, nil)
+	if err != nil {
+		return errors.Wrapf(err, "open block %s", blockID)
+	}
+	defer func() {
+		if err := src.Close(); err != nil {
+			level.Warn(userLogger).Log("msg", "failed to close block", "block", blockID, "err", err)
+		}
+	}()
+
+	// Blocks overlapping with the requests but without matching series don't need to be rewritten.
+	matching, err := blockContainsSeries(src, requests)
+	if err != nil {
+		return errors.Wrapf(err, "look up series of block %s", blockID)
+	}
+	if !matching {
+		level.Debug(userLogger).Log("msg", "block doesn't contain series matching the series deletion requests", "block", blockID)
+		return nil
+	}
+
+	deletions := make([]metadata.DeletionRequest, 0, len(requests))
+	for _, t := range requests {
+		for _, matchers := range t.Matchers() {
+			if err := src.Delete(t.StartTime, t.EndTime, matchers...); err != nil {
+				return errors.Wrapf(err, "apply series deletion request %s", t.RequestID)
+			}
+
+			deletions = append(deletions, metadata.DeletionRequest{
+				Matchers:  matchers,
+				Intervals: tombstones.Intervals{t.Interval()},
+				RequestID: t.RequestID,
+			})
+		}
+	}
+
+	comp, err := tsdb.NewLeveledCompactor(ctx, nil, userLogger, []int64{meta.MaxTime - meta.MinTime}, nil, nil, false)
+	if err != nil {
+		return errors.Wrap(err, "create compactor")
+	}
+
+	newID, err := comp.Write(workDir, src, meta.MinTime, meta.MaxTime, &meta.BlockMeta)
+	if err != nil {
+		return errors.Wrapf(err, "rewrite block %s", blockID)
+	}
+
+	// An empty ULID means all data in the block has been deleted, so there's nothing to upload.
+	if newID != (ulid.ULID{}) {
+		newDir := filepath.Join(workDir, newID.String())
+
+		thanosMeta := meta.Thanos
+		thanosMeta.Source = metadata.BucketRewriteSource
+		thanosMeta.SegmentFiles = block.GetSegmentFiles(newDir)
+		thanosMeta.Files = nil
+		thanosMeta.Rewrites = append(append([]metadata.Rewrite(nil), meta.Thanos.Rewrites...), metadata.Rewrite{
+			Sources:          meta.Compaction.Sources,
+			DeletionsApplied: deletions,
+		})
+
+		// Keep the original compaction details, so that the rewritten block is planned like the original one.
+		newMeta, err := metadata.InjectThanos(userLogger, newDir, thanosMeta, &meta.BlockMeta)
+		if err != nil {
+			return errors.Wrapf(err, "failed to finalize the block %s", newDir)
+		}
+
+		if err := os.Remove(filepath.Join(newDir, "tombstones")); err != nil && !os.IsNotExist(err) {
+			return errors.Wrap(err, "remove tombstones")
+		}
+
+		if err := block.VerifyIndex(userLogger, filepath.Join(newDir, block.IndexFilename), newMeta.MinTime, newMeta.MaxTime); err != nil {
+			return errors.Wrapf(err, "invalid result block %s", newDir)
+		}
+
+		if err := mimir_tsdb.UploadBlock(ctx, userLogger, userBucket, newDir, nil); err != nil {
+			return errors.Wrapf(err, "upload of %s failed", newID)
+		}
+
+		level.Info(userLogger).Log("msg", "uploaded block with series deletion requests applied", "block", blockID, "result_block", newID)
+	} else {
+		level.Info(userLogger).Log("msg", "all data in block matches series deletion requests", "block", blockID)
+	}
+
+	c.seriesDeletionBlocksRewritten.Inc()
+
+	// Spawn a new context so we always mark a block for deletion in full on shutdown.
+	delCtx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+
+	if err := block.MarkForDeletion(delCtx, userLogger, userBucket, blockID, "source of block with series deletion requests applied", c.seriesDeletionBlocksMarkedForDeletion); err != nil {
+		return errors.Wrapf(err, "mark block %s for deletion", blockID)
+	}
+	return nil
+}
+
+// blockContainsSeries returns whether the block contains series matching any of the series deletion requests.
+func blockContainsSeries(b *tsdb.Block, requests []*mimir_tsdb.Tombstone) (_ bool, err error) {
+	ir, err := b.Index()
+	if err != nil {
+		return false, err
+	}
+	defer runutil.CloseWithErrCapture(&err, ir, "close index reader")
+
+	for _, t := range requests {
+		for _, matchers := range t.Matchers() {
+			p, err := tsdb.PostingsForMatchers(ir, matchers...)
+			if err != nil {
+				return false, err
+			}
+			if p.Next() {
+				return true, nil
+			}
+			if err := p.Err(); err != nil {
+				return false, err
+			}
+		}
+	}
+	return false, nil
+}
+
+// filterSeriesDeletionRequestsNotApplied returns the series deletion requests not yet applied to the block.
+func filterSeriesDeletionRequestsNotApplied(meta metadata.Meta, requests []*mimir_tsdb.Tombstone) []*mimir_tsdb.Tombstone {
+	applied := map[string]struct{}{}
+	for _, r := range meta.Thanos.Rewrites {
+		for _, d := range r.DeletionsApplied {
+			applied[d.RequestID] = struct{}{}
+		}
+	}
+
+	var result []*mimir_tsdb.Tombstone
+	for _, t := range requests {
+		if _, ok := applied[t.RequestID]; !ok {
+			result = append(result, t)
+		}
+	}
+	return result
+}
+
+// pendingSeriesDeletionMeta is the state of the synced blocks excluded from compaction because of pending series deletion requests.
+const pendingSeriesDeletionMeta = "pending-series-deletion"
+
+// pendingSeriesDeletionDir is the directory, within the tenant's meta sync directory, where the index-headers of the
+// blocks overlapping with pending series deletion requests are built.
+const pendingSeriesDeletionDir = "series-deletion-index-headers"
+
+var _ block.MetadataFilter = &PendingSeriesDeletionFilter{}
+
+// PendingSeriesDeletionFilter is a block.Fetcher filter that removes from synced metas the blocks which may contain
+// series matching the pending series deletion requests of a tenant, not yet applied to them. These blocks are rewritten
+// by the blocks cleaner, so compacting them at the same time could produce a block still containing the deleted series.
+// Whether a block contains matching series is checked on the label values of its index-header, which is built in dir.
+type PendingSeriesDeletionFilter struct {
+	bkt     objstore.BucketReader
+	userBkt objstore.BucketReader
+	userID  string
+	dir     string
+	logger  log.Logger
+}
+
+// NewPendingSeriesDeletionFilter creates PendingSeriesDeletionFilter.
+func NewPendingSeriesDeletionFilter(bkt, userBkt objstore.BucketReader, userID, dir string, logger log.Logger) *PendingSeriesDeletionFilter {
+	return &PendingSeriesDeletionFilter{
+		bkt:     bkt,
+		userBkt: userBkt,
+		userID:  userID,
+		dir:     dir,
+		logger:  logger,
+	}
+}
+
+// Filter removes from metas the blocks which may contain series matching pending series deletion requests.
+func (f *PendingSeriesDeletionFilter) Filter(ctx context.Context, metas map[ulid.ULID]*metadata.Meta, synced *extprom.TxGaugeVec, _ *extprom.TxGaugeVec) error {
+	all, err := mimir_tsdb.ReadTombstones(ctx, f.bkt, f.userID)
+	if err != nil {
+		return errors.Wrap(err, "read series deletion requests")
+	}
+
+	var pending []*mimir_tsdb.Tombstone
+	for _, t := range all {
+		if t.State == mimir_tsdb.TombstonePending {
+			pending = append(pending, t)
+		}
+	}
+	if len(pending) == 0 {
+		return f.removeIndexHeaders(nil)
+	}
+
+	checked := map[ulid.ULID]struct{}{}
+	for id, m := range metas {
+		var overlapping []*mimir_tsdb.Tombstone
+		for _, t := range filterSeriesDeletionRequestsNotApplied(*m, pending) {
+			// The block max time is exclusive.
+			if t.Overlaps(m.MinTime, m.MaxTime-1) {
+				overlapping = append(overlapping, t)
+			}
+		}
+		if len(overlapping) == 0 {
+			continue
+		}
+
+		checked[id] = struct{}{}
+		matching, err := f.blockMayContainSeries(ctx, id, overlapping)
+		if err != nil {
+			return errors.Wrapf(err, "check series deletion requests of block %s", id)
+		}
+		if matching {
+			synced.WithLabelValues(pendingSeriesDeletionMeta).Inc()
+			delete(metas, id)
+		}
+	}
+
+	return f.removeIndexHeaders(checked)
+}
+
+// blockMayContainSeries returns whether the block may contain series matching any of the series deletion requests.
+// A series selector can match series in the block only if each matcher matches some value of its label in the block,
+// or matches the empty value.
+func (f *PendingSeriesDeletionFilter) blockMayContainSeries(ctx context.Context, id ulid.ULID, requests []*mimir_tsdb.Tombstone) (bool, error) {
+	r, err := indexheader.NewBinaryReader(ctx, f.logger, f.userBkt, f.dir, id, mimir_tsdb.DefaultPostingOffsetInMemorySampling, indexheader.BinaryReaderConfig{})
+	if err != nil {
+		return false, err
+	}
+	defer runutil.CloseWithLogOnErr(f.logger, r, "close index-header")
+
+	for _, t := range requests {
+		for _, matchers := range t.Matchers() {
+			ok, err := indexHeaderMatchesAll(r, matchers)
+			if err != nil || ok {
+				return ok, err
+			}
+		}
+	}
+	return false, nil
+}
+
+func indexHeaderMatchesAll(r indexheader.Reader, matchers []*labels.Matcher) (bool, error) {
+	for _, m := range matchers {
+		if m.Matches("") {
+			continue
+		}
+
+		values, err := r.LabelValues(m.Name)
+		if err != nil {
+			return false, err
+		}
+
+		found := false
+		for _, v := range values {
+			if m.Matches(v) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// removeIndexHeaders removes the index-headers of the blocks not in keep.
+func (f *PendingSeriesDeletionFilter) removeIndexHeaders(keep map[ulid.ULID]struct{}) error {
+	entries, err := os.ReadDir(f.dir)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return errors.Wrap(err, "read index-headers directory")
+	}
+
+	for _, e := range entries {
+		if id, err := ulid.Parse(e.Name()); err == nil {
+			if _, ok := keep[id]; ok {
+				continue
+			}
+		}
+		if err := os.RemoveAll(filepath.Join(f.dir, e.Name())); err != nil {
+			return errors.Wrap(err, "remove index-header")
+		}
+	}
+	return nil
+}
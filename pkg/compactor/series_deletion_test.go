@@ -0,0 +1,268 @@
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package compactor
+
+import (
+	"context"
+	"os"
+	"path"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/oklog/ulid"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/prometheus/prometheus/tsdb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/thanos-io/thanos/pkg/block"
+	"github.com/thanos-io/thanos/pkg/block/metadata"
+	"github.com/thanos-io/thanos/pkg/extprom"
+
+	"github.com/grafana/mimir/pkg/storage/bucket"
+	mimir_tsdb "github.com/grafana/mimir/pkg/storage/tsdb"
+	"github.com/grafana/mimir/pkg/storage/tsdb/bucketindex"
+	mimir_testutil "github.com/grafana/mimir/pkg/storage/tsdb/testutil"
+	"github.com/grafana/mimir/pkg/util/test"
+)
+
+func TestBlocksCleaner_ShouldApplySeriesDeletionRequests(t *testing.T) {
+	const userID = "user-1"
+
+	bucketClient, _ := mimir_testutil.PrepareFilesystemBucket(t)
+	bucketClient = bucketindex.BucketWithGlobalMarkers(bucketClient)
+
+	ctx := context.Background()
+	logger := test.NewTestingLogger(t)
+	reg := prometheus.NewPedanticRegistry()
+	cfgProvider := newMockConfigProvider()
+
+	// Create blocks with series "series_id=0" to "series_id=3" in the past.
+	now := time.Now()
+	minT := now.Add(-4*time.Hour).Unix() * 1000
+	maxT := now.Add(-2*time.Hour).Unix() * 1000
+	affected := createTSDBBlock(t, bucketClient, userID, minT, maxT, 4, nil)
+	unaffected := createTSDBBlock(t, bucketClient, userID, maxT, maxT+(maxT-minT), 4, nil)
+
+	// Create a deletion request which has already passed the delay, and one which hasn't.
+	ready, err := mimir_tsdb.NewTombstone(now.Add(-2*time.Hour), 0, maxT-1, []string{`{series_id="0"}`})
+	require.NoError(t, err)
+	require.NoError(t, mimir_tsdb.WriteTombstone(ctx, bucketClient, userID, nil, ready))
+
+	notReady, err := mimir_tsdb.NewTombstone(now, 0, maxT-1, []string{`{series_id="1"}`})
+	require.NoError(t, err)
+	require.NoError(t, mimir_tsdb.WriteTombstone(ctx, bucketClient, userID, nil, notReady))
+
+	cfg := BlocksCleanerConfig{
+		DeletionDelay:           time.Hour,
+		CleanupInterval:         time.Minute,
+		CleanupConcurrency:      1,
+		DeleteBlocksConcurrency: 1,
+		SeriesDeletionEnabled:   true,
+		SeriesDeletionDelay:     time.Hour,
+		SeriesDeletionDataDir:   filepath.Join(t.TempDir(), "series-deletion"),
+	}
+
+	cleaner := NewBlocksCleaner(cfg, bucketClient, mimir_tsdb.AllUsers, cfgProvider, logger, reg)
+	require.NoError(t, cleaner.cleanUsers(ctx))
+
+	userBucket := bucket.NewUserBucketClient(userID, bucketClient, nil)
+
+	// The affected block has been marked for deletion, while the other one hasn't.
+	assertBlockMarkedForDeletion := func(blockID ulid.ULID, expected bool) {
+		exists, err := userBucket.Exists(ctx, path.Join(blockID.String(), metadata.DeletionMarkFilename))
+		require.NoError(t, err)
+		assert.Equal(t, expected, exists)
+	}
+	assertBlockMarkedForDeletion(affected, true)
+	assertBlockMarkedForDeletion(unaffected, false)
+
+	// Find the rewritten block.
+	var rewritten []ulid.ULID
+	require.NoError(t, userBucket.Iter(ctx, "", func(name string) error {
+		if id, ok := block.IsBlockDir(name); ok && id != affected && id != unaffected {
+			rewritten = append(rewritten, id)
+		}
+		return nil
+	}))
+	require.Len(t, rewritten, 1)
+
+	meta, err := block.DownloadMeta(ctx, logger, userBucket, rewritten[0])
+	require.NoError(t, err)
+	assert.Equal(t, minT, meta.MinTime)
+	assert.Equal(t, maxT, meta.MaxTime)
+	assert.Equal(t, uint64(3), meta.Stats.NumSeries)
+	assert.Equal(t, metadata.BucketRewriteSource, meta.Thanos.Source)
+	require.Len(t, meta.Thanos.Rewrites, 1)
+	require.Len(t, meta.Thanos.Rewrites[0].DeletionsApplied, 1)
+	assert.Equal(t, ready.RequestID, meta.Thanos.Rewrites[0].DeletionsApplied[0].RequestID)
+
+	// Ensure the deleted series is not in the rewritten block anymore.
+	blockDir := filepath.Join(t.TempDir(), rewritten[0].String())
+	require.NoError(t, block.Download(ctx, logger, userBucket, rewritten[0], blockDir))
+	b, err := tsdb.OpenBlock(logger, blockDir, nil)
+	require.NoError(t, err)
+	t.Cleanup(func() { require.NoError(t, b.Close()) })
+
+	idxReader, err := b.Index()
+	require.NoError(t, err)
+	t.Cleanup(func() { require.NoError(t, idxReader.Close()) })
+
+	values, err := idxReader.SortedLabelValues("series_id")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"1", "2", "3"}, values)
+
+	// The ready request has been processed, while the other is still pending.
+	actual, err := mimir_tsdb.ReadTombstone(ctx, bucketClient, userID, ready.RequestID)
+	require.NoError(t, err)
+	assert.Equal(t, mimir_tsdb.TombstoneProcessed, actual.State)
+
+	actual, err = mimir_tsdb.ReadTombstone(ctx, bucketClient, userID, notReady.RequestID)
+	require.NoError(t, err)
+	assert.Equal(t, mimir_tsdb.TombstonePending, actual.State)
+
+	assert.Equal(t, float64(1), testutil.ToFloat64(cleaner.seriesDeletionRequestsProcessed))
+	assert.Equal(t, float64(1), testutil.ToFloat64(cleaner.seriesDeletionBlocksRewritten))
+	assert.Equal(t, float64(0), testutil.ToFloat64(cleaner.seriesDeletionBlocksFailed))
+
+	// Running the cleaner again doesn't rewrite any block.
+	require.NoError(t, cleaner.cleanUsers(ctx))
+	assert.Equal(t, float64(1), testutil.ToFloat64(cleaner.seriesDeletionBlocksRewritten))
+}
+
+func TestFilterSeriesDeletionRequestsNotApplied(t *testing.T) {
+	t1, err := mimir_tsdb.NewTombstone(time.Now(), 0, 10, []string{"up"})
+	require.NoError(t, err)
+	t2, err := mimir_tsdb.NewTombstone(time.Now(), 0, 20, []string{"up"})
+	require.NoError(t, err)
+
+	meta := metadata.Meta{Thanos: metadata.Thanos{Rewrites: []metadata.Rewrite{{
+		DeletionsApplied: []metadata.DeletionRequest{{
+			Matchers:  []*labels.Matcher{labels.MustNewMatcher(labels.MatchEqual, labels.MetricName, "up")},
+			RequestID: t1.RequestID,
+		}},
+	}}}}
+
+	assert.Equal(t, []*mimir_tsdb.Tombstone{t2}, filterSeriesDeletionRequestsNotApplied(meta, []*mimir_tsdb.Tombstone{t1, t2}))
+	assert.Empty(t, filterSeriesDeletionRequestsNotApplied(meta, []*mimir_tsdb.Tombstone{t1}))
+}
+
+func TestBlocksCleaner_ShouldNotRewriteBlocksWithoutSeriesMatchingSeriesDeletionRequests(t *testing.T) {
+	const userID = "user-1"
+
+	bucketClient, _ := mimir_testutil.PrepareFilesystemBucket(t)
+	bucketClient = bucketindex.BucketWithGlobalMarkers(bucketClient)
+
+	ctx := context.Background()
+	logger := test.NewTestingLogger(t)
+	now := time.Now()
+	minT := now.Add(-4*time.Hour).Unix() * 1000
+	maxT := now.Add(-2*time.Hour).Unix() * 1000
+	blockID := createTSDBBlock(t, bucketClient, userID, minT, maxT, 2, nil)
+
+	request, err := mimir_tsdb.NewTombstone(now.Add(-2*time.Hour), 0, maxT-1, []string{`{series_id="5"}`})
+	require.NoError(t, err)
+	require.NoError(t, mimir_tsdb.WriteTombstone(ctx, bucketClient, userID, nil, request))
+
+	cfg := BlocksCleanerConfig{
+		DeletionDelay:           time.Hour,
+		CleanupInterval:         time.Minute,
+		CleanupConcurrency:      1,
+		DeleteBlocksConcurrency: 1,
+		SeriesDeletionEnabled:   true,
+		SeriesDeletionDelay:     time.Hour,
+		SeriesDeletionDataDir:   filepath.Join(t.TempDir(), "series-deletion"),
+	}
+
+	cleaner := NewBlocksCleaner(cfg, bucketClient, mimir_tsdb.AllUsers, newMockConfigProvider(), logger, prometheus.NewPedanticRegistry())
+	require.NoError(t, cleaner.cleanUsers(ctx))
+
+	userBucket := bucket.NewUserBucketClient(userID, bucketClient, nil)
+	exists, err := userBucket.Exists(ctx, path.Join(blockID.String(), metadata.DeletionMarkFilename))
+	require.NoError(t, err)
+	assert.False(t, exists)
+
+	actual, err := mimir_tsdb.ReadTombstone(ctx, bucketClient, userID, request.RequestID)
+	require.NoError(t, err)
+	assert.Equal(t, mimir_tsdb.TombstoneProcessed, actual.State)
+
+	assert.Equal(t, float64(0), testutil.ToFloat64(cleaner.seriesDeletionBlocksRewritten))
+}
+
+func TestPendingSeriesDeletionFilter(t *testing.T) {
+	const userID = "user-1"
+
+	bucketClient, _ := mimir_testutil.PrepareFilesystemBucket(t)
+	userBucket := bucket.NewUserBucketClient(userID, bucketClient, nil)
+	ctx := context.Background()
+	logger := test.NewTestingLogger(t)
+
+	pending, err := mimir_tsdb.NewTombstone(time.Now(), 100, 299, []string{`{series_id="0"}`})
+	require.NoError(t, err)
+	require.NoError(t, mimir_tsdb.WriteTombstone(ctx, bucketClient, userID, nil, pending))
+
+	pendingNotMatching, err := mimir_tsdb.NewTombstone(time.Now(), 300, 399, []string{`{series_id="5"}`})
+	require.NoError(t, err)
+	require.NoError(t, mimir_tsdb.WriteTombstone(ctx, bucketClient, userID, nil, pendingNotMatching))
+
+	processed, err := mimir_tsdb.NewTombstone(time.Now(), 400, 499, []string{`{series_id="0"}`})
+	require.NoError(t, err)
+	processed.UpdateState(mimir_tsdb.TombstoneProcessed, time.Now())
+	require.NoError(t, mimir_tsdb.WriteTombstone(ctx, bucketClient, userID, nil, processed))
+
+	newMeta := func(minT, maxT int64) *metadata.Meta {
+		id := createTSDBBlock(t, bucketClient, userID, minT, maxT, 2, nil)
+		meta, err := block.DownloadMeta(ctx, logger, userBucket, id)
+		require.NoError(t, err)
+		return &meta
+	}
+	// The block max time is exclusive, so the first block doesn't overlap with the pending request.
+	notOverlapping := newMeta(0, 100)
+	overlappingPending := newMeta(100, 200)
+	overlappingPendingNotMatching := newMeta(300, 400)
+	overlappingProcessed := newMeta(400, 500)
+
+	// A block which has already been rewritten with the pending request applied.
+	overlappingPendingApplied := newMeta(200, 300)
+	overlappingPendingApplied.Thanos.Rewrites = []metadata.Rewrite{{
+		DeletionsApplied: []metadata.DeletionRequest{{RequestID: pending.RequestID}},
+	}}
+
+	metas := map[ulid.ULID]*metadata.Meta{
+		notOverlapping.ULID:                notOverlapping,
+		overlappingPending.ULID:            overlappingPending,
+		overlappingPendingNotMatching.ULID: overlappingPendingNotMatching,
+		overlappingProcessed.ULID:          overlappingProcessed,
+		overlappingPendingApplied.ULID:     overlappingPendingApplied,
+	}
+
+	synced := extprom.NewTxGaugeVec(nil, prometheus.GaugeOpts{}, []string{"state"})
+	dir := t.TempDir()
+	f := NewPendingSeriesDeletionFilter(bucketClient, userBucket, userID, dir, logger)
+	require.NoError(t, f.Filter(ctx, metas, synced, nil))
+
+	assert.Equal(t, map[ulid.ULID]*metadata.Meta{
+		notOverlapping.ULID:                notOverlapping,
+		overlappingPendingNotMatching.ULID: overlappingPendingNotMatching,
+		overlappingProcessed.ULID:          overlappingProcessed,
+		overlappingPendingApplied.ULID:     overlappingPendingApplied,
+	}, metas)
+
+	// The index-headers are built only for the blocks with pending requests not yet applied.
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Len(t, entries, 2)
+
+	// Once there are no pending requests, the index-headers are removed.
+	pending.UpdateState(mimir_tsdb.TombstoneProcessed, time.Now())
+	require.NoError(t, mimir_tsdb.WriteTombstone(ctx, bucketClient, userID, nil, pending))
+	pendingNotMatching.UpdateState(mimir_tsdb.TombstoneProcessed, time.Now())
+	require.NoError(t, mimir_tsdb.WriteTombstone(ctx, bucketClient, userID, nil, pendingNotMatching))
+
+	require.NoError(t, f.Filter(ctx, metas, synced, nil))
+	entries, err = os.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+}
@@ -5,6 +5,7 @@ package compactor
 import (
 	"context"
 	"os"
+	"strconv"
 	"strings"
 	"testing"
 	"time"
@@ -16,6 +17,7 @@ import (
 	"github.com/oklog/ulid"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/prometheus/prometheus/model/labels"
 	"github.com/prometheus/prometheus/tsdb"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -24,7 +26,9 @@ import (
 	"github.com/thanos-io/thanos/pkg/objstore"
 
 	"github.com/grafana/mimir/pkg/storage/bucket"
+	"github.com/grafana/mimir/pkg/storage/sharding"
 	mimir_tsdb "github.com/grafana/mimir/pkg/storage/tsdb"
+	"github.com/grafana/mimir/pkg/storage/tsdb/exemplars"
 )
 
 func TestMultitenantCompactor_ShouldSupportSplitAndMergeCompactor(t *testing.T) {
@@ -674,6 +678,108 @@ func TestMultitenantCompactor_ShouldSupportSplitAndMergeCompactor(t *testing.T)
 	}
 }
 
+func TestMultitenantCompactor_ShouldCompactBlocksExemplars(t *testing.T) {
+	const (
+		userID     = "user-1"
+		numSeries  = 10
+		numShards  = 2
+		blockRange = 2 * time.Hour
+	)
+
+	var (
+		blockRangeMillis = blockRange.Milliseconds()
+		ctx              = context.Background()
+		storageDir       = t.TempDir()
+		fetcherDir       = t.TempDir()
+	)
+
+	storageCfg := mimir_tsdb.BlocksStorageConfig{}
+	flagext.DefaultValues(&storageCfg)
+	storageCfg.Bucket.Backend = bucket.Filesystem
+	storageCfg.Bucket.Filesystem.Directory = storageDir
+
+	compactorCfg := prepareConfig(t)
+	compactorCfg.DataDir = t.TempDir()
+	compactorCfg.BlockRanges = mimir_tsdb.DurationList{blockRange, 2 * blockRange}
+
+	cfgProvider := newMockConfigProvider()
+	cfgProvider.splitAndMergeShards[userID] = numShards
+
+	logger := log.NewNopLogger()
+	reg := prometheus.NewPedanticRegistry()
+
+	bucketClient, err := bucket.NewClient(ctx, storageCfg.Bucket, "test", logger, nil)
+	require.NoError(t, err)
+	userBucket := bucket.NewUserBucketClient(userID, bucketClient, nil)
+
+	// Create two overlapping blocks, each one with the exemplars of some series. The same
+	// exemplar is stored in both blocks.
+	exemplarOf := func(seriesID int, ts int64) exemplars.Series {
+		return exemplars.Series{
+			Labels: labels.FromStrings("series_id", strconv.Itoa(seriesID)),
+			Exemplars: []exemplars.Exemplar{
+				{Labels: labels.FromStrings("trace_id", strconv.Itoa(seriesID)), Value: 1, Timestamp: ts},
+			},
+		}
+	}
+
+	block1 := createTSDBBlock(t, bucketClient, userID, blockRangeMillis, 2*blockRangeMillis, numSeries, nil)
+	block2 := createTSDBBlock(t, bucketClient, userID, blockRangeMillis, 2*blockRangeMillis, numSeries, nil)
+
+	require.NoError(t, exemplars.Upload(ctx, userBucket, block1, &exemplars.File{
+		Version: exemplars.FileVersion1,
+		Series:  []exemplars.Series{exemplarOf(0, blockRangeMillis), exemplarOf(1, blockRangeMillis+1), exemplarOf(2, blockRangeMillis+2)},
+	}))
+	require.NoError(t, exemplars.Upload(ctx, userBucket, block2, &exemplars.File{
+		Version: exemplars.FileVersion1,
+		Series:  []exemplars.Series{exemplarOf(2, blockRangeMillis+2), exemplarOf(3, blockRangeMillis+3)},
+	}))
+
+	c, err := NewMultitenantCompactor(compactorCfg, storageCfg, cfgProvider, logger, reg)
+	require.NoError(t, err)
+	require.NoError(t, services.StartAndAwaitRunning(ctx, c))
+	t.Cleanup(func() {
+		require.NoError(t, services.StopAndAwaitTerminated(ctx, c))
+	})
+
+	// Wait until the first compaction run completed.
+	test.Poll(t, 15*time.Second, nil, func() interface{} {
+		return testutil.GatherAndCompare(reg, strings.NewReader(`
+			# HELP cortex_compactor_runs_completed_total Total number of compaction runs successfully completed.
+			# TYPE cortex_compactor_runs_completed_total counter
+			cortex_compactor_runs_completed_total 1
+		`), "cortex_compactor_runs_completed_total")
+	})
+
+	// List back any (non deleted) block from the storage.
+	fetcher, err := block.NewMetaFetcher(logger, 1, userBucket, fetcherDir, reg, []block.MetadataFilter{NewExcludeMarkedForDeletionFilter(userBucket)})
+	require.NoError(t, err)
+	metas, _, err := fetcher.Fetch(ctx)
+	require.NoError(t, err)
+	require.Len(t, metas, numShards)
+
+	// Each compacted block should hold the exemplars of the series belonging to its shard.
+	var actual []*exemplars.File
+	for id, meta := range metas {
+		shardIndex, shardCount, err := sharding.ParseShardIDLabelValue(meta.Thanos.Labels[mimir_tsdb.CompactorShardIDExternalLabel])
+		require.NoError(t, err)
+
+		f, err := exemplars.Read(ctx, userBucket, id, logger)
+		require.NoError(t, err)
+		require.NotNil(t, f)
+
+		for _, s := range f.Series {
+			assert.Equal(t, shardIndex, s.Labels.Hash()%shardCount)
+		}
+		actual = append(actual, f)
+	}
+
+	assert.Equal(t, &exemplars.File{
+		Version: exemplars.FileVersion1,
+		Series:  []exemplars.Series{exemplarOf(0, blockRangeMillis), exemplarOf(1, blockRangeMillis+1), exemplarOf(2, blockRangeMillis+2), exemplarOf(3, blockRangeMillis+3)},
+	}, exemplars.Merge(actual...))
+}
+
 func convertMetasMapToSlice(metas map[ulid.ULID]*metadata.Meta) []*metadata.Meta {
 	var out []*metadata.Meta
 	for _, m := range metas {
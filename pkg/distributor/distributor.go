@@ -100,8 +100,9 @@ type Distributor struct {
 	HATracker *haTracker
 
 	// Per-user rate limiters.
-	requestRateLimiter   *limiter.RateLimiter
-	ingestionRateLimiter *limiter.RateLimiter
+	requestRateLimiter        *limiter.RateLimiter
+	ingestionRateLimiter      *limiter.RateLimiter
+	ingestionBytesRateLimiter *limiter.RateLimiter
 
 	// Manager for subservices (HA Tracker, distributor ring and client pool)
 	subservices        *services.Manager
@@ -185,6 +186,14 @@ func (cfg *Config) Validate(limits validation.Limits) error {
 		return errInvalidTenantShardSize
 	}
 
+	if err := cfg.Forwarding.Validate(); err != nil {
+		return err
+	}
+
+	if err := limits.ValidateHADeduplicationMode(); err != nil {
+		return err
+	}
+
 	return cfg.HATrackerConfig.Validate()
 }
 
@@ -219,7 +228,7 @@ func New(cfg Config, clientConfig ingester_client.Config, limits *validation.Ove
 		ingesterPool:          NewPool(cfg.PoolConfig, ingestersRing, cfg.IngesterClientFactory, log),
 		healthyInstancesCount: atomic.NewUint32(0),
 		limits:                limits,
-		forwarder:             forwarding.NewForwarder(reg, cfg.Forwarding),
+		forwarder:             forwarding.NewForwarder(reg, cfg.Forwarding, log),
 		HATracker:             haTracker,
 		ingestionRate:         util_math.NewEWMARate(0.2, instanceIngestionRateTickInterval),
 
@@ -332,13 +341,14 @@ func New(cfg Config, clientConfig ingester_client.Config, limits *validation.Ove
 	// Create the configured ingestion rate limit strategy (local or global). In case
 	// it's an internal dependency and we can't join the distributors ring, we skip rate
 	// limiting.
-	var ingestionRateStrategy, requestRateStrategy limiter.RateLimiterStrategy
+	var ingestionRateStrategy, ingestionBytesRateStrategy, requestRateStrategy limiter.RateLimiterStrategy
 	var distributorsLifecycler *ring.BasicLifecycler
 	var distributorsRing *ring.Ring
 
 	if !canJoinDistributorsRing {
 		requestRateStrategy = newInfiniteRateStrategy()
 		ingestionRateStrategy = newInfiniteRateStrategy()
+		ingestionBytesRateStrategy = newInfiniteRateStrategy()
 	} else {
 		distributorsRing, distributorsLifecycler, err = newRingAndLifecycler(cfg.DistributorRing, d.healthyInstancesCount, log, reg)
 		if err != nil {
@@ -348,10 +358,12 @@ func New(cfg Config, clientConfig ingester_client.Config, limits *validation.Ove
 		subservices = append(subservices, distributorsLifecycler, distributorsRing)
 		requestRateStrategy = newGlobalRateStrategy(newRequestRateStrategy(limits), d)
 		ingestionRateStrategy = newGlobalRateStrategy(newIngestionRateStrategy(limits), d)
+		ingestionBytesRateStrategy = newGlobalRateStrategy(newIngestionBytesRateStrategy(limits), d)
 	}
 
 	d.requestRateLimiter = limiter.NewRateLimiter(requestRateStrategy, 10*time.Second)
 	d.ingestionRateLimiter = limiter.NewRateLimiter(ingestionRateStrategy, 10*time.Second)
+	d.ingestionBytesRateLimiter = limiter.NewRateLimiter(ingestionBytesRateStrategy, 10*time.Second)
 	d.distributorsLifecycler = distributorsLifecycler
 	d.distributorsRing = distributorsRing
 
@@ -359,6 +371,9 @@ func New(cfg Config, clientConfig ingester_client.Config, limits *validation.Ove
 	d.activeUsers = util.NewActiveUsersCleanupWithDefaultValues(d.cleanupInactiveUser)
 
 	subservices = append(subservices, d.ingesterPool, d.activeUsers)
+	if d.forwarder != nil {
+		subservices = append(subservices, d.forwarder)
+	}
 	d.subservices, err = services.NewManager(subservices...)
 	if err != nil {
 		return nil, err
@@ -506,18 +521,30 @@ func removeLabel(labelName string, labels *[]mimirpb.LabelAdapter) {
 	}
 }
 
+// addDefaultLabels adds the default labels to a slice of LabelPairs which doesn't have them, or has them with an empty value.
+func addDefaultLabels(defaults map[string]string, labels *[]mimirpb.LabelAdapter) {
+	for name, value := range defaults {
+		found := false
+		for i := range *labels {
+			if (*labels)[i].Name == name {
+				if (*labels)[i].Value == "" {
+					(*labels)[i].Value = value
+				}
+				found = true
+				break
+			}
+		}
+		if !found {
+			*labels = append(*labels, mimirpb.LabelAdapter{Name: name, Value: value})
+		}
+	}
+}
+
 // Returns a boolean that indicates whether or not we want to remove the replica label going forward,
 // and an error that indicates whether we want to accept samples based on the cluster/replica found in ts.
 // nil for the error means accept the sample.
 func (d *Distributor) checkSample(ctx context.Context, userID, cluster, replica string) (removeReplicaLabel bool, _ error) {
-	// If the sample doesn't have either HA label, accept it.
-	// At the moment we want to accept these samples by default.
-	if cluster == "" || replica == "" {
-		return false, nil
-	}
-
-	// If replica label is too long, don't use it. We accept the sample here, but it will fail validation later anyway.
-	if len(replica) > d.limits.MaxLabelValueLength(userID) {
+	if !d.hasValidHALabels(userID, cluster, replica) {
 		return false, nil
 	}
 
@@ -531,6 +558,18 @@ func (d *Distributor) checkSample(ctx context.Context, userID, cluster, replica
 	return true, nil
 }
 
+// hasValidHALabels returns whether both HA labels have been found, and the replica can be used to deduplicate samples.
+func (d *Distributor) hasValidHALabels(userID, cluster, replica string) bool {
+	// If the sample doesn't have either HA label, accept it.
+	// At the moment we want to accept these samples by default.
+	if cluster == "" || replica == "" {
+		return false
+	}
+
+	// If replica label is too long, don't use it. We accept the sample here, but it will fail validation later anyway.
+	return len(replica) <= d.limits.MaxLabelValueLength(userID)
+}
+
 // Validates a single series from a write request.
 // May alter timeseries data in-place.
 // The returned error may retain the series labels.
@@ -540,6 +579,10 @@ func (d *Distributor) validateSeries(nowt time.Time, ts mimirpb.PreallocTimeseri
 		return err
 	}
 
+	if err := validation.ValidateLabelPolicies(d.limits, userID, ts.Labels); err != nil {
+		return err
+	}
+
 	now := model.TimeFromUnixNano(nowt.UnixNano())
 
 	for _, s := range ts.Samples {
@@ -653,6 +696,7 @@ func (d *Distributor) PushWithCleanup(ctx context.Context, req *mimirpb.WriteReq
 
 	var firstPartialErr error
 	removeReplica := false
+	haReplica := ""
 
 	numSamples := 0
 	numExemplars := 0
@@ -684,7 +728,14 @@ func (d *Distributor) PushWithCleanup(ctx context.Context, req *mimirpb.WriteReq
 			span.SetTag("cluster", cluster)
 			span.SetTag("replica", replica)
 		}
-		removeReplica, err = d.checkSample(ctx, userID, cluster, replica)
+		if d.limits.HADeduplicationMode(userID) == validation.HADeduplicationModeIngester {
+			// The samples of all the replicas are sent to the ingesters, which deduplicate them per series.
+			if removeReplica = d.hasValidHALabels(userID, cluster, replica); removeReplica {
+				haReplica = replica
+			}
+		} else {
+			removeReplica, err = d.checkSample(ctx, userID, cluster, replica)
+		}
 		if err != nil {
 			if errors.Is(err, replicasNotMatchError{}) {
 				// These samples have been deduped.
@@ -750,6 +801,9 @@ func (d *Distributor) PushWithCleanup(ctx context.Context, req *mimirpb.WriteReq
 			continue
 		}
 
+		// The default labels are added before computing the sharding token, since they're part of the series.
+		addDefaultLabels(d.limits.DefaultLabels(userID), &ts.Labels)
+
 		// We rely on sorted labels in different places:
 		// 1) When computing token for labels, and sharding by all labels. Here different order of labels returns
 		// different tokens, which is bad.
@@ -844,6 +898,16 @@ func (d *Distributor) PushWithCleanup(ctx context.Context, req *mimirpb.WriteReq
 		return nil, httpgrpc.Errorf(http.StatusTooManyRequests, validation.NewIngestionRateLimitedError(d.limits.IngestionRate(userID), d.limits.IngestionBurstSize(userID)).Error())
 	}
 
+	// Computing the size of the request is not free, so it's skipped if the ingestion bytes rate limit is disabled.
+	if d.limits.IngestionBytesRate(userID) > 0 {
+		if totalBytes := ingestedBytes(validatedTimeseries, validatedMetadata); !d.ingestionBytesRateLimiter.AllowN(now, userID, totalBytes) {
+			validation.DiscardedSamples.WithLabelValues(validation.ReasonBytesRateLimited, userID).Add(float64(validatedSamples))
+			validation.DiscardedExemplars.WithLabelValues(validation.ReasonBytesRateLimited, userID).Add(float64(validatedExemplars))
+			validation.DiscardedMetadata.WithLabelValues(validation.ReasonBytesRateLimited, userID).Add(float64(len(validatedMetadata)))
+			return nil, httpgrpc.Errorf(http.StatusTooManyRequests, validation.NewIngestionBytesRateLimitedError(d.limits.IngestionBytesRate(userID), d.limits.IngestionBytesBurstSize(userID)).Error())
+		}
+	}
+
 	// totalN included samples and metadata. Ingester follows this pattern when computing its ingestion rate.
 	d.ingestionRate.Add(int64(totalN))
 
@@ -878,7 +942,7 @@ func (d *Distributor) PushWithCleanup(ctx context.Context, req *mimirpb.WriteReq
 			}
 		}
 
-		return d.send(localCtx, ingester, timeseries, metadata, req.Source)
+		return d.send(localCtx, ingester, timeseries, metadata, req.Source, haReplica)
 	}, func() { cleanup(); cancel() })
 
 	if forwardingErrCh != nil {
@@ -893,6 +957,18 @@ func (d *Distributor) PushWithCleanup(ctx context.Context, req *mimirpb.WriteReq
 	return &mimirpb.WriteResponse{}, firstPartialErr
 }
 
+// ingestedBytes returns the size of the series and metadata, as it's applied to the ingestion bytes rate limit.
+func ingestedBytes(timeseries []mimirpb.PreallocTimeseries, metadata []*mimirpb.MetricMetadata) int {
+	n := 0
+	for _, ts := range timeseries {
+		n += ts.Size()
+	}
+	for _, m := range metadata {
+		n += m.Size()
+	}
+	return n
+}
+
 func copyString(s string) string {
 	return string([]byte(s))
 }
@@ -919,7 +995,7 @@ func sortLabelsIfNeeded(labels []mimirpb.LabelAdapter) {
 	})
 }
 
-func (d *Distributor) send(ctx context.Context, ingester ring.InstanceDesc, timeseries []mimirpb.PreallocTimeseries, metadata []*mimirpb.MetricMetadata, source mimirpb.WriteRequest_SourceEnum) error {
+func (d *Distributor) send(ctx context.Context, ingester ring.InstanceDesc, timeseries []mimirpb.PreallocTimeseries, metadata []*mimirpb.MetricMetadata, source mimirpb.WriteRequest_SourceEnum, haReplica string) error {
 	h, err := d.ingesterPool.GetClientFor(ingester.Addr)
 	if err != nil {
 		return err
@@ -930,6 +1006,7 @@ func (d *Distributor) send(ctx context.Context, ingester ring.InstanceDesc, time
 		Timeseries: timeseries,
 		Metadata:   metadata,
 		Source:     source,
+		HaReplica:  haReplica,
 	}
 	_, err = c.Push(ctx, &req)
 
@@ -1091,6 +1168,97 @@ func (m *labelNamesAndValuesResponseMerger) putItemsToMap(message *ingester_clie
 	return nil
 }
 
+// ActiveSeries queries ingesters for the active series matching the matchers, and returns
+// the distinct series across all the ingesters.
+func (d *Distributor) ActiveSeries(ctx context.Context, matchers []*labels.Matcher) ([]labels.Labels, error) {
+	replicationSet, err := d.GetIngestersForMetadata(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	matchersProto, err := ingester_client.ToLabelMatchers(matchers)
+	if err != nil {
+		return nil, err
+	}
+	req := &ingester_client.ActiveSeriesRequest{Matchers: matchersProto}
+
+	userID, err := tenant.TenantID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	sizeLimitBytes := d.limits.ActiveSeriesResultsMaxSizeBytes(userID)
+	merger := &activeSeriesResponseMerger{result: map[uint64]labels.Labels{}, sizeLimitBytes: sizeLimitBytes}
+	_, err = d.ForReplicationSet(ctx, replicationSet, func(ctx context.Context, client ingester_client.IngesterClient) (interface{}, error) {
+		stream, err := client.ActiveSeries(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+		defer stream.CloseSend() //nolint:errcheck
+		return nil, merger.collectResponses(stream)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return merger.toSeries(), nil
+}
+
+type activeSeriesResponseMerger struct {
+	lock             sync.Mutex
+	result           map[uint64]labels.Labels
+	sizeLimitBytes   int
+	currentSizeBytes int
+}
+
+// toSeries returns the distinct series received from the ingesters.
+func (m *activeSeriesResponseMerger) toSeries() []labels.Labels {
+	// Some ingesters responses might still be processed if ForReplicationSet() returned
+	// once it got enough responses, so we need to acquire the lock.
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	result := make([]labels.Labels, 0, len(m.result))
+	for _, series := range m.result {
+		result = append(result, series)
+	}
+	return result
+}
+
+// collectResponses listens for the stream and once the message is received, puts the series to the map with distinct series.
+func (m *activeSeriesResponseMerger) collectResponses(stream ingester_client.Ingester_ActiveSeriesClient) error {
+	for {
+		message, err := stream.Recv()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return err
+		}
+		err = m.putSeriesToMap(message)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (m *activeSeriesResponseMerger) putSeriesToMap(message *ingester_client.ActiveSeriesResponse) error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	for _, metric := range message.Metric {
+		series := mimirpb.FromLabelAdaptersToLabels(metric.Labels)
+		fp := series.Hash()
+		if _, exists := m.result[fp]; exists {
+			continue
+		}
+		for _, l := range series {
+			m.currentSizeBytes += len(l.Name) + len(l.Value)
+		}
+		if m.currentSizeBytes > m.sizeLimitBytes {
+			return fmt.Errorf("size of distinct active series is greater than %v bytes", m.sizeLimitBytes)
+		}
+		m.result[fp] = series
+	}
+	return nil
+}
+
 // LabelValuesCardinality performs the following two operations in parallel:
 //  * queries ingesters for label values cardinality of a set of labelNames
 //  * queries ingesters for user stats to get the ingester's series head count
@@ -1249,6 +1417,120 @@ func (cm *labelValuesCardinalityConcurrentMap) toLabelValuesCardinalityResponse(
 	}
 }
 
+// TSDBStatus queries ingesters for the cardinality statistics of their TSDB head, and merges them.
+// Each ingester only returns its top `limit` items of each list of statistics, so the merged
+// statistics are an approximation when the top items differ between ingesters.
+func (d *Distributor) TSDBStatus(ctx context.Context, limit int) (*ingester_client.TSDBStatusResponse, error) {
+	replicationSet, err := d.GetIngestersForMetadata(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// Make sure we get a successful response from all the ingesters
+	replicationSet.MaxErrors = 0
+	replicationSet.MaxUnavailableZones = 0
+
+	req := &ingester_client.TSDBStatusRequest{Limit: int32(limit)}
+	resps, err := d.ForReplicationSet(ctx, replicationSet, func(ctx context.Context, client ingester_client.IngesterClient) (interface{}, error) {
+		return client.TSDBStatus(ctx, req)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	merger := newTSDBStatusMerger()
+	for _, resp := range resps {
+		merger.add(resp.(*ingester_client.TSDBStatusResponse))
+	}
+	return merger.toTSDBStatusResponse(d.ingestersRing.ReplicationFactor(), limit), nil
+}
+
+// tsdbStatusMerger merges the TSDB statistics of multiple ingesters. The series counts are summed up
+// and then adjusted to the replication factor. The label values counts can't be summed up, because
+// the same value is stored in multiple ingesters, so the ones of the ingester with the most are kept.
+type tsdbStatusMerger struct {
+	numSeries     uint64
+	numLabelPairs uint64
+	chunkCount    uint64
+	minTime       int64
+	maxTime       int64
+
+	seriesCountByMetricName     map[string]uint64
+	labelValueCountByLabelName  map[string]uint64
+	memoryInBytesByLabelName    map[string]uint64
+	seriesCountByLabelValuePair map[string]uint64
+}
+
+func newTSDBStatusMerger() *tsdbStatusMerger {
+	return &tsdbStatusMerger{
+		minTime:                     math.MaxInt64,
+		maxTime:                     math.MinInt64,
+		seriesCountByMetricName:     map[string]uint64{},
+		labelValueCountByLabelName:  map[string]uint64{},
+		memoryInBytesByLabelName:    map[string]uint64{},
+		seriesCountByLabelValuePair: map[string]uint64{},
+	}
+}
+
+func (m *tsdbStatusMerger) add(resp *ingester_client.TSDBStatusResponse) {
+	if resp.NumSeries == 0 {
+		return
+	}
+
+	m.numSeries += resp.NumSeries
+	m.chunkCount += resp.ChunkCount
+	m.minTime = util_math.Min64(m.minTime, resp.MinTime)
+	m.maxTime = util_math.Max64(m.maxTime, resp.MaxTime)
+	if resp.NumLabelPairs > m.numLabelPairs {
+		m.numLabelPairs = resp.NumLabelPairs
+	}
+
+	for _, item := range resp.SeriesCountByMetricName {
+		m.seriesCountByMetricName[item.Name] += item.Value
+	}
+	for _, item := range resp.SeriesCountByLabelValuePair {
+		m.seriesCountByLabelValuePair[item.Name] += item.Value
+	}
+	for _, item := range resp.LabelValueCountByLabelName {
+		if item.Value > m.labelValueCountByLabelName[item.Name] {
+			m.labelValueCountByLabelName[item.Name] = item.Value
+		}
+	}
+	for _, item := range resp.MemoryInBytesByLabelName {
+		if item.Value > m.memoryInBytesByLabelName[item.Name] {
+			m.memoryInBytesByLabelName[item.Name] = item.Value
+		}
+	}
+}
+
+func (m *tsdbStatusMerger) toTSDBStatusResponse(replicationFactor, limit int) *ingester_client.TSDBStatusResponse {
+	rf := uint64(replicationFactor)
+	resp := &ingester_client.TSDBStatusResponse{
+		NumSeries:                   m.numSeries / rf,
+		NumLabelPairs:               m.numLabelPairs,
+		ChunkCount:                  m.chunkCount / rf,
+		SeriesCountByMetricName:     dividedTSDBStatisticItems(m.seriesCountByMetricName, rf, limit),
+		LabelValueCountByLabelName:  dividedTSDBStatisticItems(m.labelValueCountByLabelName, 1, limit),
+		MemoryInBytesByLabelName:    dividedTSDBStatisticItems(m.memoryInBytesByLabelName, 1, limit),
+		SeriesCountByLabelValuePair: dividedTSDBStatisticItems(m.seriesCountByLabelValuePair, rf, limit),
+	}
+	if m.numSeries > 0 {
+		resp.MinTime = m.minTime
+		resp.MaxTime = m.maxTime
+	}
+	return resp
+}
+
+// dividedTSDBStatisticItems divides the values by the divisor, and returns the first `limit` items sorted
+// in descending order by value and ascending order by name.
+func dividedTSDBStatisticItems(values map[string]uint64, divisor uint64, limit int) []ingester_client.TSDBStatisticItem {
+	items := make([]ingester_client.TSDBStatisticItem, 0, len(values))
+	for name, value := range values {
+		items = append(items, ingester_client.TSDBStatisticItem{Name: name, Value: value / divisor})
+	}
+	return ingester_client.TopTSDBStatisticItems(items, limit)
+}
+
 // LabelNames returns all of the label names.
 func (d *Distributor) LabelNames(ctx context.Context, from, to model.Time, matchers ...*labels.Matcher) ([]string, error) {
 	replicationSet, err := d.GetIngestersForMetadata(ctx)
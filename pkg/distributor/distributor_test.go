@@ -595,6 +595,66 @@ func TestDistributor_PushIngestionRateLimiter(t *testing.T) {
 	}
 }
 
+func TestDistributor_PushIngestionBytesRateLimiter(t *testing.T) {
+	ctx := user.InjectOrgID(context.Background(), "user")
+
+	// All the pushes send the same request, so they all have the same size.
+	request := makeWriteRequest(0, 2, 1, false)
+	requestSize := ingestedBytes(request.Timeseries, request.Metadata)
+
+	tests := map[string]struct {
+		ingestionBytesRate      float64
+		ingestionBytesBurstSize int
+		expectedErrors          []error
+	}{
+		"ingestion bytes limit is disabled when set to 0": {
+			ingestionBytesRate:      0,
+			ingestionBytesBurstSize: requestSize,
+			expectedErrors:          []error{nil, nil, nil},
+		},
+		"ingestion bytes limit is enforced with the burst size": {
+			// A very low rate, so that no token is replenished during the test.
+			ingestionBytesRate:      0.001,
+			ingestionBytesBurstSize: 2 * requestSize,
+			expectedErrors: []error{
+				nil,
+				nil,
+				httpgrpc.Errorf(http.StatusTooManyRequests, validation.NewIngestionBytesRateLimitedError(0.001, 2*requestSize).Error()),
+			},
+		},
+	}
+
+	for testName, testData := range tests {
+		testData := testData
+
+		t.Run(testName, func(t *testing.T) {
+			limits := &validation.Limits{}
+			flagext.DefaultValues(limits)
+			limits.IngestionBytesRate = testData.ingestionBytesRate
+			limits.IngestionBytesBurstSize = testData.ingestionBytesBurstSize
+
+			distributors, _, _ := prepare(t, prepConfig{
+				numIngesters:    3,
+				happyIngesters:  3,
+				numDistributors: 1,
+				limits:          limits,
+			})
+
+			for _, expectedErr := range testData.expectedErrors {
+				response, err := distributors[0].Push(ctx, makeWriteRequest(0, 2, 1, false))
+
+				if expectedErr == nil {
+					assert.Equal(t, emptyResponse, response)
+					assert.Nil(t, err)
+				} else {
+					assert.Nil(t, response)
+					assert.Equal(t, expectedErr, err)
+				}
+			}
+		})
+	}
+}
+
 func TestDistributor_PushInstanceLimits(t *testing.T) {
 	type testPush struct {
 		samples       int
@@ -832,6 +892,50 @@ func TestDistributor_PushHAInstances(t *testing.T) {
 	}
 }
 
+func TestDistributor_PushHAInstances_IngesterDeduplicationMode(t *testing.T) {
+	ctx := user.InjectOrgID(context.Background(), "user")
+
+	var limits validation.Limits
+	flagext.DefaultValues(&limits)
+	limits.AcceptHASamples = true
+	limits.HADeduplicationMode = validation.HADeduplicationModeIngester
+
+	ds, ingesters, _ := prepare(t, prepConfig{
+		numIngesters:      1,
+		happyIngesters:    1,
+		numDistributors:   1,
+		replicationFactor: 1,
+		limits:            &limits,
+		enableTracker:     true,
+	})
+	d := ds[0]
+
+	// The elected replica doesn't matter in the ingester deduplication mode.
+	require.NoError(t, d.HATracker.checkReplica(ctx, "user", "cluster0", "instance0", time.Now()))
+
+	for _, replica := range []string{"instance0", "instance1"} {
+		response, err := d.Push(ctx, makeWriteRequestHA(5, replica, "cluster0"))
+		require.NoError(t, err)
+		assert.Equal(t, emptyResponse, response)
+
+		assert.Equal(t, replica, ingesters[0].haReplica)
+	}
+
+	// The samples of both replicas have been sent to the same series, without the replica label.
+	series := ingesters[0].series()
+	require.Len(t, series, 5)
+	for _, ts := range series {
+		assert.Equal(t, "", mimirpb.FromLabelAdaptersToLabels(ts.Labels).Get("__replica__"))
+		assert.Len(t, ts.Samples, 2)
+	}
+
+	// The series without both HA labels are sent without replica.
+	response, err := d.Push(ctx, makeWriteRequestHA(5, "instance0", ""))
+	require.NoError(t, err)
+	assert.Equal(t, emptyResponse, response)
+	assert.Equal(t, "", ingesters[0].haReplica)
+}
+
 func TestDistributor_PushQuery(t *testing.T) {
 	ctx := user.InjectOrgID(context.Background(), "user")
 	nameMatcher := mustEqualMatcher(model.MetricNameLabel, "foo")
@@ -1237,6 +1341,88 @@ func TestDistributor_Push_LabelRemoval(t *testing.T) {
 	}
 }
 
+func TestDistributor_Push_LabelPolicies(t *testing.T) {
+	ctx := user.InjectOrgID(context.Background(), "user")
+
+	tests := map[string]struct {
+		inputSeries    labels.Labels
+		expectedSeries labels.Labels
+		expectedErr    string
+	}{
+		"default labels are added to the series which don't have them": {
+			inputSeries: labels.Labels{
+				{Name: "__name__", Value: "some_metric"},
+				{Name: "team", Value: "payments"},
+			},
+			expectedSeries: labels.Labels{
+				{Name: "__name__", Value: "some_metric"},
+				{Name: "env", Value: "prod"},
+				{Name: "team", Value: "payments"},
+			},
+		},
+		"default labels don't override the series labels": {
+			inputSeries: labels.Labels{
+				{Name: "__name__", Value: "some_metric"},
+				{Name: "env", Value: "staging"},
+				{Name: "team", Value: "payments"},
+			},
+			expectedSeries: labels.Labels{
+				{Name: "__name__", Value: "some_metric"},
+				{Name: "env", Value: "staging"},
+				{Name: "team", Value: "payments"},
+			},
+		},
+		"series missing a required label are rejected": {
+			inputSeries: labels.Labels{
+				{Name: "__name__", Value: "some_metric"},
+				{Name: "env", Value: "prod"},
+			},
+			expectedErr: "received a series without the required label: 'team'",
+		},
+		"series with a label value not allowed are rejected": {
+			inputSeries: labels.Labels{
+				{Name: "__name__", Value: "some_metric"},
+				{Name: "env", Value: "qa"},
+				{Name: "team", Value: "payments"},
+			},
+			expectedErr: "received a series whose label value is not allowed, label: 'env' value: 'qa'",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			var limits validation.Limits
+			flagext.DefaultValues(&limits)
+			limits.RequiredLabels = []string{"team", "env"}
+			limits.DefaultLabels = map[string]string{"env": "prod"}
+			limits.AllowedLabelValues = map[string][]string{"env": {"prod", "staging"}}
+
+			ds, ingesters, _ := prepare(t, prepConfig{
+				numIngesters:    2,
+				happyIngesters:  2,
+				numDistributors: 1,
+				limits:          &limits,
+			})
+
+			_, err := ds[0].Push(ctx, mockWriteRequest(tc.inputSeries, 1, 1))
+			if tc.expectedErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tc.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+
+			for i := range ingesters {
+				timeseries := ingesters[i].series()
+				assert.Equal(t, 1, len(timeseries))
+				for _, v := range timeseries {
+					assert.Equal(t, tc.expectedSeries, mimirpb.FromLabelAdaptersToLabels(v.Labels))
+				}
+			}
+		})
+	}
+}
+
 func TestDistributor_Push_ShouldGuaranteeShardingTokenConsistencyOverTheTime(t *testing.T) {
 	ctx := user.InjectOrgID(context.Background(), "user")
 	tests := map[string]struct {
@@ -2607,6 +2793,160 @@ func TestDistributor_LabelValuesCardinality(t *testing.T) {
 	}
 }
 
+func TestDistributor_TSDBStatus(t *testing.T) {
+	const numIngesters = 3
+	const replicationFactor = 3
+
+	fixtures := []struct {
+		labels    labels.Labels
+		value     float64
+		timestamp int64
+	}{
+		{labels.Labels{{Name: labels.MetricName, Value: "test_1"}, {Name: "status", Value: "200"}}, 1, 100000},
+		{labels.Labels{{Name: labels.MetricName, Value: "test_1"}, {Name: "status", Value: "500"}, {Name: "reason", Value: "broken"}}, 1, 110000},
+		{labels.Labels{{Name: labels.MetricName, Value: "test_2"}}, 2, 200000},
+	}
+
+	tests := map[string]struct {
+		pushFixtures   bool
+		limit          int
+		expectedResult *client.TSDBStatusResponse
+	}{
+		"should return empty statistics if the tenant has no series": {
+			limit: 10,
+			expectedResult: &client.TSDBStatusResponse{
+				SeriesCountByMetricName:     []client.TSDBStatisticItem{},
+				LabelValueCountByLabelName:  []client.TSDBStatisticItem{},
+				MemoryInBytesByLabelName:    []client.TSDBStatisticItem{},
+				SeriesCountByLabelValuePair: []client.TSDBStatisticItem{},
+			},
+		},
+		"should merge the statistics of the ingesters and adjust the series counts to the replication factor": {
+			pushFixtures: true,
+			limit:        2,
+			expectedResult: &client.TSDBStatusResponse{
+				NumSeries:     3,
+				NumLabelPairs: 5,
+				ChunkCount:    3,
+				MinTime:       100000,
+				MaxTime:       200000,
+				SeriesCountByMetricName: []client.TSDBStatisticItem{
+					{Name: "test_1", Value: 2},
+					{Name: "test_2", Value: 1},
+				},
+				LabelValueCountByLabelName: []client.TSDBStatisticItem{
+					{Name: labels.MetricName, Value: 2},
+					{Name: "status", Value: 2},
+				},
+				MemoryInBytesByLabelName: []client.TSDBStatisticItem{
+					{Name: labels.MetricName, Value: 12},
+					{Name: "reason", Value: 6},
+				},
+				SeriesCountByLabelValuePair: []client.TSDBStatisticItem{
+					{Name: "__name__=test_1", Value: 2},
+					{Name: "__name__=test_2", Value: 1},
+				},
+			},
+		},
+	}
+
+	for testName, testData := range tests {
+		t.Run(testName, func(t *testing.T) {
+			ds, ingesters, _ := prepare(t, prepConfig{
+				numIngesters:      numIngesters,
+				happyIngesters:    numIngesters,
+				numDistributors:   1,
+				replicationFactor: replicationFactor,
+			})
+
+			ctx := user.InjectOrgID(context.Background(), "tsdb-status")
+
+			if testData.pushFixtures {
+				for _, series := range fixtures {
+					req := mockWriteRequest(series.labels, series.value, series.timestamp)
+					_, err := ds[0].Push(ctx, req)
+					require.NoError(t, err)
+				}
+			}
+
+			// Since the Push() response is sent as soon as the quorum is reached, when we reach this point
+			// the final ingester may not have received series yet.
+			test.Poll(t, time.Second, testData.expectedResult, func() interface{} {
+				res, err := ds[0].TSDBStatus(ctx, testData.limit)
+				require.NoError(t, err)
+				return res
+			})
+
+			// Make sure all the ingesters have been queried
+			assert.Equal(t, numIngesters, countMockIngestersCalls(ingesters, "TSDBStatus"))
+		})
+	}
+}
+
+func TestDistributor_ActiveSeries(t *testing.T) {
+	const numIngesters = 3
+	const replicationFactor = 3
+
+	series1 := labels.Labels{{Name: labels.MetricName, Value: "test_1"}, {Name: "status", Value: "200"}}
+	series2 := labels.Labels{{Name: labels.MetricName, Value: "test_1"}, {Name: "status", Value: "500"}}
+	series3 := labels.Labels{{Name: labels.MetricName, Value: "test_2"}}
+
+	tests := map[string]struct {
+		matchers       []*labels.Matcher
+		sizeLimitBytes int
+		expectedSeries []labels.Labels
+		expectedError  string
+	}{
+		"should return the distinct series matching the matchers": {
+			matchers:       []*labels.Matcher{mustNewMatcher(labels.MatchEqual, model.MetricNameLabel, "test_1")},
+			expectedSeries: []labels.Labels{series1, series2},
+		},
+		"should return all the series if the matchers select all of them": {
+			matchers:       []*labels.Matcher{mustNewMatcher(labels.MatchRegexp, model.MetricNameLabel, "test_.*")},
+			expectedSeries: []labels.Labels{series1, series2, series3},
+		},
+		"should return an error if the size limit is reached": {
+			matchers:       []*labels.Matcher{mustNewMatcher(labels.MatchEqual, model.MetricNameLabel, "test_1")},
+			sizeLimitBytes: 30,
+			expectedError:  "size of distinct active series is greater than 30 bytes",
+		},
+	}
+
+	for testName, testData := range tests {
+		t.Run(testName, func(t *testing.T) {
+			limits := validation.Limits{}
+			flagext.DefaultValues(&limits)
+			if testData.sizeLimitBytes > 0 {
+				limits.ActiveSeriesResultsMaxSizeBytes = testData.sizeLimitBytes
+			}
+			ds, _, _ := prepare(t, prepConfig{
+				numIngesters:      numIngesters,
+				happyIngesters:    numIngesters,
+				numDistributors:   1,
+				replicationFactor: replicationFactor,
+				limits:            &limits,
+			})
+
+			ctx := user.InjectOrgID(context.Background(), "active-series")
+
+			for _, series := range []labels.Labels{series1, series2, series3} {
+				_, err := ds[0].Push(ctx, mockWriteRequest(series, 1, 100000))
+				require.NoError(t, err)
+			}
+
+			// Each series has been written to a quorum of ingesters, so the merged response of
+			// a quorum of ingesters includes all of them, even if the final ingester is lagging.
+			res, err := ds[0].ActiveSeries(ctx, testData.matchers)
+			if testData.expectedError != "" {
+				require.EqualError(t, err, testData.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			assert.ElementsMatch(t, testData.expectedSeries, res)
+		})
+	}
+}
+
 func TestDistributor_LabelValuesCardinalityLimit(t *testing.T) {
 	fixtures := []struct {
 		labels    labels.Labels
@@ -3041,6 +3381,7 @@ type mockIngester struct {
 	seriesCountTotal uint64
 	zone             string
 	responseDelay    time.Duration
+	haReplica        string
 }
 
 func (i *mockIngester) series() map[uint32]*mimirpb.PreallocTimeseries {
@@ -3077,6 +3418,8 @@ func (i *mockIngester) Push(ctx context.Context, req *mimirpb.WriteRequest, opts
 		return nil, errFail
 	}
 
+	i.haReplica = req.HaReplica
+
 	if i.timeseries == nil {
 		i.timeseries = map[uint32]*mimirpb.PreallocTimeseries{}
 	}
@@ -3436,6 +3779,93 @@ func (i *mockIngester) AllUserStats(ctx context.Context, in *client.UserStatsReq
 	return &i.stats, nil
 }
 
+func (i *mockIngester) ActiveSeries(ctx context.Context, req *client.ActiveSeriesRequest, opts ...grpc.CallOption) (client.Ingester_ActiveSeriesClient, error) {
+	i.Lock()
+	defer i.Unlock()
+
+	i.trackCall("ActiveSeries")
+
+	if !i.happy {
+		return nil, errFail
+	}
+
+	matchers, err := client.FromLabelMatchers(req.Matchers)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &client.ActiveSeriesResponse{}
+	for _, ts := range i.timeseries {
+		if match(ts.Labels, matchers) {
+			resp.Metric = append(resp.Metric, &mimirpb.Metric{Labels: ts.Labels})
+		}
+	}
+	return &activeSeriesMockStream{responses: []*client.ActiveSeriesResponse{resp}}, nil
+}
+
+type activeSeriesMockStream struct {
+	grpc.ClientStream
+	responses []*client.ActiveSeriesResponse
+	i         int
+}
+
+func (*activeSeriesMockStream) CloseSend() error {
+	return nil
+}
+
+func (s *activeSeriesMockStream) Recv() (*client.ActiveSeriesResponse, error) {
+	if s.i >= len(s.responses) {
+		return nil, io.EOF
+	}
+	result := s.responses[s.i]
+	s.i++
+	return result, nil
+}
+
+func (i *mockIngester) TSDBStatus(ctx context.Context, req *client.TSDBStatusRequest, opts ...grpc.CallOption) (*client.TSDBStatusResponse, error) {
+	i.Lock()
+	defer i.Unlock()
+
+	i.trackCall("TSDBStatus")
+
+	if !i.happy {
+		return nil, errFail
+	}
+
+	resp := &client.TSDBStatusResponse{MinTime: math.MaxInt64, MaxTime: math.MinInt64}
+	seriesByLabelPair := map[string]map[string]uint64{}
+	for _, ts := range i.timeseries {
+		resp.NumSeries++
+		resp.ChunkCount++
+		for _, s := range ts.Samples {
+			resp.MinTime = util_math.Min64(resp.MinTime, s.TimestampMs)
+			resp.MaxTime = util_math.Max64(resp.MaxTime, s.TimestampMs)
+		}
+		for _, l := range ts.Labels {
+			if _, ok := seriesByLabelPair[l.Name]; !ok {
+				seriesByLabelPair[l.Name] = map[string]uint64{}
+			}
+			seriesByLabelPair[l.Name][l.Value]++
+		}
+	}
+
+	for name, values := range seriesByLabelPair {
+		size := uint64(0)
+		for value, count := range values {
+			size += uint64(len(value))
+			resp.SeriesCountByLabelValuePair = append(resp.SeriesCountByLabelValuePair, client.TSDBStatisticItem{Name: name + "=" + value, Value: count})
+			if name == labels.MetricName {
+				resp.SeriesCountByMetricName = append(resp.SeriesCountByMetricName, client.TSDBStatisticItem{Name: value, Value: count})
+			}
+		}
+		resp.NumLabelPairs += uint64(len(values))
+		resp.LabelValueCountByLabelName = append(resp.LabelValueCountByLabelName, client.TSDBStatisticItem{Name: name, Value: uint64(len(values))})
+		resp.MemoryInBytesByLabelName = append(resp.MemoryInBytesByLabelName, client.TSDBStatisticItem{Name: name, Value: size})
+	}
+
+	return resp, nil
+}
+
 func (i *mockIngester) UserStats(ctx context.Context, in *client.UserStatsRequest, opts ...grpc.CallOption) (*client.UserStatsResponse, error) {
 	if !i.happy {
 		return nil, errFail
@@ -3463,12 +3893,14 @@ outer:
 }
 
 func setMockForwarder(distributor *Distributor, ingest bool) *mockForwarder {
-	forwarder := &mockForwarder{ingest: ingest}
+	forwarder := &mockForwarder{Service: services.NewIdleService(nil, nil), ingest: ingest}
 	distributor.forwarder = forwarder
 	return forwarder
 }
 
 type mockForwarder struct {
+	services.Service
+
 	ingest    bool
 	sendCount atomic.Uint32
 }
@@ -5,16 +5,61 @@ package forwarding
 import (
 	"flag"
 	"time"
+
+	"github.com/pkg/errors"
+)
+
+var (
+	errInvalidQueueShards      = errors.New("the forwarding queue shards must be greater than 0")
+	errInvalidQueueCapacity    = errors.New("the forwarding queue capacity must be greater than 0")
+	errInvalidQueueIdleTimeout = errors.New("the forwarding queue idle timeout must not be negative")
 )
 
 type Config struct {
 	Enabled         bool          `yaml:"enabled" category:"experimental"`
 	RequestTimeout  time.Duration `yaml:"request_timeout" category:"experimental"`
 	PropagateErrors bool          `yaml:"propagate_errors" category:"experimental"`
+
+	QueueEnabled           bool          `yaml:"queue_enabled" category:"experimental"`
+	QueueCapacity          int           `yaml:"queue_capacity" category:"experimental"`
+	QueueShards            int           `yaml:"queue_shards" category:"experimental"`
+	QueueMinBackoff        time.Duration `yaml:"queue_min_backoff" category:"experimental"`
+	QueueMaxBackoff        time.Duration `yaml:"queue_max_backoff" category:"experimental"`
+	QueueMaxRetries        int           `yaml:"queue_max_retries" category:"experimental"`
+	QueueSpillDirectory    string        `yaml:"queue_spill_directory" category:"experimental"`
+	QueueSpillMaxSizeBytes int64         `yaml:"queue_spill_max_size_bytes" category:"experimental"`
+	QueueIdleTimeout       time.Duration `yaml:"queue_idle_timeout" category:"experimental"`
 }
 
 func (c *Config) RegisterFlags(f *flag.FlagSet) {
 	f.BoolVar(&c.Enabled, "distributor.forwarding.enabled", false, "Enables the feature to forward certain metrics in remote_write requests, depending on defined rules.")
 	f.DurationVar(&c.RequestTimeout, "distributor.forwarding.request-timeout", 10*time.Second, "Timeout for requests to ingestion endpoints to which we forward metrics.")
 	f.BoolVar(&c.PropagateErrors, "distributor.forwarding.propagate-errors", true, "If disabled then forwarding requests are always considered to be successful, errors are ignored.")
+
+	f.BoolVar(&c.QueueEnabled, "distributor.forwarding.queue-enabled", false, "If enabled, forwarded samples are buffered in a queue for each endpoint and sent asynchronously with retries, instead of being sent within the push request. When enabled, forwarding errors are never propagated to the client: the samples which can't be enqueued, like when the queue is full, are dropped and counted in the cortex_distributor_forward_queue_dropped_samples_total metric.")
+	f.IntVar(&c.QueueCapacity, "distributor.forwarding.queue-capacity", 1000, "Maximum number of forwarding requests buffered in memory for each shard of an endpoint queue.")
+	f.IntVar(&c.QueueShards, "distributor.forwarding.queue-shards", 4, "Number of shards of each endpoint queue, which send forwarding requests concurrently. Series are assigned to shards by hashing their labels, so the samples of a series are sent in order.")
+	f.DurationVar(&c.QueueMinBackoff, "distributor.forwarding.queue-min-backoff", 100*time.Millisecond, "Minimum backoff before retrying a forwarding request which failed with a recoverable error.")
+	f.DurationVar(&c.QueueMaxBackoff, "distributor.forwarding.queue-max-backoff", 10*time.Second, "Maximum backoff before retrying a forwarding request which failed with a recoverable error.")
+	f.IntVar(&c.QueueMaxRetries, "distributor.forwarding.queue-max-retries", 10, "Maximum number of attempts to send a forwarding request which failed with a recoverable error, before dropping it. 0 to retry forever.")
+	f.StringVar(&c.QueueSpillDirectory, "distributor.forwarding.queue-spill-directory", "", "Directory where forwarding requests are spilled to when the in-memory queue of an endpoint is full. Spilled requests are retained across restarts, and resumed once samples are forwarded to the same endpoint again. The credentials of the endpoints are never stored on disk. If empty, the requests which don't fit in the queue are dropped.")
+	f.Int64Var(&c.QueueSpillMaxSizeBytes, "distributor.forwarding.queue-spill-max-size-bytes", 1024*1024*1024, "Maximum size of the forwarding requests spilled to disk for each shard of an endpoint queue. The requests which don't fit are dropped.")
+	f.DurationVar(&c.QueueIdleTimeout, "distributor.forwarding.queue-idle-timeout", 10*time.Minute, "The queue of an endpoint which no samples have been forwarded to for this long, and which has no requests left to send, is closed along with its spill directory. 0 to never close the endpoint queues.")
+}
+
+// Validate config and returns error on failure.
+func (c *Config) Validate() error {
+	if !c.Enabled || !c.QueueEnabled {
+		return nil
+	}
+	if c.QueueShards <= 0 {
+		return errInvalidQueueShards
+	}
+	if c.QueueCapacity <= 0 {
+		return errInvalidQueueCapacity
+	}
+	if c.QueueIdleTimeout < 0 {
+		return errInvalidQueueIdleTimeout
+	}
+	return nil
 }
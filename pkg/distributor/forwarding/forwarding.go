@@ -6,18 +6,26 @@ import (
 	"bufio"
 	"bytes"
 	"context"
+	"encoding/base64"
 	"io"
 	"io/ioutil"
 	"net/http"
+	"sort"
 	"strconv"
+	"strings"
 	"sync"
 	"time"
 
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
 	"github.com/gogo/protobuf/proto"
 	"github.com/golang/snappy"
+	"github.com/grafana/dskit/services"
 	"github.com/pkg/errors"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/prometheus/prometheus/model/relabel"
 	"github.com/weaveworks/common/httpgrpc"
 
 	"github.com/grafana/mimir/pkg/mimirpb"
@@ -28,6 +36,7 @@ import (
 var errBadEndpointConfiguration = errors.New("bad endpoint configuration")
 
 type Forwarder interface {
+	services.Service
 	NewRequest(ctx context.Context, tenant string, rules validation.ForwardingRules) Request
 }
 
@@ -36,9 +45,8 @@ type Request interface {
 	// Samples which don't match any forwarding rule won't be added to the request.
 	// It returns a bool which indicates whether this timeseries should be sent to the Ingesters.
 	// A timeseries should be sent to the Ingester if any of the following conditions are true:
-	// - It has a labelset without a metric name, hence it can't match a forwarding rule.
-	// - There is no matching forwarding rule for the metric name of the timeseries.
-	// - There is a forwarding rule which defines that this metric should be forwarded and also pushed to the Ingesters.
+	// - There is no forwarding rule whose series selector matches the timeseries.
+	// - There is a matching forwarding rule which defines that this metric should be forwarded and also pushed to the Ingesters.
 	Add(sample mimirpb.PreallocTimeseries) bool
 
 	// Send sends the timeseries which have been added to this forwarding request to the according endpoints.
@@ -57,24 +65,30 @@ type pools struct {
 }
 
 type forwarder struct {
+	services.Service
+
 	cfg    Config
 	pools  pools
 	client http.Client
+	sender httpSender
+	logger log.Logger
+
+	// queues is nil if the forwarding queue is disabled.
+	queues *queues
 
-	requestsTotal           prometheus.Counter
-	errorsTotal             *prometheus.CounterVec
-	samplesTotal            prometheus.Counter
-	requestLatencyHistogram prometheus.Histogram
+	requestsTotal prometheus.Counter
+	samplesTotal  prometheus.Counter
 }
 
 // NewForwarder returns a new forwarder, if forwarding is disabled it returns nil.
-func NewForwarder(reg prometheus.Registerer, cfg Config) Forwarder {
+func NewForwarder(reg prometheus.Registerer, cfg Config, logger log.Logger) Forwarder {
 	if !cfg.Enabled {
 		return nil
 	}
 
-	return &forwarder{
-		cfg: cfg,
+	f := &forwarder{
+		cfg:    cfg,
+		logger: logger,
 		pools: pools{
 			timeseries: sync.Pool{New: func() interface{} { return &[]mimirpb.PreallocTimeseries{} }},
 			protobuf:   sync.Pool{New: func() interface{} { return &[]byte{} }},
@@ -86,90 +100,301 @@ func NewForwarder(reg prometheus.Registerer, cfg Config) Forwarder {
 			Name:      "distributor_forward_requests_total",
 			Help:      "The total number of requests the Distributor made to forward samples.",
 		}),
-		errorsTotal: promauto.With(reg).NewCounterVec(prometheus.CounterOpts{
-			Namespace: "cortex",
-			Name:      "distributor_forward_errors_total",
-			Help:      "The total number of errors that the distributor received from forwarding targets when trying to send samples to them.",
-		}, []string{"status_code"}),
 		samplesTotal: promauto.With(reg).NewCounter(prometheus.CounterOpts{
 			Namespace: "cortex",
 			Name:      "distributor_forward_samples_total",
 			Help:      "The total number of samples the Distributor forwarded.",
 		}),
-		requestLatencyHistogram: promauto.With(reg).NewHistogram(prometheus.HistogramOpts{
+	}
+
+	f.sender = httpSender{
+		client:  &f.client, // http client should be re-used so open connections get re-used.
+		timeout: cfg.RequestTimeout,
+		errors: promauto.With(reg).NewCounterVec(prometheus.CounterOpts{
+			Namespace: "cortex",
+			Name:      "distributor_forward_errors_total",
+			Help:      "The total number of errors that the distributor received from forwarding targets when trying to send samples to them.",
+		}, []string{"status_code"}),
+		latency: promauto.With(reg).NewHistogram(prometheus.HistogramOpts{
 			Namespace: "cortex",
 			Name:      "distributor_forward_requests_latency_seconds",
 			Help:      "The client-side latency of requests to forward metrics made by the Distributor.",
 			Buckets:   []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10, 20, 30},
 		}),
 	}
+
+	if cfg.QueueEnabled {
+		f.queues = newQueues(cfg, &f.sender, &f.pools, logger, reg)
+		f.Service = services.NewIdleService(f.queues.start, f.queues.stop)
+	} else {
+		f.Service = services.NewIdleService(nil, nil)
+	}
+
+	return f
 }
 
 func (r *forwarder) NewRequest(ctx context.Context, tenant string, rules validation.ForwardingRules) Request {
+	compiled := compileRules(rules)
+
 	return &request{
 		ctx:    ctx,
-		client: &r.client, // http client should be re-used so open connections get re-used.
+		sender: &r.sender,
 		pools:  &r.pools,
+		queues: r.queues,
 
-		tsByEndpoint: make(map[string]*[]mimirpb.PreallocTimeseries),
-
-		rules:           rules,
-		timeout:         r.cfg.RequestTimeout,
+		rules:           compiled,
+		timeseries:      make([]*[]mimirpb.PreallocTimeseries, len(compiled.endpoints)),
 		propagateErrors: r.cfg.PropagateErrors,
 
 		requests: r.requestsTotal,
-		errors:   r.errorsTotal,
 		samples:  r.samplesTotal,
-		latency:  r.requestLatencyHistogram,
 	}
 }
 
+// compiledRules are the forwarding rules of a tenant, indexed by metric name and whose endpoints
+// have been deduplicated.
+type compiledRules struct {
+	// The rules selecting a single metric name are indexed by it, so they're only evaluated for that metric.
+	byMetricName map[string][]*forwardingRule
+	others       []*forwardingRule
+
+	// endpoints are all the endpoints of the rules. The endpoints without write relabel configs
+	// are shared by all the rules forwarding series to the same target.
+	endpoints []*endpoint
+}
+
+func compileRules(rules validation.ForwardingRules) *compiledRules {
+	c := &compiledRules{
+		byMetricName: make(map[string][]*forwardingRule),
+	}
+	endpointsByTarget := make(map[string]*endpoint)
+
+	getOrCreateEndpoint := func(cfg validation.ForwardingEndpoint) *endpoint {
+		t := newTarget(cfg)
+		if len(cfg.WriteRelabelConfigs) == 0 {
+			if e, ok := endpointsByTarget[t.key()]; ok {
+				return e
+			}
+		}
+
+		e := &endpoint{index: len(c.endpoints), target: t, relabelConfigs: cfg.WriteRelabelConfigs}
+		c.endpoints = append(c.endpoints, e)
+		if len(cfg.WriteRelabelConfigs) == 0 {
+			endpointsByTarget[t.key()] = e
+		}
+		return e
+	}
+
+	for _, rule := range rules {
+		// The series selectors are compiled when loading the limits, and always have at least one matcher.
+		matchers := rule.Matchers()
+		if len(matchers) == 0 {
+			continue
+		}
+
+		endpoints := rule.Endpoints
+		if rule.Endpoint != "" {
+			endpoints = append([]validation.ForwardingEndpoint{{URL: rule.Endpoint}}, rule.Endpoints...)
+		}
+
+		fr := &forwardingRule{ingest: rule.Ingest, endpoints: make([]*endpoint, 0, len(endpoints))}
+		for _, e := range endpoints {
+			fr.endpoints = append(fr.endpoints, getOrCreateEndpoint(e))
+		}
+
+		if metricName, others, ok := splitMetricNameMatcher(matchers); ok {
+			fr.matchers = others
+			c.byMetricName[metricName] = append(c.byMetricName[metricName], fr)
+		} else {
+			fr.matchers = matchers
+			c.others = append(c.others, fr)
+		}
+	}
+
+	return c
+}
+
+// splitMetricNameMatcher returns the metric name selected by an equal matcher, and the other matchers.
+func splitMetricNameMatcher(matchers []*labels.Matcher) (string, []*labels.Matcher, bool) {
+	for i, m := range matchers {
+		if m.Name == labels.MetricName && m.Type == labels.MatchEqual {
+			others := make([]*labels.Matcher, 0, len(matchers)-1)
+			others = append(others, matchers[:i]...)
+			others = append(others, matchers[i+1:]...)
+			return m.Value, others, true
+		}
+	}
+	return "", nil, false
+}
+
+// forwardingRule is a forwarding rule along with the matchers of its series selector.
+type forwardingRule struct {
+	matchers  []*labels.Matcher
+	ingest    bool
+	endpoints []*endpoint
+}
+
+func (r *forwardingRule) matches(lbls []mimirpb.LabelAdapter) bool {
+	for _, m := range r.matchers {
+		if !m.Matches(valueOf(lbls, m.Name)) {
+			return false
+		}
+	}
+	return true
+}
+
+func valueOf(lbls []mimirpb.LabelAdapter, name string) string {
+	for _, l := range lbls {
+		if l.Name == name {
+			return l.Value
+		}
+	}
+	return ""
+}
+
+// endpoint is a target to forward the timeseries to, once the write relabel configs have been applied.
+type endpoint struct {
+	// index is the index of the endpoint in the compiled rules, and of its timeseries in each request.
+	index          int
+	target         target
+	relabelConfigs []*relabel.Config
+}
+
 type request struct {
 	ctx    context.Context
-	client *http.Client
+	sender *httpSender
 	pools  *pools
-
-	tsByEndpoint map[string]*[]mimirpb.PreallocTimeseries
+	queues *queues
 
 	// The rules which define:
-	// - which metrics get forwarded
-	// - where the metrics get forwarded to
-	// - whether the forwarded metrics should also be ingested (sent to ingesters)
-	rules           validation.ForwardingRules
-	timeout         time.Duration
+	// - which series get forwarded
+	// - where the series get forwarded to, and how they're relabeled before
+	// - whether the forwarded series should also be ingested (sent to ingesters)
+	rules           *compiledRules
 	propagateErrors bool
 
+	// timeseries are the timeseries to forward to each endpoint of the rules, indexed like the endpoints.
+	timeseries []*[]mimirpb.PreallocTimeseries
+
+	// added are the endpoints the timeseries being added has been added to already.
+	added []*endpoint
+
 	requests prometheus.Counter
-	errors   *prometheus.CounterVec
 	samples  prometheus.Counter
-	latency  prometheus.Histogram
 }
 
 func (r *request) Add(sample mimirpb.PreallocTimeseries) bool {
-	metric, err := extract.UnsafeMetricNameFromLabelAdapters(sample.Labels)
-	if err != nil {
-		// The only possible error is due to no metric name being defined, in which case we won't forward the sample
-		// to anywhere but we should still send it to the Ingesters.
-		return true
+	matched, ingest := false, false
+	r.added = r.added[:0]
+
+	add := func(rule *forwardingRule) {
+		if !rule.matches(sample.Labels) {
+			return
+		}
+		matched = true
+		ingest = ingest || rule.ingest
+		for _, e := range rule.endpoints {
+			r.addToEndpoint(e, sample)
+		}
 	}
 
-	rule, ok := r.rules[metric]
-	if !ok {
-		// There is no forwarding rule for this metric, send it to the Ingesters.
+	if metric, err := extract.UnsafeMetricNameFromLabelAdapters(sample.Labels); err == nil {
+		for _, rule := range r.rules.byMetricName[metric] {
+			add(rule)
+		}
+	}
+	for _, rule := range r.rules.others {
+		add(rule)
+	}
+
+	if !matched {
+		// There is no forwarding rule for this timeseries, send it to the Ingesters.
 		return true
 	}
+	return ingest
+}
+
+// addToEndpoint adds the timeseries to the given endpoint, unless the timeseries has been added to it
+// already by another rule or it's dropped by the write relabel configs of the endpoint.
+func (r *request) addToEndpoint(e *endpoint, sample mimirpb.PreallocTimeseries) {
+	for _, added := range r.added {
+		if added == e {
+			return
+		}
+	}
+	r.added = append(r.added, e)
+
+	if len(e.relabelConfigs) > 0 {
+		lbls := relabel.Process(mimirpb.FromLabelAdaptersToLabels(sample.Labels), e.relabelConfigs...)
+		if len(lbls) == 0 {
+			return
+		}
+
+		// The timeseries is shared with the Ingesters push, so we can't modify it.
+		sample = mimirpb.PreallocTimeseries{TimeSeries: &mimirpb.TimeSeries{
+			Labels:    mimirpb.FromLabelsToLabelAdapters(lbls),
+			Samples:   sample.Samples,
+			Exemplars: sample.Exemplars,
+		}}
+	}
+
 	r.samples.Add(float64(len(sample.Samples)))
 
-	ts, ok := r.tsByEndpoint[rule.Endpoint]
-	if !ok {
+	ts := r.timeseries[e.index]
+	if ts == nil {
 		ts = r.pools.timeseries.Get().(*[]mimirpb.PreallocTimeseries)
+		r.timeseries[e.index] = ts
 		r.requests.Inc()
 	}
-
 	*ts = append(*ts, sample)
-	r.tsByEndpoint[rule.Endpoint] = ts
+}
+
+// target is a remote_write endpoint, along with the headers to send to it.
+type target struct {
+	URL     string
+	Headers map[string]string
+}
+
+func newTarget(cfg validation.ForwardingEndpoint) target {
+	t := target{URL: cfg.URL}
+	if len(cfg.Headers) == 0 && cfg.BasicAuth.Username == "" && cfg.BearerToken.String() == "" {
+		return t
+	}
 
-	return rule.Ingest
+	t.Headers = make(map[string]string, len(cfg.Headers)+1)
+	for name, value := range cfg.Headers {
+		t.Headers[name] = value
+	}
+	if cfg.BasicAuth.Username != "" {
+		credentials := cfg.BasicAuth.Username + ":" + cfg.BasicAuth.Password.String()
+		t.Headers["Authorization"] = "Basic " + base64.StdEncoding.EncodeToString([]byte(credentials))
+	} else if cfg.BearerToken.String() != "" {
+		t.Headers["Authorization"] = "Bearer " + cfg.BearerToken.String()
+	}
+	return t
+}
+
+// key uniquely identifies the target.
+func (t target) key() string {
+	if len(t.Headers) == 0 {
+		return t.URL
+	}
+
+	names := make([]string, 0, len(t.Headers))
+	for name := range t.Headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	sb := strings.Builder{}
+	sb.WriteString(t.URL)
+	for _, name := range names {
+		sb.WriteString("\n")
+		sb.WriteString(name)
+		sb.WriteString(": ")
+		sb.WriteString(t.Headers[name])
+	}
+	return sb.String()
 }
 
 type recoverableError struct {
@@ -179,8 +404,15 @@ type recoverableError struct {
 func (r *request) Send(ctx context.Context) <-chan error {
 	errCh := make(chan error, 1)
 
+	var endpoints []*endpoint
+	for _, e := range r.rules.endpoints {
+		if r.timeseries[e.index] != nil {
+			endpoints = append(endpoints, e)
+		}
+	}
+
 	// Early return if there's no data to send.
-	if len(r.tsByEndpoint) == 0 {
+	if len(endpoints) == 0 {
 		close(errCh)
 		return errCh
 	}
@@ -192,20 +424,37 @@ func (r *request) Send(ctx context.Context) <-chan error {
 		errCh <- err
 	}
 
+	if r.queues != nil {
+		defer r.cleanup()
+		defer close(errCh)
+
+		// The timeseries are encoded while enqueuing them, so they can be returned to the pool right after.
+		// The forwarding is decoupled from the ingestion: the samples which can't be enqueued, like when the
+		// queue is full, are dropped and accounted in the dropped samples metric, without failing the request.
+		for _, e := range endpoints {
+			if err := r.queues.enqueue(e.target, *r.timeseries[e.index]); err != nil && !errors.Is(err, errQueueFull) {
+				level.Warn(r.queues.logger).Log("msg", "dropped forwarded samples which couldn't be enqueued", "endpoint", e.target.URL, "err", err)
+			}
+		}
+		return errCh
+	}
+
 	var wg sync.WaitGroup
-	wg.Add(len(r.tsByEndpoint))
+	wg.Add(len(endpoints))
 
 	// Can't use concurrency.ForEachJob because we don't want to cancel the other jobs if one errors.
-	errorsByEndpoint := make(map[string]error, len(r.tsByEndpoint))
+	errorsByEndpoint := make(map[*endpoint]error, len(endpoints))
 	var errorsMtx sync.Mutex
-	for endpoint, ts := range r.tsByEndpoint {
-		go func(endpoint string, ts []mimirpb.PreallocTimeseries) {
+	for _, e := range endpoints {
+		go func(e *endpoint, ts []mimirpb.PreallocTimeseries) {
 			defer wg.Done()
 
+			err := r.sendToEndpoint(ctx, e.target, ts)
+
 			errorsMtx.Lock()
 			defer errorsMtx.Unlock()
-			errorsByEndpoint[endpoint] = r.sendToEndpoint(ctx, endpoint, ts)
-		}(endpoint, *ts)
+			errorsByEndpoint[e] = err
+		}(e, *r.timeseries[e.index])
 	}
 
 	go func() {
@@ -216,18 +465,18 @@ func (r *request) Send(ctx context.Context) <-chan error {
 
 		var nonRecoverable error
 		// No need to get errorsMtx because we already waited for all routines which might modify it to end.
-		for endpoint, err := range errorsByEndpoint {
+		for e, err := range errorsByEndpoint {
 			if err == nil {
 				continue
 			}
 
 			if errors.As(err, &recoverableError{}) {
 				// If there is at least one recoverable error we want to return the recoverable error.
-				returnErr(httpgrpc.Errorf(http.StatusInternalServerError, "endpoint %s: %s", endpoint, err.Error()))
+				returnErr(httpgrpc.Errorf(http.StatusInternalServerError, "endpoint %s: %s", e.target.URL, err.Error()))
 				return
 			}
 
-			nonRecoverable = httpgrpc.Errorf(http.StatusBadRequest, "endpoint %s: %s", endpoint, err.Error())
+			nonRecoverable = httpgrpc.Errorf(http.StatusBadRequest, "endpoint %s: %s", e.target.URL, err.Error())
 		}
 
 		if nonRecoverable != nil {
@@ -238,9 +487,9 @@ func (r *request) Send(ctx context.Context) <-chan error {
 	return errCh
 }
 
-// sendToEndpoint sends the given timeseries to the given endpoint.
+// sendToEndpoint sends the given timeseries to the given target.
 // All returned errors which are recoverable are of the type recoverableError.
-func (r *request) sendToEndpoint(ctx context.Context, endpoint string, ts []mimirpb.PreallocTimeseries) error {
+func (r *request) sendToEndpoint(ctx context.Context, t target, ts []mimirpb.PreallocTimeseries) error {
 	protoBufBytes := (*r.pools.protobuf.Get().(*[]byte))[:0]
 	protoBuf := proto.NewBuffer(protoBufBytes)
 	err := protoBuf.Marshal(&mimirpb.WriteRequest{Timeseries: ts})
@@ -254,10 +503,25 @@ func (r *request) sendToEndpoint(ctx context.Context, endpoint string, ts []mimi
 	snappyBuf = snappy.Encode(snappyBuf[:cap(snappyBuf)], protoBufBytes)
 	defer r.pools.snappy.Put(&snappyBuf)
 
-	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	return r.sender.send(ctx, t, snappyBuf)
+}
+
+// httpSender sends snappy compressed remote_write requests to the forwarding endpoints.
+type httpSender struct {
+	client  *http.Client
+	timeout time.Duration
+
+	errors  *prometheus.CounterVec
+	latency prometheus.Histogram
+}
+
+// send sends the given snappy compressed remote_write request body to the given target.
+// All returned errors which are recoverable are of the type recoverableError.
+func (s *httpSender) send(ctx context.Context, t target, body []byte) error {
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
 	defer cancel()
 
-	httpReq, err := http.NewRequestWithContext(ctx, "POST", endpoint, bytes.NewReader(snappyBuf))
+	httpReq, err := http.NewRequestWithContext(ctx, "POST", t.URL, bytes.NewReader(body))
 	if err != nil {
 		// Errors from NewRequest are from unparsable URLs being configured.
 		// Usually configuration errors should lead to recoverable errors (5xx), but this is an exception because we
@@ -266,12 +530,15 @@ func (r *request) sendToEndpoint(ctx context.Context, endpoint string, ts []mimi
 		return errBadEndpointConfiguration
 	}
 
-	httpReq.Header.Add("Content-Encoding", "snappy")
+	for name, value := range t.Headers {
+		httpReq.Header.Set(name, value)
+	}
+	httpReq.Header.Set("Content-Encoding", "snappy")
 	httpReq.Header.Set("Content-Type", "application/x-protobuf")
 
 	beforeTs := time.Now()
-	httpResp, err := r.client.Do(httpReq)
-	r.latency.Observe(time.Since(beforeTs).Seconds())
+	httpResp, err := s.client.Do(httpReq)
+	s.latency.Observe(time.Since(beforeTs).Seconds())
 	if err != nil {
 		// Errors from Client.Do are from (for example) network errors, so are recoverable.
 		return recoverableError{err}
@@ -287,7 +554,7 @@ func (r *request) sendToEndpoint(ctx context.Context, endpoint string, ts []mimi
 		if scanner.Scan() {
 			line = scanner.Text()
 		}
-		r.errors.WithLabelValues(strconv.Itoa(httpResp.StatusCode)).Inc()
+		s.errors.WithLabelValues(strconv.Itoa(httpResp.StatusCode)).Inc()
 		err := errors.Errorf("server returned HTTP status %s: %s", httpResp.Status, line)
 		if httpResp.StatusCode/100 == 5 || httpResp.StatusCode == http.StatusTooManyRequests {
 			return recoverableError{err}
@@ -300,10 +567,14 @@ func (r *request) sendToEndpoint(ctx context.Context, endpoint string, ts []mimi
 
 // cleanup must be called to return the used buffers to their pools after a request has completed.
 func (r *request) cleanup() {
-	for _, ts := range r.tsByEndpoint {
+	for i, ts := range r.timeseries {
+		if ts == nil {
+			continue
+		}
 		*ts = (*ts)[:0]
 		r.pools.timeseries.Put(ts)
+		r.timeseries[i] = nil
 	}
 
-	r.tsByEndpoint = nil
+	r.timeseries = nil
 }
@@ -8,14 +8,18 @@ import (
 	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
+	"sort"
 	"strings"
 	"sync"
 	"testing"
 	"time"
 
+	"github.com/go-kit/log"
 	"github.com/golang/snappy"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/prometheus/common/model"
+	"github.com/prometheus/prometheus/model/relabel"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"github.com/weaveworks/common/httpgrpc"
@@ -41,12 +45,12 @@ func TestForwardingSamplesSuccessfully(t *testing.T) {
 	defer close2()
 
 	reg := prometheus.NewPedanticRegistry()
-	forwarder := NewForwarder(reg, testConfig)
+	forwarder := NewForwarder(reg, testConfig, log.NewNopLogger())
 
-	rules := validation.ForwardingRules{
+	rules := mustNewForwardingRules(t, validation.ForwardingRules{
 		"metric1": validation.ForwardingRule{Endpoint: url1, Ingest: false},
 		"metric2": validation.ForwardingRule{Endpoint: url2, Ingest: true},
-	}
+	})
 
 	forwardingReq := forwarder.NewRequest(context.Background(), tenant, rules)
 
@@ -170,7 +174,7 @@ func TestForwardingSamplesWithDifferentErrorsWithPropagation(t *testing.T) {
 	for _, tc := range tcs {
 		t.Run(tc.name, func(t *testing.T) {
 			reg := prometheus.NewRegistry()
-			forwarder := NewForwarder(reg, tc.config)
+			forwarder := NewForwarder(reg, tc.config, log.NewNopLogger())
 			urls := make([]string, len(tc.remoteStatusCodes))
 			closers := make([]func(), len(tc.remoteStatusCodes))
 			expectedErrorsByStatusCode := make(map[int]int)
@@ -191,6 +195,7 @@ func TestForwardingSamplesWithDifferentErrorsWithPropagation(t *testing.T) {
 				metrics = append(metrics, metric)
 				rules[metric] = validation.ForwardingRule{Endpoint: url}
 			}
+			rules = mustNewForwardingRules(t, rules)
 
 			now := time.Now().UnixMilli()
 			forwardingReq := forwarder.NewRequest(context.Background(), tenant, rules)
@@ -230,6 +235,112 @@ func TestForwardingSamplesWithDifferentErrorsWithPropagation(t *testing.T) {
 	}
 }
 
+func TestForwardingSamplesWithSelectorRules(t *testing.T) {
+	const tenant = "tenant"
+	now := time.Now().UnixMilli()
+
+	url1, reqs1, bodies1, close1 := newTestServer(t, 200, true)
+	defer close1()
+
+	url2, reqs2, bodies2, close2 := newTestServer(t, 200, true)
+	defer close2()
+
+	reg := prometheus.NewPedanticRegistry()
+	forwarder := NewForwarder(reg, testConfig, log.NewNopLogger())
+
+	rules := mustNewForwardingRules(t, validation.ForwardingRules{
+		`{team="payments"}`: validation.ForwardingRule{
+			Endpoints: []validation.ForwardingEndpoint{
+				{URL: url1, Headers: map[string]string{"X-Scope-OrgID": "payments"}},
+				{
+					URL:       url2,
+					BasicAuth: validation.ForwardingBasicAuth{Username: "user", Password: validation.NewSecret("pass")},
+					WriteRelabelConfigs: []*relabel.Config{{
+						Regex:  relabel.MustNewRegexp("internal_.*"),
+						Action: relabel.LabelDrop,
+					}},
+				},
+			},
+		},
+		`{job=~"edge.*"}`:    validation.ForwardingRule{Ingest: true, Endpoints: []validation.ForwardingEndpoint{{URL: url1, Headers: map[string]string{"X-Scope-OrgID": "payments"}}}},
+		`metric1{env="dev"}`: validation.ForwardingRule{Endpoint: url2, Ingest: true},
+	})
+
+	forwardingReq := forwarder.NewRequest(context.Background(), tenant, rules)
+
+	// Matches the payments rule only.
+	require.False(t, forwardingReq.Add(newSample(t, now, 1, "__name__", "metric1", "internal_id", "1", "team", "payments")))
+	// Matches both the payments and edge rules, which forward it to the first endpoint only once.
+	require.True(t, forwardingReq.Add(newSample(t, now, 2, "__name__", "metric2", "job", "edge-1", "team", "payments")))
+	// Matches the metric1 rule only.
+	require.True(t, forwardingReq.Add(newSample(t, now, 3, "__name__", "metric1", "env", "dev")))
+	// Doesn't match any rule.
+	require.True(t, forwardingReq.Add(newSample(t, now, 4, "__name__", "metric1", "env", "prod")))
+
+	require.NoError(t, <-forwardingReq.Send(context.Background()))
+
+	require.Len(t, *bodies1, 1)
+	require.Equal(t, "payments", (*reqs1)[0].Header.Get("X-Scope-OrgID"))
+	receivedReq := decodeBody(t, (*bodies1)[0])
+	require.Len(t, receivedReq.Timeseries, 2)
+	requireLabelsEqual(t, receivedReq.Timeseries[0].Labels, "__name__", "metric1", "internal_id", "1", "team", "payments")
+	requireSamplesEqual(t, receivedReq.Timeseries[0].Samples, now, 1)
+	requireLabelsEqual(t, receivedReq.Timeseries[1].Labels, "__name__", "metric2", "job", "edge-1", "team", "payments")
+	requireSamplesEqual(t, receivedReq.Timeseries[1].Samples, now, 2)
+
+	// The second endpoint receives the relabeled series of the payments rule and the series of the metric1 rule.
+	require.Len(t, *bodies2, 2)
+	var received []mimirpb.PreallocTimeseries
+	for i, body := range *bodies2 {
+		if (*reqs2)[i].Header.Get("Authorization") != "" {
+			user, pass, ok := (*reqs2)[i].BasicAuth()
+			require.True(t, ok)
+			require.Equal(t, "user", user)
+			require.Equal(t, "pass", pass)
+		}
+		received = append(received, decodeBody(t, body).Timeseries...)
+	}
+	sort.Slice(received, func(i, j int) bool { return received[i].Samples[0].Value < received[j].Samples[0].Value })
+	require.Len(t, received, 3)
+	requireLabelsEqual(t, received[0].Labels, "__name__", "metric1", "team", "payments")
+	requireLabelsEqual(t, received[1].Labels, "__name__", "metric2", "job", "edge-1", "team", "payments")
+	requireLabelsEqual(t, received[2].Labels, "__name__", "metric1", "env", "dev")
+}
+
+func TestForwardingSamplesDroppedByRelabeling(t *testing.T) {
+	url, _, bodies, closeFn := newTestServer(t, 200, true)
+	defer closeFn()
+
+	forwarder := NewForwarder(prometheus.NewPedanticRegistry(), testConfig, log.NewNopLogger())
+
+	rules := mustNewForwardingRules(t, validation.ForwardingRules{
+		"metric1": validation.ForwardingRule{Endpoints: []validation.ForwardingEndpoint{{
+			URL: url,
+			WriteRelabelConfigs: []*relabel.Config{{
+				SourceLabels: model.LabelNames{"env"},
+				Regex:        relabel.MustNewRegexp("dev"),
+				Action:       relabel.Drop,
+			}},
+		}}},
+	})
+
+	forwardingReq := forwarder.NewRequest(context.Background(), "tenant", rules)
+	require.False(t, forwardingReq.Add(newSample(t, 1, 1, "__name__", "metric1", "env", "dev")))
+	require.False(t, forwardingReq.Add(newSample(t, 1, 2, "__name__", "metric1", "env", "prod")))
+	require.NoError(t, <-forwardingReq.Send(context.Background()))
+
+	require.Len(t, *bodies, 1)
+	receivedReq := decodeBody(t, (*bodies)[0])
+	require.Len(t, receivedReq.Timeseries, 1)
+	requireLabelsEqual(t, receivedReq.Timeseries[0].Labels, "__name__", "metric1", "env", "prod")
+}
+
+func mustNewForwardingRules(tb testing.TB, rules validation.ForwardingRules) validation.ForwardingRules {
+	compiled, err := validation.NewForwardingRules(rules)
+	require.NoError(tb, err)
+	return compiled
+}
+
 func newSample(tb testing.TB, time int64, value float64, labelValuePairs ...string) mimirpb.PreallocTimeseries {
 	require.Zero(tb, len(labelValuePairs)%2)
 
@@ -319,8 +430,9 @@ func BenchmarkRemoteWriteForwarding(b *testing.B) {
 		samples[i] = newSample(b, now, 1, "__name__", metric)
 		rules[metric] = validation.ForwardingRule{Endpoint: "http://localhost/"}
 	}
+	rules = mustNewForwardingRules(b, rules)
 
-	forwarder := NewForwarder(nil, testConfig).(*forwarder)
+	forwarder := NewForwarder(nil, testConfig, log.NewNopLogger()).(*forwarder)
 
 	// No-op client, we don't want the benchmark to be skewed by TCP performance
 	forwarder.client = http.Client{Transport: &noopRoundTripper{}}
@@ -0,0 +1,737 @@
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package forwarding
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/gogo/protobuf/proto"
+	"github.com/golang/snappy"
+	"github.com/grafana/dskit/backoff"
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"go.uber.org/atomic"
+
+	"github.com/grafana/mimir/pkg/mimirpb"
+)
+
+const (
+	droppedReasonQueueFull           = "queue-full"
+	droppedReasonNonRecoverableError = "non-recoverable-error"
+	droppedReasonMaxRetries          = "max-retries"
+	droppedReasonSpillError          = "spill-error"
+	droppedReasonShutdown            = "shutdown"
+
+	spillFileExtension    = ".req"
+	spillTmpFileExtension = ".tmp"
+	shardDirPrefix        = "shard-"
+)
+
+var (
+	errQueueFull    = errors.New("forwarding queue is full")
+	errQueueStopped = errors.New("forwarding queue is stopped")
+	errSpillFull    = errors.New("forwarding queue spill directory is full")
+)
+
+// queueItem is an encoded remote_write request waiting to be sent to an endpoint.
+type queueItem struct {
+	// body is the snappy compressed protobuf of the remote_write request.
+	body    []byte
+	samples int
+}
+
+type queueMetrics struct {
+	length         prometheus.Gauge
+	spilledLength  prometheus.Gauge
+	droppedSamples *prometheus.CounterVec
+	retries        prometheus.Counter
+}
+
+// queues buffers the forwarding requests in a queue for each endpoint, and sends them asynchronously
+// to the endpoints. Each endpoint queue is split into shards, which send the requests concurrently.
+type queues struct {
+	cfg     Config
+	sender  *httpSender
+	pools   *pools
+	logger  log.Logger
+	metrics *queueMetrics
+
+	// ctx is canceled when the queues are stopped, to stop the shards.
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+
+	// stoppedMtx is held in read mode while enqueuing requests, so that no request
+	// is enqueued once the queues have been stopped, or to an endpoint queue being closed.
+	stoppedMtx sync.RWMutex
+	stopped    bool
+
+	endpointsMtx sync.Mutex
+	endpoints    map[string]*endpointQueue
+}
+
+func newQueues(cfg Config, sender *httpSender, pools *pools, logger log.Logger, reg prometheus.Registerer) *queues {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	return &queues{
+		cfg:       cfg,
+		sender:    sender,
+		pools:     pools,
+		logger:    logger,
+		ctx:       ctx,
+		cancel:    cancel,
+		endpoints: map[string]*endpointQueue{},
+		metrics: &queueMetrics{
+			length: promauto.With(reg).NewGauge(prometheus.GaugeOpts{
+				Namespace: "cortex",
+				Name:      "distributor_forward_queue_length",
+				Help:      "The number of forwarding requests buffered in memory, waiting to be sent.",
+			}),
+			spilledLength: promauto.With(reg).NewGauge(prometheus.GaugeOpts{
+				Namespace: "cortex",
+				Name:      "distributor_forward_queue_spilled_length",
+				Help:      "The number of forwarding requests spilled to disk, waiting to be sent.",
+			}),
+			droppedSamples: promauto.With(reg).NewCounterVec(prometheus.CounterOpts{
+				Namespace: "cortex",
+				Name:      "distributor_forward_queue_dropped_samples_total",
+				Help:      "The total number of samples the Distributor dropped instead of forwarding them.",
+			}, []string{"reason"}),
+			retries: promauto.With(reg).NewCounter(prometheus.CounterOpts{
+				Namespace: "cortex",
+				Name:      "distributor_forward_queue_retries_total",
+				Help:      "The total number of forwarding requests retried after a recoverable error.",
+			}),
+		},
+	}
+}
+
+// start creates the spill directory. The requests spilled to disk before the last shutdown are resumed
+// once the endpoint queue they belong to is created again, when samples are forwarded to the endpoint.
+func (q *queues) start(_ context.Context) error {
+	if q.cfg.QueueSpillDirectory != "" {
+		if err := os.MkdirAll(q.cfg.QueueSpillDirectory, 0o755); err != nil {
+			return errors.Wrap(err, "create forwarding queue spill directory")
+		}
+	}
+
+	if q.cfg.QueueIdleTimeout > 0 {
+		q.wg.Add(1)
+		go func() {
+			defer q.wg.Done()
+			q.closeIdleLoop(q.ctx)
+		}()
+	}
+	return nil
+}
+
+// closeIdleLoop periodically closes the idle endpoint queues, so that the queues of the endpoints
+// which aren't referenced by the forwarding rules anymore don't leak.
+func (q *queues) closeIdleLoop(ctx context.Context) {
+	ticker := time.NewTicker(q.cfg.QueueIdleTimeout / 2)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			q.closeIdle(time.Now())
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// closeIdle closes and removes the endpoint queues which no requests have been enqueued to since
+// the idle timeout, and which have no requests left to send. Their spill directory is removed too.
+func (q *queues) closeIdle(now time.Time) {
+	// No request can be enqueued while the idle queues are closed.
+	q.stoppedMtx.Lock()
+	defer q.stoppedMtx.Unlock()
+
+	if q.stopped {
+		return
+	}
+
+	q.endpointsMtx.Lock()
+	defer q.endpointsMtx.Unlock()
+
+	for key, e := range q.endpoints {
+		if e.outstanding.Load() > 0 || now.Sub(time.Unix(0, e.lastEnqueued.Load())) < q.cfg.QueueIdleTimeout {
+			continue
+		}
+
+		e.close()
+		delete(q.endpoints, key)
+
+		if e.dir != "" {
+			if err := os.RemoveAll(e.dir); err != nil {
+				level.Warn(q.logger).Log("msg", "failed to remove the spill directory of an idle forwarding queue", "dir", e.dir, "err", err)
+			}
+		}
+	}
+}
+
+// stop stops sending requests. The requests left in memory are spilled to disk if it's enabled,
+// otherwise they're dropped.
+func (q *queues) stop(_ error) error {
+	q.stoppedMtx.Lock()
+	q.stopped = true
+	q.stoppedMtx.Unlock()
+
+	q.cancel()
+	q.wg.Wait()
+
+	q.endpointsMtx.Lock()
+	defer q.endpointsMtx.Unlock()
+
+	for _, e := range q.endpoints {
+		for _, s := range e.shards {
+			s.flush()
+		}
+	}
+	return nil
+}
+
+// enqueue adds the given timeseries to the queue of the given target. The timeseries are encoded
+// before returning, so the caller can reuse them right after.
+func (q *queues) enqueue(t target, ts []mimirpb.PreallocTimeseries) error {
+	q.stoppedMtx.RLock()
+	defer q.stoppedMtx.RUnlock()
+
+	if q.stopped {
+		q.metrics.droppedSamples.WithLabelValues(droppedReasonShutdown).Add(float64(countSamples(ts)))
+		return errQueueStopped
+	}
+
+	e, err := q.getOrCreate(t)
+	if err != nil {
+		q.metrics.droppedSamples.WithLabelValues(droppedReasonSpillError).Add(float64(countSamples(ts)))
+		return err
+	}
+	e.lastEnqueued.Store(time.Now().UnixNano())
+
+	// Series are assigned to shards by hashing their labels, so the samples of a series are always
+	// sent in order by the same shard.
+	tsByShard := make([][]mimirpb.PreallocTimeseries, len(e.shards))
+	for _, series := range ts {
+		shard := mimirpb.FromLabelAdaptersToLabels(series.Labels).Hash() % uint64(len(e.shards))
+		tsByShard[shard] = append(tsByShard[shard], series)
+	}
+
+	var firstErr error
+	for shard, shardTs := range tsByShard {
+		if len(shardTs) == 0 {
+			continue
+		}
+
+		item, err := q.encode(shardTs)
+		if err != nil {
+			return err
+		}
+
+		if err := e.shards[shard].enqueue(item); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+
+	return firstErr
+}
+
+// encode encodes the given timeseries into a remote_write request body.
+func (q *queues) encode(ts []mimirpb.PreallocTimeseries) (queueItem, error) {
+	protoBufBytes := (*q.pools.protobuf.Get().(*[]byte))[:0]
+	protoBuf := proto.NewBuffer(protoBufBytes)
+	if err := protoBuf.Marshal(&mimirpb.WriteRequest{Timeseries: ts}); err != nil {
+		return queueItem{}, err
+	}
+	protoBufBytes = protoBuf.Bytes()
+	defer q.pools.protobuf.Put(&protoBufBytes)
+
+	// The body is retained by the queue, so it can't come from a pool.
+	return queueItem{
+		body:    snappy.Encode(nil, protoBufBytes),
+		samples: countSamples(ts),
+	}, nil
+}
+
+// getOrCreate returns the queue of the given target, creating and starting it if it doesn't exist yet.
+// Targets with the same URL but different headers have different queues.
+func (q *queues) getOrCreate(t target) (*endpointQueue, error) {
+	key := t.key()
+
+	q.endpointsMtx.Lock()
+	defer q.endpointsMtx.Unlock()
+
+	if e, ok := q.endpoints[key]; ok {
+		return e, nil
+	}
+
+	e, err := newEndpointQueue(q.cfg, t, q.sender, q.logger, q.metrics)
+	if err != nil {
+		return nil, err
+	}
+
+	// The shards of the endpoint are stopped either when the queues are stopped, or when the endpoint queue is closed.
+	var ctx context.Context
+	ctx, e.cancel = context.WithCancel(q.ctx)
+
+	for _, s := range e.shards {
+		q.wg.Add(1)
+		e.wg.Add(1)
+		go func(s *queueShard) {
+			defer q.wg.Done()
+			defer e.wg.Done()
+			s.run(ctx)
+		}(s)
+	}
+
+	q.endpoints[key] = e
+	return e, nil
+}
+
+// endpointQueue is the queue of the requests to a single endpoint.
+type endpointQueue struct {
+	shards []*queueShard
+
+	// dir is the spill directory of the endpoint, empty if spilling to disk is disabled.
+	dir string
+
+	// lastEnqueued is the time, in nanoseconds, requests have been last enqueued at.
+	lastEnqueued atomic.Int64
+	// outstanding is the number of requests which have been enqueued but not sent or dropped yet.
+	outstanding atomic.Int64
+
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+func newEndpointQueue(cfg Config, t target, sender *httpSender, logger log.Logger, metrics *queueMetrics) (*endpointQueue, error) {
+	e := &endpointQueue{shards: make([]*queueShard, cfg.QueueShards)}
+	e.lastEnqueued.Store(time.Now().UnixNano())
+
+	for i := range e.shards {
+		e.shards[i] = &queueShard{
+			target:  t,
+			items:   make(chan queueItem, cfg.QueueCapacity),
+			spilled: make(chan struct{}, 1),
+			sender:  sender,
+			backoff: backoff.Config{
+				MinBackoff: cfg.QueueMinBackoff,
+				MaxBackoff: cfg.QueueMaxBackoff,
+				MaxRetries: cfg.QueueMaxRetries,
+			},
+			logger:      log.With(logger, "endpoint", t.URL, "shard", i),
+			metrics:     metrics,
+			outstanding: &e.outstanding,
+		}
+	}
+
+	if cfg.QueueSpillDirectory == "" {
+		return e, nil
+	}
+
+	// Each endpoint queue spills its requests to a different directory, named after the hash of the
+	// endpoint URL and headers, so that the queue resumes the requests spilled before a restart.
+	// The endpoint itself isn't stored on disk, since its headers may carry credentials.
+	h := sha256.Sum256([]byte(t.key()))
+	dir := filepath.Join(cfg.QueueSpillDirectory, hex.EncodeToString(h[:16]))
+	e.dir = dir
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+
+	for i, s := range e.shards {
+		spill, err := newSpillQueue(filepath.Join(dir, fmt.Sprintf("%s%d", shardDirPrefix, i)), cfg.QueueSpillMaxSizeBytes)
+		if err != nil {
+			return nil, err
+		}
+		s.spill = spill
+	}
+
+	// The number of shards may have been reduced since the requests were spilled,
+	// so we reassign the requests spilled by the removed shards to the current ones.
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	for _, entry := range entries {
+		shard, err := strconv.Atoi(strings.TrimPrefix(entry.Name(), shardDirPrefix))
+		if !entry.IsDir() || err != nil || shard < len(e.shards) {
+			continue
+		}
+		if err := e.shards[shard%len(e.shards)].spill.load(filepath.Join(dir, entry.Name())); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, s := range e.shards {
+		metrics.spilledLength.Add(float64(s.spill.len()))
+		e.outstanding.Add(int64(s.spill.len()))
+	}
+
+	return e, nil
+}
+
+// close stops the shards of the endpoint queue, and waits until they've stopped.
+func (e *endpointQueue) close() {
+	e.cancel()
+	e.wg.Wait()
+}
+
+// queueShard sends a subset of the requests to an endpoint, in the order they've been enqueued.
+type queueShard struct {
+	target target
+	items  chan queueItem
+
+	// spill is nil if spilling to disk is disabled.
+	spill *spillQueue
+	// spilled is notified when a request is spilled to disk.
+	spilled chan struct{}
+	// pending is the request which was being sent when the shard has been stopped.
+	pending *queueItem
+
+	sender  *httpSender
+	backoff backoff.Config
+	logger  log.Logger
+	metrics *queueMetrics
+
+	// outstanding is the number of requests of the endpoint which have been enqueued but not sent or dropped yet.
+	outstanding *atomic.Int64
+}
+
+func (s *queueShard) enqueue(item queueItem) error {
+	s.outstanding.Inc()
+	if err := s.push(item); err != nil {
+		s.outstanding.Dec()
+		return err
+	}
+	return nil
+}
+
+// push adds the given request to the in-memory queue, or spills it to disk if the in-memory queue is full.
+func (s *queueShard) push(item queueItem) error {
+	// Once requests have been spilled to disk, the following ones are spilled too until the
+	// spilled ones have been sent, so that the requests are sent in the order they've been enqueued.
+	if s.spill == nil || s.spill.len() == 0 {
+		s.metrics.length.Inc()
+		select {
+		case s.items <- item:
+			return nil
+		default:
+			s.metrics.length.Dec()
+		}
+	}
+
+	if s.spill == nil {
+		s.drop(item, droppedReasonQueueFull)
+		return errQueueFull
+	}
+	return s.spillItem(item)
+}
+
+func (s *queueShard) spillItem(item queueItem) error {
+	if err := s.spill.push(item); err != nil {
+		if errors.Is(err, errSpillFull) {
+			s.drop(item, droppedReasonQueueFull)
+			return errQueueFull
+		}
+
+		level.Warn(s.logger).Log("msg", "failed to spill forwarding request to disk", "err", err)
+		s.drop(item, droppedReasonSpillError)
+		return err
+	}
+
+	s.metrics.spilledLength.Inc()
+	select {
+	case s.spilled <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+func (s *queueShard) run(ctx context.Context) {
+	for {
+		item, spilled, ok := s.next(ctx)
+		if !ok {
+			return
+		}
+
+		if !s.send(ctx, item) {
+			// The spilled requests are removed from disk only once they've been sent,
+			// so only the requests coming from memory have to be kept.
+			if !spilled {
+				s.pending = &item
+			}
+			return
+		}
+
+		if spilled {
+			if err := s.spill.pop(); err != nil {
+				level.Warn(s.logger).Log("msg", "failed to remove forwarding request spilled to disk", "err", err)
+			}
+			s.metrics.spilledLength.Dec()
+		}
+		s.outstanding.Dec()
+	}
+}
+
+// next returns the next request to send, and whether it has been read from disk. The requests in memory
+// are sent first, because the requests are spilled to disk only once the in-memory queue is full.
+// It returns false if the context has been canceled while waiting for a request.
+func (s *queueShard) next(ctx context.Context) (queueItem, bool, bool) {
+	for {
+		select {
+		case item := <-s.items:
+			s.metrics.length.Dec()
+			return item, false, true
+		default:
+		}
+
+		if s.spill != nil && s.spill.len() > 0 {
+			item, err := s.spill.peek()
+			if err == nil {
+				return item, true, true
+			}
+
+			level.Warn(s.logger).Log("msg", "dropping corrupted forwarding request spilled to disk", "err", err)
+			if err := s.spill.pop(); err != nil {
+				level.Warn(s.logger).Log("msg", "failed to remove forwarding request spilled to disk", "err", err)
+			}
+			s.metrics.spilledLength.Dec()
+			s.outstanding.Dec()
+			continue
+		}
+
+		select {
+		case item := <-s.items:
+			s.metrics.length.Dec()
+			return item, false, true
+		case <-s.spilled:
+		case <-ctx.Done():
+			return queueItem{}, false, false
+		}
+	}
+}
+
+// send sends the given request, retrying it with backoff in case of recoverable errors. It returns false
+// if the context has been canceled before the request has been either sent or dropped.
+func (s *queueShard) send(ctx context.Context, item queueItem) bool {
+	var err error
+
+	boff := backoff.New(ctx, s.backoff)
+	for boff.Ongoing() {
+		err = s.sender.send(ctx, s.target, item.body)
+		if err == nil {
+			return true
+		}
+		if ctx.Err() != nil {
+			return false
+		}
+		if !errors.As(err, &recoverableError{}) {
+			level.Warn(s.logger).Log("msg", "dropping forwarding request which failed with a non-recoverable error", "err", err)
+			s.drop(item, droppedReasonNonRecoverableError)
+			return true
+		}
+
+		s.metrics.retries.Inc()
+		boff.Wait()
+	}
+
+	if ctx.Err() != nil {
+		return false
+	}
+
+	level.Warn(s.logger).Log("msg", "dropping forwarding request after max retries", "err", err)
+	s.drop(item, droppedReasonMaxRetries)
+	return true
+}
+
+// flush spills the requests left in memory to disk, or drops them if spilling is disabled.
+// It must be called only once the shard has stopped running.
+func (s *queueShard) flush() {
+	var items []queueItem
+	if s.pending != nil {
+		items = append(items, *s.pending)
+		s.pending = nil
+	}
+
+	for len(s.items) > 0 {
+		items = append(items, <-s.items)
+		s.metrics.length.Dec()
+	}
+
+	for _, item := range items {
+		if s.spill == nil {
+			s.drop(item, droppedReasonShutdown)
+			continue
+		}
+		_ = s.spillItem(item)
+	}
+}
+
+func (s *queueShard) drop(item queueItem, reason string) {
+	s.metrics.droppedSamples.WithLabelValues(reason).Add(float64(item.samples))
+}
+
+// spillQueue is a FIFO queue of requests stored on disk, one file per request.
+type spillQueue struct {
+	dir     string
+	maxSize int64
+
+	mtx     sync.Mutex
+	files   []spillFile
+	size    int64
+	nextSeq uint64
+}
+
+type spillFile struct {
+	path string
+	size int64
+}
+
+func newSpillQueue(dir string, maxSize int64) (*spillQueue, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+
+	q := &spillQueue{dir: dir, maxSize: maxSize}
+	return q, q.load(dir)
+}
+
+// load appends the requests spilled to the given directory to the queue.
+func (q *spillQueue) load(dir string) error {
+	q.mtx.Lock()
+	defer q.mtx.Unlock()
+
+	// The entries are sorted by file name, which is the zero-padded sequence number of the request.
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() {
+			continue
+		}
+
+		// Remove the leftovers of the requests whose spilling has been interrupted.
+		if strings.HasSuffix(name, spillTmpFileExtension) {
+			if err := os.Remove(filepath.Join(dir, name)); err != nil {
+				return err
+			}
+			continue
+		}
+
+		seq, err := strconv.ParseUint(strings.TrimSuffix(name, spillFileExtension), 10, 64)
+		if !strings.HasSuffix(name, spillFileExtension) || err != nil {
+			continue
+		}
+
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+
+		q.files = append(q.files, spillFile{path: filepath.Join(dir, name), size: info.Size()})
+		q.size += info.Size()
+		if dir == q.dir && seq >= q.nextSeq {
+			q.nextSeq = seq + 1
+		}
+	}
+
+	return nil
+}
+
+func (q *spillQueue) len() int {
+	q.mtx.Lock()
+	defer q.mtx.Unlock()
+
+	return len(q.files)
+}
+
+// push appends the given request to the queue.
+func (q *spillQueue) push(item queueItem) error {
+	data := make([]byte, binary.MaxVarintLen64, binary.MaxVarintLen64+len(item.body))
+	data = append(data[:binary.PutUvarint(data, uint64(item.samples))], item.body...)
+
+	q.mtx.Lock()
+	defer q.mtx.Unlock()
+
+	if q.size+int64(len(data)) > q.maxSize {
+		return errSpillFull
+	}
+
+	// The request is written to a temporary file first, so that a partially written request is never read back.
+	path := filepath.Join(q.dir, fmt.Sprintf("%020d%s", q.nextSeq, spillFileExtension))
+	if err := os.WriteFile(path+spillTmpFileExtension, data, 0o644); err != nil {
+		return err
+	}
+	if err := os.Rename(path+spillTmpFileExtension, path); err != nil {
+		return err
+	}
+
+	q.nextSeq++
+	q.files = append(q.files, spillFile{path: path, size: int64(len(data))})
+	q.size += int64(len(data))
+	return nil
+}
+
+// peek returns the oldest request in the queue, without removing it.
+func (q *spillQueue) peek() (queueItem, error) {
+	q.mtx.Lock()
+	if len(q.files) == 0 {
+		q.mtx.Unlock()
+		return queueItem{}, errors.New("no spilled forwarding requests")
+	}
+	path := q.files[0].path
+	q.mtx.Unlock()
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return queueItem{}, err
+	}
+
+	samples, n := binary.Uvarint(data)
+	if n <= 0 {
+		return queueItem{}, errors.Errorf("invalid spilled forwarding request %s", path)
+	}
+
+	return queueItem{body: data[n:], samples: int(samples)}, nil
+}
+
+// pop removes the oldest request from the queue.
+func (q *spillQueue) pop() error {
+	q.mtx.Lock()
+	defer q.mtx.Unlock()
+
+	if len(q.files) == 0 {
+		return nil
+	}
+
+	f := q.files[0]
+	q.files = q.files[1:]
+	q.size -= f.size
+	return os.Remove(f.path)
+}
+
+func countSamples(ts []mimirpb.PreallocTimeseries) int {
+	samples := 0
+	for _, series := range ts {
+		samples += len(series.Samples)
+	}
+	return samples
+}
@@ -0,0 +1,364 @@
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package forwarding
+
+import (
+	"context"
+	"io/fs"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/grafana/dskit/services"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/mimir/pkg/util/validation"
+)
+
+func newTestQueueConfig() Config {
+	cfg := testConfig
+	cfg.QueueEnabled = true
+	cfg.QueueCapacity = 10
+	cfg.QueueShards = 2
+	cfg.QueueMinBackoff = time.Millisecond
+	cfg.QueueMaxBackoff = 10 * time.Millisecond
+	cfg.QueueMaxRetries = 3
+	cfg.QueueSpillMaxSizeBytes = 1024 * 1024
+	return cfg
+}
+
+func TestForwardingQueue_ShouldSendSamplesAsynchronously(t *testing.T) {
+	srv := newQueueTestServer(t, http.StatusServiceUnavailable, http.StatusServiceUnavailable, http.StatusOK)
+
+	reg := prometheus.NewPedanticRegistry()
+	forwarder := startTestForwarder(t, reg, newTestQueueConfig())
+
+	rules := mustNewForwardingRules(t, validation.ForwardingRules{"metric1": validation.ForwardingRule{Endpoint: srv.URL}})
+	now := time.Now().UnixMilli()
+
+	req := forwarder.NewRequest(context.Background(), "tenant", rules)
+	require.False(t, req.Add(newSample(t, now, 1, "__name__", "metric1", "some_label", "foo")))
+	require.False(t, req.Add(newSample(t, now, 2, "__name__", "metric1", "some_label", "bar")))
+	require.NoError(t, <-req.Send(context.Background()))
+
+	// The series are split between the shards, which retry the requests failed with a recoverable error.
+	require.Eventually(t, func() bool {
+		return srv.receivedSamples() == 2
+	}, 5*time.Second, 10*time.Millisecond)
+
+	assert.NoError(t, testutil.GatherAndCompare(reg, strings.NewReader(`
+		# HELP cortex_distributor_forward_queue_length The number of forwarding requests buffered in memory, waiting to be sent.
+		# TYPE cortex_distributor_forward_queue_length gauge
+		cortex_distributor_forward_queue_length 0
+		# HELP cortex_distributor_forward_queue_retries_total The total number of forwarding requests retried after a recoverable error.
+		# TYPE cortex_distributor_forward_queue_retries_total counter
+		cortex_distributor_forward_queue_retries_total 2
+	`), "cortex_distributor_forward_queue_length", "cortex_distributor_forward_queue_retries_total"))
+}
+
+func TestForwardingQueue_ShouldDropSamplesOnNonRecoverableErrorAndMaxRetries(t *testing.T) {
+	for _, tc := range []struct {
+		name           string
+		statusCode     int
+		expectedReason string
+	}{
+		{name: "non-recoverable error", statusCode: http.StatusBadRequest, expectedReason: droppedReasonNonRecoverableError},
+		{name: "max retries", statusCode: http.StatusInternalServerError, expectedReason: droppedReasonMaxRetries},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			srv := newQueueTestServer(t, tc.statusCode)
+
+			reg := prometheus.NewPedanticRegistry()
+			f := startTestForwarder(t, reg, newTestQueueConfig())
+
+			rules := mustNewForwardingRules(t, validation.ForwardingRules{"metric1": validation.ForwardingRule{Endpoint: srv.URL}})
+			req := f.NewRequest(context.Background(), "tenant", rules)
+			req.Add(newSample(t, time.Now().UnixMilli(), 1, "__name__", "metric1"))
+			require.NoError(t, <-req.Send(context.Background()))
+
+			require.Eventually(t, func() bool {
+				return testutil.ToFloat64(f.(*forwarder).queues.metrics.droppedSamples.WithLabelValues(tc.expectedReason)) == 1
+			}, 5*time.Second, 10*time.Millisecond)
+		})
+	}
+}
+
+func TestForwardingQueue_ShouldDropSamplesWhenQueueIsFull(t *testing.T) {
+	srv := newQueueTestServer(t, http.StatusOK)
+	srv.block()
+
+	cfg := newTestQueueConfig()
+	cfg.QueueCapacity = 1
+	cfg.QueueShards = 1
+
+	reg := prometheus.NewPedanticRegistry()
+	forwarder := startTestForwarder(t, reg, cfg)
+	rules := mustNewForwardingRules(t, validation.ForwardingRules{"metric1": validation.ForwardingRule{Endpoint: srv.URL}})
+
+	send := func() error {
+		req := forwarder.NewRequest(context.Background(), "tenant", rules)
+		req.Add(newSample(t, time.Now().UnixMilli(), 1, "__name__", "metric1"))
+		return <-req.Send(context.Background())
+	}
+
+	// The first request is being sent, and the second one fills the queue.
+	require.NoError(t, send())
+	require.Eventually(t, func() bool { return srv.receivedRequests() == 1 }, 5*time.Second, 10*time.Millisecond)
+	require.NoError(t, send())
+
+	// The third request is dropped, without failing the push request.
+	require.NoError(t, send())
+
+	srv.unblock()
+	require.Eventually(t, func() bool { return srv.receivedSamples() == 2 }, 5*time.Second, 10*time.Millisecond)
+
+	assert.NoError(t, testutil.GatherAndCompare(reg, strings.NewReader(`
+		# HELP cortex_distributor_forward_queue_dropped_samples_total The total number of samples the Distributor dropped instead of forwarding them.
+		# TYPE cortex_distributor_forward_queue_dropped_samples_total counter
+		cortex_distributor_forward_queue_dropped_samples_total{reason="queue-full"} 1
+	`), "cortex_distributor_forward_queue_dropped_samples_total"))
+}
+
+func TestForwardingQueue_ShouldSpillToDiskAndResumeAfterRestart(t *testing.T) {
+	srv := newQueueTestServer(t, http.StatusOK)
+	srv.block()
+
+	cfg := newTestQueueConfig()
+	cfg.QueueCapacity = 1
+	cfg.QueueShards = 1
+	cfg.QueueSpillDirectory = t.TempDir()
+
+	reg := prometheus.NewPedanticRegistry()
+	f := startTestForwarder(t, reg, cfg)
+	rules := mustNewForwardingRules(t, validation.ForwardingRules{"metric1": validation.ForwardingRule{Endpoints: []validation.ForwardingEndpoint{{
+		URL:         srv.URL,
+		BearerToken: validation.NewSecret("secret-token"),
+	}}}})
+
+	for i := 0; i < 4; i++ {
+		req := f.NewRequest(context.Background(), "tenant", rules)
+		req.Add(newSample(t, int64(i), float64(i), "__name__", "metric1"))
+		require.NoError(t, <-req.Send(context.Background()))
+
+		if i == 0 {
+			require.Eventually(t, func() bool { return srv.receivedRequests() == 1 }, 5*time.Second, 10*time.Millisecond)
+		}
+	}
+
+	// The first request is being sent, the second one is in memory and the others have been spilled to disk.
+	assert.NoError(t, testutil.GatherAndCompare(reg, strings.NewReader(`
+		# HELP cortex_distributor_forward_queue_length The number of forwarding requests buffered in memory, waiting to be sent.
+		# TYPE cortex_distributor_forward_queue_length gauge
+		cortex_distributor_forward_queue_length 1
+		# HELP cortex_distributor_forward_queue_spilled_length The number of forwarding requests spilled to disk, waiting to be sent.
+		# TYPE cortex_distributor_forward_queue_spilled_length gauge
+		cortex_distributor_forward_queue_spilled_length 2
+	`), "cortex_distributor_forward_queue_length", "cortex_distributor_forward_queue_spilled_length"))
+
+	// Stopping the forwarder spills the requests left in memory to disk.
+	require.NoError(t, services.StopAndAwaitTerminated(context.Background(), f))
+	srv.unblock()
+
+	// The credentials of the endpoint are never stored on disk.
+	require.NoError(t, filepath.WalkDir(cfg.QueueSpillDirectory, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		data, err := os.ReadFile(path)
+		require.NoError(t, err)
+		require.NotContains(t, string(data), "secret-token")
+		return nil
+	}))
+
+	// The spilled requests are resumed once samples are forwarded to the endpoint again.
+	restarted := startTestForwarder(t, prometheus.NewPedanticRegistry(), cfg)
+	req := restarted.NewRequest(context.Background(), "tenant", rules)
+	req.Add(newSample(t, 4, 4, "__name__", "metric1"))
+	require.NoError(t, <-req.Send(context.Background()))
+
+	// The request being sent while stopping may have been received anyway, so we check each sample has been received.
+	require.Eventually(t, func() bool { return srv.receivedTimestamps() == 5 }, 5*time.Second, 10*time.Millisecond)
+
+	// The spilled requests are removed from disk once sent.
+	require.Eventually(t, func() bool {
+		return testutil.ToFloat64(restarted.(*forwarder).queues.metrics.spilledLength) == 0
+	}, 5*time.Second, 10*time.Millisecond)
+}
+
+func TestForwardingQueue_ShouldCloseIdleEndpointQueues(t *testing.T) {
+	srv := newQueueTestServer(t, http.StatusOK)
+	srv.block()
+
+	cfg := newTestQueueConfig()
+	cfg.QueueSpillDirectory = t.TempDir()
+	cfg.QueueIdleTimeout = time.Minute
+
+	f := startTestForwarder(t, prometheus.NewPedanticRegistry(), cfg)
+	q := f.(*forwarder).queues
+	rules := mustNewForwardingRules(t, validation.ForwardingRules{"metric1": validation.ForwardingRule{Endpoint: srv.URL}})
+
+	send := func() {
+		req := f.NewRequest(context.Background(), "tenant", rules)
+		req.Add(newSample(t, time.Now().UnixMilli(), 1, "__name__", "metric1"))
+		require.NoError(t, <-req.Send(context.Background()))
+	}
+	endpoints := func() int {
+		q.endpointsMtx.Lock()
+		defer q.endpointsMtx.Unlock()
+		return len(q.endpoints)
+	}
+	spillDirs := func() int {
+		entries, err := os.ReadDir(cfg.QueueSpillDirectory)
+		require.NoError(t, err)
+		return len(entries)
+	}
+
+	send()
+	require.Equal(t, 1, endpoints())
+	require.Equal(t, 1, spillDirs())
+
+	// The endpoint queue isn't closed while it has requests left to send, even once idle.
+	require.Eventually(t, func() bool { return srv.receivedRequests() == 1 }, 5*time.Second, 10*time.Millisecond)
+	q.closeIdle(time.Now().Add(2 * cfg.QueueIdleTimeout))
+	require.Equal(t, 1, endpoints())
+
+	// The endpoint queue isn't closed before the idle timeout once the requests have been sent.
+	srv.unblock()
+	require.Eventually(t, func() bool { return srv.receivedSamples() == 1 }, 5*time.Second, 10*time.Millisecond)
+	q.closeIdle(time.Now())
+	require.Equal(t, 1, endpoints())
+
+	// The idle endpoint queue is closed along with its spill directory.
+	require.Eventually(t, func() bool {
+		q.closeIdle(time.Now().Add(2 * cfg.QueueIdleTimeout))
+		return endpoints() == 0
+	}, 5*time.Second, 10*time.Millisecond)
+	require.Equal(t, 0, spillDirs())
+
+	// The endpoint queue is created again once samples are forwarded to the endpoint.
+	send()
+	require.Eventually(t, func() bool { return srv.receivedSamples() == 2 }, 5*time.Second, 10*time.Millisecond)
+	require.Equal(t, 1, endpoints())
+	require.Equal(t, 1, spillDirs())
+}
+
+func startTestForwarder(t *testing.T, reg prometheus.Registerer, cfg Config) Forwarder {
+	forwarder := NewForwarder(reg, cfg, log.NewNopLogger())
+	require.NoError(t, services.StartAndAwaitRunning(context.Background(), forwarder))
+	t.Cleanup(func() {
+		_ = services.StopAndAwaitTerminated(context.Background(), forwarder)
+	})
+	return forwarder
+}
+
+// queueTestServer is a remote_write server replying with the configured status codes, in order.
+// Once all of them have been used, it keeps replying with the last one.
+type queueTestServer struct {
+	*httptest.Server
+
+	t           *testing.T
+	mtx         sync.Mutex
+	statusCodes []int
+	requests    int
+	samples     int
+	timestamps  map[int64]struct{}
+	blocked     chan struct{}
+}
+
+func newQueueTestServer(t *testing.T, statusCodes ...int) *queueTestServer {
+	// Status codes are consumed from the end.
+	reversed := make([]int, 0, len(statusCodes))
+	for i := len(statusCodes) - 1; i >= 0; i-- {
+		reversed = append(reversed, statusCodes[i])
+	}
+
+	s := &queueTestServer{t: t, statusCodes: reversed, timestamps: map[int64]struct{}{}}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.handle))
+	t.Cleanup(func() {
+		s.unblock()
+		s.Close()
+	})
+	return s
+}
+
+func (s *queueTestServer) handle(w http.ResponseWriter, req *http.Request) {
+	body, err := ioutil.ReadAll(req.Body)
+	require.NoError(s.t, err)
+
+	s.mtx.Lock()
+	s.requests++
+	blocked := s.blocked
+	s.mtx.Unlock()
+
+	if blocked != nil {
+		select {
+		case <-blocked:
+		case <-req.Context().Done():
+			return
+		}
+	}
+
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	status := s.statusCodes[len(s.statusCodes)-1]
+	if len(s.statusCodes) > 1 {
+		s.statusCodes = s.statusCodes[:len(s.statusCodes)-1]
+	}
+	if status/100 == 2 {
+		for _, series := range decodeBody(s.t, body).Timeseries {
+			for _, sample := range series.Samples {
+				s.samples++
+				s.timestamps[sample.TimestampMs] = struct{}{}
+			}
+		}
+	}
+
+	http.Error(w, "", status)
+}
+
+// block makes the server wait before replying until unblock is called.
+func (s *queueTestServer) block() {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	s.blocked = make(chan struct{})
+}
+
+func (s *queueTestServer) unblock() {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	if s.blocked != nil {
+		close(s.blocked)
+		s.blocked = nil
+	}
+}
+
+func (s *queueTestServer) receivedRequests() int {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	return s.requests
+}
+
+// receivedSamples returns the number of samples successfully received.
+func (s *queueTestServer) receivedSamples() int {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	return s.samples
+}
+
+// receivedTimestamps returns the number of unique timestamps of the samples successfully received.
+func (s *queueTestServer) receivedTimestamps() int {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	return len(s.timestamps)
+}
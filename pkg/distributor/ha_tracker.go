@@ -34,7 +34,7 @@ import (
 var (
 	errNegativeUpdateTimeoutJitterMax = errors.New("HA tracker max update timeout jitter shouldn't be negative")
 	errInvalidFailoverTimeout         = "HA Tracker failover timeout (%v) must be at least 1s greater than update timeout - max jitter (%v)"
-	errMemberlistUnsupported          = errors.New("memberlist is not supported by the HA tracker since gossip propagation is too slow for HA purposes")
+	errHAClusterNotFound              = errors.New("HA cluster not found")
 )
 
 type haTrackerLimits interface {
@@ -68,7 +68,7 @@ type HATrackerConfig struct {
 	// more than this duration
 	FailoverTimeout time.Duration `yaml:"ha_tracker_failover_timeout" category:"advanced"`
 
-	KVStore kv.Config `yaml:"kvstore" doc:"description=Backend storage to use for the ring. Please be aware that memberlist is not supported by the HA tracker since gossip propagation is too slow for HA purposes."`
+	KVStore kv.Config `yaml:"kvstore" doc:"description=Backend storage to use for the ring. When using memberlist, the elected replica changes take longer to propagate to all distributors than with consul or etcd, so samples of both replicas may be accepted for a short time after a failover."`
 }
 
 // RegisterFlags adds the flags required to config this to the given FlagSet.
@@ -96,10 +96,6 @@ func (cfg *HATrackerConfig) Validate() error {
 		return fmt.Errorf(errInvalidFailoverTimeout, cfg.FailoverTimeout, minFailureTimeout)
 	}
 
-	if cfg.KVStore.Store == "memberlist" {
-		return errMemberlistUnsupported
-	}
-
 	return nil
 }
 
@@ -239,19 +235,9 @@ func (h *haTracker) loop(ctx context.Context) error {
 		user := segments[0]
 		cluster := segments[1]
 
-		if replica.DeletedAt > 0 {
-			h.electedReplicaChanges.DeleteLabelValues(user, cluster)
-			h.electedReplicaTimestamp.DeleteLabelValues(user, cluster)
-
-			h.electedLock.Lock()
-			defer h.electedLock.Unlock()
-			userClusters := h.clusters[user]
-			if userClusters != nil {
-				delete(userClusters, cluster)
-				if len(userClusters) == 0 {
-					delete(h.clusters, user)
-				}
-			}
+		// With memberlist, the replicas marked for deletion are returned as empty descriptors.
+		if replica.DeletedAt > 0 || replica.isEmpty() {
+			h.removeFromCache(user, cluster)
 			return true
 		}
 
@@ -355,6 +341,13 @@ func (h *haTracker) cleanupOldReplicas(ctx context.Context, deadline time.Time)
 			continue
 		}
 
+		// Memberlist doesn't support deleting keys: the replicas marked for deletion are kept as tombstones,
+		// which are hidden from the clients and purged by ReplicaDesc.RemoveTombstones once they're older
+		// than -memberlist.left-ingesters-timeout, so there's nothing left to delete.
+		if desc.isEmpty() {
+			continue
+		}
+
 		if desc.DeletedAt > 0 {
 			if timestamp.Time(desc.DeletedAt).After(deadline) {
 				continue
@@ -383,7 +376,7 @@ func (h *haTracker) cleanupOldReplicas(ctx context.Context, deadline time.Time)
 		if desc.DeletedAt == 0 && timestamp.Time(desc.ReceivedAt).Before(deadline) {
 			err := h.client.CAS(ctx, key, func(in interface{}) (out interface{}, retry bool, err error) {
 				d, ok := in.(*ReplicaDesc)
-				if !ok || d == nil || d.DeletedAt > 0 || !timestamp.Time(desc.ReceivedAt).Before(deadline) {
+				if !ok || d == nil || d.DeletedAt > 0 || d.isEmpty() || !timestamp.Time(desc.ReceivedAt).Before(deadline) {
 					return nil, false, nil
 				}
 
@@ -469,6 +462,73 @@ func (h *haTracker) updateCache(userID, cluster string, desc *ReplicaDesc) {
 	h.electedReplicaTimestamp.WithLabelValues(userID, cluster).Set(float64(desc.ReceivedAt / 1000))
 }
 
+func (h *haTracker) removeFromCache(userID, cluster string) {
+	h.electedReplicaChanges.DeleteLabelValues(userID, cluster)
+	h.electedReplicaTimestamp.DeleteLabelValues(userID, cluster)
+
+	h.electedLock.Lock()
+	defer h.electedLock.Unlock()
+	userClusters := h.clusters[userID]
+	if userClusters != nil {
+		delete(userClusters, cluster)
+		if len(userClusters) == 0 {
+			delete(h.clusters, userID)
+		}
+	}
+}
+
+// forceElectReplica stores the replica in input as the elected replica for the cluster,
+// regardless of the currently elected replica and of the failover timeout.
+func (h *haTracker) forceElectReplica(ctx context.Context, userID, cluster, replica string, now time.Time) error {
+	key := fmt.Sprintf("%s/%s", userID, cluster)
+	var desc *ReplicaDesc
+	err := h.client.CAS(ctx, key, func(in interface{}) (out interface{}, retry bool, err error) {
+		desc = &ReplicaDesc{
+			Replica:    replica,
+			ReceivedAt: nextReceivedAt(in, now),
+			DeletedAt:  0,
+		}
+		return desc, true, nil
+	})
+	h.kvCASCalls.WithLabelValues(userID, cluster).Inc()
+	if err != nil {
+		return err
+	}
+
+	h.electedLock.Lock()
+	h.updateCache(userID, cluster, desc)
+	h.electedLock.Unlock()
+	return nil
+}
+
+// deleteCluster marks the elected replica of the cluster for deletion, so that the next replica
+// we receive a sample from gets elected. It returns errHAClusterNotFound if the cluster isn't tracked.
+func (h *haTracker) deleteCluster(ctx context.Context, userID, cluster string, now time.Time) error {
+	key := fmt.Sprintf("%s/%s", userID, cluster)
+	found := false
+	err := h.client.CAS(ctx, key, func(in interface{}) (out interface{}, retry bool, err error) {
+		desc, ok := in.(*ReplicaDesc)
+		if !ok || desc == nil || desc.DeletedAt > 0 || desc.isEmpty() {
+			found = false
+			return nil, false, nil
+		}
+
+		found = true
+		desc.DeletedAt = timestamp.FromTime(now)
+		return desc, true, nil
+	})
+	h.kvCASCalls.WithLabelValues(userID, cluster).Inc()
+	if err != nil {
+		return err
+	}
+	if !found {
+		return errHAClusterNotFound
+	}
+
+	h.removeFromCache(userID, cluster)
+	return nil
+}
+
 // If we do set the value then err will be nil and desc will contain the value we set.
 // If there is already a valid value in the store, return nil, nil.
 func (h *haTracker) updateKVStore(ctx context.Context, userID, cluster, replica string, now time.Time) error {
@@ -488,7 +548,7 @@ func (h *haTracker) updateKVStore(ctx context.Context, userID, cluster, replica
 		// Attempt to update KVStore to our timestamp and replica.
 		desc = &ReplicaDesc{
 			Replica:    replica,
-			ReceivedAt: timestamp.FromTime(now),
+			ReceivedAt: nextReceivedAt(in, now),
 			DeletedAt:  0,
 		}
 		return desc, true, nil
@@ -505,6 +565,18 @@ func (h *haTracker) updateKVStore(ctx context.Context, userID, cluster, replica
 	return err
 }
 
+// nextReceivedAt returns the timestamp to store for a replica elected at the given time, replacing the
+// stored value in input. The timestamp is guaranteed to be newer than the stored one, so that the
+// elected replica wins over the stored value when merged by memberlist, even if the clock of the
+// distributor which stored it is ahead of ours.
+func nextReceivedAt(in interface{}, now time.Time) int64 {
+	receivedAt := timestamp.FromTime(now)
+	if prev, ok := in.(*ReplicaDesc); ok && prev != nil && prev.ReceivedAt >= receivedAt {
+		receivedAt = prev.ReceivedAt + 1
+	}
+	return receivedAt
+}
+
 type replicasNotMatchError struct {
 	replica, elected string
 }
@@ -7,11 +7,15 @@ package distributor
 
 import (
 	_ "embed" // Used to embed html template
+	"errors"
+	"fmt"
 	"html/template"
 	"net/http"
 	"sort"
 	"time"
 
+	"github.com/go-kit/log/level"
+	"github.com/grafana/dskit/tenant"
 	"github.com/prometheus/prometheus/model/timestamp"
 
 	"github.com/grafana/mimir/pkg/util"
@@ -69,3 +73,65 @@ func (h *haTracker) ServeHTTP(w http.ResponseWriter, req *http.Request) {
 		Now:     time.Now(),
 	}, haTrackerStatusPageTemplate, req)
 }
+
+const (
+	haTrackerActionElect  = "elect"
+	haTrackerActionDelete = "delete"
+)
+
+// AdminHandler allows to manually update the HA tracker state of the tenant of the request.
+// The "elect" action forces the election of the "replica" for the "cluster",
+// while the "delete" action clears the "cluster" entry, so that the next replica
+// we receive a sample from gets elected.
+func (h *haTracker) AdminHandler(w http.ResponseWriter, req *http.Request) {
+	if !h.cfg.EnableHATracker {
+		http.Error(w, "HA tracker is not enabled", http.StatusBadRequest)
+		return
+	}
+
+	userID, err := tenant.TenantID(req.Context())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+
+	if err := req.ParseForm(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	action := req.Form.Get("action")
+	cluster := req.Form.Get("cluster")
+	replica := req.Form.Get("replica")
+	if cluster == "" {
+		http.Error(w, "cluster is required", http.StatusBadRequest)
+		return
+	}
+
+	switch action {
+	case haTrackerActionElect:
+		if replica == "" {
+			http.Error(w, "replica is required", http.StatusBadRequest)
+			return
+		}
+		err = h.forceElectReplica(req.Context(), userID, cluster, replica, time.Now())
+	case haTrackerActionDelete:
+		err = h.deleteCluster(req.Context(), userID, cluster, time.Now())
+	default:
+		http.Error(w, fmt.Sprintf("invalid action %q, supported actions are %q and %q", action, haTrackerActionElect, haTrackerActionDelete), http.StatusBadRequest)
+		return
+	}
+
+	if errors.Is(err, errHAClusterNotFound) {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		level.Error(h.logger).Log("msg", "failed to update HA tracker", "action", action, "user", userID, "cluster", cluster, "replica", replica, "err", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	level.Info(h.logger).Log("msg", "updated HA tracker", "action", action, "user", userID, "cluster", cluster, "replica", replica)
+	http.Redirect(w, req, req.URL.Path, http.StatusSeeOther)
+}
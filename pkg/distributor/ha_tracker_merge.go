@@ -0,0 +1,77 @@
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package distributor
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/grafana/dskit/kv/memberlist"
+	"github.com/prometheus/prometheus/model/timestamp"
+)
+
+// Merge implements memberlist.Mergeable, to use the HA tracker with the memberlist KV store.
+// The merge is last-write-wins: the descriptor with the latest ReceivedAt wins, and ties are broken by
+// DeletedAt (so that a replica marked for deletion stays deleted) and then by replica name,
+// which makes the merge commutative, associative and idempotent.
+func (d *ReplicaDesc) Merge(mergeable memberlist.Mergeable, _ bool) (memberlist.Mergeable, error) {
+	if mergeable == nil {
+		return nil, nil
+	}
+
+	other, ok := mergeable.(*ReplicaDesc)
+	if !ok {
+		return nil, fmt.Errorf("expected *distributor.ReplicaDesc, got %T", mergeable)
+	}
+	if other == nil || !other.isNewerThan(d) {
+		return nil, nil
+	}
+
+	*d = *other
+	return other.Clone(), nil
+}
+
+// isNewerThan returns whether d wins over other when merging them.
+func (d *ReplicaDesc) isNewerThan(other *ReplicaDesc) bool {
+	if d.ReceivedAt != other.ReceivedAt {
+		return d.ReceivedAt > other.ReceivedAt
+	}
+	if d.DeletedAt != other.DeletedAt {
+		return d.DeletedAt > other.DeletedAt
+	}
+	return d.Replica > other.Replica
+}
+
+// MergeContent implements memberlist.Mergeable. An empty descriptor, left by a removed tombstone, has no content.
+func (d *ReplicaDesc) MergeContent() []string {
+	if d.isEmpty() {
+		return nil
+	}
+	return []string{d.Replica}
+}
+
+// RemoveTombstones implements memberlist.Mergeable. Memberlist doesn't support deleting keys, so a replica
+// marked for deletion is kept as a tombstone, to prevent the deletion from being reverted by the other members
+// gossiping an older value. The tombstone is removed once it has been marked for deletion before the limit
+// (or regardless of when, if the limit is zero), leaving an empty descriptor which is not gossiped anymore.
+func (d *ReplicaDesc) RemoveTombstones(limit time.Time) (total, removed int) {
+	if d.DeletedAt <= 0 {
+		return 0, 0
+	}
+	if limit.IsZero() || timestamp.Time(d.DeletedAt).Before(limit) {
+		*d = ReplicaDesc{}
+		return 0, 1
+	}
+	return 1, 0
+}
+
+// isEmpty returns whether d doesn't hold any replica, like after its tombstone has been removed.
+func (d *ReplicaDesc) isEmpty() bool {
+	return d.Replica == "" && d.ReceivedAt == 0 && d.DeletedAt == 0
+}
+
+// Clone implements memberlist.Mergeable.
+func (d *ReplicaDesc) Clone() memberlist.Mergeable {
+	clone := *d
+	return &clone
+}
@@ -0,0 +1,139 @@
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package distributor
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReplicaDesc_Merge(t *testing.T) {
+	tests := map[string]struct {
+		local          *ReplicaDesc
+		incoming       *ReplicaDesc
+		expectedResult *ReplicaDesc
+		expectedChange bool
+	}{
+		"newer replica wins": {
+			local:          &ReplicaDesc{Replica: "r1", ReceivedAt: 1000},
+			incoming:       &ReplicaDesc{Replica: "r2", ReceivedAt: 2000},
+			expectedResult: &ReplicaDesc{Replica: "r2", ReceivedAt: 2000},
+			expectedChange: true,
+		},
+		"older replica is ignored": {
+			local:          &ReplicaDesc{Replica: "r1", ReceivedAt: 2000},
+			incoming:       &ReplicaDesc{Replica: "r2", ReceivedAt: 1000},
+			expectedResult: &ReplicaDesc{Replica: "r1", ReceivedAt: 2000},
+			expectedChange: false,
+		},
+		"same value is ignored": {
+			local:          &ReplicaDesc{Replica: "r1", ReceivedAt: 1000},
+			incoming:       &ReplicaDesc{Replica: "r1", ReceivedAt: 1000},
+			expectedResult: &ReplicaDesc{Replica: "r1", ReceivedAt: 1000},
+			expectedChange: false,
+		},
+		"deletion wins over the same election": {
+			local:          &ReplicaDesc{Replica: "r1", ReceivedAt: 1000},
+			incoming:       &ReplicaDesc{Replica: "r1", ReceivedAt: 1000, DeletedAt: 3000},
+			expectedResult: &ReplicaDesc{Replica: "r1", ReceivedAt: 1000, DeletedAt: 3000},
+			expectedChange: true,
+		},
+		"newer election wins over deletion": {
+			local:          &ReplicaDesc{Replica: "r1", ReceivedAt: 1000, DeletedAt: 3000},
+			incoming:       &ReplicaDesc{Replica: "r2", ReceivedAt: 4000},
+			expectedResult: &ReplicaDesc{Replica: "r2", ReceivedAt: 4000},
+			expectedChange: true,
+		},
+		"ties are broken by replica name": {
+			local:          &ReplicaDesc{Replica: "r1", ReceivedAt: 1000},
+			incoming:       &ReplicaDesc{Replica: "r2", ReceivedAt: 1000},
+			expectedResult: &ReplicaDesc{Replica: "r2", ReceivedAt: 1000},
+			expectedChange: true,
+		},
+	}
+
+	for testName, testData := range tests {
+		t.Run(testName, func(t *testing.T) {
+			// Merging in both directions must converge to the same result.
+			reversed := testData.incoming.Clone().(*ReplicaDesc)
+			_, err := reversed.Merge(testData.local.Clone(), false)
+			require.NoError(t, err)
+			assert.Equal(t, testData.expectedResult, reversed)
+
+			change, err := testData.local.Merge(testData.incoming, false)
+			require.NoError(t, err)
+			assert.Equal(t, testData.expectedResult, testData.local)
+
+			if testData.expectedChange {
+				assert.Equal(t, testData.expectedResult, change)
+			} else {
+				assert.Nil(t, change)
+			}
+		})
+	}
+}
+
+func TestReplicaDesc_MergeInvalid(t *testing.T) {
+	desc := &ReplicaDesc{Replica: "r1", ReceivedAt: 1000}
+
+	change, err := desc.Merge(nil, false)
+	require.NoError(t, err)
+	assert.Nil(t, change)
+
+	change, err = desc.Merge((*ReplicaDesc)(nil), false)
+	require.NoError(t, err)
+	assert.Nil(t, change)
+
+	assert.Equal(t, &ReplicaDesc{Replica: "r1", ReceivedAt: 1000}, desc)
+}
+
+func TestReplicaDesc_RemoveTombstones(t *testing.T) {
+	now := time.Now()
+	deletedAt := now.Add(-time.Hour).UnixMilli()
+
+	tests := map[string]struct {
+		desc            *ReplicaDesc
+		limit           time.Time
+		expectedDesc    *ReplicaDesc
+		expectedTotal   int
+		expectedRemoved int
+	}{
+		"replica not marked for deletion is kept": {
+			desc:         &ReplicaDesc{Replica: "r1", ReceivedAt: 1000},
+			limit:        now,
+			expectedDesc: &ReplicaDesc{Replica: "r1", ReceivedAt: 1000},
+		},
+		"replica marked for deletion after the limit is kept": {
+			desc:          &ReplicaDesc{Replica: "r1", ReceivedAt: 1000, DeletedAt: deletedAt},
+			limit:         now.Add(-2 * time.Hour),
+			expectedDesc:  &ReplicaDesc{Replica: "r1", ReceivedAt: 1000, DeletedAt: deletedAt},
+			expectedTotal: 1,
+		},
+		"replica marked for deletion before the limit is removed": {
+			desc:            &ReplicaDesc{Replica: "r1", ReceivedAt: 1000, DeletedAt: deletedAt},
+			limit:           now,
+			expectedDesc:    &ReplicaDesc{},
+			expectedRemoved: 1,
+		},
+		"replica marked for deletion is removed if the limit is zero": {
+			desc:            &ReplicaDesc{Replica: "r1", ReceivedAt: 1000, DeletedAt: deletedAt},
+			expectedDesc:    &ReplicaDesc{},
+			expectedRemoved: 1,
+		},
+	}
+
+	for testName, testData := range tests {
+		t.Run(testName, func(t *testing.T) {
+			total, removed := testData.desc.RemoveTombstones(testData.limit)
+			assert.Equal(t, testData.expectedTotal, total)
+			assert.Equal(t, testData.expectedRemoved, removed)
+			assert.Equal(t, testData.expectedDesc, testData.desc)
+
+			// An empty descriptor is not gossiped.
+			assert.Equal(t, testData.expectedRemoved == 0, len(testData.desc.MergeContent()) > 0)
+		})
+	}
+}
@@ -8,6 +8,9 @@ package distributor
 import (
 	"context"
 	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
 	"strings"
 	"testing"
 	"time"
@@ -15,7 +18,9 @@ import (
 	"github.com/go-kit/log"
 	"github.com/grafana/dskit/flagext"
 	"github.com/grafana/dskit/kv"
+	"github.com/grafana/dskit/kv/codec"
 	"github.com/grafana/dskit/kv/consul"
+	"github.com/grafana/dskit/kv/memberlist"
 	"github.com/grafana/dskit/ring"
 	"github.com/grafana/dskit/services"
 	"github.com/grafana/dskit/test"
@@ -26,6 +31,7 @@ import (
 	"github.com/prometheus/prometheus/model/timestamp"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"github.com/thanos-io/thanos/pkg/discovery/dns"
 	"github.com/weaveworks/common/user"
 
 	"github.com/grafana/mimir/pkg/mimirpb"
@@ -116,7 +122,7 @@ func TestHATrackerConfig_Validate(t *testing.T) {
 			}(),
 			expectedErr: nil,
 		},
-		"should fail if KV backend is set to memberlist": {
+		"should pass if KV backend is set to memberlist": {
 			cfg: func() HATrackerConfig {
 				cfg := HATrackerConfig{}
 				flagext.DefaultValues(&cfg)
@@ -124,7 +130,7 @@ func TestHATrackerConfig_Validate(t *testing.T) {
 
 				return cfg
 			}(),
-			expectedErr: errMemberlistUnsupported,
+			expectedErr: nil,
 		},
 	}
 
@@ -852,7 +858,8 @@ func checkReplicaDeletionState(t *testing.T, duration time.Duration, c *haTracke
 	val, err := c.client.Get(context.Background(), key)
 	require.NoError(t, err)
 
-	existsInKV := val != nil
+	// With memberlist, the replicas marked for deletion are returned as empty descriptors.
+	existsInKV := val != nil && !val.(*ReplicaDesc).isEmpty()
 	require.Equal(t, expectedExistsInKV, existsInKV, "exists in KV")
 
 	if val != nil {
@@ -860,3 +867,137 @@ func checkReplicaDeletionState(t *testing.T, duration time.Duration, c *haTracke
 		require.Equal(t, expectedMarkedForDeletion, markedForDeletion, "KV entry marked for deletion")
 	}
 }
+
+func TestHATracker_AdminHandler(t *testing.T) {
+	const userID = "user"
+
+	kvStore, closer := consul.NewInMemoryClient(GetReplicaDescCodec(), log.NewNopLogger(), nil)
+	t.Cleanup(func() { assert.NoError(t, closer.Close()) })
+
+	c, err := newHATracker(HATrackerConfig{
+		EnableHATracker:        true,
+		KVStore:                kv.Config{Mock: kv.PrefixClient(kvStore, "prefix")},
+		UpdateTimeout:          time.Second,
+		UpdateTimeoutJitterMax: 0,
+		FailoverTimeout:        time.Minute,
+	}, trackerLimits{maxClusters: 100}, nil, log.NewNopLogger())
+	require.NoError(t, err)
+	require.NoError(t, services.StartAndAwaitRunning(context.Background(), c))
+	defer services.StopAndAwaitTerminated(context.Background(), c) //nolint:errcheck
+
+	now := time.Now()
+	require.NoError(t, c.checkReplica(context.Background(), userID, "c1", "r1", now))
+	checkReplicaTimestamp(t, time.Second, c, userID, "c1", "r1", now)
+
+	post := func(orgID string, form url.Values) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/distributor/ha_tracker", strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		if orgID != "" {
+			req = req.WithContext(user.InjectOrgID(req.Context(), orgID))
+		}
+		rec := httptest.NewRecorder()
+		c.AdminHandler(rec, req)
+		return rec
+	}
+
+	// Invalid requests.
+	assert.Equal(t, http.StatusUnauthorized, post("", url.Values{"action": {"delete"}, "cluster": {"c1"}}).Code)
+	assert.Equal(t, http.StatusBadRequest, post(userID, url.Values{"action": {"unknown"}, "cluster": {"c1"}}).Code)
+	assert.Equal(t, http.StatusBadRequest, post(userID, url.Values{"action": {"delete"}}).Code)
+	assert.Equal(t, http.StatusBadRequest, post(userID, url.Values{"action": {"elect"}, "cluster": {"c1"}}).Code)
+	assert.Equal(t, http.StatusNotFound, post(userID, url.Values{"action": {"delete"}, "cluster": {"unknown"}}).Code)
+	assert.Equal(t, http.StatusNotFound, post("another-user", url.Values{"action": {"delete"}, "cluster": {"c1"}}).Code)
+
+	// Force the election of another replica, within the failover timeout.
+	assert.Equal(t, http.StatusSeeOther, post(userID, url.Values{"action": {"elect"}, "cluster": {"c1"}, "replica": {"r2"}}).Code)
+	assert.ErrorIs(t, c.checkReplica(context.Background(), userID, "c1", "r1", time.Now()), replicasNotMatchError{})
+	assert.NoError(t, c.checkReplica(context.Background(), userID, "c1", "r2", time.Now()))
+
+	val, err := c.client.Get(context.Background(), userID+"/c1")
+	require.NoError(t, err)
+	assert.Equal(t, "r2", val.(*ReplicaDesc).Replica)
+
+	// Clear the cluster entry: the next replica we receive a sample from gets elected.
+	assert.Equal(t, http.StatusSeeOther, post(userID, url.Values{"action": {"delete"}, "cluster": {"c1"}}).Code)
+	checkReplicaDeletionState(t, time.Second, c, userID, "c1", false, true, true)
+	assert.Equal(t, http.StatusNotFound, post(userID, url.Values{"action": {"delete"}, "cluster": {"c1"}}).Code)
+
+	require.NoError(t, c.checkReplica(context.Background(), userID, "c1", "r1", time.Now()))
+	assert.ErrorIs(t, c.checkReplica(context.Background(), userID, "c1", "r2", time.Now()), replicasNotMatchError{})
+}
+
+func TestHATracker_AdminHandler_Disabled(t *testing.T) {
+	c, err := newHATracker(HATrackerConfig{EnableHATracker: false}, trackerLimits{}, nil, log.NewNopLogger())
+	require.NoError(t, err)
+
+	req := httptest.NewRequest(http.MethodPost, "/distributor/ha_tracker?action=delete&cluster=c1", nil)
+	req = req.WithContext(user.InjectOrgID(req.Context(), "user"))
+	rec := httptest.NewRecorder()
+	c.AdminHandler(rec, req)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
+
+func TestHATracker_MemberlistKV(t *testing.T) {
+	const (
+		userID  = "user"
+		cluster = "c1"
+	)
+
+	var mlCfg memberlist.KVConfig
+	flagext.DefaultValues(&mlCfg)
+	mlCfg.TCPTransport = memberlist.TCPTransportConfig{
+		BindAddrs: []string{"127.0.0.1"},
+		BindPort:  0,
+	}
+	mlCfg.Codecs = []codec.Codec{GetReplicaDescCodec()}
+
+	mkv := memberlist.NewKV(mlCfg, log.NewNopLogger(), dns.NewProvider(log.NewNopLogger(), nil, dns.GolangResolverType), nil)
+	require.NoError(t, services.StartAndAwaitRunning(context.Background(), mkv))
+	t.Cleanup(func() { assert.NoError(t, services.StopAndAwaitTerminated(context.Background(), mkv)) })
+
+	var cfg HATrackerConfig
+	flagext.DefaultValues(&cfg)
+	cfg.EnableHATracker = true
+	cfg.UpdateTimeout = time.Second
+	cfg.UpdateTimeoutJitterMax = 0
+	cfg.FailoverTimeout = 2 * time.Second
+	cfg.KVStore.Store = "memberlist"
+	cfg.KVStore.MemberlistKV = func() (*memberlist.KV, error) { return mkv, nil }
+	require.NoError(t, cfg.Validate())
+
+	reg := prometheus.NewPedanticRegistry()
+	c, err := newHATracker(cfg, trackerLimits{maxClusters: 100}, reg, log.NewNopLogger())
+	require.NoError(t, err)
+	require.NoError(t, services.StartAndAwaitRunning(context.Background(), c))
+	defer services.StopAndAwaitTerminated(context.Background(), c) //nolint:errcheck
+
+	// The first replica gets elected, and the second one fails over after the timeout.
+	now := time.Now().Add(-10 * time.Second)
+	require.NoError(t, c.checkReplica(context.Background(), userID, cluster, "r1", now))
+	checkReplicaTimestamp(t, time.Second, c, userID, cluster, "r1", now)
+	assert.ErrorIs(t, c.checkReplica(context.Background(), userID, cluster, "r2", now), replicasNotMatchError{})
+
+	now = now.Add(5 * time.Second)
+	require.NoError(t, c.updateKVStore(context.Background(), userID, cluster, "r2", now))
+	checkReplicaTimestamp(t, time.Second, c, userID, cluster, "r2", now)
+
+	// Old replicas are marked for deletion. Memberlist doesn't support deleting keys, so they're kept
+	// as tombstones in the KV store, which are hidden from the clients.
+	c.cleanupOldReplicas(context.Background(), now.Add(time.Second))
+	checkReplicaDeletionState(t, time.Second, c, userID, cluster, false, false, false)
+
+	c.cleanupOldReplicas(context.Background(), time.Now().Add(time.Hour))
+	checkReplicaDeletionState(t, time.Second, c, userID, cluster, false, false, false)
+
+	require.NoError(t, testutil.GatherAndCompare(reg, strings.NewReader(`
+		# HELP cortex_ha_tracker_replicas_cleanup_delete_failed_total Number of elected replicas that failed to be marked for deletion, or deleted.
+		# TYPE cortex_ha_tracker_replicas_cleanup_delete_failed_total counter
+		cortex_ha_tracker_replicas_cleanup_delete_failed_total 0
+	`), "cortex_ha_tracker_replicas_cleanup_delete_failed_total"))
+
+	// A replica marked for deletion is replaced by the next elected one.
+	now = time.Now()
+	require.NoError(t, c.checkReplica(context.Background(), userID, cluster, "r1", now))
+	checkReplicaTimestamp(t, time.Second, c, userID, cluster, "r1", now)
+	checkReplicaDeletionState(t, time.Second, c, userID, cluster, true, true, false)
+}
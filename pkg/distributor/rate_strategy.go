@@ -94,6 +94,34 @@ func (s *ingestionRateStrategy) Burst(tenantID string) int {
 	return s.limits.IngestionBurstSize(tenantID)
 }
 
+type ingestionBytesRateStrategy struct {
+	limits *validation.Overrides
+}
+
+func newIngestionBytesRateStrategy(limits *validation.Overrides) limiter.RateLimiterStrategy {
+	return &ingestionBytesRateStrategy{
+		limits: limits,
+	}
+}
+
+func (s *ingestionBytesRateStrategy) Limit(tenantID string) float64 {
+	if lm := s.limits.IngestionBytesRate(tenantID); lm > 0 {
+		return lm
+	}
+	return float64(rate.Inf)
+}
+
+func (s *ingestionBytesRateStrategy) Burst(tenantID string) int {
+	if s.limits.IngestionBytesRate(tenantID) <= 0 {
+		// Burst is ignored when limit = rate.Inf
+		return 0
+	}
+	if lm := s.limits.IngestionBytesBurstSize(tenantID); lm > 0 {
+		return lm
+	}
+	return math.MaxInt
+}
+
 type infiniteStrategy struct{}
 
 func newInfiniteRateStrategy() limiter.RateLimiterStrategy {
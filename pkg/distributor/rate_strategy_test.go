@@ -33,6 +33,38 @@ func TestIngestionRateStrategy(t *testing.T) {
 		assert.Equal(t, strategy.Burst("test"), 10000)
 	})
 
+	t.Run("ingestion bytes rate limiter should share the limit across the number of distributors", func(t *testing.T) {
+		// Init limits overrides
+		overrides, err := validation.NewOverrides(validation.Limits{
+			IngestionBytesRate:      float64(1000),
+			IngestionBytesBurstSize: 10000,
+		}, nil)
+		require.NoError(t, err)
+
+		mockRing := newReadLifecyclerMock()
+		mockRing.On("HealthyInstancesCount").Return(2)
+
+		strategy := newGlobalRateStrategy(newIngestionBytesRateStrategy(overrides), mockRing)
+		assert.Equal(t, strategy.Limit("test"), float64(500))
+		assert.Equal(t, strategy.Burst("test"), 10000)
+	})
+
+	t.Run("ingestion bytes rate limiter should be unlimited when disabled", func(t *testing.T) {
+		// Init limits overrides
+		overrides, err := validation.NewOverrides(validation.Limits{
+			IngestionBytesRate:      0,
+			IngestionBytesBurstSize: 10000,
+		}, nil)
+		require.NoError(t, err)
+
+		mockRing := newReadLifecyclerMock()
+		mockRing.On("HealthyInstancesCount").Return(2)
+
+		strategy := newGlobalRateStrategy(newIngestionBytesRateStrategy(overrides), mockRing)
+		assert.Equal(t, strategy.Limit("test"), float64(rate.Inf))
+		assert.Equal(t, strategy.Burst("test"), 0)
+	})
+
 	t.Run("infinite rate limiter should return unlimited settings", func(t *testing.T) {
 		strategy := newInfiniteRateStrategy()
 
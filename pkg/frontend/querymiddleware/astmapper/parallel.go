@@ -10,6 +10,7 @@ import (
 
 	"github.com/go-kit/log"
 	"github.com/go-kit/log/level"
+	"github.com/prometheus/prometheus/model/labels"
 	"github.com/prometheus/prometheus/promql/parser"
 )
 
@@ -21,6 +22,20 @@ var summableAggregates = map[parser.ItemType]struct{}{
 	parser.AVG:   {},
 }
 
+// selectionAggregates is the list of aggregations selecting a subset of the input series. They can be
+// parallelized only when their parameter is a constant, so that it's the same in every shard.
+var selectionAggregates = map[parser.ItemType]struct{}{
+	parser.TOPK:    {},
+	parser.BOTTOMK: {},
+}
+
+// labelsAlteringFuncs is the list of functions changing the labels of the input series, other
+// than dropping the metric name.
+var labelsAlteringFuncs = []string{
+	"label_join",
+	"label_replace",
+}
+
 // NonParallelFuncs is the list of functions that shouldn't be parallelized.
 var NonParallelFuncs = []string{
 	// The following functions are not safe to parallelize.
@@ -63,8 +78,7 @@ func CanParallelize(node parser.Node, logger log.Logger) bool {
 		return true
 
 	case *parser.AggregateExpr:
-		_, ok := summableAggregates[n.Op]
-		if !ok {
+		if !isParallelizableAggregation(n) {
 			return false
 		}
 
@@ -84,9 +98,15 @@ func CanParallelize(node parser.Node, logger log.Logger) bool {
 		parallelisable := func(a, b parser.Node) bool {
 			return CanParallelize(a, logger) && noAggregates(a) && isConstantScalar(b)
 		}
-		// If n.VectorMatching is not nil, then both hands are vector operators, so none of them is a constant scalar, so we can't shard it.
-		// It is just a shortcut, but the other two operations should imply the same.
-		return n.VectorMatching == nil && (parallelisable(n.LHS, n.RHS) || parallelisable(n.RHS, n.LHS))
+		// If n.VectorMatching is not nil, then both hands are vector operators, so none of them is a constant scalar.
+		if n.VectorMatching == nil {
+			return parallelisable(n.LHS, n.RHS) || parallelisable(n.RHS, n.LHS)
+		}
+		// A binary operation between two vectors can be parallelised only if the matching series of both hands
+		// are guaranteed to belong to the same shard, see matchesSeriesOfSameShard().
+		return matchesSeriesOfSameShard(n) &&
+			CanParallelize(n.LHS, logger) && noAggregates(n.LHS) &&
+			CanParallelize(n.RHS, logger) && noAggregates(n.RHS)
 
 	case *parser.Call:
 		if n.Func == nil {
@@ -127,6 +147,18 @@ func CanParallelize(node parser.Node, logger log.Logger) bool {
 	}
 }
 
+// isParallelizableAggregation returns true if the given aggregation operation can be parallelized,
+// regardless of the expression it's applied to.
+func isParallelizableAggregation(n *parser.AggregateExpr) bool {
+	if _, ok := summableAggregates[n.Op]; ok {
+		return true
+	}
+	if _, ok := selectionAggregates[n.Op]; ok {
+		return isConstantScalar(n.Param)
+	}
+	return false
+}
+
 // containsAggregateExpr returns true if the given node contains an aggregate expression within its children.
 func containsAggregateExpr(n parser.Node) bool {
 	containsAggregate, _ := anyNode(n, isAggregateExpr)
@@ -160,6 +192,51 @@ func argsWithDefaults(call *parser.Call) parser.Expressions {
 	return call.Args
 }
 
+// matchesSeriesOfSameShard returns true if the given binary operation between two vectors only matches
+// series belonging to the same shard. A series shard is computed from all its labels, so this is the case
+// when the operation matches on all labels (except the metric name) and every series selector of both
+// hands selects the same metric name, without altering the labels of the selected series: the matching
+// series have the same labels in this case, so they belong to the same shard.
+func matchesSeriesOfSameShard(n *parser.BinaryExpr) bool {
+	if n.VectorMatching == nil || n.VectorMatching.On || len(n.VectorMatching.MatchingLabels) > 0 {
+		return false
+	}
+	if n.VectorMatching.Card != parser.CardOneToOne && n.VectorMatching.Card != parser.CardManyToMany {
+		return false
+	}
+
+	metricName := ""
+	sameMetricName := true
+	parser.Inspect(n, func(node parser.Node, _ []parser.Node) error {
+		switch node := node.(type) {
+		case *parser.VectorSelector:
+			name, ok := metricNameEqualMatcherValue(node.LabelMatchers)
+			if !ok || (metricName != "" && metricName != name) {
+				sameMetricName = false
+			}
+			metricName = name
+		case *parser.Call:
+			for _, fn := range labelsAlteringFuncs {
+				if node.Func.Name == fn {
+					sameMetricName = false
+				}
+			}
+		}
+		return nil
+	})
+
+	return sameMetricName && metricName != ""
+}
+
+func metricNameEqualMatcherValue(matchers []*labels.Matcher) (string, bool) {
+	for _, m := range matchers {
+		if m.Name == labels.MetricName && m.Type == labels.MatchEqual {
+			return m.Value, true
+		}
+	}
+	return "", false
+}
+
 func noAggregates(n parser.Node) bool {
 	hasAggregates, _ := anyNode(n, isAggregateExpr)
 	return !hasAggregates
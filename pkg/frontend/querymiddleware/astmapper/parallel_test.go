@@ -185,6 +185,26 @@ func TestCanParallel_String(t *testing.T) {
 			[10m:])`,
 			false,
 		},
+		{
+			`topk(10, rate(foo[1m]))`,
+			true,
+		},
+		{
+			`topk(scalar(bar), rate(foo[1m]))`,
+			false,
+		},
+		{
+			`rate(foo[1m]) / rate(foo[1m] offset 1d)`,
+			true,
+		},
+		{
+			`rate(foo[1m]) / ignoring(bar) rate(foo[1m] offset 1d)`,
+			false,
+		},
+		{
+			`rate(foo[1m]) / rate(bar[1m])`,
+			false,
+		},
 	}
 
 	for i, c := range testExpr {
@@ -31,6 +31,16 @@ func NewSharding(shards int, logger log.Logger) (ASTMapper, error) {
 
 type squasher = func(...parser.Node) (parser.Expr, error)
 
+// shardableRangeFuncs is the list of range vector functions which are sharded even when they're
+// not wrapped by an aggregation. Sharding them doesn't reduce the number of series returned to the
+// query-frontend, but they're expensive to compute over long ranges so it's worth to run them in parallel.
+var shardableRangeFuncs = map[string]struct{}{
+	"holt_winters":       {},
+	"quantile_over_time": {},
+	"stddev_over_time":   {},
+	"stdvar_over_time":   {},
+}
+
 type shardSummer struct {
 	shards       int
 	currentShard *int
@@ -71,6 +81,9 @@ func (summer *shardSummer) MapNode(node parser.Node, stats *MapperStats) (mapped
 		if CanParallelize(n, summer.logger) {
 			return summer.shardAggregate(n, stats)
 		}
+		if !isParallelizableAggregation(n) {
+			stats.AddNotShardableReason(NotShardableReasonAggregation)
+		}
 		return n, false, nil
 
 	case *parser.VectorSelector:
@@ -89,25 +102,47 @@ func (summer *shardSummer) MapNode(node parser.Node, stats *MapperStats) (mapped
 
 	case *parser.Call:
 		// only shard the most outer function call.
-		if summer.currentShard == nil {
-			// Only shards Subqueries, they are parallelizable if they are parallelizable themselves
+		if summer.currentShard != nil {
+			return n, false, nil
+		}
+		if !ParallelizableFunc(*n.Func) {
+			stats.AddNotShardableReason(NotShardableReasonFunction)
+			return n, false, nil
+		}
+
+		rangeArg, ok := rangeVectorArg(n)
+		if !ok {
+			return n, false, nil
+		}
+
+		switch rangeArg.(type) {
+		case *parser.SubqueryExpr:
+			// Subqueries are parallelizable if they are parallelizable themselves
 			// and they don't contain aggregations over series in children nodes.
-			if isSubquery(n) {
-				if containsAggregateExpr(n) {
-					return n, true, nil
-				}
-				if !CanParallelize(n, summer.logger) {
-					return n, true, nil
-				}
+			if containsAggregateExpr(n) {
+				stats.AddNotShardableReason(NotShardableReasonSubquery)
+				return n, true, nil
+			}
+			if !CanParallelize(n, summer.logger) {
+				stats.AddNotShardableReason(NotShardableReasonFunction)
+				return n, true, nil
+			}
+			return summer.shardAndSquashFuncCall(n, stats)
+
+		case *parser.MatrixSelector:
+			// Range vector functions don't reduce the number of series, so we only shard the expensive ones.
+			if _, ok := shardableRangeFuncs[n.Func.Name]; ok {
 				return summer.shardAndSquashFuncCall(n, stats)
 			}
-			return n, false, nil
 		}
 		return n, false, nil
 
 	case *parser.BinaryExpr:
 		if summer.currentShard == nil {
 			if !CanParallelize(n, summer.logger) {
+				if n.VectorMatching != nil && noAggregates(n) && !matchesSeriesOfSameShard(n) {
+					stats.AddNotShardableReason(NotShardableReasonBinaryOperation)
+				}
 				return n, false, nil
 			}
 
@@ -222,6 +257,12 @@ func (summer *shardSummer) shardAggregate(expr *parser.AggregateExpr, stats *Map
 			return nil, false, err
 		}
 		return mapped, true, nil
+	case parser.TOPK, parser.BOTTOMK:
+		mapped, err = summer.shardTopKBottomK(expr, stats)
+		if err != nil {
+			return nil, false, err
+		}
+		return mapped, true, nil
 	}
 
 	// If the aggregation operation is not shardable, we have to return the input
@@ -308,6 +349,30 @@ func (summer *shardSummer) shardMinMax(expr *parser.AggregateExpr, stats *Mapper
 	}, nil
 }
 
+// shardTopKBottomK attempts to shard the given TOPK/BOTTOMK aggregation expression.
+func (summer *shardSummer) shardTopKBottomK(expr *parser.AggregateExpr, stats *MapperStats) (result parser.Node, err error) {
+	// We expect the given aggregation is either a TOPK or BOTTOMK.
+	if expr.Op != parser.TOPK && expr.Op != parser.BOTTOMK {
+		return nil, errors.Errorf("expected TOPK or BOTTOMK aggregation while got %s", expr.Op.String())
+	}
+
+	// The top K series of each group are among the top K series of the same group in
+	// each shard, so the TOPK/BOTTOMK aggregation can be parallelized as the TOPK/BOTTOMK
+	// of per-shard TOPK/BOTTOMK.
+	sharded, err := summer.shardAndSquashAggregateExpr(expr, expr.Op, stats)
+	if err != nil {
+		return nil, err
+	}
+
+	return &parser.AggregateExpr{
+		Op:       expr.Op,
+		Expr:     sharded,
+		Param:    expr.Param,
+		Grouping: expr.Grouping,
+		Without:  expr.Without,
+	}, nil
+}
+
 // shardAvg attempts to shard the given AVG aggregation expression.
 func (summer *shardSummer) shardAvg(expr *parser.AggregateExpr, stats *MapperStats) (result parser.Node, err error) {
 	// The AVG aggregation can be parallelized as per-shard SUM() divided by per-shard COUNT().
@@ -344,11 +409,12 @@ func (summer *shardSummer) shardAndSquashAggregateExpr(expr *parser.AggregateExp
 		}
 
 		// Create the child expression, which runs the given aggregation operation
-		// on a single shard. We need to preserve the grouping as it was
-		// in the original one.
+		// on a single shard. We need to preserve the parameter and grouping as they
+		// were in the original one.
 		children = append(children, &parser.AggregateExpr{
 			Op:       op,
 			Expr:     sharded.(parser.Expr),
+			Param:    expr.Param,
 			Grouping: expr.Grouping,
 			Without:  expr.Without,
 		})
@@ -381,8 +447,8 @@ func (summer *shardSummer) shardBinOp(expr *parser.BinaryExpr, stats *MapperStat
 // queries, where N is the number of shards and each sub-query queries a different shard
 // with the same binary operation.
 func (summer *shardSummer) shardAndSquashBinOp(expr *parser.BinaryExpr, stats *MapperStats) (parser.Expr, error) {
-	if expr.VectorMatching != nil {
-		// We shouldn't ever reach this point with a vector matching binary expression,
+	if expr.VectorMatching != nil && !matchesSeriesOfSameShard(expr) {
+		// We shouldn't ever reach this point with a binary expression matching series of different shards,
 		// but it's better to check twice than completely mess it up with the results.
 		return nil, fmt.Errorf("tried to shard a bin op with vector matching: %s", expr)
 	}
@@ -400,10 +466,11 @@ func (summer *shardSummer) shardAndSquashBinOp(expr *parser.BinaryExpr, stats *M
 		}
 
 		children = append(children, &parser.BinaryExpr{
-			LHS:        shardedLHS.(parser.Expr),
-			Op:         expr.Op,
-			RHS:        shardedRHS.(parser.Expr),
-			ReturnBool: expr.ReturnBool,
+			LHS:            shardedLHS.(parser.Expr),
+			Op:             expr.Op,
+			RHS:            shardedRHS.(parser.Expr),
+			VectorMatching: expr.VectorMatching,
+			ReturnBool:     expr.ReturnBool,
 		})
 	}
 
@@ -457,13 +524,20 @@ func shardMatrixSelector(curshard, shards int, selector *parser.MatrixSelector)
 	return nil, fmt.Errorf("invalid selector type: %T", selector.VectorSelector)
 }
 
-// isSubquery returns true if the given function call expression is a subquery.
-func isSubquery(n *parser.Call) bool {
-	if len(n.Args) == 0 {
-		return false
+// rangeVectorArg returns the range vector argument of the given function call. It returns false if the
+// function doesn't take a range vector argument or if any of its other arguments is not a constant scalar.
+func rangeVectorArg(n *parser.Call) (parser.Expr, bool) {
+	var rangeArg parser.Expr
+	for _, arg := range n.Args {
+		if arg.Type() == parser.ValueTypeMatrix {
+			rangeArg = arg
+			continue
+		}
+		if !isConstantScalar(arg) {
+			return nil, false
+		}
 	}
-	_, ok := n.Args[0].(*parser.SubqueryExpr)
-	return ok
+	return rangeArg, rangeArg != nil
 }
 
 func copyTimestamp(original *int64) *int64 {
@@ -281,9 +281,47 @@ func TestShardSummer(t *testing.T) {
 		},
 		{
 			`quantile_over_time(0.99, cortex_ingester_active_series[1w])`,
-			concat(
-				`quantile_over_time(0.99, cortex_ingester_active_series{}[1w])`,
-			),
+			concatShards(3, `quantile_over_time(0.99, cortex_ingester_active_series{__query_shard__="x_of_y"}[1w])`),
+			3,
+		},
+		{
+			`quantile_over_time(scalar(foo), cortex_ingester_active_series[1w])`,
+			concat(`quantile_over_time(scalar(foo), cortex_ingester_active_series[1w])`),
+			0,
+		},
+		{
+			`quantile_over_time(0.99, rate(metric_counter[5m])[1h:1m])`,
+			concatShards(3, `quantile_over_time(0.99, rate(metric_counter{__query_shard__="x_of_y"}[5m])[1h:1m])`),
+			3,
+		},
+		{
+			`quantile_over_time(0.99, sum by (foo) (rate(metric_counter[5m]))[1h:1m])`,
+			concat(`quantile_over_time(0.99, sum by (foo) (rate(metric_counter[5m]))[1h:1m])`),
+			0,
+		},
+		{
+			`topk(10, rate(foo[1m]))`,
+			`topk(10, ` + concatShards(3, `topk(10, rate(foo{__query_shard__="x_of_y"}[1m]))`) + `)`,
+			3,
+		},
+		{
+			`bottomk by (foo) (10, rate(foo[1m]))`,
+			`bottomk by (foo) (10, ` + concatShards(3, `bottomk by (foo) (10, rate(foo{__query_shard__="x_of_y"}[1m]))`) + `)`,
+			3,
+		},
+		{
+			`topk(scalar(foo), rate(bar[1m]))`,
+			concat(`topk(scalar(foo), rate(bar[1m]))`),
+			0,
+		},
+		{
+			`sum by (foo) (topk(10, rate(foo[1m])))`,
+			`sum by (foo) (topk(10, ` + concatShards(3, `topk(10, rate(foo{__query_shard__="x_of_y"}[1m]))`) + `))`,
+			3,
+		},
+		{
+			`quantile(0.9, foo)`,
+			concat(`quantile(0.9, foo)`),
 			0,
 		},
 		{
@@ -390,6 +428,43 @@ func TestShardSummer(t *testing.T) {
 			concat(`foo * 2`),
 			0,
 		},
+		{
+			// foo and foo offset 1h series matching each other have the same labels, so they belong to the same shard.
+			`foo > foo offset 1h`,
+			concatShards(3, `foo{__query_shard__="x_of_y"} > foo{__query_shard__="x_of_y"} offset 1h`),
+			3,
+		},
+		{
+			`foo > on(bar) foo offset 1h`,
+			concat(`foo > on(bar) foo offset 1h`),
+			0,
+		},
+		{
+			`foo > label_replace(foo offset 1h, "bar", "$1", "baz", "(.*)")`,
+			concat(`foo > label_replace(foo offset 1h, "bar", "$1", "baz", "(.*)")`),
+			0,
+		},
+		{
+			`histogram_quantile(0.5, sum by (le) (rate(foo_bucket[1m]) - rate(foo_bucket[1m] offset 1d)))`,
+			`histogram_quantile(0.5, sum by (le) (` +
+				concatShards(3, `sum by (le) (rate(foo_bucket{__query_shard__="x_of_y"}[1m]) - rate(foo_bucket{__query_shard__="x_of_y"}[1m] offset 1d))`) +
+				`))`,
+			3,
+		},
+		{
+			`histogram_quantile(0.5, sum by (le) (rate(foo_bucket[1m])) / on(le) sum by (le) (rate(bar_bucket[1m])))`,
+			`histogram_quantile(0.5, ` +
+				`sum by (le) (` + concatShards(3, `sum by (le) (rate(foo_bucket{__query_shard__="x_of_y"}[1m]))`) + `)` +
+				` / on(le) ` +
+				`sum by (le) (` + concatShards(3, `sum by (le) (rate(bar_bucket{__query_shard__="x_of_y"}[1m]))`) + `)` +
+				`)`,
+			6,
+		},
+		{
+			`histogram_quantile(0.5, sum by (le) (rate(foo_bucket[1m]) / rate(bar_bucket[1m])))`,
+			concat(`histogram_quantile(0.5, sum by (le) (rate(foo_bucket[1m]) / rate(bar_bucket[1m])))`),
+			0,
+		},
 		{
 			`foo > 0`,
 			concatShards(3, `foo{__query_shard__="x_of_y"} > 0`),
@@ -476,6 +551,64 @@ func TestShardSummer(t *testing.T) {
 	}
 }
 
+func TestShardSummer_NotShardableReasons(t *testing.T) {
+	for _, tt := range []struct {
+		in              string
+		expectedReasons []string
+	}{
+		{
+			in:              `sum(rate(foo[1m]))`,
+			expectedReasons: nil,
+		},
+		{
+			in:              `foo * 2`,
+			expectedReasons: nil,
+		},
+		{
+			in:              `quantile(0.9, foo)`,
+			expectedReasons: []string{NotShardableReasonAggregation},
+		},
+		{
+			in:              `topk(scalar(foo), bar)`,
+			expectedReasons: []string{NotShardableReasonAggregation},
+		},
+		{
+			in:              `histogram_quantile(0.5, rate(foo_bucket[1m]))`,
+			expectedReasons: []string{NotShardableReasonFunction},
+		},
+		{
+			in:              `max_over_time(sort(foo)[10m:1m])`,
+			expectedReasons: []string{NotShardableReasonFunction},
+		},
+		{
+			in:              `max_over_time(sum by (foo) (rate(bar[1m]))[10m:1m])`,
+			expectedReasons: []string{NotShardableReasonSubquery},
+		},
+		{
+			in:              `foo > bar`,
+			expectedReasons: []string{NotShardableReasonBinaryOperation},
+		},
+		{
+			in:              `foo > bar or quantile(0.9, baz)`,
+			expectedReasons: []string{NotShardableReasonBinaryOperation, NotShardableReasonAggregation},
+		},
+	} {
+		tt := tt
+
+		t.Run(tt.in, func(t *testing.T) {
+			mapper, err := NewSharding(3, log.NewNopLogger())
+			require.NoError(t, err)
+			expr, err := parser.ParseExpr(tt.in)
+			require.NoError(t, err)
+
+			stats := NewMapperStats()
+			_, err = mapper.Map(expr, stats)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedReasons, stats.GetNotShardableReasons())
+		})
+	}
+}
+
 func concatShards(shards int, queryTemplate string) string {
 	queries := make([]string, shards)
 	for shard := range queries {
@@ -2,8 +2,31 @@
 
 package astmapper
 
+// Reasons why a query, or a part of it, can't be sharded.
+const (
+	// NotShardableReasonAggregation is used for aggregations which can't be parallelized,
+	// like quantile() or topk() with a non constant parameter.
+	NotShardableReasonAggregation = "aggregation"
+
+	// NotShardableReasonFunction is used for functions which can't be parallelized, like histogram_quantile() or sort().
+	NotShardableReasonFunction = "function"
+
+	// NotShardableReasonSubquery is used for subqueries running an aggregation, which would need
+	// to be sharded at the subquery resolution.
+	NotShardableReasonSubquery = "subquery"
+
+	// NotShardableReasonBinaryOperation is used for binary operations between vectors whose
+	// matching series may belong to different shards.
+	NotShardableReasonBinaryOperation = "binary-operation"
+
+	// NotShardableReasonNotWorthSharding is used for queries which could be sharded, but wouldn't
+	// benefit from it because sharding wouldn't reduce the amount of data processed by the query-frontend.
+	NotShardableReasonNotWorthSharding = "not-worth-sharding"
+)
+
 type MapperStats struct {
-	shardedQueries int
+	shardedQueries      int
+	notShardableReasons []string
 }
 
 func NewMapperStats() *MapperStats {
@@ -19,3 +42,19 @@ func (s *MapperStats) AddShardedQueries(num int) {
 func (s *MapperStats) GetShardedQueries() int {
 	return s.shardedQueries
 }
+
+// AddNotShardableReason records the reason why a part of the query can't be sharded.
+func (s *MapperStats) AddNotShardableReason(reason string) {
+	for _, r := range s.notShardableReasons {
+		if r == reason {
+			return
+		}
+	}
+	s.notShardableReasons = append(s.notShardableReasons, reason)
+}
+
+// GetNotShardableReasons returns the unique reasons why parts of the query can't be sharded,
+// in the order they've been recorded.
+func (s *MapperStats) GetNotShardableReasons() []string {
+	return s.notShardableReasons
+}
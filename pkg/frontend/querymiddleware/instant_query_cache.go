@@ -0,0 +1,168 @@
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package querymiddleware
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/gogo/protobuf/proto"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/common/model"
+
+	"github.com/grafana/dskit/tenant"
+
+	apierror "github.com/grafana/mimir/pkg/api/error"
+	"github.com/grafana/mimir/pkg/cache"
+	"github.com/grafana/mimir/pkg/querier/stats"
+	"github.com/grafana/mimir/pkg/util/spanlogger"
+	"github.com/grafana/mimir/pkg/util/validation"
+)
+
+type instantQueryCacheMiddlewareMetrics struct {
+	cacheableRequests prometheus.Counter
+	cacheHits         prometheus.Counter
+}
+
+func newInstantQueryCacheMiddlewareMetrics(reg prometheus.Registerer) *instantQueryCacheMiddlewareMetrics {
+	return &instantQueryCacheMiddlewareMetrics{
+		cacheableRequests: promauto.With(reg).NewCounter(prometheus.CounterOpts{
+			Name: "cortex_frontend_instant_query_cache_requests_total",
+			Help: "Total number of instant queries eligible to be served from the results cache.",
+		}),
+		cacheHits: promauto.With(reg).NewCounter(prometheus.CounterOpts{
+			Name: "cortex_frontend_instant_query_cache_hits_total",
+			Help: "Total number of instant queries served from the results cache.",
+		}),
+	}
+}
+
+// instantQueryCacheMiddleware is a Middleware that runs instant queries through the results cache.
+type instantQueryCacheMiddleware struct {
+	next      Handler
+	limits    Limits
+	cache     cache.Cache
+	extractor Extractor
+	logger    log.Logger
+	metrics   *instantQueryCacheMiddlewareMetrics
+}
+
+// newInstantQueryCacheMiddleware makes a new instantQueryCacheMiddleware.
+func newInstantQueryCacheMiddleware(limits Limits, cache cache.Cache, extractor Extractor, logger log.Logger, reg prometheus.Registerer) Middleware {
+	metrics := newInstantQueryCacheMiddlewareMetrics(reg)
+
+	return MiddlewareFunc(func(next Handler) Handler {
+		return &instantQueryCacheMiddleware{
+			next:      next,
+			limits:    limits,
+			cache:     cache,
+			extractor: extractor,
+			logger:    logger,
+			metrics:   metrics,
+		}
+	})
+}
+
+func (c *instantQueryCacheMiddleware) Do(ctx context.Context, req Request) (Response, error) {
+	tenantIDs, err := tenant.TenantIDs(ctx)
+	if err != nil {
+		return nil, apierror.New(apierror.TypeBadData, err.Error())
+	}
+
+	// Do not cache the results of queries evaluated at a time more recent than the configured
+	// max cache freshness, because they may still change.
+	maxCacheFreshness := validation.MaxDurationPerTenant(tenantIDs, c.limits.MaxCacheFreshness)
+	maxCacheTime := int64(model.Now().Add(-maxCacheFreshness))
+
+	if req.GetOptions().CacheDisabled || !isRequestCachable(req, maxCacheTime, true, c.logger) {
+		return c.next.Do(ctx, req)
+	}
+
+	queryStats := stats.FromContext(ctx)
+	queryStats.AddResultsCacheRequests(1)
+	c.metrics.cacheableRequests.Inc()
+	key := generateInstantQueryCacheKey(tenant.JoinTenantIDs(tenantIDs), req)
+
+	if res := c.fetchCachedResponse(ctx, key); res != nil {
+		queryStats.AddResultsCacheHits(1)
+		c.metrics.cacheHits.Inc()
+		return res, nil
+	}
+
+	res, err := c.next.Do(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	if isResponseCachable(res, c.logger) {
+		c.storeCachedResponse(ctx, key, req, c.extractor.ResponseWithoutHeaders(res))
+	}
+
+	return res, nil
+}
+
+// fetchCachedResponse looks up the response for the given key in the cache. Returns nil in case of
+// cache miss or error.
+func (c *instantQueryCacheMiddleware) fetchCachedResponse(ctx context.Context, key string) Response {
+	spanLog, ctx := spanlogger.NewWithLogger(ctx, c.logger, "fetchCachedResponse")
+	defer spanLog.Finish()
+
+	hashedKey := cacheHashKey(key)
+	spanLog.LogKV("key", key, "hashedKey", hashedKey)
+
+	founds := c.cache.Fetch(ctx, []string{hashedKey})
+	foundData, ok := founds[hashedKey]
+	if !ok {
+		return nil
+	}
+
+	var cached CachedResponse
+	if err := proto.Unmarshal(foundData, &cached); err != nil {
+		level.Error(spanLog).Log("msg", "error unmarshalling cached response", "err", err)
+		spanLog.Error(err)
+		return nil
+	}
+
+	// Ensure there's no hashed key collision.
+	if cached.Key != key || len(cached.Extents) != 1 {
+		return nil
+	}
+
+	res, err := cached.Extents[0].toResponse()
+	if err != nil {
+		level.Error(spanLog).Log("msg", "error decoding cached response", "err", err)
+		spanLog.Error(err)
+		return nil
+	}
+
+	spanLog.LogKV("returned bytes", len(foundData))
+	return res
+}
+
+// storeCachedResponse stores the response for given key in the cache.
+func (c *instantQueryCacheMiddleware) storeCachedResponse(ctx context.Context, key string, req Request, res Response) {
+	extent, err := toExtent(ctx, req, res)
+	if err != nil {
+		level.Error(c.logger).Log("msg", "error marshalling cached response", "err", err)
+		return
+	}
+
+	buf, err := proto.Marshal(&CachedResponse{
+		Key:     key,
+		Extents: []Extent{extent},
+	})
+	if err != nil {
+		level.Error(c.logger).Log("msg", "error marshalling cached response", "err", err)
+		return
+	}
+
+	c.cache.Store(ctx, map[string][]byte{cacheHashKey(key): buf}, resultsCacheTTL)
+}
+
+// generateInstantQueryCacheKey generates a cache key based on the tenant, query and evaluation time.
+func generateInstantQueryCacheKey(userID string, r Request) string {
+	return fmt.Sprintf("instant:%s:%s:%d", userID, r.GetQuery(), r.GetStart())
+}
@@ -0,0 +1,143 @@
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package querymiddleware
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/weaveworks/common/user"
+	"go.uber.org/atomic"
+
+	"github.com/grafana/mimir/pkg/cache"
+	"github.com/grafana/mimir/pkg/mimirpb"
+	"github.com/grafana/mimir/pkg/querier/stats"
+	"github.com/grafana/mimir/pkg/util"
+)
+
+func TestInstantQueryCacheMiddleware(t *testing.T) {
+	var (
+		now     = time.Now()
+		pastTs  = util.TimeToMillis(now.Add(-time.Hour))
+		ctx     = user.InjectOrgID(context.Background(), "user-1")
+		limits  = mockLimits{maxCacheFreshness: 10 * time.Minute}
+		request = &PrometheusInstantQueryRequest{Path: "/api/v1/query", Time: pastTs, Query: "sum(up)"}
+	)
+
+	newResponse := func(value float64, headers ...*PrometheusResponseHeader) *PrometheusResponse {
+		return &PrometheusResponse{
+			Status: statusSuccess,
+			Data: &PrometheusData{
+				ResultType: "vector",
+				Result: []SampleStream{{
+					Labels:  []mimirpb.LabelAdapter{{Name: "job", Value: "test"}},
+					Samples: []mimirpb.Sample{{TimestampMs: pastTs, Value: value}},
+				}},
+			},
+			Headers: headers,
+		}
+	}
+
+	tests := map[string]struct {
+		req                  Request
+		downstreamRes        *PrometheusResponse
+		expectedCached       bool
+		expectedCacheableReq bool
+	}{
+		"should cache the response of a query evaluated in the past": {
+			req:                  request,
+			downstreamRes:        newResponse(1),
+			expectedCached:       true,
+			expectedCacheableReq: true,
+		},
+		"should not cache the response of a query evaluated at a time more recent than the max cache freshness": {
+			req:           request.WithStartEnd(util.TimeToMillis(now), util.TimeToMillis(now)),
+			downstreamRes: newResponse(1),
+		},
+		"should not cache the response if the cache has been disabled for the request": {
+			req: func() Request {
+				r := *request
+				r.Options = Options{CacheDisabled: true}
+				return &r
+			}(),
+			downstreamRes: newResponse(1),
+		},
+		"should not cache the response of a query with a negative offset": {
+			req:           request.WithQuery("sum(up offset -1h)"),
+			downstreamRes: newResponse(1),
+		},
+		"should not cache the response with the cache control header set to no-store": {
+			req:                  request,
+			downstreamRes:        newResponse(1, &PrometheusResponseHeader{Name: cacheControlHeader, Values: []string{noStoreValue}}),
+			expectedCacheableReq: true,
+		},
+	}
+
+	for testName, testData := range tests {
+		t.Run(testName, func(t *testing.T) {
+			var downstreamCalls atomic.Int32
+			downstream := HandlerFunc(func(context.Context, Request) (Response, error) {
+				downstreamCalls.Inc()
+				return testData.downstreamRes, nil
+			})
+
+			reg := prometheus.NewPedanticRegistry()
+			mw := newInstantQueryCacheMiddleware(limits, cache.NewMockCache(), PrometheusResponseExtractor{}, log.NewNopLogger(), reg).Wrap(downstream)
+
+			// Run the same request twice.
+			queryStats, statsCtx := stats.ContextWithEmptyStats(ctx)
+			for i := 0; i < 2; i++ {
+				res, err := mw.Do(statsCtx, testData.req)
+				require.NoError(t, err)
+				assert.Equal(t, testData.downstreamRes.Data, res.(*PrometheusResponse).Data)
+			}
+
+			expectedDownstreamCalls := int32(2)
+			if testData.expectedCached {
+				expectedDownstreamCalls = 1
+			}
+			assert.Equal(t, expectedDownstreamCalls, downstreamCalls.Load())
+
+			expectedCacheableReqs := 0
+			if testData.expectedCacheableReq {
+				expectedCacheableReqs = 2
+			}
+			metrics := mw.(*instantQueryCacheMiddleware).metrics
+			assert.Equal(t, float64(expectedCacheableReqs), testutil.ToFloat64(metrics.cacheableRequests))
+			assert.Equal(t, float64(2-expectedDownstreamCalls), testutil.ToFloat64(metrics.cacheHits))
+			assert.Equal(t, uint32(expectedCacheableReqs), queryStats.LoadResultsCacheRequests())
+			assert.Equal(t, uint32(2-expectedDownstreamCalls), queryStats.LoadResultsCacheHits())
+		})
+	}
+
+	t.Run("should not share cached responses between tenants, queries and evaluation times", func(t *testing.T) {
+		var downstreamCalls atomic.Int32
+		downstream := HandlerFunc(func(context.Context, Request) (Response, error) {
+			downstreamCalls.Inc()
+			return newResponse(1), nil
+		})
+
+		mw := newInstantQueryCacheMiddleware(limits, cache.NewMockCache(), PrometheusResponseExtractor{}, log.NewNopLogger(), nil).Wrap(downstream)
+
+		for _, run := range []struct {
+			ctx context.Context
+			req Request
+		}{
+			{ctx: ctx, req: request},
+			{ctx: user.InjectOrgID(context.Background(), "user-2"), req: request},
+			{ctx: ctx, req: request.WithQuery("sum(rate(up[1m]))")},
+			{ctx: ctx, req: request.WithStartEnd(pastTs-1000, pastTs-1000)},
+		} {
+			_, err := mw.Do(run.ctx, run.req)
+			require.NoError(t, err)
+		}
+
+		assert.Equal(t, int32(4), downstreamCalls.Load())
+	})
+}
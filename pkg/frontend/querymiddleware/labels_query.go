@@ -0,0 +1,671 @@
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package querymiddleware
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/grafana/dskit/concurrency"
+	"github.com/grafana/dskit/tenant"
+	jsoniter "github.com/json-iterator/go"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/common/model"
+	"github.com/prometheus/prometheus/model/labels"
+
+	apierror "github.com/grafana/mimir/pkg/api/error"
+	"github.com/grafana/mimir/pkg/cache"
+	"github.com/grafana/mimir/pkg/querier/stats"
+	"github.com/grafana/mimir/pkg/util"
+	"github.com/grafana/mimir/pkg/util/spanlogger"
+	"github.com/grafana/mimir/pkg/util/validation"
+)
+
+const (
+	labelNamesPathSuffix = "/api/v1/labels"
+	seriesPathSuffix     = "/api/v1/series"
+
+	// maxLabelsQuerySplits is the max number of intervals a labels query is split into. Queries spanning
+	// a longer time range (eg. with no lookback limit and a start time far in the past) are not split.
+	maxLabelsQuerySplits = 100
+)
+
+var labelValuesPathRegexp = regexp.MustCompile(`/api/v1/label/([^/]+)/values$`)
+
+type labelsQueryKind int
+
+const (
+	labelNamesQuery labelsQueryKind = iota
+	labelValuesQuery
+	seriesQuery
+)
+
+func (k labelsQueryKind) String() string {
+	switch k {
+	case labelNamesQuery:
+		return "label_names"
+	case labelValuesQuery:
+		return "label_values"
+	case seriesQuery:
+		return "series"
+	default:
+		return "unknown"
+	}
+}
+
+// labelsQueryRequest is a label names, label values or series request.
+type labelsQueryRequest struct {
+	kind      labelsQueryKind
+	labelName string
+
+	// The original request, used to build the downstream requests.
+	orig *http.Request
+
+	// The request parameters, excluding the start and end time.
+	params url.Values
+
+	start, end int64
+}
+
+// labelsQueryResponse is the response of a label names, label values or series request.
+type labelsQueryResponse struct {
+	Status    string              `json:"status"`
+	Data      jsoniter.RawMessage `json:"data,omitempty"`
+	ErrorType string              `json:"errorType,omitempty"`
+	Error     string              `json:"error,omitempty"`
+	Warnings  []string            `json:"warnings,omitempty"`
+
+	// The HTTP response status code, headers and body.
+	Code    int         `json:"-"`
+	Headers http.Header `json:"-"`
+	Body    []byte      `json:"-"`
+}
+
+// cachedLabelsQueryResponse is the entry stored in the results cache for a labels query.
+type cachedLabelsQueryResponse struct {
+	// Key is the non-hashed cache key, used to detect hashed key collisions.
+	Key string `json:"key"`
+
+	// Start and End are the time range of the cached data. Since the cache key is aligned to the split
+	// interval, the cached data is only used if it's been computed for the same time range.
+	Start int64               `json:"start"`
+	End   int64               `json:"end"`
+	Data  jsoniter.RawMessage `json:"data"`
+}
+
+type labelsQueryRoundTripperMetrics struct {
+	splitQueries prometheus.Counter
+}
+
+// labelsQueryRoundTripper splits the label names, label values and series requests by interval and/or runs
+// the split requests through the results cache, enforcing the limits on their time range. The requests which
+// are neither split nor cached are passed through as-is.
+type labelsQueryRoundTripper struct {
+	next    http.RoundTripper
+	limits  Limits
+	logger  log.Logger
+	metrics labelsQueryRoundTripperMetrics
+
+	// Split by interval.
+	splitEnabled  bool
+	splitInterval time.Duration
+
+	// Results caching.
+	cacheEnabled bool
+	cache        cache.Cache
+}
+
+// newLabelsQueryTripperware returns a Tripperware handling label names, label values and series requests.
+func newLabelsQueryTripperware(
+	splitEnabled bool,
+	splitInterval time.Duration,
+	cacheEnabled bool,
+	cache cache.Cache,
+	limits Limits,
+	logger log.Logger,
+	reg prometheus.Registerer,
+) Tripperware {
+	metrics := labelsQueryRoundTripperMetrics{
+		splitQueries: promauto.With(reg).NewCounter(prometheus.CounterOpts{
+			Name: "cortex_frontend_split_labels_queries_total",
+			Help: "Total number of underlying label names, label values and series requests after the split by interval is applied.",
+		}),
+	}
+
+	return func(next http.RoundTripper) http.RoundTripper {
+		return &labelsQueryRoundTripper{
+			next:          next,
+			limits:        limits,
+			logger:        logger,
+			metrics:       metrics,
+			splitEnabled:  splitEnabled,
+			splitInterval: splitInterval,
+			cacheEnabled:  cacheEnabled,
+			cache:         cache,
+		}
+	}
+}
+
+func (rt *labelsQueryRoundTripper) RoundTrip(r *http.Request) (*http.Response, error) {
+	// The limits are enforced by the queriers too, so there's nothing to do if the request is neither split nor cached.
+	if !rt.splitEnabled && (!rt.cacheEnabled || isCacheDisabledByRequest(r)) {
+		return rt.next.RoundTrip(r)
+	}
+
+	spanLog, ctx := spanlogger.NewWithLogger(r.Context(), rt.logger, "labelsQueryRoundTripper.RoundTrip")
+	defer spanLog.Finish()
+
+	tenantIDs, err := tenant.TenantIDs(ctx)
+	if err != nil {
+		return nil, apierror.New(apierror.TypeBadData, err.Error())
+	}
+
+	req, ok, err := parseLabelsQueryRequest(r)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		// The request has no time range, so we can't enforce limits nor split it.
+		return rt.next.RoundTrip(r)
+	}
+
+	// Clamp the time range based on the max labels query length and the max query lookback,
+	// consistently with what the querier does.
+	if maxLength := validation.SmallestPositiveNonZeroDurationPerTenant(tenantIDs, rt.limits.MaxLabelsQueryLength); maxLength > 0 {
+		if minStartTime := req.end - maxLength.Milliseconds(); req.start < minStartTime {
+			level.Debug(spanLog).Log(
+				"msg", "the start time of the query has been manipulated because of the 'max labels query length' setting",
+				"original", util.FormatTimeMillis(req.start),
+				"updated", util.FormatTimeMillis(minStartTime))
+
+			req.start = minStartTime
+		}
+	}
+
+	if maxQueryLookback := validation.SmallestPositiveNonZeroDurationPerTenant(tenantIDs, rt.limits.MaxQueryLookback); maxQueryLookback > 0 {
+		minStartTime := util.TimeToMillis(time.Now().Add(-maxQueryLookback))
+
+		if req.end < minStartTime {
+			level.Debug(spanLog).Log(
+				"msg", "skipping the execution of the query because its time range is before the 'max query lookback' setting",
+				"reqStart", util.FormatTimeMillis(req.start),
+				"redEnd", util.FormatTimeMillis(req.end),
+				"maxQueryLookback", maxQueryLookback)
+
+			return encodeLabelsQueryResponse(req.kind, nil, nil)
+		}
+
+		if req.start < minStartTime {
+			level.Debug(spanLog).Log(
+				"msg", "the start time of the query has been manipulated because of the 'max query lookback' setting",
+				"original", util.FormatTimeMillis(req.start),
+				"updated", util.FormatTimeMillis(minStartTime))
+
+			req.start = minStartTime
+		}
+	}
+
+	// The querier enforces the max query length on series requests, so we do the same on the
+	// received request, before it gets split.
+	if req.kind == seriesQuery {
+		if maxQueryLength := validation.SmallestPositiveNonZeroDurationPerTenant(tenantIDs, rt.limits.MaxQueryLength); maxQueryLength > 0 {
+			queryLen := time.Duration(req.end-req.start) * time.Millisecond
+			if queryLen > maxQueryLength {
+				return nil, apierror.New(apierror.TypeBadData, validation.NewMaxQueryLengthError(queryLen, maxQueryLength).Error())
+			}
+		}
+	}
+
+	// Split the request by interval.
+	ranges := [][2]int64{{req.start, req.end}}
+	if rt.splitEnabled {
+		ranges = splitLabelsQueryTimeRange(req.start, req.end, rt.splitInterval)
+		rt.metrics.splitQueries.Add(float64(len(ranges)))
+	}
+
+	isCacheEnabled := rt.cacheEnabled && !isCacheDisabledByRequest(r)
+	maxCacheFreshness := validation.MaxDurationPerTenant(tenantIDs, rt.limits.MaxCacheFreshness)
+	maxCacheTime := int64(model.Now().Add(-maxCacheFreshness))
+	tenantID := tenant.JoinTenantIDs(tenantIDs)
+
+	// Lookup the results cache.
+	data := make([]jsoniter.RawMessage, len(ranges))
+	cacheKeys := make([]string, len(ranges))
+
+	if isCacheEnabled {
+		for i, tr := range ranges {
+			// Do not cache the recent results which may still be in flux.
+			if tr[1] > maxCacheTime {
+				continue
+			}
+			cacheKeys[i] = req.cacheKey(tenantID, tr[0], rt.splitInterval)
+		}
+
+		rt.fetchCachedResponses(ctx, cacheKeys, ranges, data)
+	}
+
+	// Run the requests for all ranges not found in the cache.
+	var toExecute []int
+	for i := range ranges {
+		if data[i] == nil {
+			toExecute = append(toExecute, i)
+		}
+	}
+
+	spanLog.LogKV("split_queries", len(ranges), "cached_queries", len(ranges)-len(toExecute))
+
+	var (
+		warningsMx sync.Mutex
+		warnings   []string
+		errResp    *http.Response
+	)
+
+	parallelism := validation.SmallestPositiveIntPerTenant(tenantIDs, rt.limits.MaxQueryParallelism)
+	err = concurrency.ForEachJob(ctx, len(toExecute), parallelism, func(ctx context.Context, idx int) error {
+		rangeIdx := toExecute[idx]
+
+		downstreamReq, err := req.toHTTPRequest(ctx, ranges[rangeIdx][0], ranges[rangeIdx][1])
+		if err != nil {
+			return err
+		}
+
+		res, err := rt.next.RoundTrip(downstreamReq)
+		if err != nil {
+			return err
+		}
+
+		resp, err := decodeLabelsQueryResponse(res)
+		if err != nil {
+			return err
+		}
+
+		warningsMx.Lock()
+		defer warningsMx.Unlock()
+
+		if resp.Code/100 != 2 || resp.Status != statusSuccess {
+			// Keep track of the first error response, which is returned as is.
+			if errResp == nil {
+				errResp = &http.Response{
+					StatusCode:    resp.Code,
+					Header:        resp.Headers,
+					Body:          ioutil.NopCloser(bytes.NewReader(resp.Body)),
+					ContentLength: int64(len(resp.Body)),
+				}
+			}
+			return nil
+		}
+
+		data[rangeIdx] = resp.Data
+		if len(data[rangeIdx]) == 0 {
+			// A successful response with no data has no results.
+			data[rangeIdx] = jsoniter.RawMessage("[]")
+		}
+		warnings = append(warnings, resp.Warnings...)
+
+		// Do not cache responses with warnings or which are explicitly not cachable.
+		if len(resp.Warnings) > 0 || strings.Contains(resp.Headers.Get(cacheControlHeader), noStoreValue) {
+			cacheKeys[rangeIdx] = ""
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if errResp != nil {
+		return errResp, nil
+	}
+
+	// Store the downstream responses in the results cache.
+	if isCacheEnabled {
+		rt.storeCachedResponses(ctx, toExecute, cacheKeys, ranges, data)
+	}
+
+	return encodeLabelsQueryResponse(req.kind, data, warnings)
+}
+
+// fetchCachedResponses looks up the input cache keys in the results cache and, for each key found whose
+// cached time range is the same of the range at the same index, stores the cached response in data at
+// the same index. Empty keys are skipped.
+func (rt *labelsQueryRoundTripper) fetchCachedResponses(ctx context.Context, keys []string, ranges [][2]int64, data []jsoniter.RawMessage) {
+	hashedKeys := make([]string, 0, len(keys))
+	hashedKeysIdx := make(map[string]int, len(keys))
+	for idx, key := range keys {
+		if key == "" {
+			continue
+		}
+
+		hashed := cacheHashKey(key)
+		hashedKeys = append(hashedKeys, hashed)
+		hashedKeysIdx[hashed] = idx
+	}
+
+	if len(hashedKeys) == 0 {
+		return
+	}
+
+	queryStats := stats.FromContext(ctx)
+	queryStats.AddResultsCacheRequests(uint32(len(hashedKeys)))
+
+	for foundKey, foundData := range rt.cache.Fetch(ctx, hashedKeys) {
+		keyIdx, ok := hashedKeysIdx[foundKey]
+		if !ok {
+			continue
+		}
+
+		var cached cachedLabelsQueryResponse
+		if err := json.Unmarshal(foundData, &cached); err != nil {
+			level.Warn(rt.logger).Log("msg", "error unmarshalling cached labels query response", "err", err)
+			continue
+		}
+
+		// Ensure there's no hashed key collision, and the data has been cached for the same time range.
+		if cached.Key != keys[keyIdx] || cached.Start != ranges[keyIdx][0] || cached.End != ranges[keyIdx][1] {
+			continue
+		}
+
+		data[keyIdx] = cached.Data
+		queryStats.AddResultsCacheHits(1)
+	}
+}
+
+// storeCachedResponses stores in the results cache the data of the executed ranges having a cache key.
+func (rt *labelsQueryRoundTripper) storeCachedResponses(ctx context.Context, executed []int, keys []string, ranges [][2]int64, data []jsoniter.RawMessage) {
+	entries := map[string][]byte{}
+
+	for _, idx := range executed {
+		if keys[idx] == "" {
+			continue
+		}
+
+		buf, err := json.Marshal(cachedLabelsQueryResponse{Key: keys[idx], Start: ranges[idx][0], End: ranges[idx][1], Data: data[idx]})
+		if err != nil {
+			level.Warn(rt.logger).Log("msg", "error marshalling labels query response to cache", "err", err)
+			continue
+		}
+
+		entries[cacheHashKey(keys[idx])] = buf
+	}
+
+	if len(entries) > 0 {
+		rt.cache.Store(ctx, entries, resultsCacheTTL)
+	}
+}
+
+// parseLabelsQueryRequest parses a label names, label values or series request. Returns false if
+// the request has no time range, and thus can't be handled by the labelsQueryRoundTripper.
+func parseLabelsQueryRequest(r *http.Request) (*labelsQueryRequest, bool, error) {
+	req := &labelsQueryRequest{orig: r}
+
+	switch {
+	case isLabelNamesQuery(r.URL.Path):
+		req.kind = labelNamesQuery
+	case isLabelValuesQuery(r.URL.Path):
+		req.kind = labelValuesQuery
+		req.labelName = labelValuesPathRegexp.FindStringSubmatch(r.URL.Path)[1]
+	case isSeriesQuery(r.URL.Path):
+		req.kind = seriesQuery
+	default:
+		return nil, false, nil
+	}
+
+	// Only GET and POST requests are split and cached (eg. series can also be deleted).
+	if r.Method != http.MethodGet && r.Method != http.MethodPost {
+		return nil, false, nil
+	}
+
+	// Parse a copy of the request, so that the body of the original one can still be forwarded.
+	body, err := readRequestBody(r)
+	if err != nil {
+		return nil, false, err
+	}
+	parsed := r.Clone(r.Context())
+	parsed.Body = ioutil.NopCloser(bytes.NewReader(body))
+	if err := parsed.ParseForm(); err != nil {
+		return nil, false, apierror.New(apierror.TypeBadData, err.Error())
+	}
+
+	if parsed.Form.Get("start") == "" || parsed.Form.Get("end") == "" {
+		return nil, false, nil
+	}
+
+	if req.start, err = util.ParseTime(parsed.Form.Get("start")); err != nil {
+		return nil, false, decorateWithParamName(err, "start")
+	}
+	if req.end, err = util.ParseTime(parsed.Form.Get("end")); err != nil {
+		return nil, false, decorateWithParamName(err, "end")
+	}
+	if req.end < req.start {
+		return nil, false, errEndBeforeStart
+	}
+
+	req.params = url.Values{}
+	for name, values := range parsed.Form {
+		if name == "start" || name == "end" {
+			continue
+		}
+		req.params[name] = values
+	}
+
+	return req, true, nil
+}
+
+// readRequestBody reads the body of the input request and replaces it with a copy, so that it can be read again.
+func readRequestBody(r *http.Request) ([]byte, error) {
+	if r.Body == nil || r.Body == http.NoBody {
+		return nil, nil
+	}
+
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		return nil, err
+	}
+	_ = r.Body.Close()
+
+	r.Body = ioutil.NopCloser(bytes.NewReader(body))
+	return body, nil
+}
+
+// toHTTPRequest returns a copy of the request with the input time range.
+func (r *labelsQueryRequest) toHTTPRequest(ctx context.Context, start, end int64) (*http.Request, error) {
+	params := url.Values{}
+	for name, values := range r.params {
+		params[name] = values
+	}
+	params.Set("start", encodeTime(start))
+	params.Set("end", encodeTime(end))
+
+	u := *r.orig.URL
+	req := r.orig.Clone(ctx)
+	req.URL = &u
+
+	if r.orig.Method == http.MethodPost {
+		body := params.Encode()
+		u.RawQuery = ""
+		req.Body = ioutil.NopCloser(strings.NewReader(body))
+		req.ContentLength = int64(len(body))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		req.Header.Set("Content-Length", strconv.Itoa(len(body)))
+	} else {
+		u.RawQuery = params.Encode()
+		req.Body = http.NoBody
+		req.ContentLength = 0
+	}
+
+	// This is what the httpgrpc code looks at.
+	req.RequestURI = u.String()
+
+	return req, nil
+}
+
+// cacheKey returns the results cache key for the request starting at the input time. Like the query range
+// results cache key, it's aligned to the split interval, so that there's at most one cache entry per interval.
+func (r *labelsQueryRequest) cacheKey(tenantID string, start int64, splitInterval time.Duration) string {
+	startInterval := start
+	if intervalMillis := splitInterval.Milliseconds(); intervalMillis > 0 {
+		startInterval = start / intervalMillis
+	}
+
+	// Sort values, so that the key doesn't depend on the order of the matchers.
+	params := make(url.Values, len(r.params))
+	for name, values := range r.params {
+		sorted := append([]string(nil), values...)
+		sort.Strings(sorted)
+		params[name] = sorted
+	}
+
+	return fmt.Sprintf("%s:%s:%s:%d:%s", tenantID, r.kind, r.labelName, startInterval, params.Encode())
+}
+
+// splitLabelsQueryTimeRange splits the input time range by interval. The returned ranges are
+// non-overlapping and both start and end are inclusive.
+func splitLabelsQueryTimeRange(start, end int64, interval time.Duration) [][2]int64 {
+	intervalMillis := interval.Milliseconds()
+	if intervalMillis <= 0 || (end/intervalMillis)-(start/intervalMillis) >= maxLabelsQuerySplits {
+		return [][2]int64{{start, end}}
+	}
+
+	var ranges [][2]int64
+	for rangeStart := start; rangeStart <= end; {
+		nextIntervalStart := ((rangeStart / intervalMillis) + 1) * intervalMillis
+		rangeEnd := nextIntervalStart - 1
+		if rangeEnd > end {
+			rangeEnd = end
+		}
+
+		ranges = append(ranges, [2]int64{rangeStart, rangeEnd})
+		rangeStart = nextIntervalStart
+	}
+	return ranges
+}
+
+func decodeLabelsQueryResponse(r *http.Response) (*labelsQueryResponse, error) {
+	defer func() { _ = r.Body.Close() }()
+
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &labelsQueryResponse{Code: r.StatusCode, Headers: r.Header, Body: body}
+	if r.StatusCode/100 != 2 {
+		return resp, nil
+	}
+
+	if err := json.Unmarshal(body, resp); err != nil {
+		return nil, apierror.Newf(apierror.TypeInternal, "error decoding response: %v", err)
+	}
+	return resp, nil
+}
+
+// encodeLabelsQueryResponse merges the input data, each one being the data of a response, and returns
+// the merged response. Label names and values are deduplicated and sorted, series are deduplicated and
+// sorted by labels. Empty data is skipped, and the merged response has an empty list if there's no result.
+func encodeLabelsQueryResponse(kind labelsQueryKind, data []jsoniter.RawMessage, warnings []string) (*http.Response, error) {
+	var merged interface{}
+
+	switch kind {
+	case seriesQuery:
+		unique := map[string]labels.Labels{}
+		for _, d := range data {
+			if len(d) == 0 {
+				continue
+			}
+			var series []map[string]string
+			if err := json.Unmarshal(d, &series); err != nil {
+				return nil, apierror.Newf(apierror.TypeInternal, "error decoding response: %v", err)
+			}
+			for _, s := range series {
+				lbls := labels.FromMap(s)
+				unique[lbls.String()] = lbls
+			}
+		}
+
+		result := make([]labels.Labels, 0, len(unique))
+		for _, lbls := range unique {
+			result = append(result, lbls)
+		}
+		sort.Slice(result, func(i, j int) bool { return labels.Compare(result[i], result[j]) < 0 })
+		merged = result
+
+	default:
+		unique := map[string]struct{}{}
+		for _, d := range data {
+			if len(d) == 0 {
+				continue
+			}
+			var values []string
+			if err := json.Unmarshal(d, &values); err != nil {
+				return nil, apierror.Newf(apierror.TypeInternal, "error decoding response: %v", err)
+			}
+			for _, v := range values {
+				unique[v] = struct{}{}
+			}
+		}
+
+		result := make([]string, 0, len(unique))
+		for v := range unique {
+			result = append(result, v)
+		}
+		sort.Strings(result)
+		merged = result
+	}
+
+	mergedData, err := json.Marshal(merged)
+	if err != nil {
+		return nil, apierror.Newf(apierror.TypeInternal, "error encoding response: %v", err)
+	}
+
+	body, err := json.Marshal(labelsQueryResponse{
+		Status:   statusSuccess,
+		Data:     mergedData,
+		Warnings: warnings,
+	})
+	if err != nil {
+		return nil, apierror.Newf(apierror.TypeInternal, "error encoding response: %v", err)
+	}
+
+	return &http.Response{
+		Header: http.Header{
+			"Content-Type": []string{"application/json"},
+		},
+		Body:          ioutil.NopCloser(bytes.NewReader(body)),
+		StatusCode:    http.StatusOK,
+		ContentLength: int64(len(body)),
+	}, nil
+}
+
+// isCacheDisabledByRequest returns whether the request asked to not use the results cache.
+func isCacheDisabledByRequest(r *http.Request) bool {
+	var opts Options
+	decodeOptions(r, &opts)
+	return opts.CacheDisabled
+}
+
+func isLabelNamesQuery(path string) bool {
+	return strings.HasSuffix(path, labelNamesPathSuffix)
+}
+
+func isLabelValuesQuery(path string) bool {
+	return labelValuesPathRegexp.MatchString(path)
+}
+
+func isSeriesQuery(path string) bool {
+	return strings.HasSuffix(path, seriesPathSuffix)
+}
+
+func isLabelsQuery(path string) bool {
+	return isLabelNamesQuery(path) || isLabelValuesQuery(path) || isSeriesQuery(path)
+}
@@ -0,0 +1,446 @@
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package querymiddleware
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/weaveworks/common/user"
+
+	apierror "github.com/grafana/mimir/pkg/api/error"
+	"github.com/grafana/mimir/pkg/cache"
+	"github.com/grafana/mimir/pkg/util"
+	"github.com/grafana/mimir/pkg/util/validation"
+)
+
+func TestLabelsQueryRoundTripper(t *testing.T) {
+	now := time.Now()
+	start := now.Add(-72 * time.Hour).Truncate(24 * time.Hour).Add(time.Hour)
+	end := now.Add(-24 * time.Hour).Truncate(24 * time.Hour).Add(time.Hour)
+
+	tests := map[string]struct {
+		method           string
+		path             string
+		params           url.Values
+		expectedBody     string
+		expectedRequests int
+	}{
+		"label names": {
+			method:           http.MethodGet,
+			path:             "/prometheus/api/v1/labels",
+			params:           url.Values{"start": {encodeTime(util.TimeToMillis(start))}, "end": {encodeTime(util.TimeToMillis(end))}},
+			expectedBody:     `{"status":"success","data":["common","day_1","day_2","day_3"]}`,
+			expectedRequests: 3,
+		},
+		"label names via POST": {
+			method:           http.MethodPost,
+			path:             "/prometheus/api/v1/labels",
+			params:           url.Values{"start": {encodeTime(util.TimeToMillis(start))}, "end": {encodeTime(util.TimeToMillis(end))}},
+			expectedBody:     `{"status":"success","data":["common","day_1","day_2","day_3"]}`,
+			expectedRequests: 3,
+		},
+		"label values": {
+			method:           http.MethodGet,
+			path:             "/prometheus/api/v1/label/job/values",
+			params:           url.Values{"start": {encodeTime(util.TimeToMillis(start))}, "end": {encodeTime(util.TimeToMillis(end))}, "match[]": {`{__name__="up"}`}},
+			expectedBody:     `{"status":"success","data":["common","day_1","day_2","day_3"]}`,
+			expectedRequests: 3,
+		},
+		"series": {
+			method:           http.MethodGet,
+			path:             "/prometheus/api/v1/series",
+			params:           url.Values{"start": {encodeTime(util.TimeToMillis(start))}, "end": {encodeTime(util.TimeToMillis(end))}, "match[]": {`{__name__="up"}`}},
+			expectedBody:     `{"status":"success","data":[{"__name__":"up","day":"day_1"},{"__name__":"up","day":"day_2"},{"__name__":"up","day":"day_3"},{"__name__":"up","job":"common"}]}`,
+			expectedRequests: 3,
+		},
+		"request without time range is not split": {
+			method:           http.MethodGet,
+			path:             "/prometheus/api/v1/labels",
+			params:           url.Values{},
+			expectedBody:     `{"status":"success","data":["common","day_0"]}`,
+			expectedRequests: 1,
+		},
+	}
+
+	for testName, testData := range tests {
+		t.Run(testName, func(t *testing.T) {
+			downstream := &mockLabelsQueryDownstream{}
+			tripperware := newLabelsQueryTripperware(true, 24*time.Hour, false, nil, mockLimits{}, log.NewNopLogger(), nil)
+
+			res, err := tripperware(downstream).RoundTrip(newLabelsQueryHTTPRequest(t, testData.method, testData.path, testData.params))
+			require.NoError(t, err)
+			require.Equal(t, http.StatusOK, res.StatusCode)
+
+			body, err := ioutil.ReadAll(res.Body)
+			require.NoError(t, err)
+			assert.JSONEq(t, testData.expectedBody, string(body))
+			assert.Len(t, downstream.requests(), testData.expectedRequests)
+
+			// All downstream requests must have the same parameters of the original one, except the time range.
+			for _, params := range downstream.requests() {
+				for name := range testData.params {
+					if name != "start" && name != "end" {
+						assert.Equal(t, testData.params[name], params[name])
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestLabelsQueryRoundTripper_ResultsCache(t *testing.T) {
+	var (
+		now   = time.Now()
+		start = now.Add(-72 * time.Hour).Truncate(24 * time.Hour).Add(time.Hour)
+		end   = now.Add(-24 * time.Hour).Truncate(24 * time.Hour).Add(time.Hour)
+		ctx   = user.InjectOrgID(context.Background(), "user-1")
+	)
+
+	// Results of the last split are too recent to be cached.
+	limits := mockLimits{maxCacheFreshness: now.Sub(end.Truncate(24 * time.Hour))}
+
+	downstream := &mockLabelsQueryDownstream{}
+	tripperware := newLabelsQueryTripperware(true, 24*time.Hour, true, cache.NewMockCache(), limits, log.NewNopLogger(), nil)
+	rt := tripperware(downstream)
+
+	params := url.Values{"start": {encodeTime(util.TimeToMillis(start))}, "end": {encodeTime(util.TimeToMillis(end))}, "match[]": {`{job="a"}`, `{job="b"}`}}
+	expectedBody := `{"status":"success","data":["common","day_1","day_2","day_3"]}`
+
+	res, err := rt.RoundTrip(newLabelsQueryHTTPRequest(t, http.MethodGet, "/api/v1/labels", params))
+	require.NoError(t, err)
+	body, err := ioutil.ReadAll(res.Body)
+	require.NoError(t, err)
+	assert.JSONEq(t, expectedBody, string(body))
+	assert.Len(t, downstream.requests(), 3)
+
+	// The same request, with the matchers in a different order, is served from the cache, except
+	// the most recent split which is not cached.
+	params["match[]"] = []string{`{job="b"}`, `{job="a"}`}
+	res, err = rt.RoundTrip(newLabelsQueryHTTPRequest(t, http.MethodGet, "/api/v1/labels", params))
+	require.NoError(t, err)
+	body, err = ioutil.ReadAll(res.Body)
+	require.NoError(t, err)
+	assert.JSONEq(t, expectedBody, string(body))
+	assert.Len(t, downstream.requests(), 4)
+
+	// A request for a different tenant is not served from the cache.
+	req := newLabelsQueryHTTPRequest(t, http.MethodGet, "/api/v1/labels", params)
+	req = req.WithContext(user.InjectOrgID(ctx, "user-2"))
+	_, err = rt.RoundTrip(req)
+	require.NoError(t, err)
+	assert.Len(t, downstream.requests(), 7)
+
+	// A request with cache disabled is not served from the cache.
+	req = newLabelsQueryHTTPRequest(t, http.MethodGet, "/api/v1/labels", params)
+	req.Header.Set(cacheControlHeader, noStoreValue)
+	_, err = rt.RoundTrip(req)
+	require.NoError(t, err)
+	assert.Len(t, downstream.requests(), 10)
+
+	// A request starting at a different time within the same split interval is not served from the cache
+	// for the first split, whose cache entry is replaced, while the other splits are.
+	params["start"] = []string{encodeTime(util.TimeToMillis(start.Add(time.Hour)))}
+	_, err = rt.RoundTrip(newLabelsQueryHTTPRequest(t, http.MethodGet, "/api/v1/labels", params))
+	require.NoError(t, err)
+	assert.Len(t, downstream.requests(), 12)
+
+	_, err = rt.RoundTrip(newLabelsQueryHTTPRequest(t, http.MethodGet, "/api/v1/labels", params))
+	require.NoError(t, err)
+	assert.Len(t, downstream.requests(), 13)
+}
+
+func TestLabelsQueryRoundTripper_ShouldReturnAnEmptyListIfTheDownstreamResponseHasNoData(t *testing.T) {
+	now := time.Now()
+
+	for _, downstreamBody := range []string{`{"status":"success"}`, `{"status":"success","data":null}`} {
+		for _, path := range []string{"/api/v1/labels", "/api/v1/series"} {
+			t.Run(path+" "+downstreamBody, func(t *testing.T) {
+				downstream := RoundTripFunc(func(r *http.Request) (*http.Response, error) {
+					return &http.Response{
+						StatusCode: http.StatusOK,
+						Header:     http.Header{"Content-Type": {"application/json"}},
+						Body:       ioutil.NopCloser(strings.NewReader(downstreamBody)),
+					}, nil
+				})
+
+				tripperware := newLabelsQueryTripperware(true, 24*time.Hour, true, cache.NewMockCache(), mockLimits{}, log.NewNopLogger(), nil)
+
+				params := url.Values{"start": {encodeTime(util.TimeToMillis(now.Add(-72 * time.Hour)))}, "end": {encodeTime(util.TimeToMillis(now))}}
+				res, err := tripperware(downstream).RoundTrip(newLabelsQueryHTTPRequest(t, http.MethodGet, path, params))
+				require.NoError(t, err)
+				require.Equal(t, http.StatusOK, res.StatusCode)
+
+				body, err := ioutil.ReadAll(res.Body)
+				require.NoError(t, err)
+				assert.JSONEq(t, `{"status":"success","data":[]}`, string(body))
+			})
+		}
+	}
+}
+
+func TestLabelsQueryRoundTripper_Limits(t *testing.T) {
+	now := time.Now()
+
+	tests := map[string]struct {
+		limits         mockLimits
+		path           string
+		start, end     time.Time
+		expectedErr    error
+		expectedStarts []int64
+	}{
+		"max labels query length clamps the start time": {
+			limits:         mockLimits{maxLabelsQueryLength: 24 * time.Hour},
+			path:           "/api/v1/labels",
+			start:          now.Add(-72 * time.Hour),
+			end:            now.Add(-time.Hour),
+			expectedStarts: []int64{util.TimeToMillis(now.Add(-25 * time.Hour))},
+		},
+		"max query lookback clamps the start time": {
+			limits:         mockLimits{maxQueryLookback: 24 * time.Hour},
+			path:           "/api/v1/label/job/values",
+			start:          now.Add(-72 * time.Hour),
+			end:            now.Add(-time.Hour),
+			expectedStarts: []int64{util.TimeToMillis(now.Add(-24 * time.Hour))},
+		},
+		"max query lookback skips the request if fully outside the allowed time range": {
+			limits: mockLimits{maxQueryLookback: 24 * time.Hour},
+			path:   "/api/v1/series",
+			start:  now.Add(-72 * time.Hour),
+			end:    now.Add(-48 * time.Hour),
+		},
+		"max query length is not enforced on label names": {
+			limits:         mockLimits{maxQueryLength: 24 * time.Hour},
+			path:           "/api/v1/labels",
+			start:          now.Add(-72 * time.Hour),
+			end:            now.Add(-time.Hour),
+			expectedStarts: []int64{util.TimeToMillis(now.Add(-72 * time.Hour))},
+		},
+		"max query length is enforced on series": {
+			limits:      mockLimits{maxQueryLength: 24 * time.Hour},
+			path:        "/api/v1/series",
+			start:       now.Add(-72 * time.Hour),
+			end:         now.Add(-time.Hour),
+			expectedErr: apierror.New(apierror.TypeBadData, validation.NewMaxQueryLengthError(71*time.Hour, 24*time.Hour).Error()),
+		},
+	}
+
+	for testName, testData := range tests {
+		t.Run(testName, func(t *testing.T) {
+			downstream := &mockLabelsQueryDownstream{}
+			tripperware := newLabelsQueryTripperware(false, 0, true, cache.NewMockCache(), testData.limits, log.NewNopLogger(), nil)
+
+			params := url.Values{"start": {encodeTime(util.TimeToMillis(testData.start))}, "end": {encodeTime(util.TimeToMillis(testData.end))}}
+			res, err := tripperware(downstream).RoundTrip(newLabelsQueryHTTPRequest(t, http.MethodGet, testData.path, params))
+			if testData.expectedErr != nil {
+				require.Equal(t, testData.expectedErr, err)
+				assert.Empty(t, downstream.requests())
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, http.StatusOK, res.StatusCode)
+
+			var actualStarts []int64
+			for _, params := range downstream.requests() {
+				start, err := util.ParseTime(params.Get("start"))
+				require.NoError(t, err)
+				actualStarts = append(actualStarts, start)
+			}
+
+			require.Len(t, actualStarts, len(testData.expectedStarts))
+			for i := range actualStarts {
+				// The clamped start time is computed based on the current time, so we allow some tolerance.
+				assert.InDelta(t, testData.expectedStarts[i], actualStarts[i], float64(time.Minute.Milliseconds()))
+			}
+		})
+	}
+}
+
+func TestLabelsQueryRoundTripper_ShouldPassThroughRequestsNeitherSplitNorCached(t *testing.T) {
+	now := time.Now()
+	params := url.Values{"start": {encodeTime(util.TimeToMillis(now.Add(-72 * time.Hour)))}, "end": {encodeTime(util.TimeToMillis(now))}}
+	limits := mockLimits{maxLabelsQueryLength: 24 * time.Hour}
+
+	t.Run("split and cache disabled", func(t *testing.T) {
+		downstream := &mockLabelsQueryDownstream{}
+		tripperware := newLabelsQueryTripperware(false, 0, false, nil, limits, log.NewNopLogger(), nil)
+
+		res, err := tripperware(downstream).RoundTrip(newLabelsQueryHTTPRequest(t, http.MethodGet, "/api/v1/labels", params))
+		require.NoError(t, err)
+		require.Equal(t, http.StatusOK, res.StatusCode)
+		assert.Equal(t, []url.Values{params}, downstream.requests())
+	})
+
+	t.Run("split disabled and cache disabled by the request", func(t *testing.T) {
+		downstream := &mockLabelsQueryDownstream{}
+		tripperware := newLabelsQueryTripperware(false, 0, true, cache.NewMockCache(), limits, log.NewNopLogger(), nil)
+
+		req := newLabelsQueryHTTPRequest(t, http.MethodGet, "/api/v1/labels", params)
+		req.Header.Set(cacheControlHeader, noStoreValue)
+		res, err := tripperware(downstream).RoundTrip(req)
+		require.NoError(t, err)
+		require.Equal(t, http.StatusOK, res.StatusCode)
+		assert.Equal(t, []url.Values{params}, downstream.requests())
+	})
+}
+
+func TestLabelsQueryRoundTripper_ShouldReturnDownstreamErrorResponse(t *testing.T) {
+	now := time.Now()
+
+	downstream := RoundTripFunc(func(r *http.Request) (*http.Response, error) {
+		return &http.Response{
+			StatusCode: http.StatusUnprocessableEntity,
+			Header:     http.Header{"Content-Type": {"application/json"}},
+			Body:       ioutil.NopCloser(strings.NewReader(`{"status":"error","errorType":"bad_data","error":"invalid matcher"}`)),
+		}, nil
+	})
+
+	tripperware := newLabelsQueryTripperware(true, 24*time.Hour, false, nil, mockLimits{}, log.NewNopLogger(), nil)
+
+	params := url.Values{"start": {encodeTime(util.TimeToMillis(now.Add(-72 * time.Hour)))}, "end": {encodeTime(util.TimeToMillis(now))}}
+	res, err := tripperware(downstream).RoundTrip(newLabelsQueryHTTPRequest(t, http.MethodGet, "/api/v1/series", params))
+	require.NoError(t, err)
+	require.Equal(t, http.StatusUnprocessableEntity, res.StatusCode)
+
+	body, err := ioutil.ReadAll(res.Body)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"status":"error","errorType":"bad_data","error":"invalid matcher"}`, string(body))
+}
+
+func TestLabelsQueryTripperware_Metrics(t *testing.T) {
+	// Ensure the tripperware can be applied multiple times with the same registry.
+	tripperware := newLabelsQueryTripperware(true, 24*time.Hour, false, nil, mockLimits{}, log.NewNopLogger(), prometheus.NewPedanticRegistry())
+	tripperware(&mockLabelsQueryDownstream{})
+	tripperware(&mockLabelsQueryDownstream{})
+}
+
+func TestSplitLabelsQueryTimeRange(t *testing.T) {
+	day := (24 * time.Hour).Milliseconds()
+
+	tests := map[string]struct {
+		start, end int64
+		interval   time.Duration
+		expected   [][2]int64
+	}{
+		"within a single interval": {
+			start:    day + 10,
+			end:      day + 20,
+			interval: 24 * time.Hour,
+			expected: [][2]int64{{day + 10, day + 20}},
+		},
+		"spanning multiple intervals": {
+			start:    day + 10,
+			end:      3*day + 20,
+			interval: 24 * time.Hour,
+			expected: [][2]int64{{day + 10, 2*day - 1}, {2 * day, 3*day - 1}, {3 * day, 3*day + 20}},
+		},
+		"ending on an interval boundary": {
+			start:    day,
+			end:      2 * day,
+			interval: 24 * time.Hour,
+			expected: [][2]int64{{day, 2*day - 1}, {2 * day, 2 * day}},
+		},
+		"spanning too many intervals": {
+			start:    0,
+			end:      1000 * day,
+			interval: 24 * time.Hour,
+			expected: [][2]int64{{0, 1000 * day}},
+		},
+	}
+
+	for testName, testData := range tests {
+		t.Run(testName, func(t *testing.T) {
+			assert.Equal(t, testData.expected, splitLabelsQueryTimeRange(testData.start, testData.end, testData.interval))
+		})
+	}
+}
+
+func newLabelsQueryHTTPRequest(t *testing.T, method, path string, params url.Values) *http.Request {
+	var (
+		req *http.Request
+		err error
+	)
+
+	if method == http.MethodPost {
+		req, err = http.NewRequest(method, path, strings.NewReader(params.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	} else {
+		req, err = http.NewRequest(method, path+"?"+params.Encode(), nil)
+	}
+	require.NoError(t, err)
+
+	return req.WithContext(user.InjectOrgID(context.Background(), "user-1"))
+}
+
+// mockLabelsQueryDownstream returns, for each day in the requested time range, a label name (or value)
+// "day_<n>" where n is the number of days ago, and a label name (or value) "common".
+type mockLabelsQueryDownstream struct {
+	mtx      sync.Mutex
+	received []url.Values
+}
+
+func (m *mockLabelsQueryDownstream) RoundTrip(r *http.Request) (*http.Response, error) {
+	if err := r.ParseForm(); err != nil {
+		return nil, err
+	}
+
+	m.mtx.Lock()
+	m.received = append(m.received, r.Form)
+	m.mtx.Unlock()
+
+	// Requests with no time range cover today only.
+	days := []int{0}
+	if r.Form.Get("start") != "" {
+		start, err := util.ParseTime(r.Form.Get("start"))
+		if err != nil {
+			return nil, err
+		}
+		end, err := util.ParseTime(r.Form.Get("end"))
+		if err != nil {
+			return nil, err
+		}
+
+		days = nil
+		today := time.Now().Truncate(24 * time.Hour)
+		for ts := start; ts <= end; ts += (24 * time.Hour).Milliseconds() {
+			days = append(days, int(today.Sub(util.TimeFromMillis(ts).Truncate(24*time.Hour))/(24*time.Hour)))
+		}
+	}
+
+	var data []string
+	if strings.HasSuffix(r.URL.Path, "/series") {
+		data = append(data, `{"__name__":"up","job":"common"}`)
+		for _, d := range days {
+			data = append(data, `{"__name__":"up","day":"day_`+string(rune('0'+d))+`"}`)
+		}
+	} else {
+		data = append(data, `"common"`)
+		for _, d := range days {
+			data = append(data, `"day_`+string(rune('0'+d))+`"`)
+		}
+	}
+
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Header:     http.Header{"Content-Type": {"application/json"}},
+		Body:       ioutil.NopCloser(strings.NewReader(`{"status":"success","data":[` + strings.Join(data, ",") + `]}`)),
+	}, nil
+}
+
+func (m *mockLabelsQueryDownstream) requests() []url.Values {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+
+	return append([]url.Values(nil), m.received...)
+}
@@ -8,13 +8,17 @@ package querymiddleware
 import (
 	"context"
 	"net/http"
+	"strings"
 	"sync"
 	"time"
 
 	"github.com/go-kit/log"
 	"github.com/go-kit/log/level"
 	"github.com/opentracing/opentracing-go"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
 	"github.com/prometheus/prometheus/model/timestamp"
+	"github.com/prometheus/prometheus/promql/parser"
 	"github.com/weaveworks/common/user"
 
 	"github.com/grafana/dskit/tenant"
@@ -34,6 +38,9 @@ type Limits interface {
 	// MaxQueryLength returns the limit of the length (in time) of a query.
 	MaxQueryLength(userID string) time.Duration
 
+	// MaxLabelsQueryLength returns the limit of the length (in time) of a label names, label values or series request.
+	MaxLabelsQueryLength(userID string) time.Duration
+
 	// MaxQueryParallelism returns the limit to the number of split queries the
 	// frontend will process in parallel.
 	MaxQueryParallelism(userID string) int
@@ -42,6 +49,9 @@ type Limits interface {
 	// to prevent caching of very recent results.
 	MaxCacheFreshness(userID string) time.Duration
 
+	// BlockedQueries returns the queries to reject for a given tenant.
+	BlockedQueries(userID string) []validation.BlockedQuery
+
 	// QueryShardingTotalShards returns the number of shards to use for a given tenant.
 	QueryShardingTotalShards(userID string) int
 
@@ -54,19 +64,39 @@ type Limits interface {
 	CompactorSplitAndMergeShards(userID string) int
 }
 
+type limitsMiddlewareMetrics struct {
+	blockedQueries *prometheus.CounterVec
+}
+
+func newLimitsMiddlewareMetrics(registerer prometheus.Registerer) *limitsMiddlewareMetrics {
+	return &limitsMiddlewareMetrics{
+		blockedQueries: promauto.With(registerer).NewCounterVec(prometheus.CounterOpts{
+			Namespace: "cortex",
+			Name:      "query_frontend_blocked_queries_total",
+			Help:      "Number of queries rejected because they match a blocked query configured for the tenant.",
+		}, []string{"user"}),
+	}
+}
+
 type limitsMiddleware struct {
 	Limits
-	next   Handler
-	logger log.Logger
+	next    Handler
+	logger  log.Logger
+	metrics *limitsMiddlewareMetrics
 }
 
 // newLimitsMiddleware creates a new Middleware that enforces query limits.
-func newLimitsMiddleware(l Limits, logger log.Logger) Middleware {
+func newLimitsMiddleware(l Limits, logger log.Logger, metrics *limitsMiddlewareMetrics) Middleware {
+	if metrics == nil {
+		metrics = newLimitsMiddlewareMetrics(nil)
+	}
+
 	return MiddlewareFunc(func(next Handler) Handler {
 		return limitsMiddleware{
-			next:   next,
-			Limits: l,
-			logger: logger,
+			next:    next,
+			Limits:  l,
+			logger:  logger,
+			metrics: metrics,
 		}
 	})
 }
@@ -80,6 +110,14 @@ func (l limitsMiddleware) Do(ctx context.Context, r Request) (Response, error) {
 		return nil, apierror.New(apierror.TypeBadData, err.Error())
 	}
 
+	// Reject the query if it's blocked for any of the tenants.
+	for _, tenantID := range tenantIDs {
+		if l.isBlocked(log, tenantID, r.GetQuery()) {
+			l.metrics.blockedQueries.WithLabelValues(tenantID).Inc()
+			return nil, apierror.New(apierror.TypeBadData, validation.NewQueryBlockedError().Error())
+		}
+	}
+
 	// Clamp the time range based on the max query lookback.
 
 	if maxQueryLookback := validation.SmallestPositiveNonZeroDurationPerTenant(tenantIDs, l.MaxQueryLookback); maxQueryLookback > 0 {
@@ -119,6 +157,29 @@ func (l limitsMiddleware) Do(ctx context.Context, r Request) (Response, error) {
 	return l.next.Do(ctx, r)
 }
 
+// isBlocked returns whether the query matches any of the blocked queries configured for the tenant.
+func (l limitsMiddleware) isBlocked(logger log.Logger, tenantID, query string) bool {
+	blocks := l.BlockedQueries(tenantID)
+	if len(blocks) == 0 {
+		return false
+	}
+
+	query = strings.TrimSpace(query)
+	normalisedQuery := ""
+	if expr, err := parser.ParseExpr(query); err == nil {
+		normalisedQuery = expr.String()
+	}
+
+	for _, block := range blocks {
+		if block.Matches(query, normalisedQuery) {
+			level.Info(logger).Log("msg", "query blocked", "user", tenantID, "pattern", block.Pattern, "regex", block.Regex)
+			return true
+		}
+	}
+
+	return false
+}
+
 type limitedParallelismRoundTripper struct {
 	downstream Handler
 	limits     Limits
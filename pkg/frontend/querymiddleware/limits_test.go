@@ -8,11 +8,14 @@ package querymiddleware
 import (
 	"context"
 	"net/http"
+	"strings"
 	"sync"
 	"testing"
 	"time"
 
 	"github.com/go-kit/log"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
@@ -20,6 +23,7 @@ import (
 	"go.uber.org/atomic"
 
 	"github.com/grafana/mimir/pkg/util"
+	"github.com/grafana/mimir/pkg/util/validation"
 )
 
 func TestLimitsMiddleware_MaxQueryLookback(t *testing.T) {
@@ -81,7 +85,7 @@ func TestLimitsMiddleware_MaxQueryLookback(t *testing.T) {
 			}
 
 			limits := mockLimits{maxQueryLookback: testData.maxQueryLookback}
-			middleware := newLimitsMiddleware(limits, log.NewNopLogger())
+			middleware := newLimitsMiddleware(limits, log.NewNopLogger(), nil)
 
 			innerRes := newEmptyPrometheusResponse()
 			inner := &mockHandler{}
@@ -166,7 +170,7 @@ func TestLimitsMiddleware_MaxQueryLength(t *testing.T) {
 			}
 
 			limits := mockLimits{maxQueryLength: testData.maxQueryLength}
-			middleware := newLimitsMiddleware(limits, log.NewNopLogger())
+			middleware := newLimitsMiddleware(limits, log.NewNopLogger(), nil)
 
 			innerRes := newEmptyPrometheusResponse()
 			inner := &mockHandler{}
@@ -195,14 +199,104 @@ func TestLimitsMiddleware_MaxQueryLength(t *testing.T) {
 	}
 }
 
+func TestLimitsMiddleware_BlockedQueries(t *testing.T) {
+	type blockedQuery struct {
+		pattern string
+		regex   bool
+	}
+
+	tests := map[string]struct {
+		query          string
+		blockedQueries []blockedQuery
+		expectedErr    bool
+	}{
+		"should allow any query if no blocked queries are configured": {
+			query: "up",
+		},
+		"should block a query matching a blocked query exactly": {
+			query:          "sum(rate(http_requests_total[5m]))",
+			blockedQueries: []blockedQuery{{pattern: "sum(rate(http_requests_total[5m]))"}},
+			expectedErr:    true,
+		},
+		"should ignore surrounding whitespaces when matching a blocked query exactly": {
+			query:          "  up\n",
+			blockedQueries: []blockedQuery{{pattern: " up "}},
+			expectedErr:    true,
+		},
+		"should ignore the formatting differences when matching a blocked query exactly": {
+			query:          "sum by(job)(rate( http_requests_total [5m] ))",
+			blockedQueries: []blockedQuery{{pattern: "sum  by (job) (rate(http_requests_total[5m]))"}},
+			expectedErr:    true,
+		},
+		"should allow a query only containing a blocked query": {
+			query:          "sum(up)",
+			blockedQueries: []blockedQuery{{pattern: "up"}},
+		},
+		"should allow a query which can't be parsed": {
+			query:          "sum(up",
+			blockedQueries: []blockedQuery{{pattern: "sum(up)"}},
+		},
+		"should block a query matching a blocked regex": {
+			query:          "sum by (pod) (rate(container_cpu_usage_seconds_total[1m]))",
+			blockedQueries: []blockedQuery{{pattern: ".*container_cpu_usage_seconds_total.*", regex: true}},
+			expectedErr:    true,
+		},
+		"should require a blocked regex to match the whole query": {
+			query:          "sum(container_cpu_usage_seconds_total)",
+			blockedQueries: []blockedQuery{{pattern: "container_cpu_usage_seconds_total", regex: true}},
+		},
+	}
+
+	for testName, testData := range tests {
+		t.Run(testName, func(t *testing.T) {
+			req := &PrometheusRangeQueryRequest{Query: testData.query}
+
+			reg := prometheus.NewPedanticRegistry()
+			limits := mockLimits{}
+			for _, q := range testData.blockedQueries {
+				blocked, err := validation.NewBlockedQuery(q.pattern, q.regex)
+				require.NoError(t, err)
+				limits.blockedQueries = append(limits.blockedQueries, blocked)
+			}
+			middleware := newLimitsMiddleware(limits, log.NewNopLogger(), newLimitsMiddlewareMetrics(reg))
+
+			innerRes := newEmptyPrometheusResponse()
+			inner := &mockHandler{}
+			inner.On("Do", mock.Anything, mock.Anything).Return(innerRes, nil)
+
+			ctx := user.InjectOrgID(context.Background(), "test")
+			res, err := middleware.Wrap(inner).Do(ctx, req)
+
+			if testData.expectedErr {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), "the request has been blocked by the cluster administrator")
+				assert.Nil(t, res)
+				assert.Len(t, inner.Calls, 0)
+				assert.NoError(t, testutil.GatherAndCompare(reg, strings.NewReader(`
+					# HELP cortex_query_frontend_blocked_queries_total Number of queries rejected because they match a blocked query configured for the tenant.
+					# TYPE cortex_query_frontend_blocked_queries_total counter
+					cortex_query_frontend_blocked_queries_total{user="test"} 1
+				`)))
+			} else {
+				require.NoError(t, err)
+				assert.Same(t, innerRes, res)
+				assert.Len(t, inner.Calls, 1)
+				assert.NoError(t, testutil.GatherAndCompare(reg, strings.NewReader(""), "cortex_query_frontend_blocked_queries_total"))
+			}
+		})
+	}
+}
+
 type mockLimits struct {
-	maxQueryLookback    time.Duration
-	maxQueryLength      time.Duration
-	maxCacheFreshness   time.Duration
-	maxQueryParallelism int
-	maxShardedQueries   int
-	totalShards         int
-	compactorShards     int
+	maxQueryLookback     time.Duration
+	maxQueryLength       time.Duration
+	maxLabelsQueryLength time.Duration
+	maxCacheFreshness    time.Duration
+	maxQueryParallelism  int
+	maxShardedQueries    int
+	totalShards          int
+	compactorShards      int
+	blockedQueries       []validation.BlockedQuery
 }
 
 func (m mockLimits) MaxQueryLookback(string) time.Duration {
@@ -213,6 +307,10 @@ func (m mockLimits) MaxQueryLength(string) time.Duration {
 	return m.maxQueryLength
 }
 
+func (m mockLimits) MaxLabelsQueryLength(string) time.Duration {
+	return m.maxLabelsQueryLength
+}
+
 func (m mockLimits) MaxQueryParallelism(string) int {
 	if m.maxQueryParallelism == 0 {
 		return 14 // Flag default.
@@ -224,6 +322,10 @@ func (m mockLimits) MaxCacheFreshness(string) time.Duration {
 	return m.maxCacheFreshness
 }
 
+func (m mockLimits) BlockedQueries(string) []validation.BlockedQuery {
+	return m.blockedQueries
+}
+
 func (m mockLimits) QueryShardingTotalShards(string) int {
 	return m.totalShards
 }
@@ -8,6 +8,7 @@ package querymiddleware
 import (
 	"context"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/go-kit/log"
@@ -46,6 +47,7 @@ type querySharding struct {
 type queryShardingMetrics struct {
 	shardingAttempts       prometheus.Counter
 	shardingSuccesses      prometheus.Counter
+	shardingSkipped        *prometheus.CounterVec
 	shardedQueries         prometheus.Counter
 	shardedQueriesPerQuery prometheus.Histogram
 }
@@ -73,6 +75,11 @@ func newQueryShardingMiddleware(
 			Name:      "frontend_query_sharding_rewrites_succeeded_total",
 			Help:      "Total number of queries the query-frontend successfully rewritten in a shardable way.",
 		}),
+		shardingSkipped: promauto.With(registerer).NewCounterVec(prometheus.CounterOpts{
+			Namespace: "cortex",
+			Name:      "frontend_query_sharding_rewrites_skipped_total",
+			Help:      "Total number of queries the query-frontend didn't rewrite in a shardable way, by reason why they're not shardable.",
+		}, []string{"reason"}),
 		shardedQueries: promauto.With(registerer).NewCounter(prometheus.CounterOpts{
 			Namespace: "cortex",
 			Name:      "frontend_sharded_queries_total",
@@ -120,7 +127,14 @@ func (s *querySharding) Do(ctx context.Context, r Request) (Response, error) {
 		if err != nil {
 			level.Warn(log).Log("msg", "failed to rewrite the input query into a shardable query, falling back to try executing without sharding", "query", r.GetQuery(), "err", err)
 		} else {
-			level.Debug(log).Log("msg", "query is not supported for being rewritten into a shardable query", "query", r.GetQuery())
+			reasons := shardingStats.GetNotShardableReasons()
+			if len(reasons) == 0 {
+				reasons = []string{astmapper.NotShardableReasonNotWorthSharding}
+			}
+			for _, reason := range reasons {
+				s.shardingSkipped.WithLabelValues(reason).Inc()
+			}
+			level.Debug(log).Log("msg", "query is not supported for being rewritten into a shardable query", "query", r.GetQuery(), "reasons", strings.Join(reasons, ","))
 		}
 
 		return s.next.Do(ctx, r)
@@ -387,6 +387,42 @@ func TestQueryShardingCorrectness(t *testing.T) {
 			query:                  `max_over_time(metric_counter[5m]) > scalar(min(metric_counter))`,
 			expectedShardedQueries: 1, // scalar on the right should be sharded, but not the binary op itself, hence 1
 		},
+		"topk()": {
+			query:                  `topk(2, metric_counter{const="fixed"})`,
+			expectedShardedQueries: 1,
+		},
+		"bottomk()": {
+			query:                  `bottomk(2, metric_counter{const="fixed"})`,
+			expectedShardedQueries: 1,
+		},
+		"topk() by some label": {
+			query:                  `topk by (group_1) (3, rate(metric_counter[1m]))`,
+			expectedShardedQueries: 1,
+		},
+		"sum() of topk()": {
+			query:                  `sum by (group_1) (topk(5, rate(metric_counter[1m])))`,
+			expectedShardedQueries: 1,
+		},
+		"quantile_over_time()": {
+			query:                  `quantile_over_time(0.9, metric_counter[5m])`,
+			expectedShardedQueries: 1,
+		},
+		"quantile_over_time() on a subquery": {
+			query:                  `quantile_over_time(0.9, rate(metric_counter[1m])[5m:1m])`,
+			expectedShardedQueries: 1,
+		},
+		"binary operation between the same metric": {
+			query:                  `rate(metric_counter[1m]) / rate(metric_counter[1m] offset 2m) > 0`,
+			expectedShardedQueries: 1,
+		},
+		"histogram_quantile() with binary operation on the same metric": {
+			query:                  `histogram_quantile(0.5, sum by (le) (rate(metric_histogram_bucket[1m]) - rate(metric_histogram_bucket[1m] offset 2m) * 0.5))`,
+			expectedShardedQueries: 1,
+		},
+		"histogram_quantile() with binary operation between aggregations": {
+			query:                  `histogram_quantile(0.5, sum by (le) (rate(metric_histogram_bucket[1m])) + on(le) sum by (le) (rate(metric_histogram_bucket[1m] offset 2m)))`,
+			expectedShardedQueries: 2,
+		},
 		//
 		// The following queries are not expected to be shardable.
 		//
@@ -416,12 +452,12 @@ func TestQueryShardingCorrectness(t *testing.T) {
 			query:                  `stdvar(metric_counter{const="fixed"})`,
 			expectedShardedQueries: 0,
 		},
-		"topk()": {
-			query:                  `topk(2, metric_counter{const="fixed"})`,
+		"topk() with non constant parameter": {
+			query:                  `topk(scalar(count(metric_counter{unique="1"})), metric_counter{const="fixed"})`,
 			expectedShardedQueries: 0,
 		},
-		"bottomk()": {
-			query:                  `bottomk(2, metric_counter{const="fixed"})`,
+		"binary operation not matching on all labels": {
+			query:                  `rate(metric_counter[1m]) + ignoring(group_2) metric_counter`,
 			expectedShardedQueries: 0,
 		},
 		"vector()": {
@@ -936,6 +972,37 @@ func TestQuerySharding_ShouldFallbackToDownstreamHandlerOnMappingFailure(t *test
 	downstream.AssertCalled(t, "Do", mock.Anything, mock.Anything)
 }
 
+func TestQuerySharding_ShouldTrackNotShardableReasons(t *testing.T) {
+	reg := prometheus.NewPedanticRegistry()
+	shardingware := newQueryShardingMiddleware(log.NewNopLogger(), newEngine(), mockLimits{totalShards: 16}, reg)
+
+	downstream := &mockHandler{}
+	downstream.On("Do", mock.Anything, mock.Anything).Return(&PrometheusResponse{Status: statusSuccess}, nil)
+
+	for _, query := range []string{`quantile(0.9, foo)`, `foo > bar`, `foo`} {
+		req := &PrometheusRangeQueryRequest{
+			Path:  "/query_range",
+			Start: util.TimeToMillis(start),
+			End:   util.TimeToMillis(end),
+			Step:  step.Milliseconds(),
+			Query: query,
+		}
+
+		// The not shardable query should be executed by the downstream handler as is.
+		_, err := shardingware.Wrap(downstream).Do(user.InjectOrgID(context.Background(), "test"), req)
+		require.NoError(t, err)
+		downstream.AssertCalled(t, "Do", mock.Anything, req)
+	}
+
+	assert.NoError(t, testutil.GatherAndCompare(reg, strings.NewReader(`
+		# HELP cortex_frontend_query_sharding_rewrites_skipped_total Total number of queries the query-frontend didn't rewrite in a shardable way, by reason why they're not shardable.
+		# TYPE cortex_frontend_query_sharding_rewrites_skipped_total counter
+		cortex_frontend_query_sharding_rewrites_skipped_total{reason="aggregation"} 1
+		cortex_frontend_query_sharding_rewrites_skipped_total{reason="binary-operation"} 1
+		cortex_frontend_query_sharding_rewrites_skipped_total{reason="not-worth-sharding"} 1
+	`), "cortex_frontend_query_sharding_rewrites_skipped_total"))
+}
+
 func TestQuerySharding_ShouldSkipShardingViaOption(t *testing.T) {
 	req := &PrometheusRangeQueryRequest{
 		Path:  "/query_range",
@@ -43,7 +43,7 @@ const (
 )
 
 var (
-	supportedResultsCacheBackends = []string{cache.BackendMemcached}
+	supportedResultsCacheBackends = []string{cache.BackendMemcached, cache.BackendRedis}
 )
 
 // ResultsCacheConfig is the config for the results cache.
@@ -56,6 +56,7 @@ type ResultsCacheConfig struct {
 func (cfg *ResultsCacheConfig) RegisterFlags(f *flag.FlagSet) {
 	f.StringVar(&cfg.Backend, "query-frontend.results-cache.backend", "", fmt.Sprintf("Backend for query-frontend results cache, if not empty. Supported values: %s.", supportedResultsCacheBackends))
 	cfg.Memcached.RegisterFlagsWithPrefix(f, "query-frontend.results-cache.memcached.")
+	cfg.Redis.RegisterFlagsWithPrefix(f, "query-frontend.results-cache.redis.")
 	cfg.Compression.RegisterFlagsWithPrefix(f, "query-frontend.results-cache.")
 }
 
@@ -70,6 +71,12 @@ func (cfg *ResultsCacheConfig) Validate() error {
 		}
 	}
 
+	if cfg.Backend == cache.BackendRedis {
+		if err := cfg.Redis.Validate(); err != nil {
+			return errors.Wrap(err, "query-frontend results cache")
+		}
+	}
+
 	if err := cfg.Compression.Validate(); err != nil {
 		return errors.Wrap(err, "query-frontend results cache")
 	}
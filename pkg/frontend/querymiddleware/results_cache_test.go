@@ -56,6 +56,28 @@ func TestResultsCacheConfig_Validate(t *testing.T) {
 			},
 			expected: errors.New("query-frontend results cache: no memcached addresses configured"),
 		},
+		"should pass with redis backend": {
+			cfg: ResultsCacheConfig{
+				BackendConfig: cache.BackendConfig{
+					Backend: cache.BackendRedis,
+					Redis: cache.RedisConfig{
+						Endpoint:            "localhost:6379",
+						MaxAsyncConcurrency: 1,
+					},
+				},
+			},
+		},
+		"should fail with invalid redis config": {
+			cfg: ResultsCacheConfig{
+				BackendConfig: cache.BackendConfig{
+					Backend: cache.BackendRedis,
+					Redis: cache.RedisConfig{
+						Endpoint: "",
+					},
+				},
+			},
+			expected: errors.New("query-frontend results cache: no redis endpoints configured"),
+		},
 		"should fail with unsupported backend": {
 			cfg: ResultsCacheConfig{
 				BackendConfig: cache.BackendConfig{
@@ -41,6 +41,8 @@ type Config struct {
 	MaxRetries             int  `yaml:"max_retries" category:"advanced"`
 	ShardedQueries         bool `yaml:"parallelize_shardable_queries"`
 	CacheUnalignedRequests bool `yaml:"cache_unaligned_requests" category:"advanced"`
+	SplitAndCacheLabels    bool `yaml:"split_and_cache_labels_queries" category:"experimental"`
+	CacheInstantQueries    bool `yaml:"cache_instant_queries" category:"experimental"`
 }
 
 // RegisterFlags adds the flags required to config this to the given FlagSet.
@@ -51,6 +53,8 @@ func (cfg *Config) RegisterFlags(f *flag.FlagSet) {
 	f.BoolVar(&cfg.CacheResults, "query-frontend.cache-results", false, "Cache query results.")
 	f.BoolVar(&cfg.ShardedQueries, "query-frontend.parallelize-shardable-queries", false, "True to enable query sharding.")
 	f.BoolVar(&cfg.CacheUnalignedRequests, "query-frontend.cache-unaligned-requests", false, "Cache requests that are not step-aligned.")
+	f.BoolVar(&cfg.SplitAndCacheLabels, "query-frontend.split-and-cache-labels-queries", false, "True to split label names, label values and series requests by -query-frontend.split-queries-by-interval and, if -query-frontend.cache-results is enabled, cache their results.")
+	f.BoolVar(&cfg.CacheInstantQueries, "query-frontend.cache-instant-queries", false, "True to cache the results of instant queries. Requires -query-frontend.cache-results to be enabled.")
 	cfg.ResultsCacheConfig.RegisterFlags(f)
 }
 
@@ -157,31 +161,31 @@ func newQueryTripperware(
 ) (Tripperware, error) {
 	// Metric used to keep track of each middleware execution duration.
 	metrics := newInstrumentMiddlewareMetrics(registerer)
+	limitsMiddlewareMetrics := newLimitsMiddlewareMetrics(registerer)
 
 	queryRangeMiddleware := []Middleware{
 		// Track query range statistics. Added first before any subsequent middleware modifies the request.
 		newQueryStatsMiddleware(registerer),
-		newLimitsMiddleware(limits, log),
+		newLimitsMiddleware(limits, log, limitsMiddlewareMetrics),
 	}
 	if cfg.AlignQueriesWithStep {
 		queryRangeMiddleware = append(queryRangeMiddleware, newInstrumentMiddleware("step_align", metrics, log), newStepAlignMiddleware())
 	}
 
-	// Inject the middleware to split requests by interval + results cache (if at least one of the two is enabled).
-	if cfg.SplitQueriesByInterval > 0 || cfg.CacheResults {
-		var c cache.Cache
-
-		// Init the cache client.
-		if cfg.CacheResults {
-			var err error
+	// Init the cache client, shared by the range queries and labels queries results cache.
+	var c cache.Cache
+	if cfg.CacheResults {
+		var err error
 
-			c, err = newResultsCache(cfg.ResultsCacheConfig, log, registerer)
-			if err != nil {
-				return nil, err
-			}
-			c = cache.NewCompression(cfg.ResultsCacheConfig.Compression, c, log)
+		c, err = newResultsCache(cfg.ResultsCacheConfig, log, registerer)
+		if err != nil {
+			return nil, err
 		}
+		c = cache.NewCompression(cfg.ResultsCacheConfig.Compression, c, log)
+	}
 
+	// Inject the middleware to split requests by interval + results cache (if at least one of the two is enabled).
+	if cfg.SplitQueriesByInterval > 0 || cfg.CacheResults {
 		shouldCache := func(r Request) bool {
 			return !r.GetOptions().CacheDisabled
 		}
@@ -201,7 +205,17 @@ func newQueryTripperware(
 			registerer,
 		))
 	}
-	queryInstantMiddleware := []Middleware{newLimitsMiddleware(limits, log)}
+	queryInstantMiddleware := []Middleware{newLimitsMiddleware(limits, log, limitsMiddlewareMetrics)}
+
+	// Inject the results cache for instant queries. It's added before query sharding, so that
+	// the merged result of the sharded query is cached.
+	if cfg.CacheResults && cfg.CacheInstantQueries {
+		queryInstantMiddleware = append(
+			queryInstantMiddleware,
+			newInstrumentMiddleware("instant_query_results_cache", metrics, log),
+			newInstantQueryCacheMiddleware(limits, c, cacheExtractor, log, registerer),
+		)
+	}
 
 	if cfg.ShardedQueries {
 		// Disable concurrency limits for sharded queries.
@@ -230,18 +244,31 @@ func newQueryTripperware(
 		queryInstantMiddleware = append(queryInstantMiddleware, newInstrumentMiddleware("retry", metrics, log), newRetryMiddleware(log, cfg.MaxRetries, retryMiddlewareMetrics))
 	}
 
+	labelsTripperware := newLabelsQueryTripperware(
+		cfg.SplitAndCacheLabels && cfg.SplitQueriesByInterval > 0,
+		cfg.SplitQueriesByInterval,
+		cfg.SplitAndCacheLabels && cfg.CacheResults,
+		c,
+		limits,
+		log,
+		registerer,
+	)
+
 	return func(next http.RoundTripper) http.RoundTripper {
 		queryrange := newLimitedParallelismRoundTripper(next, codec, limits, queryRangeMiddleware...)
 		instant := defaultInstantQueryParamsRoundTripper(
 			newLimitedParallelismRoundTripper(next, codec, limits, queryInstantMiddleware...),
 			time.Now,
 		)
+		labels := labelsTripperware(next)
 		return RoundTripFunc(func(r *http.Request) (*http.Response, error) {
 			switch {
 			case isRangeQuery(r.URL.Path):
 				return queryrange.RoundTrip(r)
 			case isInstantQuery(r.URL.Path):
 				return instant.RoundTrip(r)
+			case isLabelsQuery(r.URL.Path) && cfg.SplitAndCacheLabels:
+				return labels.RoundTrip(r)
 			default:
 				return next.RoundTrip(r)
 			}
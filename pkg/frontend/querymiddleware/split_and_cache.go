@@ -28,6 +28,7 @@ import (
 
 	apierror "github.com/grafana/mimir/pkg/api/error"
 	"github.com/grafana/mimir/pkg/cache"
+	"github.com/grafana/mimir/pkg/querier/stats"
 	"github.com/grafana/mimir/pkg/util/spanlogger"
 	"github.com/grafana/mimir/pkg/util/validation"
 )
@@ -151,6 +152,9 @@ func (s *splitAndCacheMiddleware) Do(ctx context.Context, req Request) (Response
 		// Lookup all keys from cache.
 		fetchedExtents := s.fetchCacheExtents(ctx, lookupKeys)
 
+		queryStats := stats.FromContext(ctx)
+		queryStats.AddResultsCacheRequests(uint32(len(lookupKeys)))
+
 		for lookupIdx, extents := range fetchedExtents {
 			if len(extents) == 0 {
 				// We just need to run the request as is because no part of it has been cached yet.
@@ -166,6 +170,8 @@ func (s *splitAndCacheMiddleware) Do(ctx context.Context, req Request) (Response
 			}
 
 			if len(requests) == 0 {
+				queryStats.AddResultsCacheHits(1)
+
 				// The full response has been picked up from the cache so we can merge it and store it.
 				response, err := s.merger.MergeResponse(responses...)
 				if err != nil {
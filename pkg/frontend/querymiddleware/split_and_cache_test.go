@@ -34,6 +34,7 @@ import (
 	apierror "github.com/grafana/mimir/pkg/api/error"
 	"github.com/grafana/mimir/pkg/cache"
 	"github.com/grafana/mimir/pkg/mimirpb"
+	"github.com/grafana/mimir/pkg/querier/stats"
 	"github.com/grafana/mimir/pkg/util"
 )
 
@@ -99,7 +100,7 @@ func TestSplitAndCacheMiddleware_SplitByInterval(t *testing.T) {
 
 	// Chain middlewares together.
 	middlewares := []Middleware{
-		newLimitsMiddleware(mockLimits{}, log.NewNopLogger()),
+		newLimitsMiddleware(mockLimits{}, log.NewNopLogger(), nil),
 		splitCacheMiddleware,
 		newAssertHintsMiddleware(t, &Hints{TotalQueries: 2}),
 	}
@@ -189,11 +190,14 @@ func TestSplitAndCacheMiddleware_ResultsCache(t *testing.T) {
 	assert.Equal(t, 1, cacheBackend.CountStoreCalls())
 
 	// Doing same request again shouldn't change anything.
-	resp, err = rc.Do(ctx, req)
+	queryStats, statsCtx := stats.ContextWithEmptyStats(ctx)
+	resp, err = rc.Do(statsCtx, req)
 	require.NoError(t, err)
 	require.Equal(t, 1, downstreamReqs)
 	require.Equal(t, expectedResponse, resp)
 	assert.Equal(t, 1, cacheBackend.CountStoreCalls())
+	assert.Equal(t, uint32(1), queryStats.LoadResultsCacheRequests())
+	assert.Equal(t, uint32(1), queryStats.LoadResultsCacheHits())
 
 	// Doing request with new end time should do one more query.
 	req = req.WithStartEnd(req.GetStart(), req.GetEnd()+step)
@@ -8,6 +8,8 @@ package transport
 import (
 	"bytes"
 	"context"
+	"encoding/json"
+	"errors"
 	"flag"
 	"fmt"
 	"io"
@@ -29,6 +31,7 @@ import (
 
 	apierror "github.com/grafana/mimir/pkg/api/error"
 	querier_stats "github.com/grafana/mimir/pkg/querier/stats"
+	"github.com/grafana/mimir/pkg/scheduler/queue"
 	"github.com/grafana/mimir/pkg/util"
 	util_log "github.com/grafana/mimir/pkg/util/log"
 )
@@ -37,6 +40,10 @@ const (
 	// StatusClientClosedRequest is the status code for when a client request cancellation of an http request
 	StatusClientClosedRequest = 499
 	ServiceTimingHeaderName   = "Server-Timing"
+
+	// QueryStatsHeaderName is the request header a client can set to "true" to get the query
+	// statistics returned in the "queryStats" field of the JSON response body.
+	QueryStatsHeaderName = "X-Mimir-Query-Stats"
 )
 
 var (
@@ -130,6 +137,16 @@ func (f *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		r = r.WithContext(ctx)
 	}
 
+	// Propagate the query priority requested by the client down the request chain.
+	if name := r.Header.Get(queue.PriorityHeaderName); name != "" {
+		priority, err := queue.ParsePriority(name)
+		if err != nil {
+			writeError(w, apierror.New(apierror.TypeBadData, err.Error()))
+			return
+		}
+		r = r.WithContext(queue.ContextWithPriority(r.Context(), priority))
+	}
+
 	defer func() {
 		_ = r.Body.Close()
 	}()
@@ -143,11 +160,47 @@ func (f *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	resp, err := f.roundTripper.RoundTrip(r)
 	queryResponseTime := time.Since(startTime)
 
+	// Check whether we should parse the query string.
+	shouldReportSlowQuery := f.cfg.LogQueriesLongerThan > 0 && queryResponseTime > f.cfg.LogQueriesLongerThan
+	if shouldReportSlowQuery || f.cfg.QueryStatsEnabled {
+		queryString = f.parseRequestQueryString(r, buf)
+	}
+
+	if shouldReportSlowQuery {
+		f.reportSlowQuery(r, queryString, queryResponseTime)
+	}
+
 	if err != nil {
+		if f.cfg.QueryStatsEnabled {
+			f.reportQueryStats(r, queryString, queryResponseTime, stats, 0, err)
+		}
+
 		writeError(w, err)
 		return
 	}
 
+	if f.cfg.QueryStatsEnabled {
+		f.reportQueryStats(r, queryString, queryResponseTime, stats, resp.StatusCode, nil)
+	}
+
+	body := resp.Body
+	if f.cfg.QueryStatsEnabled && shouldReturnQueryStats(r, resp) {
+		// Buffer the body, so that the original response can be returned if the stats can't be added to it.
+		original, err := ioutil.ReadAll(resp.Body)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+
+		if withStats, err := injectQueryStats(original, queryResponseTime, stats); err != nil {
+			level.Warn(util_log.WithContext(r.Context(), f.log)).Log("msg", "unable to add query stats to the response body", "err", err)
+			body = ioutil.NopCloser(bytes.NewReader(original))
+		} else {
+			body = ioutil.NopCloser(bytes.NewReader(withStats))
+			resp.Header.Del("Content-Length")
+		}
+	}
+
 	hs := w.Header()
 	for h, vs := range resp.Header {
 		hs[h] = vs
@@ -159,20 +212,7 @@ func (f *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 
 	w.WriteHeader(resp.StatusCode)
 	// we don't check for copy error as there is no much we can do at this point
-	_, _ = io.Copy(w, resp.Body)
-
-	// Check whether we should parse the query string.
-	shouldReportSlowQuery := f.cfg.LogQueriesLongerThan > 0 && queryResponseTime > f.cfg.LogQueriesLongerThan
-	if shouldReportSlowQuery || f.cfg.QueryStatsEnabled {
-		queryString = f.parseRequestQueryString(r, buf)
-	}
-
-	if shouldReportSlowQuery {
-		f.reportSlowQuery(r, queryString, queryResponseTime)
-	}
-	if f.cfg.QueryStatsEnabled {
-		f.reportQueryStats(r, queryString, queryResponseTime, stats)
-	}
+	_, _ = io.Copy(w, body)
 }
 
 // reportSlowQuery reports slow queries.
@@ -188,7 +228,7 @@ func (f *Handler) reportSlowQuery(r *http.Request, queryString url.Values, query
 	level.Info(util_log.WithContext(r.Context(), f.log)).Log(logMessage...)
 }
 
-func (f *Handler) reportQueryStats(r *http.Request, queryString url.Values, queryResponseTime time.Duration, stats *querier_stats.Stats) {
+func (f *Handler) reportQueryStats(r *http.Request, queryString url.Values, queryResponseTime time.Duration, stats *querier_stats.Stats, statusCode int, queryErr error) {
 	tenantIDs, err := tenant.TenantIDs(r.Context())
 	if err != nil {
 		return
@@ -208,7 +248,7 @@ func (f *Handler) reportQueryStats(r *http.Request, queryString url.Values, quer
 	f.activeUsers.UpdateUserTimestamp(userID, time.Now())
 
 	// Log stats.
-	logMessage := append([]interface{}{
+	logMessage := []interface{}{
 		"msg", "query stats",
 		"component", "query-frontend",
 		"method", r.Method,
@@ -219,11 +259,57 @@ func (f *Handler) reportQueryStats(r *http.Request, queryString url.Values, quer
 		"fetched_chunk_bytes", numBytes,
 		"fetched_chunks_count", numChunks,
 		"sharded_queries", stats.LoadShardedQueries(),
-	}, formatQueryString(queryString)...)
+		"queue_time_seconds", stats.LoadQueueTime().Seconds(),
+	}
+
+	if length, ok := queryLength(queryString); ok {
+		logMessage = append(logMessage, "length", length)
+	}
+
+	if cacheRequests := stats.LoadResultsCacheRequests(); cacheRequests > 0 {
+		cacheHits := stats.LoadResultsCacheHits()
+		logMessage = append(logMessage,
+			"results_cache_requests", cacheRequests,
+			"results_cache_hits", cacheHits,
+			"results_cache_hit_ratio", float64(cacheHits)/float64(cacheRequests),
+		)
+	}
+
+	switch {
+	case queryErr == nil && statusCode/100 == 2:
+		logMessage = append(logMessage, "status", "success", "status_code", statusCode)
+	case queryErr == nil:
+		logMessage = append(logMessage, "status", "failed", "status_code", statusCode)
+	case errors.Is(queryErr, context.Canceled):
+		logMessage = append(logMessage, "status", "canceled", "err", queryErr)
+	default:
+		logMessage = append(logMessage, "status", "failed", "err", queryErr)
+	}
+
+	logMessage = append(logMessage, formatQueryString(queryString)...)
 
 	level.Info(util_log.WithContext(r.Context(), f.log)).Log(logMessage...)
 }
 
+// queryLength returns the time range covered by the query, if the query string contains
+// both a valid start and end time.
+func queryLength(queryString url.Values) (time.Duration, bool) {
+	if queryString.Get("start") == "" || queryString.Get("end") == "" {
+		return 0, false
+	}
+
+	start, err := util.ParseTime(queryString.Get("start"))
+	if err != nil {
+		return 0, false
+	}
+	end, err := util.ParseTime(queryString.Get("end"))
+	if err != nil {
+		return 0, false
+	}
+
+	return time.Duration(end-start) * time.Millisecond, true
+}
+
 func (f *Handler) parseRequestQueryString(r *http.Request, bodyBuf bytes.Buffer) url.Values {
 	// Use previously buffered body.
 	r.Body = ioutil.NopCloser(&bodyBuf)
@@ -279,3 +365,59 @@ func statsValue(name string, d time.Duration) string {
 	durationInMs := strconv.FormatFloat(float64(d)/float64(time.Millisecond), 'f', -1, 64)
 	return name + ";dur=" + durationInMs
 }
+
+// shouldReturnQueryStats returns whether the client asked to get the query stats in the response
+// body, and the response body is a JSON object we can add them to.
+func shouldReturnQueryStats(r *http.Request, resp *http.Response) bool {
+	if enabled, _ := strconv.ParseBool(r.Header.Get(QueryStatsHeaderName)); !enabled {
+		return false
+	}
+
+	return resp.Header.Get("Content-Encoding") == "" && strings.HasPrefix(resp.Header.Get("Content-Type"), "application/json")
+}
+
+// queryStatsResponse is the query stats reported in the response body.
+type queryStatsResponse struct {
+	ResponseTimeSeconds  float64 `json:"responseTimeSeconds"`
+	WallTimeSeconds      float64 `json:"wallTimeSeconds"`
+	QueueTimeSeconds     float64 `json:"queueTimeSeconds"`
+	FetchedSeriesCount   uint64  `json:"fetchedSeriesCount"`
+	FetchedChunkBytes    uint64  `json:"fetchedChunkBytes"`
+	FetchedChunksCount   uint64  `json:"fetchedChunksCount"`
+	ShardedQueries       uint32  `json:"shardedQueries"`
+	ResultsCacheRequests uint32  `json:"resultsCacheRequests"`
+	ResultsCacheHits     uint32  `json:"resultsCacheHits"`
+	ResultsCacheHitRatio float64 `json:"resultsCacheHitRatio"`
+}
+
+// injectQueryStats reads the JSON object from body and returns it with the query stats
+// added in the "queryStats" field.
+func injectQueryStats(body []byte, queryResponseTime time.Duration, stats *querier_stats.Stats) ([]byte, error) {
+	var obj map[string]json.RawMessage
+	if err := json.Unmarshal(body, &obj); err != nil {
+		return nil, err
+	}
+
+	res := queryStatsResponse{
+		ResponseTimeSeconds:  queryResponseTime.Seconds(),
+		WallTimeSeconds:      stats.LoadWallTime().Seconds(),
+		QueueTimeSeconds:     stats.LoadQueueTime().Seconds(),
+		FetchedSeriesCount:   stats.LoadFetchedSeries(),
+		FetchedChunkBytes:    stats.LoadFetchedChunkBytes(),
+		FetchedChunksCount:   stats.LoadFetchedChunks(),
+		ShardedQueries:       stats.LoadShardedQueries(),
+		ResultsCacheRequests: stats.LoadResultsCacheRequests(),
+		ResultsCacheHits:     stats.LoadResultsCacheHits(),
+	}
+	if res.ResultsCacheRequests > 0 {
+		res.ResultsCacheHitRatio = float64(res.ResultsCacheHits) / float64(res.ResultsCacheRequests)
+	}
+
+	encoded, err := json.Marshal(res)
+	if err != nil {
+		return nil, err
+	}
+	obj["queryStats"] = encoded
+
+	return json.Marshal(obj)
+}
@@ -6,12 +6,16 @@
 package transport
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
 	"io"
 	"net/http"
 	"net/http/httptest"
+	"strconv"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/go-kit/log"
 	"github.com/pkg/errors"
@@ -21,6 +25,9 @@ import (
 	"github.com/stretchr/testify/require"
 	"github.com/weaveworks/common/httpgrpc"
 	"github.com/weaveworks/common/user"
+
+	querier_stats "github.com/grafana/mimir/pkg/querier/stats"
+	"github.com/grafana/mimir/pkg/scheduler/queue"
 )
 
 type roundTripperFunc func(*http.Request) (*http.Response, error)
@@ -97,3 +104,204 @@ func TestHandler_ServeHTTP(t *testing.T) {
 		})
 	}
 }
+
+func TestHandler_ServeHTTP_QueryStatsLog(t *testing.T) {
+	for _, tt := range []struct {
+		name            string
+		roundTripperRes *http.Response
+		roundTripperErr error
+		expectedFields  []string
+	}{
+		{
+			name:            "successful query",
+			roundTripperRes: &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader("{}"))},
+			expectedFields: []string{
+				"status=success", "status_code=200", "user=12345", "queue_time_seconds=1.5", "sharded_queries=2",
+				"results_cache_requests=4", "results_cache_hits=3", "results_cache_hit_ratio=0.75",
+				"length=1h0m0s", "param_query=up", "param_step=15",
+			},
+		},
+		{
+			name:            "query returning a non-2xx status code",
+			roundTripperRes: &http.Response{StatusCode: http.StatusUnprocessableEntity, Body: io.NopCloser(strings.NewReader("{}"))},
+			expectedFields:  []string{"status=failed", "status_code=422"},
+		},
+		{
+			name:            "failed query",
+			roundTripperErr: errors.New("something went wrong"),
+			expectedFields:  []string{"status=failed", `err="something went wrong"`},
+		},
+		{
+			name:            "canceled query",
+			roundTripperErr: context.Canceled,
+			expectedFields:  []string{"status=canceled", `err="context canceled"`},
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			roundTripper := roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+				stats := querier_stats.FromContext(req.Context())
+				stats.AddQueueTime(1500 * time.Millisecond)
+				stats.AddShardedQueries(2)
+				stats.AddResultsCacheRequests(4)
+				stats.AddResultsCacheHits(3)
+
+				return tt.roundTripperRes, tt.roundTripperErr
+			})
+
+			logs := &bytes.Buffer{}
+			handler := NewHandler(HandlerConfig{QueryStatsEnabled: true}, roundTripper, log.NewLogfmtLogger(logs), prometheus.NewPedanticRegistry())
+
+			req := httptest.NewRequest("GET", "/api/v1/query_range?query=up&start=3600&end=7200&step=15", nil)
+			req = req.WithContext(user.InjectOrgID(context.Background(), "12345"))
+			handler.ServeHTTP(httptest.NewRecorder(), req)
+
+			require.Contains(t, logs.String(), `msg="query stats"`)
+			for _, field := range tt.expectedFields {
+				assert.Contains(t, logs.String(), field)
+			}
+		})
+	}
+}
+
+func TestHandler_ServeHTTP_ReturnQueryStats(t *testing.T) {
+	const body = `{"status":"success","data":{"resultType":"vector","result":[]}}`
+
+	for _, tt := range []struct {
+		name              string
+		cfg               HandlerConfig
+		headerValue       string
+		contentType       string
+		body              string
+		expectedWithStats bool
+	}{
+		{
+			name:              "header set and stats enabled",
+			cfg:               HandlerConfig{QueryStatsEnabled: true},
+			headerValue:       "true",
+			contentType:       "application/json",
+			expectedWithStats: true,
+		},
+		{
+			name:        "header not set",
+			cfg:         HandlerConfig{QueryStatsEnabled: true},
+			contentType: "application/json",
+		},
+		{
+			name:        "header set but stats disabled",
+			cfg:         HandlerConfig{QueryStatsEnabled: false},
+			headerValue: "true",
+			contentType: "application/json",
+		},
+		{
+			name:        "header set but response is not JSON",
+			cfg:         HandlerConfig{QueryStatsEnabled: true},
+			headerValue: "true",
+			contentType: "application/x-protobuf",
+		},
+		{
+			name:        "header set but response body is not a JSON object",
+			cfg:         HandlerConfig{QueryStatsEnabled: true},
+			headerValue: "true",
+			contentType: "application/json",
+			body:        `["not", "an", "object"]`,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			body := body
+			if tt.body != "" {
+				body = tt.body
+			}
+
+			roundTripper := roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+				stats := querier_stats.FromContext(req.Context())
+				stats.AddFetchedSeries(10)
+				stats.AddResultsCacheRequests(2)
+				stats.AddResultsCacheHits(1)
+
+				return &http.Response{
+					StatusCode: http.StatusOK,
+					Header:     http.Header{"Content-Type": {tt.contentType}, "Content-Length": {strconv.Itoa(len(body))}},
+					Body:       io.NopCloser(strings.NewReader(body)),
+				}, nil
+			})
+
+			handler := NewHandler(tt.cfg, roundTripper, log.NewNopLogger(), prometheus.NewPedanticRegistry())
+
+			req := httptest.NewRequest("GET", "/api/v1/query?query=up", nil)
+			req = req.WithContext(user.InjectOrgID(context.Background(), "12345"))
+			if tt.headerValue != "" {
+				req.Header.Set(QueryStatsHeaderName, tt.headerValue)
+			}
+			resp := httptest.NewRecorder()
+			handler.ServeHTTP(resp, req)
+			require.Equal(t, http.StatusOK, resp.Code)
+
+			if !tt.expectedWithStats {
+				assert.Equal(t, body, resp.Body.String())
+				return
+			}
+
+			assert.Empty(t, resp.Header().Get("Content-Length"))
+
+			var actual struct {
+				Status     string             `json:"status"`
+				QueryStats queryStatsResponse `json:"queryStats"`
+			}
+			require.NoError(t, json.Unmarshal(resp.Body.Bytes(), &actual))
+			assert.Equal(t, "success", actual.Status)
+			assert.Equal(t, uint64(10), actual.QueryStats.FetchedSeriesCount)
+			assert.Equal(t, uint32(2), actual.QueryStats.ResultsCacheRequests)
+			assert.Equal(t, uint32(1), actual.QueryStats.ResultsCacheHits)
+			assert.Equal(t, 0.5, actual.QueryStats.ResultsCacheHitRatio)
+		})
+	}
+}
+
+func TestHandler_ServeHTTP_QueryPriority(t *testing.T) {
+	for _, tt := range []struct {
+		name             string
+		headerValue      string
+		expectedStatus   int
+		expectedPriority queue.Priority
+	}{
+		{
+			name:             "no priority header",
+			expectedStatus:   http.StatusOK,
+			expectedPriority: queue.PriorityNormal,
+		},
+		{
+			name:             "high priority",
+			headerValue:      "high",
+			expectedStatus:   http.StatusOK,
+			expectedPriority: queue.PriorityHigh,
+		},
+		{
+			name:           "invalid priority",
+			headerValue:    "urgent",
+			expectedStatus: http.StatusBadRequest,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			var actualPriority queue.Priority
+			roundTripper := roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+				actualPriority = queue.PriorityFromContext(req.Context())
+				return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader("{}"))}, nil
+			})
+
+			handler := NewHandler(HandlerConfig{}, roundTripper, log.NewNopLogger(), nil)
+
+			req := httptest.NewRequest("GET", "/api/v1/query?query=up", nil)
+			req = req.WithContext(user.InjectOrgID(context.Background(), "12345"))
+			if tt.headerValue != "" {
+				req.Header.Set(queue.PriorityHeaderName, tt.headerValue)
+			}
+			resp := httptest.NewRecorder()
+			handler.ServeHTTP(resp, req)
+
+			require.Equal(t, tt.expectedStatus, resp.Code)
+			if tt.expectedStatus == http.StatusOK {
+				assert.Equal(t, tt.expectedPriority, actualPriority)
+			}
+		})
+	}
+}
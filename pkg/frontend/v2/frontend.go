@@ -31,6 +31,7 @@ import (
 
 	"github.com/grafana/mimir/pkg/frontend/v2/frontendv2pb"
 	"github.com/grafana/mimir/pkg/querier/stats"
+	"github.com/grafana/mimir/pkg/scheduler/queue"
 	"github.com/grafana/mimir/pkg/util/httpgrpcutil"
 )
 
@@ -84,6 +85,7 @@ type frontendRequest struct {
 	request      *httpgrpc.HTTPRequest
 	userID       string
 	statsEnabled bool
+	priority     queue.Priority
 
 	cancel context.CancelFunc
 
@@ -183,6 +185,7 @@ func (f *Frontend) RoundTripGRPC(ctx context.Context, req *httpgrpc.HTTPRequest)
 		request:      req,
 		userID:       userID,
 		statsEnabled: stats.IsEnabled(ctx),
+		priority:     queue.PriorityFromContext(ctx),
 
 		cancel: cancel,
 
@@ -295,6 +295,7 @@ func (w *frontendSchedulerWorker) schedulerLoop(loop schedulerpb.SchedulerForFro
 				HttpRequest:     req.request,
 				FrontendAddress: w.frontendAddr,
 				StatsEnabled:    req.statsEnabled,
+				Priority:        int32(req.priority),
 			})
 			w.enqueuedRequests.Inc()
 
@@ -124,6 +124,40 @@ func (c *ActiveSeries) Active(now time.Time) (int, []int, bool) {
 	return total, totalMatching, true
 }
 
+// ActiveWithMatchers returns the labels of the series active since now minus the timeout and matching all the
+// provided matchers. The order of the returned series is not guaranteed.
+func (c *ActiveSeries) ActiveWithMatchers(now time.Time, matchers []*labels.Matcher) []labels.Labels {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	keepUntilNanos := now.Add(-c.timeout).UnixNano()
+
+	var series []labels.Labels
+	for s := 0; s < numStripes; s++ {
+		series = c.stripes[s].appendActiveMatching(series, keepUntilNanos, matchers)
+	}
+
+	return series
+}
+
+// appendActiveMatching appends to the provided slice the labels of the entries updated not before keepUntilNanos
+// and matching all the provided matchers.
+func (s *seriesStripe) appendActiveMatching(series []labels.Labels, keepUntilNanos int64, matchers labelsMatchers) []labels.Labels {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, entries := range s.refs {
+		for _, entry := range entries {
+			if entry.nanos.Load() < keepUntilNanos || !matchers.Matches(entry.lbs) {
+				continue
+			}
+			series = append(series, entry.lbs)
+		}
+	}
+
+	return series
+}
+
 // getTotalAndUpdateMatching will return the total active series in the stripe and also update the slice provided
 // with each matcher's total.
 func (s *seriesStripe) getTotalAndUpdateMatching(matching []int) int {
@@ -88,6 +88,26 @@ func TestActiveSeries_UpdateSeries_WithMatchers(t *testing.T) {
 	assert.True(t, valid)
 }
 
+func TestActiveSeries_ActiveWithMatchers(t *testing.T) {
+	ls1 := labels.FromStrings("a", "1", "b", "x")
+	ls2 := labels.FromStrings("a", "2", "b", "x")
+	ls3 := labels.FromStrings("a", "3", "b", "y")
+
+	now := time.Now()
+	c := NewActiveSeries(&Matchers{}, DefaultTimeout)
+	assert.Empty(t, c.ActiveWithMatchers(now, nil))
+
+	c.UpdateSeries(ls1, now.Add(-2*DefaultTimeout), copyFn)
+	c.UpdateSeries(ls2, now, copyFn)
+	c.UpdateSeries(ls3, now, copyFn)
+
+	// The first series isn't active anymore, even if it hasn't been purged yet.
+	assert.ElementsMatch(t, []labels.Labels{ls2, ls3}, c.ActiveWithMatchers(now, nil))
+	assert.ElementsMatch(t, []labels.Labels{ls2}, c.ActiveWithMatchers(now, []*labels.Matcher{labels.MustNewMatcher(labels.MatchEqual, "b", "x")}))
+	assert.ElementsMatch(t, []labels.Labels{ls1, ls2}, c.ActiveWithMatchers(now.Add(-2*DefaultTimeout), []*labels.Matcher{labels.MustNewMatcher(labels.MatchEqual, "b", "x")}))
+	assert.Empty(t, c.ActiveWithMatchers(now, []*labels.Matcher{labels.MustNewMatcher(labels.MatchEqual, "b", "z")}))
+}
+
 func TestActiveSeries_ShouldCorrectlyHandleFingerprintCollisions(t *testing.T) {
 	metric := labels.NewBuilder(labels.FromStrings("__name__", "logs"))
 	ls1 := metric.Set("_", "ypfajYg2lsv").Labels()
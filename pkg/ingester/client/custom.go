@@ -5,6 +5,8 @@
 
 package client
 
+import "sort"
+
 // ChunksCount returns the number of chunks in response.
 func (m *QueryStreamResponse) ChunksCount() int {
 	if len(m.Chunkseries) == 0 {
@@ -32,3 +34,19 @@ func (m *QueryStreamResponse) ChunksSize() int {
 	}
 	return size
 }
+
+// TopTSDBStatisticItems sorts the items in descending order by value and ascending order by name,
+// and returns the first `limit` ones.
+func TopTSDBStatisticItems(items []TSDBStatisticItem, limit int) []TSDBStatisticItem {
+	sort.Slice(items, func(i, j int) bool {
+		if items[i].Value != items[j].Value {
+			return items[i].Value > items[j].Value
+		}
+		return items[i].Name < items[j].Name
+	})
+
+	if len(items) > limit {
+		items = items[:limit]
+	}
+	return items
+}
@@ -82,7 +82,7 @@ var ReadRequest_ResponseType_value = map[string]int32{
 }
 
 func (ReadRequest_ResponseType) EnumDescriptor() ([]byte, []int) {
-	return fileDescriptor_60f6df4f3586b478, []int{6, 0}
+	return fileDescriptor_60f6df4f3586b478, []int{11, 0}
 }
 
 type StreamChunk_Encoding int32
@@ -103,7 +103,7 @@ var StreamChunk_Encoding_value = map[string]int32{
 }
 
 func (StreamChunk_Encoding) EnumDescriptor() ([]byte, []int) {
-	return fileDescriptor_60f6df4f3586b478, []int{10, 0}
+	return fileDescriptor_60f6df4f3586b478, []int{15, 0}
 }
 
 type LabelNamesAndValuesRequest struct {
@@ -388,6 +388,295 @@ func (m *LabelValueSeriesCount) GetLabelValueSeries() map[string]uint64 {
 	return nil
 }
 
+type TSDBStatusRequest struct {
+	// The maximum number of items returned in each list of statistics.
+	Limit int32 `protobuf:"varint,1,opt,name=limit,proto3" json:"limit,omitempty"`
+}
+
+func (m *TSDBStatusRequest) Reset()      { *m = TSDBStatusRequest{} }
+func (*TSDBStatusRequest) ProtoMessage() {}
+func (*TSDBStatusRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_60f6df4f3586b478, []int{6}
+}
+func (m *TSDBStatusRequest) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *TSDBStatusRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_TSDBStatusRequest.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *TSDBStatusRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_TSDBStatusRequest.Merge(m, src)
+}
+func (m *TSDBStatusRequest) XXX_Size() int {
+	return m.Size()
+}
+func (m *TSDBStatusRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_TSDBStatusRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_TSDBStatusRequest proto.InternalMessageInfo
+
+func (m *TSDBStatusRequest) GetLimit() int32 {
+	if m != nil {
+		return m.Limit
+	}
+	return 0
+}
+
+type TSDBStatusResponse struct {
+	NumSeries     uint64 `protobuf:"varint,1,opt,name=num_series,json=numSeries,proto3" json:"num_series,omitempty"`
+	NumLabelPairs uint64 `protobuf:"varint,2,opt,name=num_label_pairs,json=numLabelPairs,proto3" json:"num_label_pairs,omitempty"`
+	ChunkCount    uint64 `protobuf:"varint,3,opt,name=chunk_count,json=chunkCount,proto3" json:"chunk_count,omitempty"`
+	MinTime       int64  `protobuf:"varint,4,opt,name=min_time,json=minTime,proto3" json:"min_time,omitempty"`
+	MaxTime       int64  `protobuf:"varint,5,opt,name=max_time,json=maxTime,proto3" json:"max_time,omitempty"`
+	// The lists of statistics are sorted by value in descending order.
+	SeriesCountByMetricName     []TSDBStatisticItem `protobuf:"bytes,6,rep,name=series_count_by_metric_name,json=seriesCountByMetricName,proto3" json:"series_count_by_metric_name"`
+	LabelValueCountByLabelName  []TSDBStatisticItem `protobuf:"bytes,7,rep,name=label_value_count_by_label_name,json=labelValueCountByLabelName,proto3" json:"label_value_count_by_label_name"`
+	MemoryInBytesByLabelName    []TSDBStatisticItem `protobuf:"bytes,8,rep,name=memory_in_bytes_by_label_name,json=memoryInBytesByLabelName,proto3" json:"memory_in_bytes_by_label_name"`
+	SeriesCountByLabelValuePair []TSDBStatisticItem `protobuf:"bytes,9,rep,name=series_count_by_label_value_pair,json=seriesCountByLabelValuePair,proto3" json:"series_count_by_label_value_pair"`
+}
+
+func (m *TSDBStatusResponse) Reset()      { *m = TSDBStatusResponse{} }
+func (*TSDBStatusResponse) ProtoMessage() {}
+func (*TSDBStatusResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_60f6df4f3586b478, []int{7}
+}
+func (m *TSDBStatusResponse) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *TSDBStatusResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_TSDBStatusResponse.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *TSDBStatusResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_TSDBStatusResponse.Merge(m, src)
+}
+func (m *TSDBStatusResponse) XXX_Size() int {
+	return m.Size()
+}
+func (m *TSDBStatusResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_TSDBStatusResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_TSDBStatusResponse proto.InternalMessageInfo
+
+func (m *TSDBStatusResponse) GetNumSeries() uint64 {
+	if m != nil {
+		return m.NumSeries
+	}
+	return 0
+}
+
+func (m *TSDBStatusResponse) GetNumLabelPairs() uint64 {
+	if m != nil {
+		return m.NumLabelPairs
+	}
+	return 0
+}
+
+func (m *TSDBStatusResponse) GetChunkCount() uint64 {
+	if m != nil {
+		return m.ChunkCount
+	}
+	return 0
+}
+
+func (m *TSDBStatusResponse) GetMinTime() int64 {
+	if m != nil {
+		return m.MinTime
+	}
+	return 0
+}
+
+func (m *TSDBStatusResponse) GetMaxTime() int64 {
+	if m != nil {
+		return m.MaxTime
+	}
+	return 0
+}
+
+func (m *TSDBStatusResponse) GetSeriesCountByMetricName() []TSDBStatisticItem {
+	if m != nil {
+		return m.SeriesCountByMetricName
+	}
+	return nil
+}
+
+func (m *TSDBStatusResponse) GetLabelValueCountByLabelName() []TSDBStatisticItem {
+	if m != nil {
+		return m.LabelValueCountByLabelName
+	}
+	return nil
+}
+
+func (m *TSDBStatusResponse) GetMemoryInBytesByLabelName() []TSDBStatisticItem {
+	if m != nil {
+		return m.MemoryInBytesByLabelName
+	}
+	return nil
+}
+
+func (m *TSDBStatusResponse) GetSeriesCountByLabelValuePair() []TSDBStatisticItem {
+	if m != nil {
+		return m.SeriesCountByLabelValuePair
+	}
+	return nil
+}
+
+type TSDBStatisticItem struct {
+	Name  string `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Value uint64 `protobuf:"varint,2,opt,name=value,proto3" json:"value,omitempty"`
+}
+
+func (m *TSDBStatisticItem) Reset()      { *m = TSDBStatisticItem{} }
+func (*TSDBStatisticItem) ProtoMessage() {}
+func (*TSDBStatisticItem) Descriptor() ([]byte, []int) {
+	return fileDescriptor_60f6df4f3586b478, []int{8}
+}
+func (m *TSDBStatisticItem) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *TSDBStatisticItem) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_TSDBStatisticItem.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *TSDBStatisticItem) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_TSDBStatisticItem.Merge(m, src)
+}
+func (m *TSDBStatisticItem) XXX_Size() int {
+	return m.Size()
+}
+func (m *TSDBStatisticItem) XXX_DiscardUnknown() {
+	xxx_messageInfo_TSDBStatisticItem.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_TSDBStatisticItem proto.InternalMessageInfo
+
+func (m *TSDBStatisticItem) GetName() string {
+	if m != nil {
+		return m.Name
+	}
+	return ""
+}
+
+func (m *TSDBStatisticItem) GetValue() uint64 {
+	if m != nil {
+		return m.Value
+	}
+	return 0
+}
+
+type ActiveSeriesRequest struct {
+	Matchers []*LabelMatcher `protobuf:"bytes,1,rep,name=matchers,proto3" json:"matchers,omitempty"`
+}
+
+func (m *ActiveSeriesRequest) Reset()      { *m = ActiveSeriesRequest{} }
+func (*ActiveSeriesRequest) ProtoMessage() {}
+func (*ActiveSeriesRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_60f6df4f3586b478, []int{9}
+}
+func (m *ActiveSeriesRequest) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *ActiveSeriesRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_ActiveSeriesRequest.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *ActiveSeriesRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ActiveSeriesRequest.Merge(m, src)
+}
+func (m *ActiveSeriesRequest) XXX_Size() int {
+	return m.Size()
+}
+func (m *ActiveSeriesRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_ActiveSeriesRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ActiveSeriesRequest proto.InternalMessageInfo
+
+func (m *ActiveSeriesRequest) GetMatchers() []*LabelMatcher {
+	if m != nil {
+		return m.Matchers
+	}
+	return nil
+}
+
+type ActiveSeriesResponse struct {
+	Metric []*mimirpb.Metric `protobuf:"bytes,1,rep,name=metric,proto3" json:"metric,omitempty"`
+}
+
+func (m *ActiveSeriesResponse) Reset()      { *m = ActiveSeriesResponse{} }
+func (*ActiveSeriesResponse) ProtoMessage() {}
+func (*ActiveSeriesResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_60f6df4f3586b478, []int{10}
+}
+func (m *ActiveSeriesResponse) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *ActiveSeriesResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_ActiveSeriesResponse.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *ActiveSeriesResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ActiveSeriesResponse.Merge(m, src)
+}
+func (m *ActiveSeriesResponse) XXX_Size() int {
+	return m.Size()
+}
+func (m *ActiveSeriesResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_ActiveSeriesResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ActiveSeriesResponse proto.InternalMessageInfo
+
+func (m *ActiveSeriesResponse) GetMetric() []*mimirpb.Metric {
+	if m != nil {
+		return m.Metric
+	}
+	return nil
+}
+
 type ReadRequest struct {
 	Queries               []*QueryRequest            `protobuf:"bytes,1,rep,name=queries,proto3" json:"queries,omitempty"`
 	AcceptedResponseTypes []ReadRequest_ResponseType `protobuf:"varint,2,rep,packed,name=accepted_response_types,json=acceptedResponseTypes,proto3,enum=cortex.ReadRequest_ResponseType" json:"accepted_response_types,omitempty"`
@@ -396,7 +685,7 @@ type ReadRequest struct {
 func (m *ReadRequest) Reset()      { *m = ReadRequest{} }
 func (*ReadRequest) ProtoMessage() {}
 func (*ReadRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_60f6df4f3586b478, []int{6}
+	return fileDescriptor_60f6df4f3586b478, []int{11}
 }
 func (m *ReadRequest) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -446,7 +735,7 @@ type ReadResponse struct {
 func (m *ReadResponse) Reset()      { *m = ReadResponse{} }
 func (*ReadResponse) ProtoMessage() {}
 func (*ReadResponse) Descriptor() ([]byte, []int) {
-	return fileDescriptor_60f6df4f3586b478, []int{7}
+	return fileDescriptor_60f6df4f3586b478, []int{12}
 }
 func (m *ReadResponse) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -490,7 +779,7 @@ type StreamReadResponse struct {
 func (m *StreamReadResponse) Reset()      { *m = StreamReadResponse{} }
 func (*StreamReadResponse) ProtoMessage() {}
 func (*StreamReadResponse) Descriptor() ([]byte, []int) {
-	return fileDescriptor_60f6df4f3586b478, []int{8}
+	return fileDescriptor_60f6df4f3586b478, []int{13}
 }
 func (m *StreamReadResponse) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -541,7 +830,7 @@ type StreamChunkedSeries struct {
 func (m *StreamChunkedSeries) Reset()      { *m = StreamChunkedSeries{} }
 func (*StreamChunkedSeries) ProtoMessage() {}
 func (*StreamChunkedSeries) Descriptor() ([]byte, []int) {
-	return fileDescriptor_60f6df4f3586b478, []int{9}
+	return fileDescriptor_60f6df4f3586b478, []int{14}
 }
 func (m *StreamChunkedSeries) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -587,7 +876,7 @@ type StreamChunk struct {
 func (m *StreamChunk) Reset()      { *m = StreamChunk{} }
 func (*StreamChunk) ProtoMessage() {}
 func (*StreamChunk) Descriptor() ([]byte, []int) {
-	return fileDescriptor_60f6df4f3586b478, []int{10}
+	return fileDescriptor_60f6df4f3586b478, []int{15}
 }
 func (m *StreamChunk) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -653,7 +942,7 @@ type QueryRequest struct {
 func (m *QueryRequest) Reset()      { *m = QueryRequest{} }
 func (*QueryRequest) ProtoMessage() {}
 func (*QueryRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_60f6df4f3586b478, []int{11}
+	return fileDescriptor_60f6df4f3586b478, []int{16}
 }
 func (m *QueryRequest) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -712,7 +1001,7 @@ type ExemplarQueryRequest struct {
 func (m *ExemplarQueryRequest) Reset()      { *m = ExemplarQueryRequest{} }
 func (*ExemplarQueryRequest) ProtoMessage() {}
 func (*ExemplarQueryRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_60f6df4f3586b478, []int{12}
+	return fileDescriptor_60f6df4f3586b478, []int{17}
 }
 func (m *ExemplarQueryRequest) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -769,7 +1058,7 @@ type QueryResponse struct {
 func (m *QueryResponse) Reset()      { *m = QueryResponse{} }
 func (*QueryResponse) ProtoMessage() {}
 func (*QueryResponse) Descriptor() ([]byte, []int) {
-	return fileDescriptor_60f6df4f3586b478, []int{13}
+	return fileDescriptor_60f6df4f3586b478, []int{18}
 }
 func (m *QueryResponse) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -814,7 +1103,7 @@ type QueryStreamResponse struct {
 func (m *QueryStreamResponse) Reset()      { *m = QueryStreamResponse{} }
 func (*QueryStreamResponse) ProtoMessage() {}
 func (*QueryStreamResponse) Descriptor() ([]byte, []int) {
-	return fileDescriptor_60f6df4f3586b478, []int{14}
+	return fileDescriptor_60f6df4f3586b478, []int{19}
 }
 func (m *QueryStreamResponse) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -864,7 +1153,7 @@ type ExemplarQueryResponse struct {
 func (m *ExemplarQueryResponse) Reset()      { *m = ExemplarQueryResponse{} }
 func (*ExemplarQueryResponse) ProtoMessage() {}
 func (*ExemplarQueryResponse) Descriptor() ([]byte, []int) {
-	return fileDescriptor_60f6df4f3586b478, []int{15}
+	return fileDescriptor_60f6df4f3586b478, []int{20}
 }
 func (m *ExemplarQueryResponse) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -910,7 +1199,7 @@ type LabelValuesRequest struct {
 func (m *LabelValuesRequest) Reset()      { *m = LabelValuesRequest{} }
 func (*LabelValuesRequest) ProtoMessage() {}
 func (*LabelValuesRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_60f6df4f3586b478, []int{16}
+	return fileDescriptor_60f6df4f3586b478, []int{21}
 }
 func (m *LabelValuesRequest) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -974,7 +1263,7 @@ type LabelValuesResponse struct {
 func (m *LabelValuesResponse) Reset()      { *m = LabelValuesResponse{} }
 func (*LabelValuesResponse) ProtoMessage() {}
 func (*LabelValuesResponse) Descriptor() ([]byte, []int) {
-	return fileDescriptor_60f6df4f3586b478, []int{17}
+	return fileDescriptor_60f6df4f3586b478, []int{22}
 }
 func (m *LabelValuesResponse) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -1019,7 +1308,7 @@ type LabelNamesRequest struct {
 func (m *LabelNamesRequest) Reset()      { *m = LabelNamesRequest{} }
 func (*LabelNamesRequest) ProtoMessage() {}
 func (*LabelNamesRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_60f6df4f3586b478, []int{18}
+	return fileDescriptor_60f6df4f3586b478, []int{23}
 }
 func (m *LabelNamesRequest) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -1076,7 +1365,7 @@ type LabelNamesResponse struct {
 func (m *LabelNamesResponse) Reset()      { *m = LabelNamesResponse{} }
 func (*LabelNamesResponse) ProtoMessage() {}
 func (*LabelNamesResponse) Descriptor() ([]byte, []int) {
-	return fileDescriptor_60f6df4f3586b478, []int{19}
+	return fileDescriptor_60f6df4f3586b478, []int{24}
 }
 func (m *LabelNamesResponse) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -1118,7 +1407,7 @@ type UserStatsRequest struct {
 func (m *UserStatsRequest) Reset()      { *m = UserStatsRequest{} }
 func (*UserStatsRequest) ProtoMessage() {}
 func (*UserStatsRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_60f6df4f3586b478, []int{20}
+	return fileDescriptor_60f6df4f3586b478, []int{25}
 }
 func (m *UserStatsRequest) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -1157,7 +1446,7 @@ type UserStatsResponse struct {
 func (m *UserStatsResponse) Reset()      { *m = UserStatsResponse{} }
 func (*UserStatsResponse) ProtoMessage() {}
 func (*UserStatsResponse) Descriptor() ([]byte, []int) {
-	return fileDescriptor_60f6df4f3586b478, []int{21}
+	return fileDescriptor_60f6df4f3586b478, []int{26}
 }
 func (m *UserStatsResponse) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -1222,7 +1511,7 @@ type UserIDStatsResponse struct {
 func (m *UserIDStatsResponse) Reset()      { *m = UserIDStatsResponse{} }
 func (*UserIDStatsResponse) ProtoMessage() {}
 func (*UserIDStatsResponse) Descriptor() ([]byte, []int) {
-	return fileDescriptor_60f6df4f3586b478, []int{22}
+	return fileDescriptor_60f6df4f3586b478, []int{27}
 }
 func (m *UserIDStatsResponse) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -1272,7 +1561,7 @@ type UsersStatsResponse struct {
 func (m *UsersStatsResponse) Reset()      { *m = UsersStatsResponse{} }
 func (*UsersStatsResponse) ProtoMessage() {}
 func (*UsersStatsResponse) Descriptor() ([]byte, []int) {
-	return fileDescriptor_60f6df4f3586b478, []int{23}
+	return fileDescriptor_60f6df4f3586b478, []int{28}
 }
 func (m *UsersStatsResponse) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -1317,7 +1606,7 @@ type MetricsForLabelMatchersRequest struct {
 func (m *MetricsForLabelMatchersRequest) Reset()      { *m = MetricsForLabelMatchersRequest{} }
 func (*MetricsForLabelMatchersRequest) ProtoMessage() {}
 func (*MetricsForLabelMatchersRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_60f6df4f3586b478, []int{24}
+	return fileDescriptor_60f6df4f3586b478, []int{29}
 }
 func (m *MetricsForLabelMatchersRequest) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -1374,7 +1663,7 @@ type MetricsForLabelMatchersResponse struct {
 func (m *MetricsForLabelMatchersResponse) Reset()      { *m = MetricsForLabelMatchersResponse{} }
 func (*MetricsForLabelMatchersResponse) ProtoMessage() {}
 func (*MetricsForLabelMatchersResponse) Descriptor() ([]byte, []int) {
-	return fileDescriptor_60f6df4f3586b478, []int{25}
+	return fileDescriptor_60f6df4f3586b478, []int{30}
 }
 func (m *MetricsForLabelMatchersResponse) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -1416,7 +1705,7 @@ type MetricsMetadataRequest struct {
 func (m *MetricsMetadataRequest) Reset()      { *m = MetricsMetadataRequest{} }
 func (*MetricsMetadataRequest) ProtoMessage() {}
 func (*MetricsMetadataRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_60f6df4f3586b478, []int{26}
+	return fileDescriptor_60f6df4f3586b478, []int{31}
 }
 func (m *MetricsMetadataRequest) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -1452,7 +1741,7 @@ type MetricsMetadataResponse struct {
 func (m *MetricsMetadataResponse) Reset()      { *m = MetricsMetadataResponse{} }
 func (*MetricsMetadataResponse) ProtoMessage() {}
 func (*MetricsMetadataResponse) Descriptor() ([]byte, []int) {
-	return fileDescriptor_60f6df4f3586b478, []int{27}
+	return fileDescriptor_60f6df4f3586b478, []int{32}
 }
 func (m *MetricsMetadataResponse) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -1498,7 +1787,7 @@ type TimeSeriesChunk struct {
 func (m *TimeSeriesChunk) Reset()      { *m = TimeSeriesChunk{} }
 func (*TimeSeriesChunk) ProtoMessage() {}
 func (*TimeSeriesChunk) Descriptor() ([]byte, []int) {
-	return fileDescriptor_60f6df4f3586b478, []int{28}
+	return fileDescriptor_60f6df4f3586b478, []int{33}
 }
 func (m *TimeSeriesChunk) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -1558,7 +1847,7 @@ type Chunk struct {
 func (m *Chunk) Reset()      { *m = Chunk{} }
 func (*Chunk) ProtoMessage() {}
 func (*Chunk) Descriptor() ([]byte, []int) {
-	return fileDescriptor_60f6df4f3586b478, []int{29}
+	return fileDescriptor_60f6df4f3586b478, []int{34}
 }
 func (m *Chunk) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -1622,7 +1911,7 @@ type LabelMatchers struct {
 func (m *LabelMatchers) Reset()      { *m = LabelMatchers{} }
 func (*LabelMatchers) ProtoMessage() {}
 func (*LabelMatchers) Descriptor() ([]byte, []int) {
-	return fileDescriptor_60f6df4f3586b478, []int{30}
+	return fileDescriptor_60f6df4f3586b478, []int{35}
 }
 func (m *LabelMatchers) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -1667,7 +1956,7 @@ type LabelMatcher struct {
 func (m *LabelMatcher) Reset()      { *m = LabelMatcher{} }
 func (*LabelMatcher) ProtoMessage() {}
 func (*LabelMatcher) Descriptor() ([]byte, []int) {
-	return fileDescriptor_60f6df4f3586b478, []int{31}
+	return fileDescriptor_60f6df4f3586b478, []int{36}
 }
 func (m *LabelMatcher) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -1727,7 +2016,7 @@ type TimeSeriesFile struct {
 func (m *TimeSeriesFile) Reset()      { *m = TimeSeriesFile{} }
 func (*TimeSeriesFile) ProtoMessage() {}
 func (*TimeSeriesFile) Descriptor() ([]byte, []int) {
-	return fileDescriptor_60f6df4f3586b478, []int{32}
+	return fileDescriptor_60f6df4f3586b478, []int{37}
 }
 func (m *TimeSeriesFile) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -1795,6 +2084,11 @@ func init() {
 	proto.RegisterType((*LabelValuesCardinalityResponse)(nil), "cortex.LabelValuesCardinalityResponse")
 	proto.RegisterType((*LabelValueSeriesCount)(nil), "cortex.LabelValueSeriesCount")
 	proto.RegisterMapType((map[string]uint64)(nil), "cortex.LabelValueSeriesCount.LabelValueSeriesEntry")
+	proto.RegisterType((*TSDBStatusRequest)(nil), "cortex.TSDBStatusRequest")
+	proto.RegisterType((*TSDBStatusResponse)(nil), "cortex.TSDBStatusResponse")
+	proto.RegisterType((*TSDBStatisticItem)(nil), "cortex.TSDBStatisticItem")
+	proto.RegisterType((*ActiveSeriesRequest)(nil), "cortex.ActiveSeriesRequest")
+	proto.RegisterType((*ActiveSeriesResponse)(nil), "cortex.ActiveSeriesResponse")
 	proto.RegisterType((*ReadRequest)(nil), "cortex.ReadRequest")
 	proto.RegisterType((*ReadResponse)(nil), "cortex.ReadResponse")
 	proto.RegisterType((*StreamReadResponse)(nil), "cortex.StreamReadResponse")
@@ -1827,110 +2121,127 @@ func init() {
 func init() { proto.RegisterFile("ingester.proto", fileDescriptor_60f6df4f3586b478) }
 
 var fileDescriptor_60f6df4f3586b478 = []byte{
-	// 1640 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xbc, 0x58, 0xcd, 0x6f, 0xdb, 0x46,
-	0x16, 0xd7, 0x48, 0xb2, 0x6c, 0x3d, 0xd9, 0x8a, 0x3c, 0x8a, 0x6d, 0x85, 0x59, 0xd3, 0x5a, 0x2e,
-	0x92, 0xd5, 0xee, 0x26, 0xf2, 0x47, 0xb2, 0x40, 0x12, 0x14, 0x08, 0x64, 0x5b, 0x89, 0x5d, 0x47,
-	0x72, 0x42, 0xd9, 0x8d, 0x51, 0xa0, 0x20, 0x28, 0x69, 0x6c, 0x13, 0x16, 0x29, 0x85, 0xa4, 0x0a,
-	0xfb, 0x56, 0xa0, 0xf7, 0xb6, 0xe8, 0xa9, 0xa7, 0x02, 0xbd, 0xf5, 0x58, 0x14, 0x28, 0x7a, 0xeb,
-	0x39, 0x97, 0x02, 0x39, 0x06, 0x3d, 0x04, 0x8d, 0x73, 0x69, 0x6f, 0xf9, 0x13, 0x0a, 0xce, 0x0c,
-	0x29, 0x92, 0xa2, 0x3f, 0x52, 0x24, 0x39, 0x49, 0xf3, 0xde, 0x6f, 0x7e, 0xf3, 0xbe, 0x38, 0xef,
-	0x91, 0x90, 0xd5, 0x8c, 0x3d, 0x62, 0xd9, 0xc4, 0x2c, 0xf7, 0xcc, 0xae, 0xdd, 0xc5, 0xa9, 0x56,
-	0xd7, 0xb4, 0xc9, 0xa1, 0x70, 0x7d, 0x4f, 0xb3, 0xf7, 0xfb, 0xcd, 0x72, 0xab, 0xab, 0xcf, 0xef,
-	0x75, 0xf7, 0xba, 0xf3, 0x54, 0xdd, 0xec, 0xef, 0xd2, 0x15, 0x5d, 0xd0, 0x7f, 0x6c, 0x9b, 0xb0,
-	0xe0, 0x87, 0x9b, 0xea, 0xae, 0x6a, 0xa8, 0xf3, 0xba, 0xa6, 0x6b, 0xe6, 0x7c, 0xef, 0x60, 0x8f,
-	0xfd, 0xeb, 0x35, 0xd9, 0x2f, 0xdb, 0x21, 0xd5, 0x41, 0x78, 0xa0, 0x36, 0x49, 0xa7, 0xae, 0xea,
-	0xc4, 0xaa, 0x18, 0xed, 0x8f, 0xd4, 0x4e, 0x9f, 0x58, 0x32, 0x79, 0xd2, 0x27, 0x96, 0x8d, 0x17,
-	0x60, 0x4c, 0x57, 0xed, 0xd6, 0x3e, 0x31, 0xad, 0x02, 0x2a, 0x26, 0x4a, 0x99, 0xa5, 0x8b, 0x65,
-	0x66, 0x59, 0x99, 0xee, 0xaa, 0x31, 0xa5, 0xec, 0xa1, 0xa4, 0x35, 0xb8, 0x1c, 0xc9, 0x67, 0xf5,
-	0xba, 0x86, 0x45, 0xf0, 0x7f, 0x60, 0x44, 0xb3, 0x89, 0xee, 0xb2, 0xe5, 0x03, 0x6c, 0x1c, 0xcb,
-	0x10, 0xd2, 0x2a, 0x64, 0x7c, 0x52, 0x3c, 0x0b, 0xd0, 0x71, 0x96, 0x8a, 0xa1, 0xea, 0xa4, 0x80,
-	0x8a, 0xa8, 0x94, 0x96, 0xd3, 0x1d, 0xf7, 0x28, 0x3c, 0x0d, 0xa9, 0x4f, 0x29, 0xb0, 0x10, 0x2f,
-	0x26, 0x4a, 0x69, 0x99, 0xaf, 0x24, 0x13, 0x66, 0x7d, 0x2c, 0x2b, 0xaa, 0xd9, 0xd6, 0x0c, 0xb5,
-	0xa3, 0xd9, 0x47, 0xae, 0x8b, 0x73, 0x90, 0x19, 0xf0, 0x32, 0xbb, 0xd2, 0x32, 0x78, 0xc4, 0x56,
-	0x20, 0x06, 0xf1, 0x73, 0xc5, 0x60, 0x1b, 0xc4, 0x93, 0xce, 0xe4, 0x61, 0xb8, 0x11, 0x0c, 0xc3,
-	0xec, 0x70, 0x18, 0x1a, 0xc4, 0xd4, 0x88, 0xb5, 0xd2, 0xed, 0x1b, 0xb6, 0x1b, 0x90, 0x17, 0x08,
-	0xa6, 0x22, 0x01, 0x67, 0xc5, 0x46, 0x05, 0xcc, 0xd4, 0x34, 0x26, 0x8a, 0x45, 0x77, 0x72, 0x5f,
-	0x6e, 0x9c, 0x7a, 0xf4, 0x90, 0xb4, 0x6a, 0xd8, 0xe6, 0x91, 0x9c, 0xeb, 0x84, 0xc4, 0xc2, 0xca,
-	0xb0, 0x69, 0x14, 0x8a, 0x73, 0x90, 0x38, 0x20, 0x47, 0xdc, 0x26, 0xe7, 0x2f, 0xbe, 0x08, 0x23,
-	0xd4, 0x8e, 0x42, 0xbc, 0x88, 0x4a, 0x49, 0x99, 0x2d, 0xee, 0xc4, 0x6f, 0x21, 0xe9, 0x57, 0x04,
-	0x19, 0x99, 0xa8, 0x6d, 0x37, 0x35, 0x65, 0x18, 0x7d, 0xd2, 0x67, 0xc6, 0x86, 0x8a, 0xef, 0x51,
-	0x9f, 0x98, 0x6e, 0x06, 0x65, 0x17, 0x84, 0x77, 0x60, 0x46, 0x6d, 0xb5, 0x48, 0xcf, 0x26, 0x6d,
-	0xc5, 0xe4, 0xa1, 0x56, 0xec, 0xa3, 0x1e, 0x77, 0x36, 0xbb, 0x54, 0x74, 0xf7, 0xfb, 0x4e, 0x29,
-	0xbb, 0x49, 0xd9, 0x3a, 0xea, 0x11, 0x79, 0xca, 0x25, 0xf0, 0x4b, 0x2d, 0xe9, 0x26, 0x8c, 0xfb,
-	0x05, 0x38, 0x03, 0xa3, 0x8d, 0x4a, 0xed, 0xe1, 0x83, 0x6a, 0x23, 0x17, 0xc3, 0x33, 0x90, 0x6f,
-	0x6c, 0xc9, 0xd5, 0x4a, 0xad, 0xba, 0xaa, 0xec, 0x6c, 0xca, 0xca, 0xca, 0xda, 0x76, 0x7d, 0xa3,
-	0x91, 0x43, 0xd2, 0x5d, 0x67, 0x97, 0xea, 0x51, 0xe1, 0x79, 0x18, 0x35, 0x89, 0xd5, 0xef, 0xd8,
-	0xae, 0x3f, 0x53, 0x21, 0x7f, 0x18, 0x4e, 0x76, 0x51, 0xd2, 0x11, 0xe0, 0x86, 0x6d, 0x12, 0x55,
-	0x0f, 0xd0, 0x2c, 0x43, 0xb6, 0xb5, 0xdf, 0x37, 0x0e, 0x48, 0xdb, 0x4d, 0x25, 0x63, 0xbb, 0xec,
-	0xb2, 0xb1, 0x3d, 0x2b, 0x0c, 0xc3, 0x92, 0x21, 0x4f, 0xb4, 0xfc, 0x4b, 0xa7, 0xea, 0x9d, 0xa8,
-	0x1d, 0x29, 0x9a, 0xd1, 0x26, 0x87, 0x34, 0x15, 0x09, 0x19, 0xa8, 0x68, 0xdd, 0x91, 0x48, 0x3f,
-	0x20, 0xc8, 0x47, 0xf0, 0xe0, 0x5d, 0x48, 0xd1, 0xe4, 0x87, 0x9f, 0xe0, 0x5e, 0x93, 0xd5, 0xca,
-	0x43, 0x55, 0x33, 0x97, 0x6f, 0x3f, 0x7d, 0x31, 0x17, 0xfb, 0xed, 0xc5, 0xdc, 0xe2, 0x79, 0xae,
-	0x23, 0xb6, 0xaf, 0xd2, 0x56, 0x7b, 0x36, 0x31, 0x65, 0xce, 0x8e, 0x17, 0x21, 0x45, 0x2d, 0x76,
-	0xeb, 0x34, 0x1f, 0xe1, 0xdc, 0x72, 0xd2, 0x39, 0x47, 0xe6, 0x40, 0xe9, 0x27, 0x04, 0x19, 0x9f,
-	0x16, 0x8b, 0x90, 0xd1, 0x35, 0x43, 0xb1, 0x35, 0x9d, 0x28, 0xf4, 0x51, 0x73, 0x7c, 0x4c, 0xeb,
-	0x9a, 0xb1, 0xa5, 0xe9, 0xa4, 0x66, 0x51, 0xbd, 0x7a, 0xe8, 0xe9, 0xe3, 0x5c, 0xaf, 0x1e, 0x72,
-	0xfd, 0x02, 0x24, 0x9d, 0xe2, 0x29, 0x24, 0x8a, 0xa8, 0x94, 0x5d, 0xfa, 0x47, 0x84, 0x01, 0xe5,
-	0xaa, 0xd1, 0xea, 0xb6, 0x35, 0x63, 0x4f, 0xa6, 0x48, 0x8c, 0x21, 0xd9, 0x56, 0x6d, 0xb5, 0x90,
-	0x2c, 0xa2, 0xd2, 0xb8, 0x4c, 0xff, 0x4b, 0x45, 0x18, 0x73, 0x51, 0x4e, 0xd9, 0x6c, 0xd7, 0x37,
-	0xea, 0x9b, 0x8f, 0xeb, 0xb9, 0x18, 0x1e, 0x85, 0xc4, 0xce, 0xa6, 0x9c, 0x43, 0xd2, 0x37, 0x08,
-	0xc6, 0xfd, 0x05, 0x8d, 0xaf, 0x01, 0xb6, 0x6c, 0xd5, 0xb4, 0xa9, 0x69, 0x96, 0xad, 0xea, 0xbd,
-	0x81, 0xfd, 0x39, 0xaa, 0xd9, 0x72, 0x15, 0x35, 0x0b, 0x97, 0x20, 0x47, 0x8c, 0x76, 0x10, 0xcb,
-	0x7c, 0xc9, 0x12, 0xa3, 0xed, 0x47, 0xfa, 0x6f, 0xb2, 0xc4, 0xb9, 0x6e, 0xb2, 0xef, 0x10, 0x5c,
-	0xac, 0x1e, 0x12, 0xbd, 0xd7, 0x51, 0xcd, 0xf7, 0x62, 0xe2, 0xe2, 0x90, 0x89, 0x53, 0x51, 0x26,
-	0x5a, 0x3e, 0x1b, 0x37, 0x60, 0x22, 0xf0, 0xf8, 0xe0, 0x3b, 0x00, 0xf4, 0xa4, 0xa8, 0x9b, 0xa3,
-	0xd7, 0x2c, 0x3b, 0xc7, 0xb1, 0x62, 0xe6, 0xf5, 0xe3, 0x43, 0x4b, 0x5f, 0x23, 0xc8, 0x53, 0x36,
-	0xf7, 0xb9, 0xe3, 0x9c, 0x77, 0x21, 0xc3, 0xaa, 0xcc, 0x4f, 0x3a, 0xe3, 0x9a, 0x36, 0xa0, 0xf4,
-	0xd7, 0xa5, 0x7f, 0x47, 0xc8, 0xa8, 0xf8, 0x1b, 0x19, 0xd5, 0x80, 0xa9, 0x50, 0x12, 0xde, 0x82,
-	0xa7, 0xbf, 0x20, 0xc0, 0xfe, 0xae, 0xcb, 0x13, 0x7b, 0x46, 0x2b, 0x89, 0xce, 0x7b, 0xfc, 0x0d,
-	0xf2, 0x9e, 0x38, 0x33, 0xef, 0xce, 0xd3, 0x73, 0x8e, 0xbc, 0xdf, 0x82, 0x7c, 0xc0, 0x7e, 0x1e,
-	0x93, 0x7f, 0xc2, 0xb8, 0xaf, 0xd9, 0xb9, 0x0d, 0x3d, 0x33, 0xe8, 0x58, 0x96, 0xf4, 0x2d, 0x82,
-	0xc9, 0xc1, 0x90, 0xf2, 0x7e, 0x4b, 0xfa, 0x5c, 0xae, 0xfd, 0x9f, 0xa7, 0x86, 0xdb, 0xc7, 0x3d,
-	0x3b, 0x6b, 0x52, 0x91, 0x30, 0xe4, 0xb6, 0x2d, 0x62, 0x36, 0x6c, 0xd5, 0x76, 0xbd, 0x92, 0x7e,
-	0x46, 0x30, 0xe9, 0x13, 0x72, 0xaa, 0x2b, 0xee, 0xc0, 0xa9, 0x75, 0x0d, 0xc5, 0x54, 0x6d, 0x96,
-	0x69, 0x24, 0x4f, 0x78, 0x52, 0x59, 0xb5, 0x89, 0x53, 0x0c, 0x46, 0x5f, 0x1f, 0x0c, 0x0c, 0x4e,
-	0xbf, 0x4e, 0x1b, 0x7d, 0x9d, 0xf7, 0x82, 0x6b, 0x80, 0xd5, 0x9e, 0xa6, 0x84, 0x98, 0x12, 0x94,
-	0x29, 0xa7, 0xf6, 0xb4, 0xf5, 0x00, 0x59, 0x19, 0xf2, 0x66, 0xbf, 0x43, 0xc2, 0xf0, 0x24, 0x85,
-	0x4f, 0x3a, 0xaa, 0x00, 0x5e, 0xfa, 0x04, 0xf2, 0x8e, 0xe1, 0xeb, 0xab, 0x41, 0xd3, 0x67, 0x60,
-	0xb4, 0x6f, 0x11, 0x53, 0xd1, 0xda, 0xbc, 0x3a, 0x53, 0xce, 0x72, 0xbd, 0x8d, 0xaf, 0xf3, 0xcb,
-	0x37, 0x4e, 0x63, 0x7c, 0xc9, 0x8d, 0xf1, 0x90, 0xf3, 0xfc, 0x5e, 0xbe, 0x0f, 0xd8, 0x51, 0x59,
-	0x41, 0xf6, 0x45, 0x18, 0xb1, 0x1c, 0x41, 0xb8, 0xa5, 0x46, 0x58, 0x22, 0x33, 0xa4, 0xf4, 0x23,
-	0x02, 0xb1, 0x46, 0x6c, 0x53, 0x6b, 0x59, 0xf7, 0xba, 0x66, 0x30, 0xa5, 0xef, 0xb8, 0xb4, 0x6e,
-	0xc1, 0xb8, 0x5b, 0x33, 0x8a, 0x45, 0xec, 0xd3, 0x6f, 0xcc, 0x8c, 0x0b, 0x6d, 0x10, 0x5b, 0xda,
-	0x80, 0xb9, 0x13, 0x6d, 0xe6, 0xa1, 0x28, 0x41, 0x4a, 0xa7, 0x10, 0x1e, 0x8b, 0xdc, 0xe0, 0x62,
-	0x61, 0x5b, 0x65, 0xae, 0x97, 0x0a, 0x30, 0xcd, 0xc9, 0x6a, 0xc4, 0x56, 0x9d, 0xe8, 0xba, 0xd5,
-	0xb7, 0x09, 0x33, 0x43, 0x1a, 0x4e, 0x7f, 0x13, 0xc6, 0x74, 0x2e, 0xe3, 0x07, 0x14, 0xc2, 0x07,
-	0x78, 0x7b, 0x3c, 0xa4, 0xf4, 0x27, 0x82, 0x0b, 0xa1, 0xdb, 0xd6, 0x89, 0xd7, 0xae, 0xd9, 0xd5,
-	0x15, 0xf7, 0x15, 0x6a, 0x50, 0x1a, 0x59, 0x47, 0xbe, 0xce, 0xc5, 0xeb, 0x6d, 0x7f, 0xed, 0xc4,
-	0x03, 0xb5, 0x33, 0x98, 0x6a, 0x12, 0xef, 0x74, 0xaa, 0xf9, 0x9f, 0x37, 0xd5, 0x24, 0xe9, 0x39,
-	0x13, 0x6e, 0xaa, 0xa2, 0xe6, 0x99, 0x2f, 0x11, 0x8c, 0x30, 0x0f, 0xdf, 0x55, 0xfd, 0x08, 0x30,
-	0x46, 0xf8, 0x6c, 0x42, 0x1f, 0xdb, 0x11, 0xd9, 0x5b, 0x47, 0xce, 0x32, 0x15, 0x98, 0x08, 0xd4,
-	0xca, 0xdf, 0x78, 0x3f, 0x54, 0x60, 0xdc, 0xaf, 0xc1, 0x57, 0xf8, 0x90, 0x85, 0xe8, 0x90, 0x35,
-	0xe9, 0xee, 0xa6, 0x6a, 0x3a, 0x91, 0x7b, 0x93, 0x15, 0x6d, 0x48, 0x2c, 0x6d, 0xf4, 0xff, 0xe0,
-	0x45, 0x22, 0x41, 0x85, 0x6c, 0x21, 0x7d, 0x8e, 0x20, 0x3b, 0xa8, 0x90, 0x7b, 0x5a, 0x87, 0xbc,
-	0x8d, 0x02, 0x11, 0x60, 0x6c, 0x57, 0xeb, 0x10, 0x6a, 0x03, 0x3b, 0xce, 0x5b, 0x47, 0x45, 0xea,
-	0xbf, 0x1f, 0x42, 0xda, 0x73, 0x01, 0xa7, 0x61, 0xa4, 0xfa, 0x68, 0xbb, 0xf2, 0x20, 0x17, 0xc3,
-	0x13, 0x90, 0xae, 0x6f, 0x6e, 0x29, 0x6c, 0x89, 0xf0, 0x05, 0xc8, 0xc8, 0xd5, 0xfb, 0xd5, 0x1d,
-	0xa5, 0x56, 0xd9, 0x5a, 0x59, 0xcb, 0xc5, 0x31, 0x86, 0x2c, 0x13, 0xd4, 0x37, 0xb9, 0x2c, 0xb1,
-	0xf4, 0xc5, 0x28, 0x8c, 0xb9, 0x36, 0xe2, 0xdb, 0x90, 0x7c, 0xd8, 0xb7, 0xf6, 0xf1, 0xf4, 0xa0,
-	0x42, 0x1f, 0x9b, 0x9a, 0x4d, 0xf8, 0x13, 0x27, 0xcc, 0x0c, 0xc9, 0xd9, 0xf3, 0x26, 0xc5, 0xf0,
-	0x2a, 0x64, 0x7c, 0xa3, 0x0d, 0x8e, 0x7c, 0x99, 0x12, 0x2e, 0x07, 0xa4, 0xc1, 0x29, 0x48, 0x8a,
-	0x2d, 0x20, 0xbc, 0x09, 0x59, 0xaa, 0x72, 0x27, 0x12, 0x0b, 0x7b, 0x93, 0x71, 0xd4, 0xa4, 0x28,
-	0xcc, 0x9e, 0xa0, 0xf5, 0xcc, 0x5a, 0x0b, 0xbe, 0xe7, 0x0b, 0x51, 0x9f, 0x04, 0xc2, 0xc6, 0x45,
-	0x34, 0x7e, 0x29, 0x86, 0xab, 0x00, 0x83, 0xb6, 0x89, 0x2f, 0x05, 0xc0, 0xfe, 0x56, 0x2f, 0x08,
-	0x51, 0x2a, 0x8f, 0x66, 0x19, 0xd2, 0x5e, 0xd3, 0xc0, 0x85, 0x88, 0x3e, 0xc2, 0x48, 0x4e, 0xee,
-	0x30, 0x52, 0x0c, 0xdf, 0x83, 0xf1, 0x4a, 0xa7, 0x73, 0x1e, 0x1a, 0xc1, 0xaf, 0xb1, 0xc2, 0x3c,
-	0x1d, 0xef, 0x02, 0x0d, 0xdf, 0xd3, 0xf8, 0xaa, 0xf7, 0xac, 0x9c, 0xda, 0x7c, 0x84, 0x7f, 0x9f,
-	0x89, 0xf3, 0x4e, 0xdb, 0x82, 0x0b, 0xa1, 0xeb, 0x1a, 0x8b, 0xa1, 0xdd, 0xa1, 0x1b, 0x5e, 0x98,
-	0x3b, 0x51, 0xef, 0xb1, 0x36, 0xf9, 0xa0, 0x16, 0xfc, 0x24, 0x84, 0xa5, 0xe1, 0x24, 0x84, 0xbf,
-	0x3f, 0x09, 0xff, 0x3a, 0x15, 0xe3, 0xab, 0xca, 0x03, 0x98, 0x8e, 0xfe, 0xe4, 0x82, 0xaf, 0x44,
-	0xd4, 0xcc, 0xf0, 0x67, 0x20, 0xe1, 0xea, 0x59, 0xb0, 0xc1, 0x61, 0xcb, 0x1f, 0x3c, 0x7b, 0x29,
-	0xc6, 0x9e, 0xbf, 0x14, 0x63, 0xaf, 0x5f, 0x8a, 0xe8, 0xb3, 0x63, 0x11, 0x7d, 0x7f, 0x2c, 0xa2,
-	0xa7, 0xc7, 0x22, 0x7a, 0x76, 0x2c, 0xa2, 0xdf, 0x8f, 0x45, 0xf4, 0xc7, 0xb1, 0x18, 0x7b, 0x7d,
-	0x2c, 0xa2, 0xaf, 0x5e, 0x89, 0xb1, 0x67, 0xaf, 0xc4, 0xd8, 0xf3, 0x57, 0x62, 0xec, 0xe3, 0x54,
-	0xab, 0xa3, 0x11, 0xc3, 0x6e, 0xa6, 0xe8, 0x87, 0xb7, 0x1b, 0x7f, 0x05, 0x00, 0x00, 0xff, 0xff,
-	0x2f, 0xe4, 0x86, 0xaf, 0xf3, 0x13, 0x00, 0x00,
+	// 1911 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xbc, 0x58, 0xcd, 0x6f, 0x1b, 0xc7,
+	0x15, 0xe7, 0xf0, 0x4b, 0xe4, 0x23, 0x45, 0x53, 0x43, 0xc9, 0xa2, 0x57, 0x11, 0xc5, 0x6e, 0x61,
+	0x57, 0x69, 0x13, 0xca, 0x1f, 0x29, 0xe0, 0x04, 0x2d, 0x52, 0x4a, 0xa2, 0x6d, 0xd5, 0xa6, 0xe4,
+	0x2c, 0xa5, 0xc6, 0x28, 0x10, 0x6c, 0x97, 0xe4, 0x48, 0x5e, 0x98, 0xbb, 0x64, 0x76, 0x87, 0x81,
+	0x78, 0x2b, 0xd0, 0x3f, 0xa0, 0x45, 0x4f, 0x3d, 0x15, 0x28, 0xd0, 0x43, 0x8f, 0x45, 0x81, 0xa2,
+	0xb7, 0x02, 0xbd, 0xe5, 0x52, 0xc0, 0xc7, 0xa0, 0x07, 0xa3, 0x96, 0x2f, 0xed, 0x2d, 0x7f, 0x42,
+	0xb0, 0x33, 0xb3, 0xcb, 0xd9, 0xe5, 0x4a, 0xa2, 0x83, 0xd8, 0x27, 0xee, 0xbc, 0xf7, 0xe6, 0xf7,
+	0x3e, 0xe7, 0xcd, 0xe3, 0x40, 0xc9, 0xb4, 0x4f, 0x88, 0x4b, 0x89, 0xd3, 0x18, 0x39, 0x43, 0x3a,
+	0xc4, 0xd9, 0xde, 0xd0, 0xa1, 0xe4, 0x54, 0x79, 0xff, 0xc4, 0xa4, 0x4f, 0xc7, 0xdd, 0x46, 0x6f,
+	0x68, 0x6d, 0x9d, 0x0c, 0x4f, 0x86, 0x5b, 0x8c, 0xdd, 0x1d, 0x1f, 0xb3, 0x15, 0x5b, 0xb0, 0x2f,
+	0xbe, 0x4d, 0xb9, 0x29, 0x8b, 0x3b, 0xc6, 0xb1, 0x61, 0x1b, 0x5b, 0x96, 0x69, 0x99, 0xce, 0xd6,
+	0xe8, 0xd9, 0x09, 0xff, 0x1a, 0x75, 0xf9, 0x2f, 0xdf, 0xa1, 0xee, 0x83, 0xf2, 0xc8, 0xe8, 0x92,
+	0xc1, 0xbe, 0x61, 0x11, 0xb7, 0x69, 0xf7, 0x7f, 0x61, 0x0c, 0xc6, 0xc4, 0xd5, 0xc8, 0xe7, 0x63,
+	0xe2, 0x52, 0x7c, 0x13, 0x72, 0x96, 0x41, 0x7b, 0x4f, 0x89, 0xe3, 0x56, 0x51, 0x3d, 0xb5, 0x59,
+	0xb8, 0xbd, 0xdc, 0xe0, 0x96, 0x35, 0xd8, 0xae, 0x36, 0x67, 0x6a, 0x81, 0x94, 0xfa, 0x00, 0xd6,
+	0x62, 0xf1, 0xdc, 0xd1, 0xd0, 0x76, 0x09, 0x7e, 0x17, 0x32, 0x26, 0x25, 0x96, 0x8f, 0x56, 0x09,
+	0xa1, 0x09, 0x59, 0x2e, 0xa1, 0xee, 0x42, 0x41, 0xa2, 0xe2, 0x75, 0x80, 0x81, 0xb7, 0xd4, 0x6d,
+	0xc3, 0x22, 0x55, 0x54, 0x47, 0x9b, 0x79, 0x2d, 0x3f, 0xf0, 0x55, 0xe1, 0xab, 0x90, 0xfd, 0x82,
+	0x09, 0x56, 0x93, 0xf5, 0xd4, 0x66, 0x5e, 0x13, 0x2b, 0xd5, 0x81, 0x75, 0x09, 0x65, 0xc7, 0x70,
+	0xfa, 0xa6, 0x6d, 0x0c, 0x4c, 0x3a, 0xf1, 0x5d, 0xdc, 0x80, 0xc2, 0x14, 0x97, 0xdb, 0x95, 0xd7,
+	0x20, 0x00, 0x76, 0x43, 0x31, 0x48, 0xce, 0x15, 0x83, 0x23, 0xa8, 0x9d, 0xa7, 0x53, 0x84, 0xe1,
+	0x4e, 0x38, 0x0c, 0xeb, 0xb3, 0x61, 0xe8, 0x10, 0xc7, 0x24, 0xee, 0xce, 0x70, 0x6c, 0x53, 0x3f,
+	0x20, 0x2f, 0x10, 0xac, 0xc4, 0x0a, 0x5c, 0x16, 0x1b, 0x03, 0x30, 0x67, 0xb3, 0x98, 0xe8, 0x2e,
+	0xdb, 0x29, 0x7c, 0xb9, 0x73, 0xa1, 0xea, 0x19, 0x6a, 0xcb, 0xa6, 0xce, 0x44, 0x2b, 0x0f, 0x22,
+	0x64, 0x65, 0x07, 0x56, 0x62, 0x45, 0x71, 0x19, 0x52, 0xcf, 0xc8, 0x44, 0xd8, 0xe4, 0x7d, 0xe2,
+	0x65, 0xc8, 0x30, 0x3b, 0xaa, 0xc9, 0x3a, 0xda, 0x4c, 0x6b, 0x7c, 0xf1, 0x51, 0xf2, 0x2e, 0x52,
+	0xdf, 0x85, 0xa5, 0xc3, 0xce, 0xee, 0x76, 0x87, 0x1a, 0x74, 0x1c, 0x94, 0xe0, 0x32, 0x64, 0x06,
+	0xa6, 0x65, 0x52, 0x06, 0x91, 0xd1, 0xf8, 0x42, 0xfd, 0x57, 0x1a, 0xb0, 0x2c, 0x2b, 0xe2, 0xba,
+	0x0e, 0x60, 0x8f, 0x2d, 0xdf, 0x43, 0xc4, 0x14, 0xe4, 0xed, 0xb1, 0xc5, 0x2d, 0xc2, 0x37, 0xe0,
+	0x8a, 0xc7, 0xe6, 0xc1, 0x18, 0x19, 0x26, 0xcb, 0xa8, 0x27, 0xb3, 0x68, 0x8f, 0x2d, 0x66, 0xff,
+	0x63, 0x8f, 0xe8, 0xd5, 0x44, 0xef, 0xe9, 0xd8, 0x7e, 0xa6, 0xf7, 0xbc, 0x20, 0x54, 0x53, 0x4c,
+	0x06, 0x18, 0x89, 0x07, 0xfc, 0x1a, 0xe4, 0x2c, 0xd3, 0xd6, 0xa9, 0x69, 0x91, 0x6a, 0xba, 0x8e,
+	0x36, 0x53, 0xda, 0x82, 0x65, 0xda, 0x87, 0xa6, 0x45, 0x18, 0xcb, 0x38, 0xe5, 0xac, 0x8c, 0x60,
+	0x19, 0xa7, 0x8c, 0xf5, 0x19, 0xac, 0x71, 0xcb, 0x38, 0xae, 0xde, 0x9d, 0xe8, 0x16, 0xa1, 0x8e,
+	0xd9, 0xe3, 0x79, 0xcb, 0xb2, 0x84, 0x5c, 0xf3, 0x13, 0xe2, 0xbb, 0x67, 0xba, 0xd4, 0xec, 0xed,
+	0x51, 0x62, 0x6d, 0xa7, 0xbf, 0x7c, 0xb1, 0x91, 0xd0, 0x56, 0xdd, 0x69, 0x7e, 0xb6, 0x27, 0x6d,
+	0x06, 0xc0, 0xd2, 0xdc, 0x87, 0x0d, 0x39, 0xcd, 0x81, 0x0e, 0xa9, 0x34, 0x16, 0xe6, 0x53, 0xa1,
+	0x4c, 0xf3, 0x2b, 0xd4, 0x04, 0x67, 0x1a, 0xff, 0x0a, 0xd6, 0x2d, 0x62, 0x0d, 0x9d, 0x89, 0x6e,
+	0xda, 0x7a, 0x77, 0x42, 0x89, 0x1b, 0xd1, 0x91, 0x9b, 0x4f, 0x47, 0x95, 0xa3, 0xec, 0xd9, 0xdb,
+	0x1e, 0x86, 0xac, 0xe1, 0x18, 0xea, 0xd1, 0x30, 0xc9, 0x7e, 0x79, 0x79, 0xab, 0xe6, 0xe7, 0x53,
+	0xb2, 0x16, 0x8a, 0xd5, 0xb4, 0x42, 0xbd, 0x34, 0xab, 0x3f, 0x85, 0xa5, 0x99, 0x7d, 0x18, 0x43,
+	0x5a, 0x3a, 0x44, 0xec, 0x3b, 0xbe, 0x62, 0xd5, 0xfb, 0x50, 0x69, 0xf6, 0xa8, 0xf9, 0x85, 0x28,
+	0xf7, 0x6f, 0xdf, 0x32, 0x7f, 0x06, 0xcb, 0x61, 0x20, 0x51, 0xcc, 0x9b, 0x90, 0xe5, 0xe5, 0x21,
+	0x70, 0xca, 0x02, 0x67, 0xd4, 0x6d, 0xf0, 0xac, 0x6b, 0x82, 0xaf, 0xfe, 0x1b, 0x41, 0x41, 0x23,
+	0x46, 0xdf, 0xb7, 0xa1, 0x01, 0x0b, 0x9f, 0x8f, 0xfd, 0x33, 0x10, 0x32, 0xe1, 0x93, 0x31, 0x71,
+	0xfc, 0xd6, 0xa7, 0xf9, 0x42, 0xf8, 0x09, 0xac, 0x1a, 0xbd, 0x1e, 0x19, 0x51, 0xd2, 0xd7, 0x1d,
+	0xa1, 0x5e, 0xa7, 0x93, 0x91, 0xe8, 0x12, 0xa5, 0xdb, 0x75, 0x7f, 0xbf, 0xa4, 0xa5, 0xe1, 0x1b,
+	0x7a, 0x38, 0x19, 0x11, 0x6d, 0xc5, 0x07, 0x90, 0xa9, 0xae, 0xfa, 0x01, 0x14, 0x65, 0x02, 0x2e,
+	0xc0, 0x42, 0xa7, 0xd9, 0x7e, 0xfc, 0xa8, 0xd5, 0x29, 0x27, 0xf0, 0x2a, 0x54, 0x3a, 0x87, 0x5a,
+	0xab, 0xd9, 0x6e, 0xed, 0xea, 0x4f, 0x0e, 0x34, 0x7d, 0xe7, 0xc1, 0xd1, 0xfe, 0xc3, 0x4e, 0x19,
+	0xa9, 0x1f, 0x43, 0x91, 0x2b, 0x12, 0x91, 0xd8, 0x82, 0x05, 0x87, 0xb8, 0xe3, 0x01, 0xf5, 0xfd,
+	0x59, 0x89, 0xf8, 0xc3, 0xe5, 0x34, 0x5f, 0x4a, 0x9d, 0x00, 0xee, 0x50, 0x87, 0x18, 0x56, 0x08,
+	0x66, 0x1b, 0x4a, 0xec, 0x0c, 0x93, 0xfe, 0xb4, 0x43, 0x78, 0x68, 0x6b, 0x3e, 0x1a, 0xdf, 0xb3,
+	0xc3, 0x65, 0x44, 0x36, 0x16, 0x7b, 0xf2, 0xd2, 0x6b, 0x0d, 0x5e, 0xd4, 0xbc, 0xea, 0xef, 0x93,
+	0x53, 0x56, 0x11, 0x29, 0x0d, 0x18, 0x69, 0xcf, 0xa3, 0xa8, 0x7f, 0x45, 0x50, 0x89, 0xc1, 0xc1,
+	0xc7, 0x90, 0x65, 0x55, 0x1c, 0xbd, 0xfa, 0x46, 0xdd, 0x46, 0xd0, 0x79, 0xb6, 0x3f, 0xf4, 0xaa,
+	0xf6, 0x3f, 0x2f, 0x36, 0x6e, 0xcd, 0x73, 0x8f, 0xf3, 0x7d, 0xcd, 0xbe, 0x31, 0xa2, 0xc4, 0xd1,
+	0x04, 0x3a, 0xbe, 0x05, 0x59, 0x66, 0xb1, 0xdf, 0xe0, 0x2b, 0x31, 0xce, 0x89, 0xd3, 0x21, 0x04,
+	0xd5, 0xbf, 0x23, 0x28, 0x48, 0x5c, 0x5c, 0x83, 0x82, 0xdf, 0xdd, 0x74, 0x8b, 0xb7, 0xd1, 0x94,
+	0x96, 0x17, 0x0d, 0xae, 0xed, 0x32, 0xbe, 0x71, 0x1a, 0xf0, 0x93, 0x82, 0xcf, 0xbb, 0x5c, 0xdb,
+	0xbb, 0x31, 0xd3, 0x5e, 0xf1, 0xb0, 0xbe, 0x59, 0xba, 0xfd, 0x4e, 0x8c, 0x01, 0x8d, 0x96, 0xdd,
+	0x1b, 0xf6, 0x4d, 0xfb, 0x44, 0x63, 0x92, 0xde, 0xa9, 0xeb, 0x1b, 0xd4, 0x60, 0xbd, 0xb4, 0xa8,
+	0xb1, 0x6f, 0xb5, 0x0e, 0x39, 0x5f, 0xca, 0x2b, 0x9b, 0xa3, 0xfd, 0x87, 0xfb, 0x07, 0x9f, 0xee,
+	0x97, 0x13, 0x78, 0x01, 0x52, 0x4f, 0x0e, 0xb4, 0x32, 0x52, 0xff, 0x80, 0xa0, 0x28, 0x17, 0x34,
+	0x7e, 0x0f, 0xb0, 0x4b, 0x0d, 0x87, 0x32, 0xd3, 0x5c, 0x6a, 0x58, 0xa3, 0xa9, 0xfd, 0x65, 0xc6,
+	0x39, 0xf4, 0x19, 0x6d, 0x17, 0x6f, 0x42, 0x99, 0xd8, 0xfd, 0xb0, 0x2c, 0xf7, 0xa5, 0x44, 0xec,
+	0xbe, 0x2c, 0x29, 0x9f, 0xe9, 0xd4, 0x5c, 0x67, 0xfa, 0x4f, 0x08, 0x96, 0x5b, 0xa7, 0xc4, 0x1a,
+	0x0d, 0x0c, 0xe7, 0xad, 0x98, 0x78, 0x6b, 0xc6, 0xc4, 0x95, 0x38, 0x13, 0x5d, 0xc9, 0xc6, 0x87,
+	0xb0, 0x18, 0x3a, 0x3e, 0xf8, 0x23, 0x00, 0xa6, 0x29, 0xae, 0x73, 0x8c, 0xba, 0x0d, 0x4f, 0x1d,
+	0x2f, 0x66, 0x51, 0x3f, 0x92, 0xb4, 0xfa, 0x7b, 0x04, 0x15, 0x86, 0xe6, 0x9f, 0x3b, 0x81, 0xf9,
+	0xb1, 0xb8, 0x4a, 0x43, 0xa0, 0xab, 0x41, 0xdf, 0x0e, 0x20, 0xe5, 0xba, 0x94, 0x77, 0x44, 0x8c,
+	0x4a, 0xbe, 0x96, 0x51, 0x1d, 0x58, 0x89, 0x24, 0xe1, 0x3b, 0xf0, 0xf4, 0x9f, 0x08, 0xb0, 0x3c,
+	0xae, 0x8a, 0xc4, 0x5e, 0x32, 0x83, 0xc5, 0xe7, 0x3d, 0xf9, 0x1a, 0x79, 0x4f, 0x5d, 0x9a, 0x77,
+	0xef, 0xf4, 0xcc, 0x91, 0xf7, 0xbb, 0x50, 0x09, 0xd9, 0x2f, 0x62, 0xf2, 0x3d, 0x28, 0x4a, 0xd7,
+	0xac, 0x3f, 0x09, 0x17, 0xa6, 0xa3, 0x80, 0xab, 0xfe, 0x11, 0xc1, 0xd2, 0x74, 0xba, 0x7f, 0xbb,
+	0x25, 0x3d, 0x97, 0x6b, 0x3f, 0x06, 0x2c, 0xdb, 0x27, 0x3c, 0xbb, 0x6c, 0xc4, 0x57, 0x31, 0x94,
+	0x8f, 0x5c, 0xe2, 0x78, 0x93, 0x80, 0xef, 0x95, 0xfa, 0x0f, 0x04, 0x4b, 0x12, 0x51, 0x40, 0x5d,
+	0xf7, 0xff, 0xa9, 0x99, 0x43, 0x5b, 0x77, 0x0c, 0xca, 0x33, 0x8d, 0xb4, 0xc5, 0x80, 0xaa, 0x19,
+	0x34, 0x3a, 0x87, 0x26, 0xa3, 0x73, 0xe8, 0x7b, 0x80, 0x8d, 0x91, 0xa9, 0x47, 0x90, 0x52, 0x0c,
+	0xa9, 0x6c, 0x8c, 0xcc, 0xbd, 0x10, 0x58, 0x03, 0x2a, 0xce, 0x78, 0x40, 0xa2, 0xe2, 0x69, 0x26,
+	0xbe, 0xe4, 0xb1, 0x42, 0xf2, 0xea, 0x67, 0x50, 0xf1, 0x0c, 0xdf, 0xdb, 0x0d, 0x9b, 0xbe, 0x0a,
+	0x0b, 0x63, 0x97, 0x38, 0xba, 0xd9, 0x17, 0xd5, 0x99, 0xf5, 0x96, 0x7b, 0x7d, 0xfc, 0xbe, 0x68,
+	0xbe, 0xc9, 0x3a, 0x92, 0x67, 0xaa, 0x19, 0xe7, 0x45, 0x5f, 0xbe, 0x0f, 0xd8, 0x63, 0xb9, 0x61,
+	0xf4, 0x5b, 0x90, 0x71, 0x3d, 0x42, 0xf4, 0x4a, 0x8d, 0xb1, 0x44, 0xe3, 0x92, 0xea, 0xdf, 0x10,
+	0xd4, 0xf8, 0x20, 0xe3, 0xde, 0x1b, 0x3a, 0xe1, 0x94, 0xbe, 0xe1, 0xd2, 0xba, 0x0b, 0x45, 0xbf,
+	0x66, 0x74, 0x97, 0xd0, 0x8b, 0x3b, 0x66, 0xc1, 0x17, 0xed, 0x10, 0xaa, 0x3e, 0x84, 0x8d, 0x73,
+	0x6d, 0x7e, 0xed, 0xb9, 0xad, 0x0a, 0x57, 0x05, 0x58, 0x9b, 0x50, 0xc3, 0x8b, 0xae, 0x5f, 0x7d,
+	0x07, 0xb0, 0x3a, 0xc3, 0x11, 0xf0, 0x1f, 0x40, 0xce, 0x12, 0x34, 0xa1, 0xa0, 0x1a, 0x55, 0x10,
+	0xec, 0x09, 0x24, 0xd5, 0xff, 0x23, 0xb8, 0x12, 0xe9, 0xb6, 0x5e, 0xbc, 0x8e, 0x9d, 0xa1, 0xa5,
+	0xfb, 0x6f, 0x0f, 0xd3, 0xd2, 0x28, 0x79, 0xf4, 0x3d, 0x41, 0xde, 0xeb, 0xcb, 0xb5, 0x93, 0x0c,
+	0xd5, 0xce, 0x74, 0xaa, 0x49, 0xbd, 0xd1, 0xa9, 0xe6, 0x47, 0xc1, 0x54, 0x93, 0x66, 0x7a, 0x16,
+	0xfd, 0x54, 0xc5, 0xcd, 0x33, 0xbf, 0x45, 0x90, 0xe1, 0x1e, 0xbe, 0xa9, 0xfa, 0x51, 0x20, 0x47,
+	0xc4, 0x6c, 0xc2, 0x8e, 0x6d, 0x46, 0x0b, 0xd6, 0xb1, 0xb3, 0x4c, 0x13, 0x16, 0x43, 0xb5, 0xf2,
+	0x2d, 0xfe, 0x25, 0xe8, 0x50, 0x94, 0x39, 0xf8, 0xba, 0x18, 0xb2, 0x10, 0x1b, 0xb2, 0x96, 0xfc,
+	0xdd, 0x8c, 0xcd, 0x26, 0xf2, 0x60, 0xb2, 0x62, 0x17, 0x52, 0x32, 0xee, 0xff, 0x4c, 0x8a, 0x11,
+	0xf9, 0x42, 0xfd, 0x0d, 0x82, 0xd2, 0xb4, 0x42, 0xee, 0x99, 0x03, 0xf2, 0x5d, 0x14, 0x88, 0x02,
+	0xb9, 0x63, 0x73, 0x40, 0x98, 0x0d, 0x5c, 0x5d, 0xb0, 0x8e, 0x8b, 0xd4, 0x0f, 0x7f, 0x0e, 0xf9,
+	0xc0, 0x05, 0x9c, 0x87, 0x4c, 0xeb, 0x93, 0xa3, 0xe6, 0xa3, 0x72, 0x02, 0x2f, 0x42, 0x7e, 0xff,
+	0xe0, 0x50, 0xe7, 0x4b, 0x84, 0xaf, 0x40, 0x41, 0x6b, 0xdd, 0x6f, 0x3d, 0xd1, 0xdb, 0xcd, 0xc3,
+	0x9d, 0x07, 0xe5, 0x24, 0xc6, 0x50, 0xe2, 0x84, 0xfd, 0x03, 0x41, 0x4b, 0xdd, 0xfe, 0x73, 0x0e,
+	0x72, 0xbe, 0x8d, 0xf8, 0x43, 0x48, 0x3f, 0x1e, 0xbb, 0x4f, 0xf1, 0xd5, 0x69, 0x85, 0x7e, 0xea,
+	0x98, 0x94, 0x88, 0x13, 0xa7, 0xac, 0xce, 0xd0, 0xf9, 0x79, 0x53, 0x13, 0x78, 0x17, 0x0a, 0xd2,
+	0x68, 0x83, 0x63, 0xff, 0x4c, 0x29, 0x6b, 0x21, 0x6a, 0x78, 0x0a, 0x52, 0x13, 0x37, 0x11, 0x3e,
+	0x80, 0x12, 0x63, 0xf9, 0x13, 0x89, 0x8b, 0x83, 0xc9, 0x38, 0x6e, 0x52, 0x54, 0xd6, 0xcf, 0xe1,
+	0x06, 0x66, 0x3d, 0x08, 0x3f, 0x90, 0x29, 0x71, 0x6f, 0x69, 0x51, 0xe3, 0x62, 0x2e, 0x7e, 0x35,
+	0x81, 0x5b, 0x00, 0xd3, 0x6b, 0x13, 0x5f, 0x0b, 0x09, 0xcb, 0x57, 0xbd, 0xa2, 0xc4, 0xb1, 0x02,
+	0x98, 0x6d, 0xc8, 0x07, 0x97, 0x06, 0xae, 0xc6, 0xdc, 0x23, 0x1c, 0xe4, 0xfc, 0x1b, 0x46, 0x4d,
+	0xe0, 0x7b, 0x50, 0x6c, 0x0e, 0x06, 0xf3, 0xc0, 0x28, 0x32, 0xc7, 0x8d, 0xe2, 0x0c, 0x60, 0xf5,
+	0x9c, 0x3e, 0x8d, 0x6f, 0x04, 0x67, 0xe5, 0xc2, 0xcb, 0x47, 0xf9, 0xc1, 0xa5, 0x72, 0x81, 0xb6,
+	0x43, 0xb8, 0x12, 0x69, 0xd7, 0xb8, 0x16, 0xd9, 0x1d, 0xe9, 0xf0, 0xca, 0xc6, 0xb9, 0xfc, 0x00,
+	0xb5, 0x0b, 0x95, 0x69, 0x9c, 0x83, 0xb7, 0x54, 0xac, 0xce, 0x26, 0x21, 0xfa, 0x70, 0xab, 0x7c,
+	0xff, 0x42, 0x19, 0xa9, 0x2a, 0x9f, 0xc1, 0xd5, 0xf8, 0xb7, 0x4a, 0x7c, 0x3d, 0xa6, 0x66, 0x66,
+	0xdf, 0x4f, 0x95, 0x1b, 0x97, 0x89, 0x49, 0xca, 0x5a, 0x00, 0xd3, 0x47, 0x3b, 0x3c, 0xf3, 0x7a,
+	0x33, 0x9e, 0xcd, 0xed, 0xec, 0x1b, 0x9f, 0x9a, 0xc0, 0x6d, 0x28, 0xca, 0x0f, 0x26, 0x38, 0xa8,
+	0xee, 0x98, 0xf7, 0x18, 0xe5, 0x9d, 0x78, 0xe6, 0xd4, 0xaa, 0xed, 0x9f, 0x3c, 0x7f, 0x59, 0x4b,
+	0x7c, 0xf5, 0xb2, 0x96, 0xf8, 0xfa, 0x65, 0x0d, 0xfd, 0xfa, 0xac, 0x86, 0xfe, 0x72, 0x56, 0x43,
+	0x5f, 0x9e, 0xd5, 0xd0, 0xf3, 0xb3, 0x1a, 0xfa, 0xef, 0x59, 0x0d, 0xfd, 0xef, 0xac, 0x96, 0xf8,
+	0xfa, 0xac, 0x86, 0x7e, 0xf7, 0xaa, 0x96, 0x78, 0xfe, 0xaa, 0x96, 0xf8, 0xea, 0x55, 0x2d, 0xf1,
+	0xcb, 0x6c, 0x6f, 0x60, 0x12, 0x9b, 0x76, 0xb3, 0xec, 0x1d, 0xfd, 0xce, 0x37, 0x03, 0x00, 0x12,
+	0x31, 0x5e, 0x7a, 0xc2, 0x17, 0x00, 0x00,
 }
 
 func (x MatchType) String() string {
@@ -2142,14 +2453,14 @@ func (this *LabelValueSeriesCount) Equal(that interface{}) bool {
 	}
 	return true
 }
-func (this *ReadRequest) Equal(that interface{}) bool {
+func (this *TSDBStatusRequest) Equal(that interface{}) bool {
 	if that == nil {
 		return this == nil
 	}
 
-	that1, ok := that.(*ReadRequest)
+	that1, ok := that.(*TSDBStatusRequest)
 	if !ok {
-		that2, ok := that.(ReadRequest)
+		that2, ok := that.(TSDBStatusRequest)
 		if ok {
 			that1 = &that2
 		} else {
@@ -2161,32 +2472,209 @@ func (this *ReadRequest) Equal(that interface{}) bool {
 	} else if this == nil {
 		return false
 	}
-	if len(this.Queries) != len(that1.Queries) {
-		return false
-	}
-	for i := range this.Queries {
-		if !this.Queries[i].Equal(that1.Queries[i]) {
-			return false
-		}
-	}
-	if len(this.AcceptedResponseTypes) != len(that1.AcceptedResponseTypes) {
+	if this.Limit != that1.Limit {
 		return false
 	}
-	for i := range this.AcceptedResponseTypes {
-		if this.AcceptedResponseTypes[i] != that1.AcceptedResponseTypes[i] {
-			return false
-		}
-	}
 	return true
 }
-func (this *ReadResponse) Equal(that interface{}) bool {
+func (this *TSDBStatusResponse) Equal(that interface{}) bool {
 	if that == nil {
 		return this == nil
 	}
 
-	that1, ok := that.(*ReadResponse)
+	that1, ok := that.(*TSDBStatusResponse)
 	if !ok {
-		that2, ok := that.(ReadResponse)
+		that2, ok := that.(TSDBStatusResponse)
+		if ok {
+			that1 = &that2
+		} else {
+			return false
+		}
+	}
+	if that1 == nil {
+		return this == nil
+	} else if this == nil {
+		return false
+	}
+	if this.NumSeries != that1.NumSeries {
+		return false
+	}
+	if this.NumLabelPairs != that1.NumLabelPairs {
+		return false
+	}
+	if this.ChunkCount != that1.ChunkCount {
+		return false
+	}
+	if this.MinTime != that1.MinTime {
+		return false
+	}
+	if this.MaxTime != that1.MaxTime {
+		return false
+	}
+	if len(this.SeriesCountByMetricName) != len(that1.SeriesCountByMetricName) {
+		return false
+	}
+	for i := range this.SeriesCountByMetricName {
+		if !this.SeriesCountByMetricName[i].Equal(&that1.SeriesCountByMetricName[i]) {
+			return false
+		}
+	}
+	if len(this.LabelValueCountByLabelName) != len(that1.LabelValueCountByLabelName) {
+		return false
+	}
+	for i := range this.LabelValueCountByLabelName {
+		if !this.LabelValueCountByLabelName[i].Equal(&that1.LabelValueCountByLabelName[i]) {
+			return false
+		}
+	}
+	if len(this.MemoryInBytesByLabelName) != len(that1.MemoryInBytesByLabelName) {
+		return false
+	}
+	for i := range this.MemoryInBytesByLabelName {
+		if !this.MemoryInBytesByLabelName[i].Equal(&that1.MemoryInBytesByLabelName[i]) {
+			return false
+		}
+	}
+	if len(this.SeriesCountByLabelValuePair) != len(that1.SeriesCountByLabelValuePair) {
+		return false
+	}
+	for i := range this.SeriesCountByLabelValuePair {
+		if !this.SeriesCountByLabelValuePair[i].Equal(&that1.SeriesCountByLabelValuePair[i]) {
+			return false
+		}
+	}
+	return true
+}
+func (this *TSDBStatisticItem) Equal(that interface{}) bool {
+	if that == nil {
+		return this == nil
+	}
+
+	that1, ok := that.(*TSDBStatisticItem)
+	if !ok {
+		that2, ok := that.(TSDBStatisticItem)
+		if ok {
+			that1 = &that2
+		} else {
+			return false
+		}
+	}
+	if that1 == nil {
+		return this == nil
+	} else if this == nil {
+		return false
+	}
+	if this.Name != that1.Name {
+		return false
+	}
+	if this.Value != that1.Value {
+		return false
+	}
+	return true
+}
+func (this *ActiveSeriesRequest) Equal(that interface{}) bool {
+	if that == nil {
+		return this == nil
+	}
+
+	that1, ok := that.(*ActiveSeriesRequest)
+	if !ok {
+		that2, ok := that.(ActiveSeriesRequest)
+		if ok {
+			that1 = &that2
+		} else {
+			return false
+		}
+	}
+	if that1 == nil {
+		return this == nil
+	} else if this == nil {
+		return false
+	}
+	if len(this.Matchers) != len(that1.Matchers) {
+		return false
+	}
+	for i := range this.Matchers {
+		if !this.Matchers[i].Equal(that1.Matchers[i]) {
+			return false
+		}
+	}
+	return true
+}
+func (this *ActiveSeriesResponse) Equal(that interface{}) bool {
+	if that == nil {
+		return this == nil
+	}
+
+	that1, ok := that.(*ActiveSeriesResponse)
+	if !ok {
+		that2, ok := that.(ActiveSeriesResponse)
+		if ok {
+			that1 = &that2
+		} else {
+			return false
+		}
+	}
+	if that1 == nil {
+		return this == nil
+	} else if this == nil {
+		return false
+	}
+	if len(this.Metric) != len(that1.Metric) {
+		return false
+	}
+	for i := range this.Metric {
+		if !this.Metric[i].Equal(that1.Metric[i]) {
+			return false
+		}
+	}
+	return true
+}
+func (this *ReadRequest) Equal(that interface{}) bool {
+	if that == nil {
+		return this == nil
+	}
+
+	that1, ok := that.(*ReadRequest)
+	if !ok {
+		that2, ok := that.(ReadRequest)
+		if ok {
+			that1 = &that2
+		} else {
+			return false
+		}
+	}
+	if that1 == nil {
+		return this == nil
+	} else if this == nil {
+		return false
+	}
+	if len(this.Queries) != len(that1.Queries) {
+		return false
+	}
+	for i := range this.Queries {
+		if !this.Queries[i].Equal(that1.Queries[i]) {
+			return false
+		}
+	}
+	if len(this.AcceptedResponseTypes) != len(that1.AcceptedResponseTypes) {
+		return false
+	}
+	for i := range this.AcceptedResponseTypes {
+		if this.AcceptedResponseTypes[i] != that1.AcceptedResponseTypes[i] {
+			return false
+		}
+	}
+	return true
+}
+func (this *ReadResponse) Equal(that interface{}) bool {
+	if that == nil {
+		return this == nil
+	}
+
+	that1, ok := that.(*ReadResponse)
+	if !ok {
+		that2, ok := that.(ReadResponse)
 		if ok {
 			that1 = &that2
 		} else {
@@ -3071,6 +3559,93 @@ func (this *LabelValueSeriesCount) GoString() string {
 	s = append(s, "}")
 	return strings.Join(s, "")
 }
+func (this *TSDBStatusRequest) GoString() string {
+	if this == nil {
+		return "nil"
+	}
+	s := make([]string, 0, 5)
+	s = append(s, "&client.TSDBStatusRequest{")
+	s = append(s, "Limit: "+fmt.Sprintf("%#v", this.Limit)+",\n")
+	s = append(s, "}")
+	return strings.Join(s, "")
+}
+func (this *TSDBStatusResponse) GoString() string {
+	if this == nil {
+		return "nil"
+	}
+	s := make([]string, 0, 13)
+	s = append(s, "&client.TSDBStatusResponse{")
+	s = append(s, "NumSeries: "+fmt.Sprintf("%#v", this.NumSeries)+",\n")
+	s = append(s, "NumLabelPairs: "+fmt.Sprintf("%#v", this.NumLabelPairs)+",\n")
+	s = append(s, "ChunkCount: "+fmt.Sprintf("%#v", this.ChunkCount)+",\n")
+	s = append(s, "MinTime: "+fmt.Sprintf("%#v", this.MinTime)+",\n")
+	s = append(s, "MaxTime: "+fmt.Sprintf("%#v", this.MaxTime)+",\n")
+	if this.SeriesCountByMetricName != nil {
+		vs := make([]*TSDBStatisticItem, len(this.SeriesCountByMetricName))
+		for i := range vs {
+			vs[i] = &this.SeriesCountByMetricName[i]
+		}
+		s = append(s, "SeriesCountByMetricName: "+fmt.Sprintf("%#v", vs)+",\n")
+	}
+	if this.LabelValueCountByLabelName != nil {
+		vs := make([]*TSDBStatisticItem, len(this.LabelValueCountByLabelName))
+		for i := range vs {
+			vs[i] = &this.LabelValueCountByLabelName[i]
+		}
+		s = append(s, "LabelValueCountByLabelName: "+fmt.Sprintf("%#v", vs)+",\n")
+	}
+	if this.MemoryInBytesByLabelName != nil {
+		vs := make([]*TSDBStatisticItem, len(this.MemoryInBytesByLabelName))
+		for i := range vs {
+			vs[i] = &this.MemoryInBytesByLabelName[i]
+		}
+		s = append(s, "MemoryInBytesByLabelName: "+fmt.Sprintf("%#v", vs)+",\n")
+	}
+	if this.SeriesCountByLabelValuePair != nil {
+		vs := make([]*TSDBStatisticItem, len(this.SeriesCountByLabelValuePair))
+		for i := range vs {
+			vs[i] = &this.SeriesCountByLabelValuePair[i]
+		}
+		s = append(s, "SeriesCountByLabelValuePair: "+fmt.Sprintf("%#v", vs)+",\n")
+	}
+	s = append(s, "}")
+	return strings.Join(s, "")
+}
+func (this *TSDBStatisticItem) GoString() string {
+	if this == nil {
+		return "nil"
+	}
+	s := make([]string, 0, 6)
+	s = append(s, "&client.TSDBStatisticItem{")
+	s = append(s, "Name: "+fmt.Sprintf("%#v", this.Name)+",\n")
+	s = append(s, "Value: "+fmt.Sprintf("%#v", this.Value)+",\n")
+	s = append(s, "}")
+	return strings.Join(s, "")
+}
+func (this *ActiveSeriesRequest) GoString() string {
+	if this == nil {
+		return "nil"
+	}
+	s := make([]string, 0, 5)
+	s = append(s, "&client.ActiveSeriesRequest{")
+	if this.Matchers != nil {
+		s = append(s, "Matchers: "+fmt.Sprintf("%#v", this.Matchers)+",\n")
+	}
+	s = append(s, "}")
+	return strings.Join(s, "")
+}
+func (this *ActiveSeriesResponse) GoString() string {
+	if this == nil {
+		return "nil"
+	}
+	s := make([]string, 0, 5)
+	s = append(s, "&client.ActiveSeriesResponse{")
+	if this.Metric != nil {
+		s = append(s, "Metric: "+fmt.Sprintf("%#v", this.Metric)+",\n")
+	}
+	s = append(s, "}")
+	return strings.Join(s, "")
+}
 func (this *ReadRequest) GoString() string {
 	if this == nil {
 		return "nil"
@@ -3471,6 +4046,11 @@ type IngesterClient interface {
 	// that match the matchers.
 	// The listing order of the labels is not guaranteed.
 	LabelValuesCardinality(ctx context.Context, in *LabelValuesCardinalityRequest, opts ...grpc.CallOption) (Ingester_LabelValuesCardinalityClient, error)
+	// TSDBStatus returns the cardinality statistics of the TSDB head, like the Prometheus /api/v1/status/tsdb API.
+	TSDBStatus(ctx context.Context, in *TSDBStatusRequest, opts ...grpc.CallOption) (*TSDBStatusResponse, error)
+	// ActiveSeries returns the active series matching the matchers.
+	// The listing order of the series is not guaranteed.
+	ActiveSeries(ctx context.Context, in *ActiveSeriesRequest, opts ...grpc.CallOption) (Ingester_ActiveSeriesClient, error)
 }
 
 type ingesterClient struct {
@@ -3649,6 +4229,47 @@ func (x *ingesterLabelValuesCardinalityClient) Recv() (*LabelValuesCardinalityRe
 	return m, nil
 }
 
+func (c *ingesterClient) TSDBStatus(ctx context.Context, in *TSDBStatusRequest, opts ...grpc.CallOption) (*TSDBStatusResponse, error) {
+	out := new(TSDBStatusResponse)
+	err := c.cc.Invoke(ctx, "/cortex.Ingester/TSDBStatus", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *ingesterClient) ActiveSeries(ctx context.Context, in *ActiveSeriesRequest, opts ...grpc.CallOption) (Ingester_ActiveSeriesClient, error) {
+	stream, err := c.cc.NewStream(ctx, &_Ingester_serviceDesc.Streams[3], "/cortex.Ingester/ActiveSeries", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &ingesterActiveSeriesClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type Ingester_ActiveSeriesClient interface {
+	Recv() (*ActiveSeriesResponse, error)
+	grpc.ClientStream
+}
+
+type ingesterActiveSeriesClient struct {
+	grpc.ClientStream
+}
+
+func (x *ingesterActiveSeriesClient) Recv() (*ActiveSeriesResponse, error) {
+	m := new(ActiveSeriesResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
 // IngesterServer is the server API for Ingester service.
 type IngesterServer interface {
 	Push(context.Context, *mimirpb.WriteRequest) (*mimirpb.WriteResponse, error)
@@ -3667,6 +4288,11 @@ type IngesterServer interface {
 	// that match the matchers.
 	// The listing order of the labels is not guaranteed.
 	LabelValuesCardinality(*LabelValuesCardinalityRequest, Ingester_LabelValuesCardinalityServer) error
+	// TSDBStatus returns the cardinality statistics of the TSDB head, like the Prometheus /api/v1/status/tsdb API.
+	TSDBStatus(context.Context, *TSDBStatusRequest) (*TSDBStatusResponse, error)
+	// ActiveSeries returns the active series matching the matchers.
+	// The listing order of the series is not guaranteed.
+	ActiveSeries(*ActiveSeriesRequest, Ingester_ActiveSeriesServer) error
 }
 
 // UnimplementedIngesterServer can be embedded to have forward compatible implementations.
@@ -3706,6 +4332,12 @@ func (*UnimplementedIngesterServer) LabelNamesAndValues(req *LabelNamesAndValues
 func (*UnimplementedIngesterServer) LabelValuesCardinality(req *LabelValuesCardinalityRequest, srv Ingester_LabelValuesCardinalityServer) error {
 	return status.Errorf(codes.Unimplemented, "method LabelValuesCardinality not implemented")
 }
+func (*UnimplementedIngesterServer) TSDBStatus(ctx context.Context, req *TSDBStatusRequest) (*TSDBStatusResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method TSDBStatus not implemented")
+}
+func (*UnimplementedIngesterServer) ActiveSeries(req *ActiveSeriesRequest, srv Ingester_ActiveSeriesServer) error {
+	return status.Errorf(codes.Unimplemented, "method ActiveSeries not implemented")
+}
 
 func RegisterIngesterServer(s *grpc.Server, srv IngesterServer) {
 	s.RegisterService(&_Ingester_serviceDesc, srv)
@@ -3918,10 +4550,49 @@ func (x *ingesterLabelValuesCardinalityServer) Send(m *LabelValuesCardinalityRes
 	return x.ServerStream.SendMsg(m)
 }
 
-var _Ingester_serviceDesc = grpc.ServiceDesc{
-	ServiceName: "cortex.Ingester",
-	HandlerType: (*IngesterServer)(nil),
-	Methods: []grpc.MethodDesc{
+func _Ingester_TSDBStatus_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(TSDBStatusRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(IngesterServer).TSDBStatus(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/cortex.Ingester/TSDBStatus",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(IngesterServer).TSDBStatus(ctx, req.(*TSDBStatusRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Ingester_ActiveSeries_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(ActiveSeriesRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(IngesterServer).ActiveSeries(m, &ingesterActiveSeriesServer{stream})
+}
+
+type Ingester_ActiveSeriesServer interface {
+	Send(*ActiveSeriesResponse) error
+	grpc.ServerStream
+}
+
+type ingesterActiveSeriesServer struct {
+	grpc.ServerStream
+}
+
+func (x *ingesterActiveSeriesServer) Send(m *ActiveSeriesResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+var _Ingester_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "cortex.Ingester",
+	HandlerType: (*IngesterServer)(nil),
+	Methods: []grpc.MethodDesc{
 		{
 			MethodName: "Push",
 			Handler:    _Ingester_Push_Handler,
@@ -3954,6 +4625,10 @@ var _Ingester_serviceDesc = grpc.ServiceDesc{
 			MethodName: "MetricsMetadata",
 			Handler:    _Ingester_MetricsMetadata_Handler,
 		},
+		{
+			MethodName: "TSDBStatus",
+			Handler:    _Ingester_TSDBStatus_Handler,
+		},
 	},
 	Streams: []grpc.StreamDesc{
 		{
@@ -3971,6 +4646,11 @@ var _Ingester_serviceDesc = grpc.ServiceDesc{
 			Handler:       _Ingester_LabelValuesCardinality_Handler,
 			ServerStreams: true,
 		},
+		{
+			StreamName:    "ActiveSeries",
+			Handler:       _Ingester_ActiveSeries_Handler,
+			ServerStreams: true,
+		},
 	},
 	Metadata: "ingester.proto",
 }
@@ -4218,6 +4898,247 @@ func (m *LabelValueSeriesCount) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 	return len(dAtA) - i, nil
 }
 
+func (m *TSDBStatusRequest) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *TSDBStatusRequest) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *TSDBStatusRequest) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	if m.Limit != 0 {
+		i = encodeVarintIngester(dAtA, i, uint64(m.Limit))
+		i--
+		dAtA[i] = 0x8
+	}
+	return len(dAtA) - i, nil
+}
+
+func (m *TSDBStatusResponse) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *TSDBStatusResponse) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *TSDBStatusResponse) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	if len(m.SeriesCountByLabelValuePair) > 0 {
+		for iNdEx := len(m.SeriesCountByLabelValuePair) - 1; iNdEx >= 0; iNdEx-- {
+			{
+				size, err := m.SeriesCountByLabelValuePair[iNdEx].MarshalToSizedBuffer(dAtA[:i])
+				if err != nil {
+					return 0, err
+				}
+				i -= size
+				i = encodeVarintIngester(dAtA, i, uint64(size))
+			}
+			i--
+			dAtA[i] = 0x4a
+		}
+	}
+	if len(m.MemoryInBytesByLabelName) > 0 {
+		for iNdEx := len(m.MemoryInBytesByLabelName) - 1; iNdEx >= 0; iNdEx-- {
+			{
+				size, err := m.MemoryInBytesByLabelName[iNdEx].MarshalToSizedBuffer(dAtA[:i])
+				if err != nil {
+					return 0, err
+				}
+				i -= size
+				i = encodeVarintIngester(dAtA, i, uint64(size))
+			}
+			i--
+			dAtA[i] = 0x42
+		}
+	}
+	if len(m.LabelValueCountByLabelName) > 0 {
+		for iNdEx := len(m.LabelValueCountByLabelName) - 1; iNdEx >= 0; iNdEx-- {
+			{
+				size, err := m.LabelValueCountByLabelName[iNdEx].MarshalToSizedBuffer(dAtA[:i])
+				if err != nil {
+					return 0, err
+				}
+				i -= size
+				i = encodeVarintIngester(dAtA, i, uint64(size))
+			}
+			i--
+			dAtA[i] = 0x3a
+		}
+	}
+	if len(m.SeriesCountByMetricName) > 0 {
+		for iNdEx := len(m.SeriesCountByMetricName) - 1; iNdEx >= 0; iNdEx-- {
+			{
+				size, err := m.SeriesCountByMetricName[iNdEx].MarshalToSizedBuffer(dAtA[:i])
+				if err != nil {
+					return 0, err
+				}
+				i -= size
+				i = encodeVarintIngester(dAtA, i, uint64(size))
+			}
+			i--
+			dAtA[i] = 0x32
+		}
+	}
+	if m.MaxTime != 0 {
+		i = encodeVarintIngester(dAtA, i, uint64(m.MaxTime))
+		i--
+		dAtA[i] = 0x28
+	}
+	if m.MinTime != 0 {
+		i = encodeVarintIngester(dAtA, i, uint64(m.MinTime))
+		i--
+		dAtA[i] = 0x20
+	}
+	if m.ChunkCount != 0 {
+		i = encodeVarintIngester(dAtA, i, uint64(m.ChunkCount))
+		i--
+		dAtA[i] = 0x18
+	}
+	if m.NumLabelPairs != 0 {
+		i = encodeVarintIngester(dAtA, i, uint64(m.NumLabelPairs))
+		i--
+		dAtA[i] = 0x10
+	}
+	if m.NumSeries != 0 {
+		i = encodeVarintIngester(dAtA, i, uint64(m.NumSeries))
+		i--
+		dAtA[i] = 0x8
+	}
+	return len(dAtA) - i, nil
+}
+
+func (m *TSDBStatisticItem) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *TSDBStatisticItem) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *TSDBStatisticItem) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	if m.Value != 0 {
+		i = encodeVarintIngester(dAtA, i, uint64(m.Value))
+		i--
+		dAtA[i] = 0x10
+	}
+	if len(m.Name) > 0 {
+		i -= len(m.Name)
+		copy(dAtA[i:], m.Name)
+		i = encodeVarintIngester(dAtA, i, uint64(len(m.Name)))
+		i--
+		dAtA[i] = 0xa
+	}
+	return len(dAtA) - i, nil
+}
+
+func (m *ActiveSeriesRequest) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *ActiveSeriesRequest) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *ActiveSeriesRequest) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	if len(m.Matchers) > 0 {
+		for iNdEx := len(m.Matchers) - 1; iNdEx >= 0; iNdEx-- {
+			{
+				size, err := m.Matchers[iNdEx].MarshalToSizedBuffer(dAtA[:i])
+				if err != nil {
+					return 0, err
+				}
+				i -= size
+				i = encodeVarintIngester(dAtA, i, uint64(size))
+			}
+			i--
+			dAtA[i] = 0xa
+		}
+	}
+	return len(dAtA) - i, nil
+}
+
+func (m *ActiveSeriesResponse) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *ActiveSeriesResponse) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *ActiveSeriesResponse) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	if len(m.Metric) > 0 {
+		for iNdEx := len(m.Metric) - 1; iNdEx >= 0; iNdEx-- {
+			{
+				size, err := m.Metric[iNdEx].MarshalToSizedBuffer(dAtA[:i])
+				if err != nil {
+					return 0, err
+				}
+				i -= size
+				i = encodeVarintIngester(dAtA, i, uint64(size))
+			}
+			i--
+			dAtA[i] = 0xa
+		}
+	}
+	return len(dAtA) - i, nil
+}
+
 func (m *ReadRequest) Marshal() (dAtA []byte, err error) {
 	size := m.Size()
 	dAtA = make([]byte, size)
@@ -5477,41 +6398,147 @@ func (m *LabelValueSeriesCount) Size() (n int) {
 	return n
 }
 
-func (m *ReadRequest) Size() (n int) {
+func (m *TSDBStatusRequest) Size() (n int) {
 	if m == nil {
 		return 0
 	}
 	var l int
 	_ = l
-	if len(m.Queries) > 0 {
-		for _, e := range m.Queries {
-			l = e.Size()
-			n += 1 + l + sovIngester(uint64(l))
-		}
-	}
-	if len(m.AcceptedResponseTypes) > 0 {
-		l = 0
-		for _, e := range m.AcceptedResponseTypes {
-			l += sovIngester(uint64(e))
-		}
-		n += 1 + sovIngester(uint64(l)) + l
+	if m.Limit != 0 {
+		n += 1 + sovIngester(uint64(m.Limit))
 	}
 	return n
 }
 
-func (m *ReadResponse) Size() (n int) {
+func (m *TSDBStatusResponse) Size() (n int) {
 	if m == nil {
 		return 0
 	}
 	var l int
 	_ = l
-	if len(m.Results) > 0 {
-		for _, e := range m.Results {
+	if m.NumSeries != 0 {
+		n += 1 + sovIngester(uint64(m.NumSeries))
+	}
+	if m.NumLabelPairs != 0 {
+		n += 1 + sovIngester(uint64(m.NumLabelPairs))
+	}
+	if m.ChunkCount != 0 {
+		n += 1 + sovIngester(uint64(m.ChunkCount))
+	}
+	if m.MinTime != 0 {
+		n += 1 + sovIngester(uint64(m.MinTime))
+	}
+	if m.MaxTime != 0 {
+		n += 1 + sovIngester(uint64(m.MaxTime))
+	}
+	if len(m.SeriesCountByMetricName) > 0 {
+		for _, e := range m.SeriesCountByMetricName {
 			l = e.Size()
 			n += 1 + l + sovIngester(uint64(l))
 		}
 	}
-	return n
+	if len(m.LabelValueCountByLabelName) > 0 {
+		for _, e := range m.LabelValueCountByLabelName {
+			l = e.Size()
+			n += 1 + l + sovIngester(uint64(l))
+		}
+	}
+	if len(m.MemoryInBytesByLabelName) > 0 {
+		for _, e := range m.MemoryInBytesByLabelName {
+			l = e.Size()
+			n += 1 + l + sovIngester(uint64(l))
+		}
+	}
+	if len(m.SeriesCountByLabelValuePair) > 0 {
+		for _, e := range m.SeriesCountByLabelValuePair {
+			l = e.Size()
+			n += 1 + l + sovIngester(uint64(l))
+		}
+	}
+	return n
+}
+
+func (m *TSDBStatisticItem) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	l = len(m.Name)
+	if l > 0 {
+		n += 1 + l + sovIngester(uint64(l))
+	}
+	if m.Value != 0 {
+		n += 1 + sovIngester(uint64(m.Value))
+	}
+	return n
+}
+
+func (m *ActiveSeriesRequest) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if len(m.Matchers) > 0 {
+		for _, e := range m.Matchers {
+			l = e.Size()
+			n += 1 + l + sovIngester(uint64(l))
+		}
+	}
+	return n
+}
+
+func (m *ActiveSeriesResponse) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if len(m.Metric) > 0 {
+		for _, e := range m.Metric {
+			l = e.Size()
+			n += 1 + l + sovIngester(uint64(l))
+		}
+	}
+	return n
+}
+
+func (m *ReadRequest) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if len(m.Queries) > 0 {
+		for _, e := range m.Queries {
+			l = e.Size()
+			n += 1 + l + sovIngester(uint64(l))
+		}
+	}
+	if len(m.AcceptedResponseTypes) > 0 {
+		l = 0
+		for _, e := range m.AcceptedResponseTypes {
+			l += sovIngester(uint64(e))
+		}
+		n += 1 + sovIngester(uint64(l)) + l
+	}
+	return n
+}
+
+func (m *ReadResponse) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if len(m.Results) > 0 {
+		for _, e := range m.Results {
+			l = e.Size()
+			n += 1 + l + sovIngester(uint64(l))
+		}
+	}
+	return n
 }
 
 func (m *StreamReadResponse) Size() (n int) {
@@ -6072,6 +7099,95 @@ func (this *LabelValueSeriesCount) String() string {
 	}, "")
 	return s
 }
+func (this *TSDBStatusRequest) String() string {
+	if this == nil {
+		return "nil"
+	}
+	s := strings.Join([]string{`&TSDBStatusRequest{`,
+		`Limit:` + fmt.Sprintf("%v", this.Limit) + `,`,
+		`}`,
+	}, "")
+	return s
+}
+func (this *TSDBStatusResponse) String() string {
+	if this == nil {
+		return "nil"
+	}
+	repeatedStringForSeriesCountByMetricName := "[]TSDBStatisticItem{"
+	for _, f := range this.SeriesCountByMetricName {
+		repeatedStringForSeriesCountByMetricName += strings.Replace(strings.Replace(f.String(), "TSDBStatisticItem", "TSDBStatisticItem", 1), `&`, ``, 1) + ","
+	}
+	repeatedStringForSeriesCountByMetricName += "}"
+	repeatedStringForLabelValueCountByLabelName := "[]TSDBStatisticItem{"
+	for _, f := range this.LabelValueCountByLabelName {
+		repeatedStringForLabelValueCountByLabelName += strings.Replace(strings.Replace(f.String(), "TSDBStatisticItem", "TSDBStatisticItem", 1), `&`, ``, 1) + ","
+	}
+	repeatedStringForLabelValueCountByLabelName += "}"
+	repeatedStringForMemoryInBytesByLabelName := "[]TSDBStatisticItem{"
+	for _, f := range this.MemoryInBytesByLabelName {
+		repeatedStringForMemoryInBytesByLabelName += strings.Replace(strings.Replace(f.String(), "TSDBStatisticItem", "TSDBStatisticItem", 1), `&`, ``, 1) + ","
+	}
+	repeatedStringForMemoryInBytesByLabelName += "}"
+	repeatedStringForSeriesCountByLabelValuePair := "[]TSDBStatisticItem{"
+	for _, f := range this.SeriesCountByLabelValuePair {
+		repeatedStringForSeriesCountByLabelValuePair += strings.Replace(strings.Replace(f.String(), "TSDBStatisticItem", "TSDBStatisticItem", 1), `&`, ``, 1) + ","
+	}
+	repeatedStringForSeriesCountByLabelValuePair += "}"
+	s := strings.Join([]string{`&TSDBStatusResponse{`,
+		`NumSeries:` + fmt.Sprintf("%v", this.NumSeries) + `,`,
+		`NumLabelPairs:` + fmt.Sprintf("%v", this.NumLabelPairs) + `,`,
+		`ChunkCount:` + fmt.Sprintf("%v", this.ChunkCount) + `,`,
+		`MinTime:` + fmt.Sprintf("%v", this.MinTime) + `,`,
+		`MaxTime:` + fmt.Sprintf("%v", this.MaxTime) + `,`,
+		`SeriesCountByMetricName:` + repeatedStringForSeriesCountByMetricName + `,`,
+		`LabelValueCountByLabelName:` + repeatedStringForLabelValueCountByLabelName + `,`,
+		`MemoryInBytesByLabelName:` + repeatedStringForMemoryInBytesByLabelName + `,`,
+		`SeriesCountByLabelValuePair:` + repeatedStringForSeriesCountByLabelValuePair + `,`,
+		`}`,
+	}, "")
+	return s
+}
+func (this *TSDBStatisticItem) String() string {
+	if this == nil {
+		return "nil"
+	}
+	s := strings.Join([]string{`&TSDBStatisticItem{`,
+		`Name:` + fmt.Sprintf("%v", this.Name) + `,`,
+		`Value:` + fmt.Sprintf("%v", this.Value) + `,`,
+		`}`,
+	}, "")
+	return s
+}
+func (this *ActiveSeriesRequest) String() string {
+	if this == nil {
+		return "nil"
+	}
+	repeatedStringForMatchers := "[]*LabelMatcher{"
+	for _, f := range this.Matchers {
+		repeatedStringForMatchers += strings.Replace(f.String(), "LabelMatcher", "LabelMatcher", 1) + ","
+	}
+	repeatedStringForMatchers += "}"
+	s := strings.Join([]string{`&ActiveSeriesRequest{`,
+		`Matchers:` + repeatedStringForMatchers + `,`,
+		`}`,
+	}, "")
+	return s
+}
+func (this *ActiveSeriesResponse) String() string {
+	if this == nil {
+		return "nil"
+	}
+	repeatedStringForMetric := "[]*Metric{"
+	for _, f := range this.Metric {
+		repeatedStringForMetric += strings.Replace(fmt.Sprintf("%v", f), "Metric", "mimirpb.Metric", 1) + ","
+	}
+	repeatedStringForMetric += "}"
+	s := strings.Join([]string{`&ActiveSeriesResponse{`,
+		`Metric:` + repeatedStringForMetric + `,`,
+		`}`,
+	}, "")
+	return s
+}
 func (this *ReadRequest) String() string {
 	if this == nil {
 		return "nil"
@@ -7156,6 +8272,640 @@ func (m *LabelValueSeriesCount) Unmarshal(dAtA []byte) error {
 	}
 	return nil
 }
+func (m *TSDBStatusRequest) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowIngester
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: TSDBStatusRequest: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: TSDBStatusRequest: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Limit", wireType)
+			}
+			m.Limit = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowIngester
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Limit |= int32(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		default:
+			iNdEx = preIndex
+			skippy, err := skipIngester(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthIngester
+			}
+			if (iNdEx + skippy) < 0 {
+				return ErrInvalidLengthIngester
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *TSDBStatusResponse) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowIngester
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: TSDBStatusResponse: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: TSDBStatusResponse: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field NumSeries", wireType)
+			}
+			m.NumSeries = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowIngester
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.NumSeries |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 2:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field NumLabelPairs", wireType)
+			}
+			m.NumLabelPairs = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowIngester
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.NumLabelPairs |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 3:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field ChunkCount", wireType)
+			}
+			m.ChunkCount = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowIngester
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.ChunkCount |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 4:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field MinTime", wireType)
+			}
+			m.MinTime = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowIngester
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.MinTime |= int64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 5:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field MaxTime", wireType)
+			}
+			m.MaxTime = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowIngester
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.MaxTime |= int64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 6:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field SeriesCountByMetricName", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowIngester
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthIngester
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthIngester
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.SeriesCountByMetricName = append(m.SeriesCountByMetricName, TSDBStatisticItem{})
+			if err := m.SeriesCountByMetricName[len(m.SeriesCountByMetricName)-1].Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 7:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field LabelValueCountByLabelName", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowIngester
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthIngester
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthIngester
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.LabelValueCountByLabelName = append(m.LabelValueCountByLabelName, TSDBStatisticItem{})
+			if err := m.LabelValueCountByLabelName[len(m.LabelValueCountByLabelName)-1].Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 8:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field MemoryInBytesByLabelName", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowIngester
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthIngester
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthIngester
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.MemoryInBytesByLabelName = append(m.MemoryInBytesByLabelName, TSDBStatisticItem{})
+			if err := m.MemoryInBytesByLabelName[len(m.MemoryInBytesByLabelName)-1].Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 9:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field SeriesCountByLabelValuePair", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowIngester
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthIngester
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthIngester
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.SeriesCountByLabelValuePair = append(m.SeriesCountByLabelValuePair, TSDBStatisticItem{})
+			if err := m.SeriesCountByLabelValuePair[len(m.SeriesCountByLabelValuePair)-1].Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipIngester(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthIngester
+			}
+			if (iNdEx + skippy) < 0 {
+				return ErrInvalidLengthIngester
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *TSDBStatisticItem) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowIngester
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: TSDBStatisticItem: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: TSDBStatisticItem: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Name", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowIngester
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthIngester
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthIngester
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Name = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 2:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Value", wireType)
+			}
+			m.Value = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowIngester
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Value |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		default:
+			iNdEx = preIndex
+			skippy, err := skipIngester(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthIngester
+			}
+			if (iNdEx + skippy) < 0 {
+				return ErrInvalidLengthIngester
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *ActiveSeriesRequest) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowIngester
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: ActiveSeriesRequest: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: ActiveSeriesRequest: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Matchers", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowIngester
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthIngester
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthIngester
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Matchers = append(m.Matchers, &LabelMatcher{})
+			if err := m.Matchers[len(m.Matchers)-1].Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipIngester(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthIngester
+			}
+			if (iNdEx + skippy) < 0 {
+				return ErrInvalidLengthIngester
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *ActiveSeriesResponse) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowIngester
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: ActiveSeriesResponse: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: ActiveSeriesResponse: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Metric", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowIngester
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthIngester
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthIngester
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Metric = append(m.Metric, &mimirpb.Metric{})
+			if err := m.Metric[len(m.Metric)-1].Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipIngester(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthIngester
+			}
+			if (iNdEx + skippy) < 0 {
+				return ErrInvalidLengthIngester
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
 func (m *ReadRequest) Unmarshal(dAtA []byte) error {
 	l := len(dAtA)
 	iNdEx := 0
@@ -36,6 +36,14 @@ func makeMetricLimitError(errorType string, labels labels.Labels, err error) err
 	}
 }
 
+func makeRateLimitError(errorType string, err error) error {
+	return &validationError{
+		errorType: errorType,
+		err:       err,
+		code:      http.StatusTooManyRequests,
+	}
+}
+
 func (e *validationError) Error() string {
 	if e.err == nil {
 		return e.errorType
@@ -0,0 +1,146 @@
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package ingester
+
+import (
+	"sync"
+	"time"
+
+	"github.com/prometheus/common/model"
+	"github.com/segmentio/fasthash/fnv1a"
+
+	"github.com/grafana/mimir/pkg/mimirpb"
+)
+
+const numHAReplicaTrackerShards = 128
+
+type haReplicaOwner struct {
+	// Hash of the name of the replica whose samples are ingested.
+	replica uint64
+	// Timestamp of the latest sample ingested from the replica.
+	lastTimestampMs int64
+
+	// Samples of another replica newer than the latest ingested sample, which are ingested if the series fails
+	// over to that replica, so that the failover doesn't leave a gap in the series. They're kept for one replica
+	// at a time, and at most for the failover timeout.
+	pendingReplica uint64
+	pending        []mimirpb.Sample
+}
+
+// prunePending removes the pending samples not newer than the latest ingested sample, and returns their number.
+func (o *haReplicaOwner) prunePending() int {
+	i := 0
+	for i < len(o.pending) && o.pending[i].TimestampMs <= o.lastTimestampMs {
+		i++
+	}
+	o.pending = o.pending[i:]
+	if len(o.pending) == 0 {
+		o.pending = nil
+	}
+	return i
+}
+
+type haReplicaTrackerShard struct {
+	mtx sync.Mutex
+	// Owner of each series, keyed by series labels hash.
+	m map[uint64]*haReplicaOwner
+}
+
+// haReplicaTracker keeps track, for each in-memory series of a tenant ingested in the ingester HA deduplication mode,
+// of the HA replica whose samples are ingested. The samples of the other replicas are deduplicated, unless they're
+// newer than the latest sample of the owner by more than the failover timeout, in which case the series fails over to
+// their replica. The samples of the other replica newer than the latest sample of the owner are held until then, and
+// ingested on failover. Samples older than the latest sample of the owner are deduplicated rather than rejected as
+// out-of-order, since they're expected to be sent by the replicas lagging behind.
+type haReplicaTracker struct {
+	shards []haReplicaTrackerShard
+}
+
+func newHAReplicaTracker() *haReplicaTracker {
+	shards := make([]haReplicaTrackerShard, 0, numHAReplicaTrackerShards)
+	for i := 0; i < numHAReplicaTrackerShards; i++ {
+		shards = append(shards, haReplicaTrackerShard{
+			m: map[uint64]*haReplicaOwner{},
+		})
+	}
+	return &haReplicaTracker{shards: shards}
+}
+
+func (t *haReplicaTracker) getShard(seriesHash uint64) *haReplicaTrackerShard {
+	return &t.shards[hashFP(model.Fingerprint(seriesHash))%numHAReplicaTrackerShards]
+}
+
+// acceptSamples returns the samples of the series with the given labels hash, sent by the given replica,
+// which should be ingested, and the number of samples deduplicated. The samples are expected to be sorted
+// by timestamp, oldest sample first. On failover, the returned samples include the ones previously sent by
+// the replica and held by the tracker.
+func (t *haReplicaTracker) acceptSamples(seriesHash uint64, replica string, samples []mimirpb.Sample, failoverTimeout time.Duration) ([]mimirpb.Sample, int) {
+	if len(samples) == 0 {
+		return samples, 0
+	}
+
+	replicaHash := fnv1a.HashString64(replica)
+	lastTimestampMs := samples[len(samples)-1].TimestampMs
+
+	shard := t.getShard(seriesHash)
+	shard.mtx.Lock()
+	defer shard.mtx.Unlock()
+
+	owner, ok := shard.m[seriesHash]
+	if !ok {
+		shard.m[seriesHash] = &haReplicaOwner{replica: replicaHash, lastTimestampMs: lastTimestampMs}
+		return samples, 0
+	}
+
+	if owner.replica == replicaHash {
+		if lastTimestampMs > owner.lastTimestampMs {
+			owner.lastTimestampMs = lastTimestampMs
+		}
+		return samples, owner.prunePending()
+	}
+
+	// The samples not newer than the latest sample of the owner are always deduplicated.
+	first := 0
+	for first < len(samples) && samples[first].TimestampMs <= owner.lastTimestampMs {
+		first++
+	}
+	deduped := first
+	samples = samples[first:]
+	if len(samples) == 0 {
+		return nil, deduped
+	}
+
+	// The pending samples of another replica are deduplicated, since only one replica can take over.
+	if owner.pendingReplica != replicaHash {
+		deduped += len(owner.pending)
+		owner.pendingReplica = replicaHash
+		owner.pending = nil
+	}
+
+	// Hold the samples until the series fails over, unless they've already been sent. The samples are copied,
+	// since the request they belong to is reused once pushed.
+	for _, s := range samples {
+		if n := len(owner.pending); n > 0 && s.TimestampMs <= owner.pending[n-1].TimestampMs {
+			deduped++
+			continue
+		}
+		owner.pending = append(owner.pending, s)
+	}
+
+	// Fail over to the replica once its latest sample is newer than the latest sample of the owner by more than the timeout.
+	if lastTimestampMs <= owner.lastTimestampMs+failoverTimeout.Milliseconds() {
+		return nil, deduped
+	}
+
+	accepted := owner.pending
+	shard.m[seriesHash] = &haReplicaOwner{replica: replicaHash, lastTimestampMs: accepted[len(accepted)-1].TimestampMs}
+	return accepted, deduped
+}
+
+// removeSeries stops tracking the series with the given labels hash.
+func (t *haReplicaTracker) removeSeries(seriesHash uint64) {
+	shard := t.getShard(seriesHash)
+	shard.mtx.Lock()
+	delete(shard.m, seriesHash)
+	shard.mtx.Unlock()
+}
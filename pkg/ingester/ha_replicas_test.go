@@ -0,0 +1,72 @@
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package ingester
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/grafana/mimir/pkg/mimirpb"
+)
+
+func TestHAReplicaTracker_acceptSamples(t *testing.T) {
+	const (
+		series          = uint64(1)
+		failoverTimeout = 30 * time.Second
+	)
+
+	samplesAt := func(timestampsMs ...int64) []mimirpb.Sample {
+		samples := make([]mimirpb.Sample, 0, len(timestampsMs))
+		for _, ts := range timestampsMs {
+			samples = append(samples, mimirpb.Sample{TimestampMs: ts, Value: float64(ts)})
+		}
+		return samples
+	}
+
+	type result struct {
+		accepted []mimirpb.Sample
+		deduped  int
+	}
+	accept := func(tracker *haReplicaTracker, series uint64, replica string, samples []mimirpb.Sample) result {
+		accepted, deduped := tracker.acceptSamples(series, replica, samples, failoverTimeout)
+		return result{accepted: accepted, deduped: deduped}
+	}
+
+	tracker := newHAReplicaTracker()
+
+	// The first replica pushing a series owns it.
+	assert.Equal(t, result{accepted: samplesAt(10000)}, accept(tracker, series, "replica-1", samplesAt(10000)))
+
+	// The samples of the other replica older than the latest sample of the owner are deduplicated, while the newer
+	// ones are held until the owner sends newer samples.
+	assert.Equal(t, result{deduped: 1}, accept(tracker, series, "replica-2", samplesAt(5000)))
+	assert.Equal(t, result{}, accept(tracker, series, "replica-2", samplesAt(17000)))
+
+	// The samples of the owner are always accepted, and the held samples of the other replica are deduplicated.
+	assert.Equal(t, result{accepted: samplesAt(25000, 40000), deduped: 1}, accept(tracker, series, "replica-1", samplesAt(25000, 40000)))
+	assert.Equal(t, result{accepted: samplesAt(30000)}, accept(tracker, series, "replica-1", samplesAt(30000)))
+
+	// The series fails over to the other replica once its latest sample is newer than the latest sample of the owner
+	// by more than the timeout. All its samples newer than the latest sample of the owner are ingested, including the
+	// held ones, so that there's no gap in the series.
+	assert.Equal(t, result{}, accept(tracker, series, "replica-2", samplesAt(56000)))
+	assert.Equal(t, result{deduped: 1}, accept(tracker, series, "replica-2", samplesAt(56000, 62000)))
+	assert.Equal(t, result{accepted: samplesAt(56000, 62000, 71000, 86000)}, accept(tracker, series, "replica-2", samplesAt(71000, 86000)))
+	assert.Equal(t, result{}, accept(tracker, series, "replica-1", samplesAt(100000)))
+	assert.Equal(t, result{accepted: samplesAt(101000), deduped: 1}, accept(tracker, series, "replica-2", samplesAt(101000)))
+
+	// Only the samples of one other replica are held at a time.
+	assert.Equal(t, result{}, accept(tracker, series, "replica-1", samplesAt(110000)))
+	assert.Equal(t, result{deduped: 1}, accept(tracker, series, "replica-3", samplesAt(115000)))
+	assert.Equal(t, result{accepted: samplesAt(115000, 140000)}, accept(tracker, series, "replica-3", samplesAt(140000)))
+
+	// Other series are tracked independently.
+	assert.Equal(t, result{accepted: samplesAt(100000)}, accept(tracker, series+1, "replica-1", samplesAt(100000)))
+
+	// Once the series is removed, the next replica pushing it owns it.
+	tracker.removeSeries(series)
+	assert.Equal(t, result{accepted: samplesAt(102000)}, accept(tracker, series, "replica-1", samplesAt(102000)))
+	assert.Equal(t, result{}, accept(tracker, series, "replica-2", samplesAt(116000)))
+}
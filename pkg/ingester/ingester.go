@@ -55,6 +55,7 @@ import (
 	"github.com/grafana/mimir/pkg/storage/chunk"
 	"github.com/grafana/mimir/pkg/storage/sharding"
 	mimir_tsdb "github.com/grafana/mimir/pkg/storage/tsdb"
+	"github.com/grafana/mimir/pkg/storage/tsdb/metricmetadata"
 	"github.com/grafana/mimir/pkg/util"
 	"github.com/grafana/mimir/pkg/util/globalerror"
 	util_log "github.com/grafana/mimir/pkg/util/log"
@@ -74,6 +75,9 @@ const (
 	// Period at which to attempt purging metadata from memory.
 	metadataPurgePeriod = 5 * time.Minute
 
+	// Default period at which to persist metadata on disk and to the storage, when enabled.
+	defaultMetadataPersistPeriod = 5 * time.Minute
+
 	// IngesterRingKey is the key under which we store the ingesters ring in the KVStore.
 	IngesterRingKey = "ring"
 
@@ -116,6 +120,11 @@ type Config struct {
 	// Config for metadata purging.
 	MetadataRetainPeriod time.Duration `yaml:"metadata_retain_period" category:"advanced"`
 
+	MetadataPersistenceEnabled bool          `yaml:"metadata_persistence_enabled" category:"experimental"`
+	MetadataPersistencePeriod  time.Duration `yaml:"metadata_persistence_period" category:"experimental"`
+
+	ExemplarsShippingEnabled bool `yaml:"exemplars_shipping_enabled" category:"experimental"`
+
 	RateUpdatePeriod time.Duration `yaml:"rate_update_period" category:"advanced"`
 
 	ActiveSeriesMetricsEnabled      bool                              `yaml:"active_series_metrics_enabled" category:"advanced"`
@@ -133,6 +142,10 @@ type Config struct {
 	DefaultLimits    InstanceLimits         `yaml:"instance_limits"`
 	InstanceLimitsFn func() *InstanceLimits `yaml:"-"`
 
+	// How long the queriers keep querying the ingesters for the samples they've received.
+	// Used to know when an ingester prepared for scale down can be safely removed.
+	QueryIngestersWithin time.Duration `yaml:"-"`
+
 	IgnoreSeriesLimitForMetricNames string `yaml:"ignore_series_limit_for_metric_names" category:"advanced"`
 
 	// For testing, you can override the address and ID of this ingester.
@@ -144,6 +157,9 @@ func (cfg *Config) RegisterFlags(f *flag.FlagSet, logger log.Logger) {
 	cfg.IngesterRing.RegisterFlags(f, logger)
 
 	f.DurationVar(&cfg.MetadataRetainPeriod, "ingester.metadata-retain-period", 10*time.Minute, "Period at which metadata we have not seen will remain in memory before being deleted.")
+	f.BoolVar(&cfg.MetadataPersistenceEnabled, "ingester.metadata-persistence-enabled", false, "Enable the periodic persistence of metric metadata in the tenant's TSDB directory, from where it's restored on restart. When blocks shipping is enabled, the metadata is also uploaded to the storage, where it's merged by the compactor and can be queried from the store-gateways. The metadata isn't written to the TSDB WAL, so the metadata received since it's been last persisted is lost if the ingester crashes.")
+	f.DurationVar(&cfg.MetadataPersistencePeriod, "ingester.metadata-persistence-period", defaultMetadataPersistPeriod, "Period at which the metric metadata is persisted, when enabled. A shorter period reduces the metadata lost if the ingester crashes, at the cost of more frequent writes to the disk and uploads to the storage.")
+	f.BoolVar(&cfg.ExemplarsShippingEnabled, "ingester.exemplars-shipping-enabled", false, "Upload the in-memory exemplars in the time range of each block alongside the block, when shipping it to the storage. Exemplars evicted from memory before the block is shipped are not uploaded.")
 
 	f.DurationVar(&cfg.RateUpdatePeriod, "ingester.rate-update-period", 15*time.Second, "Period with which to update the per-tenant ingestion rates.")
 	f.BoolVar(&cfg.ActiveSeriesMetricsEnabled, "ingester.active-series-metrics-enabled", true, "Enable tracking of active series and export them as metrics.")
@@ -226,6 +242,13 @@ type Ingester struct {
 	// Rate of pushed samples. Used to limit global samples push rate.
 	ingestionRate        *util_math.EwmaRate
 	inflightPushRequests atomic.Int64
+
+	// Number of push requests received, used to tell when the distributors have stopped sending writes.
+	receivedPushRequests atomic.Int64
+
+	// Progress of the preparation for scale down, nil if it has not been requested.
+	prepareScaleDownMtx sync.Mutex
+	prepareScaleDown    *prepareScaleDownProgress
 }
 
 func newIngester(cfg Config, limits *validation.Overrides, registerer prometheus.Registerer, logger log.Logger) (*Ingester, error) {
@@ -372,6 +395,15 @@ func (i *Ingester) starting(ctx context.Context) error {
 		servs = append(servs, closeIdleService)
 	}
 
+	if i.cfg.MetadataPersistenceEnabled {
+		period := i.cfg.MetadataPersistencePeriod
+		if period <= 0 {
+			period = defaultMetadataPersistPeriod
+		}
+		persistMetadataService := services.NewTimerService(period, nil, i.persistUsersMetadata, nil)
+		servs = append(servs, persistMetadataService)
+	}
+
 	var err error
 	i.subservices, err = services.NewManager(servs...)
 	if err == nil {
@@ -396,6 +428,11 @@ func (i *Ingester) stopping(_ error) error {
 		level.Warn(i.logger).Log("msg", "failed to stop ingester subservices", "err", err)
 	}
 
+	// Persist the metadata received since the last periodic persistence, so that it's restored on restart.
+	if i.cfg.MetadataPersistenceEnabled {
+		_ = i.persistUsersMetadata(context.Background())
+	}
+
 	// Next initiate our graceful exit from the ring.
 	if err := services.StopAndAwaitTerminated(context.Background(), i.lifecycler); err != nil {
 		level.Warn(i.logger).Log("msg", "failed to stop ingester lifecycler", "err", err)
@@ -554,6 +591,7 @@ func (i *Ingester) PushWithCleanup(ctx context.Context, req *mimirpb.WriteReques
 	// We will report *this* request in the error too.
 	inflight := i.inflightPushRequests.Inc()
 	defer i.inflightPushRequests.Dec()
+	i.receivedPushRequests.Inc()
 
 	il := i.getInstanceLimits()
 	if il != nil && il.MaxInflightPushRequests > 0 {
@@ -614,6 +652,11 @@ func (i *Ingester) PushWithCleanup(ctx context.Context, req *mimirpb.WriteReques
 		newValueForTimestampCount = 0
 		perUserSeriesLimitCount   = 0
 		perMetricSeriesLimitCount = 0
+		newSeriesRateLimitedCount = 0
+
+		perLabelNameValuesLimitCount  = 0
+		perLabelValueSeriesLimitCount = 0
+		dedupedSamplesCount           = 0
 
 		minAppendTime, minAppendTimeAvailable = db.Head().AppendableMinValidTime()
 
@@ -633,21 +676,35 @@ func (i *Ingester) PushWithCleanup(ctx context.Context, req *mimirpb.WriteReques
 	}
 
 	oooTW := i.limits.OutOfOrderTimeWindow(userID)
+	haFailoverTimeout := i.limits.HADeduplicationFailoverTimeout(userID)
 	for _, ts := range req.Timeseries {
 		// The labels must be sorted (in our case, it's guaranteed a write request
 		// has sorted labels once hit the ingester).
 
+		// In the ingester HA deduplication mode, only the samples of the replica owning the series are ingested.
+		samples := ts.Samples
+		if req.HaReplica != "" && len(samples) > 0 {
+			var deduped int
+			samples, deduped = db.haReplicas.acceptSamples(mimirpb.FromLabelAdaptersToLabels(ts.Labels).Hash(), req.HaReplica, samples, haFailoverTimeout)
+			dedupedSamplesCount += deduped
+
+			// The exemplars of the deduplicated replica are dropped too.
+			if len(samples) == 0 {
+				continue
+			}
+		}
+
 		// Fast path in case we only have samples and they are all out of bound
 		// and out-of-order support is not enabled.
 		// TODO(jesus.vazquez) If we had too many old samples we might want to
 		// extend the fast path to fail early.
 		if oooTW <= 0 && minAppendTimeAvailable &&
-			len(ts.Samples) > 0 && len(ts.Exemplars) == 0 && allOutOfBounds(ts.Samples, minAppendTime) {
-			failedSamplesCount += len(ts.Samples)
-			sampleOutOfBoundsCount += len(ts.Samples)
+			len(samples) > 0 && len(ts.Exemplars) == 0 && allOutOfBounds(samples, minAppendTime) {
+			failedSamplesCount += len(samples)
+			sampleOutOfBoundsCount += len(samples)
 
 			updateFirstPartial(func() error {
-				return newIngestErrSampleTimestampTooOld(model.Time(ts.Samples[0].TimestampMs), ts.Labels)
+				return newIngestErrSampleTimestampTooOld(model.Time(samples[0].TimestampMs), ts.Labels)
 			})
 			continue
 		}
@@ -658,7 +715,7 @@ func (i *Ingester) PushWithCleanup(ctx context.Context, req *mimirpb.WriteReques
 		// To find out if any sample was added to this series, we keep old value.
 		oldSucceededSamplesCount := succeededSamplesCount
 
-		for _, s := range ts.Samples {
+		for _, s := range samples {
 			var err error
 
 			// If the cached reference exists, we try to use it.
@@ -720,6 +777,26 @@ func (i *Ingester) PushWithCleanup(ctx context.Context, req *mimirpb.WriteReques
 					return makeMetricLimitError(perMetricSeriesLimit, copiedLabels, i.limiter.FormatError(userID, cause))
 				})
 				continue
+
+			case errNewSeriesRateLimitExceeded:
+				newSeriesRateLimitedCount++
+				updateFirstPartial(func() error { return makeRateLimitError(newSeriesRateLimited, i.limiter.FormatError(userID, cause)) })
+				continue
+			}
+
+			if labelErr, ok := errors.Cause(err).(*labelLimitError); ok {
+				if labelErr.err == errMaxLabelValuesPerLabelNameLimitExceeded {
+					perLabelNameValuesLimitCount++
+					updateFirstPartial(func() error {
+						return makeMetricLimitError(perLabelNameValuesLimit, copiedLabels, i.limiter.FormatError(userID, labelErr))
+					})
+				} else {
+					perLabelValueSeriesLimitCount++
+					updateFirstPartial(func() error {
+						return makeMetricLimitError(perLabelValueSeriesLimit, copiedLabels, i.limiter.FormatError(userID, labelErr))
+					})
+				}
+				continue
 			}
 
 			// The error looks an issue on our side, so we should rollback
@@ -817,6 +894,18 @@ func (i *Ingester) PushWithCleanup(ctx context.Context, req *mimirpb.WriteReques
 	if perMetricSeriesLimitCount > 0 {
 		validation.DiscardedSamples.WithLabelValues(perMetricSeriesLimit, userID).Add(float64(perMetricSeriesLimitCount))
 	}
+	if newSeriesRateLimitedCount > 0 {
+		validation.DiscardedSamples.WithLabelValues(newSeriesRateLimited, userID).Add(float64(newSeriesRateLimitedCount))
+	}
+	if perLabelNameValuesLimitCount > 0 {
+		validation.DiscardedSamples.WithLabelValues(perLabelNameValuesLimit, userID).Add(float64(perLabelNameValuesLimitCount))
+	}
+	if perLabelValueSeriesLimitCount > 0 {
+		validation.DiscardedSamples.WithLabelValues(perLabelValueSeriesLimit, userID).Add(float64(perLabelValueSeriesLimitCount))
+	}
+	if dedupedSamplesCount > 0 {
+		i.metrics.dedupedSamples.WithLabelValues(userID).Add(float64(dedupedSamplesCount))
+	}
 	if succeededSamplesCount > 0 {
 		i.ingestionRate.Add(int64(succeededSamplesCount))
 
@@ -1140,6 +1229,97 @@ func (i *Ingester) LabelValuesCardinality(req *client.LabelValuesCardinalityRequ
 	)
 }
 
+// TSDBStatus implements client.IngesterServer.
+func (i *Ingester) TSDBStatus(ctx context.Context, req *client.TSDBStatusRequest) (*client.TSDBStatusResponse, error) {
+	if err := i.checkRunning(); err != nil {
+		return nil, err
+	}
+	userID, err := tenant.TenantID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	db := i.getTSDB(userID)
+	if db == nil {
+		return &client.TSDBStatusResponse{}, nil
+	}
+
+	limit := int(req.GetLimit())
+	return db.tsdbStatus.get(time.Now(), limit, func() (*client.TSDBStatusResponse, error) {
+		idx, err := db.Head().Index()
+		if err != nil {
+			return nil, err
+		}
+		defer idx.Close()
+
+		resp, err := tsdbStatus(ctx, idx, limit)
+		if err != nil {
+			return nil, err
+		}
+
+		// The time range of an empty head is not meaningful.
+		if resp.NumSeries > 0 {
+			resp.MinTime = db.Head().MinTime()
+			resp.MaxTime = db.Head().MaxTime()
+		}
+		return resp, nil
+	})
+}
+
+// activeSeriesTargetSizeBytes is the target size of the messages streamed by ActiveSeries.
+// We arbitrarily set it to 1mb to avoid reaching the actual gRPC default limit (4mb).
+const activeSeriesTargetSizeBytes = 1 * 1024 * 1024
+
+// ActiveSeries implements client.IngesterServer.
+func (i *Ingester) ActiveSeries(req *client.ActiveSeriesRequest, srv client.Ingester_ActiveSeriesServer) error {
+	if err := i.checkRunning(); err != nil {
+		return err
+	}
+	if !i.cfg.ActiveSeriesMetricsEnabled {
+		return errors.New("active series tracking is disabled (-ingester.active-series-metrics-enabled=false)")
+	}
+	userID, err := tenant.TenantID(srv.Context())
+	if err != nil {
+		return err
+	}
+
+	matchers, err := client.FromLabelMatchers(req.GetMatchers())
+	if err != nil {
+		return err
+	}
+
+	db := i.getTSDB(userID)
+	if db == nil {
+		return nil
+	}
+
+	resp := &client.ActiveSeriesResponse{}
+	respSize := 0
+
+	for _, series := range db.activeSeries.ActiveWithMatchers(time.Now(), matchers) {
+		resp.Metric = append(resp.Metric, &mimirpb.Metric{Labels: mimirpb.FromLabelsToLabelAdapters(series)})
+		for _, l := range series {
+			respSize += len(l.Name) + len(l.Value)
+		}
+
+		if respSize < activeSeriesTargetSizeBytes {
+			continue
+		}
+		// Flush the response when reached message threshold.
+		if err := client.SendActiveSeriesResponse(srv, resp); err != nil {
+			return err
+		}
+		resp = &client.ActiveSeriesResponse{}
+		respSize = 0
+	}
+
+	// Send response in case there are any pending series.
+	if len(resp.Metric) > 0 {
+		return client.SendActiveSeriesResponse(srv, resp)
+	}
+	return nil
+}
+
 func createUserStats(db *userTSDB) *client.UserStatsResponse {
 	apiRate := db.ingestedAPISamples.Rate()
 	ruleRate := db.ingestedRuleSamples.Rate()
@@ -1472,6 +1652,8 @@ func (i *Ingester) createTSDB(userID string) (*userTSDB, error) {
 		userID:              userID,
 		activeSeries:        activeseries.NewActiveSeries(activeseries.NewMatchers(matchersConfig), i.cfg.ActiveSeriesMetricsIdleTimeout),
 		seriesInMetric:      newMetricCounter(i.limiter, i.cfg.getIgnoreSeriesLimitForMetricNamesMap()),
+		seriesInLabels:      newLabelValueCounter(i.limiter),
+		haReplicas:          newHAReplicaTracker(),
 		ingestedAPISamples:  util_math.NewEWMARate(0.2, i.cfg.RateUpdatePeriod),
 		ingestedRuleSamples: util_math.NewEWMARate(0.2, i.cfg.RateUpdatePeriod),
 
@@ -1526,6 +1708,10 @@ func (i *Ingester) createTSDB(userID string) (*userTSDB, error) {
 	// series during WAL replay.
 	userDB.limiter = i.limiter
 
+	if i.cfg.MetadataPersistenceEnabled {
+		i.restoreUserMetadata(userID, udir, userLogger)
+	}
+
 	if db.Head().NumSeries() > 0 {
 		// If there are series in the head, use max time from head. If this time is too old,
 		// TSDB will be eligible for flushing and closing sooner, unless more data is pushed to it quickly.
@@ -1537,6 +1723,11 @@ func (i *Ingester) createTSDB(userID string) (*userTSDB, error) {
 
 	// Create a new shipper for this database
 	if i.cfg.BlocksStorageConfig.TSDB.IsBlocksShippingEnabled() {
+		var exemplars storage.ExemplarQueryable
+		if i.cfg.ExemplarsShippingEnabled {
+			exemplars = userDB
+		}
+
 		userDB.shipper = NewShipper(
 			userLogger,
 			tsdbPromReg,
@@ -1544,6 +1735,7 @@ func (i *Ingester) createTSDB(userID string) (*userTSDB, error) {
 			bucket.NewUserBucketClient(userID, i.bucket, i.limits),
 			metadata.ReceiveSource,
 			metadata.NoneFunc,
+			exemplars,
 		)
 
 		// Initialise the shipper blocks cache.
@@ -2047,50 +2239,7 @@ func (i *Ingester) FlushHandler(w http.ResponseWriter, r *http.Request) {
 			return
 		}
 
-		compactionCallbackCh := make(chan struct{})
-
-		level.Info(i.logger).Log("msg", "flushing TSDB blocks: triggering compaction")
-		select {
-		case i.forceCompactTrigger <- requestWithUsersAndCallback{users: allowedUsers, callback: compactionCallbackCh}:
-			// Compacting now.
-		case <-ingCtx.Done():
-			level.Warn(i.logger).Log("msg", "failed to compact TSDB blocks, ingester not running anymore")
-			return
-		}
-
-		// Wait until notified about compaction being finished.
-		select {
-		case <-compactionCallbackCh:
-			level.Info(i.logger).Log("msg", "finished compacting TSDB blocks")
-		case <-ingCtx.Done():
-			level.Warn(i.logger).Log("msg", "failed to compact TSDB blocks, ingester not running anymore")
-			return
-		}
-
-		if i.cfg.BlocksStorageConfig.TSDB.IsBlocksShippingEnabled() {
-			shippingCallbackCh := make(chan struct{}) // must be new channel, as compactionCallbackCh is closed now.
-
-			level.Info(i.logger).Log("msg", "flushing TSDB blocks: triggering shipping")
-
-			select {
-			case i.shipTrigger <- requestWithUsersAndCallback{users: allowedUsers, callback: shippingCallbackCh}:
-				// shipping now
-			case <-ingCtx.Done():
-				level.Warn(i.logger).Log("msg", "failed to ship TSDB blocks, ingester not running anymore")
-				return
-			}
-
-			// Wait until shipping finished.
-			select {
-			case <-shippingCallbackCh:
-				level.Info(i.logger).Log("msg", "shipping of TSDB blocks finished")
-			case <-ingCtx.Done():
-				level.Warn(i.logger).Log("msg", "failed to ship TSDB blocks, ingester not running anymore")
-				return
-			}
-		}
-
-		level.Info(i.logger).Log("msg", "flushing TSDB blocks: finished")
+		i.compactAndShipBlocks(ingCtx, allowedUsers)
 	}
 
 	if len(r.Form[waitParam]) > 0 && r.Form[waitParam][0] == "true" {
@@ -2103,6 +2252,56 @@ func (i *Ingester) FlushHandler(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusNoContent)
 }
 
+// compactAndShipBlocks forces the compaction of the TSDB head of the allowed users and, if enabled,
+// ships the resulting blocks to the storage. It returns false if the ingester stopped in the meanwhile.
+func (i *Ingester) compactAndShipBlocks(ingCtx context.Context, allowedUsers *util.AllowedTenants) bool {
+	compactionCallbackCh := make(chan struct{})
+
+	level.Info(i.logger).Log("msg", "flushing TSDB blocks: triggering compaction")
+	select {
+	case i.forceCompactTrigger <- requestWithUsersAndCallback{users: allowedUsers, callback: compactionCallbackCh}:
+		// Compacting now.
+	case <-ingCtx.Done():
+		level.Warn(i.logger).Log("msg", "failed to compact TSDB blocks, ingester not running anymore")
+		return false
+	}
+
+	// Wait until notified about compaction being finished.
+	select {
+	case <-compactionCallbackCh:
+		level.Info(i.logger).Log("msg", "finished compacting TSDB blocks")
+	case <-ingCtx.Done():
+		level.Warn(i.logger).Log("msg", "failed to compact TSDB blocks, ingester not running anymore")
+		return false
+	}
+
+	if i.cfg.BlocksStorageConfig.TSDB.IsBlocksShippingEnabled() {
+		shippingCallbackCh := make(chan struct{}) // must be new channel, as compactionCallbackCh is closed now.
+
+		level.Info(i.logger).Log("msg", "flushing TSDB blocks: triggering shipping")
+
+		select {
+		case i.shipTrigger <- requestWithUsersAndCallback{users: allowedUsers, callback: shippingCallbackCh}:
+			// shipping now
+		case <-ingCtx.Done():
+			level.Warn(i.logger).Log("msg", "failed to ship TSDB blocks, ingester not running anymore")
+			return false
+		}
+
+		// Wait until shipping finished.
+		select {
+		case <-shippingCallbackCh:
+			level.Info(i.logger).Log("msg", "shipping of TSDB blocks finished")
+		case <-ingCtx.Done():
+			level.Warn(i.logger).Log("msg", "failed to ship TSDB blocks, ingester not running anymore")
+			return false
+		}
+	}
+
+	level.Info(i.logger).Log("msg", "flushing TSDB blocks: finished")
+	return true
+}
+
 func newIngestErr(errID globalerror.ID, errMsg string, timestamp model.Time, labels []mimirpb.LabelAdapter) error {
 	return fmt.Errorf("%v. The affected sample has timestamp %s and is from series %s", errID.Message(errMsg), timestamp.Time().UTC().Format(time.RFC3339Nano), mimirpb.FromLabelAdaptersToLabels(labels).String())
 }
@@ -2295,6 +2494,57 @@ func (i *Ingester) purgeUserMetricsMetadata() {
 	}
 }
 
+// persistUsersMetadata writes the metric metadata of each tenant to its TSDB directory and,
+// if blocks shipping is enabled, uploads it to the storage. Errors are logged and never returned,
+// so that a failure doesn't stop the periodic persistence.
+func (i *Ingester) persistUsersMetadata(ctx context.Context) error {
+	now := time.Now()
+
+	// The ship concurrency may be unset when blocks shipping is disabled.
+	_ = concurrency.ForEachUser(ctx, i.getUsersWithMetadata(), util_math.Max(i.cfg.BlocksStorageConfig.TSDB.ShipConcurrency, 1), func(ctx context.Context, userID string) error {
+		metadata := i.getUserMetadata(userID)
+		if metadata == nil {
+			return nil
+		}
+
+		f := metadata.toFile(now)
+
+		// Get the user's DB. If the TSDB has been closed, the metadata is not persisted locally.
+		userDB := i.getTSDB(userID)
+		if userDB != nil {
+			if err := metricmetadata.WriteLocalFile(userDB.db.Dir(), f); err != nil {
+				level.Warn(i.logger).Log("msg", "failed to persist metric metadata on disk", "user", userID, "err", err)
+			}
+		}
+
+		if !i.cfg.BlocksStorageConfig.TSDB.IsBlocksShippingEnabled() || (userDB != nil && userDB.deletionMarkFound.Load()) {
+			return nil
+		}
+
+		if err := metricmetadata.WriteIngesterFile(ctx, i.bucket, userID, i.shipperIngesterID, i.limits, f); err != nil {
+			level.Warn(i.logger).Log("msg", "failed to upload metric metadata to the storage", "user", userID, "err", err)
+		}
+		return nil
+	})
+
+	return nil
+}
+
+// restoreUserMetadata restores the metric metadata of the tenant persisted in the TSDB directory.
+func (i *Ingester) restoreUserMetadata(userID, dir string, logger log.Logger) {
+	f, err := metricmetadata.ReadLocalFile(dir)
+	if err != nil {
+		level.Warn(logger).Log("msg", "failed to read persisted metric metadata", "err", err)
+		return
+	}
+	if f == nil || len(f.Metadata) == 0 {
+		return
+	}
+
+	i.getOrCreateUserMetadata(userID).restore(f, time.Now().Add(-i.cfg.MetadataRetainPeriod))
+	level.Info(logger).Log("msg", "restored persisted metric metadata", "metadata", len(f.Metadata))
+}
+
 // MetricsMetadata returns all the metric metadata of a user.
 func (i *Ingester) MetricsMetadata(ctx context.Context, req *client.MetricsMetadataRequest) (*client.MetricsMetadataResponse, error) {
 	if err := i.checkRunning(); err != nil {
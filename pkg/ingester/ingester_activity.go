@@ -129,6 +129,24 @@ func (i *ActivityTrackerWrapper) LabelValuesCardinality(request *client.LabelVal
 	return i.ing.LabelValuesCardinality(request, server)
 }
 
+func (i *ActivityTrackerWrapper) ActiveSeries(request *client.ActiveSeriesRequest, server client.Ingester_ActiveSeriesServer) error {
+	ix := i.tracker.Insert(func() string {
+		return requestActivity(server.Context(), "Ingester/ActiveSeries", request)
+	})
+	defer i.tracker.Delete(ix)
+
+	return i.ing.ActiveSeries(request, server)
+}
+
+func (i *ActivityTrackerWrapper) TSDBStatus(ctx context.Context, request *client.TSDBStatusRequest) (*client.TSDBStatusResponse, error) {
+	ix := i.tracker.Insert(func() string {
+		return requestActivity(ctx, "Ingester/TSDBStatus", request)
+	})
+	defer i.tracker.Delete(ix)
+
+	return i.ing.TSDBStatus(ctx, request)
+}
+
 func (i *ActivityTrackerWrapper) FlushHandler(w http.ResponseWriter, r *http.Request) {
 	ix := i.tracker.Insert(func() string {
 		return requestActivity(r.Context(), "Ingester/FlushHandler", nil)
@@ -147,6 +165,15 @@ func (i *ActivityTrackerWrapper) ShutdownHandler(w http.ResponseWriter, r *http.
 	i.ing.ShutdownHandler(w, r)
 }
 
+func (i *ActivityTrackerWrapper) PrepareScaleDownHandler(w http.ResponseWriter, r *http.Request) {
+	ix := i.tracker.Insert(func() string {
+		return requestActivity(r.Context(), "Ingester/PrepareScaleDownHandler", nil)
+	})
+	defer i.tracker.Delete(ix)
+
+	i.ing.PrepareScaleDownHandler(w, r)
+}
+
 func requestActivity(ctx context.Context, name string, req interface{}) string {
 	userID, _ := tenant.TenantID(ctx)
 	traceID, _ := tracing.ExtractSampledTraceID(ctx)
@@ -11,6 +11,7 @@ package ingester
 import (
 	"bytes"
 	"context"
+	"encoding/json"
 	"fmt"
 	"io"
 	"io/ioutil"
@@ -58,6 +59,7 @@ import (
 	"github.com/grafana/mimir/pkg/storage/chunk"
 	"github.com/grafana/mimir/pkg/storage/sharding"
 	mimir_tsdb "github.com/grafana/mimir/pkg/storage/tsdb"
+	"github.com/grafana/mimir/pkg/storage/tsdb/metricmetadata"
 	"github.com/grafana/mimir/pkg/util"
 	"github.com/grafana/mimir/pkg/util/chunkcompat"
 	util_math "github.com/grafana/mimir/pkg/util/math"
@@ -1729,6 +1731,139 @@ func TestIngester_LabelValuesCardinality(t *testing.T) {
 	}
 }
 
+func TestIngester_TSDBStatus(t *testing.T) {
+	series := []series{
+		{
+			lbls:      labels.Labels{{Name: labels.MetricName, Value: "metric_0"}, {Name: "status", Value: "500"}},
+			value:     1.5,
+			timestamp: 100000,
+		},
+		{
+			lbls:      labels.Labels{{Name: labels.MetricName, Value: "metric_0"}, {Name: "status", Value: "200"}},
+			value:     1.5,
+			timestamp: 110030,
+		},
+		{
+			lbls:      labels.Labels{{Name: labels.MetricName, Value: "metric_1"}, {Name: "env", Value: "prod"}},
+			value:     1.5,
+			timestamp: 100060,
+		},
+		{
+			lbls:      labels.Labels{{Name: labels.MetricName, Value: "metric_1"}, {Name: "env", Value: "prod"}, {Name: "status", Value: "300"}},
+			value:     1.5,
+			timestamp: 100090,
+		},
+	}
+
+	i := requireActiveIngesterWithBlocksStorage(t, defaultIngesterTestConfig(t), nil)
+
+	// A tenant without series gets empty statistics.
+	res, err := i.TSDBStatus(user.InjectOrgID(context.Background(), "test"), &client.TSDBStatusRequest{Limit: 2})
+	require.NoError(t, err)
+	require.Equal(t, &client.TSDBStatusResponse{}, res)
+
+	ctx := pushSeriesToIngester(t, series, i)
+
+	res, err = i.TSDBStatus(ctx, &client.TSDBStatusRequest{Limit: 2})
+	require.NoError(t, err)
+	require.Equal(t, &client.TSDBStatusResponse{
+		NumSeries:     4,
+		NumLabelPairs: 6,
+		ChunkCount:    4,
+		MinTime:       100000,
+		MaxTime:       110030,
+		SeriesCountByMetricName: []client.TSDBStatisticItem{
+			{Name: "metric_0", Value: 2},
+			{Name: "metric_1", Value: 2},
+		},
+		LabelValueCountByLabelName: []client.TSDBStatisticItem{
+			{Name: "status", Value: 3},
+			{Name: labels.MetricName, Value: 2},
+		},
+		MemoryInBytesByLabelName: []client.TSDBStatisticItem{
+			{Name: labels.MetricName, Value: 16},
+			{Name: "status", Value: 9},
+		},
+		SeriesCountByLabelValuePair: []client.TSDBStatisticItem{
+			{Name: "__name__=metric_0", Value: 2},
+			{Name: "__name__=metric_1", Value: 2},
+		},
+	}, res)
+
+	// The statistics are cached, and truncated to the limit of the request.
+	series[0].lbls = labels.Labels{{Name: labels.MetricName, Value: "metric_2"}}
+	pushSeriesToIngester(t, series[:1], i)
+
+	res, err = i.TSDBStatus(ctx, &client.TSDBStatusRequest{Limit: 1})
+	require.NoError(t, err)
+	require.Equal(t, uint64(4), res.NumSeries)
+	require.Equal(t, []client.TSDBStatisticItem{{Name: "metric_0", Value: 2}}, res.SeriesCountByMetricName)
+
+	// A request with a greater limit than the cached statistics computes them again.
+	res, err = i.TSDBStatus(ctx, &client.TSDBStatusRequest{Limit: 3})
+	require.NoError(t, err)
+	require.Equal(t, uint64(5), res.NumSeries)
+	require.Equal(t, []client.TSDBStatisticItem{
+		{Name: "metric_0", Value: 2},
+		{Name: "metric_1", Value: 2},
+		{Name: "metric_2", Value: 1},
+	}, res.SeriesCountByMetricName)
+}
+
+func TestIngester_ActiveSeries(t *testing.T) {
+	series := []series{
+		{lbls: labels.Labels{{Name: labels.MetricName, Value: "metric_0"}, {Name: "status", Value: "500"}}, value: 1.5, timestamp: 100000},
+		{lbls: labels.Labels{{Name: labels.MetricName, Value: "metric_0"}, {Name: "status", Value: "200"}}, value: 1.5, timestamp: 110030},
+		{lbls: labels.Labels{{Name: labels.MetricName, Value: "metric_1"}, {Name: "env", Value: "prod"}}, value: 1.5, timestamp: 100060},
+	}
+
+	t.Run("should return the active series matching the request matchers", func(t *testing.T) {
+		i := requireActiveIngesterWithBlocksStorage(t, defaultIngesterTestConfig(t), nil)
+
+		// A tenant without series gets no response.
+		s := &mockActiveSeriesServer{context: user.InjectOrgID(context.Background(), "test")}
+		require.NoError(t, i.ActiveSeries(&client.ActiveSeriesRequest{}, s))
+		require.Empty(t, s.SentResponses)
+
+		ctx := pushSeriesToIngester(t, series, i)
+
+		s = &mockActiveSeriesServer{context: ctx}
+		req := &client.ActiveSeriesRequest{Matchers: []*client.LabelMatcher{{Type: client.EQUAL, Name: labels.MetricName, Value: "metric_0"}}}
+		require.NoError(t, i.ActiveSeries(req, s))
+		require.Len(t, s.SentResponses, 1)
+
+		var actual []labels.Labels
+		for _, m := range s.SentResponses[0].Metric {
+			actual = append(actual, mimirpb.FromLabelAdaptersToLabels(m.Labels))
+		}
+		assert.ElementsMatch(t, []labels.Labels{series[0].lbls, series[1].lbls}, actual)
+	})
+
+	t.Run("should return an error if the active series tracking is disabled", func(t *testing.T) {
+		cfg := defaultIngesterTestConfig(t)
+		cfg.ActiveSeriesMetricsEnabled = false
+		i := requireActiveIngesterWithBlocksStorage(t, cfg, nil)
+
+		s := &mockActiveSeriesServer{context: user.InjectOrgID(context.Background(), "test")}
+		require.Error(t, i.ActiveSeries(&client.ActiveSeriesRequest{}, s))
+	})
+}
+
+type mockActiveSeriesServer struct {
+	client.Ingester_ActiveSeriesServer
+	SentResponses []*client.ActiveSeriesResponse
+	context       context.Context
+}
+
+func (m *mockActiveSeriesServer) Send(resp *client.ActiveSeriesResponse) error {
+	m.SentResponses = append(m.SentResponses, resp)
+	return nil
+}
+
+func (m *mockActiveSeriesServer) Context() context.Context {
+	return m.context
+}
+
 func BenchmarkIngester_LabelValuesCardinality(b *testing.B) {
 	var (
 		userID              = "test"
@@ -3654,6 +3789,78 @@ func TestIngester_flushing(t *testing.T) {
 	}
 }
 
+func TestIngester_PrepareScaleDownHandler(t *testing.T) {
+	cfg := defaultIngesterTestConfig(t)
+	cfg.BlocksStorageConfig.TSDB.ShipConcurrency = 1
+	cfg.BlocksStorageConfig.TSDB.ShipInterval = 1 * time.Minute // Long enough to not be reached during the test.
+	cfg.BlocksStorageConfig.BucketStore.SyncInterval = 500 * time.Millisecond
+	cfg.IngesterRing.HeartbeatPeriod = 100 * time.Millisecond
+	cfg.QueryIngestersWithin = time.Second
+
+	reg := prometheus.NewPedanticRegistry()
+	i, err := prepareIngesterWithBlocksStorage(t, cfg, reg)
+	require.NoError(t, err)
+
+	require.NoError(t, services.StartAndAwaitRunning(context.Background(), i))
+	t.Cleanup(func() {
+		_ = services.StopAndAwaitTerminated(context.Background(), i)
+	})
+
+	// Wait until it's healthy
+	test.Poll(t, 1*time.Second, 1, func() interface{} {
+		return i.lifecycler.HealthyInstancesCount()
+	})
+
+	getStatus := func(method string) (int, prepareScaleDownResponse) {
+		recorder := httptest.NewRecorder()
+		i.PrepareScaleDownHandler(recorder, httptest.NewRequest(method, "/ingester/prepare-scale-down", nil))
+
+		res := prepareScaleDownResponse{}
+		if recorder.Code == http.StatusOK {
+			require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &res))
+		}
+		return recorder.Code, res
+	}
+
+	code, res := getStatus(http.MethodGet)
+	require.Equal(t, http.StatusOK, code)
+	require.Equal(t, prepareScaleDownNotRequested, res.Status)
+
+	pushSingleSampleWithMetadata(t, i)
+
+	code, res = getStatus(http.MethodPost)
+	require.Equal(t, http.StatusOK, code)
+	require.Equal(t, prepareScaleDownInProgress, res.Status)
+	require.NotEmpty(t, res.StartedAt)
+
+	// The ingester doesn't receive writes anymore, but it's still queried.
+	require.Equal(t, ring.LEAVING, i.lifecycler.GetState())
+
+	// Requesting the preparation again is a no-op.
+	code, res = getStatus(http.MethodPost)
+	require.Equal(t, http.StatusOK, code)
+	require.NotEqual(t, prepareScaleDownNotRequested, res.Status)
+
+	// The wait time is known once the distributors have stopped sending writes to the ingester.
+	test.Poll(t, 5*time.Second, true, func() interface{} {
+		_, res := getStatus(http.MethodGet)
+		return res.WaitUntil != ""
+	})
+
+	test.Poll(t, 5*time.Second, prepareScaleDownReady, func() interface{} {
+		_, res := getStatus(http.MethodGet)
+		return res.Status
+	})
+
+	verifyCompactedHead(t, i, true)
+	require.NoError(t, testutil.GatherAndCompare(reg, bytes.NewBufferString(`
+		# HELP cortex_ingester_shipper_uploads_total Total number of uploaded TSDB blocks
+		# TYPE cortex_ingester_shipper_uploads_total counter
+		cortex_ingester_shipper_uploads_total 1
+	`), "cortex_ingester_shipper_uploads_total"))
+	require.Equal(t, ring.LEAVING, i.lifecycler.GetState())
+}
+
 func TestIngester_ForFlush(t *testing.T) {
 	cfg := defaultIngesterTestConfig(t)
 	cfg.BlocksStorageConfig.TSDB.ShipConcurrency = 1
@@ -4792,6 +4999,55 @@ func TestIngesterPurgeMetadata(t *testing.T) {
 	}
 }
 
+func TestIngesterPersistMetadata(t *testing.T) {
+	cfg := defaultIngesterTestConfig(t)
+	cfg.MetadataPersistenceEnabled = true
+	cfg.BlocksStorageConfig.TSDB.ShipInterval = 1 * time.Minute // Required to enable shipping.
+	dataDir := t.TempDir()
+
+	ing, err := prepareIngesterWithBlocksStorageAndLimits(t, cfg, defaultLimitsTestConfig(), dataDir, nil)
+	require.NoError(t, err)
+	require.NoError(t, services.StartAndAwaitRunning(context.Background(), ing))
+
+	// Wait until the ingester is healthy
+	test.Poll(t, 100*time.Millisecond, 1, func() interface{} {
+		return ing.lifecycler.HealthyInstancesCount()
+	})
+
+	// Push samples too, in order to open the tenants TSDB.
+	pushTestSamples(t, ing, 1, 1, 0)
+	userIDs, testData := pushTestMetadata(t, ing, 10, 3)
+
+	// The metadata is persisted when the ingester is stopped.
+	require.NoError(t, services.StopAndAwaitTerminated(context.Background(), ing))
+
+	for _, userID := range userIDs {
+		f, err := metricmetadata.ReadLocalFile(filepath.Join(dataDir, userID))
+		require.NoError(t, err)
+		require.NotNil(t, f)
+		assert.ElementsMatch(t, testData[userID], f.MetricMetadata())
+
+		files, err := metricmetadata.ReadIngesterFiles(context.Background(), ing.bucket, userID, nil, log.NewNopLogger())
+		require.NoError(t, err)
+		require.Contains(t, files, metricmetadata.IngesterFilePath(ing.shipperIngesterID))
+		assert.ElementsMatch(t, testData[userID], files[metricmetadata.IngesterFilePath(ing.shipperIngesterID)].MetricMetadata())
+	}
+
+	// Restart the ingester on the same data directory, and check the metadata has been restored.
+	ing, err = prepareIngesterWithBlocksStorageAndLimits(t, cfg, defaultLimitsTestConfig(), dataDir, nil)
+	require.NoError(t, err)
+	require.NoError(t, services.StartAndAwaitRunning(context.Background(), ing))
+	defer services.StopAndAwaitTerminated(context.Background(), ing) //nolint:errcheck
+
+	for _, userID := range userIDs {
+		ctx := user.InjectOrgID(context.Background(), userID)
+
+		resp, err := ing.MetricsMetadata(ctx, nil)
+		require.NoError(t, err)
+		assert.ElementsMatch(t, testData[userID], resp.GetMetadata())
+	}
+}
+
 func TestIngesterMetadataMetrics(t *testing.T) {
 	reg := prometheus.NewPedanticRegistry()
 	cfg := defaultIngesterTestConfig(t)
@@ -5151,6 +5407,170 @@ func TestIngesterMetricLimitExceeded(t *testing.T) {
 	testLimits()
 }
 
+func TestIngesterLabelLimitsExceeded(t *testing.T) {
+	tests := map[string]struct {
+		maxLabelValuesPerLabelName int
+		maxSeriesPerLabelValue     int
+		expectedReason             string
+		expectedErr                error
+	}{
+		"per-label-name values limit": {
+			maxLabelValuesPerLabelName: 2,
+			expectedReason:             perLabelNameValuesLimit,
+			expectedErr:                newLabelLimitError(errMaxLabelValuesPerLabelNameLimitExceeded, labels.Label{Name: "pod", Value: "pod-3"}),
+		},
+		"per-label-value series limit": {
+			maxSeriesPerLabelValue: 2,
+			expectedReason:         perLabelValueSeriesLimit,
+			expectedErr:            newLabelLimitError(errMaxSeriesPerLabelValueLimitExceeded, labels.Label{Name: "job", Value: "app"}),
+		},
+	}
+
+	for testName, testData := range tests {
+		t.Run(testName, func(t *testing.T) {
+			limits := defaultLimitsTestConfig()
+			limits.MaxGlobalLabelValuesPerLabelName = testData.maxLabelValuesPerLabelName
+			limits.MaxGlobalSeriesPerLabelValue = testData.maxSeriesPerLabelValue
+
+			cfg := defaultIngesterTestConfig(t)
+			// Set RF=1 here to ensure the limits are not multiplied by the replication factor.
+			cfg.IngesterRing.ReplicationFactor = 1
+			ing, err := prepareIngesterWithBlocksStorageAndLimits(t, cfg, limits, "", nil)
+			require.NoError(t, err)
+			require.NoError(t, services.StartAndAwaitRunning(context.Background(), ing))
+			defer services.StopAndAwaitTerminated(context.Background(), ing) //nolint:errcheck
+
+			// Wait until it's healthy
+			test.Poll(t, time.Second, 1, func() interface{} {
+				return ing.lifecycler.HealthyInstancesCount()
+			})
+
+			userID := "1"
+			ctx := user.InjectOrgID(context.Background(), userID)
+			series1 := labels.FromStrings(labels.MetricName, "testmetric", "job", "app", "pod", "pod-1")
+			series2 := labels.FromStrings(labels.MetricName, "othermetric", "job", "app", "pod", "pod-2")
+			series3 := labels.FromStrings(labels.MetricName, "testmetric", "job", "app", "pod", "pod-3")
+
+			// The first two series are within the limits.
+			_, err = ing.Push(ctx, mimirpb.ToWriteRequest([]labels.Labels{series1, series2}, []mimirpb.Sample{{TimestampMs: 0, Value: 1}, {TimestampMs: 0, Value: 2}}, nil, nil, mimirpb.API))
+			require.NoError(t, err)
+
+			// The third series exceeds the limit, while the samples of the existing series are still ingested.
+			_, err = ing.Push(ctx, mimirpb.ToWriteRequest([]labels.Labels{series1, series3}, []mimirpb.Sample{{TimestampMs: 1, Value: 3}, {TimestampMs: 1, Value: 4}}, nil, nil, mimirpb.API))
+			httpResp, ok := httpgrpc.HTTPResponseFromError(err)
+			require.True(t, ok, "returned error is not an httpgrpc response")
+			assert.Equal(t, http.StatusBadRequest, int(httpResp.Code))
+			assert.Equal(t, wrapWithUser(makeMetricLimitError(testData.expectedReason, series3, ing.limiter.FormatError(userID, testData.expectedErr)), userID).Error(), string(httpResp.Body))
+
+			assert.Equal(t, float64(1), testutil.ToFloat64(validation.DiscardedSamples.WithLabelValues(testData.expectedReason, userID)))
+			validation.DiscardedSamples.DeleteLabelValues(testData.expectedReason, userID)
+
+			res, _, err := runTestQuery(ctx, t, ing, labels.MatchEqual, "job", "app")
+			require.NoError(t, err)
+			require.Len(t, res, 2)
+			assert.Len(t, res[1].Values, 2)
+
+			// Once the series are removed from memory, the limits are no longer exceeded.
+			db := ing.getTSDB(userID)
+			db.PostDeletion(series1, series2)
+			require.NoError(t, db.PreCreation(series3))
+		})
+	}
+}
+
+func TestIngesterNewSeriesRateLimitExceeded(t *testing.T) {
+	limits := defaultLimitsTestConfig()
+	// A very low rate, so that no token is replenished during the test.
+	limits.NewSeriesRate = 0.001
+	limits.NewSeriesBurstSize = 2
+
+	cfg := defaultIngesterTestConfig(t)
+	// Set RF=1 here to ensure the limits are not multiplied by the replication factor.
+	cfg.IngesterRing.ReplicationFactor = 1
+	ing, err := prepareIngesterWithBlocksStorageAndLimits(t, cfg, limits, "", nil)
+	require.NoError(t, err)
+	require.NoError(t, services.StartAndAwaitRunning(context.Background(), ing))
+	defer services.StopAndAwaitTerminated(context.Background(), ing) //nolint:errcheck
+
+	// Wait until it's healthy
+	test.Poll(t, time.Second, 1, func() interface{} {
+		return ing.lifecycler.HealthyInstancesCount()
+	})
+
+	userID := "1"
+	ctx := user.InjectOrgID(context.Background(), userID)
+	series1 := labels.FromStrings(labels.MetricName, "testmetric", "pod", "pod-1")
+	series2 := labels.FromStrings(labels.MetricName, "testmetric", "pod", "pod-2")
+	series3 := labels.FromStrings(labels.MetricName, "testmetric", "pod", "pod-3")
+
+	// The first two series are within the burst.
+	_, err = ing.Push(ctx, mimirpb.ToWriteRequest([]labels.Labels{series1, series2}, []mimirpb.Sample{{TimestampMs: 0, Value: 1}, {TimestampMs: 0, Value: 2}}, nil, nil, mimirpb.API))
+	require.NoError(t, err)
+
+	// The third series exceeds the rate limit, while the samples of the existing series are still ingested.
+	_, err = ing.Push(ctx, mimirpb.ToWriteRequest([]labels.Labels{series1, series3}, []mimirpb.Sample{{TimestampMs: 1, Value: 3}, {TimestampMs: 1, Value: 4}}, nil, nil, mimirpb.API))
+	httpResp, ok := httpgrpc.HTTPResponseFromError(err)
+	require.True(t, ok, "returned error is not an httpgrpc response")
+	assert.Equal(t, http.StatusTooManyRequests, int(httpResp.Code))
+	assert.Equal(t, wrapWithUser(makeRateLimitError(newSeriesRateLimited, ing.limiter.FormatError(userID, errNewSeriesRateLimitExceeded)), userID).Error(), string(httpResp.Body))
+
+	assert.Equal(t, float64(1), testutil.ToFloat64(validation.DiscardedSamples.WithLabelValues(newSeriesRateLimited, userID)))
+	validation.DiscardedSamples.DeleteLabelValues(newSeriesRateLimited, userID)
+
+	res, _, err := runTestQuery(ctx, t, ing, labels.MatchEqual, labels.MetricName, "testmetric")
+	require.NoError(t, err)
+	require.Len(t, res, 2)
+	assert.Len(t, res[0].Values, 2)
+}
+
+func TestIngester_Push_HADeduplication(t *testing.T) {
+	limits := defaultLimitsTestConfig()
+	limits.HADeduplicationFailoverTimeout = model.Duration(30 * time.Second)
+
+	cfg := defaultIngesterTestConfig(t)
+	ing, err := prepareIngesterWithBlocksStorageAndLimits(t, cfg, limits, "", nil)
+	require.NoError(t, err)
+	require.NoError(t, services.StartAndAwaitRunning(context.Background(), ing))
+	defer services.StopAndAwaitTerminated(context.Background(), ing) //nolint:errcheck
+
+	// Wait until it's healthy
+	test.Poll(t, time.Second, 1, func() interface{} {
+		return ing.lifecycler.HealthyInstancesCount()
+	})
+
+	userID := "1"
+	ctx := user.InjectOrgID(context.Background(), userID)
+	series := labels.FromStrings(labels.MetricName, "testmetric", "cluster", "cluster-1")
+
+	push := func(replica string, timestampMs int64) {
+		req := mimirpb.ToWriteRequest([]labels.Labels{series}, []mimirpb.Sample{{TimestampMs: timestampMs, Value: float64(timestampMs)}}, nil, nil, mimirpb.API)
+		req.HaReplica = replica
+		_, err := ing.Push(ctx, req)
+		require.NoError(t, err)
+	}
+
+	push("replica-1", 10000)
+	push("replica-2", 5000)  // Deduplicated rather than rejected as out-of-order.
+	push("replica-2", 17000) // Held, and deduplicated once replica-1 sends a newer sample.
+	push("replica-1", 25000)
+	push("replica-2", 40000) // Held until the failover.
+	push("replica-2", 62000) // Replica-1 stopped, fail over to replica-2 ingesting the held samples too.
+	push("replica-1", 70000) // Held, and deduplicated once replica-2 sends a newer sample.
+	push("replica-2", 77000)
+
+	res, _, err := runTestQuery(ctx, t, ing, labels.MatchEqual, model.MetricNameLabel, "testmetric")
+	require.NoError(t, err)
+	require.Len(t, res, 1)
+
+	var timestamps []model.Time
+	for _, v := range res[0].Values {
+		timestamps = append(timestamps, v.Timestamp)
+	}
+	assert.Equal(t, []model.Time{10000, 25000, 40000, 62000, 77000}, timestamps)
+	assert.Equal(t, float64(3), testutil.ToFloat64(ing.metrics.dedupedSamples.WithLabelValues(userID)))
+	assert.Equal(t, float64(0), testutil.ToFloat64(ing.metrics.ingestedSamplesFail.WithLabelValues(userID)))
+}
+
 // Construct a set of realistic-looking samples, all with slightly different label sets
 func benchmarkData(nSeries int) (allLabels []labels.Labels, allSamples []mimirpb.Sample) {
 	// Real example from Kubernetes' embedded cAdvisor metrics, lightly obfuscated.
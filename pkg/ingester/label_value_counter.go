@@ -0,0 +1,254 @@
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package ingester
+
+import (
+	"sync"
+
+	"github.com/pkg/errors"
+	"github.com/prometheus/common/model"
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/prometheus/prometheus/tsdb"
+	"github.com/segmentio/fasthash/fnv1a"
+)
+
+// DiscardedSamples metric labels
+const (
+	perLabelNameValuesLimit  = "per_label_name_values_limit"
+	perLabelValueSeriesLimit = "per_label_value_series_limit"
+)
+
+const numLabelValueCounterShards = 128
+
+type labelValueCounterShard struct {
+	mtx sync.Mutex
+	// Number of series per label value, keyed by label name.
+	m map[string]map[string]int
+}
+
+// labelValueCounter keeps track of the number of in-memory series of a tenant for each label value,
+// to enforce the per-label cardinality limits. The metric name is tracked by metricCounter instead.
+//
+// The series are only tracked while the per-label limits are enabled for the tenant. When the limits
+// get enabled, the counters are rebuilt from the TSDB head index, so that the series created before
+// (or while replaying the WAL) are accounted for.
+type labelValueCounter struct {
+	limiter *Limiter
+	shards  []labelValueCounterShard
+
+	// Protects tracking, and prevents the series from being tracked while the counters are rebuilt.
+	trackingMtx sync.RWMutex
+	tracking    bool
+}
+
+func newLabelValueCounter(limiter *Limiter) *labelValueCounter {
+	shards := make([]labelValueCounterShard, 0, numLabelValueCounterShards)
+	for i := 0; i < numLabelValueCounterShards; i++ {
+		shards = append(shards, labelValueCounterShard{
+			m: map[string]map[string]int{},
+		})
+	}
+	return &labelValueCounter{
+		limiter: limiter,
+		shards:  shards,
+	}
+}
+
+func (c *labelValueCounter) getShard(labelName string) *labelValueCounterShard {
+	return &c.shards[hashFP(model.Fingerprint(fnv1a.HashString64(labelName)))%numLabelValueCounterShards]
+}
+
+// canAddSeriesFor returns an error naming the first label of the series in input which would exceed
+// a per-label limit if the series was added. It starts (or stops) tracking the series when the per-label
+// limits get enabled (or disabled) for the user, rebuilding the counters from the index of the head.
+func (c *labelValueCounter) canAddSeriesFor(userID string, series labels.Labels, head *tsdb.Head) error {
+	if !c.limiter.hasLabelLimits(userID) {
+		c.stopTracking()
+		return nil
+	}
+
+	if err := c.startTracking(head); err != nil {
+		return err
+	}
+
+	for _, l := range series {
+		if l.Name == labels.MetricName {
+			continue
+		}
+
+		shard := c.getShard(l.Name)
+		shard.mtx.Lock()
+		values := shard.m[l.Name]
+		numSeries, ok := values[l.Value]
+		numValues := len(values)
+		shard.mtx.Unlock()
+
+		if !ok {
+			if err := c.limiter.AssertMaxLabelValuesPerLabelName(userID, l, numValues); err != nil {
+				return err
+			}
+		}
+		if err := c.limiter.AssertMaxSeriesPerLabelValue(userID, l, numSeries); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// startTracking rebuilds the counters from the index of the head, unless the series are already tracked.
+// The series being created concurrently, but not yet in the index, may be missed: they're a negligible
+// fraction of the series of the tenant.
+func (c *labelValueCounter) startTracking(head *tsdb.Head) error {
+	c.trackingMtx.RLock()
+	tracking := c.tracking
+	c.trackingMtx.RUnlock()
+	if tracking {
+		return nil
+	}
+
+	c.trackingMtx.Lock()
+	defer c.trackingMtx.Unlock()
+	if c.tracking {
+		return nil
+	}
+
+	if err := c.rebuild(head); err != nil {
+		c.reset()
+		return errors.Wrap(err, "failed to count the series per label value")
+	}
+	c.tracking = true
+	return nil
+}
+
+// stopTracking stops tracking the series and drops the counters, unless the series aren't tracked.
+func (c *labelValueCounter) stopTracking() {
+	c.trackingMtx.RLock()
+	tracking := c.tracking
+	c.trackingMtx.RUnlock()
+	if !tracking {
+		return
+	}
+
+	c.trackingMtx.Lock()
+	defer c.trackingMtx.Unlock()
+	c.reset()
+	c.tracking = false
+}
+
+// rebuild counts the series of the head for each label value. It must be called with trackingMtx locked.
+func (c *labelValueCounter) rebuild(head *tsdb.Head) error {
+	ir, err := head.Index()
+	if err != nil {
+		return err
+	}
+	defer ir.Close()
+
+	names, err := ir.LabelNames()
+	if err != nil {
+		return err
+	}
+
+	for _, name := range names {
+		if name == "" || name == labels.MetricName {
+			continue
+		}
+
+		values, err := ir.LabelValues(name)
+		if err != nil {
+			return err
+		}
+
+		counts := make(map[string]int, len(values))
+		for _, value := range values {
+			p, err := ir.Postings(name, value)
+			if err != nil {
+				return err
+			}
+
+			numSeries := 0
+			for p.Next() {
+				numSeries++
+			}
+			if err := p.Err(); err != nil {
+				return err
+			}
+			if numSeries > 0 {
+				counts[value] = numSeries
+			}
+		}
+
+		if len(counts) > 0 {
+			shard := c.getShard(name)
+			shard.mtx.Lock()
+			shard.m[name] = counts
+			shard.mtx.Unlock()
+		}
+	}
+
+	return nil
+}
+
+// reset drops the counters. It must be called with trackingMtx locked.
+func (c *labelValueCounter) reset() {
+	for i := range c.shards {
+		shard := &c.shards[i]
+		shard.mtx.Lock()
+		shard.m = map[string]map[string]int{}
+		shard.mtx.Unlock()
+	}
+}
+
+// increaseSeriesFor tracks the series in input, if the series are tracked.
+func (c *labelValueCounter) increaseSeriesFor(series labels.Labels) {
+	c.trackingMtx.RLock()
+	defer c.trackingMtx.RUnlock()
+	if !c.tracking {
+		return
+	}
+
+	for _, l := range series {
+		if l.Name == labels.MetricName {
+			continue
+		}
+
+		shard := c.getShard(l.Name)
+		shard.mtx.Lock()
+		values, ok := shard.m[l.Name]
+		if !ok {
+			values = map[string]int{}
+			shard.m[l.Name] = values
+		}
+		values[l.Value]++
+		shard.mtx.Unlock()
+	}
+}
+
+// decreaseSeriesFor untracks the series in input, if the series are tracked. It's a no-op for the label values
+// which are not tracked, like the ones of the series missed while rebuilding the counters.
+func (c *labelValueCounter) decreaseSeriesFor(series labels.Labels) {
+	c.trackingMtx.RLock()
+	defer c.trackingMtx.RUnlock()
+	if !c.tracking {
+		return
+	}
+
+	for _, l := range series {
+		if l.Name == labels.MetricName {
+			continue
+		}
+
+		shard := c.getShard(l.Name)
+		shard.mtx.Lock()
+		if values, ok := shard.m[l.Name]; ok {
+			values[l.Value]--
+			if values[l.Value] <= 0 {
+				delete(values, l.Value)
+			}
+			if len(values) == 0 {
+				delete(shard.m, l.Name)
+			}
+		}
+		shard.mtx.Unlock()
+	}
+}
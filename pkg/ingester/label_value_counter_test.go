@@ -0,0 +1,84 @@
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package ingester
+
+import (
+	"context"
+	"testing"
+
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/prometheus/prometheus/tsdb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/mimir/pkg/util/validation"
+)
+
+func TestLabelValueCounter_ShouldTrackSeriesOnlyIfLimitsAreEnabled(t *testing.T) {
+	series := labels.FromStrings(labels.MetricName, "test", "job", "app")
+
+	countTracked := func(c *labelValueCounter) map[string]int {
+		counts := map[string]int{}
+		for i := range c.shards {
+			for name, values := range c.shards[i].m {
+				for value, numSeries := range values {
+					counts[name+"="+value] = numSeries
+				}
+			}
+		}
+		return counts
+	}
+
+	newLimiter := func(enabled bool) *Limiter {
+		limitsCfg := validation.Limits{}
+		if enabled {
+			limitsCfg.MaxGlobalSeriesPerLabelValue = 10
+		}
+		limits, err := validation.NewOverrides(limitsCfg, nil)
+		require.NoError(t, err)
+
+		ring := &ringCountMock{}
+		ring.On("HealthyInstancesCount").Return(1)
+		ring.On("ZonesCount").Return(1)
+
+		return NewLimiter(limits, ring, 1, false)
+	}
+
+	// Create a head with a series, created while the limits were disabled.
+	opts := tsdb.DefaultHeadOptions()
+	opts.ChunkDirRoot = t.TempDir()
+	head, err := tsdb.NewHead(nil, nil, nil, nil, opts, nil)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_ = head.Close()
+	})
+
+	app := head.Appender(context.Background())
+	_, err = app.Append(0, labels.FromStrings(labels.MetricName, "test", "job", "app", "instance", "a"), 1, 1)
+	require.NoError(t, err)
+	require.NoError(t, app.Commit())
+
+	c := newLabelValueCounter(newLimiter(false))
+	require.NoError(t, c.canAddSeriesFor("test", series, head))
+	c.increaseSeriesFor(series)
+	assert.Empty(t, countTracked(c))
+
+	// The counters are rebuilt from the head once the limits are enabled.
+	c.limiter = newLimiter(true)
+	require.NoError(t, c.canAddSeriesFor("test", series, head))
+	assert.Equal(t, map[string]int{"job=app": 1, "instance=a": 1}, countTracked(c))
+
+	c.increaseSeriesFor(series)
+	assert.Equal(t, map[string]int{"job=app": 2, "instance=a": 1}, countTracked(c))
+
+	c.decreaseSeriesFor(series)
+	assert.Equal(t, map[string]int{"job=app": 1, "instance=a": 1}, countTracked(c))
+
+	// The counters are dropped once the limits are disabled.
+	c.limiter = newLimiter(false)
+	require.NoError(t, c.canAddSeriesFor("test", series, head))
+	assert.Empty(t, countTracked(c))
+
+	c.increaseSeriesFor(series)
+	assert.Empty(t, countTracked(c))
+}
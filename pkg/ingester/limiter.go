@@ -8,8 +8,12 @@ package ingester
 import (
 	"fmt"
 	"math"
+	"time"
 
+	"github.com/grafana/dskit/limiter"
 	"github.com/pkg/errors"
+	"github.com/prometheus/prometheus/model/labels"
+	"golang.org/x/time/rate"
 
 	"github.com/grafana/mimir/pkg/util"
 	"github.com/grafana/mimir/pkg/util/globalerror"
@@ -23,8 +27,28 @@ var (
 	errMaxMetadataPerMetricLimitExceeded = errors.New("per-metric metadata limit exceeded")
 	errMaxSeriesPerUserLimitExceeded     = errors.New("per-user series limit exceeded")
 	errMaxMetadataPerUserLimitExceeded   = errors.New("per-user metric metadata limit exceeded")
+
+	errMaxLabelValuesPerLabelNameLimitExceeded = errors.New("per-label-name values limit exceeded")
+	errMaxSeriesPerLabelValueLimitExceeded     = errors.New("per-label-value series limit exceeded")
+
+	errNewSeriesRateLimitExceeded = errors.New("new series rate limit exceeded")
 )
 
+// labelLimitError is an internal error returned when a per-label limit is exceeded,
+// which keeps track of the offending label to include it in the API error message.
+type labelLimitError struct {
+	err   error
+	label labels.Label
+}
+
+func newLabelLimitError(err error, label labels.Label) *labelLimitError {
+	return &labelLimitError{err: err, label: label}
+}
+
+func (e *labelLimitError) Error() string {
+	return fmt.Sprintf("%s for label %s=%q", e.err, e.label.Name, e.label.Value)
+}
+
 // RingCount is the interface exposed by a ring implementation which allows
 // to count members
 type RingCount interface {
@@ -39,6 +63,9 @@ type Limiter struct {
 	ring                 RingCount
 	replicationFactor    int
 	zoneAwarenessEnabled bool
+
+	// Per-tenant rate limiter of the series creation.
+	newSeriesRateLimiter *limiter.RateLimiter
 }
 
 // NewLimiter makes a new in-memory series limiter
@@ -48,12 +75,14 @@ func NewLimiter(
 	replicationFactor int,
 	zoneAwarenessEnabled bool,
 ) *Limiter {
-	return &Limiter{
+	l := &Limiter{
 		limits:               limits,
 		ring:                 ring,
 		replicationFactor:    replicationFactor,
 		zoneAwarenessEnabled: zoneAwarenessEnabled,
 	}
+	l.newSeriesRateLimiter = limiter.NewRateLimiter(newSeriesRateStrategy{limiter: l}, 10*time.Second)
+	return l
 }
 
 // AssertMaxSeriesPerMetric limit has not been reached compared to the current
@@ -96,9 +125,43 @@ func (l *Limiter) AssertMaxMetricsWithMetadataPerUser(userID string, metrics int
 	return errMaxMetadataPerUserLimitExceeded
 }
 
+// AssertMaxLabelValuesPerLabelName limit has not been reached compared to the current
+// number of distinct values of the label name in input and returns an error if so.
+func (l *Limiter) AssertMaxLabelValuesPerLabelName(userID string, label labels.Label, values int) error {
+	if actualLimit := l.maxLabelValuesPerLabelName(userID); values < actualLimit {
+		return nil
+	}
+
+	return newLabelLimitError(errMaxLabelValuesPerLabelNameLimitExceeded, label)
+}
+
+// AssertMaxSeriesPerLabelValue limit has not been reached compared to the current
+// number of series with the label value in input and returns an error if so.
+func (l *Limiter) AssertMaxSeriesPerLabelValue(userID string, label labels.Label, series int) error {
+	if actualLimit := l.maxSeriesPerLabelValue(userID); series < actualLimit {
+		return nil
+	}
+
+	return newLabelLimitError(errMaxSeriesPerLabelValueLimitExceeded, label)
+}
+
+// AssertNewSeriesRate checks whether the tenant is allowed to create a new series at the given time
+// and returns an error if not. If it is allowed, the new series is accounted to the rate limit.
+func (l *Limiter) AssertNewSeriesRate(userID string, now time.Time) error {
+	if l.newSeriesRateLimiter.AllowN(now, userID, 1) {
+		return nil
+	}
+
+	return errNewSeriesRateLimitExceeded
+}
+
 // FormatError returns the input error enriched with the actual limits for the given user.
 // It acts as pass-through if the input error is unknown.
 func (l *Limiter) FormatError(userID string, err error) error {
+	if labelErr, ok := err.(*labelLimitError); ok {
+		return l.formatLabelLimitError(userID, labelErr)
+	}
+
 	switch err {
 	case errMaxSeriesPerUserLimitExceeded:
 		return l.formatMaxSeriesPerUserError(userID)
@@ -108,6 +171,8 @@ func (l *Limiter) FormatError(userID string, err error) error {
 		return l.formatMaxMetadataPerUserError(userID)
 	case errMaxMetadataPerMetricLimitExceeded:
 		return l.formatMaxMetadataPerMetricError(userID)
+	case errNewSeriesRateLimitExceeded:
+		return l.formatNewSeriesRateError(userID)
 	default:
 		return err
 	}
@@ -149,6 +214,38 @@ func (l *Limiter) formatMaxMetadataPerMetricError(userID string) error {
 	))
 }
 
+func (l *Limiter) formatNewSeriesRateError(userID string) error {
+	globalRate := l.limits.NewSeriesRate(userID)
+	burst := l.limits.NewSeriesBurstSize(userID)
+
+	return errors.New(globalerror.NewSeriesRateLimited.MessageWithLimitConfig(
+		fmt.Sprintf("new series rate limit of %v series/s with a maximum allowed burst of %d exceeded", globalRate, burst),
+		validation.NewSeriesRateFlag,
+		validation.NewSeriesBurstSizeFlag,
+	))
+}
+
+func (l *Limiter) formatLabelLimitError(userID string, err *labelLimitError) error {
+	switch err.err {
+	case errMaxLabelValuesPerLabelNameLimitExceeded:
+		globalLimit := l.limits.MaxGlobalLabelValuesPerLabelName(userID)
+
+		return errors.New(globalerror.MaxLabelValuesPerLabelName.MessageWithLimitConfig(
+			fmt.Sprintf("per-label-name values limit of %d exceeded for label name %s (new value %q)", globalLimit, err.label.Name, err.label.Value),
+			validation.MaxLabelValuesPerLabelNameFlag,
+		))
+	case errMaxSeriesPerLabelValueLimitExceeded:
+		globalLimit := l.limits.MaxGlobalSeriesPerLabelValue(userID)
+
+		return errors.New(globalerror.MaxSeriesPerLabelValue.MessageWithLimitConfig(
+			fmt.Sprintf("per-label-value series limit of %d exceeded for label %s=%q", globalLimit, err.label.Name, err.label.Value),
+			validation.MaxSeriesPerLabelValueFlag,
+		))
+	default:
+		return err
+	}
+}
+
 func (l *Limiter) maxSeriesPerMetric(userID string) int {
 	return l.convertGlobalToLocalLimitOrUnlimited(userID, l.limits.MaxGlobalSeriesPerMetric)
 }
@@ -165,6 +262,24 @@ func (l *Limiter) maxMetadataPerUser(userID string) int {
 	return l.convertGlobalToLocalLimitOrUnlimited(userID, l.limits.MaxGlobalMetricsWithMetadataPerUser)
 }
 
+// hasLabelLimits returns whether any of the per-label limits is enabled for the given user.
+func (l *Limiter) hasLabelLimits(userID string) bool {
+	return l.limits.MaxGlobalLabelValuesPerLabelName(userID) > 0 || l.limits.MaxGlobalSeriesPerLabelValue(userID) > 0
+}
+
+func (l *Limiter) maxLabelValuesPerLabelName(userID string) int {
+	// Unlike series, label values are not partitioned across ingesters: each ingester holds most of
+	// the values of the labels with a low cardinality, so the limit is enforced as is by each ingester.
+	if limit := l.limits.MaxGlobalLabelValuesPerLabelName(userID); limit > 0 {
+		return limit
+	}
+	return math.MaxInt32
+}
+
+func (l *Limiter) maxSeriesPerLabelValue(userID string) int {
+	return l.convertGlobalToLocalLimitOrUnlimited(userID, l.limits.MaxGlobalSeriesPerLabelValue)
+}
+
 func (l *Limiter) convertGlobalToLocalLimitOrUnlimited(userID string, globalLimitFn func(string) int) int {
 	// We can assume that series/metadata are evenly distributed across ingesters
 	globalLimit := globalLimitFn(userID)
@@ -187,7 +302,7 @@ func (l *Limiter) convertGlobalToLocalLimit(userID string, globalLimit int) int
 	// topology changes) and we prefer to always be in favor of the tenant,
 	// we can use a per-ingester limit equal to:
 	// (global limit / number of ingesters) * replication factor
-	numIngesters := l.ring.HealthyInstancesCount()
+	numIngesters := l.numIngesters(userID)
 
 	// May happen because the number of ingesters is asynchronously updated.
 	// If happens, we just temporarily ignore the global limit.
@@ -195,6 +310,30 @@ func (l *Limiter) convertGlobalToLocalLimit(userID string, globalLimit int) int
 		return 0
 	}
 
+	return int((float64(globalLimit) / float64(numIngesters)) * float64(l.replicationFactor))
+}
+
+// convertGlobalToLocalRate is like convertGlobalToLocalLimit, for a global rate.
+func (l *Limiter) convertGlobalToLocalRate(userID string, globalRate float64) float64 {
+	if globalRate == 0 {
+		return 0
+	}
+
+	numIngesters := l.numIngesters(userID)
+	if numIngesters == 0 {
+		return 0
+	}
+
+	return (globalRate / float64(numIngesters)) * float64(l.replicationFactor)
+}
+
+// numIngesters returns the number of ingesters the series of the tenant are distributed across.
+func (l *Limiter) numIngesters(userID string) int {
+	numIngesters := l.ring.HealthyInstancesCount()
+	if numIngesters == 0 {
+		return 0
+	}
+
 	// If the number of available ingesters is greater than the tenant's shard
 	// size, then we should honor the shard size because series/metadata won't
 	// be written to more ingesters than it.
@@ -203,7 +342,7 @@ func (l *Limiter) convertGlobalToLocalLimit(userID string, globalLimit int) int
 		numIngesters = util_math.Min(numIngesters, util.ShuffleShardExpectedInstances(shardSize, l.getNumZones()))
 	}
 
-	return int((float64(globalLimit) / float64(numIngesters)) * float64(l.replicationFactor))
+	return numIngesters
 }
 
 func (l *Limiter) getShardSize(userID string) int {
@@ -216,3 +355,29 @@ func (l *Limiter) getNumZones() int {
 	}
 	return 1
 }
+
+// newSeriesRateStrategy is the limiter.RateLimiterStrategy of the new series rate limit,
+// which converts the global rate to the local one of the ingester.
+type newSeriesRateStrategy struct {
+	limiter *Limiter
+}
+
+func (s newSeriesRateStrategy) Limit(userID string) float64 {
+	if lm := s.limiter.convertGlobalToLocalRate(userID, s.limiter.limits.NewSeriesRate(userID)); lm > 0 {
+		return lm
+	}
+	return float64(rate.Inf)
+}
+
+func (s newSeriesRateStrategy) Burst(userID string) int {
+	if s.Limit(userID) == float64(rate.Inf) {
+		// Burst is ignored when limit = rate.Inf
+		return 0
+	}
+	// The meaning of burst doesn't change when converting the global rate to the local one,
+	// in order to keep it easier to understand for users / operators.
+	if lm := s.limiter.limits.NewSeriesBurstSize(userID); lm > 0 {
+		return lm
+	}
+	return math.MaxInt
+}
@@ -9,10 +9,13 @@ import (
 	"errors"
 	"math"
 	"testing"
+	"time"
 
+	"github.com/prometheus/prometheus/model/labels"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
+	"golang.org/x/time/rate"
 
 	"github.com/grafana/mimir/pkg/util/validation"
 )
@@ -232,6 +235,82 @@ func TestLimiter_AssertMaxSeriesPerMetric(t *testing.T) {
 		})
 	}
 }
+func TestLimiter_AssertMaxLabelValuesPerLabelNameAndSeriesPerLabelValue(t *testing.T) {
+	label := labels.Label{Name: "pod", Value: "pod-1"}
+
+	tests := map[string]struct {
+		maxGlobalLimit        int
+		ringReplicationFactor int
+		ringIngesterCount     int
+		current               int
+		expectedValuesErr     bool
+		expectedSeriesErr     bool
+	}{
+		"limit is disabled": {
+			maxGlobalLimit:        0,
+			ringReplicationFactor: 1,
+			ringIngesterCount:     1,
+			current:               100,
+		},
+		"current number is below the limit": {
+			maxGlobalLimit:        1000,
+			ringReplicationFactor: 3,
+			ringIngesterCount:     10,
+			current:               299,
+		},
+		"current number is above the local series limit but below the label values limit": {
+			maxGlobalLimit:        1000,
+			ringReplicationFactor: 3,
+			ringIngesterCount:     10,
+			current:               300,
+			expectedSeriesErr:     true,
+		},
+		"current number is above both limits": {
+			maxGlobalLimit:        1000,
+			ringReplicationFactor: 3,
+			ringIngesterCount:     10,
+			current:               1000,
+			expectedValuesErr:     true,
+			expectedSeriesErr:     true,
+		},
+	}
+
+	for testName, testData := range tests {
+		testData := testData
+
+		t.Run(testName, func(t *testing.T) {
+			// Mock the ring
+			ring := &ringCountMock{}
+			ring.On("HealthyInstancesCount").Return(testData.ringIngesterCount)
+			ring.On("ZonesCount").Return(1)
+
+			// Mock limits
+			limits, err := validation.NewOverrides(validation.Limits{
+				MaxGlobalLabelValuesPerLabelName: testData.maxGlobalLimit,
+				MaxGlobalSeriesPerLabelValue:     testData.maxGlobalLimit,
+			}, nil)
+			require.NoError(t, err)
+
+			limiter := NewLimiter(limits, ring, testData.ringReplicationFactor, false)
+			assert.Equal(t, testData.maxGlobalLimit > 0, limiter.hasLabelLimits("test"))
+
+			valuesErr := limiter.AssertMaxLabelValuesPerLabelName("test", label, testData.current)
+			if testData.expectedValuesErr {
+				assert.Equal(t, newLabelLimitError(errMaxLabelValuesPerLabelNameLimitExceeded, label), valuesErr)
+			} else {
+				assert.NoError(t, valuesErr)
+			}
+
+			seriesErr := limiter.AssertMaxSeriesPerLabelValue("test", label, testData.current)
+			if testData.expectedSeriesErr {
+				assert.Equal(t, newLabelLimitError(errMaxSeriesPerLabelValueLimitExceeded, label), seriesErr)
+			} else {
+				assert.NoError(t, seriesErr)
+			}
+		})
+	}
+}
+
 func TestLimiter_AssertMaxMetadataPerMetric(t *testing.T) {
 	tests := map[string]struct {
 		maxGlobalMetadataPerMetric int
@@ -394,6 +473,86 @@ func TestLimiter_AssertMaxMetricsWithMetadataPerUser(t *testing.T) {
 	}
 }
 
+func TestLimiter_AssertNewSeriesRate(t *testing.T) {
+	tests := map[string]struct {
+		globalRate            float64
+		burst                 int
+		ringReplicationFactor int
+		ringIngesterCount     int
+		shardSize             int
+		expectedLocalRate     float64
+		expectedAllowed       int
+	}{
+		"limit is disabled": {
+			globalRate:            0,
+			burst:                 10,
+			ringReplicationFactor: 3,
+			ringIngesterCount:     10,
+			expectedLocalRate:     float64(rate.Inf),
+			expectedAllowed:       100,
+		},
+		"limit is enabled": {
+			globalRate:            100,
+			burst:                 10,
+			ringReplicationFactor: 3,
+			ringIngesterCount:     10,
+			expectedLocalRate:     30,
+			expectedAllowed:       10,
+		},
+		"limit is enabled and shuffle sharding is used": {
+			globalRate:            100,
+			burst:                 10,
+			ringReplicationFactor: 3,
+			ringIngesterCount:     10,
+			shardSize:             5,
+			expectedLocalRate:     60,
+			expectedAllowed:       10,
+		},
+		"limit is enabled and no ingester is healthy": {
+			globalRate:            100,
+			burst:                 10,
+			ringReplicationFactor: 3,
+			ringIngesterCount:     0,
+			expectedLocalRate:     float64(rate.Inf),
+			expectedAllowed:       100,
+		},
+	}
+
+	for testName, testData := range tests {
+		testData := testData
+
+		t.Run(testName, func(t *testing.T) {
+			// Mock the ring
+			ring := &ringCountMock{}
+			ring.On("HealthyInstancesCount").Return(testData.ringIngesterCount)
+			ring.On("ZonesCount").Return(1)
+
+			// Mock limits
+			limits, err := validation.NewOverrides(validation.Limits{
+				NewSeriesRate:            testData.globalRate,
+				NewSeriesBurstSize:       testData.burst,
+				IngestionTenantShardSize: testData.shardSize,
+			}, nil)
+			require.NoError(t, err)
+
+			limiter := NewLimiter(limits, ring, testData.ringReplicationFactor, false)
+			assert.Equal(t, testData.expectedLocalRate, newSeriesRateStrategy{limiter: limiter}.Limit("test"))
+
+			// All the series are created at the same time, so that no token is replenished.
+			now := time.Now()
+			allowed := 0
+			for i := 0; i < 100; i++ {
+				if err := limiter.AssertNewSeriesRate("test", now); err != nil {
+					assert.Equal(t, errNewSeriesRateLimitExceeded, err)
+					continue
+				}
+				allowed++
+			}
+			assert.Equal(t, testData.expectedAllowed, allowed)
+		})
+	}
+}
+
 func TestLimiter_FormatError(t *testing.T) {
 	// Mock the ring
 	ring := &ringCountMock{}
@@ -406,6 +565,10 @@ func TestLimiter_FormatError(t *testing.T) {
 		MaxGlobalSeriesPerMetric:            20,
 		MaxGlobalMetricsWithMetadataPerUser: 10,
 		MaxGlobalMetadataPerMetric:          3,
+		MaxGlobalLabelValuesPerLabelName:    5,
+		MaxGlobalSeriesPerLabelValue:        7,
+		NewSeriesRate:                       50,
+		NewSeriesBurstSize:                  200,
 	}, nil)
 	require.NoError(t, err)
 
@@ -423,6 +586,15 @@ func TestLimiter_FormatError(t *testing.T) {
 	actual = limiter.FormatError("user-1", errMaxMetadataPerMetricLimitExceeded)
 	assert.ErrorContains(t, actual, "per-metric metadata limit of 3 exceeded")
 
+	actual = limiter.FormatError("user-1", newLabelLimitError(errMaxLabelValuesPerLabelNameLimitExceeded, labels.Label{Name: "pod", Value: "pod-1"}))
+	assert.ErrorContains(t, actual, `per-label-name values limit of 5 exceeded for label name pod (new value "pod-1")`)
+
+	actual = limiter.FormatError("user-1", newLabelLimitError(errMaxSeriesPerLabelValueLimitExceeded, labels.Label{Name: "pod", Value: "pod-1"}))
+	assert.ErrorContains(t, actual, `per-label-value series limit of 7 exceeded for label pod="pod-1"`)
+
+	actual = limiter.FormatError("user-1", errNewSeriesRateLimitExceeded)
+	assert.ErrorContains(t, actual, "new series rate limit of 50 series/s with a maximum allowed burst of 200 exceeded")
+
 	input := errors.New("unknown error")
 	actual = limiter.FormatError("user-1", input)
 	assert.Equal(t, input, actual)
@@ -16,6 +16,7 @@ import (
 const (
 	perUserSeriesLimit   = "per_user_series_limit"
 	perMetricSeriesLimit = "per_metric_series_limit"
+	newSeriesRateLimited = "new_series_rate_limited"
 )
 
 const numMetricCounterShards = 128
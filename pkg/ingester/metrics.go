@@ -21,6 +21,7 @@ type ingesterMetrics struct {
 	ingestedSamplesFail     *prometheus.CounterVec
 	ingestedExemplarsFail   prometheus.Counter
 	ingestedMetadataFail    prometheus.Counter
+	dedupedSamples          *prometheus.CounterVec
 	queries                 prometheus.Counter
 	queriedSamples          prometheus.Histogram
 	queriedExemplars        prometheus.Histogram
@@ -97,6 +98,10 @@ func newIngesterMetrics(
 			Name: "cortex_ingester_ingested_samples_failures_total",
 			Help: "The total number of samples that errored on ingestion per user.",
 		}, []string{"user"}),
+		dedupedSamples: promauto.With(r).NewCounterVec(prometheus.CounterOpts{
+			Name: "cortex_ingester_ha_deduped_samples_total",
+			Help: "The total number of samples deduplicated per user, in the ingester HA deduplication mode.",
+		}, []string{"user"}),
 		ingestedExemplarsFail: promauto.With(r).NewCounter(prometheus.CounterOpts{
 			Name: "cortex_ingester_ingested_exemplars_failures_total",
 			Help: "The total number of exemplars that errored on ingestion.",
@@ -266,6 +271,7 @@ func newIngesterMetrics(
 func (m *ingesterMetrics) deletePerUserMetrics(userID string) {
 	m.ingestedSamples.DeleteLabelValues(userID)
 	m.ingestedSamplesFail.DeleteLabelValues(userID)
+	m.dedupedSamples.DeleteLabelValues(userID)
 	m.memMetadataCreatedTotal.DeleteLabelValues(userID)
 	m.memMetadataRemovedTotal.DeleteLabelValues(userID)
 }
@@ -0,0 +1,193 @@
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package ingester
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/go-kit/log/level"
+	"github.com/grafana/dskit/ring"
+	"github.com/pkg/errors"
+
+	"github.com/grafana/mimir/pkg/util"
+)
+
+const (
+	prepareScaleDownNotRequested = "not_requested"
+	prepareScaleDownInProgress   = "in_progress"
+	prepareScaleDownReady        = "ready"
+
+	// defaultPrepareScaleDownQuietPeriod is how long the ingester must not receive writes, to consider the
+	// LEAVING state propagated through the ring, when the ring heartbeat is disabled.
+	defaultPrepareScaleDownQuietPeriod = 5 * time.Second
+)
+
+// prepareScaleDownProgress tracks the progress of the preparation of the ingester for scale down.
+type prepareScaleDownProgress struct {
+	startedAt time.Time
+
+	// The ingester may still be queried for the samples it has received, or the store-gateways may not
+	// have discovered the blocks it has shipped yet, until this time. Zero until the distributors have
+	// stopped sending writes to the ingester.
+	waitUntil time.Time
+
+	// Zero until the ingester is ready to be removed.
+	readyAt time.Time
+}
+
+type prepareScaleDownResponse struct {
+	Status    string `json:"status"`
+	StartedAt string `json:"started_at,omitempty"`
+	WaitUntil string `json:"wait_until,omitempty"`
+	ReadyAt   string `json:"ready_at,omitempty"`
+}
+
+// PrepareScaleDownHandler prepares the ingester to be removed from the cluster without data gaps.
+// On POST, the ingester:
+//   - switches to the LEAVING state in the ring, so that it doesn't receive new writes but it's still queried;
+//   - compacts the TSDB head and ships the blocks of all tenants to the storage;
+//   - waits until the LEAVING state has propagated through the ring, and the distributors have stopped sending writes to it;
+//   - compacts and ships again the samples received while the ring change was propagating;
+//   - waits until the queriers stop querying it for the samples it has received (-querier.query-ingesters-within),
+//     and until the store-gateways have discovered the shipped blocks.
+//
+// Both on GET and POST, it returns the progress of the preparation. Once the status is "ready",
+// the ingester can be removed. The preparation can't be canceled: restarting the ingester brings
+// it back to the ACTIVE state.
+func (i *Ingester) PrepareScaleDownHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodPost {
+		if err := i.startPrepareScaleDown(); err != nil {
+			level.Warn(i.logger).Log("msg", "failed to prepare the ingester for scale down", "err", err)
+			http.Error(w, err.Error(), http.StatusConflict)
+			return
+		}
+	}
+
+	util.WriteJSONResponse(w, i.prepareScaleDownStatus())
+}
+
+// startPrepareScaleDown switches the ingester to the LEAVING state and starts the preparation
+// for scale down in background. It does nothing if the preparation has already been started.
+func (i *Ingester) startPrepareScaleDown() error {
+	i.prepareScaleDownMtx.Lock()
+	defer i.prepareScaleDownMtx.Unlock()
+
+	if i.prepareScaleDown != nil {
+		return nil
+	}
+
+	ingCtx := i.BasicService.ServiceContext()
+	if ingCtx == nil || ingCtx.Err() != nil {
+		return errors.New("ingester not running")
+	}
+
+	// Only an ACTIVE ingester can switch to LEAVING.
+	if err := i.lifecycler.ChangeState(ingCtx, ring.LEAVING); err != nil {
+		return errors.Wrap(err, "switch ingester to the LEAVING state")
+	}
+
+	progress := &prepareScaleDownProgress{startedAt: time.Now()}
+	i.prepareScaleDown = progress
+
+	level.Info(i.logger).Log("msg", "preparing the ingester for scale down")
+	go i.runPrepareScaleDown(ingCtx, progress)
+
+	return nil
+}
+
+func (i *Ingester) runPrepareScaleDown(ctx context.Context, progress *prepareScaleDownProgress) {
+	allUsers := util.NewAllowedTenants(nil, nil)
+
+	// Ship the samples received so far as soon as possible, so that they're available from the storage.
+	if !i.compactAndShipBlocks(ctx, allUsers) {
+		return
+	}
+
+	if !i.waitUntilWritesStopped(ctx) {
+		return
+	}
+
+	// Ship the samples received while the distributors were picking up the ring change.
+	if !i.compactAndShipBlocks(ctx, allUsers) {
+		return
+	}
+
+	// The store-gateways discover the shipped blocks once the compactor has added them to the bucket index,
+	// and they've synced the updated bucket index. Both happen periodically, at the bucket store sync interval
+	// by default, so we wait twice as long.
+	shippedAt := time.Now()
+	waitUntil := shippedAt.Add(i.cfg.QueryIngestersWithin)
+	if discoveredAt := shippedAt.Add(2 * i.cfg.BlocksStorageConfig.BucketStore.SyncInterval); discoveredAt.After(waitUntil) {
+		waitUntil = discoveredAt
+	}
+
+	i.prepareScaleDownMtx.Lock()
+	progress.waitUntil = waitUntil
+	i.prepareScaleDownMtx.Unlock()
+
+	level.Info(i.logger).Log("msg", "waiting for the ingester to not be queried anymore", "wait_until", waitUntil)
+	select {
+	case <-time.After(time.Until(waitUntil)):
+	case <-ctx.Done():
+		return
+	}
+
+	i.prepareScaleDownMtx.Lock()
+	progress.readyAt = time.Now()
+	i.prepareScaleDownMtx.Unlock()
+
+	level.Info(i.logger).Log("msg", "the ingester is ready to be scaled down")
+}
+
+// waitUntilWritesStopped waits until the distributors have noticed the LEAVING state, and stopped sending
+// writes to the ingester: no push request has been received, nor is in-flight, for a ring heartbeat period.
+// It returns false if the context is done before.
+func (i *Ingester) waitUntilWritesStopped(ctx context.Context) bool {
+	quietPeriod := i.cfg.IngesterRing.HeartbeatPeriod
+	if quietPeriod <= 0 {
+		quietPeriod = defaultPrepareScaleDownQuietPeriod
+	}
+
+	ticker := time.NewTicker(quietPeriod)
+	defer ticker.Stop()
+
+	received := i.receivedPushRequests.Load()
+	for {
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return false
+		}
+
+		prev := received
+		received = i.receivedPushRequests.Load()
+		if received == prev && i.inflightPushRequests.Load() == 0 {
+			return true
+		}
+	}
+}
+
+func (i *Ingester) prepareScaleDownStatus() prepareScaleDownResponse {
+	i.prepareScaleDownMtx.Lock()
+	defer i.prepareScaleDownMtx.Unlock()
+
+	progress := i.prepareScaleDown
+	if progress == nil {
+		return prepareScaleDownResponse{Status: prepareScaleDownNotRequested}
+	}
+
+	res := prepareScaleDownResponse{
+		Status:    prepareScaleDownInProgress,
+		StartedAt: progress.startedAt.UTC().Format(time.RFC3339),
+	}
+	if !progress.waitUntil.IsZero() {
+		res.WaitUntil = progress.waitUntil.UTC().Format(time.RFC3339)
+	}
+	if !progress.readyAt.IsZero() {
+		res.Status = prepareScaleDownReady
+		res.ReadyAt = progress.readyAt.UTC().Format(time.RFC3339)
+	}
+	return res
+}
@@ -19,12 +19,15 @@ import (
 	"github.com/pkg/errors"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/prometheus/prometheus/storage"
 	"github.com/thanos-io/thanos/pkg/block"
 	"github.com/thanos-io/thanos/pkg/block/metadata"
 	"github.com/thanos-io/thanos/pkg/objstore"
 	"github.com/thanos-io/thanos/pkg/shipper"
 
 	"github.com/grafana/mimir/pkg/storage/tsdb"
+	"github.com/grafana/mimir/pkg/storage/tsdb/exemplars"
 )
 
 type metrics struct {
@@ -67,11 +70,16 @@ type Shipper struct {
 	source  metadata.SourceType
 
 	hashFunc metadata.HashFunc
+
+	// exemplars is used to query the exemplars to upload alongside each block. Exemplars
+	// are not uploaded if nil.
+	exemplars storage.ExemplarQueryable
 }
 
 // NewShipper creates a new uploader that detects new TSDB blocks in dir and uploads them to
 // remote if necessary. It attaches the Thanos metadata section in each meta JSON file.
 // If uploadCompacted is enabled, it also uploads compacted blocks which are already in filesystem.
+// If exemplars is not nil, the exemplars in the time range of each block are uploaded alongside it.
 func NewShipper(
 	logger log.Logger,
 	r prometheus.Registerer,
@@ -79,18 +87,20 @@ func NewShipper(
 	bucket objstore.Bucket,
 	source metadata.SourceType,
 	hashFunc metadata.HashFunc,
+	exemplars storage.ExemplarQueryable,
 ) *Shipper {
 	if logger == nil {
 		logger = log.NewNopLogger()
 	}
 
 	return &Shipper{
-		logger:   logger,
-		dir:      dir,
-		bucket:   bucket,
-		metrics:  newMetrics(r),
-		source:   source,
-		hashFunc: hashFunc,
+		logger:    logger,
+		dir:       dir,
+		bucket:    bucket,
+		metrics:   newMetrics(r),
+		source:    source,
+		hashFunc:  hashFunc,
+		exemplars: exemplars,
 	}
 }
 
@@ -189,10 +199,47 @@ func (s *Shipper) upload(ctx context.Context, meta *metadata.Meta) error {
 	meta.Thanos.Source = s.source
 	meta.Thanos.SegmentFiles = block.GetSegmentFiles(blockDir)
 
+	// Exemplars are uploaded before the block, so that they're available once the block is complete.
+	// Failing to upload them doesn't fail the block upload, given exemplars are best-effort anyway.
+	if s.exemplars != nil {
+		if err := s.uploadExemplars(ctx, meta); err != nil {
+			level.Warn(s.logger).Log("msg", "failed to upload block exemplars", "id", meta.ULID, "err", err)
+		}
+	}
+
 	// Upload block with custom metadata.
 	return tsdb.UploadBlock(ctx, s.logger, s.bucket, blockDir, meta)
 }
 
+// uploadExemplars uploads the exemplars in the time range of the block. Exemplars are kept in memory
+// by the TSDB head, so the ones which have already been evicted are not uploaded.
+func (s *Shipper) uploadExemplars(ctx context.Context, meta *metadata.Meta) error {
+	q, err := s.exemplars.ExemplarQuerier(ctx)
+	if err != nil {
+		return err
+	}
+
+	// The block max time is exclusive, while the exemplars query one is inclusive.
+	results, err := q.Select(meta.MinTime, meta.MaxTime-1, []*labels.Matcher{
+		labels.MustNewMatcher(labels.MatchRegexp, labels.MetricName, ".+"),
+	})
+	if err != nil {
+		return errors.Wrap(err, "query exemplars")
+	}
+
+	f := exemplars.NewFile(results)
+	if len(f.Series) == 0 {
+		return nil
+	}
+
+	if err := exemplars.Upload(ctx, s.bucket, meta.ULID, f); err != nil {
+		return err
+	}
+
+	level.Info(s.logger).Log("msg", "uploaded block exemplars", "id", meta.ULID, "series", len(f.Series), "exemplars", f.NumExemplars())
+	return nil
+}
+
 // blockMetasFromOldest returns the block meta of each block found in dir
 // sorted by minTime asc.
 func (s *Shipper) blockMetasFromOldest() (metas []*metadata.Meta, _ error) {
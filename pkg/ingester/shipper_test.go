@@ -14,12 +14,18 @@ import (
 	"github.com/go-kit/log"
 	"github.com/grafana/dskit/concurrency"
 	"github.com/oklog/ulid"
+	"github.com/prometheus/prometheus/model/exemplar"
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/prometheus/prometheus/storage"
 	"github.com/prometheus/prometheus/tsdb"
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"github.com/thanos-io/thanos/pkg/block"
 	"github.com/thanos-io/thanos/pkg/block/metadata"
+	"github.com/thanos-io/thanos/pkg/objstore"
 
 	"github.com/grafana/mimir/pkg/storage/bucket/filesystem"
+	"github.com/grafana/mimir/pkg/storage/tsdb/exemplars"
 )
 
 func createBlock(t *testing.T, blocksDir string, id ulid.ULID, m metadata.Meta) {
@@ -42,7 +48,7 @@ func TestShipper(t *testing.T) {
 	logs := &concurrency.SyncBuffer{}
 	logger := log.NewLogfmtLogger(logs)
 
-	s := NewShipper(logger, nil, blocksDir, bkt, metadata.TestSource, metadata.NoneFunc)
+	s := NewShipper(logger, nil, blocksDir, bkt, metadata.TestSource, metadata.NoneFunc, nil)
 
 	t.Run("no shipper file yet", func(t *testing.T) {
 		// No shipper file = nothing is reported as shipped.
@@ -145,3 +151,64 @@ func TestShipper(t *testing.T) {
 
 	t.Log(logs.String())
 }
+
+func TestShipper_ShouldUploadExemplarsAlongsideBlocks(t *testing.T) {
+	blocksDir := t.TempDir()
+	bucketDir := t.TempDir()
+
+	bkt, err := filesystem.NewBucketClient(filesystem.Config{Directory: bucketDir})
+	require.NoError(t, err)
+
+	series := labels.FromStrings("__name__", "a")
+	queryable := &exemplarQueryableMock{results: []exemplar.QueryResult{{
+		SeriesLabels: series,
+		Exemplars: []exemplar.Exemplar{
+			{Labels: labels.FromStrings("trace_id", "1"), Value: 1, Ts: 1000, HasTs: true},
+		},
+	}}}
+
+	s := NewShipper(log.NewNopLogger(), nil, blocksDir, bkt, metadata.TestSource, metadata.NoneFunc, queryable)
+
+	id := ulid.MustNew(1, nil)
+	createBlock(t, blocksDir, id, metadata.Meta{
+		BlockMeta: tsdb.BlockMeta{
+			ULID:    id,
+			MinTime: 1000,
+			MaxTime: 2000,
+			Version: 1,
+			Stats:   tsdb.BlockStats{NumSamples: 100},
+		},
+	})
+
+	uploaded, err := s.Sync(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, 1, uploaded)
+
+	// The exemplars have been queried in the block time range, with the max time being exclusive.
+	assert.Equal(t, int64(1000), queryable.start)
+	assert.Equal(t, int64(1999), queryable.end)
+
+	actual, err := exemplars.Read(context.Background(), objstore.WithNoopInstr(bkt), id, log.NewNopLogger())
+	require.NoError(t, err)
+	require.NotNil(t, actual)
+	assert.Equal(t, []exemplars.Series{{
+		Labels: series,
+		Exemplars: []exemplars.Exemplar{
+			{Labels: labels.FromStrings("trace_id", "1"), Value: 1, Timestamp: 1000},
+		},
+	}}, actual.Series)
+}
+
+type exemplarQueryableMock struct {
+	results    []exemplar.QueryResult
+	start, end int64
+}
+
+func (m *exemplarQueryableMock) ExemplarQuerier(context.Context) (storage.ExemplarQuerier, error) {
+	return m, nil
+}
+
+func (m *exemplarQueryableMock) Select(start, end int64, _ ...[]*labels.Matcher) ([]exemplar.QueryResult, error) {
+	m.start, m.end = start, end
+	return m.results, nil
+}
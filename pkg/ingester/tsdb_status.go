@@ -0,0 +1,135 @@
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package ingester
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/prometheus/prometheus/storage"
+	"github.com/prometheus/prometheus/tsdb"
+	"github.com/prometheus/prometheus/tsdb/chunks"
+	"github.com/prometheus/prometheus/tsdb/index"
+
+	"github.com/grafana/mimir/pkg/ingester/client"
+)
+
+// tsdbStatusCacheTTL is how long the TSDB status of a tenant is cached for, since computing it requires
+// a full scan of the head index.
+const tsdbStatusCacheTTL = 30 * time.Second
+
+// tsdbStatusCache caches the last TSDB status computed for a tenant.
+type tsdbStatusCache struct {
+	mtx        sync.Mutex
+	resp       *client.TSDBStatusResponse
+	limit      int
+	computedAt time.Time
+}
+
+// get returns the cached TSDB status if it has been computed within the TTL with at least the given limit,
+// otherwise it computes it and caches it. Concurrent requests wait for the TSDB status being computed,
+// instead of scanning the index concurrently.
+func (c *tsdbStatusCache) get(now time.Time, limit int, compute func() (*client.TSDBStatusResponse, error)) (*client.TSDBStatusResponse, error) {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	if c.resp == nil || c.limit < limit || now.Sub(c.computedAt) >= tsdbStatusCacheTTL {
+		resp, err := compute()
+		if err != nil {
+			return nil, err
+		}
+		c.resp, c.limit, c.computedAt = resp, limit, now
+	}
+
+	resp := *c.resp
+	resp.SeriesCountByMetricName = limitTSDBStatisticItems(resp.SeriesCountByMetricName, limit)
+	resp.LabelValueCountByLabelName = limitTSDBStatisticItems(resp.LabelValueCountByLabelName, limit)
+	resp.MemoryInBytesByLabelName = limitTSDBStatisticItems(resp.MemoryInBytesByLabelName, limit)
+	resp.SeriesCountByLabelValuePair = limitTSDBStatisticItems(resp.SeriesCountByLabelValuePair, limit)
+	return &resp, nil
+}
+
+// limitTSDBStatisticItems returns the first `limit` items, which are already sorted.
+func limitTSDBStatisticItems(items []client.TSDBStatisticItem, limit int) []client.TSDBStatisticItem {
+	if len(items) > limit {
+		return items[:limit]
+	}
+	return items
+}
+
+// tsdbStatus computes the cardinality statistics of all the series in the index, keeping only the
+// top `limit` items of each list of statistics. The min and max time of the response are not set.
+func tsdbStatus(ctx context.Context, idx tsdb.IndexReader, limit int) (*client.TSDBStatusResponse, error) {
+	postings, err := idx.Postings(index.AllPostingsKey())
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		resp = &client.TSDBStatusResponse{}
+		lbls labels.Labels
+		chks []chunks.Meta
+
+		// Number of series by label name and value.
+		seriesByLabelPair = map[string]map[string]uint64{}
+	)
+
+	for postings.Next() {
+		if resp.NumSeries%checkContextErrorSeriesCount == 0 {
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
+		}
+
+		if err := idx.Series(postings.At(), &lbls, &chks); err != nil {
+			// The series may have been garbage collected in the meanwhile.
+			if errors.Is(err, storage.ErrNotFound) {
+				continue
+			}
+			return nil, err
+		}
+
+		resp.NumSeries++
+		resp.ChunkCount += uint64(len(chks))
+
+		for _, l := range lbls {
+			values, ok := seriesByLabelPair[l.Name]
+			if !ok {
+				values = map[string]uint64{}
+				seriesByLabelPair[l.Name] = values
+			}
+			values[l.Value]++
+		}
+	}
+	if err := postings.Err(); err != nil {
+		return nil, err
+	}
+
+	var seriesByMetricName, valuesByLabelName, bytesByLabelName, seriesByLabelValuePair []client.TSDBStatisticItem
+
+	for name, values := range seriesByLabelPair {
+		size := uint64(0)
+		for value, seriesCount := range values {
+			size += uint64(len(value))
+			seriesByLabelValuePair = append(seriesByLabelValuePair, client.TSDBStatisticItem{Name: name + "=" + value, Value: seriesCount})
+
+			if name == labels.MetricName {
+				seriesByMetricName = append(seriesByMetricName, client.TSDBStatisticItem{Name: value, Value: seriesCount})
+			}
+		}
+
+		resp.NumLabelPairs += uint64(len(values))
+		valuesByLabelName = append(valuesByLabelName, client.TSDBStatisticItem{Name: name, Value: uint64(len(values))})
+		bytesByLabelName = append(bytesByLabelName, client.TSDBStatisticItem{Name: name, Value: size})
+	}
+
+	resp.SeriesCountByMetricName = client.TopTSDBStatisticItems(seriesByMetricName, limit)
+	resp.LabelValueCountByLabelName = client.TopTSDBStatisticItems(valuesByLabelName, limit)
+	resp.MemoryInBytesByLabelName = client.TopTSDBStatisticItems(bytesByLabelName, limit)
+	resp.SeriesCountByLabelValuePair = client.TopTSDBStatisticItems(seriesByLabelValuePair, limit)
+
+	return resp, nil
+}
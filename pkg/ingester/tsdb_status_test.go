@@ -0,0 +1,62 @@
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package ingester
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/mimir/pkg/ingester/client"
+)
+
+func TestTSDBStatusCache(t *testing.T) {
+	var (
+		cache    tsdbStatusCache
+		now      = time.Now()
+		computed = 0
+	)
+
+	compute := func() (*client.TSDBStatusResponse, error) {
+		computed++
+		return &client.TSDBStatusResponse{
+			NumSeries:               uint64(computed),
+			SeriesCountByMetricName: []client.TSDBStatisticItem{{Name: "metric_0", Value: 2}, {Name: "metric_1", Value: 1}},
+		}, nil
+	}
+
+	resp, err := cache.get(now, 2, compute)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(1), resp.NumSeries)
+	assert.Len(t, resp.SeriesCountByMetricName, 2)
+
+	// The cached statistics are truncated to a lower limit.
+	resp, err = cache.get(now.Add(tsdbStatusCacheTTL/2), 1, compute)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(1), resp.NumSeries)
+	assert.Equal(t, []client.TSDBStatisticItem{{Name: "metric_0", Value: 2}}, resp.SeriesCountByMetricName)
+
+	// The truncation doesn't affect the cached statistics.
+	resp, err = cache.get(now.Add(tsdbStatusCacheTTL/2), 2, compute)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(1), resp.NumSeries)
+	assert.Len(t, resp.SeriesCountByMetricName, 2)
+
+	// The statistics are computed again once expired.
+	resp, err = cache.get(now.Add(tsdbStatusCacheTTL), 2, compute)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(2), resp.NumSeries)
+
+	// Errors aren't cached.
+	_, err = cache.get(now.Add(2*tsdbStatusCacheTTL), 2, func() (*client.TSDBStatusResponse, error) {
+		return nil, errors.New("failed")
+	})
+	require.Error(t, err)
+
+	resp, err = cache.get(now.Add(2*tsdbStatusCacheTTL), 2, compute)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(3), resp.NumSeries)
+}
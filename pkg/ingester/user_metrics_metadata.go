@@ -12,6 +12,8 @@ import (
 	"github.com/prometheus/prometheus/model/labels"
 
 	"github.com/grafana/mimir/pkg/mimirpb"
+	"github.com/grafana/mimir/pkg/storage/tsdb/metricmetadata"
+	"github.com/grafana/mimir/pkg/util"
 	"github.com/grafana/mimir/pkg/util/validation"
 )
 
@@ -100,6 +102,52 @@ func (mm *userMetricsMetadata) toClientMetadata() []*mimirpb.MetricMetadata {
 	return r
 }
 
+// toFile returns a snapshot of the metadata, along with the last time each one has been seen.
+func (mm *userMetricsMetadata) toFile(now time.Time) *metricmetadata.File {
+	mm.mtx.RLock()
+	defer mm.mtx.RUnlock()
+
+	all := map[mimirpb.MetricMetadata]time.Time{}
+	for _, set := range mm.metricToMetadata {
+		for m, t := range set {
+			all[m] = t
+		}
+	}
+	return metricmetadata.NewFile(all, now)
+}
+
+// restore adds the metadata in the file last seen after the deadline. Limits are not
+// enforced, because the metadata has already been accepted before being persisted.
+func (mm *userMetricsMetadata) restore(f *metricmetadata.File, deadline time.Time) {
+	mm.mtx.Lock()
+	defer mm.mtx.Unlock()
+
+	for _, e := range f.Metadata {
+		lastSeen := util.TimeFromMillis(e.LastSeen)
+		if deadline.After(lastSeen) {
+			continue
+		}
+
+		set, ok := mm.metricToMetadata[e.MetricFamilyName]
+		if !ok {
+			set = metricMetadataSet{}
+			mm.metricToMetadata[e.MetricFamilyName] = set
+		}
+
+		m := e.MetricMetadata()
+		if t, ok := set[m]; ok {
+			if lastSeen.After(t) {
+				set[m] = lastSeen
+			}
+			continue
+		}
+
+		set[m] = lastSeen
+		mm.metrics.memMetadata.Inc()
+		mm.metrics.memMetadataCreatedTotal.WithLabelValues(mm.userID).Inc()
+	}
+}
+
 type metricMetadataSet map[mimirpb.MetricMetadata]time.Time
 
 // If deadline is zero time, all metrics are purged.
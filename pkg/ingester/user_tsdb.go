@@ -58,6 +58,8 @@ type userTSDB struct {
 	userID         string
 	activeSeries   *activeseries.ActiveSeries
 	seriesInMetric *metricCounter
+	seriesInLabels *labelValueCounter
+	haReplicas     *haReplicaTracker
 	limiter        *Limiter
 
 	instanceSeriesCount *atomic.Int64 // Shared across all userTSDB instances created by ingester.
@@ -87,6 +89,9 @@ type userTSDB struct {
 	// Cached shipped blocks.
 	shippedBlocksMtx sync.Mutex
 	shippedBlocks    map[ulid.ULID]struct{}
+
+	// Cached TSDB status, computed from the head index.
+	tsdbStatus tsdbStatusCache
 }
 
 // Explicitly wrapping the tsdb.DB functions that we use.
@@ -198,12 +203,23 @@ func (u *userTSDB) PreCreation(metric labels.Labels) error {
 		return err
 	}
 
+	// Per-label limits.
+	if err := u.seriesInLabels.canAddSeriesFor(u.userID, metric, u.Head()); err != nil {
+		return err
+	}
+
+	// New series rate limit. It's checked last, to only account the series which would be created.
+	if err := u.limiter.AssertNewSeriesRate(u.userID, time.Now()); err != nil {
+		return err
+	}
+
 	return nil
 }
 
 // PostCreation implements SeriesLifecycleCallback interface.
 func (u *userTSDB) PostCreation(metric labels.Labels) {
 	u.instanceSeriesCount.Inc()
+	u.seriesInLabels.increaseSeriesFor(metric)
 
 	metricName, err := extract.MetricNameFromLabels(metric)
 	if err != nil {
@@ -218,6 +234,9 @@ func (u *userTSDB) PostDeletion(metrics ...labels.Labels) {
 	u.instanceSeriesCount.Sub(int64(len(metrics)))
 
 	for _, metric := range metrics {
+		u.seriesInLabels.decreaseSeriesFor(metric)
+		u.haReplicas.removeSeries(metric.Hash())
+
 		metricName, err := extract.MetricNameFromLabels(metric)
 		if err != nil {
 			// This should never happen because it has already been checked in PreCreation().
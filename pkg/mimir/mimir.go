@@ -470,6 +470,15 @@ type Mimir struct {
 
 	// Queryables that the querier should use to query the long term storage.
 	StoreQueryables []querier.QueryableWithFilter
+
+	// Series deletion requests the querier should apply at query time. Nil if disabled.
+	Tombstones querier.TombstonesProvider
+
+	// Metric metadata persisted in the long term storage the querier should return. Nil if disabled.
+	StoreMetadataSupplier querier.MetadataSupplier
+
+	// Exemplars uploaded to the long term storage the querier should return. Nil if disabled.
+	StoreExemplarQueryable prom_storage.ExemplarQueryable
 }
 
 // New makes a new Mimir.
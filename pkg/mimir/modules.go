@@ -301,7 +301,7 @@ func (t *Mimir) initDistributorService() (serv services.Service, err error) {
 }
 
 func (t *Mimir) initDistributor() (serv services.Service, err error) {
-	t.API.RegisterDistributor(t.Distributor, t.Cfg.Distributor)
+	t.API.RegisterDistributor(t.Distributor, t.Cfg.Distributor, t.Overrides)
 
 	return nil, nil
 }
@@ -312,7 +312,10 @@ func (t *Mimir) initQueryable() (serv services.Service, err error) {
 	querierRegisterer := prometheus.WrapRegistererWith(prometheus.Labels{"engine": "querier"}, prometheus.DefaultRegisterer)
 
 	// Create a querier queryable and PromQL engine
-	t.QuerierQueryable, t.ExemplarQueryable, t.QuerierEngine = querier.New(t.Cfg.Querier, t.Overrides, t.Distributor, t.StoreQueryables, querierRegisterer, util_log.Logger, t.ActivityTracker)
+	t.QuerierQueryable, t.ExemplarQueryable, t.QuerierEngine = querier.New(t.Cfg.Querier, t.Overrides, t.Distributor, t.StoreQueryables, t.Tombstones, querierRegisterer, util_log.Logger, t.ActivityTracker)
+	if t.StoreExemplarQueryable != nil {
+		t.ExemplarQueryable = querier.NewMergeExemplarQueryable(t.ExemplarQueryable, t.StoreExemplarQueryable)
+	}
 
 	// Register the default endpoints that are always enabled for the querier module
 	t.API.RegisterQueryable(t.QuerierQueryable, t.Distributor)
@@ -384,6 +387,12 @@ func (t *Mimir) initTenantFederation() (serv services.Service, err error) {
 //                                            └──────────────────┘
 //
 func (t *Mimir) initQuerier() (serv services.Service, err error) {
+	// The metric metadata held by ingesters is merged with the one persisted in the storage, if enabled.
+	metadataSupplier := querier.MetadataSupplier(t.Distributor)
+	if t.StoreMetadataSupplier != nil {
+		metadataSupplier = querier.NewMergeMetadataSupplier(t.Distributor, t.StoreMetadataSupplier)
+	}
+
 	// Create a internal HTTP handler that is configured with the Prometheus API routes and points
 	// to a Prometheus API struct instantiated with the Mimir Queryable.
 	internalQuerierRouter := api.NewQuerierHandler(
@@ -392,6 +401,7 @@ func (t *Mimir) initQuerier() (serv services.Service, err error) {
 		t.ExemplarQueryable,
 		t.QuerierEngine,
 		t.Distributor,
+		metadataSupplier,
 		prometheus.DefaultRegisterer,
 		util_log.Logger,
 		t.Overrides,
@@ -446,6 +456,21 @@ func (t *Mimir) initStoreQueryables() (services.Service, error) {
 	} else {
 		t.StoreQueryables = append(t.StoreQueryables, querier.UseAlwaysQueryable(q))
 		servs = append(servs, q)
+
+		if t.Cfg.Querier.QueryStoreForMetadata {
+			t.StoreMetadataSupplier = q
+		}
+		if t.Cfg.Querier.QueryStoreForExemplars {
+			t.StoreExemplarQueryable = q
+		}
+	}
+
+	if t.Cfg.Querier.SeriesDeletionEnabled {
+		l, err := querier.NewTombstonesLoaderFromConfig(t.Cfg.Querier, t.Cfg.BlocksStorage, util_log.Logger, prometheus.DefaultRegisterer)
+		if err != nil {
+			return nil, fmt.Errorf("failed to initialize series deletion requests loader: %v", err)
+		}
+		t.Tombstones = l
 	}
 
 	// Return service, if any.
@@ -470,6 +495,7 @@ func (t *Mimir) initIngesterService() (serv services.Service, err error) {
 	t.Cfg.Ingester.IngesterRing.ListenPort = t.Cfg.Server.GRPCListenPort
 	t.Cfg.Ingester.StreamTypeFn = ingesterChunkStreaming(t.RuntimeConfig)
 	t.Cfg.Ingester.InstanceLimitsFn = ingesterInstanceLimits(t.RuntimeConfig)
+	t.Cfg.Ingester.QueryIngestersWithin = t.Cfg.Querier.QueryIngestersWithin
 	t.tsdbIngesterConfig()
 
 	t.Ingester, err = ingester.New(t.Cfg.Ingester, t.Overrides, prometheus.DefaultRegisterer, util_log.Logger)
@@ -600,7 +626,7 @@ func (t *Mimir) initRuler() (serv services.Service, err error) {
 		// TODO: Consider wrapping logger to differentiate from querier module logger
 		rulerRegisterer := prometheus.WrapRegistererWith(prometheus.Labels{"engine": "ruler"}, prometheus.DefaultRegisterer)
 
-		queryable, _, eng := querier.New(t.Cfg.Querier, t.Overrides, t.Distributor, t.StoreQueryables, rulerRegisterer, util_log.Logger, t.ActivityTracker)
+		queryable, _, eng := querier.New(t.Cfg.Querier, t.Overrides, t.Distributor, t.StoreQueryables, t.Tombstones, rulerRegisterer, util_log.Logger, t.ActivityTracker)
 		queryable = querier.NewErrorTranslateQueryableWithFn(queryable, ruler.WrapQueryableErrors)
 
 		if t.Cfg.Ruler.TenantFederation.Enabled {
@@ -721,6 +747,7 @@ func (t *Mimir) initMemberlistKV() (services.Service, error) {
 	t.Cfg.MemberlistKV.MetricsRegisterer = reg
 	t.Cfg.MemberlistKV.Codecs = []codec.Codec{
 		ring.GetCodec(),
+		distributor.GetReplicaDescCodec(),
 	}
 	dnsProviderReg := prometheus.WrapRegistererWithPrefix(
 		"cortex_",
@@ -735,6 +762,7 @@ func (t *Mimir) initMemberlistKV() (services.Service, error) {
 
 	// Update the config.
 	t.Cfg.Distributor.DistributorRing.KVStore.MemberlistKV = t.MemberlistKV.GetMemberlistKV
+	t.Cfg.Distributor.HATrackerConfig.KVStore.MemberlistKV = t.MemberlistKV.GetMemberlistKV
 	t.Cfg.Ingester.IngesterRing.KVStore.MemberlistKV = t.MemberlistKV.GetMemberlistKV
 	t.Cfg.StoreGateway.ShardingRing.KVStore.MemberlistKV = t.MemberlistKV.GetMemberlistKV
 	t.Cfg.Compactor.ShardingRing.KVStore.MemberlistKV = t.MemberlistKV.GetMemberlistKV
@@ -752,6 +780,13 @@ func (t *Mimir) initTenantDeletionAPI() (services.Service, error) {
 	}
 
 	t.API.RegisterTenantDeletion(tenantDeletionAPI)
+
+	seriesDeletionAPI, err := purger.NewSeriesDeletionAPI(t.Cfg.BlocksStorage, t.Overrides, util_log.Logger, prometheus.DefaultRegisterer)
+	if err != nil {
+		return nil, err
+	}
+
+	t.API.RegisterSeriesDeletion(seriesDeletionAPI)
 	return nil, nil
 }
 
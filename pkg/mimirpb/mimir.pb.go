@@ -93,6 +93,8 @@ type WriteRequest struct {
 	Source                  WriteRequest_SourceEnum `protobuf:"varint,2,opt,name=Source,proto3,enum=cortexpb.WriteRequest_SourceEnum" json:"Source,omitempty"`
 	Metadata                []*MetricMetadata       `protobuf:"bytes,3,rep,name=metadata,proto3" json:"metadata,omitempty"`
 	SkipLabelNameValidation bool                    `protobuf:"varint,1000,opt,name=skip_label_name_validation,json=skipLabelNameValidation,proto3" json:"skip_label_name_validation,omitempty"`
+	// The HA replica which sent the series, set by the distributor in the ingester HA deduplication mode.
+	HaReplica string `protobuf:"bytes,1001,opt,name=ha_replica,json=haReplica,proto3" json:"ha_replica,omitempty"`
 }
 
 func (m *WriteRequest) Reset()      { *m = WriteRequest{} }
@@ -148,6 +150,13 @@ func (m *WriteRequest) GetSkipLabelNameValidation() bool {
 	return false
 }
 
+func (m *WriteRequest) GetHaReplica() string {
+	if m != nil {
+		return m.HaReplica
+	}
+	return ""
+}
+
 type WriteResponse struct {
 }
 
@@ -511,51 +520,52 @@ func init() {
 func init() { proto.RegisterFile("mimir.proto", fileDescriptor_86d4d7485f544059) }
 
 var fileDescriptor_86d4d7485f544059 = []byte{
-	// 701 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xac, 0x54, 0xbd, 0x6e, 0xd3, 0x5e,
-	0x14, 0xf7, 0xcd, 0x77, 0x4e, 0xd2, 0xfc, 0xad, 0xfb, 0xaf, 0x84, 0xd5, 0xc1, 0x49, 0xcd, 0x92,
-	0x01, 0x52, 0x54, 0x04, 0x08, 0x04, 0x83, 0x83, 0xd2, 0x52, 0xb5, 0xf9, 0xd0, 0x8d, 0x43, 0x05,
-	0x4b, 0x74, 0x93, 0xde, 0xb6, 0x16, 0xbe, 0xb1, 0xb1, 0x9d, 0xaa, 0xd9, 0x98, 0x98, 0x99, 0x79,
-	0x02, 0x9e, 0x00, 0x89, 0x37, 0xe8, 0xd8, 0xb1, 0x62, 0xa8, 0xa8, 0xbb, 0x74, 0xec, 0x23, 0x20,
-	0x5f, 0x3b, 0x71, 0xab, 0x8a, 0xad, 0xdb, 0x3d, 0xe7, 0xf7, 0x71, 0x8e, 0xcf, 0x39, 0x32, 0x94,
-	0xb8, 0xc9, 0x4d, 0xb7, 0xe1, 0xb8, 0xb6, 0x6f, 0xe3, 0xc2, 0xd8, 0x76, 0x7d, 0x76, 0xec, 0x8c,
-	0x56, 0x1e, 0x1f, 0x98, 0xfe, 0xe1, 0x74, 0xd4, 0x18, 0xdb, 0x7c, 0xed, 0xc0, 0x3e, 0xb0, 0xd7,
-	0x04, 0x61, 0x34, 0xdd, 0x17, 0x91, 0x08, 0xc4, 0x2b, 0x12, 0x6a, 0x3f, 0x53, 0x50, 0xde, 0x75,
-	0x4d, 0x9f, 0x11, 0xf6, 0x79, 0xca, 0x3c, 0x1f, 0xf7, 0x00, 0x7c, 0x93, 0x33, 0x8f, 0xb9, 0x26,
-	0xf3, 0x14, 0x54, 0x4b, 0xd7, 0x4b, 0xeb, 0xcb, 0x8d, 0xb9, 0x7d, 0xc3, 0x30, 0x39, 0xeb, 0x0b,
-	0xac, 0xb9, 0x72, 0x72, 0x5e, 0x95, 0x7e, 0x9f, 0x57, 0x71, 0xcf, 0x65, 0xd4, 0xb2, 0xec, 0xb1,
-	0xb1, 0xd0, 0x91, 0x1b, 0x1e, 0xf8, 0x25, 0xe4, 0xfa, 0xf6, 0xd4, 0x1d, 0x33, 0x25, 0x55, 0x43,
-	0xf5, 0xca, 0xfa, 0x6a, 0xe2, 0x76, 0xb3, 0x72, 0x23, 0x22, 0xb5, 0x26, 0x53, 0x4e, 0x62, 0x01,
-	0x7e, 0x05, 0x05, 0xce, 0x7c, 0xba, 0x47, 0x7d, 0xaa, 0xa4, 0x45, 0x2b, 0x4a, 0x22, 0x6e, 0x33,
-	0xdf, 0x35, 0xc7, 0xed, 0x18, 0x6f, 0x66, 0x4e, 0xce, 0xab, 0x88, 0x2c, 0xf8, 0xf8, 0x35, 0xac,
-	0x78, 0x9f, 0x4c, 0x67, 0x68, 0xd1, 0x11, 0xb3, 0x86, 0x13, 0xca, 0xd9, 0xf0, 0x88, 0x5a, 0xe6,
-	0x1e, 0xf5, 0x4d, 0x7b, 0xa2, 0x5c, 0xe5, 0x6b, 0xa8, 0x5e, 0x20, 0x0f, 0x42, 0xca, 0x4e, 0xc8,
-	0xe8, 0x50, 0xce, 0xde, 0x2f, 0x70, 0xad, 0x0a, 0x90, 0xf4, 0x83, 0xf3, 0x90, 0xd6, 0x7b, 0x5b,
-	0xb2, 0x84, 0x0b, 0x90, 0x21, 0x83, 0x9d, 0x96, 0x8c, 0xb4, 0xff, 0x60, 0x29, 0xee, 0xde, 0x73,
-	0xec, 0x89, 0xc7, 0xb4, 0x5f, 0x08, 0x20, 0x99, 0x0e, 0xd6, 0x21, 0x27, 0x2a, 0xcf, 0x67, 0xf8,
-	0x7f, 0xd2, 0xb8, 0xa8, 0xd7, 0xa3, 0xa6, 0xdb, 0x5c, 0x8e, 0x47, 0x58, 0x16, 0x29, 0x7d, 0x8f,
-	0x3a, 0x3e, 0x73, 0x49, 0x2c, 0xc4, 0x4f, 0x20, 0xef, 0x51, 0xee, 0x58, 0xcc, 0x53, 0x52, 0xc2,
-	0x43, 0x4e, 0x3c, 0xfa, 0x02, 0x10, 0x1f, 0x2d, 0x91, 0x39, 0x0d, 0x3f, 0x87, 0x22, 0x3b, 0x66,
-	0xdc, 0xb1, 0xa8, 0xeb, 0xc5, 0x03, 0xc3, 0x89, 0xa6, 0x15, 0x43, 0xb1, 0x2a, 0xa1, 0x6a, 0xcf,
-	0xa0, 0xb8, 0x68, 0x0a, 0x63, 0xc8, 0x84, 0xd3, 0x52, 0x50, 0x0d, 0xd5, 0xcb, 0x44, 0xbc, 0xf1,
-	0x32, 0x64, 0x8f, 0xa8, 0x35, 0x8d, 0x56, 0x58, 0x26, 0x51, 0xa0, 0xe9, 0x90, 0x8b, 0xfa, 0xc0,
-	0xab, 0x50, 0x16, 0x1b, 0xf7, 0x29, 0x77, 0x86, 0xdc, 0x13, 0xb4, 0x34, 0x29, 0x2d, 0x72, 0x6d,
-	0x2f, 0xb1, 0x08, 0x7d, 0xd1, 0xdc, 0xe2, 0x7b, 0x0a, 0x2a, 0xb7, 0x17, 0x89, 0x5f, 0x40, 0xc6,
-	0x9f, 0x39, 0x11, 0xaf, 0xb2, 0xfe, 0xf0, 0x5f, 0x0b, 0x8f, 0x43, 0x63, 0xe6, 0x30, 0x22, 0x04,
-	0xf8, 0x11, 0x60, 0x2e, 0x72, 0xc3, 0x7d, 0xca, 0x4d, 0x6b, 0x26, 0x96, 0x2e, 0x5a, 0x29, 0x12,
-	0x39, 0x42, 0x36, 0x04, 0x10, 0xee, 0x3a, 0xfc, 0xcc, 0x43, 0x66, 0x39, 0x4a, 0x46, 0xe0, 0xe2,
-	0x1d, 0xe6, 0xa6, 0x13, 0xd3, 0x57, 0xb2, 0x51, 0x2e, 0x7c, 0x6b, 0x33, 0x80, 0xa4, 0x12, 0x2e,
-	0x41, 0x7e, 0xd0, 0xd9, 0xee, 0x74, 0x77, 0x3b, 0xb2, 0x14, 0x06, 0x6f, 0xbb, 0x83, 0x8e, 0xd1,
-	0x22, 0x32, 0xc2, 0x45, 0xc8, 0x6e, 0xea, 0x83, 0xcd, 0x96, 0x9c, 0xc2, 0x4b, 0x50, 0x7c, 0xb7,
-	0xd5, 0x37, 0xba, 0x9b, 0x44, 0x6f, 0xcb, 0x69, 0x8c, 0xa1, 0x22, 0x90, 0x24, 0x97, 0x09, 0xa5,
-	0xfd, 0x41, 0xbb, 0xad, 0x93, 0x0f, 0x72, 0x36, 0xbc, 0xaa, 0xad, 0xce, 0x46, 0x57, 0xce, 0xe1,
-	0x32, 0x14, 0xfa, 0x86, 0x6e, 0xb4, 0xfa, 0x2d, 0x43, 0xce, 0x6b, 0xdb, 0x90, 0x8b, 0x4a, 0xdf,
-	0xc3, 0x35, 0x69, 0x5f, 0x11, 0x14, 0xe6, 0x17, 0x70, 0x1f, 0xd7, 0x79, 0xeb, 0x24, 0xe6, 0xfb,
-	0xbc, 0x73, 0x08, 0xe9, 0x3b, 0x87, 0xd0, 0x7c, 0x73, 0x7a, 0xa1, 0x4a, 0x67, 0x17, 0xaa, 0x74,
-	0x7d, 0xa1, 0xa2, 0x2f, 0x81, 0x8a, 0x7e, 0x04, 0x2a, 0x3a, 0x09, 0x54, 0x74, 0x1a, 0xa8, 0xe8,
-	0x4f, 0xa0, 0xa2, 0xab, 0x40, 0x95, 0xae, 0x03, 0x15, 0x7d, 0xbb, 0x54, 0xa5, 0xd3, 0x4b, 0x55,
-	0x3a, 0xbb, 0x54, 0xa5, 0x8f, 0x79, 0xf1, 0xbb, 0x73, 0x46, 0xa3, 0x9c, 0xf8, 0x71, 0x3d, 0xfd,
-	0x1b, 0x00, 0x00, 0xff, 0xff, 0x40, 0x35, 0x74, 0x55, 0x00, 0x05, 0x00, 0x00,
+	// 718 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xac, 0x54, 0xcd, 0x6e, 0xd3, 0x40,
+	0x10, 0xf6, 0xe6, 0x3f, 0x93, 0x34, 0x58, 0x4b, 0x25, 0xac, 0x1e, 0x9c, 0xd4, 0x5c, 0x72, 0x80,
+	0x14, 0x15, 0x01, 0x02, 0xc1, 0xc1, 0x41, 0x69, 0xa9, 0xda, 0xfc, 0x68, 0xe3, 0x50, 0xc1, 0x25,
+	0xda, 0xa4, 0xdb, 0xc6, 0xc2, 0x1b, 0x1b, 0xdb, 0xa9, 0x9a, 0x1b, 0x27, 0xce, 0x9c, 0x79, 0x02,
+	0x5e, 0x81, 0x37, 0xe8, 0xb1, 0x27, 0x54, 0x71, 0xa8, 0x68, 0x7a, 0x29, 0xb7, 0x3e, 0x02, 0xf2,
+	0xda, 0x89, 0x5b, 0x55, 0xdc, 0x7a, 0x9b, 0x99, 0xef, 0xfb, 0x66, 0x67, 0x67, 0x3f, 0x2d, 0x14,
+	0xb8, 0xc9, 0x4d, 0xb7, 0xe6, 0xb8, 0xb6, 0x6f, 0xe3, 0xdc, 0xd0, 0x76, 0x7d, 0x76, 0xe4, 0x0c,
+	0x56, 0x1e, 0x1f, 0x98, 0xfe, 0x68, 0x32, 0xa8, 0x0d, 0x6d, 0xbe, 0x76, 0x60, 0x1f, 0xd8, 0x6b,
+	0x82, 0x30, 0x98, 0xec, 0x8b, 0x4c, 0x24, 0x22, 0x0a, 0x85, 0xda, 0xaf, 0x04, 0x14, 0x77, 0x5d,
+	0xd3, 0x67, 0x84, 0x7d, 0x9e, 0x30, 0xcf, 0xc7, 0x1d, 0x00, 0xdf, 0xe4, 0xcc, 0x63, 0xae, 0xc9,
+	0x3c, 0x05, 0x55, 0x92, 0xd5, 0xc2, 0xfa, 0x72, 0x6d, 0xde, 0xbe, 0x66, 0x98, 0x9c, 0x75, 0x05,
+	0x56, 0x5f, 0x39, 0x3e, 0x2b, 0x4b, 0xbf, 0xcf, 0xca, 0xb8, 0xe3, 0x32, 0x6a, 0x59, 0xf6, 0xd0,
+	0x58, 0xe8, 0xc8, 0xb5, 0x1e, 0xf8, 0x25, 0x64, 0xba, 0xf6, 0xc4, 0x1d, 0x32, 0x25, 0x51, 0x41,
+	0xd5, 0xd2, 0xfa, 0x6a, 0xdc, 0xed, 0xfa, 0xc9, 0xb5, 0x90, 0xd4, 0x18, 0x4f, 0x38, 0x89, 0x04,
+	0xf8, 0x15, 0xe4, 0x38, 0xf3, 0xe9, 0x1e, 0xf5, 0xa9, 0x92, 0x14, 0xa3, 0x28, 0xb1, 0xb8, 0xc9,
+	0x7c, 0xd7, 0x1c, 0x36, 0x23, 0xbc, 0x9e, 0x3a, 0x3e, 0x2b, 0x23, 0xb2, 0xe0, 0xe3, 0xd7, 0xb0,
+	0xe2, 0x7d, 0x32, 0x9d, 0xbe, 0x45, 0x07, 0xcc, 0xea, 0x8f, 0x29, 0x67, 0xfd, 0x43, 0x6a, 0x99,
+	0x7b, 0xd4, 0x37, 0xed, 0xb1, 0x72, 0x99, 0xad, 0xa0, 0x6a, 0x8e, 0x3c, 0x08, 0x28, 0x3b, 0x01,
+	0xa3, 0x45, 0x39, 0x7b, 0xbf, 0xc0, 0xb1, 0x0a, 0x30, 0xa2, 0x7d, 0x97, 0x39, 0x96, 0x39, 0xa4,
+	0xca, 0xdf, 0x80, 0x9d, 0x27, 0xf9, 0x11, 0x25, 0x61, 0x45, 0x2b, 0x03, 0xc4, 0xf3, 0xe2, 0x2c,
+	0x24, 0xf5, 0xce, 0x96, 0x2c, 0xe1, 0x1c, 0xa4, 0x48, 0x6f, 0xa7, 0x21, 0x23, 0xed, 0x1e, 0x2c,
+	0x45, 0xb7, 0xf3, 0x1c, 0x7b, 0xec, 0x31, 0xed, 0x27, 0x02, 0x88, 0xb7, 0x87, 0x75, 0xc8, 0x88,
+	0xc9, 0xe6, 0x3b, 0xbe, 0x1f, 0x5f, 0x4c, 0xcc, 0xd3, 0xa1, 0xa6, 0x5b, 0x5f, 0x8e, 0x56, 0x5c,
+	0x14, 0x25, 0x7d, 0x8f, 0x3a, 0x3e, 0x73, 0x49, 0x24, 0xc4, 0x4f, 0x20, 0xeb, 0x51, 0xee, 0x58,
+	0xcc, 0x53, 0x12, 0xa2, 0x87, 0x1c, 0xf7, 0xe8, 0x0a, 0x40, 0x2c, 0x45, 0x22, 0x73, 0x1a, 0x7e,
+	0x0e, 0x79, 0x76, 0xc4, 0xb8, 0x63, 0x51, 0xd7, 0x8b, 0x16, 0x8a, 0x63, 0x4d, 0x23, 0x82, 0x22,
+	0x55, 0x4c, 0xd5, 0x9e, 0x41, 0x7e, 0x31, 0x14, 0xc6, 0x90, 0x0a, 0xb6, 0xa9, 0xa0, 0x0a, 0xaa,
+	0x16, 0x89, 0x88, 0xf1, 0x32, 0xa4, 0x0f, 0xa9, 0x35, 0x09, 0x9f, 0xb8, 0x48, 0xc2, 0x44, 0xd3,
+	0x21, 0x13, 0xce, 0x81, 0x57, 0xa1, 0x28, 0x1c, 0xe1, 0x53, 0xee, 0xf4, 0xb9, 0x27, 0x68, 0x49,
+	0x52, 0x58, 0xd4, 0x9a, 0x5e, 0xdc, 0x22, 0xe8, 0x8b, 0xe6, 0x2d, 0xbe, 0x27, 0xa0, 0x74, 0xf3,
+	0xa1, 0xf1, 0x0b, 0x48, 0xf9, 0x53, 0x27, 0xe4, 0x95, 0xd6, 0x1f, 0xfe, 0xcf, 0x10, 0x51, 0x6a,
+	0x4c, 0x1d, 0x46, 0x84, 0x00, 0x3f, 0x02, 0xcc, 0x45, 0xad, 0xbf, 0x4f, 0xb9, 0x69, 0x4d, 0x85,
+	0x29, 0xc4, 0x28, 0x79, 0x22, 0x87, 0xc8, 0x86, 0x00, 0x02, 0x2f, 0x04, 0xd7, 0x1c, 0x31, 0xcb,
+	0x51, 0x52, 0x02, 0x17, 0x71, 0x50, 0x9b, 0x8c, 0x4d, 0x5f, 0x49, 0x87, 0xb5, 0x20, 0xd6, 0xa6,
+	0x00, 0xf1, 0x49, 0xb8, 0x00, 0xd9, 0x5e, 0x6b, 0xbb, 0xd5, 0xde, 0x6d, 0xc9, 0x52, 0x90, 0xbc,
+	0x6d, 0xf7, 0x5a, 0x46, 0x83, 0xc8, 0x08, 0xe7, 0x21, 0xbd, 0xa9, 0xf7, 0x36, 0x1b, 0x72, 0x02,
+	0x2f, 0x41, 0xfe, 0xdd, 0x56, 0xd7, 0x68, 0x6f, 0x12, 0xbd, 0x29, 0x27, 0x31, 0x86, 0x92, 0x40,
+	0xe2, 0x5a, 0x2a, 0x90, 0x76, 0x7b, 0xcd, 0xa6, 0x4e, 0x3e, 0xc8, 0xe9, 0xc0, 0x55, 0x5b, 0xad,
+	0x8d, 0xb6, 0x9c, 0xc1, 0x45, 0xc8, 0x75, 0x0d, 0xdd, 0x68, 0x74, 0x1b, 0x86, 0x9c, 0xd5, 0xb6,
+	0x21, 0x13, 0x1e, 0x7d, 0x07, 0x6e, 0xd2, 0xbe, 0x22, 0xc8, 0xcd, 0x1d, 0x70, 0x17, 0xee, 0xbc,
+	0x61, 0x89, 0xf9, 0x7b, 0xde, 0x32, 0x42, 0xf2, 0x96, 0x11, 0xea, 0x6f, 0x4e, 0xce, 0x55, 0xe9,
+	0xf4, 0x5c, 0x95, 0xae, 0xce, 0x55, 0xf4, 0x65, 0xa6, 0xa2, 0x1f, 0x33, 0x15, 0x1d, 0xcf, 0x54,
+	0x74, 0x32, 0x53, 0xd1, 0x9f, 0x99, 0x8a, 0x2e, 0x67, 0xaa, 0x74, 0x35, 0x53, 0xd1, 0xb7, 0x0b,
+	0x55, 0x3a, 0xb9, 0x50, 0xa5, 0xd3, 0x0b, 0x55, 0xfa, 0x98, 0x15, 0xdf, 0xa1, 0x33, 0x18, 0x64,
+	0xc4, 0xc7, 0xf6, 0xf4, 0xdf, 0x00, 0x72, 0xcd, 0x23, 0x0f, 0x20, 0x05, 0x00, 0x00,
 }
 
 func (x WriteRequest_SourceEnum) String() string {
@@ -613,6 +623,9 @@ func (this *WriteRequest) Equal(that interface{}) bool {
 	if this.SkipLabelNameValidation != that1.SkipLabelNameValidation {
 		return false
 	}
+	if this.HaReplica != that1.HaReplica {
+		return false
+	}
 	return true
 }
 func (this *WriteResponse) Equal(that interface{}) bool {
@@ -836,7 +849,7 @@ func (this *WriteRequest) GoString() string {
 	if this == nil {
 		return "nil"
 	}
-	s := make([]string, 0, 8)
+	s := make([]string, 0, 9)
 	s = append(s, "&mimirpb.WriteRequest{")
 	s = append(s, "Timeseries: "+fmt.Sprintf("%#v", this.Timeseries)+",\n")
 	s = append(s, "Source: "+fmt.Sprintf("%#v", this.Source)+",\n")
@@ -844,6 +857,7 @@ func (this *WriteRequest) GoString() string {
 		s = append(s, "Metadata: "+fmt.Sprintf("%#v", this.Metadata)+",\n")
 	}
 	s = append(s, "SkipLabelNameValidation: "+fmt.Sprintf("%#v", this.SkipLabelNameValidation)+",\n")
+	s = append(s, "HaReplica: "+fmt.Sprintf("%#v", this.HaReplica)+",\n")
 	s = append(s, "}")
 	return strings.Join(s, "")
 }
@@ -965,6 +979,15 @@ func (m *WriteRequest) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 	_ = i
 	var l int
 	_ = l
+	if len(m.HaReplica) > 0 {
+		i -= len(m.HaReplica)
+		copy(dAtA[i:], m.HaReplica)
+		i = encodeVarintMimir(dAtA, i, uint64(len(m.HaReplica)))
+		i--
+		dAtA[i] = 0x3e
+		i--
+		dAtA[i] = 0xca
+	}
 	if m.SkipLabelNameValidation {
 		i--
 		if m.SkipLabelNameValidation {
@@ -1341,6 +1364,10 @@ func (m *WriteRequest) Size() (n int) {
 	if m.SkipLabelNameValidation {
 		n += 3
 	}
+	l = len(m.HaReplica)
+	if l > 0 {
+		n += 2 + l + sovMimir(uint64(l))
+	}
 	return n
 }
 
@@ -1492,6 +1519,7 @@ func (this *WriteRequest) String() string {
 		`Source:` + fmt.Sprintf("%v", this.Source) + `,`,
 		`Metadata:` + repeatedStringForMetadata + `,`,
 		`SkipLabelNameValidation:` + fmt.Sprintf("%v", this.SkipLabelNameValidation) + `,`,
+		`HaReplica:` + fmt.Sprintf("%v", this.HaReplica) + `,`,
 		`}`,
 	}, "")
 	return s
@@ -1728,6 +1756,38 @@ func (m *WriteRequest) Unmarshal(dAtA []byte) error {
 				}
 			}
 			m.SkipLabelNameValidation = bool(v != 0)
+		case 1001:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field HaReplica", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMimir
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthMimir
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthMimir
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.HaReplica = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
 		default:
 			iNdEx = preIndex
 			skippy, err := skipMimir(dAtA[iNdEx:])
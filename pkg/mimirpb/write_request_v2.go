@@ -0,0 +1,458 @@
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package mimirpb
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"sync"
+
+	"github.com/prometheus/prometheus/model/labels"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// Field numbers of the remote write 2.0 protocol messages, as defined by the io.prometheus.write.v2 protobuf package.
+const (
+	writeV2RequestSymbolsField    = 4
+	writeV2RequestTimeseriesField = 5
+
+	writeV2TimeSeriesLabelsRefsField       = 1
+	writeV2TimeSeriesSamplesField          = 2
+	writeV2TimeSeriesHistogramsField       = 3
+	writeV2TimeSeriesExemplarsField        = 4
+	writeV2TimeSeriesMetadataField         = 5
+	writeV2TimeSeriesCreatedTimestampField = 6
+
+	writeV2SampleValueField     = 1
+	writeV2SampleTimestampField = 2
+
+	writeV2ExemplarLabelsRefsField = 1
+	writeV2ExemplarValueField      = 2
+	writeV2ExemplarTimestampField  = 3
+
+	writeV2MetadataTypeField    = 1
+	writeV2MetadataHelpRefField = 3
+	writeV2MetadataUnitRefField = 4
+)
+
+const expectedSymbols = 1024
+
+var (
+	errWriteV2InvalidSymbolsTable = errors.New("invalid remote write 2.0 request: the first symbol must be an empty string")
+	errWriteV2OddLabelsRefs       = errors.New("invalid remote write 2.0 request: odd number of label references")
+
+	symbolsPool = sync.Pool{
+		New: func() interface{} {
+			return make([]string, 0, expectedSymbols)
+		},
+	}
+)
+
+// PreallocWriteRequestV2 is a PreallocWriteRequest which is unmarshalled from a remote write 2.0 request
+// (io.prometheus.write.v2.Request) instead of a WriteRequest.
+//
+// Remote write 2.0 requests contain a table of symbols, referenced by the labels, metadata and exemplars of each series.
+// The request is decoded in a streaming fashion directly into a PreallocWriteRequest: the strings referencing the
+// symbols are not copied, and point into the buffer in input, which must not be modified or reused until the
+// PreallocWriteRequest isn't used anymore, like in PreallocWriteRequest.Unmarshal. The per-series metadata is
+// converted into the request metadata, once per metric family. Native histograms and created timestamps aren't
+// supported and are skipped.
+type PreallocWriteRequestV2 struct {
+	*PreallocWriteRequest
+
+	// SkippedHistograms is the number of native histograms skipped while unmarshalling the request.
+	SkippedHistograms int
+}
+
+// Unmarshal implements proto.Unmarshaler.
+func (p *PreallocWriteRequestV2) Unmarshal(dAtA []byte) error {
+	p.Timeseries = PreallocTimeseriesSliceFromPool()
+
+	symbols := symbolsPool.Get().([]string)
+	defer func() {
+		// Clear the references to the buffer, to allow GC.
+		for i := range symbols {
+			symbols[i] = ""
+		}
+		symbolsPool.Put(symbols[:0]) //nolint:staticcheck // see comment on slicePool
+	}()
+
+	var err error
+	symbols, err = unmarshalWriteV2Symbols(dAtA, symbols)
+	if err != nil {
+		return err
+	}
+	if len(symbols) > 0 && symbols[0] != "" {
+		return errWriteV2InvalidSymbolsTable
+	}
+
+	u := writeV2Unmarshaller{
+		symbols:          symbols,
+		metadataFamilies: map[string]struct{}{},
+	}
+	defer func() {
+		p.SkippedHistograms = u.skippedHistograms
+	}()
+
+	for len(dAtA) > 0 {
+		num, typ, n := protowire.ConsumeTag(dAtA)
+		if n < 0 {
+			return protowire.ParseError(n)
+		}
+		dAtA = dAtA[n:]
+
+		if num == writeV2RequestTimeseriesField && typ == protowire.BytesType {
+			v, n := protowire.ConsumeBytes(dAtA)
+			if n < 0 {
+				return protowire.ParseError(n)
+			}
+			dAtA = dAtA[n:]
+
+			ts := TimeseriesFromPool()
+			p.Timeseries = append(p.Timeseries, PreallocTimeseries{TimeSeries: ts})
+			if err := u.unmarshalTimeSeries(v, ts, &p.WriteRequest); err != nil {
+				return err
+			}
+			continue
+		}
+
+		n = protowire.ConsumeFieldValue(num, typ, dAtA)
+		if n < 0 {
+			return protowire.ParseError(n)
+		}
+		dAtA = dAtA[n:]
+	}
+
+	return nil
+}
+
+// unmarshalWriteV2Symbols appends the symbols of the request in input to dst. The symbols are decoded
+// before the series, since the protobuf encoding doesn't guarantee that they come first in the request.
+func unmarshalWriteV2Symbols(dAtA []byte, dst []string) ([]string, error) {
+	for len(dAtA) > 0 {
+		num, typ, n := protowire.ConsumeTag(dAtA)
+		if n < 0 {
+			return dst, protowire.ParseError(n)
+		}
+		dAtA = dAtA[n:]
+
+		if num == writeV2RequestSymbolsField && typ == protowire.BytesType {
+			v, n := protowire.ConsumeBytes(dAtA)
+			if n < 0 {
+				return dst, protowire.ParseError(n)
+			}
+			dAtA = dAtA[n:]
+			dst = append(dst, yoloString(v))
+			continue
+		}
+
+		n = protowire.ConsumeFieldValue(num, typ, dAtA)
+		if n < 0 {
+			return dst, protowire.ParseError(n)
+		}
+		dAtA = dAtA[n:]
+	}
+	return dst, nil
+}
+
+type writeV2Unmarshaller struct {
+	symbols []string
+
+	// Scratch buffer of label references, reused across series and exemplars.
+	refs []uint64
+
+	// Metric families whose metadata has already been added to the request.
+	metadataFamilies map[string]struct{}
+
+	// Number of native histograms skipped, since they aren't supported.
+	skippedHistograms int
+}
+
+func (u *writeV2Unmarshaller) symbol(ref uint64) (string, error) {
+	if ref >= uint64(len(u.symbols)) {
+		return "", fmt.Errorf("invalid remote write 2.0 request: symbol reference %d out of range, the request contains %d symbols", ref, len(u.symbols))
+	}
+	return u.symbols[ref], nil
+}
+
+// consumeRefs appends to u.refs the label references at the beginning of dAtA, either packed or not,
+// and returns the number of bytes consumed.
+func (u *writeV2Unmarshaller) consumeRefs(typ protowire.Type, dAtA []byte) int {
+	if typ == protowire.VarintType {
+		ref, n := protowire.ConsumeVarint(dAtA)
+		if n >= 0 {
+			u.refs = append(u.refs, ref)
+		}
+		return n
+	}
+	if typ != protowire.BytesType {
+		return protowire.ConsumeFieldValue(writeV2TimeSeriesLabelsRefsField, typ, dAtA)
+	}
+
+	packed, n := protowire.ConsumeBytes(dAtA)
+	if n < 0 {
+		return n
+	}
+	for len(packed) > 0 {
+		ref, m := protowire.ConsumeVarint(packed)
+		if m < 0 {
+			return m
+		}
+		packed = packed[m:]
+		u.refs = append(u.refs, ref)
+	}
+	return n
+}
+
+// appendLabels appends to dst the labels referenced by u.refs.
+func (u *writeV2Unmarshaller) appendLabels(dst []LabelAdapter) ([]LabelAdapter, error) {
+	if len(u.refs)%2 != 0 {
+		return dst, errWriteV2OddLabelsRefs
+	}
+	for i := 0; i < len(u.refs); i += 2 {
+		name, err := u.symbol(u.refs[i])
+		if err != nil {
+			return dst, err
+		}
+		value, err := u.symbol(u.refs[i+1])
+		if err != nil {
+			return dst, err
+		}
+		dst = append(dst, LabelAdapter{Name: name, Value: value})
+	}
+	return dst, nil
+}
+
+func (u *writeV2Unmarshaller) unmarshalTimeSeries(dAtA []byte, ts *TimeSeries, req *WriteRequest) error {
+	var (
+		metadata    []byte
+		hasMetadata bool
+	)
+
+	u.refs = u.refs[:0]
+	for len(dAtA) > 0 {
+		num, typ, n := protowire.ConsumeTag(dAtA)
+		if n < 0 {
+			return protowire.ParseError(n)
+		}
+		dAtA = dAtA[n:]
+
+		switch {
+		case num == writeV2TimeSeriesLabelsRefsField:
+			n = u.consumeRefs(typ, dAtA)
+
+		case num == writeV2TimeSeriesSamplesField && typ == protowire.BytesType:
+			var v []byte
+			if v, n = protowire.ConsumeBytes(dAtA); n >= 0 {
+				sample, err := unmarshalWriteV2Sample(v)
+				if err != nil {
+					return err
+				}
+				ts.Samples = append(ts.Samples, sample)
+			}
+
+		case num == writeV2TimeSeriesExemplarsField && typ == protowire.BytesType:
+			var v []byte
+			if v, n = protowire.ConsumeBytes(dAtA); n >= 0 {
+				// The exemplar labels references are decoded in the same scratch buffer, after the series ones.
+				exemplar, err := u.unmarshalExemplar(v)
+				if err != nil {
+					return err
+				}
+				ts.Exemplars = append(ts.Exemplars, exemplar)
+			}
+
+		case num == writeV2TimeSeriesMetadataField && typ == protowire.BytesType:
+			// The metadata is converted once the metric name is known.
+			metadata, n = protowire.ConsumeBytes(dAtA)
+			hasMetadata = true
+
+		case num == writeV2TimeSeriesHistogramsField:
+			// The ingesters don't support native histograms, so they're skipped and counted as discarded.
+			n = protowire.ConsumeFieldValue(num, typ, dAtA)
+			u.skippedHistograms++
+
+		case num == writeV2TimeSeriesCreatedTimestampField:
+			// The ingesters don't support created timestamps, so they're skipped.
+			n = protowire.ConsumeFieldValue(num, typ, dAtA)
+
+		default:
+			n = protowire.ConsumeFieldValue(num, typ, dAtA)
+		}
+
+		if n < 0 {
+			return protowire.ParseError(n)
+		}
+		dAtA = dAtA[n:]
+	}
+
+	var err error
+	if ts.Labels, err = u.appendLabels(ts.Labels); err != nil {
+		return err
+	}
+
+	if hasMetadata {
+		return u.appendMetadata(req, ts.Labels, metadata)
+	}
+	return nil
+}
+
+func unmarshalWriteV2Sample(dAtA []byte) (Sample, error) {
+	var s Sample
+	for len(dAtA) > 0 {
+		num, typ, n := protowire.ConsumeTag(dAtA)
+		if n < 0 {
+			return s, protowire.ParseError(n)
+		}
+		dAtA = dAtA[n:]
+
+		switch {
+		case num == writeV2SampleValueField && typ == protowire.Fixed64Type:
+			var v uint64
+			v, n = protowire.ConsumeFixed64(dAtA)
+			s.Value = math.Float64frombits(v)
+		case num == writeV2SampleTimestampField && typ == protowire.VarintType:
+			var v uint64
+			v, n = protowire.ConsumeVarint(dAtA)
+			s.TimestampMs = int64(v)
+		default:
+			n = protowire.ConsumeFieldValue(num, typ, dAtA)
+		}
+
+		if n < 0 {
+			return s, protowire.ParseError(n)
+		}
+		dAtA = dAtA[n:]
+	}
+	return s, nil
+}
+
+// unmarshalExemplar decodes an exemplar. The labels references of the series, already decoded in u.refs, are preserved.
+func (u *writeV2Unmarshaller) unmarshalExemplar(dAtA []byte) (Exemplar, error) {
+	var e Exemplar
+
+	seriesRefs := u.refs
+	u.refs = u.refs[len(u.refs):]
+	defer func() {
+		u.refs = seriesRefs
+	}()
+
+	for len(dAtA) > 0 {
+		num, typ, n := protowire.ConsumeTag(dAtA)
+		if n < 0 {
+			return e, protowire.ParseError(n)
+		}
+		dAtA = dAtA[n:]
+
+		switch {
+		case num == writeV2ExemplarLabelsRefsField:
+			n = u.consumeRefs(typ, dAtA)
+		case num == writeV2ExemplarValueField && typ == protowire.Fixed64Type:
+			var v uint64
+			v, n = protowire.ConsumeFixed64(dAtA)
+			e.Value = math.Float64frombits(v)
+		case num == writeV2ExemplarTimestampField && typ == protowire.VarintType:
+			var v uint64
+			v, n = protowire.ConsumeVarint(dAtA)
+			e.TimestampMs = int64(v)
+		default:
+			n = protowire.ConsumeFieldValue(num, typ, dAtA)
+		}
+
+		if n < 0 {
+			return e, protowire.ParseError(n)
+		}
+		dAtA = dAtA[n:]
+	}
+
+	var err error
+	if len(u.refs) > 0 {
+		e.Labels, err = u.appendLabels(make([]LabelAdapter, 0, len(u.refs)/2))
+	}
+	return e, err
+}
+
+// appendMetadata adds the metadata of a series to the request, unless the metadata of its metric family
+// has already been added.
+func (u *writeV2Unmarshaller) appendMetadata(req *WriteRequest, series []LabelAdapter, dAtA []byte) error {
+	var (
+		metricType       MetricMetadata_MetricType
+		helpRef, unitRef uint64
+	)
+
+	for len(dAtA) > 0 {
+		num, typ, n := protowire.ConsumeTag(dAtA)
+		if n < 0 {
+			return protowire.ParseError(n)
+		}
+		dAtA = dAtA[n:]
+
+		switch {
+		case num == writeV2MetadataTypeField && typ == protowire.VarintType:
+			var v uint64
+			v, n = protowire.ConsumeVarint(dAtA)
+			metricType = MetricMetadata_MetricType(v)
+		case num == writeV2MetadataHelpRefField && typ == protowire.VarintType:
+			helpRef, n = protowire.ConsumeVarint(dAtA)
+		case num == writeV2MetadataUnitRefField && typ == protowire.VarintType:
+			unitRef, n = protowire.ConsumeVarint(dAtA)
+		default:
+			n = protowire.ConsumeFieldValue(num, typ, dAtA)
+		}
+
+		if n < 0 {
+			return protowire.ParseError(n)
+		}
+		dAtA = dAtA[n:]
+	}
+
+	help, err := u.symbol(helpRef)
+	if err != nil {
+		return err
+	}
+	unit, err := u.symbol(unitRef)
+	if err != nil {
+		return err
+	}
+	if metricType == UNKNOWN && help == "" && unit == "" {
+		return nil
+	}
+
+	family := metricFamilyName(FromLabelAdaptersToLabels(series).Get(labels.MetricName), metricType)
+	if family == "" {
+		return nil
+	}
+	if _, ok := u.metadataFamilies[family]; ok {
+		return nil
+	}
+	u.metadataFamilies[family] = struct{}{}
+
+	req.Metadata = append(req.Metadata, &MetricMetadata{
+		Type:             metricType,
+		MetricFamilyName: family,
+		Help:             help,
+		Unit:             unit,
+	})
+	return nil
+}
+
+// metricFamilyName returns the name of the metric family of a series with the given metric name and type:
+// the series of histograms and summaries have a suffix which isn't part of the metric family name.
+func metricFamilyName(metricName string, metricType MetricMetadata_MetricType) string {
+	var suffixes []string
+	switch metricType {
+	case HISTOGRAM, GAUGEHISTOGRAM:
+		suffixes = []string{"_bucket", "_sum", "_count"}
+	case SUMMARY:
+		suffixes = []string{"_sum", "_count"}
+	}
+
+	for _, suffix := range suffixes {
+		if strings.HasSuffix(metricName, suffix) {
+			return strings.TrimSuffix(metricName, suffix)
+		}
+	}
+	return metricName
+}
@@ -0,0 +1,272 @@
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package mimirpb
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// writeV2Series is the remote write 2.0 representation of a series, used to encode test requests.
+type writeV2Series struct {
+	labelsRefs       []uint64
+	samples          []Sample
+	exemplars        []writeV2Exemplar
+	metadata         *writeV2Metadata
+	createdTimestamp int64
+	histograms       int
+}
+
+type writeV2Exemplar struct {
+	labelsRefs  []uint64
+	value       float64
+	timestampMs int64
+}
+
+type writeV2Metadata struct {
+	metricType       MetricMetadata_MetricType
+	helpRef, unitRef uint64
+}
+
+func appendWriteV2Refs(b []byte, num protowire.Number, refs []uint64, packed bool) []byte {
+	if !packed {
+		for _, ref := range refs {
+			b = protowire.AppendTag(b, num, protowire.VarintType)
+			b = protowire.AppendVarint(b, ref)
+		}
+		return b
+	}
+
+	var p []byte
+	for _, ref := range refs {
+		p = protowire.AppendVarint(p, ref)
+	}
+	b = protowire.AppendTag(b, num, protowire.BytesType)
+	return protowire.AppendBytes(b, p)
+}
+
+func appendWriteV2Series(b []byte, s writeV2Series, packed bool) []byte {
+	var ts []byte
+	ts = appendWriteV2Refs(ts, writeV2TimeSeriesLabelsRefsField, s.labelsRefs, packed)
+	for _, sample := range s.samples {
+		var v []byte
+		v = protowire.AppendTag(v, writeV2SampleValueField, protowire.Fixed64Type)
+		v = protowire.AppendFixed64(v, math.Float64bits(sample.Value))
+		v = protowire.AppendTag(v, writeV2SampleTimestampField, protowire.VarintType)
+		v = protowire.AppendVarint(v, uint64(sample.TimestampMs))
+		ts = protowire.AppendTag(ts, writeV2TimeSeriesSamplesField, protowire.BytesType)
+		ts = protowire.AppendBytes(ts, v)
+	}
+	for i := 0; i < s.histograms; i++ {
+		// Native histograms are skipped, so their content doesn't matter.
+		ts = protowire.AppendTag(ts, writeV2TimeSeriesHistogramsField, protowire.BytesType)
+		ts = protowire.AppendBytes(ts, []byte{0x08, 0x01})
+	}
+	for _, e := range s.exemplars {
+		var v []byte
+		v = appendWriteV2Refs(v, writeV2ExemplarLabelsRefsField, e.labelsRefs, packed)
+		v = protowire.AppendTag(v, writeV2ExemplarValueField, protowire.Fixed64Type)
+		v = protowire.AppendFixed64(v, math.Float64bits(e.value))
+		v = protowire.AppendTag(v, writeV2ExemplarTimestampField, protowire.VarintType)
+		v = protowire.AppendVarint(v, uint64(e.timestampMs))
+		ts = protowire.AppendTag(ts, writeV2TimeSeriesExemplarsField, protowire.BytesType)
+		ts = protowire.AppendBytes(ts, v)
+	}
+	if s.metadata != nil {
+		var v []byte
+		v = protowire.AppendTag(v, writeV2MetadataTypeField, protowire.VarintType)
+		v = protowire.AppendVarint(v, uint64(s.metadata.metricType))
+		v = protowire.AppendTag(v, writeV2MetadataHelpRefField, protowire.VarintType)
+		v = protowire.AppendVarint(v, s.metadata.helpRef)
+		v = protowire.AppendTag(v, writeV2MetadataUnitRefField, protowire.VarintType)
+		v = protowire.AppendVarint(v, s.metadata.unitRef)
+		ts = protowire.AppendTag(ts, writeV2TimeSeriesMetadataField, protowire.BytesType)
+		ts = protowire.AppendBytes(ts, v)
+	}
+	if s.createdTimestamp != 0 {
+		ts = protowire.AppendTag(ts, writeV2TimeSeriesCreatedTimestampField, protowire.VarintType)
+		ts = protowire.AppendVarint(ts, uint64(s.createdTimestamp))
+	}
+
+	b = protowire.AppendTag(b, writeV2RequestTimeseriesField, protowire.BytesType)
+	return protowire.AppendBytes(b, ts)
+}
+
+func appendWriteV2Symbols(b []byte, symbols []string) []byte {
+	for _, s := range symbols {
+		b = protowire.AppendTag(b, writeV2RequestSymbolsField, protowire.BytesType)
+		b = protowire.AppendString(b, s)
+	}
+	return b
+}
+
+func marshalWriteV2Request(symbols []string, series []writeV2Series, symbolsLast, packed bool) []byte {
+	var b []byte
+	if !symbolsLast {
+		b = appendWriteV2Symbols(b, symbols)
+	}
+	for _, s := range series {
+		b = appendWriteV2Series(b, s, packed)
+	}
+	if symbolsLast {
+		b = appendWriteV2Symbols(b, symbols)
+	}
+	return b
+}
+
+func TestPreallocWriteRequestV2_Unmarshal(t *testing.T) {
+	symbols := []string{"", "__name__", "http_requests_total", "job", "api", "Total HTTP requests.", "trace_id", "abc", "request_duration_seconds_bucket", "le", "+Inf", "seconds"}
+	series := []writeV2Series{
+		{
+			labelsRefs:       []uint64{1, 2, 3, 4},
+			samples:          []Sample{{TimestampMs: 1000, Value: 1}, {TimestampMs: 2000, Value: 2}},
+			exemplars:        []writeV2Exemplar{{labelsRefs: []uint64{6, 7}, value: 2, timestampMs: 1500}},
+			metadata:         &writeV2Metadata{metricType: COUNTER, helpRef: 5},
+			createdTimestamp: 500,
+		},
+		{
+			// Same metric family: the metadata isn't added twice.
+			labelsRefs: []uint64{1, 2, 3, 2},
+			samples:    []Sample{{TimestampMs: 1000, Value: 3}},
+			metadata:   &writeV2Metadata{metricType: COUNTER, helpRef: 5},
+		},
+		{
+			labelsRefs: []uint64{1, 8, 9, 10},
+			samples:    []Sample{{TimestampMs: 1000, Value: 4}},
+			metadata:   &writeV2Metadata{metricType: HISTOGRAM, unitRef: 11},
+			histograms: 1,
+		},
+		{
+			// No metadata.
+			labelsRefs: []uint64{1, 4},
+			histograms: 2,
+			metadata:   &writeV2Metadata{},
+		},
+	}
+
+	expected := WriteRequest{
+		Timeseries: []PreallocTimeseries{
+			{TimeSeries: &TimeSeries{
+				Labels:    []LabelAdapter{{Name: "__name__", Value: "http_requests_total"}, {Name: "job", Value: "api"}},
+				Samples:   []Sample{{TimestampMs: 1000, Value: 1}, {TimestampMs: 2000, Value: 2}},
+				Exemplars: []Exemplar{{Labels: []LabelAdapter{{Name: "trace_id", Value: "abc"}}, Value: 2, TimestampMs: 1500}},
+			}},
+			{TimeSeries: &TimeSeries{
+				Labels:    []LabelAdapter{{Name: "__name__", Value: "http_requests_total"}, {Name: "job", Value: "http_requests_total"}},
+				Samples:   []Sample{{TimestampMs: 1000, Value: 3}},
+				Exemplars: []Exemplar{},
+			}},
+			{TimeSeries: &TimeSeries{
+				Labels:    []LabelAdapter{{Name: "__name__", Value: "request_duration_seconds_bucket"}, {Name: "le", Value: "+Inf"}},
+				Samples:   []Sample{{TimestampMs: 1000, Value: 4}},
+				Exemplars: []Exemplar{},
+			}},
+			{TimeSeries: &TimeSeries{
+				Labels:    []LabelAdapter{{Name: "__name__", Value: "api"}},
+				Samples:   []Sample{},
+				Exemplars: []Exemplar{},
+			}},
+		},
+		Metadata: []*MetricMetadata{
+			{Type: COUNTER, MetricFamilyName: "http_requests_total", Help: "Total HTTP requests."},
+			{Type: HISTOGRAM, MetricFamilyName: "request_duration_seconds", Unit: "seconds"},
+		},
+	}
+
+	for _, symbolsLast := range []bool{false, true} {
+		for _, packed := range []bool{false, true} {
+			req := PreallocWriteRequestV2{PreallocWriteRequest: &PreallocWriteRequest{}}
+			require.NoError(t, req.Unmarshal(marshalWriteV2Request(symbols, series, symbolsLast, packed)))
+
+			require.Len(t, req.Timeseries, len(expected.Timeseries))
+			for i := range expected.Timeseries {
+				assert.Equal(t, expected.Timeseries[i].Labels, req.Timeseries[i].Labels)
+				assert.Equal(t, expected.Timeseries[i].Samples, req.Timeseries[i].Samples)
+				assert.Equal(t, expected.Timeseries[i].Exemplars, req.Timeseries[i].Exemplars)
+			}
+			assert.Equal(t, expected.Metadata, req.Metadata)
+			assert.Equal(t, 3, req.SkippedHistograms)
+
+			ReuseSlice(req.Timeseries)
+		}
+	}
+}
+
+func TestPreallocWriteRequestV2_UnmarshalInvalid(t *testing.T) {
+	tests := map[string]struct {
+		symbols     []string
+		series      []writeV2Series
+		expectedErr string
+	}{
+		"first symbol not empty": {
+			symbols:     []string{"__name__", "foo"},
+			series:      []writeV2Series{{labelsRefs: []uint64{0, 1}}},
+			expectedErr: errWriteV2InvalidSymbolsTable.Error(),
+		},
+		"odd number of label references": {
+			symbols:     []string{"", "__name__", "foo"},
+			series:      []writeV2Series{{labelsRefs: []uint64{1, 2, 1}}},
+			expectedErr: errWriteV2OddLabelsRefs.Error(),
+		},
+		"label reference out of range": {
+			symbols:     []string{"", "__name__", "foo"},
+			series:      []writeV2Series{{labelsRefs: []uint64{1, 3}}},
+			expectedErr: "invalid remote write 2.0 request: symbol reference 3 out of range, the request contains 3 symbols",
+		},
+		"exemplar label reference out of range": {
+			symbols:     []string{"", "__name__", "foo"},
+			series:      []writeV2Series{{labelsRefs: []uint64{1, 2}, exemplars: []writeV2Exemplar{{labelsRefs: []uint64{1, 5}}}}},
+			expectedErr: "invalid remote write 2.0 request: symbol reference 5 out of range, the request contains 3 symbols",
+		},
+		"metadata reference out of range": {
+			symbols:     []string{"", "__name__", "foo"},
+			series:      []writeV2Series{{labelsRefs: []uint64{1, 2}, metadata: &writeV2Metadata{metricType: GAUGE, helpRef: 10}}},
+			expectedErr: "invalid remote write 2.0 request: symbol reference 10 out of range, the request contains 3 symbols",
+		},
+	}
+
+	for testName, testData := range tests {
+		t.Run(testName, func(t *testing.T) {
+			req := PreallocWriteRequestV2{PreallocWriteRequest: &PreallocWriteRequest{}}
+			err := req.Unmarshal(marshalWriteV2Request(testData.symbols, testData.series, false, true))
+			require.EqualError(t, err, testData.expectedErr)
+		})
+	}
+
+	t.Run("truncated request", func(t *testing.T) {
+		data := marshalWriteV2Request([]string{"", "__name__", "foo"}, []writeV2Series{{labelsRefs: []uint64{1, 2}}}, false, true)
+
+		req := PreallocWriteRequestV2{PreallocWriteRequest: &PreallocWriteRequest{}}
+		require.Error(t, req.Unmarshal(data[:len(data)-1]))
+	})
+}
+
+func BenchmarkPreallocWriteRequestV2_Unmarshal(b *testing.B) {
+	const numSeries = 1000
+
+	symbols := []string{"", "__name__", "metric", "job", "api", "instance"}
+	series := make([]writeV2Series, 0, numSeries)
+	for i := 0; i < numSeries; i++ {
+		symbols = append(symbols, "instance-"+string(rune('a'+i%26))+string(rune('a'+i/26%26)))
+		series = append(series, writeV2Series{
+			labelsRefs: []uint64{1, 2, 3, 4, 5, uint64(len(symbols) - 1)},
+			samples:    []Sample{{TimestampMs: int64(i), Value: float64(i)}},
+			metadata:   &writeV2Metadata{metricType: GAUGE},
+		})
+	}
+	data := marshalWriteV2Request(symbols, series, false, true)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		req := PreallocWriteRequestV2{PreallocWriteRequest: &PreallocWriteRequest{}}
+		if err := req.Unmarshal(data); err != nil {
+			b.Fatal(err)
+		}
+		ReuseSlice(req.Timeseries)
+	}
+}
@@ -0,0 +1,199 @@
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package purger
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/thanos-io/thanos/pkg/objstore"
+
+	"github.com/grafana/dskit/tenant"
+
+	"github.com/grafana/mimir/pkg/storage/bucket"
+	mimir_tsdb "github.com/grafana/mimir/pkg/storage/tsdb"
+	"github.com/grafana/mimir/pkg/util"
+)
+
+// SeriesDeletionAPI exposes the API to create, list and cancel series deletion requests.
+// Requests are stored as tombstones in the tenant's location in the bucket. Queriers
+// mask matching data as soon as they load them, while the compactor rewrites the
+// affected blocks.
+type SeriesDeletionAPI struct {
+	bucketClient objstore.Bucket
+	logger       log.Logger
+	cfgProvider  bucket.TenantConfigProvider
+
+	deleteRequestsReceived *prometheus.CounterVec
+}
+
+func NewSeriesDeletionAPI(storageCfg mimir_tsdb.BlocksStorageConfig, cfgProvider bucket.TenantConfigProvider, logger log.Logger, reg prometheus.Registerer) (*SeriesDeletionAPI, error) {
+	bucketClient, err := createBucketClient(storageCfg, "series-deletion", logger, reg)
+	if err != nil {
+		return nil, err
+	}
+
+	return newSeriesDeletionAPI(bucketClient, cfgProvider, logger, reg), nil
+}
+
+func newSeriesDeletionAPI(bkt objstore.Bucket, cfgProvider bucket.TenantConfigProvider, logger log.Logger, reg prometheus.Registerer) *SeriesDeletionAPI {
+	return &SeriesDeletionAPI{
+		bucketClient: bkt,
+		cfgProvider:  cfgProvider,
+		logger:       logger,
+
+		deleteRequestsReceived: promauto.With(reg).NewCounterVec(prometheus.CounterOpts{
+			Name: "cortex_purger_series_delete_requests_received_total",
+			Help: "Total number of series delete requests received per user.",
+		}, []string{"user"}),
+	}
+}
+
+// AddDeleteRequest creates a series deletion request. The request is idempotent: submitting
+// the same selectors and time range again doesn't create a new request.
+func (api *SeriesDeletionAPI) AddDeleteRequest(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	userID, err := tenant.TenantID(ctx)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	selectors := r.Form["match[]"]
+	if len(selectors) == 0 {
+		http.Error(w, "selectors not set", http.StatusBadRequest)
+		return
+	}
+
+	now := time.Now()
+	startTime := int64(0)
+	if start := r.FormValue("start"); start != "" {
+		startTime, err = util.ParseTime(start)
+		if err != nil {
+			http.Error(w, "invalid start time: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+
+	// Series can't be deleted in the future, so the end time is capped to now.
+	endTime := util.TimeToMillis(now)
+	if end := r.FormValue("end"); end != "" {
+		parsed, err := util.ParseTime(end)
+		if err != nil {
+			http.Error(w, "invalid end time: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+		if parsed < endTime {
+			endTime = parsed
+		}
+	}
+
+	t, err := mimir_tsdb.NewTombstone(now, startTime, endTime, selectors)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	existing, err := mimir_tsdb.ReadTombstone(ctx, api.bucketClient, userID, t.RequestID)
+	if err != nil {
+		level.Error(api.logger).Log("msg", "failed to read tombstone", "user", userID, "request_id", t.RequestID, "err", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if existing != nil && existing.State != mimir_tsdb.TombstoneCancelled {
+		level.Info(api.logger).Log("msg", "series deletion request already exists", "user", userID, "request_id", t.RequestID, "state", existing.State)
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	if err := mimir_tsdb.WriteTombstone(ctx, api.bucketClient, userID, api.cfgProvider, t); err != nil {
+		level.Error(api.logger).Log("msg", "failed to write tombstone", "user", userID, "request_id", t.RequestID, "err", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	api.deleteRequestsReceived.WithLabelValues(userID).Inc()
+	level.Info(api.logger).Log("msg", "series deletion request created", "user", userID, "request_id", t.RequestID, "selectors", fmt.Sprintf("%v", selectors), "start", util.FormatTimeMillis(startTime), "end", util.FormatTimeMillis(endTime))
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// GetAllDeleteRequests lists all the series deletion requests of the tenant, including their state.
+func (api *SeriesDeletionAPI) GetAllDeleteRequests(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	userID, err := tenant.TenantID(ctx)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+
+	tombstones, err := mimir_tsdb.ReadTombstones(ctx, api.bucketClient, userID)
+	if err != nil {
+		level.Error(api.logger).Log("msg", "failed to read tombstones", "user", userID, "err", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if tombstones == nil {
+		tombstones = []*mimir_tsdb.Tombstone{}
+	}
+
+	util.WriteJSONResponse(w, tombstones)
+}
+
+// CancelDeleteRequest cancels a pending series deletion request. Requests which have already
+// been processed by the compactor can't be cancelled because the data has been deleted.
+func (api *SeriesDeletionAPI) CancelDeleteRequest(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	userID, err := tenant.TenantID(ctx)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+
+	requestID := r.FormValue("request_id")
+	if requestID == "" {
+		http.Error(w, "request_id not set", http.StatusBadRequest)
+		return
+	}
+
+	t, err := mimir_tsdb.ReadTombstone(ctx, api.bucketClient, userID, requestID)
+	if err != nil {
+		level.Error(api.logger).Log("msg", "failed to read tombstone", "user", userID, "request_id", requestID, "err", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if t == nil {
+		http.Error(w, "series deletion request not found", http.StatusNotFound)
+		return
+	}
+
+	switch t.State {
+	case mimir_tsdb.TombstoneCancelled:
+		w.WriteHeader(http.StatusNoContent)
+		return
+	case mimir_tsdb.TombstoneProcessed:
+		http.Error(w, "series deletion request has already been processed", http.StatusBadRequest)
+		return
+	}
+
+	t.UpdateState(mimir_tsdb.TombstoneCancelled, time.Now())
+	if err := mimir_tsdb.WriteTombstone(ctx, api.bucketClient, userID, api.cfgProvider, t); err != nil {
+		level.Error(api.logger).Log("msg", "failed to write tombstone", "user", userID, "request_id", requestID, "err", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	level.Info(api.logger).Log("msg", "series deletion request cancelled", "user", userID, "request_id", requestID)
+	w.WriteHeader(http.StatusNoContent)
+}
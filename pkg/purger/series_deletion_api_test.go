@@ -0,0 +1,93 @@
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package purger
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/go-kit/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/thanos-io/thanos/pkg/objstore"
+	"github.com/weaveworks/common/user"
+
+	"github.com/grafana/mimir/pkg/storage/tsdb"
+)
+
+func TestSeriesDeletionAPI(t *testing.T) {
+	const userID = "user"
+
+	bkt := objstore.NewInMemBucket()
+	api := newSeriesDeletionAPI(bkt, nil, log.NewNopLogger(), nil)
+	ctx := user.InjectOrgID(context.Background(), userID)
+
+	do := func(handler http.HandlerFunc, ctx context.Context, params url.Values) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(params.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		resp := httptest.NewRecorder()
+		handler(resp, req.WithContext(ctx))
+		return resp
+	}
+
+	list := func() []*tsdb.Tombstone {
+		resp := do(api.GetAllDeleteRequests, ctx, nil)
+		require.Equal(t, http.StatusOK, resp.Code)
+
+		var result []*tsdb.Tombstone
+		require.NoError(t, json.Unmarshal(resp.Body.Bytes(), &result))
+		return result
+	}
+
+	// No tenant.
+	require.Equal(t, http.StatusUnauthorized, do(api.AddDeleteRequest, context.Background(), url.Values{"match[]": {"up"}}).Code)
+
+	// Invalid requests.
+	require.Equal(t, http.StatusBadRequest, do(api.AddDeleteRequest, ctx, url.Values{}).Code)
+	require.Equal(t, http.StatusBadRequest, do(api.AddDeleteRequest, ctx, url.Values{"match[]": {"up{"}}).Code)
+	require.Equal(t, http.StatusBadRequest, do(api.AddDeleteRequest, ctx, url.Values{"match[]": {"up"}, "start": {"invalid"}}).Code)
+	require.Equal(t, http.StatusBadRequest, do(api.AddDeleteRequest, ctx, url.Values{"match[]": {"up"}, "start": {"20"}, "end": {"10"}}).Code)
+
+	assert.Empty(t, list())
+
+	// Valid request.
+	params := url.Values{"match[]": {`up{job="a"}`}, "start": {"10"}, "end": {"20"}}
+	require.Equal(t, http.StatusNoContent, do(api.AddDeleteRequest, ctx, params).Code)
+
+	requests := list()
+	require.Len(t, requests, 1)
+	assert.Equal(t, []string{`up{job="a"}`}, requests[0].Selectors)
+	assert.Equal(t, int64(10000), requests[0].StartTime)
+	assert.Equal(t, int64(20000), requests[0].EndTime)
+	assert.Equal(t, tsdb.TombstonePending, requests[0].State)
+	requestID := requests[0].RequestID
+
+	// The same request again doesn't create a new one.
+	require.Equal(t, http.StatusNoContent, do(api.AddDeleteRequest, ctx, params).Code)
+	require.Len(t, list(), 1)
+
+	// Cancel the request.
+	require.Equal(t, http.StatusBadRequest, do(api.CancelDeleteRequest, ctx, url.Values{}).Code)
+	require.Equal(t, http.StatusNotFound, do(api.CancelDeleteRequest, ctx, url.Values{"request_id": {"missing"}}).Code)
+	require.Equal(t, http.StatusNoContent, do(api.CancelDeleteRequest, ctx, url.Values{"request_id": {requestID}}).Code)
+
+	requests = list()
+	require.Len(t, requests, 1)
+	assert.Equal(t, tsdb.TombstoneCancelled, requests[0].State)
+
+	// A cancelled request can be submitted again.
+	require.Equal(t, http.StatusNoContent, do(api.AddDeleteRequest, ctx, params).Code)
+	requests = list()
+	require.Len(t, requests, 1)
+	assert.Equal(t, tsdb.TombstonePending, requests[0].State)
+
+	// A processed request can't be cancelled.
+	requests[0].UpdateState(tsdb.TombstoneProcessed, requests[0].GetRequestCreatedAt())
+	require.NoError(t, tsdb.WriteTombstone(ctx, bkt, userID, nil, requests[0]))
+	require.Equal(t, http.StatusBadRequest, do(api.CancelDeleteRequest, ctx, url.Values{"request_id": {requestID}}).Code)
+}
@@ -0,0 +1,152 @@
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package querier
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+
+	"github.com/grafana/dskit/tenant"
+	"github.com/prometheus/common/model"
+	"github.com/prometheus/prometheus/model/labels"
+
+	"github.com/grafana/mimir/pkg/ingester/activeseries"
+	"github.com/grafana/mimir/pkg/util"
+	"github.com/grafana/mimir/pkg/util/validation"
+)
+
+type activeSeriesResponse struct {
+	SeriesCountTotal uint64                      `json:"series_count_total"`
+	CustomTrackers   []activeSeriesCustomTracker `json:"custom_trackers"`
+}
+
+type activeSeriesListResponse struct {
+	activeSeriesResponse
+	Series []labels.Labels `json:"series"`
+}
+
+type activeSeriesGroupsResponse struct {
+	activeSeriesResponse
+	Groups []activeSeriesGroup `json:"groups"`
+}
+
+type activeSeriesCustomTracker struct {
+	Name        string `json:"name"`
+	SeriesCount uint64 `json:"series_count"`
+}
+
+type activeSeriesGroup struct {
+	LabelValue  string `json:"label_value"`
+	SeriesCount uint64 `json:"series_count"`
+}
+
+// ActiveSeriesHandler creates handler for the active series API, returning the tenant's active series matching
+// the selector across the ingesters, or their count grouped by the value of a label. The response also breaks
+// down the matching series by the tenant's active series custom trackers.
+func ActiveSeriesHandler(d Distributor, limits *validation.Overrides) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		tenantID, err := tenant.TenantID(ctx)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if !limits.CardinalityAnalysisEnabled(tenantID) {
+			http.Error(w, fmt.Sprintf("cardinality analysis is disabled for the tenant: %v", tenantID), http.StatusBadRequest)
+			return
+		}
+
+		matchers, groupBy, err := extractActiveSeriesRequestParams(r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		series, err := d.ActiveSeries(ctx, matchers)
+		if err != nil {
+			respondFromError(err, w)
+			return
+		}
+
+		customTrackers := activeseries.NewMatchers(limits.ActiveSeriesCustomTrackersConfig(tenantID))
+		util.WriteJSONResponse(w, toActiveSeriesResponse(series, groupBy, customTrackers))
+	})
+}
+
+// extractActiveSeriesRequestParams parses and validates the request params: the required `selector`
+// and the optional `group_by` label name.
+func extractActiveSeriesRequestParams(r *http.Request) (matchers []*labels.Matcher, groupBy string, err error) {
+	if err := r.ParseForm(); err != nil {
+		return nil, "", err
+	}
+
+	if matchers, err = extractSelector(r); err != nil {
+		return nil, "", err
+	}
+	if len(matchers) == 0 {
+		return nil, "", fmt.Errorf("'selector' param is required")
+	}
+
+	groupByParams := r.Form["group_by"]
+	if len(groupByParams) > 1 {
+		return nil, "", fmt.Errorf("multiple 'group_by' params are not allowed")
+	}
+	if len(groupByParams) == 1 {
+		groupBy = groupByParams[0]
+		if !model.LabelName(groupBy).IsValid() {
+			return nil, "", fmt.Errorf("invalid 'group_by' param '%v'", groupBy)
+		}
+	}
+
+	return matchers, groupBy, nil
+}
+
+// toActiveSeriesResponse builds the response from the series returned by the distributor. If groupBy is
+// empty, the series are listed, otherwise they're counted by the value of the groupBy label.
+func toActiveSeriesResponse(series []labels.Labels, groupBy string, customTrackers *activeseries.Matchers) interface{} {
+	resp := activeSeriesResponse{SeriesCountTotal: uint64(len(series))}
+
+	trackerNames := customTrackers.MatcherNames()
+	trackerCounts := make([]uint64, len(trackerNames))
+	groupCounts := map[string]uint64{}
+
+	for _, s := range series {
+		for i, matches := range customTrackers.Matches(s) {
+			if matches {
+				trackerCounts[i]++
+			}
+		}
+		if groupBy != "" {
+			groupCounts[s.Get(groupBy)]++
+		}
+	}
+
+	resp.CustomTrackers = make([]activeSeriesCustomTracker, 0, len(trackerNames))
+	for i, name := range trackerNames {
+		resp.CustomTrackers = append(resp.CustomTrackers, activeSeriesCustomTracker{Name: name, SeriesCount: trackerCounts[i]})
+	}
+
+	if groupBy == "" {
+		if series == nil {
+			series = []labels.Labels{}
+		}
+		sort.Slice(series, func(i, j int) bool {
+			return labels.Compare(series[i], series[j]) < 0
+		})
+		return activeSeriesListResponse{activeSeriesResponse: resp, Series: series}
+	}
+
+	groups := make([]activeSeriesGroup, 0, len(groupCounts))
+	for value, count := range groupCounts {
+		groups = append(groups, activeSeriesGroup{LabelValue: value, SeriesCount: count})
+	}
+	// Sort the groups in descending order by series count and ascending order by label value.
+	sort.Slice(groups, func(i, j int) bool {
+		if groups[i].SeriesCount != groups[j].SeriesCount {
+			return groups[i].SeriesCount > groups[j].SeriesCount
+		}
+		return groups[i].LabelValue < groups[j].LabelValue
+	})
+	return activeSeriesGroupsResponse{activeSeriesResponse: resp, Groups: groups}
+}
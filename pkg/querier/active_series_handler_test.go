@@ -0,0 +1,116 @@
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package querier
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/mimir/pkg/ingester/activeseries"
+	"github.com/grafana/mimir/pkg/util/validation"
+)
+
+func TestActiveSeriesHandler(t *testing.T) {
+	series := []labels.Labels{
+		labels.FromStrings(labels.MetricName, "test_2", "team", "b"),
+		labels.FromStrings(labels.MetricName, "test_1", "team", "a", "status", "500"),
+		labels.FromStrings(labels.MetricName, "test_1", "team", "a", "status", "200"),
+	}
+
+	customTrackers, err := activeseries.NewCustomTrackersConfig(map[string]string{
+		"team_a": `{team="a"}`,
+		"team_c": `{team="c"}`,
+	})
+	require.NoError(t, err)
+
+	tests := map[string]struct {
+		url                        string
+		cardinalityAnalysisEnabled bool
+		expectedMatchers           []*labels.Matcher
+		expectedStatusCode         int
+		expectedBody               string
+	}{
+		"should list the active series matching the selector": {
+			url:                        `/api/v1/cardinality/active_series?selector={__name__=~"test_.*"}`,
+			cardinalityAnalysisEnabled: true,
+			expectedMatchers:           []*labels.Matcher{labels.MustNewMatcher(labels.MatchRegexp, labels.MetricName, "test_.*")},
+			expectedStatusCode:         http.StatusOK,
+			expectedBody: `{
+				"series_count_total": 3,
+				"custom_trackers": [{"name": "team_a", "series_count": 2}, {"name": "team_c", "series_count": 0}],
+				"series": [
+					{"__name__": "test_1", "team": "a", "status": "200"},
+					{"__name__": "test_1", "team": "a", "status": "500"},
+					{"__name__": "test_2", "team": "b"}
+				]
+			}`,
+		},
+		"should count the active series grouped by label": {
+			url:                        `/api/v1/cardinality/active_series?selector={__name__=~"test_.*"}&group_by=status`,
+			cardinalityAnalysisEnabled: true,
+			expectedMatchers:           []*labels.Matcher{labels.MustNewMatcher(labels.MatchRegexp, labels.MetricName, "test_.*")},
+			expectedStatusCode:         http.StatusOK,
+			expectedBody: `{
+				"series_count_total": 3,
+				"custom_trackers": [{"name": "team_a", "series_count": 2}, {"name": "team_c", "series_count": 0}],
+				"groups": [
+					{"label_value": "", "series_count": 1},
+					{"label_value": "200", "series_count": 1},
+					{"label_value": "500", "series_count": 1}
+				]
+			}`,
+		},
+		"should return an error if the selector is missing": {
+			url:                        `/api/v1/cardinality/active_series`,
+			cardinalityAnalysisEnabled: true,
+			expectedStatusCode:         http.StatusBadRequest,
+		},
+		"should return an error if the selector is invalid": {
+			url:                        `/api/v1/cardinality/active_series?selector={__name__="test_1"`,
+			cardinalityAnalysisEnabled: true,
+			expectedStatusCode:         http.StatusBadRequest,
+		},
+		"should return an error if the group_by label name is invalid": {
+			url:                        `/api/v1/cardinality/active_series?selector={__name__="test_1"}&group_by=1nvalid`,
+			cardinalityAnalysisEnabled: true,
+			expectedStatusCode:         http.StatusBadRequest,
+		},
+		"should return an error if the cardinality analysis feature is disabled": {
+			url:                        `/api/v1/cardinality/active_series?selector={__name__="test_1"}`,
+			cardinalityAnalysisEnabled: false,
+			expectedStatusCode:         http.StatusBadRequest,
+		},
+	}
+
+	for testName, testData := range tests {
+		t.Run(testName, func(t *testing.T) {
+			distributor := &mockDistributor{}
+			distributor.On("ActiveSeries", mock.Anything, mock.Anything).Return(append([]labels.Labels{}, series...), nil)
+
+			limits := validation.Limits{
+				CardinalityAnalysisEnabled:       testData.cardinalityAnalysisEnabled,
+				ActiveSeriesCustomTrackersConfig: customTrackers,
+			}
+			overrides, err := validation.NewOverrides(limits, nil)
+			require.NoError(t, err)
+			handler := ActiveSeriesHandler(distributor, overrides)
+
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, createRequest(testData.url, "team-a"))
+
+			require.Equal(t, testData.expectedStatusCode, recorder.Result().StatusCode)
+			if testData.expectedStatusCode != http.StatusOK {
+				distributor.AssertNotCalled(t, "ActiveSeries", mock.Anything, mock.Anything)
+				return
+			}
+
+			distributor.AssertCalled(t, "ActiveSeries", mock.Anything, testData.expectedMatchers)
+			require.JSONEq(t, testData.expectedBody, recorder.Body.String())
+		})
+	}
+}
@@ -25,7 +25,9 @@ import (
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promauto"
 	"github.com/prometheus/common/model"
+	"github.com/prometheus/prometheus/model/exemplar"
 	"github.com/prometheus/prometheus/model/labels"
+	"github.com/prometheus/prometheus/scrape"
 	"github.com/prometheus/prometheus/storage"
 	"github.com/thanos-io/thanos/pkg/block"
 	"github.com/thanos-io/thanos/pkg/extprom"
@@ -45,6 +47,7 @@ import (
 	"github.com/grafana/mimir/pkg/storage/sharding"
 	mimir_tsdb "github.com/grafana/mimir/pkg/storage/tsdb"
 	"github.com/grafana/mimir/pkg/storage/tsdb/bucketindex"
+	"github.com/grafana/mimir/pkg/storage/tsdb/exemplars"
 	"github.com/grafana/mimir/pkg/storegateway"
 	"github.com/grafana/mimir/pkg/storegateway/storegatewaypb"
 	"github.com/grafana/mimir/pkg/util"
@@ -297,6 +300,20 @@ func (q *BlocksStoreQueryable) stopping(_ error) error {
 
 // Querier returns a new Querier on the storage.
 func (q *BlocksStoreQueryable) Querier(ctx context.Context, mint, maxt int64) (storage.Querier, error) {
+	return q.newQuerier(ctx, mint, maxt)
+}
+
+// ExemplarQuerier returns a new ExemplarQuerier on the exemplars uploaded alongside the blocks.
+func (q *BlocksStoreQueryable) ExemplarQuerier(ctx context.Context) (storage.ExemplarQuerier, error) {
+	querier, err := q.newQuerier(ctx, 0, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	return &blocksStoreExemplarQuerier{querier: querier}, nil
+}
+
+func (q *BlocksStoreQueryable) newQuerier(ctx context.Context, mint, maxt int64) (*blocksStoreQuerier, error) {
 	if s := q.State(); s != services.Running {
 		return nil, errors.Errorf("BlocksStoreQueryable is not running: %v", s)
 	}
@@ -321,6 +338,74 @@ func (q *BlocksStoreQueryable) Querier(ctx context.Context, mint, maxt int64) (s
 	}, nil
 }
 
+// MetricsMetadata returns the metric metadata of the tenant persisted in the storage. The metadata
+// is fetched from a store-gateway holding the most recent block of the tenant, which loads it at each sync.
+func (q *BlocksStoreQueryable) MetricsMetadata(ctx context.Context) ([]scrape.MetricMetadata, error) {
+	if s := q.State(); s != services.Running {
+		return nil, errors.Errorf("BlocksStoreQueryable is not running: %v", s)
+	}
+
+	userID, err := tenant.TenantID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	logger := util_log.WithContext(ctx, q.logger)
+
+	// The metadata is not stored in the blocks, but any store-gateway owning a block of the tenant
+	// can read it. Blocks are sorted by max time descending, so we pick the most recent one.
+	knownBlocks, _, err := q.finder.GetBlocks(ctx, userID, 0, util.TimeToMillis(time.Now()))
+	if err != nil {
+		return nil, err
+	}
+	if len(knownBlocks) == 0 {
+		return nil, nil
+	}
+
+	var (
+		blockIDs = []ulid.ULID{knownBlocks[0].ID}
+		exclude  = map[ulid.ULID][]string{}
+		reqCtx   = grpc_metadata.AppendToOutgoingContext(ctx, storegateway.GrpcContextMetadataTenantID, userID)
+		lastErr  error
+	)
+
+	for attempt := 1; attempt <= maxFetchSeriesAttempts; attempt++ {
+		clients, err := q.stores.GetClientsFor(userID, blockIDs, exclude)
+		if err != nil {
+			// If it's a retry and we get an error, it means there are no more store-gateways left.
+			if attempt > 1 {
+				break
+			}
+			return nil, err
+		}
+
+		// A single store-gateway replica is picked for the block, so a single store-gateway is queried
+		// at each attempt, and another replica is queried only if it fails.
+		for c := range clients {
+			resp, err := c.MetricsMetadata(reqCtx, &storegatewaypb.MetricsMetadataRequest{})
+			if err != nil {
+				level.Warn(logger).Log("msg", "failed to fetch metric metadata from store-gateway", "instance", c.RemoteAddress(), "attempt", attempt, "err", err)
+				exclude[blockIDs[0]] = append(exclude[blockIDs[0]], c.RemoteAddress())
+				lastErr = err
+				continue
+			}
+
+			result := make([]scrape.MetricMetadata, 0, len(resp.Metadata))
+			for _, m := range resp.Metadata {
+				result = append(result, scrape.MetricMetadata{
+					Metric: m.MetricFamilyName,
+					Help:   m.Help,
+					Unit:   m.Unit,
+					Type:   mimirpb.MetricMetadataMetricTypeToMetricType(m.GetType()),
+				})
+			}
+			return result, nil
+		}
+	}
+
+	return nil, errors.Wrap(lastErr, "failed to fetch metric metadata from store-gateways")
+}
+
 type blocksStoreQuerier struct {
 	ctx         context.Context
 	minT, maxT  int64
@@ -430,6 +515,41 @@ func (q *blocksStoreQuerier) Close() error {
 	return nil
 }
 
+func (q *blocksStoreQuerier) selectExemplars(start, end int64, matchers ...[]*labels.Matcher) ([]exemplar.QueryResult, error) {
+	spanLog, spanCtx := spanlogger.NewWithLogger(q.ctx, q.logger, "blocksStoreQuerier.selectExemplars")
+	defer spanLog.Span.Finish()
+
+	level.Debug(spanLog).Log("start", util.TimeFromMillis(start).UTC().String(), "end", util.TimeFromMillis(end).UTC().String())
+
+	var (
+		resResults        []exemplar.QueryResult
+		convertedMatchers = make([]storegatewaypb.ExemplarsMatchers, 0, len(matchers))
+	)
+
+	for _, set := range matchers {
+		convertedMatchers = append(convertedMatchers, storegatewaypb.ExemplarsMatchers{Matchers: convertMatchersToLabelMatcher(set)})
+	}
+
+	queryFunc := func(clients map[BlocksStoreClient][]ulid.ULID, minT, maxT int64) ([]ulid.ULID, error) {
+		results, queriedBlocks, err := q.fetchExemplarsFromStores(spanCtx, clients, minT, maxT, convertedMatchers)
+		if err != nil {
+			return nil, err
+		}
+
+		resResults = append(resResults, results...)
+		return queriedBlocks, nil
+	}
+
+	err := q.queryWithConsistencyCheck(spanCtx, spanLog, start, end, nil, queryFunc)
+	if err != nil {
+		return nil, err
+	}
+
+	// The same series may be returned by multiple store-gateways, and the same exemplar may
+	// have been uploaded alongside multiple blocks, so we need to merge them.
+	return exemplars.NewFile(resResults).Select(start, end, matchers...), nil
+}
+
 func (q *blocksStoreQuerier) selectSorted(sp *storage.SelectHints, matchers ...*labels.Matcher) storage.SeriesSet {
 	spanLog, spanCtx := spanlogger.NewWithLogger(q.ctx, q.logger, "blocksStoreQuerier.selectSorted")
 	defer spanLog.Span.Finish()
@@ -899,6 +1019,80 @@ func (q *blocksStoreQuerier) fetchLabelNamesFromStore(
 	return nameSets, warnings, queriedBlocks, nil
 }
 
+func (q *blocksStoreQuerier) fetchExemplarsFromStores(
+	ctx context.Context,
+	clients map[BlocksStoreClient][]ulid.ULID,
+	minT int64,
+	maxT int64,
+	matchers []storegatewaypb.ExemplarsMatchers,
+) ([]exemplar.QueryResult, []ulid.ULID, error) {
+	var (
+		reqCtx        = grpc_metadata.AppendToOutgoingContext(ctx, storegateway.GrpcContextMetadataTenantID, q.userID)
+		g, gCtx       = errgroup.WithContext(reqCtx)
+		mtx           = sync.Mutex{}
+		results       = []exemplar.QueryResult(nil)
+		queriedBlocks = []ulid.ULID(nil)
+		spanLog       = spanlogger.FromContext(ctx, q.logger)
+	)
+
+	// Concurrently fetch exemplars from all clients.
+	for c, blockIDs := range clients {
+		// Change variables scope since it will be used in a goroutine.
+		c := c
+		blockIDs := blockIDs
+
+		g.Go(func() error {
+			req := &storegatewaypb.ExemplarsRequest{
+				StartTimestampMs: minT,
+				EndTimestampMs:   maxT,
+				Matchers:         matchers,
+				BlockIds:         convertULIDsToString(blockIDs),
+			}
+
+			resp, err := c.Exemplars(gCtx, req)
+			if err != nil {
+				level.Warn(spanLog).Log("msg", "failed to fetch exemplars", "remote", c.RemoteAddress(), "err", err)
+				return nil
+			}
+
+			myQueriedBlocks := make([]ulid.ULID, 0, len(resp.QueriedBlocks))
+			for _, id := range resp.QueriedBlocks {
+				blockID, err := ulid.Parse(id)
+				if err != nil {
+					return errors.Wrapf(err, "failed to parse queried block IDs from received exemplars")
+				}
+				myQueriedBlocks = append(myQueriedBlocks, blockID)
+			}
+
+			level.Debug(spanLog).Log("msg", "received exemplars from store-gateway",
+				"instance", c,
+				"num series", len(resp.Timeseries),
+				"requested blocks", strings.Join(convertULIDsToString(blockIDs), " "),
+				"queried blocks", strings.Join(convertULIDsToString(myQueriedBlocks), " "))
+
+			// Store the result.
+			mtx.Lock()
+			for _, ts := range resp.Timeseries {
+				results = append(results, exemplar.QueryResult{
+					SeriesLabels: mimirpb.FromLabelAdaptersToLabels(ts.Labels),
+					Exemplars:    mimirpb.FromExemplarProtosToExemplars(ts.Exemplars),
+				})
+			}
+			queriedBlocks = append(queriedBlocks, myQueriedBlocks...)
+			mtx.Unlock()
+
+			return nil
+		})
+	}
+
+	// Wait until all client requests complete.
+	if err := g.Wait(); err != nil {
+		return nil, nil, err
+	}
+
+	return results, queriedBlocks, nil
+}
+
 func (q *blocksStoreQuerier) fetchLabelValuesFromStore(
 	ctx context.Context,
 	name string,
@@ -980,6 +1174,16 @@ func (q *blocksStoreQuerier) fetchLabelValuesFromStore(
 	return valueSets, warnings, queriedBlocks, nil
 }
 
+// blocksStoreExemplarQuerier is a storage.ExemplarQuerier on the exemplars uploaded alongside the blocks.
+type blocksStoreExemplarQuerier struct {
+	querier *blocksStoreQuerier
+}
+
+// Select implements storage.ExemplarQuerier.
+func (q *blocksStoreExemplarQuerier) Select(start, end int64, matchers ...[]*labels.Matcher) ([]exemplar.QueryResult, error) {
+	return q.querier.selectExemplars(start, end, matchers...)
+}
+
 func createSeriesRequest(minT, maxT int64, matchers []storepb.LabelMatcher, skipChunks bool, blockIDs []ulid.ULID) (*storepb.SeriesRequest, error) {
 	// Selectively query only specific blocks.
 	hints := &hintspb.SeriesRequestHints{
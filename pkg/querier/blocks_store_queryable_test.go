@@ -23,8 +23,10 @@ import (
 	"github.com/pkg/errors"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/prometheus/prometheus/model/exemplar"
 	"github.com/prometheus/prometheus/model/labels"
 	"github.com/prometheus/prometheus/promql"
+	"github.com/prometheus/prometheus/scrape"
 	"github.com/prometheus/prometheus/storage"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
@@ -35,6 +37,7 @@ import (
 	"github.com/weaveworks/common/user"
 	"google.golang.org/grpc"
 
+	"github.com/grafana/mimir/pkg/mimirpb"
 	"github.com/grafana/mimir/pkg/storage/sharding"
 	"github.com/grafana/mimir/pkg/storage/tsdb/bucketindex"
 	"github.com/grafana/mimir/pkg/storegateway/storegatewaypb"
@@ -1695,6 +1698,191 @@ func TestBlocksStoreQuerier_PromQLExecution(t *testing.T) {
 	}
 }
 
+func TestBlocksStoreQueryable_MetricsMetadata(t *testing.T) {
+	block1 := ulid.MustNew(1, nil)
+	block2 := ulid.MustNew(2, nil)
+
+	metadataResponse := &storegatewaypb.MetricsMetadataResponse{Metadata: []*mimirpb.MetricMetadata{
+		{MetricFamilyName: "test_metric", Type: mimirpb.COUNTER, Help: "test help"},
+	}}
+	failingGateway := &storeGatewayClientMock{remoteAddr: "1.1.1.1", mockedMetadataErr: errors.New("failed")}
+	workingGateway := &storeGatewayClientMock{remoteAddr: "2.2.2.2", mockedMetadataResponse: metadataResponse}
+
+	tests := map[string]struct {
+		blocks         bucketindex.Blocks
+		storeSetResult []interface{}
+		expected       []scrape.MetricMetadata
+		expectedErr    string
+	}{
+		"should return no metadata if the tenant has no blocks": {
+			blocks: bucketindex.Blocks{},
+		},
+		"should return the metadata from the store-gateway": {
+			blocks: bucketindex.Blocks{{ID: block2}, {ID: block1}},
+			storeSetResult: []interface{}{
+				map[BlocksStoreClient][]ulid.ULID{workingGateway: {block2}},
+			},
+			expected: []scrape.MetricMetadata{{Metric: "test_metric", Type: "counter", Help: "test help"}},
+		},
+		"should retry on another store-gateway on failure": {
+			blocks: bucketindex.Blocks{{ID: block2}, {ID: block1}},
+			storeSetResult: []interface{}{
+				map[BlocksStoreClient][]ulid.ULID{failingGateway: {block2}},
+				map[BlocksStoreClient][]ulid.ULID{workingGateway: {block2}},
+			},
+			expected: []scrape.MetricMetadata{{Metric: "test_metric", Type: "counter", Help: "test help"}},
+		},
+		"should fail if no store-gateway is left to retry": {
+			blocks: bucketindex.Blocks{{ID: block2}, {ID: block1}},
+			storeSetResult: []interface{}{
+				map[BlocksStoreClient][]ulid.ULID{failingGateway: {block2}},
+				errors.New("no store-gateway left"),
+			},
+			expectedErr: "failed to fetch metric metadata from store-gateways: failed",
+		},
+	}
+
+	for testName, testData := range tests {
+		t.Run(testName, func(t *testing.T) {
+			finder := &blocksFinderMock{
+				Service: services.NewIdleService(nil, nil),
+			}
+			finder.On("GetBlocks", mock.Anything, "user-1", mock.Anything, mock.Anything).Return(testData.blocks, map[ulid.ULID]*bucketindex.BlockDeletionMark(nil), error(nil))
+
+			stores := &blocksStoreSetMock{
+				Service:         services.NewIdleService(nil, nil),
+				mockedResponses: testData.storeSetResult,
+			}
+
+			logger := log.NewNopLogger()
+			queryable, err := NewBlocksStoreQueryable(stores, finder, NewBlocksConsistencyChecker(0, 0, logger, nil), &blocksStoreLimitsMock{}, 0, logger, nil)
+			require.NoError(t, err)
+			require.NoError(t, services.StartAndAwaitRunning(context.Background(), queryable))
+			defer services.StopAndAwaitTerminated(context.Background(), queryable) // nolint:errcheck
+
+			actual, err := queryable.MetricsMetadata(user.InjectOrgID(context.Background(), "user-1"))
+			if testData.expectedErr != "" {
+				require.EqualError(t, err, testData.expectedErr)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, testData.expected, actual)
+		})
+	}
+}
+
+func TestBlocksStoreQueryable_ExemplarQuerier(t *testing.T) {
+	const (
+		minT = int64(10)
+		maxT = int64(20)
+	)
+
+	block1 := ulid.MustNew(1, nil)
+	block2 := ulid.MustNew(2, nil)
+
+	series1 := labels.FromStrings(labels.MetricName, "series_1")
+	series2 := labels.FromStrings(labels.MetricName, "series_2")
+	exemplar1 := exemplar.Exemplar{Labels: labels.FromStrings("trace_id", "1"), Value: 1, Ts: 11}
+	exemplar2 := exemplar.Exemplar{Labels: labels.FromStrings("trace_id", "2"), Value: 2, Ts: 12}
+
+	mockTimeSeries := func(lbls labels.Labels, exemplars ...exemplar.Exemplar) mimirpb.TimeSeries {
+		return mimirpb.TimeSeries{
+			Labels:    mimirpb.FromLabelsToLabelAdapters(lbls),
+			Exemplars: mimirpb.FromExemplarsToExemplarProtos(exemplars),
+		}
+	}
+
+	tests := map[string]struct {
+		blocks         bucketindex.Blocks
+		storeSetResult []interface{}
+		expected       []exemplar.QueryResult
+		expectedErr    string
+	}{
+		"should return no exemplars if the tenant has no blocks": {
+			blocks: bucketindex.Blocks{},
+		},
+		"should merge the exemplars returned by multiple store-gateways": {
+			blocks: bucketindex.Blocks{{ID: block2, MinTime: minT, MaxTime: maxT}, {ID: block1, MinTime: minT, MaxTime: maxT}},
+			storeSetResult: []interface{}{
+				map[BlocksStoreClient][]ulid.ULID{
+					&storeGatewayClientMock{remoteAddr: "1.1.1.1", mockedExemplarsResponse: &storegatewaypb.ExemplarsResponse{
+						Timeseries:    []mimirpb.TimeSeries{mockTimeSeries(series1, exemplar1), mockTimeSeries(series2, exemplar2)},
+						QueriedBlocks: []string{block1.String()},
+					}}: {block1},
+					&storeGatewayClientMock{remoteAddr: "2.2.2.2", mockedExemplarsResponse: &storegatewaypb.ExemplarsResponse{
+						Timeseries:    []mimirpb.TimeSeries{mockTimeSeries(series1, exemplar1, exemplar2)},
+						QueriedBlocks: []string{block2.String()},
+					}}: {block2},
+				},
+			},
+			expected: []exemplar.QueryResult{
+				{SeriesLabels: series1, Exemplars: []exemplar.Exemplar{exemplar1, exemplar2}},
+				{SeriesLabels: series2, Exemplars: []exemplar.Exemplar{exemplar2}},
+			},
+		},
+		"should retry the missing blocks on another store-gateway": {
+			blocks: bucketindex.Blocks{{ID: block1, MinTime: minT, MaxTime: maxT}},
+			storeSetResult: []interface{}{
+				map[BlocksStoreClient][]ulid.ULID{
+					&storeGatewayClientMock{remoteAddr: "1.1.1.1", mockedExemplarsErr: errors.New("failed")}: {block1},
+				},
+				map[BlocksStoreClient][]ulid.ULID{
+					&storeGatewayClientMock{remoteAddr: "2.2.2.2", mockedExemplarsResponse: &storegatewaypb.ExemplarsResponse{
+						Timeseries:    []mimirpb.TimeSeries{mockTimeSeries(series1, exemplar1)},
+						QueriedBlocks: []string{block1.String()},
+					}}: {block1},
+				},
+			},
+			expected: []exemplar.QueryResult{
+				{SeriesLabels: series1, Exemplars: []exemplar.Exemplar{exemplar1}},
+			},
+		},
+		"should fail if the consistency check fails": {
+			blocks: bucketindex.Blocks{{ID: block1, MinTime: minT, MaxTime: maxT}},
+			storeSetResult: []interface{}{
+				map[BlocksStoreClient][]ulid.ULID{
+					&storeGatewayClientMock{remoteAddr: "1.1.1.1", mockedExemplarsErr: errors.New("failed")}: {block1},
+				},
+				errors.New("no store-gateway left"),
+			},
+			expectedErr: newStoreConsistencyCheckFailedError([]ulid.ULID{block1}).Error(),
+		},
+	}
+
+	for testName, testData := range tests {
+		t.Run(testName, func(t *testing.T) {
+			finder := &blocksFinderMock{
+				Service: services.NewIdleService(nil, nil),
+			}
+			finder.On("GetBlocks", mock.Anything, "user-1", minT, maxT).Return(testData.blocks, map[ulid.ULID]*bucketindex.BlockDeletionMark(nil), error(nil))
+
+			stores := &blocksStoreSetMock{
+				Service:         services.NewIdleService(nil, nil),
+				mockedResponses: testData.storeSetResult,
+			}
+
+			logger := log.NewNopLogger()
+			queryable, err := NewBlocksStoreQueryable(stores, finder, NewBlocksConsistencyChecker(0, 0, logger, nil), &blocksStoreLimitsMock{}, 0, logger, nil)
+			require.NoError(t, err)
+			require.NoError(t, services.StartAndAwaitRunning(context.Background(), queryable))
+			defer services.StopAndAwaitTerminated(context.Background(), queryable) // nolint:errcheck
+
+			querier, err := queryable.ExemplarQuerier(user.InjectOrgID(context.Background(), "user-1"))
+			require.NoError(t, err)
+
+			actual, err := querier.Select(minT, maxT, []*labels.Matcher{labels.MustNewMatcher(labels.MatchRegexp, labels.MetricName, "series_.*")})
+			if testData.expectedErr != "" {
+				require.EqualError(t, err, testData.expectedErr)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.ElementsMatch(t, testData.expected, actual)
+		})
+	}
+}
+
 func TestCanBlockWithCompactorShardIdContainQueryShard(t *testing.T) {
 	const numSeries = 1000
 	const maxShards = 512
@@ -1838,6 +2026,10 @@ type storeGatewayClientMock struct {
 	mockedLabelNamesErr       error
 	mockedLabelValuesResponse *storepb.LabelValuesResponse
 	mockedLabelValuesErr      error
+	mockedMetadataResponse    *storegatewaypb.MetricsMetadataResponse
+	mockedMetadataErr         error
+	mockedExemplarsResponse   *storegatewaypb.ExemplarsResponse
+	mockedExemplarsErr        error
 }
 
 func (m *storeGatewayClientMock) Series(ctx context.Context, in *storepb.SeriesRequest, opts ...grpc.CallOption) (storegatewaypb.StoreGateway_SeriesClient, error) {
@@ -1856,6 +2048,14 @@ func (m *storeGatewayClientMock) LabelValues(context.Context, *storepb.LabelValu
 	return m.mockedLabelValuesResponse, m.mockedLabelValuesErr
 }
 
+func (m *storeGatewayClientMock) MetricsMetadata(context.Context, *storegatewaypb.MetricsMetadataRequest, ...grpc.CallOption) (*storegatewaypb.MetricsMetadataResponse, error) {
+	return m.mockedMetadataResponse, m.mockedMetadataErr
+}
+
+func (m *storeGatewayClientMock) Exemplars(context.Context, *storegatewaypb.ExemplarsRequest, ...grpc.CallOption) (*storegatewaypb.ExemplarsResponse, error) {
+	return m.mockedExemplarsResponse, m.mockedExemplarsErr
+}
+
 func (m *storeGatewayClientMock) RemoteAddress() string {
 	return m.remoteAddr
 }
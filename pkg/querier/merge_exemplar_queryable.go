@@ -0,0 +1,56 @@
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package querier
+
+import (
+	"context"
+
+	"github.com/prometheus/prometheus/model/exemplar"
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/prometheus/prometheus/storage"
+
+	"github.com/grafana/mimir/pkg/storage/tsdb/exemplars"
+)
+
+type mergeExemplarQueryable struct {
+	queryables []storage.ExemplarQueryable
+}
+
+// NewMergeExemplarQueryable returns an ExemplarQueryable returning the union of the exemplars
+// returned by each of the input queryables.
+func NewMergeExemplarQueryable(queryables ...storage.ExemplarQueryable) storage.ExemplarQueryable {
+	return &mergeExemplarQueryable{queryables: queryables}
+}
+
+func (m *mergeExemplarQueryable) ExemplarQuerier(ctx context.Context) (storage.ExemplarQuerier, error) {
+	queriers := make([]storage.ExemplarQuerier, 0, len(m.queryables))
+	for _, q := range m.queryables {
+		querier, err := q.ExemplarQuerier(ctx)
+		if err != nil {
+			return nil, err
+		}
+		queriers = append(queriers, querier)
+	}
+
+	return &mergeExemplarQuerier{queriers: queriers}, nil
+}
+
+type mergeExemplarQuerier struct {
+	queriers []storage.ExemplarQuerier
+}
+
+// Select implements storage.ExemplarQuerier. The same exemplar returned by multiple queriers
+// is included only once.
+func (m *mergeExemplarQuerier) Select(start, end int64, matchers ...[]*labels.Matcher) ([]exemplar.QueryResult, error) {
+	var results []exemplar.QueryResult
+
+	for _, q := range m.queriers {
+		res, err := q.Select(start, end, matchers...)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, res...)
+	}
+
+	return exemplars.NewFile(results).Select(start, end, matchers...), nil
+}
@@ -0,0 +1,73 @@
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package querier
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/prometheus/prometheus/model/exemplar"
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/prometheus/prometheus/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMergeExemplarQueryable(t *testing.T) {
+	series1 := labels.FromStrings(labels.MetricName, "series_1")
+	series2 := labels.FromStrings(labels.MetricName, "series_2")
+	exemplar1 := exemplar.Exemplar{Labels: labels.FromStrings("trace_id", "1"), Value: 1, Ts: 10}
+	exemplar2 := exemplar.Exemplar{Labels: labels.FromStrings("trace_id", "2"), Value: 2, Ts: 20}
+	matchers := []*labels.Matcher{labels.MustNewMatcher(labels.MatchRegexp, labels.MetricName, "series_.*")}
+
+	t.Run("should return the union of the exemplars of all queryables", func(t *testing.T) {
+		queryable := NewMergeExemplarQueryable(
+			staticExemplarQueryable{results: []exemplar.QueryResult{
+				{SeriesLabels: series1, Exemplars: []exemplar.Exemplar{exemplar2}},
+			}},
+			staticExemplarQueryable{results: []exemplar.QueryResult{
+				{SeriesLabels: series1, Exemplars: []exemplar.Exemplar{exemplar1, exemplar2}},
+				{SeriesLabels: series2, Exemplars: []exemplar.Exemplar{exemplar1}},
+			}},
+		)
+
+		querier, err := queryable.ExemplarQuerier(context.Background())
+		require.NoError(t, err)
+
+		actual, err := querier.Select(0, 100, matchers)
+		require.NoError(t, err)
+		assert.Equal(t, []exemplar.QueryResult{
+			{SeriesLabels: series1, Exemplars: []exemplar.Exemplar{exemplar1, exemplar2}},
+			{SeriesLabels: series2, Exemplars: []exemplar.Exemplar{exemplar1}},
+		}, actual)
+	})
+
+	t.Run("should fail if any queryable fails", func(t *testing.T) {
+		queryable := NewMergeExemplarQueryable(
+			staticExemplarQueryable{results: []exemplar.QueryResult{
+				{SeriesLabels: series1, Exemplars: []exemplar.Exemplar{exemplar1}},
+			}},
+			staticExemplarQueryable{err: errors.New("failed")},
+		)
+
+		querier, err := queryable.ExemplarQuerier(context.Background())
+		require.NoError(t, err)
+
+		_, err = querier.Select(0, 100, matchers)
+		require.EqualError(t, err, "failed")
+	})
+}
+
+type staticExemplarQueryable struct {
+	results []exemplar.QueryResult
+	err     error
+}
+
+func (q staticExemplarQueryable) ExemplarQuerier(context.Context) (storage.ExemplarQuerier, error) {
+	return q, nil
+}
+
+func (q staticExemplarQueryable) Select(int64, int64, ...[]*labels.Matcher) ([]exemplar.QueryResult, error) {
+	return q.results, q.err
+}
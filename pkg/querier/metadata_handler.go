@@ -6,11 +6,51 @@
 package querier
 
 import (
+	"context"
 	"net/http"
 
+	"github.com/prometheus/prometheus/scrape"
+
 	"github.com/grafana/mimir/pkg/util"
 )
 
+// MetadataSupplier is the interface used to get the metric metadata of a tenant.
+type MetadataSupplier interface {
+	MetricsMetadata(ctx context.Context) ([]scrape.MetricMetadata, error)
+}
+
+type mergeMetadataSupplier struct {
+	suppliers []MetadataSupplier
+}
+
+// NewMergeMetadataSupplier returns a MetadataSupplier returning the union of the metric
+// metadata returned by each of the input suppliers.
+func NewMergeMetadataSupplier(suppliers ...MetadataSupplier) MetadataSupplier {
+	return &mergeMetadataSupplier{suppliers: suppliers}
+}
+
+func (m *mergeMetadataSupplier) MetricsMetadata(ctx context.Context) ([]scrape.MetricMetadata, error) {
+	result := []scrape.MetricMetadata{}
+	dedupTracker := map[scrape.MetricMetadata]struct{}{}
+
+	for _, s := range m.suppliers {
+		metadata, err := s.MetricsMetadata(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, md := range metadata {
+			if _, ok := dedupTracker[md]; ok {
+				continue
+			}
+			dedupTracker[md] = struct{}{}
+			result = append(result, md)
+		}
+	}
+
+	return result, nil
+}
+
 type metricMetadata struct {
 	Type string `json:"type"`
 	Help string `json:"help"`
@@ -30,7 +70,7 @@ type metadataResult struct {
 
 // MetadataHandler returns metric metadata held by Mimir for a given tenant.
 // It is kept and returned as a set.
-func MetadataHandler(d Distributor) http.Handler {
+func MetadataHandler(d MetadataSupplier) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		resp, err := d.MetricsMetadata(r.Context())
 		if err != nil {
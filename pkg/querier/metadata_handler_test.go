@@ -80,3 +80,56 @@ func TestMetadataHandler_Error(t *testing.T) {
 
 	require.JSONEq(t, expectedJSON, string(responseBody))
 }
+
+func TestMetadataHandler_MergeMetadataSupplier(t *testing.T) {
+	d := &mockDistributor{}
+	d.On("MetricsMetadata", mock.Anything).Return(
+		[]scrape.MetricMetadata{
+			{Metric: "alertmanager_dispatcher_aggregation_groups", Help: "Number of active aggregation groups", Type: "gauge", Unit: ""},
+		},
+		nil)
+
+	s := &mockDistributor{}
+	s.On("MetricsMetadata", mock.Anything).Return(
+		[]scrape.MetricMetadata{
+			{Metric: "alertmanager_dispatcher_aggregation_groups", Help: "Number of active aggregation groups", Type: "gauge", Unit: ""},
+			{Metric: "alertmanager_alerts", Help: "How many alerts by state.", Type: "gauge", Unit: ""},
+		},
+		nil)
+
+	handler := MetadataHandler(NewMergeMetadataSupplier(d, s))
+
+	request, err := http.NewRequest("GET", "/metadata", nil)
+	require.NoError(t, err)
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, request)
+
+	require.Equal(t, http.StatusOK, recorder.Result().StatusCode)
+	responseBody, err := ioutil.ReadAll(recorder.Result().Body)
+	require.NoError(t, err)
+
+	expectedJSON := `
+	{
+		"status": "success",
+		"data": {
+			"alertmanager_dispatcher_aggregation_groups": [
+				{
+					"help": "Number of active aggregation groups",
+					"type": "gauge",
+					"unit": ""
+				}
+			],
+			"alertmanager_alerts": [
+				{
+					"help": "How many alerts by state.",
+					"type": "gauge",
+					"unit": ""
+				}
+			]
+		}
+	}
+	`
+
+	require.JSONEq(t, expectedJSON, string(responseBody))
+}
@@ -52,6 +52,13 @@ type Config struct {
 
 	ShuffleShardingIngestersEnabled bool `yaml:"shuffle_sharding_ingesters_enabled" category:"advanced"`
 
+	SeriesDeletionEnabled  bool          `yaml:"series_deletion_enabled" category:"experimental"`
+	SeriesDeletionCacheTTL time.Duration `yaml:"series_deletion_cache_ttl" category:"experimental"`
+
+	QueryStoreForMetadata bool `yaml:"query_store_for_metadata" category:"experimental"`
+
+	QueryStoreForExemplars bool `yaml:"query_store_for_exemplars" category:"experimental"`
+
 	// PromQL engine config.
 	EngineConfig engine.Config `yaml:",inline"`
 }
@@ -79,6 +86,11 @@ func (cfg *Config) RegisterFlags(f *flag.FlagSet, logger log.Logger) {
 	flagext.DeprecatedFlag(f, shuffleShardingIngestersLookbackPeriodFlag, fmt.Sprintf("Deprecated: this setting should always be the same as -%s and will now behave as if it is", queryIngestersWithinFlag), logger)
 	f.BoolVar(&cfg.ShuffleShardingIngestersEnabled, "querier.shuffle-sharding-ingesters-enabled", true, fmt.Sprintf("Fetch in-memory series from the minimum set of required ingesters, selecting only ingesters which may have received series since -%s. If this setting is false or -%s is '0', queriers always query all ingesters (ingesters shuffle sharding on read path is disabled).", queryIngestersWithinFlag, queryIngestersWithinFlag))
 
+	f.BoolVar(&cfg.SeriesDeletionEnabled, "querier.series-deletion-enabled", false, "True to remove from query results the data matching the tenant's series deletion requests.")
+	f.DurationVar(&cfg.SeriesDeletionCacheTTL, "querier.series-deletion-cache-ttl", time.Minute, "How long to cache the tenant's series deletion requests before reloading them from the storage.")
+	f.BoolVar(&cfg.QueryStoreForMetadata, "querier.query-store-for-metadata", false, "True to also fetch the metric metadata persisted in the storage from the store-gateways, in addition to the metadata held by ingesters. Requires -ingester.metadata-persistence-enabled.")
+	f.BoolVar(&cfg.QueryStoreForExemplars, "querier.query-store-for-exemplars", false, "True to also fetch the exemplars uploaded alongside the blocks from the store-gateways, in addition to the exemplars held by ingesters. Requires -ingester.exemplars-shipping-enabled.")
+
 	cfg.EngineConfig.RegisterFlags(f)
 }
 
@@ -103,8 +115,9 @@ func getChunksIteratorFunction(cfg Config) chunkIteratorFunc {
 	return mergeChunks
 }
 
-// New builds a queryable and promql engine.
-func New(cfg Config, limits *validation.Overrides, distributor Distributor, stores []QueryableWithFilter, reg prometheus.Registerer, logger log.Logger, tracker *activitytracker.ActivityTracker) (storage.SampleAndChunkQueryable, storage.ExemplarQueryable, *promql.Engine) {
+// New builds a queryable and promql engine. If tombstones is not nil, data matching
+// the tenant's series deletion requests is removed from the results.
+func New(cfg Config, limits *validation.Overrides, distributor Distributor, stores []QueryableWithFilter, tombstones TombstonesProvider, reg prometheus.Registerer, logger log.Logger, tracker *activitytracker.ActivityTracker) (storage.SampleAndChunkQueryable, storage.ExemplarQueryable, *promql.Engine) {
 	iteratorFunc := getChunksIteratorFunction(cfg)
 
 	distributorQueryable := newDistributorQueryable(distributor, iteratorFunc, cfg.QueryIngestersWithin, logger)
@@ -117,6 +130,9 @@ func New(cfg Config, limits *validation.Overrides, distributor Distributor, stor
 		}
 	}
 	queryable := NewQueryable(distributorQueryable, ns, iteratorFunc, cfg, limits, logger)
+	if tombstones != nil {
+		queryable = newTombstonesQueryable(queryable, tombstones, logger)
+	}
 	exemplarQueryable := newDistributorExemplarQueryable(distributor, logger)
 
 	lazyQueryable := storage.QueryableFunc(func(ctx context.Context, mint int64, maxt int64) (storage.Querier, error) {
@@ -123,6 +123,56 @@ func (s *Stats) LoadShardedQueries() uint32 {
 	return atomic.LoadUint32(&s.ShardedQueries)
 }
 
+// AddQueueTime adds some time to the queue time counter.
+func (s *Stats) AddQueueTime(t time.Duration) {
+	if s == nil {
+		return
+	}
+
+	atomic.AddInt64((*int64)(&s.QueueTime), int64(t))
+}
+
+// LoadQueueTime returns current queue time.
+func (s *Stats) LoadQueueTime() time.Duration {
+	if s == nil {
+		return 0
+	}
+
+	return time.Duration(atomic.LoadInt64((*int64)(&s.QueueTime)))
+}
+
+func (s *Stats) AddResultsCacheRequests(num uint32) {
+	if s == nil {
+		return
+	}
+
+	atomic.AddUint32(&s.ResultsCacheRequests, num)
+}
+
+func (s *Stats) LoadResultsCacheRequests() uint32 {
+	if s == nil {
+		return 0
+	}
+
+	return atomic.LoadUint32(&s.ResultsCacheRequests)
+}
+
+func (s *Stats) AddResultsCacheHits(num uint32) {
+	if s == nil {
+		return
+	}
+
+	atomic.AddUint32(&s.ResultsCacheHits, num)
+}
+
+func (s *Stats) LoadResultsCacheHits() uint32 {
+	if s == nil {
+		return 0
+	}
+
+	return atomic.LoadUint32(&s.ResultsCacheHits)
+}
+
 // Merge the provided Stats into this one.
 func (s *Stats) Merge(other *Stats) {
 	if s == nil || other == nil {
@@ -134,6 +184,9 @@ func (s *Stats) Merge(other *Stats) {
 	s.AddFetchedChunkBytes(other.LoadFetchedChunkBytes())
 	s.AddFetchedChunks(other.LoadFetchedChunks())
 	s.AddShardedQueries(other.LoadShardedQueries())
+	s.AddQueueTime(other.LoadQueueTime())
+	s.AddResultsCacheRequests(other.LoadResultsCacheRequests())
+	s.AddResultsCacheHits(other.LoadResultsCacheHits())
 }
 
 func ShouldTrackHTTPGRPCResponse(r *httpgrpc.HTTPResponse) bool {
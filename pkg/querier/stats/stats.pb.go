@@ -40,6 +40,12 @@ type Stats struct {
 	FetchedChunksCount uint64 `protobuf:"varint,4,opt,name=fetched_chunks_count,json=fetchedChunksCount,proto3" json:"fetched_chunks_count,omitempty"`
 	// The number of sharded queries executed. 0 if sharding is disabled or the query can't be sharded.
 	ShardedQueries uint32 `protobuf:"varint,5,opt,name=sharded_queries,json=shardedQueries,proto3" json:"sharded_queries,omitempty"`
+	// The sum of the time the query spent waiting in the queue before being executed by a querier.
+	QueueTime time.Duration `protobuf:"bytes,6,opt,name=queue_time,json=queueTime,proto3,stdduration" json:"queue_time"`
+	// The number of requests looked up in the query-frontend results cache.
+	ResultsCacheRequests uint32 `protobuf:"varint,7,opt,name=results_cache_requests,json=resultsCacheRequests,proto3" json:"results_cache_requests,omitempty"`
+	// The number of requests whose response has been fully served from the query-frontend results cache.
+	ResultsCacheHits uint32 `protobuf:"varint,8,opt,name=results_cache_hits,json=resultsCacheHits,proto3" json:"results_cache_hits,omitempty"`
 }
 
 func (m *Stats) Reset()      { *m = Stats{} }
@@ -109,6 +115,27 @@ func (m *Stats) GetShardedQueries() uint32 {
 	return 0
 }
 
+func (m *Stats) GetQueueTime() time.Duration {
+	if m != nil {
+		return m.QueueTime
+	}
+	return 0
+}
+
+func (m *Stats) GetResultsCacheRequests() uint32 {
+	if m != nil {
+		return m.ResultsCacheRequests
+	}
+	return 0
+}
+
+func (m *Stats) GetResultsCacheHits() uint32 {
+	if m != nil {
+		return m.ResultsCacheHits
+	}
+	return 0
+}
+
 func init() {
 	proto.RegisterType((*Stats)(nil), "stats.Stats")
 }
@@ -116,27 +143,32 @@ func init() {
 func init() { proto.RegisterFile("stats.proto", fileDescriptor_b4756a0aec8b9d44) }
 
 var fileDescriptor_b4756a0aec8b9d44 = []byte{
-	// 320 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x54, 0x91, 0xbd, 0x4e, 0xc3, 0x30,
-	0x14, 0x85, 0x7d, 0x4b, 0x8b, 0x4a, 0x2a, 0x40, 0x04, 0x86, 0xd0, 0xe1, 0xb6, 0x62, 0xa1, 0x0b,
-	0x2e, 0x82, 0x91, 0x05, 0xb5, 0xbc, 0x00, 0x2d, 0x13, 0x4b, 0x94, 0x1f, 0x37, 0x89, 0x68, 0x6b,
-	0x88, 0x6d, 0x21, 0x36, 0x26, 0x66, 0x46, 0x1e, 0x81, 0x47, 0xe9, 0xd8, 0xb1, 0x13, 0x50, 0x77,
-	0x61, 0xec, 0x23, 0xa0, 0x38, 0x89, 0x80, 0xcd, 0xe7, 0x7e, 0xf7, 0xf3, 0x91, 0x6c, 0xab, 0x21,
-	0xa4, 0x27, 0x05, 0xbd, 0x4f, 0xb9, 0xe4, 0x76, 0xcd, 0x84, 0xe6, 0x49, 0x94, 0xc8, 0x58, 0xf9,
-	0x34, 0xe0, 0x93, 0x6e, 0xc4, 0x23, 0xde, 0x35, 0xd4, 0x57, 0x23, 0x93, 0x4c, 0x30, 0xa7, 0xdc,
-	0x6a, 0x62, 0xc4, 0x79, 0x34, 0x66, 0xbf, 0x5b, 0xa1, 0x4a, 0x3d, 0x99, 0xf0, 0x69, 0xce, 0x8f,
-	0x5e, 0x2a, 0x56, 0x6d, 0x98, 0x5d, 0x6c, 0x5f, 0x5a, 0x5b, 0x8f, 0xde, 0x78, 0xec, 0xca, 0x64,
-	0xc2, 0x1c, 0x68, 0x43, 0xa7, 0x71, 0x76, 0x48, 0x73, 0x9b, 0x96, 0x36, 0xbd, 0x2a, 0xec, 0x5e,
-	0x7d, 0xf6, 0xd1, 0x22, 0x6f, 0x9f, 0x2d, 0x18, 0xd4, 0x33, 0xeb, 0x26, 0x99, 0x30, 0xfb, 0xd4,
-	0x3a, 0x18, 0x31, 0x19, 0xc4, 0x2c, 0x74, 0x05, 0x4b, 0x13, 0x26, 0xdc, 0x80, 0xab, 0xa9, 0x74,
-	0x2a, 0x6d, 0xe8, 0x54, 0x07, 0x76, 0xc1, 0x86, 0x06, 0xf5, 0x33, 0x62, 0x53, 0x6b, 0xbf, 0x34,
-	0x82, 0x58, 0x4d, 0xef, 0x5c, 0xff, 0x49, 0x32, 0xe1, 0x6c, 0x18, 0x61, 0xaf, 0x40, 0xfd, 0x8c,
-	0xf4, 0x32, 0xf0, 0xb7, 0xc1, 0xec, 0x97, 0x0d, 0xd5, 0x7f, 0x0d, 0x46, 0x28, 0x1a, 0x8e, 0xad,
-	0x5d, 0x11, 0x7b, 0x69, 0xc8, 0x42, 0xf7, 0x41, 0x99, 0x66, 0xa7, 0xd6, 0x86, 0xce, 0xf6, 0x60,
-	0xa7, 0x18, 0x5f, 0xe7, 0xd3, 0xde, 0xc5, 0x7c, 0x89, 0x64, 0xb1, 0x44, 0xb2, 0x5e, 0x22, 0x3c,
-	0x6b, 0x84, 0x77, 0x8d, 0x30, 0xd3, 0x08, 0x73, 0x8d, 0xf0, 0xa5, 0x11, 0xbe, 0x35, 0x92, 0xb5,
-	0x46, 0x78, 0x5d, 0x21, 0x99, 0xaf, 0x90, 0x2c, 0x56, 0x48, 0x6e, 0xf3, 0x4f, 0xf1, 0x37, 0xcd,
-	0x03, 0x9d, 0xff, 0x04, 0x00, 0x00, 0xff, 0xff, 0x28, 0xab, 0xfa, 0xea, 0xb1, 0x01, 0x00, 0x00,
+	// 385 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x8c, 0x92, 0xbd, 0x4e, 0xeb, 0x30,
+	0x14, 0xc7, 0xe3, 0xdb, 0x8f, 0xdb, 0xba, 0xba, 0x5f, 0xbe, 0xd5, 0x55, 0x6e, 0x07, 0xb7, 0x62,
+	0xa1, 0x03, 0xa4, 0x08, 0xd8, 0x58, 0x50, 0xca, 0xc0, 0x4a, 0xca, 0xc4, 0x12, 0xe5, 0xc3, 0x4d,
+	0x22, 0xd2, 0x9a, 0xc6, 0xb6, 0x10, 0x1b, 0x8f, 0xc0, 0xc8, 0x23, 0x30, 0xf1, 0x1c, 0x1d, 0x3b,
+	0x76, 0x02, 0x9a, 0x2e, 0x8c, 0x7d, 0x04, 0x94, 0x93, 0x54, 0xb4, 0x1b, 0x9b, 0xcf, 0xf9, 0x9d,
+	0x9f, 0xff, 0x3a, 0x96, 0x71, 0x43, 0x48, 0x47, 0x0a, 0xe3, 0x26, 0xe1, 0x92, 0x93, 0x0a, 0x14,
+	0xad, 0xfd, 0x20, 0x92, 0xa1, 0x72, 0x0d, 0x8f, 0x8f, 0x7a, 0x01, 0x0f, 0x78, 0x0f, 0xa8, 0xab,
+	0x86, 0x50, 0x41, 0x01, 0xa7, 0xdc, 0x6a, 0xd1, 0x80, 0xf3, 0x20, 0x66, 0x9f, 0x53, 0xbe, 0x4a,
+	0x1c, 0x19, 0xf1, 0x71, 0xce, 0x77, 0x9e, 0x4b, 0xb8, 0x32, 0xc8, 0x2e, 0x26, 0xa7, 0xb8, 0x7e,
+	0xeb, 0xc4, 0xb1, 0x2d, 0xa3, 0x11, 0xd3, 0x51, 0x07, 0x75, 0x1b, 0x87, 0xff, 0x8d, 0xdc, 0x36,
+	0xd6, 0xb6, 0x71, 0x56, 0xd8, 0x66, 0x6d, 0xfa, 0xd2, 0xd6, 0x1e, 0x5f, 0xdb, 0xc8, 0xaa, 0x65,
+	0xd6, 0x65, 0x34, 0x62, 0xe4, 0x00, 0x37, 0x87, 0x4c, 0x7a, 0x21, 0xf3, 0x6d, 0xc1, 0x92, 0x88,
+	0x09, 0xdb, 0xe3, 0x6a, 0x2c, 0xf5, 0x6f, 0x1d, 0xd4, 0x2d, 0x5b, 0xa4, 0x60, 0x03, 0x40, 0xfd,
+	0x8c, 0x10, 0x03, 0xff, 0x5d, 0x1b, 0x5e, 0xa8, 0xc6, 0xd7, 0xb6, 0x7b, 0x27, 0x99, 0xd0, 0x4b,
+	0x20, 0xfc, 0x29, 0x50, 0x3f, 0x23, 0x66, 0x06, 0x36, 0x13, 0x60, 0x7e, 0x9d, 0x50, 0xde, 0x4a,
+	0x00, 0xa1, 0x48, 0xd8, 0xc5, 0xbf, 0x44, 0xe8, 0x24, 0x3e, 0xf3, 0xed, 0x89, 0x82, 0x64, 0xbd,
+	0xd2, 0x41, 0xdd, 0x1f, 0xd6, 0xcf, 0xa2, 0x7d, 0x91, 0x77, 0x89, 0x89, 0xf1, 0x44, 0x31, 0xc5,
+	0xf2, 0xfd, 0xab, 0x5f, 0xdf, 0xbf, 0x0e, 0x1a, 0x3c, 0xc0, 0x31, 0xfe, 0x97, 0x30, 0xa1, 0x62,
+	0x29, 0x6c, 0xcf, 0xf1, 0x42, 0x66, 0x27, 0x6c, 0xa2, 0x98, 0x90, 0x42, 0xff, 0x0e, 0x99, 0xcd,
+	0x82, 0xf6, 0x33, 0x68, 0x15, 0x8c, 0xec, 0x61, 0xb2, 0x6d, 0x85, 0x91, 0x14, 0x7a, 0x0d, 0x8c,
+	0xdf, 0x9b, 0xc6, 0x79, 0x24, 0x85, 0x79, 0x32, 0x5b, 0x50, 0x6d, 0xbe, 0xa0, 0xda, 0x6a, 0x41,
+	0xd1, 0x7d, 0x4a, 0xd1, 0x53, 0x4a, 0xd1, 0x34, 0xa5, 0x68, 0x96, 0x52, 0xf4, 0x96, 0x52, 0xf4,
+	0x9e, 0x52, 0x6d, 0x95, 0x52, 0xf4, 0xb0, 0xa4, 0xda, 0x6c, 0x49, 0xb5, 0xf9, 0x92, 0x6a, 0x57,
+	0xf9, 0xe7, 0x71, 0xab, 0xb0, 0xc8, 0xd1, 0xc7, 0x00, 0xd1, 0x4c, 0x60, 0x47, 0x59, 0x02, 0x00,
+	0x00,
 }
 
 func (this *Stats) Equal(that interface{}) bool {
@@ -173,19 +205,31 @@ func (this *Stats) Equal(that interface{}) bool {
 	if this.ShardedQueries != that1.ShardedQueries {
 		return false
 	}
+	if this.QueueTime != that1.QueueTime {
+		return false
+	}
+	if this.ResultsCacheRequests != that1.ResultsCacheRequests {
+		return false
+	}
+	if this.ResultsCacheHits != that1.ResultsCacheHits {
+		return false
+	}
 	return true
 }
 func (this *Stats) GoString() string {
 	if this == nil {
 		return "nil"
 	}
-	s := make([]string, 0, 9)
+	s := make([]string, 0, 12)
 	s = append(s, "&stats.Stats{")
 	s = append(s, "WallTime: "+fmt.Sprintf("%#v", this.WallTime)+",\n")
 	s = append(s, "FetchedSeriesCount: "+fmt.Sprintf("%#v", this.FetchedSeriesCount)+",\n")
 	s = append(s, "FetchedChunkBytes: "+fmt.Sprintf("%#v", this.FetchedChunkBytes)+",\n")
 	s = append(s, "FetchedChunksCount: "+fmt.Sprintf("%#v", this.FetchedChunksCount)+",\n")
 	s = append(s, "ShardedQueries: "+fmt.Sprintf("%#v", this.ShardedQueries)+",\n")
+	s = append(s, "QueueTime: "+fmt.Sprintf("%#v", this.QueueTime)+",\n")
+	s = append(s, "ResultsCacheRequests: "+fmt.Sprintf("%#v", this.ResultsCacheRequests)+",\n")
+	s = append(s, "ResultsCacheHits: "+fmt.Sprintf("%#v", this.ResultsCacheHits)+",\n")
 	s = append(s, "}")
 	return strings.Join(s, "")
 }
@@ -217,6 +261,24 @@ func (m *Stats) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 	_ = i
 	var l int
 	_ = l
+	if m.ResultsCacheHits != 0 {
+		i = encodeVarintStats(dAtA, i, uint64(m.ResultsCacheHits))
+		i--
+		dAtA[i] = 0x40
+	}
+	if m.ResultsCacheRequests != 0 {
+		i = encodeVarintStats(dAtA, i, uint64(m.ResultsCacheRequests))
+		i--
+		dAtA[i] = 0x38
+	}
+	n1, err1 := github_com_gogo_protobuf_types.StdDurationMarshalTo(m.QueueTime, dAtA[i-github_com_gogo_protobuf_types.SizeOfStdDuration(m.QueueTime):])
+	if err1 != nil {
+		return 0, err1
+	}
+	i -= n1
+	i = encodeVarintStats(dAtA, i, uint64(n1))
+	i--
+	dAtA[i] = 0x32
 	if m.ShardedQueries != 0 {
 		i = encodeVarintStats(dAtA, i, uint64(m.ShardedQueries))
 		i--
@@ -237,12 +299,12 @@ func (m *Stats) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 		i--
 		dAtA[i] = 0x10
 	}
-	n1, err1 := github_com_gogo_protobuf_types.StdDurationMarshalTo(m.WallTime, dAtA[i-github_com_gogo_protobuf_types.SizeOfStdDuration(m.WallTime):])
-	if err1 != nil {
-		return 0, err1
+	n2, err2 := github_com_gogo_protobuf_types.StdDurationMarshalTo(m.WallTime, dAtA[i-github_com_gogo_protobuf_types.SizeOfStdDuration(m.WallTime):])
+	if err2 != nil {
+		return 0, err2
 	}
-	i -= n1
-	i = encodeVarintStats(dAtA, i, uint64(n1))
+	i -= n2
+	i = encodeVarintStats(dAtA, i, uint64(n2))
 	i--
 	dAtA[i] = 0xa
 	return len(dAtA) - i, nil
@@ -279,6 +341,14 @@ func (m *Stats) Size() (n int) {
 	if m.ShardedQueries != 0 {
 		n += 1 + sovStats(uint64(m.ShardedQueries))
 	}
+	l = github_com_gogo_protobuf_types.SizeOfStdDuration(m.QueueTime)
+	n += 1 + l + sovStats(uint64(l))
+	if m.ResultsCacheRequests != 0 {
+		n += 1 + sovStats(uint64(m.ResultsCacheRequests))
+	}
+	if m.ResultsCacheHits != 0 {
+		n += 1 + sovStats(uint64(m.ResultsCacheHits))
+	}
 	return n
 }
 
@@ -298,6 +368,9 @@ func (this *Stats) String() string {
 		`FetchedChunkBytes:` + fmt.Sprintf("%v", this.FetchedChunkBytes) + `,`,
 		`FetchedChunksCount:` + fmt.Sprintf("%v", this.FetchedChunksCount) + `,`,
 		`ShardedQueries:` + fmt.Sprintf("%v", this.ShardedQueries) + `,`,
+		`QueueTime:` + strings.Replace(strings.Replace(fmt.Sprintf("%v", this.QueueTime), "Duration", "duration.Duration", 1), `&`, ``, 1) + `,`,
+		`ResultsCacheRequests:` + fmt.Sprintf("%v", this.ResultsCacheRequests) + `,`,
+		`ResultsCacheHits:` + fmt.Sprintf("%v", this.ResultsCacheHits) + `,`,
 		`}`,
 	}, "")
 	return s
@@ -448,6 +521,77 @@ func (m *Stats) Unmarshal(dAtA []byte) error {
 					break
 				}
 			}
+		case 6:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field QueueTime", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowStats
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthStats
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthStats
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if err := github_com_gogo_protobuf_types.StdDurationUnmarshal(&m.QueueTime, dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 7:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field ResultsCacheRequests", wireType)
+			}
+			m.ResultsCacheRequests = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowStats
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.ResultsCacheRequests |= uint32(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 8:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field ResultsCacheHits", wireType)
+			}
+			m.ResultsCacheHits = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowStats
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.ResultsCacheHits |= uint32(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
 		default:
 			iNdEx = preIndex
 			skippy, err := skipStats(dAtA[iNdEx:])
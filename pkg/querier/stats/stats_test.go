@@ -98,6 +98,57 @@ func TestStats_AddShardedQueries(t *testing.T) {
 	})
 }
 
+func TestStats_AddQueueTime(t *testing.T) {
+	t.Run("add and load queue time", func(t *testing.T) {
+		stats, _ := ContextWithEmptyStats(context.Background())
+		stats.AddQueueTime(time.Second)
+		stats.AddQueueTime(time.Minute)
+
+		assert.Equal(t, time.Minute+time.Second, stats.LoadQueueTime())
+	})
+
+	t.Run("add and load queue time nil receiver", func(t *testing.T) {
+		var stats *Stats
+		stats.AddQueueTime(time.Second)
+
+		assert.Equal(t, time.Duration(0), stats.LoadQueueTime())
+	})
+}
+
+func TestStats_AddResultsCacheRequests(t *testing.T) {
+	t.Run("add and load results cache requests", func(t *testing.T) {
+		stats, _ := ContextWithEmptyStats(context.Background())
+		stats.AddResultsCacheRequests(20)
+		stats.AddResultsCacheRequests(22)
+
+		assert.Equal(t, uint32(42), stats.LoadResultsCacheRequests())
+	})
+
+	t.Run("add and load results cache requests nil receiver", func(t *testing.T) {
+		var stats *Stats
+		stats.AddResultsCacheRequests(20)
+
+		assert.Equal(t, uint32(0), stats.LoadResultsCacheRequests())
+	})
+}
+
+func TestStats_AddResultsCacheHits(t *testing.T) {
+	t.Run("add and load results cache hits", func(t *testing.T) {
+		stats, _ := ContextWithEmptyStats(context.Background())
+		stats.AddResultsCacheHits(20)
+		stats.AddResultsCacheHits(22)
+
+		assert.Equal(t, uint32(42), stats.LoadResultsCacheHits())
+	})
+
+	t.Run("add and load results cache hits nil receiver", func(t *testing.T) {
+		var stats *Stats
+		stats.AddResultsCacheHits(20)
+
+		assert.Equal(t, uint32(0), stats.LoadResultsCacheHits())
+	})
+}
+
 func TestStats_Merge(t *testing.T) {
 	t.Run("merge two stats objects", func(t *testing.T) {
 		stats1 := &Stats{}
@@ -106,6 +157,9 @@ func TestStats_Merge(t *testing.T) {
 		stats1.AddFetchedChunkBytes(42)
 		stats1.AddFetchedChunks(10)
 		stats1.AddShardedQueries(20)
+		stats1.AddQueueTime(time.Second)
+		stats1.AddResultsCacheRequests(3)
+		stats1.AddResultsCacheHits(2)
 
 		stats2 := &Stats{}
 		stats2.AddWallTime(time.Second)
@@ -113,6 +167,9 @@ func TestStats_Merge(t *testing.T) {
 		stats2.AddFetchedChunkBytes(100)
 		stats2.AddFetchedChunks(11)
 		stats2.AddShardedQueries(21)
+		stats2.AddQueueTime(time.Minute)
+		stats2.AddResultsCacheRequests(4)
+		stats2.AddResultsCacheHits(1)
 
 		stats1.Merge(stats2)
 
@@ -121,6 +178,9 @@ func TestStats_Merge(t *testing.T) {
 		assert.Equal(t, uint64(142), stats1.LoadFetchedChunkBytes())
 		assert.Equal(t, uint64(21), stats1.LoadFetchedChunks())
 		assert.Equal(t, uint32(41), stats1.LoadShardedQueries())
+		assert.Equal(t, time.Minute+time.Second, stats1.LoadQueueTime())
+		assert.Equal(t, uint32(7), stats1.LoadResultsCacheRequests())
+		assert.Equal(t, uint32(3), stats1.LoadResultsCacheHits())
 	})
 
 	t.Run("merge two nil stats objects", func(t *testing.T) {
@@ -134,5 +194,8 @@ func TestStats_Merge(t *testing.T) {
 		assert.Equal(t, uint64(0), stats1.LoadFetchedChunkBytes())
 		assert.Equal(t, uint64(0), stats1.LoadFetchedChunks())
 		assert.Equal(t, uint32(0), stats1.LoadShardedQueries())
+		assert.Equal(t, time.Duration(0), stats1.LoadQueueTime())
+		assert.Equal(t, uint32(0), stats1.LoadResultsCacheRequests())
+		assert.Equal(t, uint32(0), stats1.LoadResultsCacheHits())
 	})
 }
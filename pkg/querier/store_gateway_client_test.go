@@ -85,3 +85,11 @@ func (m *mockStoreGatewayServer) LabelNames(context.Context, *storepb.LabelNames
 func (m *mockStoreGatewayServer) LabelValues(context.Context, *storepb.LabelValuesRequest) (*storepb.LabelValuesResponse, error) {
 	return nil, nil
 }
+
+func (m *mockStoreGatewayServer) MetricsMetadata(context.Context, *storegatewaypb.MetricsMetadataRequest) (*storegatewaypb.MetricsMetadataResponse, error) {
+	return nil, nil
+}
+
+func (m *mockStoreGatewayServer) Exemplars(context.Context, *storegatewaypb.ExemplarsRequest) (*storegatewaypb.ExemplarsResponse, error) {
+	return nil, nil
+}
@@ -0,0 +1,218 @@
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package querier
+
+import (
+	"context"
+	"sort"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/grafana/dskit/tenant"
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/prometheus/prometheus/storage"
+	"github.com/prometheus/prometheus/tsdb"
+	"github.com/prometheus/prometheus/tsdb/chunkenc"
+	"github.com/prometheus/prometheus/tsdb/tombstones"
+
+	"github.com/grafana/mimir/pkg/storage/bucket"
+	mimir_tsdb "github.com/grafana/mimir/pkg/storage/tsdb"
+	"github.com/grafana/mimir/pkg/util/spanlogger"
+)
+
+// TombstonesProvider returns the series deletion requests which should be applied at query time.
+type TombstonesProvider interface {
+	GetTombstones(ctx context.Context, userID string) ([]*mimir_tsdb.Tombstone, error)
+}
+
+// NewTombstonesLoaderFromConfig makes a new TombstonesLoader reading from the blocks storage bucket.
+func NewTombstonesLoaderFromConfig(querierCfg Config, storageCfg mimir_tsdb.BlocksStorageConfig, logger log.Logger, reg prometheus.Registerer) (*mimir_tsdb.TombstonesLoader, error) {
+	bucketClient, err := bucket.NewClient(context.Background(), storageCfg.Bucket, "querier-tombstones", logger, reg)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create bucket client")
+	}
+
+	return mimir_tsdb.NewTombstonesLoader(bucketClient, querierCfg.SeriesDeletionCacheTTL, storageCfg.BucketStore.ProcessedTombstonesRetention(), logger, reg), nil
+}
+
+// newTombstonesQueryable returns a queryable which removes from the results the data
+// matching the series deletion requests of the tenant.
+func newTombstonesQueryable(next storage.Queryable, provider TombstonesProvider, logger log.Logger) storage.Queryable {
+	return storage.QueryableFunc(func(ctx context.Context, mint, maxt int64) (storage.Querier, error) {
+		q, err := next.Querier(ctx, mint, maxt)
+		if err != nil {
+			return nil, err
+		}
+
+		userID, err := tenant.TenantID(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		all, err := provider.GetTombstones(ctx, userID)
+		if err != nil {
+			level.Error(spanlogger.FromContext(ctx, logger)).Log("msg", "failed to load series deletion requests", "err", err)
+			return nil, err
+		}
+
+		filtered := filterTombstonesByTimeRange(all, mint, maxt)
+		if len(filtered) == 0 {
+			return q, nil
+		}
+
+		return &tombstonesQuerier{Querier: q, tombstones: filtered, mint: mint, maxt: maxt}, nil
+	})
+}
+
+func filterTombstonesByTimeRange(all []*mimir_tsdb.Tombstone, mint, maxt int64) []*mimir_tsdb.Tombstone {
+	var filtered []*mimir_tsdb.Tombstone
+	for _, t := range all {
+		if t.Overlaps(mint, maxt) {
+			filtered = append(filtered, t)
+		}
+	}
+	return filtered
+}
+
+type tombstonesQuerier struct {
+	storage.Querier
+
+	tombstones []*mimir_tsdb.Tombstone
+	mint, maxt int64
+}
+
+// LabelNames returns the label names of the series matching the matchers, excluding the series deleted for the
+// whole queried time range. The label names are resolved from the series, since the label names index of the
+// underlying querier doesn't know about the series deletion requests.
+func (q *tombstonesQuerier) LabelNames(matchers ...*labels.Matcher) ([]string, storage.Warnings, error) {
+	if len(matchers) == 0 {
+		matchers = []*labels.Matcher{labels.MustNewMatcher(labels.MatchRegexp, labels.MetricName, ".+")}
+	}
+
+	names := map[string]struct{}{}
+	warnings, err := q.forEachSeries(matchers, func(series labels.Labels) {
+		for _, l := range series {
+			names[l.Name] = struct{}{}
+		}
+	})
+	if err != nil {
+		return nil, warnings, err
+	}
+
+	return sortedKeys(names), warnings, nil
+}
+
+// LabelValues returns the values of the label name of the series matching the matchers, excluding the series
+// deleted for the whole queried time range, like LabelNames.
+func (q *tombstonesQuerier) LabelValues(name string, matchers ...*labels.Matcher) ([]string, storage.Warnings, error) {
+	matchers = append([]*labels.Matcher{labels.MustNewMatcher(labels.MatchRegexp, name, ".+")}, matchers...)
+
+	values := map[string]struct{}{}
+	warnings, err := q.forEachSeries(matchers, func(series labels.Labels) {
+		if value := series.Get(name); value != "" {
+			values[value] = struct{}{}
+		}
+	})
+	if err != nil {
+		return nil, warnings, err
+	}
+
+	return sortedKeys(values), warnings, nil
+}
+
+// forEachSeries calls f with the labels of each series matching the matchers, which hasn't been deleted for the
+// whole queried time range.
+func (q *tombstonesQuerier) forEachSeries(matchers []*labels.Matcher, f func(series labels.Labels)) (storage.Warnings, error) {
+	set := q.Select(false, &storage.SelectHints{Start: q.mint, End: q.maxt, Func: "series"}, matchers...)
+	for set.Next() {
+		f(set.At().Labels())
+	}
+	return set.Warnings(), set.Err()
+}
+
+func sortedKeys(m map[string]struct{}) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func (q *tombstonesQuerier) Select(sortSeries bool, hints *storage.SelectHints, matchers ...*labels.Matcher) storage.SeriesSet {
+	set := q.Querier.Select(sortSeries, hints, matchers...)
+
+	filtered := q.tombstones
+	if hints != nil {
+		filtered = filterTombstonesByTimeRange(filtered, hints.Start, hints.End)
+	}
+	if len(filtered) == 0 {
+		return set
+	}
+
+	s := &tombstonesSeriesSet{SeriesSet: set, tombstones: filtered}
+	if hints != nil {
+		s.mint, s.maxt = hints.Start, hints.End
+	}
+	return s
+}
+
+// tombstonesSeriesSet removes the samples matching the series deletion requests. Series
+// whose samples have been deleted for the whole queried time range are removed too.
+type tombstonesSeriesSet struct {
+	storage.SeriesSet
+
+	tombstones []*mimir_tsdb.Tombstone
+	mint, maxt int64
+	curr       storage.Series
+}
+
+func (s *tombstonesSeriesSet) Next() bool {
+	for s.SeriesSet.Next() {
+		series := s.SeriesSet.At()
+
+		var intervals tombstones.Intervals
+		for _, t := range s.tombstones {
+			if t.Matches(series.Labels()) {
+				intervals = intervals.Add(t.Interval())
+			}
+		}
+
+		if len(intervals) == 0 {
+			s.curr = series
+			return true
+		}
+
+		// If no time range has been provided we can't tell whether the whole series has been deleted.
+		if s.mint != 0 || s.maxt != 0 {
+			if (tombstones.Interval{Mint: s.mint, Maxt: s.maxt}).IsSubrange(intervals) {
+				continue
+			}
+		}
+
+		s.curr = &tombstonesSeries{Series: series, intervals: intervals}
+		return true
+	}
+
+	return false
+}
+
+func (s *tombstonesSeriesSet) At() storage.Series {
+	return s.curr
+}
+
+type tombstonesSeries struct {
+	storage.Series
+
+	intervals tombstones.Intervals
+}
+
+func (s *tombstonesSeries) Iterator() chunkenc.Iterator {
+	// The deleted iterator consumes the intervals, so we pass a copy.
+	return &tsdb.DeletedIterator{
+		Iter:      s.Series.Iterator(),
+		Intervals: append(tombstones.Intervals(nil), s.intervals...),
+	}
+}
@@ -0,0 +1,185 @@
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package querier
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/prometheus/common/model"
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/prometheus/prometheus/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"github.com/weaveworks/common/user"
+
+	"github.com/grafana/mimir/pkg/storage/series"
+	mimir_tsdb "github.com/grafana/mimir/pkg/storage/tsdb"
+)
+
+type staticTombstonesProvider []*mimir_tsdb.Tombstone
+
+func (p staticTombstonesProvider) GetTombstones(context.Context, string) ([]*mimir_tsdb.Tombstone, error) {
+	return p, nil
+}
+
+func TestTombstonesQueryable(t *testing.T) {
+	mustTombstone := func(start, end int64, selectors ...string) *mimir_tsdb.Tombstone {
+		ts, err := mimir_tsdb.NewTombstone(time.Now(), start, end, selectors)
+		require.NoError(t, err)
+		return ts
+	}
+
+	samples := func(from, to int64) []model.SamplePair {
+		var result []model.SamplePair
+		for ts := from; ts <= to; ts += 10 {
+			result = append(result, model.SamplePair{Timestamp: model.Time(ts), Value: model.SampleValue(ts)})
+		}
+		return result
+	}
+
+	seriesA := labels.FromStrings(labels.MetricName, "up", "job", "a")
+	seriesB := labels.FromStrings(labels.MetricName, "up", "job", "b")
+
+	tests := map[string]struct {
+		tombstones []*mimir_tsdb.Tombstone
+		hints      *storage.SelectHints
+		expected   map[string][]int64
+	}{
+		"no tombstones": {
+			hints: &storage.SelectHints{Start: 0, End: 50},
+			expected: map[string][]int64{
+				seriesA.String(): {0, 10, 20, 30, 40, 50},
+				seriesB.String(): {0, 10, 20, 30, 40, 50},
+			},
+		},
+		"tombstone not matching any series": {
+			tombstones: []*mimir_tsdb.Tombstone{mustTombstone(0, 50, `{job="c"}`)},
+			hints:      &storage.SelectHints{Start: 0, End: 50},
+			expected: map[string][]int64{
+				seriesA.String(): {0, 10, 20, 30, 40, 50},
+				seriesB.String(): {0, 10, 20, 30, 40, 50},
+			},
+		},
+		"tombstone outside the queried time range": {
+			tombstones: []*mimir_tsdb.Tombstone{mustTombstone(100, 200, `{job="a"}`)},
+			hints:      &storage.SelectHints{Start: 0, End: 50},
+			expected: map[string][]int64{
+				seriesA.String(): {0, 10, 20, 30, 40, 50},
+				seriesB.String(): {0, 10, 20, 30, 40, 50},
+			},
+		},
+		"tombstone partially covering a series": {
+			tombstones: []*mimir_tsdb.Tombstone{mustTombstone(10, 25, `{job="a"}`)},
+			hints:      &storage.SelectHints{Start: 0, End: 50},
+			expected: map[string][]int64{
+				seriesA.String(): {0, 30, 40, 50},
+				seriesB.String(): {0, 10, 20, 30, 40, 50},
+			},
+		},
+		"multiple tombstones matching the same series": {
+			tombstones: []*mimir_tsdb.Tombstone{
+				mustTombstone(0, 10, `{job="a"}`),
+				mustTombstone(40, 100, `up`),
+			},
+			hints: &storage.SelectHints{Start: 0, End: 50},
+			expected: map[string][]int64{
+				seriesA.String(): {20, 30},
+				seriesB.String(): {0, 10, 20, 30},
+			},
+		},
+		"tombstone covering the whole queried time range": {
+			tombstones: []*mimir_tsdb.Tombstone{mustTombstone(0, 100, `{job="b"}`)},
+			hints:      &storage.SelectHints{Start: 0, End: 50},
+			expected: map[string][]int64{
+				seriesA.String(): {0, 10, 20, 30, 40, 50},
+			},
+		},
+		"tombstone covering the whole series but no hints": {
+			tombstones: []*mimir_tsdb.Tombstone{mustTombstone(0, 100, `{job="b"}`)},
+			expected: map[string][]int64{
+				seriesA.String(): {0, 10, 20, 30, 40, 50},
+				seriesB.String(): {},
+			},
+		},
+	}
+
+	for testName, testData := range tests {
+		t.Run(testName, func(t *testing.T) {
+			q := &mockBlocksStorageQuerier{}
+			q.On("Select", mock.Anything, mock.Anything, mock.Anything).Return(series.NewConcreteSeriesSet([]storage.Series{
+				series.NewConcreteSeries(seriesA, samples(0, 50)),
+				series.NewConcreteSeries(seriesB, samples(0, 50)),
+			}))
+
+			queryable := newTombstonesQueryable(newMockBlocksStorageQueryable(q), staticTombstonesProvider(testData.tombstones), log.NewNopLogger())
+
+			ctx := user.InjectOrgID(context.Background(), "user")
+			querier, err := queryable.Querier(ctx, 0, 50)
+			require.NoError(t, err)
+
+			set := querier.Select(true, testData.hints, labels.MustNewMatcher(labels.MatchEqual, labels.MetricName, "up"))
+
+			actual := map[string][]int64{}
+			for set.Next() {
+				s := set.At()
+				timestamps := []int64{}
+				it := s.Iterator()
+				for it.Next() {
+					ts, _ := it.At()
+					timestamps = append(timestamps, ts)
+				}
+				require.NoError(t, it.Err())
+
+				// Iterating the series again must return the same samples.
+				it = s.Iterator()
+				count := 0
+				for it.Next() {
+					count++
+				}
+				require.Equal(t, len(timestamps), count)
+
+				actual[s.Labels().String()] = timestamps
+			}
+			require.NoError(t, set.Err())
+
+			assert.Equal(t, testData.expected, actual)
+		})
+	}
+}
+
+func TestTombstonesQueryable_LabelNamesAndValues(t *testing.T) {
+	seriesA := labels.FromStrings(labels.MetricName, "up", "job", "a")
+	seriesB := labels.FromStrings(labels.MetricName, "up", "job", "b", "email", "user@example.com")
+
+	tombstone, err := mimir_tsdb.NewTombstone(time.Now(), 0, 100, []string{`{email="user@example.com"}`})
+	require.NoError(t, err)
+
+	q := &mockBlocksStorageQuerier{}
+	for i := 0; i < 3; i++ {
+		q.On("Select", false, &storage.SelectHints{Start: 0, End: 50, Func: "series"}, mock.Anything).Return(series.NewConcreteSeriesSet([]storage.Series{
+			series.NewConcreteSeries(seriesA, nil),
+			series.NewConcreteSeries(seriesB, nil),
+		})).Once()
+	}
+
+	queryable := newTombstonesQueryable(newMockBlocksStorageQueryable(q), staticTombstonesProvider{tombstone}, log.NewNopLogger())
+	querier, err := queryable.Querier(user.InjectOrgID(context.Background(), "user"), 0, 50)
+	require.NoError(t, err)
+
+	// The labels of the deleted series aren't returned.
+	names, _, err := querier.LabelNames()
+	require.NoError(t, err)
+	assert.Equal(t, []string{labels.MetricName, "job"}, names)
+
+	values, _, err := querier.LabelValues("job")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a"}, values)
+
+	values, _, err = querier.LabelValues("email")
+	require.NoError(t, err)
+	assert.Empty(t, values)
+}
@@ -0,0 +1,89 @@
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package querier
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/grafana/dskit/tenant"
+	v1 "github.com/prometheus/prometheus/web/api/v1"
+
+	ingester_client "github.com/grafana/mimir/pkg/ingester/client"
+	"github.com/grafana/mimir/pkg/util"
+	"github.com/grafana/mimir/pkg/util/validation"
+)
+
+// defaultTSDBStatusLimit is the default number of items of each list of statistics, like in Prometheus.
+const defaultTSDBStatusLimit = 10
+
+type tsdbStatusResult struct {
+	Status string        `json:"status"`
+	Data   v1.TSDBStatus `json:"data"`
+}
+
+// TSDBStatusHandler creates handler for the Prometheus compatible TSDB status endpoint, returning the
+// cardinality statistics of the tenant's series in the ingesters.
+func TSDBStatusHandler(d Distributor, limits *validation.Overrides) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		tenantID, err := tenant.TenantID(ctx)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if !limits.CardinalityAnalysisEnabled(tenantID) {
+			http.Error(w, fmt.Sprintf("cardinality analysis is disabled for the tenant: %v", tenantID), http.StatusBadRequest)
+			return
+		}
+
+		limit, err := extractTSDBStatusRequestParams(r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		response, err := d.TSDBStatus(ctx, limit)
+		if err != nil {
+			respondFromError(err, w)
+			return
+		}
+
+		util.WriteJSONResponse(w, tsdbStatusResult{Status: statusSuccess, Data: toTSDBStatus(response)})
+	})
+}
+
+func extractTSDBStatusRequestParams(r *http.Request) (int, error) {
+	if err := r.ParseForm(); err != nil {
+		return 0, err
+	}
+	if len(r.Form["limit"]) == 0 {
+		return defaultTSDBStatusLimit, nil
+	}
+	return extractLimit(r)
+}
+
+// toTSDBStatus converts distributor's response to the Prometheus TSDB status.
+func toTSDBStatus(response *ingester_client.TSDBStatusResponse) v1.TSDBStatus {
+	return v1.TSDBStatus{
+		HeadStats: v1.HeadStats{
+			NumSeries:     response.NumSeries,
+			NumLabelPairs: int(response.NumLabelPairs),
+			ChunkCount:    int64(response.ChunkCount),
+			MinTime:       response.MinTime,
+			MaxTime:       response.MaxTime,
+		},
+		SeriesCountByMetricName:     toTSDBStats(response.SeriesCountByMetricName),
+		LabelValueCountByLabelName:  toTSDBStats(response.LabelValueCountByLabelName),
+		MemoryInBytesByLabelName:    toTSDBStats(response.MemoryInBytesByLabelName),
+		SeriesCountByLabelValuePair: toTSDBStats(response.SeriesCountByLabelValuePair),
+	}
+}
+
+func toTSDBStats(items []ingester_client.TSDBStatisticItem) []v1.TSDBStat {
+	stats := make([]v1.TSDBStat, 0, len(items))
+	for _, item := range items {
+		stats = append(stats, v1.TSDBStat{Name: item.Name, Value: item.Value})
+	}
+	return stats
+}
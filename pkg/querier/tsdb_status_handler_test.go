@@ -0,0 +1,105 @@
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package querier
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/mimir/pkg/ingester/client"
+	"github.com/grafana/mimir/pkg/util/validation"
+)
+
+func TestTSDBStatusHandler(t *testing.T) {
+	response := &client.TSDBStatusResponse{
+		NumSeries:     3,
+		NumLabelPairs: 5,
+		ChunkCount:    4,
+		MinTime:       100000,
+		MaxTime:       200000,
+		SeriesCountByMetricName: []client.TSDBStatisticItem{
+			{Name: "test_1", Value: 2},
+			{Name: "test_2", Value: 1},
+		},
+		LabelValueCountByLabelName: []client.TSDBStatisticItem{
+			{Name: "__name__", Value: 2},
+		},
+		MemoryInBytesByLabelName: []client.TSDBStatisticItem{
+			{Name: "__name__", Value: 12},
+		},
+		SeriesCountByLabelValuePair: []client.TSDBStatisticItem{
+			{Name: "__name__=test_1", Value: 2},
+		},
+	}
+
+	tests := map[string]struct {
+		url                        string
+		cardinalityAnalysisEnabled bool
+		expectedLimit              int
+		expectedStatusCode         int
+		expectedBody               string
+	}{
+		"should return the statistics in the Prometheus format": {
+			url:                        "/api/v1/status/tsdb",
+			cardinalityAnalysisEnabled: true,
+			expectedLimit:              defaultTSDBStatusLimit,
+			expectedStatusCode:         http.StatusOK,
+			expectedBody: `{
+				"status": "success",
+				"data": {
+					"headStats": {"numSeries": 3, "numLabelPairs": 5, "chunkCount": 4, "minTime": 100000, "maxTime": 200000},
+					"seriesCountByMetricName": [{"name": "test_1", "value": 2}, {"name": "test_2", "value": 1}],
+					"labelValueCountByLabelName": [{"name": "__name__", "value": 2}],
+					"memoryInBytesByLabelName": [{"name": "__name__", "value": 12}],
+					"seriesCountByLabelValuePair": [{"name": "__name__=test_1", "value": 2}]
+				}
+			}`,
+		},
+		"should pass the limit to the distributor": {
+			url:                        "/api/v1/status/tsdb?limit=2",
+			cardinalityAnalysisEnabled: true,
+			expectedLimit:              2,
+			expectedStatusCode:         http.StatusOK,
+		},
+		"should return an error if the limit is invalid": {
+			url:                        "/api/v1/status/tsdb?limit=-1",
+			cardinalityAnalysisEnabled: true,
+			expectedStatusCode:         http.StatusBadRequest,
+		},
+		"should return an error if the cardinality analysis feature is disabled": {
+			url:                        "/api/v1/status/tsdb",
+			cardinalityAnalysisEnabled: false,
+			expectedStatusCode:         http.StatusBadRequest,
+		},
+	}
+
+	for testName, testData := range tests {
+		t.Run(testName, func(t *testing.T) {
+			distributor := &mockDistributor{}
+			distributor.On("TSDBStatus", mock.Anything, testData.expectedLimit).Return(response, nil)
+
+			limits := validation.Limits{CardinalityAnalysisEnabled: testData.cardinalityAnalysisEnabled}
+			overrides, err := validation.NewOverrides(limits, nil)
+			require.NoError(t, err)
+			handler := TSDBStatusHandler(distributor, overrides)
+
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, createRequest(testData.url, "team-a"))
+
+			require.Equal(t, testData.expectedStatusCode, recorder.Result().StatusCode)
+			if testData.expectedStatusCode != http.StatusOK {
+				distributor.AssertNotCalled(t, "TSDBStatus", mock.Anything, mock.Anything)
+				return
+			}
+
+			distributor.AssertCalled(t, "TSDBStatus", mock.Anything, testData.expectedLimit)
+			if testData.expectedBody != "" {
+				require.JSONEq(t, testData.expectedBody, recorder.Body.String())
+			}
+		})
+	}
+}
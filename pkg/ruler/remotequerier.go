@@ -32,6 +32,7 @@ import (
 	"github.com/weaveworks/common/user"
 	"google.golang.org/grpc"
 
+	"github.com/grafana/mimir/pkg/scheduler/queue"
 	"github.com/grafana/mimir/pkg/util/spanlogger"
 	"github.com/grafana/mimir/pkg/util/version"
 )
@@ -208,6 +209,8 @@ func (q *RemoteQuerier) query(ctx context.Context, query string, ts time.Time, l
 			{Key: textproto.CanonicalMIMEHeaderKey("User-Agent"), Values: []string{userAgent}},
 			{Key: textproto.CanonicalMIMEHeaderKey("Content-Type"), Values: []string{mimeTypeFormPost}},
 			{Key: textproto.CanonicalMIMEHeaderKey("Content-Length"), Values: []string{strconv.Itoa(len(body))}},
+			// Rule evaluations are dequeued before the other queries of the tenant.
+			{Key: textproto.CanonicalMIMEHeaderKey(queue.PriorityHeaderName), Values: []string{queue.PriorityHigh.String()}},
 		},
 	}
 
@@ -18,6 +18,8 @@ import (
 	"github.com/weaveworks/common/httpgrpc"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
+
+	"github.com/grafana/mimir/pkg/scheduler/queue"
 )
 
 type mockHTTPGRPCClient func(ctx context.Context, req *httpgrpc.HTTPRequest, _ ...grpc.CallOption) (*httpgrpc.HTTPResponse, error)
@@ -86,6 +88,7 @@ func TestRemoteQuerier_QueryReq(t *testing.T) {
 	require.Equal(t, http.MethodPost, inReq.Method)
 	require.Equal(t, "query=qs&time="+url.QueryEscape(tm.Format(time.RFC3339Nano)), string(inReq.Body))
 	require.Equal(t, "/prometheus/api/v1/query", inReq.Url)
+	require.Contains(t, inReq.Headers, &httpgrpc.Header{Key: queue.PriorityHeaderName, Values: []string{"high"}})
 }
 
 func TestRemoteQuerier_QueryReqTimeout(t *testing.T) {
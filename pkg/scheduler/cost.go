@@ -0,0 +1,121 @@
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package scheduler
+
+import (
+	"bytes"
+	"math"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/prometheus/prometheus/promql/parser"
+	"github.com/weaveworks/common/httpgrpc"
+
+	"github.com/grafana/mimir/pkg/storage/sharding"
+	"github.com/grafana/mimir/pkg/util"
+)
+
+const (
+	// minQueryCost is the cost of the cheapest requests, and of the requests whose cost can't be estimated.
+	minQueryCost = 1
+
+	// unselectiveSelectorCostMultiplier is applied to series selectors without an equality matcher
+	// on the metric name, which are expected to select many more series than the others.
+	unselectiveSelectorCostMultiplier = 4
+
+	// costUnitDuration is the time range a series selector can query for each cost unit.
+	costUnitDuration = 24 * time.Hour
+)
+
+// estimateQueryCost returns the estimated cost of the request, in cost units. A cost unit is roughly the
+// cost of running a single series selector over one day of data, so a 30 days range query is about
+// 30 times more expensive than an instant query for the same expression. Requests which are not
+// range or instant queries have the minimum cost.
+func estimateQueryCost(req *httpgrpc.HTTPRequest) int {
+	r, err := http.NewRequest(req.Method, req.Url, bytes.NewReader(req.Body))
+	if err != nil {
+		return minQueryCost
+	}
+	for _, h := range req.Headers {
+		for _, v := range h.Values {
+			r.Header.Add(h.Key, v)
+		}
+	}
+	if err := r.ParseForm(); err != nil {
+		return minQueryCost
+	}
+
+	var queryRange time.Duration
+	switch {
+	case strings.HasSuffix(r.URL.Path, "/query_range"):
+		start, err := util.ParseTime(r.Form.Get("start"))
+		if err != nil {
+			return minQueryCost
+		}
+		end, err := util.ParseTime(r.Form.Get("end"))
+		if err != nil {
+			return minQueryCost
+		}
+		queryRange = time.Duration(end-start) * time.Millisecond
+	case strings.HasSuffix(r.URL.Path, "/query"):
+		queryRange = 0
+	default:
+		return minQueryCost
+	}
+
+	expr, err := parser.ParseExpr(r.Form.Get("query"))
+	if err != nil {
+		return minQueryCost
+	}
+
+	return exprCost(expr, queryRange)
+}
+
+// exprCost returns the estimated cost of running expr over queryRange. The cost of each series
+// selector depends on the time range it queries (including the range of the enclosing range vector
+// selectors and subqueries) and on its matchers. Selectors on a single query shard only query a
+// fraction of the series, so their cost is divided by the number of shards.
+func exprCost(expr parser.Expr, queryRange time.Duration) int {
+	cost := 0.0
+
+	parser.Inspect(expr, func(node parser.Node, path []parser.Node) error {
+		vs, ok := node.(*parser.VectorSelector)
+		if !ok {
+			return nil
+		}
+
+		selectorRange := queryRange
+		for _, p := range path {
+			switch n := p.(type) {
+			case *parser.MatrixSelector:
+				selectorRange += n.Range
+			case *parser.SubqueryExpr:
+				selectorRange += n.Range
+			}
+		}
+
+		selectorCost := math.Max(1, math.Ceil(float64(selectorRange)/float64(costUnitDuration)))
+		if !hasMetricNameEqualMatcher(vs.LabelMatchers) {
+			selectorCost *= unselectiveSelectorCostMultiplier
+		}
+		if shard, _, err := sharding.ShardFromMatchers(vs.LabelMatchers); err == nil && shard != nil && shard.ShardCount > 0 {
+			selectorCost /= float64(shard.ShardCount)
+		}
+
+		cost += selectorCost
+		return nil
+	})
+
+	return int(math.Max(minQueryCost, math.Ceil(cost)))
+}
+
+func hasMetricNameEqualMatcher(matchers []*labels.Matcher) bool {
+	for _, m := range matchers {
+		if m.Name == labels.MetricName && m.Type == labels.MatchEqual {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,81 @@
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package scheduler
+
+import (
+	"net/url"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/weaveworks/common/httpgrpc"
+)
+
+func TestEstimateQueryCost(t *testing.T) {
+	const day = 86400
+
+	tests := map[string]struct {
+		req          *httpgrpc.HTTPRequest
+		expectedCost int
+	}{
+		"instant query on a single metric": {
+			req:          newQueryRequest("/prometheus/api/v1/query", url.Values{"query": {"up"}}),
+			expectedCost: 1,
+		},
+		"5 minutes range query on a single metric": {
+			req:          newQueryRequest("/prometheus/api/v1/query_range", url.Values{"query": {"up"}, "start": {"0"}, "end": {"300"}, "step": {"15"}}),
+			expectedCost: 1,
+		},
+		"30 days range query": {
+			req:          newQueryRequest("/prometheus/api/v1/query_range", url.Values{"query": {"count by(job) (up)"}, "start": {"0"}, "end": {strconv.Itoa(30 * day)}, "step": {"60"}}),
+			expectedCost: 30,
+		},
+		"instant query with a range vector selector": {
+			req:          newQueryRequest("/prometheus/api/v1/query", url.Values{"query": {"rate(up[7d])"}}),
+			expectedCost: 7,
+		},
+		"instant query with a subquery": {
+			req:          newQueryRequest("/prometheus/api/v1/query", url.Values{"query": {"max_over_time(rate(up[1d])[2d:1m])"}}),
+			expectedCost: 3,
+		},
+		"query with multiple selectors": {
+			req:          newQueryRequest("/prometheus/api/v1/query", url.Values{"query": {"up / on(job) group_left up_info"}}),
+			expectedCost: 2,
+		},
+		"query without metric name equality matcher": {
+			req:          newQueryRequest("/prometheus/api/v1/query", url.Values{"query": {`{__name__=~"up|down", job="test"}`}}),
+			expectedCost: unselectiveSelectorCostMultiplier,
+		},
+		"sharded query": {
+			req:          newQueryRequest("/prometheus/api/v1/query_range", url.Values{"query": {`sum(up{__query_shard__="1_of_4"})`}, "start": {"0"}, "end": {strconv.Itoa(16 * day)}, "step": {"60"}}),
+			expectedCost: 4,
+		},
+		"query sent via POST": {
+			req: &httpgrpc.HTTPRequest{
+				Method:  "POST",
+				Url:     "/prometheus/api/v1/query_range",
+				Body:    []byte(url.Values{"query": {"up"}, "start": {"0"}, "end": {strconv.Itoa(10 * day)}, "step": {"60"}}.Encode()),
+				Headers: []*httpgrpc.Header{{Key: "Content-Type", Values: []string{"application/x-www-form-urlencoded"}}},
+			},
+			expectedCost: 10,
+		},
+		"invalid query": {
+			req:          newQueryRequest("/prometheus/api/v1/query", url.Values{"query": {"up["}}),
+			expectedCost: minQueryCost,
+		},
+		"non query request": {
+			req:          newQueryRequest("/prometheus/api/v1/series", url.Values{"match[]": {"up"}, "start": {"0"}, "end": {strconv.Itoa(30 * day)}}),
+			expectedCost: minQueryCost,
+		},
+	}
+
+	for testName, testData := range tests {
+		t.Run(testName, func(t *testing.T) {
+			assert.Equal(t, testData.expectedCost, estimateQueryCost(testData.req))
+		})
+	}
+}
+
+func newQueryRequest(path string, params url.Values) *httpgrpc.HTTPRequest {
+	return &httpgrpc.HTTPRequest{Method: "GET", Url: path + "?" + params.Encode()}
+}
@@ -0,0 +1,66 @@
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package queue
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// Priority of a request within the tenant queue. Requests with a higher priority are dequeued first.
+type Priority int
+
+const (
+	PriorityNormal Priority = iota
+	PriorityHigh
+
+	numPriorities = int(PriorityHigh) + 1
+)
+
+// PriorityHeaderName is the HTTP request header a client can set to the name of the priority of the query.
+const PriorityHeaderName = "X-Mimir-Query-Priority"
+
+type contextKey int
+
+const priorityContextKey contextKey = 0
+
+// String implements fmt.Stringer.
+func (p Priority) String() string {
+	switch p {
+	case PriorityNormal:
+		return "normal"
+	case PriorityHigh:
+		return "high"
+	default:
+		return fmt.Sprintf("unknown(%d)", int(p))
+	}
+}
+
+// IsValid returns whether p is a known priority.
+func (p Priority) IsValid() bool {
+	return p >= PriorityNormal && int(p) < numPriorities
+}
+
+// ParsePriority parses the priority name, as returned by Priority.String.
+func ParsePriority(name string) (Priority, error) {
+	for p := PriorityNormal; int(p) < numPriorities; p++ {
+		if strings.EqualFold(name, p.String()) {
+			return p, nil
+		}
+	}
+	return PriorityNormal, fmt.Errorf("unknown query priority %q", name)
+}
+
+// ContextWithPriority returns a new context with the given query priority.
+func ContextWithPriority(ctx context.Context, p Priority) context.Context {
+	return context.WithValue(ctx, priorityContextKey, p)
+}
+
+// PriorityFromContext returns the query priority stored in the context, or PriorityNormal if not set.
+func PriorityFromContext(ctx context.Context) Priority {
+	if p, ok := ctx.Value(priorityContextKey).(Priority); ok {
+		return p
+	}
+	return PriorityNormal
+}
@@ -0,0 +1,31 @@
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package queue
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParsePriority(t *testing.T) {
+	for _, p := range []Priority{PriorityNormal, PriorityHigh} {
+		parsed, err := ParsePriority(p.String())
+		require.NoError(t, err)
+		assert.Equal(t, p, parsed)
+	}
+
+	parsed, err := ParsePriority("HIGH")
+	require.NoError(t, err)
+	assert.Equal(t, PriorityHigh, parsed)
+
+	_, err = ParsePriority("urgent")
+	assert.Error(t, err)
+}
+
+func TestPriorityFromContext(t *testing.T) {
+	assert.Equal(t, PriorityNormal, PriorityFromContext(context.Background()))
+	assert.Equal(t, PriorityHigh, PriorityFromContext(ContextWithPriority(context.Background(), PriorityHigh)))
+}
@@ -7,6 +7,7 @@ package queue
 
 import (
 	"context"
+	"fmt"
 	"sync"
 	"time"
 
@@ -19,6 +20,10 @@ import (
 const (
 	// How frequently to check for disconnected queriers that should be forgotten.
 	forgetCheckPeriod = 5 * time.Second
+
+	// How many cheap requests of a user can be dequeued while its expensive requests are waiting,
+	// before giving precedence to the expensive ones.
+	cheapRequestsPerExpensiveRequest = 4
 )
 
 var (
@@ -57,20 +62,25 @@ type RequestQueue struct {
 	connectedQuerierWorkers *atomic.Int32
 
 	mtx     sync.Mutex
-	cond    contextCond // Notified when request is enqueued or dequeued, or querier is disconnected.
+	cond    contextCond // Notified when request is enqueued, dequeued or completed, or querier is disconnected.
 	queues  *queues
 	stopped bool
 
+	// Requests with an estimated cost greater than or equal to this threshold are queued
+	// in the expensive lane of the user queue (zero = disabled).
+	expensiveQueryCostThreshold int
+
 	queueLength       *prometheus.GaugeVec   // Per user and reason.
 	discardedRequests *prometheus.CounterVec // Per user.
 }
 
-func NewRequestQueue(maxOutstandingPerTenant int, forgetDelay time.Duration, queueLength *prometheus.GaugeVec, discardedRequests *prometheus.CounterVec) *RequestQueue {
+func NewRequestQueue(maxOutstandingPerTenant int, expensiveQueryCostThreshold int, forgetDelay time.Duration, queueLength *prometheus.GaugeVec, discardedRequests *prometheus.CounterVec) *RequestQueue {
 	q := &RequestQueue{
-		queues:                  newUserQueues(maxOutstandingPerTenant, forgetDelay),
-		connectedQuerierWorkers: atomic.NewInt32(0),
-		queueLength:             queueLength,
-		discardedRequests:       discardedRequests,
+		queues:                      newUserQueues(maxOutstandingPerTenant, forgetDelay),
+		expensiveQueryCostThreshold: expensiveQueryCostThreshold,
+		connectedQuerierWorkers:     atomic.NewInt32(0),
+		queueLength:                 queueLength,
+		discardedRequests:           discardedRequests,
 	}
 
 	q.cond = contextCond{Cond: sync.NewCond(&q.mtx)}
@@ -79,12 +89,14 @@ func NewRequestQueue(maxOutstandingPerTenant int, forgetDelay time.Duration, que
 	return q
 }
 
-// EnqueueRequest puts the request into the queue. MaxQueries is user-specific value that specifies how many queriers can
-// this user use (zero or negative = all queriers). It is passed to each EnqueueRequest, because it can change
-// between calls.
+// EnqueueRequest puts the request into the queue. Requests with a higher priority are dequeued before the other
+// requests of the same user. Cost is the estimated cost of the request (zero if unknown).
+// MaxQueries is user-specific value that specifies how many queriers can this user use (zero or negative = all queriers),
+// and maxInflightCost is the user-specific max total cost of the requests dequeued but not completed yet (zero or
+// negative = unlimited). They are passed to each EnqueueRequest, because they can change between calls.
 //
 // If request is successfully enqueued, successFn is called with the lock held, before any querier can receive the request.
-func (q *RequestQueue) EnqueueRequest(userID string, req Request, maxQueriers int, successFn func()) error {
+func (q *RequestQueue) EnqueueRequest(userID string, req Request, priority Priority, cost int, maxQueriers int, maxInflightCost int, successFn func()) error {
 	q.mtx.Lock()
 	defer q.mtx.Unlock()
 
@@ -92,25 +104,33 @@ func (q *RequestQueue) EnqueueRequest(userID string, req Request, maxQueriers in
 		return ErrStopped
 	}
 
+	if !priority.IsValid() {
+		return fmt.Errorf("invalid priority %d", int(priority))
+	}
+
 	queue := q.queues.getOrAddQueue(userID, maxQueriers)
 	if queue == nil {
 		// This can only happen if userID is "".
 		return errors.New("no queue found")
 	}
 
-	select {
-	case queue <- req:
-		q.queueLength.WithLabelValues(userID).Inc()
-		q.cond.Broadcast()
-		// Call this function while holding a lock. This guarantees that no querier can fetch the request before function returns.
-		if successFn != nil {
-			successFn()
+	if queue.len() >= q.queues.maxUserQueueSize {
+		if queue.len() == 0 {
+			q.queues.deleteQueue(userID)
 		}
-		return nil
-	default:
 		q.discardedRequests.WithLabelValues(userID).Inc()
 		return ErrTooManyRequests
 	}
+
+	queue.maxInflightCost = maxInflightCost
+	queue.enqueue(queuedRequest{req: req, cost: cost}, priority, q.expensiveQueryCostThreshold > 0 && cost >= q.expensiveQueryCostThreshold)
+	q.queueLength.WithLabelValues(userID).Inc()
+	q.cond.Broadcast()
+	// Call this function while holding a lock. This guarantees that no querier can fetch the request before function returns.
+	if successFn != nil {
+		successFn()
+	}
+	return nil
 }
 
 // GetNextRequestForQuerier find next user queue and takes the next request off of it. Will block if there are no requests.
@@ -137,7 +157,8 @@ FindQueue:
 		return nil, last, err
 	}
 
-	for {
+	// Each user is checked at most once: users whose requests don't fit in their in-flight cost budget are skipped.
+	for users := q.queues.len(); users > 0; users-- {
 		queue, userID, idx := q.queues.getNextQueueForQuerier(last.last, querierID)
 		last.last = idx
 		if queue == nil {
@@ -145,27 +166,46 @@ FindQueue:
 		}
 
 		// Pick next request from the queue.
-		for {
-			request := <-queue
-			if len(queue) == 0 {
-				q.queues.deleteQueue(userID)
-			}
+		request, ok := queue.dequeue(q.queues.inflightCost[userID])
+		if !ok {
+			continue
+		}
+
+		if queue.len() == 0 {
+			q.queues.deleteQueue(userID)
+		}
 
-			q.queueLength.WithLabelValues(userID).Dec()
+		q.queues.addInflightCost(userID, request.cost)
+		q.queueLength.WithLabelValues(userID).Dec()
 
-			// Tell close() we've processed a request.
-			q.cond.Broadcast()
+		// Tell close() we've processed a request.
+		q.cond.Broadcast()
 
-			return request, last, nil
-		}
+		return request.req, last, nil
 	}
 
-	// There are no unexpired requests, so we can get back
-	// and wait for more requests.
+	// There are no requests which can be dispatched, so we can get back
+	// and wait for more requests (or for in-flight requests to complete).
 	querierWait = true
 	goto FindQueue
 }
 
+// RequestCompleted must be called once a request returned by GetNextRequestForQuerier has been processed
+// (or discarded), to release its cost from the user in-flight cost.
+func (q *RequestQueue) RequestCompleted(userID string, cost int) {
+	if cost <= 0 {
+		return
+	}
+
+	q.mtx.Lock()
+	defer q.mtx.Unlock()
+
+	q.queues.releaseInflightCost(userID, cost)
+
+	// Requests waiting for the in-flight cost to decrease may now be dispatched.
+	q.cond.Broadcast()
+}
+
 func (q *RequestQueue) forgetDisconnectedQueriers(_ context.Context) error {
 	q.mtx.Lock()
 	defer q.mtx.Unlock()
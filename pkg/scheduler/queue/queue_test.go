@@ -27,7 +27,7 @@ func BenchmarkGetNextRequest(b *testing.B) {
 	queues := make([]*RequestQueue, 0, b.N)
 
 	for n := 0; n < b.N; n++ {
-		queue := NewRequestQueue(maxOutstandingPerTenant, 0,
+		queue := NewRequestQueue(maxOutstandingPerTenant, 0, 0,
 			prometheus.NewGaugeVec(prometheus.GaugeOpts{}, []string{"user"}),
 			prometheus.NewCounterVec(prometheus.CounterOpts{}, []string{"user"}),
 		)
@@ -41,7 +41,7 @@ func BenchmarkGetNextRequest(b *testing.B) {
 			for j := 0; j < numTenants; j++ {
 				userID := strconv.Itoa(j)
 
-				err := queue.EnqueueRequest(userID, "request", 0, nil)
+				err := queue.EnqueueRequest(userID, "request", PriorityNormal, 0, 0, 0, nil)
 				if err != nil {
 					b.Fatal(err)
 				}
@@ -84,7 +84,7 @@ func BenchmarkQueueRequest(b *testing.B) {
 	requests := make([]string, 0, numTenants)
 
 	for n := 0; n < b.N; n++ {
-		q := NewRequestQueue(maxOutstandingPerTenant, 0,
+		q := NewRequestQueue(maxOutstandingPerTenant, 0, 0,
 			prometheus.NewGaugeVec(prometheus.GaugeOpts{}, []string{"user"}),
 			prometheus.NewCounterVec(prometheus.CounterOpts{}, []string{"user"}),
 		)
@@ -105,7 +105,7 @@ func BenchmarkQueueRequest(b *testing.B) {
 	for n := 0; n < b.N; n++ {
 		for i := 0; i < maxOutstandingPerTenant; i++ {
 			for j := 0; j < numTenants; j++ {
-				err := queues[n].EnqueueRequest(users[j], requests[j], 0, nil)
+				err := queues[n].EnqueueRequest(users[j], requests[j], PriorityNormal, 0, 0, 0, nil)
 				if err != nil {
 					b.Fatal(err)
 				}
@@ -117,7 +117,7 @@ func BenchmarkQueueRequest(b *testing.B) {
 func TestRequestQueue_GetNextRequestForQuerier_ShouldGetRequestAfterReshardingBecauseQuerierHasBeenForgotten(t *testing.T) {
 	const forgetDelay = 3 * time.Second
 
-	queue := NewRequestQueue(1, forgetDelay,
+	queue := NewRequestQueue(1, 0, forgetDelay,
 		prometheus.NewGaugeVec(prometheus.GaugeOpts{}, []string{"user"}),
 		prometheus.NewCounterVec(prometheus.CounterOpts{}, []string{"user"}))
 
@@ -146,7 +146,7 @@ func TestRequestQueue_GetNextRequestForQuerier_ShouldGetRequestAfterReshardingBe
 
 	// Enqueue a request from an user which would be assigned to querier-1.
 	// NOTE: "user-1" hash falls in the querier-1 shard.
-	require.NoError(t, queue.EnqueueRequest("user-1", "request", 1, nil))
+	require.NoError(t, queue.EnqueueRequest("user-1", "request", PriorityNormal, 0, 1, 0, nil))
 
 	startTime := time.Now()
 	querier2wg.Wait()
@@ -156,6 +156,105 @@ func TestRequestQueue_GetNextRequestForQuerier_ShouldGetRequestAfterReshardingBe
 	assert.GreaterOrEqual(t, waitTime.Milliseconds(), forgetDelay.Milliseconds())
 }
 
+func TestRequestQueue_ExpensiveRequestsLane(t *testing.T) {
+	queue := NewRequestQueue(100, 10, 0,
+		prometheus.NewGaugeVec(prometheus.GaugeOpts{}, []string{"user"}),
+		prometheus.NewCounterVec(prometheus.CounterOpts{}, []string{"user"}))
+
+	ctx := context.Background()
+	require.NoError(t, services.StartAndAwaitRunning(ctx, queue))
+	t.Cleanup(func() {
+		require.NoError(t, services.StopAndAwaitTerminated(ctx, queue))
+	})
+
+	queue.RegisterQuerierConnection("querier-1")
+
+	// Enqueue expensive requests first, followed by cheap ones.
+	for i := 0; i < 2; i++ {
+		require.NoError(t, queue.EnqueueRequest("user-1", fmt.Sprintf("expensive-%d", i), PriorityNormal, 10, 0, 0, nil))
+	}
+	for i := 0; i < 6; i++ {
+		require.NoError(t, queue.EnqueueRequest("user-1", fmt.Sprintf("cheap-%d", i), PriorityNormal, 1, 0, 0, nil))
+	}
+
+	// Cheap requests are dequeued first, but expensive ones are not starved.
+	expected := []string{"cheap-0", "cheap-1", "cheap-2", "cheap-3", "expensive-0", "cheap-4", "cheap-5", "expensive-1"}
+	for _, exp := range expected {
+		req, _, err := queue.GetNextRequestForQuerier(ctx, FirstUser(), "querier-1")
+		require.NoError(t, err)
+		assert.Equal(t, exp, req)
+	}
+}
+
+func TestRequestQueue_Priority(t *testing.T) {
+	queue := NewRequestQueue(100, 10, 0,
+		prometheus.NewGaugeVec(prometheus.GaugeOpts{}, []string{"user"}),
+		prometheus.NewCounterVec(prometheus.CounterOpts{}, []string{"user"}))
+
+	ctx := context.Background()
+	require.NoError(t, services.StartAndAwaitRunning(ctx, queue))
+	t.Cleanup(func() {
+		require.NoError(t, services.StopAndAwaitTerminated(ctx, queue))
+	})
+
+	queue.RegisterQuerierConnection("querier-1")
+
+	require.NoError(t, queue.EnqueueRequest("user-1", "normal-0", PriorityNormal, 1, 0, 0, nil))
+	require.NoError(t, queue.EnqueueRequest("user-1", "high-expensive-0", PriorityHigh, 10, 0, 0, nil))
+	require.NoError(t, queue.EnqueueRequest("user-1", "normal-1", PriorityNormal, 1, 0, 0, nil))
+	require.NoError(t, queue.EnqueueRequest("user-1", "high-0", PriorityHigh, 1, 0, 0, nil))
+	require.Error(t, queue.EnqueueRequest("user-1", "invalid", Priority(100), 1, 0, 0, nil))
+
+	// High priority requests are dequeued first, each priority having its own cheap and expensive lanes.
+	expected := []string{"high-0", "high-expensive-0", "normal-0", "normal-1"}
+	for _, exp := range expected {
+		req, _, err := queue.GetNextRequestForQuerier(ctx, FirstUser(), "querier-1")
+		require.NoError(t, err)
+		assert.Equal(t, exp, req)
+	}
+}
+
+func TestRequestQueue_MaxInflightCost(t *testing.T) {
+	queue := NewRequestQueue(100, 0, 0,
+		prometheus.NewGaugeVec(prometheus.GaugeOpts{}, []string{"user"}),
+		prometheus.NewCounterVec(prometheus.CounterOpts{}, []string{"user"}))
+
+	ctx := context.Background()
+	require.NoError(t, services.StartAndAwaitRunning(ctx, queue))
+	t.Cleanup(func() {
+		require.NoError(t, services.StopAndAwaitTerminated(ctx, queue))
+	})
+
+	queue.RegisterQuerierConnection("querier-1")
+
+	const maxInflightCost = 10
+	require.NoError(t, queue.EnqueueRequest("user-1", "user-1-request-0", PriorityNormal, 15, 0, maxInflightCost, nil))
+	require.NoError(t, queue.EnqueueRequest("user-1", "user-1-request-1", PriorityNormal, 5, 0, maxInflightCost, nil))
+	require.NoError(t, queue.EnqueueRequest("user-2", "user-2-request-0", PriorityNormal, 5, 0, maxInflightCost, nil))
+
+	// A request exceeding the budget is dispatched if the user has nothing in-flight.
+	req, last, err := queue.GetNextRequestForQuerier(ctx, FirstUser(), "querier-1")
+	require.NoError(t, err)
+	assert.Equal(t, "user-1-request-0", req)
+
+	// The next user-1 request doesn't fit in the budget, so user-2 is served.
+	req, last, err = queue.GetNextRequestForQuerier(ctx, last, "querier-1")
+	require.NoError(t, err)
+	assert.Equal(t, "user-2-request-0", req)
+
+	// No more requests can be dispatched until the in-flight user-1 request completes.
+	timeoutCtx, cancel := context.WithTimeout(ctx, 100*time.Millisecond)
+	defer cancel()
+	_, _, err = queue.GetNextRequestForQuerier(timeoutCtx, last, "querier-1")
+	require.Equal(t, context.DeadlineExceeded, err)
+
+	queue.RequestCompleted("user-1", 15)
+
+	req, _, err = queue.GetNextRequestForQuerier(ctx, last, "querier-1")
+	require.NoError(t, err)
+	assert.Equal(t, "user-1-request-1", req)
+}
+
 func TestContextCond(t *testing.T) {
 	t.Run("wait until broadcast", func(t *testing.T) {
 		t.Parallel()
@@ -46,10 +46,33 @@ type queues struct {
 
 	// Sorted list of querier names, used when creating per-user shard.
 	sortedQueriers []string
+
+	// Total estimated cost of the requests dequeued but not completed yet, per user.
+	inflightCost map[string]int
+}
+
+// queuedRequest is a request waiting in a user queue, along with its estimated cost.
+type queuedRequest struct {
+	req  Request
+	cost int
+}
+
+// requestLanes holds the requests of a user with the same priority. Expensive requests are queued
+// in a dedicated lane, so that cheap requests are not stuck behind them.
+type requestLanes struct {
+	cheap     []queuedRequest
+	expensive []queuedRequest
+
+	// Number of cheap requests dequeued in a row while expensive requests were waiting.
+	cheapInARow int
 }
 
 type userQueue struct {
-	ch chan Request
+	// Requests waiting to be dispatched, indexed by priority.
+	lanes [numPriorities]requestLanes
+
+	// Max total cost of the requests the user can have in-flight at the same time (zero = unlimited).
+	maxInflightCost int
 
 	// If not nil, only these queriers can handle user requests. If nil, all queriers can.
 	// We set this to nil if number of available queriers <= maxQueriers.
@@ -72,6 +95,7 @@ func newUserQueues(maxUserQueueSize int, forgetDelay time.Duration) *queues {
 		forgetDelay:      forgetDelay,
 		queriers:         map[string]*querier{},
 		sortedQueriers:   nil,
+		inflightCost:     map[string]int{},
 	}
 }
 
@@ -98,7 +122,7 @@ func (q *queues) deleteQueue(userID string) {
 // MaxQueriers is used to compute which queriers should handle requests for this user.
 // If maxQueriers is <= 0, all queriers can handle this user's requests.
 // If maxQueriers has changed since the last call, queriers for this are recomputed.
-func (q *queues) getOrAddQueue(userID string, maxQueriers int) chan Request {
+func (q *queues) getOrAddQueue(userID string, maxQueriers int) *userQueue {
 	// Empty user is not allowed, as that would break our users list ("" is used for free spot).
 	if userID == "" {
 		return nil
@@ -112,7 +136,6 @@ func (q *queues) getOrAddQueue(userID string, maxQueriers int) chan Request {
 
 	if uq == nil {
 		uq = &userQueue{
-			ch:    make(chan Request, q.maxUserQueueSize),
 			seed:  util.ShuffleShardSeed(userID, ""),
 			index: -1,
 		}
@@ -139,13 +162,13 @@ func (q *queues) getOrAddQueue(userID string, maxQueriers int) chan Request {
 		uq.queriers = shuffleQueriersForUser(uq.seed, maxQueriers, q.sortedQueriers, nil)
 	}
 
-	return uq.ch
+	return uq
 }
 
 // Finds next queue for the querier. To support fair scheduling between users, client is expected
 // to pass last user index returned by this function as argument. Is there was no previous
 // last user index, use -1.
-func (q *queues) getNextQueueForQuerier(lastUserIndex int, querierID string) (chan Request, string, int) {
+func (q *queues) getNextQueueForQuerier(lastUserIndex int, querierID string) (*userQueue, string, int) {
 	uid := lastUserIndex
 
 	// Ensure the querier is not shutting down. If the querier is shutting down, we shouldn't forward
@@ -177,11 +200,30 @@ func (q *queues) getNextQueueForQuerier(lastUserIndex int, querierID string) (ch
 			}
 		}
 
-		return q.ch, u, uid
+		return q, u, uid
 	}
 	return nil, "", uid
 }
 
+// addInflightCost adds the cost of a request dequeued for the user to the in-flight cost.
+func (q *queues) addInflightCost(userID string, cost int) {
+	if cost > 0 {
+		q.inflightCost[userID] += cost
+	}
+}
+
+// releaseInflightCost removes the cost of a completed request from the user in-flight cost.
+func (q *queues) releaseInflightCost(userID string, cost int) {
+	if cost <= 0 {
+		return
+	}
+
+	q.inflightCost[userID] -= cost
+	if q.inflightCost[userID] <= 0 {
+		delete(q.inflightCost, userID)
+	}
+}
+
 func (q *queues) addQuerierConnection(querierID string) {
 	info := q.queriers[querierID]
 	if info != nil {
@@ -289,6 +331,89 @@ func (q *queues) recomputeUserQueriers() {
 	}
 }
 
+// len returns the number of requests in the user queue, across all lanes.
+func (uq *userQueue) len() int {
+	n := 0
+	for i := range uq.lanes {
+		n += uq.lanes[i].len()
+	}
+	return n
+}
+
+// enqueue adds the request to the cheap or the expensive lane of the given priority.
+func (uq *userQueue) enqueue(req queuedRequest, priority Priority, expensive bool) {
+	uq.lanes[priority].enqueue(req, expensive)
+}
+
+// dequeue takes the next request off the user queue, given the current cost of the user requests
+// in-flight. Requests with higher priority are dequeued first. Returns false if there are no
+// requests which fit in the user in-flight cost budget.
+func (uq *userQueue) dequeue(inflightCost int) (queuedRequest, bool) {
+	fits := func(cost int) bool {
+		// A request is always allowed if the user has no requests in-flight,
+		// otherwise requests bigger than the budget would never run.
+		return uq.maxInflightCost <= 0 || inflightCost == 0 || inflightCost+cost <= uq.maxInflightCost
+	}
+
+	for priority := numPriorities - 1; priority >= 0; priority-- {
+		if req, ok := uq.lanes[priority].dequeue(fits); ok {
+			return req, true
+		}
+	}
+
+	return queuedRequest{}, false
+}
+
+func (l *requestLanes) len() int {
+	return len(l.cheap) + len(l.expensive)
+}
+
+func (l *requestLanes) enqueue(req queuedRequest, expensive bool) {
+	if expensive {
+		l.expensive = append(l.expensive, req)
+	} else {
+		l.cheap = append(l.cheap, req)
+	}
+}
+
+// dequeue takes the next request whose cost fits, if any. Cheap requests are preferred, but once
+// cheapRequestsPerExpensiveRequest cheap requests have been dequeued while expensive ones were waiting,
+// the next expensive request is dequeued as soon as it fits, and cheap requests are held back until then.
+func (l *requestLanes) dequeue(fits func(cost int) bool) (queuedRequest, bool) {
+	switch {
+	case len(l.expensive) > 0 && l.cheapInARow >= cheapRequestsPerExpensiveRequest:
+		if !fits(l.expensive[0].cost) {
+			return queuedRequest{}, false
+		}
+		return l.dequeueExpensive(), true
+
+	case len(l.cheap) > 0 && fits(l.cheap[0].cost):
+		req := l.cheap[0]
+		l.cheap[0] = queuedRequest{}
+		l.cheap = l.cheap[1:]
+
+		if len(l.expensive) > 0 {
+			l.cheapInARow++
+		}
+		return req, true
+
+	case len(l.expensive) > 0 && fits(l.expensive[0].cost):
+		return l.dequeueExpensive(), true
+
+	default:
+		return queuedRequest{}, false
+	}
+}
+
+func (l *requestLanes) dequeueExpensive() queuedRequest {
+	req := l.expensive[0]
+	l.expensive[0] = queuedRequest{}
+	l.expensive = l.expensive[1:]
+	l.cheapInARow = 0
+
+	return req
+}
+
 // shuffleQueriersForUser returns nil if queriersToSelect is 0 or there are not enough queriers to select from.
 // In that case *all* queriers should be used.
 // Scratchpad is used for shuffling, to avoid new allocations. If nil, new slice is allocated.
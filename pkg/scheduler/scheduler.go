@@ -68,8 +68,10 @@ type Scheduler struct {
 	discardedRequests        *prometheus.CounterVec
 	connectedQuerierClients  prometheus.GaugeFunc
 	connectedFrontendClients prometheus.GaugeFunc
-	queueDuration            prometheus.Histogram
+	queueDuration            *prometheus.HistogramVec
+	priorityQueueLength      *prometheus.GaugeVec
 	inflightRequests         prometheus.Summary
+	queryCost                prometheus.Histogram
 }
 
 type requestKey struct {
@@ -87,14 +89,16 @@ type connectedFrontend struct {
 }
 
 type Config struct {
-	MaxOutstandingPerTenant int               `yaml:"max_outstanding_requests_per_tenant"`
-	QuerierForgetDelay      time.Duration     `yaml:"querier_forget_delay" category:"experimental"`
-	GRPCClientConfig        grpcclient.Config `yaml:"grpc_client_config" doc:"description=This configures the gRPC client used to report errors back to the query-frontend."`
+	MaxOutstandingPerTenant     int               `yaml:"max_outstanding_requests_per_tenant"`
+	QuerierForgetDelay          time.Duration     `yaml:"querier_forget_delay" category:"experimental"`
+	ExpensiveQueryCostThreshold int               `yaml:"expensive_query_cost_threshold" category:"experimental"`
+	GRPCClientConfig            grpcclient.Config `yaml:"grpc_client_config" doc:"description=This configures the gRPC client used to report errors back to the query-frontend."`
 }
 
 func (cfg *Config) RegisterFlags(f *flag.FlagSet) {
 	f.IntVar(&cfg.MaxOutstandingPerTenant, "query-scheduler.max-outstanding-requests-per-tenant", 100, "Maximum number of outstanding requests per tenant per query-scheduler. In-flight requests above this limit will fail with HTTP response status code 429.")
 	f.DurationVar(&cfg.QuerierForgetDelay, "query-scheduler.querier-forget-delay", 0, "If a querier disconnects without sending notification about graceful shutdown, the query-scheduler will keep the querier in the tenant's shard until the forget delay has passed. This feature is useful to reduce the blast radius when shuffle-sharding is enabled.")
+	f.IntVar(&cfg.ExpensiveQueryCostThreshold, "query-scheduler.expensive-query-cost-threshold", 0, "Queries with an estimated cost greater than or equal to this threshold are queued in a separate per-tenant lane, so that they don't delay cheaper queries. A cost unit is roughly a series selector querying one day of data. 0 to disable.")
 	cfg.GRPCClientConfig.RegisterFlagsWithPrefix("query-scheduler.grpc-client-config", f)
 }
 
@@ -118,12 +122,21 @@ func NewScheduler(cfg Config, limits Limits, log log.Logger, registerer promethe
 		Name: "cortex_query_scheduler_discarded_requests_total",
 		Help: "Total number of query requests discarded.",
 	}, []string{"user"})
-	s.requestQueue = queue.NewRequestQueue(cfg.MaxOutstandingPerTenant, cfg.QuerierForgetDelay, s.queueLength, s.discardedRequests)
+	s.requestQueue = queue.NewRequestQueue(cfg.MaxOutstandingPerTenant, cfg.ExpensiveQueryCostThreshold, cfg.QuerierForgetDelay, s.queueLength, s.discardedRequests)
 
-	s.queueDuration = promauto.With(registerer).NewHistogram(prometheus.HistogramOpts{
+	s.queueDuration = promauto.With(registerer).NewHistogramVec(prometheus.HistogramOpts{
 		Name:    "cortex_query_scheduler_queue_duration_seconds",
 		Help:    "Time spend by requests in queue before getting picked up by a querier.",
 		Buckets: prometheus.DefBuckets,
+	}, []string{"priority"})
+	s.priorityQueueLength = promauto.With(registerer).NewGaugeVec(prometheus.GaugeOpts{
+		Name: "cortex_query_scheduler_queue_length_by_priority",
+		Help: "Number of queries in the queue, by priority.",
+	}, []string{"priority"})
+	s.queryCost = promauto.With(registerer).NewHistogram(prometheus.HistogramOpts{
+		Name:    "cortex_query_scheduler_query_cost",
+		Help:    "Estimated cost of the queries enqueued in the query-scheduler, in cost units.",
+		Buckets: prometheus.ExponentialBuckets(1, 4, 7),
 	})
 	s.connectedQuerierClients = promauto.With(registerer).NewGaugeFunc(prometheus.GaugeOpts{
 		Name: "cortex_query_scheduler_connected_querier_clients",
@@ -158,6 +171,10 @@ func NewScheduler(cfg Config, limits Limits, log log.Logger, registerer promethe
 type Limits interface {
 	// MaxQueriersPerUser returns max queriers to use per tenant, or 0 if shuffle sharding is disabled.
 	MaxQueriersPerUser(user string) int
+
+	// MaxInflightQueryCost returns the max total estimated cost of the queries a tenant can have
+	// in-flight at the same time, or 0 if unlimited.
+	MaxInflightQueryCost(user string) int
 }
 
 type schedulerRequest struct {
@@ -167,6 +184,11 @@ type schedulerRequest struct {
 	request         *httpgrpc.HTTPRequest
 	statsEnabled    bool
 
+	// Estimated cost of the request, in cost units.
+	cost int
+
+	priority queue.Priority
+
 	enqueueTime time.Time
 
 	ctx       context.Context
@@ -305,6 +327,13 @@ func (s *Scheduler) enqueueRequest(frontendContext context.Context, frontendAddr
 		queryID:         msg.QueryID,
 		request:         msg.HttpRequest,
 		statsEnabled:    msg.StatsEnabled,
+		cost:            estimateQueryCost(msg.HttpRequest),
+		priority:        queue.Priority(msg.Priority),
+	}
+
+	// Requests with an unknown priority, eg. sent by a newer query-frontend, are handled with the normal priority.
+	if !req.priority.IsValid() {
+		req.priority = queue.PriorityNormal
 	}
 
 	now := time.Now()
@@ -320,10 +349,13 @@ func (s *Scheduler) enqueueRequest(frontendContext context.Context, frontendAddr
 		return err
 	}
 	maxQueriers := validation.SmallestPositiveNonZeroIntPerTenant(tenantIDs, s.limits.MaxQueriersPerUser)
+	maxInflightCost := validation.SmallestPositiveNonZeroIntPerTenant(tenantIDs, s.limits.MaxInflightQueryCost)
 
 	s.activeUsers.UpdateUserTimestamp(userID, now)
-	return s.requestQueue.EnqueueRequest(userID, req, maxQueriers, func() {
+	return s.requestQueue.EnqueueRequest(userID, req, req.priority, req.cost, maxQueriers, maxInflightCost, func() {
 		shouldCancel = false
+		s.queryCost.Observe(float64(req.cost))
+		s.priorityQueueLength.WithLabelValues(req.priority.String()).Inc()
 
 		s.pendingRequestsMu.Lock()
 		s.pendingRequests[requestKey{frontendAddr: frontendAddr, queryID: msg.QueryID}] = req
@@ -369,7 +401,8 @@ func (s *Scheduler) QuerierLoop(querier schedulerpb.SchedulerForQuerier_QuerierL
 
 		r := req.(*schedulerRequest)
 
-		s.queueDuration.Observe(time.Since(r.enqueueTime).Seconds())
+		s.queueDuration.WithLabelValues(r.priority.String()).Observe(time.Since(r.enqueueTime).Seconds())
+		s.priorityQueueLength.WithLabelValues(r.priority.String()).Dec()
 		r.queueSpan.Finish()
 
 		/*
@@ -387,12 +420,15 @@ func (s *Scheduler) QuerierLoop(querier schedulerpb.SchedulerForQuerier_QuerierL
 		if r.ctx.Err() != nil {
 			// Remove from pending requests.
 			s.cancelRequestAndRemoveFromPending(r.frontendAddress, r.queryID)
+			s.requestQueue.RequestCompleted(r.userID, r.cost)
 
 			lastUserIndex = lastUserIndex.ReuseLastUser()
 			continue
 		}
 
-		if err := s.forwardRequestToQuerier(querier, r); err != nil {
+		err = s.forwardRequestToQuerier(querier, r)
+		s.requestQueue.RequestCompleted(r.userID, r.cost)
+		if err != nil {
 			return err
 		}
 	}
@@ -421,6 +457,7 @@ func (s *Scheduler) forwardRequestToQuerier(querier schedulerpb.SchedulerForQuer
 			FrontendAddress: req.frontendAddress,
 			HttpRequest:     req.request,
 			StatsEnabled:    req.statsEnabled,
+			QueueTimeNanos:  time.Since(req.enqueueTime).Nanoseconds(),
 		})
 		if err != nil {
 			errCh <- err
@@ -29,6 +29,7 @@ import (
 	"google.golang.org/grpc/credentials/insecure"
 
 	"github.com/grafana/mimir/pkg/frontend/v2/frontendv2pb"
+	"github.com/grafana/mimir/pkg/scheduler/queue"
 	"github.com/grafana/mimir/pkg/scheduler/schedulerpb"
 	"github.com/grafana/mimir/pkg/util/httpgrpcutil"
 )
@@ -446,6 +447,47 @@ func TestSchedulerMetrics(t *testing.T) {
 	`), "cortex_query_scheduler_queue_length"))
 }
 
+func TestSchedulerDequeuesHighPriorityRequestsFirst(t *testing.T) {
+	reg := prometheus.NewPedanticRegistry()
+	scheduler, frontendClient, querierClient := setupScheduler(t, reg)
+
+	frontendLoop := initFrontendLoop(t, frontendClient, "frontend-12345")
+	frontendToScheduler(t, frontendLoop, &schedulerpb.FrontendToScheduler{
+		Type:        schedulerpb.ENQUEUE,
+		QueryID:     1,
+		UserID:      "test",
+		HttpRequest: &httpgrpc.HTTPRequest{Method: "GET", Url: "/normal"},
+	})
+	frontendToScheduler(t, frontendLoop, &schedulerpb.FrontendToScheduler{
+		Type:        schedulerpb.ENQUEUE,
+		QueryID:     2,
+		UserID:      "test",
+		HttpRequest: &httpgrpc.HTTPRequest{Method: "GET", Url: "/high"},
+		Priority:    int32(queue.PriorityHigh),
+	})
+
+	require.NoError(t, promtest.GatherAndCompare(reg, strings.NewReader(`
+		# HELP cortex_query_scheduler_queue_length_by_priority Number of queries in the queue, by priority.
+		# TYPE cortex_query_scheduler_queue_length_by_priority gauge
+		cortex_query_scheduler_queue_length_by_priority{priority="high"} 1
+		cortex_query_scheduler_queue_length_by_priority{priority="normal"} 1
+	`), "cortex_query_scheduler_queue_length_by_priority"))
+
+	querierLoop := initQuerierLoop(t, querierClient, "querier-1")
+	for _, expectedURL := range []string{"/high", "/normal"} {
+		msg, err := querierLoop.Recv()
+		require.NoError(t, err)
+		require.Equal(t, expectedURL, msg.HttpRequest.Url)
+		require.NoError(t, querierLoop.Send(&schedulerpb.QuerierToScheduler{}))
+	}
+
+	verifyNoPendingRequestsLeft(t, scheduler)
+
+	count, err := promtest.GatherAndCount(reg, "cortex_query_scheduler_queue_duration_seconds")
+	require.NoError(t, err)
+	require.Equal(t, 2, count)
+}
+
 func initFrontendLoop(t *testing.T, client schedulerpb.SchedulerForFrontendClient, frontendAddr string) schedulerpb.SchedulerForFrontend_FrontendLoopClient {
 	loop, err := client.FrontendLoop(context.Background())
 	require.NoError(t, err)
@@ -500,13 +542,18 @@ func verifyNoPendingRequestsLeft(t *testing.T, scheduler *Scheduler) {
 }
 
 type limits struct {
-	queriers int
+	queriers        int
+	maxInflightCost int
 }
 
 func (l limits) MaxQueriersPerUser(_ string) int {
 	return l.queriers
 }
 
+func (l limits) MaxInflightQueryCost(_ string) int {
+	return l.maxInflightCost
+}
+
 type frontendMock struct {
 	mu   sync.Mutex
 	resp map[uint64]*httpgrpc.HTTPResponse
@@ -139,6 +139,8 @@ type SchedulerToQuerier struct {
 	// Whether query statistics tracking should be enabled. The response will include
 	// statistics only when this option is enabled.
 	StatsEnabled bool `protobuf:"varint,5,opt,name=statsEnabled,proto3" json:"statsEnabled,omitempty"`
+	// How long the request waited in the queue before being dequeued by the querier, in nanoseconds.
+	QueueTimeNanos int64 `protobuf:"varint,6,opt,name=queueTimeNanos,proto3" json:"queueTimeNanos,omitempty"`
 }
 
 func (m *SchedulerToQuerier) Reset()      { *m = SchedulerToQuerier{} }
@@ -208,6 +210,13 @@ func (m *SchedulerToQuerier) GetStatsEnabled() bool {
 	return false
 }
 
+func (m *SchedulerToQuerier) GetQueueTimeNanos() int64 {
+	if m != nil {
+		return m.QueueTimeNanos
+	}
+	return 0
+}
+
 type FrontendToScheduler struct {
 	Type FrontendToSchedulerType `protobuf:"varint,1,opt,name=type,proto3,enum=schedulerpb.FrontendToSchedulerType" json:"type,omitempty"`
 	// Used by INIT message. Will be put into all requests passed to querier.
@@ -219,6 +228,9 @@ type FrontendToScheduler struct {
 	UserID       string                `protobuf:"bytes,4,opt,name=userID,proto3" json:"userID,omitempty"`
 	HttpRequest  *httpgrpc.HTTPRequest `protobuf:"bytes,5,opt,name=httpRequest,proto3" json:"httpRequest,omitempty"`
 	StatsEnabled bool                  `protobuf:"varint,6,opt,name=statsEnabled,proto3" json:"statsEnabled,omitempty"`
+	// Priority of the request within the tenant queue. Requests with a higher priority
+	// are dequeued first. Zero is the normal priority.
+	Priority int32 `protobuf:"varint,7,opt,name=priority,proto3" json:"priority,omitempty"`
 }
 
 func (m *FrontendToScheduler) Reset()      { *m = FrontendToScheduler{} }
@@ -295,6 +307,13 @@ func (m *FrontendToScheduler) GetStatsEnabled() bool {
 	return false
 }
 
+func (m *FrontendToScheduler) GetPriority() int32 {
+	if m != nil {
+		return m.Priority
+	}
+	return 0
+}
+
 type SchedulerToFrontend struct {
 	Status SchedulerToFrontendStatus `protobuf:"varint,1,opt,name=status,proto3,enum=schedulerpb.SchedulerToFrontendStatus" json:"status,omitempty"`
 	Error  string                    `protobuf:"bytes,2,opt,name=error,proto3" json:"error,omitempty"`
@@ -438,48 +457,50 @@ func init() {
 func init() { proto.RegisterFile("scheduler.proto", fileDescriptor_2b3fc28395a6d9c5) }
 
 var fileDescriptor_2b3fc28395a6d9c5 = []byte{
-	// 650 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x8c, 0x54, 0xcf, 0x4f, 0x13, 0x41,
-	0x14, 0xde, 0x29, 0x6d, 0x81, 0x57, 0x94, 0x75, 0x00, 0xad, 0x0d, 0x0e, 0x4d, 0x63, 0x4c, 0x25,
-	0xb1, 0x35, 0xd5, 0x44, 0x0f, 0xc4, 0xa4, 0xc2, 0x22, 0x8d, 0xb8, 0x85, 0xe9, 0x34, 0xfe, 0xb8,
-	0x34, 0xb4, 0x1d, 0x5a, 0x02, 0xec, 0x2c, 0xb3, 0xbb, 0x92, 0xde, 0x3c, 0x7a, 0xf4, 0xcf, 0xf0,
-	0x4f, 0xf1, 0x62, 0xc2, 0x91, 0x83, 0x07, 0x59, 0x2e, 0x1e, 0xf9, 0x13, 0x0c, 0xd3, 0x6d, 0xdd,
-	0xd6, 0x16, 0xb8, 0xbd, 0xf7, 0xf6, 0xfb, 0x76, 0xde, 0xf7, 0xbd, 0x37, 0x03, 0xb3, 0x4e, 0xa3,
-	0xcd, 0x9b, 0xde, 0x01, 0x97, 0x39, 0x5b, 0x0a, 0x57, 0xe0, 0x44, 0xbf, 0x60, 0xd7, 0x53, 0x4f,
-	0x5a, 0x7b, 0x6e, 0xdb, 0xab, 0xe7, 0x1a, 0xe2, 0x30, 0xdf, 0x12, 0x2d, 0x91, 0x57, 0x98, 0xba,
-	0xb7, 0xab, 0x32, 0x95, 0xa8, 0xa8, 0xcb, 0x4d, 0x3d, 0x0f, 0xc1, 0x8f, 0xf9, 0xce, 0x67, 0x7e,
-	0x2c, 0xe4, 0xbe, 0x93, 0x6f, 0x88, 0xc3, 0x43, 0x61, 0xe5, 0xdb, 0xae, 0x6b, 0xb7, 0xa4, 0xdd,
-	0xe8, 0x07, 0x5d, 0x56, 0xa6, 0x00, 0x78, 0xdb, 0xe3, 0x72, 0x8f, 0x4b, 0x26, 0x2a, 0xbd, 0xc3,
-	0xf1, 0x22, 0x4c, 0x1f, 0x75, 0xab, 0xa5, 0xb5, 0x24, 0x4a, 0xa3, 0xec, 0x34, 0xfd, 0x57, 0xc8,
-	0xfc, 0x44, 0x80, 0xfb, 0x58, 0x26, 0x02, 0x3e, 0x4e, 0xc2, 0xe4, 0x25, 0xa6, 0x13, 0x50, 0xa2,
-	0xb4, 0x97, 0xe2, 0x17, 0x90, 0xb8, 0x3c, 0x96, 0xf2, 0x23, 0x8f, 0x3b, 0x6e, 0x32, 0x92, 0x46,
-	0xd9, 0x44, 0x61, 0x21, 0xd7, 0x6f, 0x65, 0x83, 0xb1, 0xad, 0xe0, 0x23, 0x0d, 0x23, 0x71, 0x16,
-	0x66, 0x77, 0xa5, 0xb0, 0x5c, 0x6e, 0x35, 0x8b, 0xcd, 0xa6, 0xe4, 0x8e, 0x93, 0x9c, 0x50, 0xdd,
-	0x0c, 0x97, 0xf1, 0x5d, 0x88, 0x7b, 0x8e, 0x6a, 0x37, 0xaa, 0x00, 0x41, 0x86, 0x33, 0x30, 0xe3,
-	0xb8, 0x3b, 0xae, 0x63, 0x58, 0x3b, 0xf5, 0x03, 0xde, 0x4c, 0xc6, 0xd2, 0x28, 0x3b, 0x45, 0x07,
-	0x6a, 0x99, 0xaf, 0x11, 0x98, 0x5b, 0x0f, 0xfe, 0x17, 0x76, 0xe1, 0x25, 0x44, 0xdd, 0x8e, 0xcd,
-	0x95, 0x9a, 0xdb, 0x85, 0x87, 0xb9, 0xd0, 0x70, 0x72, 0x23, 0xf0, 0xac, 0x63, 0x73, 0xaa, 0x18,
-	0xa3, 0xfa, 0x8e, 0x8c, 0xee, 0x3b, 0x64, 0xda, 0xc4, 0xa0, 0x69, 0xe3, 0x14, 0x0d, 0x99, 0x19,
-	0xbb, 0xb1, 0x99, 0xc3, 0x56, 0xc4, 0x47, 0x58, 0xb1, 0x0f, 0x73, 0xa1, 0xc9, 0xf6, 0x44, 0xe2,
-	0x57, 0x10, 0xbf, 0x84, 0x79, 0x4e, 0xe0, 0xc5, 0xa3, 0x01, 0x2f, 0x46, 0x30, 0x2a, 0x0a, 0x4d,
-	0x03, 0x16, 0x9e, 0x87, 0x18, 0x97, 0x52, 0xc8, 0xc0, 0x85, 0x6e, 0x92, 0x59, 0x81, 0x45, 0x53,
-	0xb8, 0x7b, 0xbb, 0x9d, 0x60, 0x83, 0x2a, 0x6d, 0xcf, 0x6d, 0x8a, 0x63, 0xab, 0xd7, 0xf0, 0xd5,
-	0x5b, 0xb8, 0x04, 0x0f, 0xc6, 0xb0, 0x1d, 0x5b, 0x58, 0x0e, 0x5f, 0x5e, 0x81, 0x7b, 0x63, 0xa6,
-	0x84, 0xa7, 0x20, 0x5a, 0x32, 0x4b, 0x4c, 0xd7, 0x70, 0x02, 0x26, 0x0d, 0x73, 0xbb, 0x6a, 0x54,
-	0x0d, 0x1d, 0x61, 0x80, 0xf8, 0x6a, 0xd1, 0x5c, 0x35, 0x36, 0xf5, 0xc8, 0x72, 0x03, 0xee, 0x8f,
-	0xd5, 0x85, 0xe3, 0x10, 0x29, 0xbf, 0xd5, 0x35, 0x9c, 0x86, 0x45, 0x56, 0x2e, 0xd7, 0xde, 0x15,
-	0xcd, 0x8f, 0x35, 0x6a, 0x6c, 0x57, 0x8d, 0x0a, 0xab, 0xd4, 0xb6, 0x0c, 0x5a, 0x63, 0x86, 0x59,
-	0x34, 0x99, 0x8e, 0xf0, 0x34, 0xc4, 0x0c, 0x4a, 0xcb, 0x54, 0x8f, 0xe0, 0x3b, 0x70, 0xab, 0xb2,
-	0x51, 0x65, 0xac, 0x64, 0xbe, 0xa9, 0xad, 0x95, 0xdf, 0x9b, 0xfa, 0x44, 0xe1, 0x17, 0x0a, 0xf9,
-	0xbd, 0x2e, 0x64, 0xef, 0x2a, 0x55, 0x21, 0x11, 0x84, 0x9b, 0x42, 0xd8, 0x78, 0x69, 0xc0, 0xee,
-	0xff, 0xef, 0x6b, 0x6a, 0x69, 0xdc, 0x3c, 0x02, 0x6c, 0x46, 0xcb, 0xa2, 0xa7, 0x08, 0x5b, 0xb0,
-	0x30, 0xd2, 0x32, 0xfc, 0x78, 0x80, 0x7f, 0xd5, 0x50, 0x52, 0xcb, 0x37, 0x81, 0x76, 0x27, 0x50,
-	0xb0, 0x61, 0x3e, 0xac, 0xae, 0xbf, 0x4e, 0x1f, 0x60, 0xa6, 0x17, 0x2b, 0x7d, 0xe9, 0xeb, 0xae,
-	0x56, 0x2a, 0x7d, 0xdd, 0xc2, 0x75, 0x15, 0xbe, 0x2e, 0x9e, 0x9c, 0x11, 0xed, 0xf4, 0x8c, 0x68,
-	0x17, 0x67, 0x04, 0x7d, 0xf1, 0x09, 0xfa, 0xee, 0x13, 0xf4, 0xc3, 0x27, 0xe8, 0xc4, 0x27, 0xe8,
-	0xb7, 0x4f, 0xd0, 0x1f, 0x9f, 0x68, 0x17, 0x3e, 0x41, 0xdf, 0xce, 0x89, 0x76, 0x72, 0x4e, 0xb4,
-	0xd3, 0x73, 0xa2, 0x7d, 0x0a, 0x3f, 0xbb, 0xf5, 0xb8, 0x7a, 0x18, 0x9f, 0xfd, 0x0d, 0x00, 0x00,
-	0xff, 0xff, 0x49, 0x11, 0xf9, 0x34, 0x9d, 0x05, 0x00, 0x00,
+	// 687 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x8c, 0x54, 0x4d, 0x4f, 0xdb, 0x4a,
+	0x14, 0xf5, 0xe4, 0x0b, 0xb8, 0xe1, 0x81, 0xdf, 0x00, 0xef, 0xb9, 0x11, 0x35, 0x96, 0x55, 0x21,
+	0x17, 0xa9, 0x49, 0x95, 0x56, 0x6a, 0x17, 0xa8, 0x52, 0x0a, 0xa6, 0x44, 0xa5, 0x0e, 0x4c, 0x1c,
+	0xf5, 0x63, 0x13, 0xe5, 0x63, 0x48, 0x22, 0x88, 0xc7, 0x8c, 0xed, 0xa2, 0xec, 0xfa, 0x13, 0xfa,
+	0x23, 0xba, 0xe8, 0x4f, 0xe9, 0x92, 0x25, 0x8b, 0x2e, 0x8a, 0xd9, 0x74, 0xc9, 0xa6, 0xfb, 0x0a,
+	0xc7, 0x49, 0x9d, 0x34, 0x01, 0x76, 0xf7, 0x5e, 0x9f, 0xe3, 0x99, 0x73, 0xee, 0xbd, 0x03, 0x8b,
+	0x4e, 0xa3, 0x4d, 0x9b, 0xde, 0x31, 0xe5, 0x59, 0x9b, 0x33, 0x97, 0xe1, 0xf4, 0xb0, 0x60, 0xd7,
+	0x33, 0x8f, 0x5a, 0x1d, 0xb7, 0xed, 0xd5, 0xb3, 0x0d, 0xd6, 0xcd, 0xb5, 0x58, 0x8b, 0xe5, 0x02,
+	0x4c, 0xdd, 0x3b, 0x0c, 0xb2, 0x20, 0x09, 0xa2, 0x3e, 0x37, 0xf3, 0x34, 0x02, 0x3f, 0xa5, 0xb5,
+	0x8f, 0xf4, 0x94, 0xf1, 0x23, 0x27, 0xd7, 0x60, 0xdd, 0x2e, 0xb3, 0x72, 0x6d, 0xd7, 0xb5, 0x5b,
+	0xdc, 0x6e, 0x0c, 0x83, 0x3e, 0x4b, 0xcd, 0x03, 0x3e, 0xf0, 0x28, 0xef, 0x50, 0x6e, 0xb2, 0xf2,
+	0xe0, 0x70, 0xbc, 0x0a, 0x73, 0x27, 0xfd, 0x6a, 0x71, 0x5b, 0x42, 0x0a, 0xd2, 0xe6, 0xc8, 0x9f,
+	0x82, 0xfa, 0x0b, 0x01, 0x1e, 0x62, 0x4d, 0x16, 0xf2, 0xb1, 0x04, 0x33, 0xd7, 0x98, 0x5e, 0x48,
+	0x49, 0x90, 0x41, 0x8a, 0x9f, 0x41, 0xfa, 0xfa, 0x58, 0x42, 0x4f, 0x3c, 0xea, 0xb8, 0x52, 0x4c,
+	0x41, 0x5a, 0x3a, 0xbf, 0x92, 0x1d, 0x5e, 0x65, 0xd7, 0x34, 0xf7, 0xc3, 0x8f, 0x24, 0x8a, 0xc4,
+	0x1a, 0x2c, 0x1e, 0x72, 0x66, 0xb9, 0xd4, 0x6a, 0x16, 0x9a, 0x4d, 0x4e, 0x1d, 0x47, 0x8a, 0x07,
+	0xb7, 0x19, 0x2f, 0xe3, 0xff, 0x20, 0xe5, 0x39, 0xc1, 0x75, 0x13, 0x01, 0x20, 0xcc, 0xb0, 0x0a,
+	0xf3, 0x8e, 0x5b, 0x73, 0x1d, 0xdd, 0xaa, 0xd5, 0x8f, 0x69, 0x53, 0x4a, 0x2a, 0x48, 0x9b, 0x25,
+	0x23, 0x35, 0xbc, 0x0e, 0x0b, 0x27, 0x1e, 0xf5, 0xa8, 0xd9, 0xe9, 0x52, 0xa3, 0x66, 0x31, 0x47,
+	0x4a, 0x29, 0x48, 0x8b, 0x93, 0xb1, 0xaa, 0xfa, 0x25, 0x06, 0x4b, 0x3b, 0xe1, 0xb9, 0x51, 0xb7,
+	0x9e, 0x43, 0xc2, 0xed, 0xd9, 0x34, 0x50, 0xbd, 0x90, 0x7f, 0x90, 0x8d, 0x34, 0x31, 0x3b, 0x01,
+	0x6f, 0xf6, 0x6c, 0x4a, 0x02, 0xc6, 0x24, 0x7d, 0xb1, 0xc9, 0xfa, 0x22, 0xe6, 0xc6, 0x47, 0xcd,
+	0x9d, 0xa6, 0x7c, 0xcc, 0xf4, 0xe4, 0x9d, 0x4d, 0x1f, 0xb7, 0x2c, 0x35, 0xc1, 0xb2, 0x0c, 0xcc,
+	0xda, 0xbc, 0xc3, 0x78, 0xc7, 0xed, 0x49, 0x33, 0x0a, 0xd2, 0x92, 0x64, 0x98, 0xab, 0x47, 0xb0,
+	0x14, 0x99, 0x8e, 0x81, 0x01, 0xf8, 0x05, 0xa4, 0xae, 0x7f, 0xe1, 0x39, 0xa1, 0x4f, 0xeb, 0x23,
+	0x3e, 0x4d, 0x60, 0x94, 0x03, 0x34, 0x09, 0x59, 0x78, 0x19, 0x92, 0x94, 0x73, 0xc6, 0x43, 0x87,
+	0xfa, 0x89, 0xba, 0x09, 0xab, 0x06, 0x73, 0x3b, 0x87, 0xbd, 0x70, 0x0a, 0xcb, 0x6d, 0xcf, 0x6d,
+	0xb2, 0x53, 0x6b, 0x20, 0xe6, 0xe6, 0x49, 0x5e, 0x83, 0xfb, 0x53, 0xd8, 0x8e, 0xcd, 0x2c, 0x87,
+	0x6e, 0x6c, 0xc2, 0xff, 0x53, 0x3a, 0x88, 0x67, 0x21, 0x51, 0x34, 0x8a, 0xa6, 0x28, 0xe0, 0x34,
+	0xcc, 0xe8, 0xc6, 0x41, 0x45, 0xaf, 0xe8, 0x22, 0xc2, 0x00, 0xa9, 0xad, 0x82, 0xb1, 0xa5, 0xef,
+	0x89, 0xb1, 0x8d, 0x06, 0xdc, 0x9b, 0xaa, 0x0b, 0xa7, 0x20, 0x56, 0x7a, 0x2d, 0x0a, 0x58, 0x81,
+	0x55, 0xb3, 0x54, 0xaa, 0xbe, 0x29, 0x18, 0xef, 0xab, 0x44, 0x3f, 0xa8, 0xe8, 0x65, 0xb3, 0x5c,
+	0xdd, 0xd7, 0x49, 0xd5, 0xd4, 0x8d, 0x82, 0x61, 0x8a, 0x08, 0xcf, 0x41, 0x52, 0x27, 0xa4, 0x44,
+	0xc4, 0x18, 0xfe, 0x17, 0xfe, 0x29, 0xef, 0x56, 0x4c, 0xb3, 0x68, 0xbc, 0xaa, 0x6e, 0x97, 0xde,
+	0x1a, 0x62, 0x3c, 0xff, 0x1d, 0x45, 0xfc, 0xde, 0x61, 0x7c, 0xb0, 0x8e, 0x15, 0x48, 0x87, 0xe1,
+	0x1e, 0x63, 0x36, 0x5e, 0x1b, 0xb1, 0xfb, 0xef, 0x9d, 0xcf, 0xac, 0x4d, 0xeb, 0x47, 0x88, 0x55,
+	0x05, 0x0d, 0x3d, 0x46, 0xd8, 0x82, 0x95, 0x89, 0x96, 0xe1, 0x87, 0x23, 0xfc, 0x9b, 0x9a, 0x92,
+	0xd9, 0xb8, 0x0b, 0xb4, 0xdf, 0x81, 0xbc, 0x0d, 0xcb, 0x51, 0x75, 0xc3, 0x71, 0x7a, 0x07, 0xf3,
+	0x83, 0x38, 0xd0, 0xa7, 0xdc, 0xb6, 0x76, 0x19, 0xe5, 0xb6, 0x81, 0xeb, 0x2b, 0x7c, 0x59, 0x38,
+	0xbb, 0x90, 0x85, 0xf3, 0x0b, 0x59, 0xb8, 0xba, 0x90, 0xd1, 0x27, 0x5f, 0x46, 0x5f, 0x7d, 0x19,
+	0x7d, 0xf3, 0x65, 0x74, 0xe6, 0xcb, 0xe8, 0x87, 0x2f, 0xa3, 0x9f, 0xbe, 0x2c, 0x5c, 0xf9, 0x32,
+	0xfa, 0x7c, 0x29, 0x0b, 0x67, 0x97, 0xb2, 0x70, 0x7e, 0x29, 0x0b, 0x1f, 0xa2, 0x4f, 0x77, 0x3d,
+	0x15, 0x3c, 0xae, 0x4f, 0x7e, 0x0f, 0x00, 0xd9, 0x25, 0x93, 0x97, 0xe1, 0x05, 0x00, 0x00,
 }
 
 func (x FrontendToSchedulerType) String() string {
@@ -554,6 +575,9 @@ func (this *SchedulerToQuerier) Equal(that interface{}) bool {
 	if this.StatsEnabled != that1.StatsEnabled {
 		return false
 	}
+	if this.QueueTimeNanos != that1.QueueTimeNanos {
+		return false
+	}
 	return true
 }
 func (this *FrontendToScheduler) Equal(that interface{}) bool {
@@ -593,6 +617,9 @@ func (this *FrontendToScheduler) Equal(that interface{}) bool {
 	if this.StatsEnabled != that1.StatsEnabled {
 		return false
 	}
+	if this.Priority != that1.Priority {
+		return false
+	}
 	return true
 }
 func (this *SchedulerToFrontend) Equal(that interface{}) bool {
@@ -681,7 +708,7 @@ func (this *SchedulerToQuerier) GoString() string {
 	if this == nil {
 		return "nil"
 	}
-	s := make([]string, 0, 9)
+	s := make([]string, 0, 10)
 	s = append(s, "&schedulerpb.SchedulerToQuerier{")
 	s = append(s, "QueryID: "+fmt.Sprintf("%#v", this.QueryID)+",\n")
 	if this.HttpRequest != nil {
@@ -690,6 +717,7 @@ func (this *SchedulerToQuerier) GoString() string {
 	s = append(s, "FrontendAddress: "+fmt.Sprintf("%#v", this.FrontendAddress)+",\n")
 	s = append(s, "UserID: "+fmt.Sprintf("%#v", this.UserID)+",\n")
 	s = append(s, "StatsEnabled: "+fmt.Sprintf("%#v", this.StatsEnabled)+",\n")
+	s = append(s, "QueueTimeNanos: "+fmt.Sprintf("%#v", this.QueueTimeNanos)+",\n")
 	s = append(s, "}")
 	return strings.Join(s, "")
 }
@@ -697,7 +725,7 @@ func (this *FrontendToScheduler) GoString() string {
 	if this == nil {
 		return "nil"
 	}
-	s := make([]string, 0, 10)
+	s := make([]string, 0, 11)
 	s = append(s, "&schedulerpb.FrontendToScheduler{")
 	s = append(s, "Type: "+fmt.Sprintf("%#v", this.Type)+",\n")
 	s = append(s, "FrontendAddress: "+fmt.Sprintf("%#v", this.FrontendAddress)+",\n")
@@ -707,6 +735,7 @@ func (this *FrontendToScheduler) GoString() string {
 		s = append(s, "HttpRequest: "+fmt.Sprintf("%#v", this.HttpRequest)+",\n")
 	}
 	s = append(s, "StatsEnabled: "+fmt.Sprintf("%#v", this.StatsEnabled)+",\n")
+	s = append(s, "Priority: "+fmt.Sprintf("%#v", this.Priority)+",\n")
 	s = append(s, "}")
 	return strings.Join(s, "")
 }
@@ -1078,6 +1107,11 @@ func (m *SchedulerToQuerier) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 	_ = i
 	var l int
 	_ = l
+	if m.QueueTimeNanos != 0 {
+		i = encodeVarintScheduler(dAtA, i, uint64(m.QueueTimeNanos))
+		i--
+		dAtA[i] = 0x30
+	}
 	if m.StatsEnabled {
 		i--
 		if m.StatsEnabled {
@@ -1142,6 +1176,11 @@ func (m *FrontendToScheduler) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 	_ = i
 	var l int
 	_ = l
+	if m.Priority != 0 {
+		i = encodeVarintScheduler(dAtA, i, uint64(m.Priority))
+		i--
+		dAtA[i] = 0x38
+	}
 	if m.StatsEnabled {
 		i--
 		if m.StatsEnabled {
@@ -1327,6 +1366,9 @@ func (m *SchedulerToQuerier) Size() (n int) {
 	if m.StatsEnabled {
 		n += 2
 	}
+	if m.QueueTimeNanos != 0 {
+		n += 1 + sovScheduler(uint64(m.QueueTimeNanos))
+	}
 	return n
 }
 
@@ -1357,6 +1399,9 @@ func (m *FrontendToScheduler) Size() (n int) {
 	if m.StatsEnabled {
 		n += 2
 	}
+	if m.Priority != 0 {
+		n += 1 + sovScheduler(uint64(m.Priority))
+	}
 	return n
 }
 
@@ -1424,6 +1469,7 @@ func (this *SchedulerToQuerier) String() string {
 		`FrontendAddress:` + fmt.Sprintf("%v", this.FrontendAddress) + `,`,
 		`UserID:` + fmt.Sprintf("%v", this.UserID) + `,`,
 		`StatsEnabled:` + fmt.Sprintf("%v", this.StatsEnabled) + `,`,
+		`QueueTimeNanos:` + fmt.Sprintf("%v", this.QueueTimeNanos) + `,`,
 		`}`,
 	}, "")
 	return s
@@ -1439,6 +1485,7 @@ func (this *FrontendToScheduler) String() string {
 		`UserID:` + fmt.Sprintf("%v", this.UserID) + `,`,
 		`HttpRequest:` + strings.Replace(fmt.Sprintf("%v", this.HttpRequest), "HTTPRequest", "httpgrpc.HTTPRequest", 1) + `,`,
 		`StatsEnabled:` + fmt.Sprintf("%v", this.StatsEnabled) + `,`,
+		`Priority:` + fmt.Sprintf("%v", this.Priority) + `,`,
 		`}`,
 	}, "")
 	return s
@@ -1734,6 +1781,25 @@ func (m *SchedulerToQuerier) Unmarshal(dAtA []byte) error {
 				}
 			}
 			m.StatsEnabled = bool(v != 0)
+		case 6:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field QueueTimeNanos", wireType)
+			}
+			m.QueueTimeNanos = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowScheduler
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.QueueTimeNanos |= int64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
 		default:
 			iNdEx = preIndex
 			skippy, err := skipScheduler(dAtA[iNdEx:])
@@ -1945,6 +2011,25 @@ func (m *FrontendToScheduler) Unmarshal(dAtA []byte) error {
 				}
 			}
 			m.StatsEnabled = bool(v != 0)
+		case 7:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Priority", wireType)
+			}
+			m.Priority = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowScheduler
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Priority |= int32(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
 		default:
 			iNdEx = preIndex
 			skippy, err := skipScheduler(dAtA[iNdEx:])
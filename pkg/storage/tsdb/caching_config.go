@@ -24,8 +24,11 @@ import (
 
 	"github.com/grafana/mimir/pkg/cache"
 	"github.com/grafana/mimir/pkg/storage/tsdb/bucketcache"
+	"github.com/grafana/mimir/pkg/storage/tsdb/exemplars"
 )
 
+var supportedCacheBackends = []string{cache.BackendMemcached, cache.BackendRedis}
+
 type ChunksCacheConfig struct {
 	cache.BackendConfig `yaml:",inline"`
 
@@ -37,9 +40,10 @@ type ChunksCacheConfig struct {
 }
 
 func (cfg *ChunksCacheConfig) RegisterFlagsWithPrefix(f *flag.FlagSet, prefix string) {
-	f.StringVar(&cfg.Backend, prefix+"backend", "", fmt.Sprintf("Backend for chunks cache, if not empty. Supported values: %s.", cache.BackendMemcached))
+	f.StringVar(&cfg.Backend, prefix+"backend", "", fmt.Sprintf("Backend for chunks cache, if not empty. Supported values: %s.", strings.Join(supportedCacheBackends, ", ")))
 
 	cfg.Memcached.RegisterFlagsWithPrefix(f, prefix+"memcached.")
+	cfg.Redis.RegisterFlagsWithPrefix(f, prefix+"redis.")
 
 	f.Int64Var(&cfg.SubrangeSize, prefix+"subrange-size", 16000, "Size of each subrange that bucket object is split into for better caching.")
 	f.IntVar(&cfg.MaxGetRangeRequests, prefix+"max-get-range-requests", 3, "Maximum number of sub-GetRange requests that a single GetRange request can be split into when fetching chunks. Zero or negative value = unlimited number of sub-requests.")
@@ -66,12 +70,15 @@ type MetadataCacheConfig struct {
 	BlockIndexAttributesTTL time.Duration `yaml:"block_index_attributes_ttl" category:"advanced"`
 	BucketIndexContentTTL   time.Duration `yaml:"bucket_index_content_ttl" category:"advanced"`
 	BucketIndexMaxSize      int           `yaml:"bucket_index_max_size_bytes" category:"advanced"`
+	BlockExemplarsTTL       time.Duration `yaml:"block_exemplars_ttl" category:"experimental"`
+	BlockExemplarsMaxSize   int           `yaml:"block_exemplars_max_size_bytes" category:"experimental"`
 }
 
 func (cfg *MetadataCacheConfig) RegisterFlagsWithPrefix(f *flag.FlagSet, prefix string) {
-	f.StringVar(&cfg.Backend, prefix+"backend", "", fmt.Sprintf("Backend for metadata cache, if not empty. Supported values: %s.", cache.BackendMemcached))
+	f.StringVar(&cfg.Backend, prefix+"backend", "", fmt.Sprintf("Backend for metadata cache, if not empty. Supported values: %s.", strings.Join(supportedCacheBackends, ", ")))
 
 	cfg.Memcached.RegisterFlagsWithPrefix(f, prefix+"memcached.")
+	cfg.Redis.RegisterFlagsWithPrefix(f, prefix+"redis.")
 
 	f.DurationVar(&cfg.TenantsListTTL, prefix+"tenants-list-ttl", 15*time.Minute, "How long to cache list of tenants in the bucket.")
 	f.DurationVar(&cfg.TenantBlocksListTTL, prefix+"tenant-blocks-list-ttl", 5*time.Minute, "How long to cache list of blocks for each tenant.")
@@ -84,6 +91,8 @@ func (cfg *MetadataCacheConfig) RegisterFlagsWithPrefix(f *flag.FlagSet, prefix
 	f.DurationVar(&cfg.BlockIndexAttributesTTL, prefix+"block-index-attributes-ttl", 168*time.Hour, "How long to cache attributes of the block index.")
 	f.DurationVar(&cfg.BucketIndexContentTTL, prefix+"bucket-index-content-ttl", 5*time.Minute, "How long to cache content of the bucket index.")
 	f.IntVar(&cfg.BucketIndexMaxSize, prefix+"bucket-index-max-size-bytes", 1*1024*1024, "Maximum size of bucket index content to cache in bytes. Caching will be skipped if the content exceeds this size. This is useful to avoid network round trip for large content if the configured caching backend has an hard limit on cached items size (in this case, you should set this limit to the same limit in the caching backend).")
+	f.DurationVar(&cfg.BlockExemplarsTTL, prefix+"block-exemplars-ttl", 24*time.Hour, "How long to cache the exemplars uploaded alongside the blocks.")
+	f.IntVar(&cfg.BlockExemplarsMaxSize, prefix+"block-exemplars-max-size-bytes", 1*1024*1024, "Maximum size of the compressed exemplars of a block to cache in bytes. Caching will be skipped if the content exceeds this size.")
 }
 
 func (cfg *MetadataCacheConfig) Validate() error {
@@ -112,6 +121,7 @@ func CreateCachingBucket(chunksConfig ChunksCacheConfig, metadataConfig Metadata
 		cfg.CacheAttributes("metafile", metadataCache, isMetaFile, metadataConfig.MetafileAttributesTTL)
 		cfg.CacheAttributes("block-index", metadataCache, isBlockIndexFile, metadataConfig.BlockIndexAttributesTTL)
 		cfg.CacheGet("bucket-index", metadataCache, isBucketIndexFile, metadataConfig.BucketIndexMaxSize, metadataConfig.BucketIndexContentTTL /* do not cache exist / not exist: */, 0, 0)
+		cfg.CacheGet("block-exemplars", metadataCache, isBlockExemplarsFile, metadataConfig.BlockExemplarsMaxSize, metadataConfig.BlockExemplarsTTL, metadataConfig.BlockExemplarsTTL, metadataConfig.MetafileDoesntExistTTL)
 
 		codec := snappyIterCodec{bucketcache.JSONIterCodec{}}
 		cfg.CacheIter("tenants-iter", metadataCache, isTenantsDir, metadataConfig.TenantsListTTL, codec)
@@ -166,6 +176,16 @@ func isBlockIndexFile(name string) bool {
 	return err == nil
 }
 
+func isBlockExemplarsFile(name string) bool {
+	// Ensure the path ends with "<block id>/<exemplars filename>".
+	if !strings.HasSuffix(name, "/"+exemplars.CompressedFilename) {
+		return false
+	}
+
+	_, err := ulid.Parse(filepath.Base(filepath.Dir(name)))
+	return err == nil
+}
+
 func isBucketIndexFile(name string) bool {
 	// TODO can't reference bucketindex because of a circular dependency. To be fixed.
 	return strings.HasSuffix(name, "/bucket-index.json.gz")
@@ -39,3 +39,14 @@ func TestIsBlockIndexFile(t *testing.T) {
 	assert.True(t, isBlockIndexFile(fmt.Sprintf("%s/index", blockID.String())))
 	assert.True(t, isBlockIndexFile(fmt.Sprintf("/%s/index", blockID.String())))
 }
+
+func TestIsBlockExemplarsFile(t *testing.T) {
+	blockID := ulid.MustNew(1, nil)
+
+	assert.False(t, isBlockExemplarsFile(""))
+	assert.False(t, isBlockExemplarsFile("/exemplars.json.gz"))
+	assert.False(t, isBlockExemplarsFile("test/exemplars.json.gz"))
+	assert.False(t, isBlockExemplarsFile(fmt.Sprintf("%s/index", blockID.String())))
+	assert.True(t, isBlockExemplarsFile(fmt.Sprintf("%s/exemplars.json.gz", blockID.String())))
+	assert.True(t, isBlockExemplarsFile(fmt.Sprintf("user/%s/exemplars.json.gz", blockID.String())))
+}
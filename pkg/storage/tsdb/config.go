@@ -302,6 +302,10 @@ type BucketStoreConfig struct {
 
 	// Controls experimental options for index-header file reading.
 	IndexHeader indexheader.BinaryReaderConfig `yaml:"index_header" category:"experimental"`
+
+	// Series deletion.
+	SeriesDeletionEnabled  bool          `yaml:"series_deletion_enabled" category:"experimental"`
+	SeriesDeletionCacheTTL time.Duration `yaml:"series_deletion_cache_ttl" category:"experimental"`
 }
 
 // RegisterFlags registers the BucketStore flags
@@ -330,6 +334,15 @@ func (cfg *BucketStoreConfig) RegisterFlags(f *flag.FlagSet) {
 	f.BoolVar(&cfg.IndexHeaderLazyLoadingEnabled, "blocks-storage.bucket-store.index-header-lazy-loading-enabled", true, "If enabled, store-gateway will lazy load an index-header only once required by a query.")
 	f.DurationVar(&cfg.IndexHeaderLazyLoadingIdleTimeout, "blocks-storage.bucket-store.index-header-lazy-loading-idle-timeout", 60*time.Minute, "If index-header lazy loading is enabled and this setting is > 0, the store-gateway will offload unused index-headers after 'idle timeout' inactivity.")
 	f.Uint64Var(&cfg.PartitionerMaxGapBytes, "blocks-storage.bucket-store.partitioner-max-gap-bytes", DefaultPartitionerMaxGapSize, "Max size - in bytes - of a gap for which the partitioner aggregates together two bucket GET object requests.")
+	f.BoolVar(&cfg.SeriesDeletionEnabled, "blocks-storage.bucket-store.series-deletion-enabled", false, "True to remove the data matching the tenants' series deletion requests from the series returned by the store-gateway.")
+	f.DurationVar(&cfg.SeriesDeletionCacheTTL, "blocks-storage.bucket-store.series-deletion-cache-ttl", time.Minute, "How long to cache the tenant's series deletion requests before reloading them from the storage.")
+}
+
+// ProcessedTombstonesRetention returns for how long the processed series deletion requests should still be
+// applied at query time. The blocks replaced by the rewritten ones are filtered out once they've been marked
+// for deletion for the ignore deletion marks delay, which is noticed within the bucket index max stale period.
+func (cfg *BucketStoreConfig) ProcessedTombstonesRetention() time.Duration {
+	return cfg.IgnoreDeletionMarksDelay + cfg.BucketIndex.MaxStalePeriod
 }
 
 // Validate the config.
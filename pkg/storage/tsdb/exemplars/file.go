@@ -0,0 +1,212 @@
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package exemplars
+
+import (
+	"math"
+	"sort"
+
+	"github.com/prometheus/common/model"
+	"github.com/prometheus/prometheus/model/exemplar"
+	"github.com/prometheus/prometheus/model/labels"
+)
+
+const (
+	FileVersion1 = 1
+)
+
+// File holds the exemplars of a block, grouped by series.
+type File struct {
+	Version int `json:"version"`
+
+	// Series is the list of series with exemplars, sorted by labels.
+	Series []Series `json:"series"`
+}
+
+// Series holds the exemplars of a single series.
+type Series struct {
+	Labels labels.Labels `json:"labels"`
+
+	// Exemplars is the list of exemplars of the series, sorted by timestamp.
+	Exemplars []Exemplar `json:"exemplars"`
+}
+
+// Exemplar is a single exemplar.
+type Exemplar struct {
+	Labels labels.Labels `json:"labels"`
+
+	// Value is stored as model.SampleValue to support special float values in JSON.
+	Value model.SampleValue `json:"value"`
+
+	// Timestamp is a unix timestamp (milliseconds precision).
+	Timestamp int64 `json:"timestamp"`
+}
+
+type exemplarKey struct {
+	timestamp int64
+	value     uint64
+	labels    string
+}
+
+func (e Exemplar) key() exemplarKey {
+	return exemplarKey{
+		timestamp: e.Timestamp,
+		value:     math.Float64bits(float64(e.Value)),
+		labels:    e.Labels.String(),
+	}
+}
+
+// NewFile returns a file holding the exemplars of the input query results.
+func NewFile(results []exemplar.QueryResult) *File {
+	b := newFileBuilder()
+	for _, res := range results {
+		for _, e := range res.Exemplars {
+			b.add(res.SeriesLabels, Exemplar{
+				Labels:    e.Labels,
+				Value:     model.SampleValue(e.Value),
+				Timestamp: e.Ts,
+			})
+		}
+	}
+	return b.build()
+}
+
+// Merge returns a new file with the union of the exemplars of the input files. The same
+// exemplar found in multiple files is included only once.
+func Merge(files ...*File) *File {
+	b := newFileBuilder()
+	for _, f := range files {
+		if f == nil {
+			continue
+		}
+
+		for _, s := range f.Series {
+			for _, e := range s.Exemplars {
+				b.add(s.Labels, e)
+			}
+		}
+	}
+	return b.build()
+}
+
+// Filter returns a new file with the exemplars in the [mint, maxt] time range, belonging to
+// series for which the keep function returns true. A nil keep function keeps all series.
+func (f *File) Filter(mint, maxt int64, keep func(labels.Labels) bool) *File {
+	res := &File{Version: FileVersion1}
+
+	for _, s := range f.Series {
+		if keep != nil && !keep(s.Labels) {
+			continue
+		}
+
+		var exemplars []Exemplar
+		for _, e := range s.Exemplars {
+			if e.Timestamp >= mint && e.Timestamp <= maxt {
+				exemplars = append(exemplars, e)
+			}
+		}
+
+		if len(exemplars) > 0 {
+			res.Series = append(res.Series, Series{Labels: s.Labels, Exemplars: exemplars})
+		}
+	}
+
+	return res
+}
+
+// Select returns the exemplars in the [mint, maxt] time range, belonging to series matching
+// at least one of the input matcher sets.
+func (f *File) Select(mint, maxt int64, matchers ...[]*labels.Matcher) []exemplar.QueryResult {
+	filtered := f.Filter(mint, maxt, func(lbls labels.Labels) bool {
+		for _, set := range matchers {
+			if matchesAll(lbls, set) {
+				return true
+			}
+		}
+		return false
+	})
+
+	res := make([]exemplar.QueryResult, 0, len(filtered.Series))
+	for _, s := range filtered.Series {
+		r := exemplar.QueryResult{
+			SeriesLabels: s.Labels,
+			Exemplars:    make([]exemplar.Exemplar, 0, len(s.Exemplars)),
+		}
+		for _, e := range s.Exemplars {
+			r.Exemplars = append(r.Exemplars, exemplar.Exemplar{
+				Labels: e.Labels,
+				Value:  float64(e.Value),
+				Ts:     e.Timestamp,
+			})
+		}
+		res = append(res, r)
+	}
+	return res
+}
+
+// NumExemplars returns the total number of exemplars in the file.
+func (f *File) NumExemplars() int {
+	n := 0
+	for _, s := range f.Series {
+		n += len(s.Exemplars)
+	}
+	return n
+}
+
+func matchesAll(lbls labels.Labels, matchers []*labels.Matcher) bool {
+	for _, m := range matchers {
+		if !m.Matches(lbls.Get(m.Name)) {
+			return false
+		}
+	}
+	return true
+}
+
+type fileBuilder struct {
+	series map[string]*Series
+	seen   map[string]map[exemplarKey]struct{}
+}
+
+func newFileBuilder() *fileBuilder {
+	return &fileBuilder{
+		series: map[string]*Series{},
+		seen:   map[string]map[exemplarKey]struct{}{},
+	}
+}
+
+func (b *fileBuilder) add(lbls labels.Labels, e Exemplar) {
+	key := lbls.String()
+
+	s, ok := b.series[key]
+	if !ok {
+		s = &Series{Labels: lbls}
+		b.series[key] = s
+		b.seen[key] = map[exemplarKey]struct{}{}
+	}
+
+	if _, ok := b.seen[key][e.key()]; ok {
+		return
+	}
+	b.seen[key][e.key()] = struct{}{}
+	s.Exemplars = append(s.Exemplars, e)
+}
+
+func (b *fileBuilder) build() *File {
+	f := &File{
+		Version: FileVersion1,
+		Series:  make([]Series, 0, len(b.series)),
+	}
+
+	for _, s := range b.series {
+		sort.SliceStable(s.Exemplars, func(i, j int) bool {
+			return s.Exemplars[i].Timestamp < s.Exemplars[j].Timestamp
+		})
+		f.Series = append(f.Series, *s)
+	}
+
+	sort.Slice(f.Series, func(i, j int) bool {
+		return labels.Compare(f.Series[i].Labels, f.Series[j].Labels) < 0
+	})
+
+	return f
+}
@@ -0,0 +1,203 @@
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package exemplars
+
+import (
+	"testing"
+
+	"github.com/prometheus/prometheus/model/exemplar"
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewFile(t *testing.T) {
+	actual := NewFile([]exemplar.QueryResult{
+		{
+			SeriesLabels: labels.FromStrings("__name__", "b"),
+			Exemplars: []exemplar.Exemplar{
+				{Labels: labels.FromStrings("trace_id", "2"), Value: 2, Ts: 20},
+				{Labels: labels.FromStrings("trace_id", "1"), Value: 1, Ts: 10},
+			},
+		}, {
+			SeriesLabels: labels.FromStrings("__name__", "a"),
+			Exemplars: []exemplar.Exemplar{
+				{Labels: labels.FromStrings("trace_id", "3"), Value: 3, Ts: 30},
+			},
+		},
+	})
+
+	assert.Equal(t, &File{
+		Version: FileVersion1,
+		Series: []Series{
+			{
+				Labels: labels.FromStrings("__name__", "a"),
+				Exemplars: []Exemplar{
+					{Labels: labels.FromStrings("trace_id", "3"), Value: 3, Timestamp: 30},
+				},
+			}, {
+				Labels: labels.FromStrings("__name__", "b"),
+				Exemplars: []Exemplar{
+					{Labels: labels.FromStrings("trace_id", "1"), Value: 1, Timestamp: 10},
+					{Labels: labels.FromStrings("trace_id", "2"), Value: 2, Timestamp: 20},
+				},
+			},
+		},
+	}, actual)
+}
+
+func TestMerge(t *testing.T) {
+	first := &File{
+		Version: FileVersion1,
+		Series: []Series{{
+			Labels: labels.FromStrings("__name__", "a"),
+			Exemplars: []Exemplar{
+				{Labels: labels.FromStrings("trace_id", "1"), Value: 1, Timestamp: 10},
+				{Labels: labels.FromStrings("trace_id", "3"), Value: 3, Timestamp: 30},
+			},
+		}},
+	}
+	second := &File{
+		Version: FileVersion1,
+		Series: []Series{{
+			Labels: labels.FromStrings("__name__", "a"),
+			Exemplars: []Exemplar{
+				{Labels: labels.FromStrings("trace_id", "2"), Value: 2, Timestamp: 20},
+				{Labels: labels.FromStrings("trace_id", "3"), Value: 3, Timestamp: 30},
+			},
+		}, {
+			Labels: labels.FromStrings("__name__", "b"),
+			Exemplars: []Exemplar{
+				{Labels: labels.FromStrings("trace_id", "4"), Value: 4, Timestamp: 40},
+			},
+		}},
+	}
+
+	assert.Equal(t, &File{
+		Version: FileVersion1,
+		Series: []Series{{
+			Labels: labels.FromStrings("__name__", "a"),
+			Exemplars: []Exemplar{
+				{Labels: labels.FromStrings("trace_id", "1"), Value: 1, Timestamp: 10},
+				{Labels: labels.FromStrings("trace_id", "2"), Value: 2, Timestamp: 20},
+				{Labels: labels.FromStrings("trace_id", "3"), Value: 3, Timestamp: 30},
+			},
+		}, {
+			Labels: labels.FromStrings("__name__", "b"),
+			Exemplars: []Exemplar{
+				{Labels: labels.FromStrings("trace_id", "4"), Value: 4, Timestamp: 40},
+			},
+		}},
+	}, Merge(first, nil, second))
+}
+
+func TestFile_Filter(t *testing.T) {
+	f := &File{
+		Version: FileVersion1,
+		Series: []Series{{
+			Labels: labels.FromStrings("__name__", "a"),
+			Exemplars: []Exemplar{
+				{Labels: labels.FromStrings("trace_id", "1"), Value: 1, Timestamp: 10},
+				{Labels: labels.FromStrings("trace_id", "2"), Value: 2, Timestamp: 20},
+			},
+		}, {
+			Labels: labels.FromStrings("__name__", "b"),
+			Exemplars: []Exemplar{
+				{Labels: labels.FromStrings("trace_id", "3"), Value: 3, Timestamp: 30},
+			},
+		}, {
+			Labels: labels.FromStrings("__name__", "c"),
+			Exemplars: []Exemplar{
+				{Labels: labels.FromStrings("trace_id", "4"), Value: 4, Timestamp: 20},
+			},
+		}},
+	}
+
+	actual := f.Filter(15, 30, func(lbls labels.Labels) bool {
+		return lbls.Get("__name__") != "c"
+	})
+
+	assert.Equal(t, &File{
+		Version: FileVersion1,
+		Series: []Series{{
+			Labels: labels.FromStrings("__name__", "a"),
+			Exemplars: []Exemplar{
+				{Labels: labels.FromStrings("trace_id", "2"), Value: 2, Timestamp: 20},
+			},
+		}, {
+			Labels: labels.FromStrings("__name__", "b"),
+			Exemplars: []Exemplar{
+				{Labels: labels.FromStrings("trace_id", "3"), Value: 3, Timestamp: 30},
+			},
+		}},
+	}, actual)
+	assert.Equal(t, 2, actual.NumExemplars())
+}
+
+func TestFile_Select(t *testing.T) {
+	f := &File{
+		Version: FileVersion1,
+		Series: []Series{{
+			Labels: labels.FromStrings("__name__", "a", "job", "x"),
+			Exemplars: []Exemplar{
+				{Labels: labels.FromStrings("trace_id", "1"), Value: 1, Timestamp: 10},
+				{Labels: labels.FromStrings("trace_id", "2"), Value: 2, Timestamp: 20},
+			},
+		}, {
+			Labels: labels.FromStrings("__name__", "a", "job", "y"),
+			Exemplars: []Exemplar{
+				{Labels: labels.FromStrings("trace_id", "3"), Value: 3, Timestamp: 30},
+			},
+		}, {
+			Labels: labels.FromStrings("__name__", "b", "job", "z"),
+			Exemplars: []Exemplar{
+				{Labels: labels.FromStrings("trace_id", "4"), Value: 4, Timestamp: 20},
+			},
+		}},
+	}
+
+	tests := map[string]struct {
+		mint, maxt int64
+		matchers   [][]*labels.Matcher
+		expected   []exemplar.QueryResult
+	}{
+		"should return no exemplars if no matchers are provided": {
+			mint:     0,
+			maxt:     100,
+			expected: []exemplar.QueryResult{},
+		},
+		"should return the exemplars of the series matching the matchers, in the time range": {
+			mint: 15,
+			maxt: 100,
+			matchers: [][]*labels.Matcher{{
+				labels.MustNewMatcher(labels.MatchEqual, "__name__", "a"),
+				labels.MustNewMatcher(labels.MatchNotEqual, "job", "y"),
+			}},
+			expected: []exemplar.QueryResult{{
+				SeriesLabels: labels.FromStrings("__name__", "a", "job", "x"),
+				Exemplars: []exemplar.Exemplar{
+					{Labels: labels.FromStrings("trace_id", "2"), Value: 2, Ts: 20},
+				},
+			}},
+		},
+		"should return the exemplars of the series matching any of the matcher sets": {
+			mint: 0,
+			maxt: 25,
+			matchers: [][]*labels.Matcher{
+				{labels.MustNewMatcher(labels.MatchEqual, "job", "y")},
+				{labels.MustNewMatcher(labels.MatchEqual, "__name__", "b")},
+			},
+			expected: []exemplar.QueryResult{{
+				SeriesLabels: labels.FromStrings("__name__", "b", "job", "z"),
+				Exemplars: []exemplar.Exemplar{
+					{Labels: labels.FromStrings("trace_id", "4"), Value: 4, Ts: 20},
+				},
+			}},
+		},
+	}
+
+	for testName, testData := range tests {
+		t.Run(testName, func(t *testing.T) {
+			assert.Equal(t, testData.expected, f.Select(testData.mint, testData.maxt, testData.matchers...))
+		})
+	}
+}
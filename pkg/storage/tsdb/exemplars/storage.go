@@ -0,0 +1,112 @@
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package exemplars
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+
+	"github.com/go-kit/log"
+	"github.com/grafana/dskit/runutil"
+	"github.com/oklog/ulid"
+	"github.com/pkg/errors"
+	"github.com/thanos-io/thanos/pkg/objstore"
+)
+
+const (
+	// Filename is the name of the file holding the exemplars of a block, in the block's directory.
+	Filename = "exemplars.json"
+
+	// CompressedFilename is the name of the compressed file holding the exemplars of a block.
+	CompressedFilename = Filename + ".gz"
+)
+
+var ErrFileCorrupted = errors.New("exemplars file corrupted")
+
+// Path returns the path of the exemplars file of the block, in the tenant's bucket.
+func Path(blockID ulid.ULID) string {
+	return path.Join(blockID.String(), CompressedFilename)
+}
+
+// Upload uploads the exemplars of the block to the tenant's bucket. The file is uploaded inside
+// the block's directory, so that it gets deleted together with the block.
+func Upload(ctx context.Context, userBkt objstore.Bucket, blockID ulid.ULID, f *File) error {
+	content, err := json.Marshal(f)
+	if err != nil {
+		return errors.Wrap(err, "marshal exemplars")
+	}
+
+	var gzipContent bytes.Buffer
+	gzip := gzip.NewWriter(&gzipContent)
+	gzip.Name = Filename
+
+	if _, err := gzip.Write(content); err != nil {
+		return errors.Wrap(err, "gzip exemplars")
+	}
+	if err := gzip.Close(); err != nil {
+		return errors.Wrap(err, "close gzip exemplars")
+	}
+
+	return errors.Wrap(userBkt.Upload(ctx, Path(blockID), &gzipContent), "upload exemplars")
+}
+
+// Read reads the exemplars of the block from the tenant's bucket. It returns a nil file and
+// no error if the block has no exemplars.
+func Read(ctx context.Context, userBkt objstore.InstrumentedBucketReader, blockID ulid.ULID, logger log.Logger) (*File, error) {
+	reader, err := userBkt.ReaderWithExpectedErrs(userBkt.IsObjNotFoundErr).Get(ctx, Path(blockID))
+	if userBkt.IsObjNotFoundErr(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, "read exemplars of block %s", blockID.String())
+	}
+	defer runutil.CloseWithLogOnErr(logger, reader, "close exemplars reader")
+
+	gzipReader, err := gzip.NewReader(reader)
+	if err != nil {
+		return nil, ErrFileCorrupted
+	}
+	defer runutil.CloseWithLogOnErr(logger, gzipReader, "close exemplars gzip reader")
+
+	return decodeFile(gzipReader)
+}
+
+// ReadFromDir reads the exemplars from the compressed file in the given local block directory.
+// It returns a nil file and no error if the block has no exemplars.
+func ReadFromDir(blockDir string, logger log.Logger) (*File, error) {
+	fpath := filepath.Join(blockDir, CompressedFilename)
+
+	reader, err := os.Open(fpath)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, "open %s", fpath)
+	}
+	defer runutil.CloseWithLogOnErr(logger, reader, "close exemplars file")
+
+	gzipReader, err := gzip.NewReader(reader)
+	if err != nil {
+		return nil, ErrFileCorrupted
+	}
+	defer runutil.CloseWithLogOnErr(logger, gzipReader, "close exemplars gzip reader")
+
+	return decodeFile(gzipReader)
+}
+
+func decodeFile(r io.Reader) (*File, error) {
+	f := &File{}
+	if err := json.NewDecoder(r).Decode(f); err != nil {
+		return nil, ErrFileCorrupted
+	}
+	if f.Version != FileVersion1 {
+		return nil, ErrFileCorrupted
+	}
+	return f, nil
+}
@@ -0,0 +1,89 @@
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package exemplars
+
+import (
+	"context"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/go-kit/log"
+	"github.com/oklog/ulid"
+	"github.com/prometheus/common/model"
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/mimir/pkg/storage/bucket"
+	mimir_testutil "github.com/grafana/mimir/pkg/storage/tsdb/testutil"
+)
+
+func TestRead_ShouldReturnNilIfFileDoesNotExist(t *testing.T) {
+	bkt, _ := mimir_testutil.PrepareFilesystemBucket(t)
+	userBkt := bucket.NewUserBucketClient("user-1", bkt, nil)
+
+	f, err := Read(context.Background(), userBkt, ulid.MustNew(1, nil), log.NewNopLogger())
+	require.NoError(t, err)
+	require.Nil(t, f)
+}
+
+func TestRead_ShouldReturnErrorIfFileIsCorrupted(t *testing.T) {
+	ctx := context.Background()
+	bkt, _ := mimir_testutil.PrepareFilesystemBucket(t)
+	userBkt := bucket.NewUserBucketClient("user-1", bkt, nil)
+	blockID := ulid.MustNew(1, nil)
+
+	require.NoError(t, userBkt.Upload(ctx, Path(blockID), strings.NewReader("invalid!}")))
+
+	f, err := Read(ctx, userBkt, blockID, log.NewNopLogger())
+	require.Equal(t, ErrFileCorrupted, err)
+	require.Nil(t, f)
+}
+
+func TestUpload_ShouldBeReadByReadAndReadFromDir(t *testing.T) {
+	ctx := context.Background()
+	bkt, bktDir := mimir_testutil.PrepareFilesystemBucket(t)
+	userBkt := bucket.NewUserBucketClient("user-1", bkt, nil)
+	blockID := ulid.MustNew(1, nil)
+
+	expected := &File{
+		Version: FileVersion1,
+		Series: []Series{{
+			Labels: labels.FromStrings("__name__", "a"),
+			Exemplars: []Exemplar{
+				{Labels: labels.FromStrings("trace_id", "1"), Value: 1, Timestamp: 10},
+				{Labels: labels.FromStrings("trace_id", "2"), Value: model.SampleValue(math.Inf(1)), Timestamp: 20},
+			},
+		}},
+	}
+
+	require.NoError(t, Upload(ctx, userBkt, blockID, expected))
+
+	actual, err := Read(ctx, userBkt, blockID, log.NewNopLogger())
+	require.NoError(t, err)
+	assert.Equal(t, expected, actual)
+
+	// The file is stored inside the block directory, so it can be read from a downloaded block too.
+	blockDir := filepath.Join(bktDir, "user-1", blockID.String())
+	actual, err = ReadFromDir(blockDir, log.NewNopLogger())
+	require.NoError(t, err)
+	assert.Equal(t, expected, actual)
+}
+
+func TestReadFromDir_ShouldReturnNilIfFileDoesNotExist(t *testing.T) {
+	f, err := ReadFromDir(t.TempDir(), log.NewNopLogger())
+	require.NoError(t, err)
+	require.Nil(t, f)
+}
+
+func TestReadFromDir_ShouldReturnErrorIfFileIsCorrupted(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, CompressedFilename), []byte("invalid!}"), 0o666))
+
+	f, err := ReadFromDir(dir, log.NewNopLogger())
+	require.Equal(t, ErrFileCorrupted, err)
+	require.Nil(t, f)
+}
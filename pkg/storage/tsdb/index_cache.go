@@ -29,6 +29,9 @@ const (
 	// IndexCacheBackendMemcached is the value for the memcached index cache backend.
 	IndexCacheBackendMemcached = cache.BackendMemcached
 
+	// IndexCacheBackendRedis is the value for the redis index cache backend.
+	IndexCacheBackendRedis = cache.BackendRedis
+
 	// IndexCacheBackendDefault is the value for the default index cache backend.
 	IndexCacheBackendDefault = IndexCacheBackendInMemory
 
@@ -36,7 +39,7 @@ const (
 )
 
 var (
-	supportedIndexCacheBackends = []string{IndexCacheBackendInMemory, IndexCacheBackendMemcached}
+	supportedIndexCacheBackends = []string{IndexCacheBackendInMemory, IndexCacheBackendMemcached, IndexCacheBackendRedis}
 
 	errUnsupportedIndexCacheBackend = errors.New("unsupported index cache backend")
 )
@@ -55,6 +58,7 @@ func (cfg *IndexCacheConfig) RegisterFlagsWithPrefix(f *flag.FlagSet, prefix str
 
 	cfg.InMemory.RegisterFlagsWithPrefix(f, prefix+"inmemory.")
 	cfg.Memcached.RegisterFlagsWithPrefix(f, prefix+"memcached.")
+	cfg.Redis.RegisterFlagsWithPrefix(f, prefix+"redis.")
 }
 
 // Validate the config.
@@ -69,6 +73,12 @@ func (cfg *IndexCacheConfig) Validate() error {
 		}
 	}
 
+	if cfg.Backend == IndexCacheBackendRedis {
+		if err := cfg.Redis.Validate(); err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
@@ -87,6 +97,8 @@ func NewIndexCache(cfg IndexCacheConfig, logger log.Logger, registerer prometheu
 		return newInMemoryIndexCache(cfg.InMemory, logger, registerer)
 	case IndexCacheBackendMemcached:
 		return newMemcachedIndexCache(cfg.Memcached, logger, registerer)
+	case IndexCacheBackendRedis:
+		return newRedisIndexCache(cfg.Redis, logger, registerer)
 	default:
 		return nil, errUnsupportedIndexCacheBackend
 	}
@@ -113,10 +125,24 @@ func newMemcachedIndexCache(cfg cache.MemcachedConfig, logger log.Logger, regist
 		return nil, errors.Wrap(err, "create index cache memcached client")
 	}
 
-	cache, err := indexcache.NewMemcachedIndexCache(logger, client, registerer)
+	cache, err := indexcache.NewRemoteIndexCache(logger, client, registerer)
 	if err != nil {
 		return nil, errors.Wrap(err, "create memcached-based index cache")
 	}
 
 	return indexcache.NewTracingIndexCache(cache, logger), nil
 }
+
+func newRedisIndexCache(cfg cache.RedisConfig, logger log.Logger, registerer prometheus.Registerer) (indexcache.IndexCache, error) {
+	client, err := cache.NewRedisClient(logger, "index-cache", cfg, registerer)
+	if err != nil {
+		return nil, errors.Wrap(err, "create index cache redis client")
+	}
+
+	cache, err := indexcache.NewRemoteIndexCache(logger, client, registerer)
+	if err != nil {
+		return nil, errors.Wrap(err, "create redis-based index cache")
+	}
+
+	return indexcache.NewTracingIndexCache(cache, logger), nil
+}
@@ -51,6 +51,24 @@ func TestIndexCacheConfig_Validate(t *testing.T) {
 				},
 			},
 		},
+		"no redis endpoint should fail": {
+			cfg: IndexCacheConfig{
+				BackendConfig: cache.BackendConfig{
+					Backend: IndexCacheBackendRedis,
+				},
+			},
+			expected: cache.ErrNoRedisEndpoints,
+		},
+		"one redis endpoint should pass": {
+			cfg: IndexCacheConfig{
+				BackendConfig: cache.BackendConfig{Backend: IndexCacheBackendRedis,
+					Redis: cache.RedisConfig{
+						Endpoint:            "localhost:6379",
+						MaxAsyncConcurrency: 1,
+					},
+				},
+			},
+		},
 	}
 
 	for testName, testData := range tests {
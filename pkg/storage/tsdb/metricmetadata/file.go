@@ -0,0 +1,144 @@
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package metricmetadata
+
+import (
+	"sort"
+	"time"
+
+	"github.com/grafana/mimir/pkg/mimirpb"
+	"github.com/grafana/mimir/pkg/util"
+)
+
+const (
+	FileVersion1 = 1
+)
+
+// File holds a set of metric metadata of a tenant, each one with the last time it has been seen.
+// It's used both for the metadata uploaded by each ingester and for the per-tenant index
+// merging them, which is maintained by the compactor.
+type File struct {
+	Version int `json:"version"`
+
+	// UpdatedAt is a unix timestamp (seconds precision) of when the file has been updated.
+	UpdatedAt int64 `json:"updated_at"`
+
+	// Metadata is the list of metric metadata, sorted by metric family name.
+	Metadata []Entry `json:"metadata"`
+}
+
+// Entry is a metric metadata, with the last time it has been seen.
+type Entry struct {
+	MetricFamilyName string                            `json:"metric_family_name"`
+	Type             mimirpb.MetricMetadata_MetricType `json:"type"`
+	Help             string                            `json:"help,omitempty"`
+	Unit             string                            `json:"unit,omitempty"`
+
+	// LastSeen is a unix timestamp (milliseconds precision) of the last time the metadata has been received.
+	LastSeen int64 `json:"last_seen"`
+}
+
+// MetricMetadata returns the metric metadata of the entry, without its last seen time.
+func (e Entry) MetricMetadata() mimirpb.MetricMetadata {
+	return mimirpb.MetricMetadata{
+		MetricFamilyName: e.MetricFamilyName,
+		Type:             e.Type,
+		Help:             e.Help,
+		Unit:             e.Unit,
+	}
+}
+
+// NewFile returns a file holding the input metadata, keyed by the time they've been last seen.
+func NewFile(metadata map[mimirpb.MetricMetadata]time.Time, updatedAt time.Time) *File {
+	lastSeen := make(map[mimirpb.MetricMetadata]int64, len(metadata))
+	for m, t := range metadata {
+		lastSeen[m] = util.TimeToMillis(t)
+	}
+
+	return newFile(lastSeen, updatedAt)
+}
+
+func newFile(lastSeen map[mimirpb.MetricMetadata]int64, updatedAt time.Time) *File {
+	f := &File{
+		Version:   FileVersion1,
+		UpdatedAt: updatedAt.Unix(),
+		Metadata:  make([]Entry, 0, len(lastSeen)),
+	}
+
+	for m, t := range lastSeen {
+		f.Metadata = append(f.Metadata, Entry{
+			MetricFamilyName: m.MetricFamilyName,
+			Type:             m.Type,
+			Help:             m.Help,
+			Unit:             m.Unit,
+			LastSeen:         t,
+		})
+	}
+
+	sort.Slice(f.Metadata, func(i, j int) bool {
+		a, b := f.Metadata[i], f.Metadata[j]
+		if a.MetricFamilyName != b.MetricFamilyName {
+			return a.MetricFamilyName < b.MetricFamilyName
+		}
+		if a.Type != b.Type {
+			return a.Type < b.Type
+		}
+		if a.Help != b.Help {
+			return a.Help < b.Help
+		}
+		return a.Unit < b.Unit
+	})
+
+	return f
+}
+
+// Merge returns a new file with the union of the metadata of the input files. The last seen
+// time of the metadata found in multiple files is the most recent one.
+func Merge(updatedAt time.Time, files ...*File) *File {
+	lastSeen := map[mimirpb.MetricMetadata]int64{}
+	for _, f := range files {
+		if f == nil {
+			continue
+		}
+
+		for _, e := range f.Metadata {
+			m := e.MetricMetadata()
+			if t, ok := lastSeen[m]; !ok || e.LastSeen > t {
+				lastSeen[m] = e.LastSeen
+			}
+		}
+	}
+
+	return newFile(lastSeen, updatedAt)
+}
+
+// Purge removes the metadata last seen before the deadline, and returns the number of removed metadata.
+func (f *File) Purge(deadline time.Time) int {
+	deadlineMs := util.TimeToMillis(deadline)
+
+	kept := f.Metadata[:0]
+	for _, e := range f.Metadata {
+		if e.LastSeen >= deadlineMs {
+			kept = append(kept, e)
+		}
+	}
+
+	removed := len(f.Metadata) - len(kept)
+	f.Metadata = kept
+	return removed
+}
+
+// MetricMetadata returns the metadata in the file, without their last seen time.
+func (f *File) MetricMetadata() []*mimirpb.MetricMetadata {
+	res := make([]*mimirpb.MetricMetadata, 0, len(f.Metadata))
+	for _, e := range f.Metadata {
+		m := e.MetricMetadata()
+		res = append(res, &m)
+	}
+	return res
+}
+
+// GetUpdatedAt returns the time the file has been updated.
+func (f *File) GetUpdatedAt() time.Time {
+	return time.Unix(f.UpdatedAt, 0)
+}
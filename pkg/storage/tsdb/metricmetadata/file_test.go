@@ -0,0 +1,74 @@
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package metricmetadata
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/grafana/mimir/pkg/mimirpb"
+)
+
+func TestNewFile(t *testing.T) {
+	now := time.Unix(1000, 0)
+
+	f := NewFile(map[mimirpb.MetricMetadata]time.Time{
+		{MetricFamilyName: "b", Type: mimirpb.GAUGE, Help: "b help"}:                  now,
+		{MetricFamilyName: "a", Type: mimirpb.COUNTER, Help: "a help", Unit: "bytes"}: now.Add(-time.Minute),
+		{MetricFamilyName: "a", Type: mimirpb.COUNTER, Help: "a other help"}:          now,
+	}, now)
+
+	assert.Equal(t, &File{
+		Version:   FileVersion1,
+		UpdatedAt: 1000,
+		Metadata: []Entry{
+			{MetricFamilyName: "a", Type: mimirpb.COUNTER, Help: "a help", Unit: "bytes", LastSeen: 940000},
+			{MetricFamilyName: "a", Type: mimirpb.COUNTER, Help: "a other help", LastSeen: 1000000},
+			{MetricFamilyName: "b", Type: mimirpb.GAUGE, Help: "b help", LastSeen: 1000000},
+		},
+	}, f)
+
+	assert.Equal(t, []*mimirpb.MetricMetadata{
+		{MetricFamilyName: "a", Type: mimirpb.COUNTER, Help: "a help", Unit: "bytes"},
+		{MetricFamilyName: "a", Type: mimirpb.COUNTER, Help: "a other help"},
+		{MetricFamilyName: "b", Type: mimirpb.GAUGE, Help: "b help"},
+	}, f.MetricMetadata())
+}
+
+func TestMerge(t *testing.T) {
+	first := &File{Version: FileVersion1, UpdatedAt: 10, Metadata: []Entry{
+		{MetricFamilyName: "a", Type: mimirpb.COUNTER, Help: "a help", LastSeen: 1000},
+		{MetricFamilyName: "b", Type: mimirpb.GAUGE, Help: "b help", LastSeen: 3000},
+	}}
+	second := &File{Version: FileVersion1, UpdatedAt: 20, Metadata: []Entry{
+		{MetricFamilyName: "a", Type: mimirpb.COUNTER, Help: "a help", LastSeen: 2000},
+		{MetricFamilyName: "b", Type: mimirpb.GAUGE, Help: "b help", LastSeen: 1000},
+		{MetricFamilyName: "c", Type: mimirpb.HISTOGRAM, Help: "c help", LastSeen: 1000},
+	}}
+
+	assert.Equal(t, &File{
+		Version:   FileVersion1,
+		UpdatedAt: 30,
+		Metadata: []Entry{
+			{MetricFamilyName: "a", Type: mimirpb.COUNTER, Help: "a help", LastSeen: 2000},
+			{MetricFamilyName: "b", Type: mimirpb.GAUGE, Help: "b help", LastSeen: 3000},
+			{MetricFamilyName: "c", Type: mimirpb.HISTOGRAM, Help: "c help", LastSeen: 1000},
+		},
+	}, Merge(time.Unix(30, 0), first, nil, second))
+}
+
+func TestFile_Purge(t *testing.T) {
+	f := &File{Version: FileVersion1, Metadata: []Entry{
+		{MetricFamilyName: "a", Type: mimirpb.COUNTER, LastSeen: 1000},
+		{MetricFamilyName: "b", Type: mimirpb.GAUGE, LastSeen: 3000},
+		{MetricFamilyName: "c", Type: mimirpb.GAUGE, LastSeen: 2000},
+	}}
+
+	assert.Equal(t, 1, f.Purge(time.UnixMilli(2000)))
+	assert.Equal(t, []Entry{
+		{MetricFamilyName: "b", Type: mimirpb.GAUGE, LastSeen: 3000},
+		{MetricFamilyName: "c", Type: mimirpb.GAUGE, LastSeen: 2000},
+	}, f.Metadata)
+}
@@ -0,0 +1,223 @@
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package metricmetadata
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/grafana/dskit/runutil"
+	"github.com/pkg/errors"
+	"github.com/thanos-io/thanos/pkg/objstore"
+
+	"github.com/grafana/mimir/pkg/storage/bucket"
+)
+
+const (
+	// Pathname is the name of the directory, in the tenant's bucket, holding the metric metadata files.
+	Pathname = "metric-metadata"
+
+	// IndexFilename is the name of the per-tenant metric metadata index.
+	IndexFilename = "index.json"
+
+	// IndexCompressedFilename is the name of the compressed per-tenant metric metadata index.
+	IndexCompressedFilename = IndexFilename + ".gz"
+
+	// IngestersPathname is the name of the directory, inside Pathname, holding the metric metadata uploaded by the ingesters.
+	IngestersPathname = "ingesters"
+
+	// LocalFilename is the name of the file holding the metric metadata in the ingester's tenant TSDB directory.
+	LocalFilename = "metric-metadata.json"
+
+	ingesterFileExtension = ".json.gz"
+)
+
+var (
+	ErrIndexNotFound  = errors.New("metric metadata index not found")
+	ErrIndexCorrupted = errors.New("metric metadata index corrupted")
+)
+
+// IndexPath returns the path of the metric metadata index, in the tenant's bucket.
+func IndexPath() string {
+	return path.Join(Pathname, IndexCompressedFilename)
+}
+
+// IngesterFilePath returns the path of the metric metadata uploaded by the ingester, in the tenant's bucket.
+func IngesterFilePath(ingesterID string) string {
+	return path.Join(Pathname, IngestersPathname, ingesterID+ingesterFileExtension)
+}
+
+// ReadIndex reads, parses and returns the metric metadata index of the tenant from the bucket.
+func ReadIndex(ctx context.Context, bkt objstore.Bucket, userID string, cfgProvider bucket.TenantConfigProvider, logger log.Logger) (*File, error) {
+	userBkt := bucket.NewUserBucketClient(userID, bkt, cfgProvider)
+
+	f, err := readFile(ctx, userBkt, IndexPath(), logger)
+	if err != nil {
+		if userBkt.IsObjNotFoundErr(err) {
+			return nil, ErrIndexNotFound
+		}
+		if errors.Is(err, errFileCorrupted) {
+			return nil, ErrIndexCorrupted
+		}
+		return nil, errors.Wrap(err, "read metric metadata index")
+	}
+
+	return f, nil
+}
+
+// WriteIndex uploads the provided metric metadata index of the tenant to the bucket.
+func WriteIndex(ctx context.Context, bkt objstore.Bucket, userID string, cfgProvider bucket.TenantConfigProvider, f *File) error {
+	userBkt := bucket.NewUserBucketClient(userID, bkt, cfgProvider)
+
+	return errors.Wrap(writeFile(ctx, userBkt, IndexPath(), IndexFilename, f), "upload metric metadata index")
+}
+
+// WriteIngesterFile uploads the provided metric metadata of the tenant, held by the ingester, to the bucket.
+func WriteIngesterFile(ctx context.Context, bkt objstore.Bucket, userID, ingesterID string, cfgProvider bucket.TenantConfigProvider, f *File) error {
+	userBkt := bucket.NewUserBucketClient(userID, bkt, cfgProvider)
+
+	return errors.Wrap(writeFile(ctx, userBkt, IngesterFilePath(ingesterID), ingesterID+".json", f), "upload ingester metric metadata")
+}
+
+// ReadIngesterFiles reads and returns all the metric metadata of the tenant uploaded by the ingesters,
+// keyed by their path in the tenant's bucket. The corrupted files are skipped.
+func ReadIngesterFiles(ctx context.Context, bkt objstore.Bucket, userID string, cfgProvider bucket.TenantConfigProvider, logger log.Logger) (map[string]*File, error) {
+	userBkt := bucket.NewUserBucketClient(userID, bkt, cfgProvider)
+
+	var names []string
+	err := userBkt.Iter(ctx, path.Join(Pathname, IngestersPathname)+"/", func(name string) error {
+		if strings.HasSuffix(name, ingesterFileExtension) {
+			names = append(names, name)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "list ingester metric metadata")
+	}
+
+	files := make(map[string]*File, len(names))
+	for _, name := range names {
+		f, err := readFile(ctx, userBkt, name, logger)
+		if err != nil {
+			// The file may have been deleted in the meanwhile.
+			if userBkt.IsObjNotFoundErr(err) {
+				continue
+			}
+			if errors.Is(err, errFileCorrupted) {
+				level.Warn(logger).Log("msg", "skipped corrupted ingester metric metadata", "file", name)
+				continue
+			}
+			return nil, errors.Wrapf(err, "read ingester metric metadata %s", name)
+		}
+
+		files[name] = f
+	}
+
+	return files, nil
+}
+
+// DeleteFile deletes the metric metadata file at the given path of the tenant's bucket. No error is returned
+// if the file does not exist.
+func DeleteFile(ctx context.Context, bkt objstore.Bucket, userID string, cfgProvider bucket.TenantConfigProvider, name string) error {
+	userBkt := bucket.NewUserBucketClient(userID, bkt, cfgProvider)
+
+	err := userBkt.Delete(ctx, name)
+	if err != nil && !userBkt.IsObjNotFoundErr(err) {
+		return errors.Wrap(err, "delete metric metadata")
+	}
+	return nil
+}
+
+var errFileCorrupted = errors.New("metric metadata file corrupted")
+
+func readFile(ctx context.Context, bkt objstore.InstrumentedBucket, name string, logger log.Logger) (*File, error) {
+	reader, err := bkt.WithExpectedErrs(bkt.IsObjNotFoundErr).Get(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	defer runutil.CloseWithLogOnErr(logger, reader, "close metric metadata reader")
+
+	gzipReader, err := gzip.NewReader(reader)
+	if err != nil {
+		return nil, errFileCorrupted
+	}
+	defer runutil.CloseWithLogOnErr(logger, gzipReader, "close metric metadata gzip reader")
+
+	return decodeFile(gzipReader)
+}
+
+func writeFile(ctx context.Context, bkt objstore.Bucket, name, uncompressedName string, f *File) error {
+	content, err := json.Marshal(f)
+	if err != nil {
+		return errors.Wrap(err, "marshal metric metadata")
+	}
+
+	var gzipContent bytes.Buffer
+	gzip := gzip.NewWriter(&gzipContent)
+	gzip.Name = uncompressedName
+
+	if _, err := gzip.Write(content); err != nil {
+		return errors.Wrap(err, "gzip metric metadata")
+	}
+	if err := gzip.Close(); err != nil {
+		return errors.Wrap(err, "close gzip metric metadata")
+	}
+
+	return bkt.Upload(ctx, name, &gzipContent)
+}
+
+func decodeFile(r io.Reader) (*File, error) {
+	f := &File{}
+	if err := json.NewDecoder(r).Decode(f); err != nil {
+		return nil, errFileCorrupted
+	}
+	if f.Version != FileVersion1 {
+		return nil, errFileCorrupted
+	}
+	return f, nil
+}
+
+// ReadLocalFile reads the metric metadata from the file in the given directory. It returns
+// a nil file and no error if the file does not exist.
+func ReadLocalFile(dir string) (*File, error) {
+	fpath := filepath.Join(dir, LocalFilename)
+
+	reader, err := os.Open(fpath)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, "open %s", fpath)
+	}
+	defer reader.Close() //nolint:errcheck
+
+	f, err := decodeFile(reader)
+	if err != nil {
+		return nil, errors.Wrapf(err, "read %s", fpath)
+	}
+	return f, nil
+}
+
+// WriteLocalFile atomically writes the metric metadata to the file in the given directory.
+func WriteLocalFile(dir string, f *File) error {
+	fpath := filepath.Join(dir, LocalFilename)
+	tmp := fpath + ".tmp"
+
+	content, err := json.Marshal(f)
+	if err != nil {
+		return errors.Wrap(err, "marshal metric metadata")
+	}
+	if err := os.WriteFile(tmp, content, 0o666); err != nil {
+		return errors.Wrapf(err, "write %s", tmp)
+	}
+	return errors.Wrapf(os.Rename(tmp, fpath), "rename %s", tmp)
+}
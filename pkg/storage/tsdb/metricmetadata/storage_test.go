@@ -0,0 +1,114 @@
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package metricmetadata
+
+import (
+	"context"
+	"path"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/mimir/pkg/mimirpb"
+	mimir_testutil "github.com/grafana/mimir/pkg/storage/tsdb/testutil"
+)
+
+func TestReadIndex_ShouldReturnErrorIfIndexDoesNotExist(t *testing.T) {
+	bkt, _ := mimir_testutil.PrepareFilesystemBucket(t)
+
+	f, err := ReadIndex(context.Background(), bkt, "user-1", nil, log.NewNopLogger())
+	require.Equal(t, ErrIndexNotFound, err)
+	require.Nil(t, f)
+}
+
+func TestReadIndex_ShouldReturnErrorIfIndexIsCorrupted(t *testing.T) {
+	const userID = "user-1"
+
+	ctx := context.Background()
+	bkt, _ := mimir_testutil.PrepareFilesystemBucket(t)
+
+	// Write a corrupted index.
+	require.NoError(t, bkt.Upload(ctx, path.Join(userID, IndexPath()), strings.NewReader("invalid!}")))
+
+	f, err := ReadIndex(ctx, bkt, userID, nil, log.NewNopLogger())
+	require.Equal(t, ErrIndexCorrupted, err)
+	require.Nil(t, f)
+}
+
+func TestWriteIndex_ShouldBeReadByReadIndex(t *testing.T) {
+	const userID = "user-1"
+
+	ctx := context.Background()
+	bkt, _ := mimir_testutil.PrepareFilesystemBucket(t)
+	expected := NewFile(map[mimirpb.MetricMetadata]time.Time{
+		{MetricFamilyName: "a", Type: mimirpb.COUNTER, Help: "a help"}: time.Unix(10, 0),
+	}, time.Unix(20, 0))
+
+	require.NoError(t, WriteIndex(ctx, bkt, userID, nil, expected))
+
+	actual, err := ReadIndex(ctx, bkt, userID, nil, log.NewNopLogger())
+	require.NoError(t, err)
+	assert.Equal(t, expected, actual)
+}
+
+func TestReadIngesterFiles(t *testing.T) {
+	const userID = "user-1"
+
+	ctx := context.Background()
+	logger := log.NewNopLogger()
+	bkt, _ := mimir_testutil.PrepareFilesystemBucket(t)
+
+	files, err := ReadIngesterFiles(ctx, bkt, userID, nil, logger)
+	require.NoError(t, err)
+	assert.Empty(t, files)
+
+	first := NewFile(map[mimirpb.MetricMetadata]time.Time{
+		{MetricFamilyName: "a", Type: mimirpb.COUNTER, Help: "a help"}: time.Unix(10, 0),
+	}, time.Unix(20, 0))
+	second := NewFile(map[mimirpb.MetricMetadata]time.Time{
+		{MetricFamilyName: "b", Type: mimirpb.GAUGE, Help: "b help"}: time.Unix(10, 0),
+	}, time.Unix(20, 0))
+
+	require.NoError(t, WriteIngesterFile(ctx, bkt, userID, "ingester-1", nil, first))
+	require.NoError(t, WriteIngesterFile(ctx, bkt, userID, "ingester-2", nil, second))
+	require.NoError(t, bkt.Upload(ctx, path.Join(userID, IngesterFilePath("ingester-3")), strings.NewReader("invalid!}")))
+	// The index is not an ingester file.
+	require.NoError(t, WriteIndex(ctx, bkt, userID, nil, first))
+
+	files, err = ReadIngesterFiles(ctx, bkt, userID, nil, logger)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]*File{
+		IngesterFilePath("ingester-1"): first,
+		IngesterFilePath("ingester-2"): second,
+	}, files)
+
+	require.NoError(t, DeleteFile(ctx, bkt, userID, nil, IngesterFilePath("ingester-1")))
+	require.NoError(t, DeleteFile(ctx, bkt, userID, nil, IngesterFilePath("ingester-1")))
+
+	files, err = ReadIngesterFiles(ctx, bkt, userID, nil, logger)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]*File{
+		IngesterFilePath("ingester-2"): second,
+	}, files)
+}
+
+func TestLocalFile(t *testing.T) {
+	dir := t.TempDir()
+
+	f, err := ReadLocalFile(dir)
+	require.NoError(t, err)
+	assert.Nil(t, f)
+
+	expected := NewFile(map[mimirpb.MetricMetadata]time.Time{
+		{MetricFamilyName: "a", Type: mimirpb.COUNTER, Help: "a help"}: time.Unix(10, 0),
+	}, time.Unix(20, 0))
+	require.NoError(t, WriteLocalFile(dir, expected))
+
+	f, err = ReadLocalFile(dir)
+	require.NoError(t, err)
+	assert.Equal(t, expected, f)
+}
@@ -0,0 +1,352 @@
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package tsdb
+
+import (
+	"bytes"
+	"context"
+	"crypto/md5"
+	"encoding/hex"
+	"encoding/json"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/prometheus/prometheus/promql/parser"
+	"github.com/prometheus/prometheus/tsdb/tombstones"
+	"github.com/thanos-io/thanos/pkg/objstore"
+
+	"github.com/grafana/mimir/pkg/storage/bucket"
+	util_log "github.com/grafana/mimir/pkg/util/log"
+)
+
+// TombstonesPath is the path, relative to the user-specific prefix, under which
+// series deletion requests are stored.
+const TombstonesPath = "tombstones"
+
+// TombstoneState is the processing state of a series deletion request.
+type TombstoneState string
+
+const (
+	// TombstonePending means the deletion request has been accepted and is applied at query time,
+	// but the compactor hasn't rewritten the affected blocks yet.
+	TombstonePending TombstoneState = "pending"
+
+	// TombstoneProcessed means the compactor has rewritten all the affected blocks.
+	TombstoneProcessed TombstoneState = "processed"
+
+	// TombstoneCancelled means the deletion request has been cancelled before being processed.
+	TombstoneCancelled TombstoneState = "cancelled"
+)
+
+var (
+	ErrTombstoneNoSelectors      = errors.New("at least one series selector must be provided")
+	ErrTombstoneInvalidTimeRange = errors.New("the start time must be before or equal to the end time")
+)
+
+// Tombstone is a series deletion request for a tenant.
+type Tombstone struct {
+	RequestID string `json:"request_id"`
+
+	// Unix timestamps in milliseconds of the time range to delete. Both ends are inclusive.
+	StartTime int64 `json:"start_time"`
+	EndTime   int64 `json:"end_time"`
+
+	// Series selectors. A series is deleted if it matches any of them.
+	Selectors []string `json:"selectors"`
+
+	// Unix timestamp when the request was created.
+	RequestCreatedAt int64 `json:"request_created_at"`
+
+	State TombstoneState `json:"state"`
+
+	// Unix timestamp when the request moved to the current state.
+	StateCreatedAt int64 `json:"state_created_at"`
+
+	matchers [][]*labels.Matcher
+}
+
+// NewTombstone returns a pending series deletion request. The request ID is
+// derived from the selectors and time range, so that identical requests share the same ID.
+func NewTombstone(createdAt time.Time, startTime, endTime int64, selectors []string) (*Tombstone, error) {
+	t := &Tombstone{
+		StartTime:        startTime,
+		EndTime:          endTime,
+		Selectors:        selectors,
+		RequestCreatedAt: createdAt.Unix(),
+		State:            TombstonePending,
+		StateCreatedAt:   createdAt.Unix(),
+	}
+
+	if err := t.parseSelectors(); err != nil {
+		return nil, err
+	}
+
+	t.RequestID = tombstoneRequestID(startTime, endTime, selectors)
+	return t, nil
+}
+
+func tombstoneRequestID(startTime, endTime int64, selectors []string) string {
+	sorted := append([]string(nil), selectors...)
+	sort.Strings(sorted)
+
+	h := md5.New()
+	_, _ = h.Write([]byte(strconv.FormatInt(startTime, 10)))
+	_, _ = h.Write([]byte(strconv.FormatInt(endTime, 10)))
+	_, _ = h.Write([]byte(strings.Join(sorted, "\xff")))
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+func (t *Tombstone) parseSelectors() error {
+	if len(t.Selectors) == 0 {
+		return ErrTombstoneNoSelectors
+	}
+	if t.StartTime > t.EndTime {
+		return ErrTombstoneInvalidTimeRange
+	}
+
+	t.matchers = make([][]*labels.Matcher, 0, len(t.Selectors))
+	for _, selector := range t.Selectors {
+		matchers, err := parser.ParseMetricSelector(selector)
+		if err != nil {
+			return errors.Wrapf(err, "invalid series selector %q", selector)
+		}
+		t.matchers = append(t.matchers, matchers)
+	}
+
+	return nil
+}
+
+// Matchers returns the parsed series selectors.
+func (t *Tombstone) Matchers() [][]*labels.Matcher {
+	return t.matchers
+}
+
+// Matches returns whether the input series matches any of the series selectors.
+func (t *Tombstone) Matches(lbls labels.Labels) bool {
+	for _, matchers := range t.matchers {
+		if matchesAll(matchers, lbls) {
+			return true
+		}
+	}
+	return false
+}
+
+func matchesAll(matchers []*labels.Matcher, lbls labels.Labels) bool {
+	for _, m := range matchers {
+		if !m.Matches(lbls.Get(m.Name)) {
+			return false
+		}
+	}
+	return true
+}
+
+// Interval returns the time range to delete.
+func (t *Tombstone) Interval() tombstones.Interval {
+	return tombstones.Interval{Mint: t.StartTime, Maxt: t.EndTime}
+}
+
+// Overlaps returns whether the time range to delete overlaps with the input one.
+func (t *Tombstone) Overlaps(mint, maxt int64) bool {
+	return t.StartTime <= maxt && mint <= t.EndTime
+}
+
+// GetRequestCreatedAt returns the time when the request was created.
+func (t *Tombstone) GetRequestCreatedAt() time.Time {
+	return time.Unix(t.RequestCreatedAt, 0)
+}
+
+// UpdateState moves the request to the input state.
+func (t *Tombstone) UpdateState(state TombstoneState, now time.Time) {
+	t.State = state
+	t.StateCreatedAt = now.Unix()
+}
+
+// GetTombstonePath returns the path of the tombstone file, relative to the user-specific prefix.
+func GetTombstonePath(requestID string) string {
+	return path.Join(TombstonesPath, requestID+".json")
+}
+
+// WriteTombstone uploads the tombstone to the tenant location in the bucket. An existing tombstone
+// with the same request ID is overwritten.
+func WriteTombstone(ctx context.Context, bkt objstore.Bucket, userID string, cfgProvider bucket.TenantConfigProvider, t *Tombstone) error {
+	bkt = bucket.NewUserBucketClient(userID, bkt, cfgProvider)
+
+	data, err := json.Marshal(t)
+	if err != nil {
+		return errors.Wrap(err, "serialize tombstone")
+	}
+
+	return errors.Wrap(bkt.Upload(ctx, GetTombstonePath(t.RequestID), bytes.NewReader(data)), "upload tombstone")
+}
+
+// ReadTombstone returns the tombstone with the given request ID. If it doesn't exist, returns nil tombstone, and no error.
+func ReadTombstone(ctx context.Context, bkt objstore.BucketReader, userID, requestID string) (*Tombstone, error) {
+	return readTombstone(ctx, bkt, path.Join(userID, GetTombstonePath(requestID)))
+}
+
+// ReadTombstones returns all the tombstones of a tenant, sorted by creation time.
+func ReadTombstones(ctx context.Context, bkt objstore.BucketReader, userID string) ([]*Tombstone, error) {
+	var result []*Tombstone
+
+	err := bkt.Iter(ctx, path.Join(userID, TombstonesPath)+"/", func(name string) error {
+		if !strings.HasSuffix(name, ".json") {
+			return nil
+		}
+
+		t, err := readTombstone(ctx, bkt, name)
+		if err != nil {
+			return err
+		}
+
+		// The tombstone may have been deleted in the meanwhile.
+		if t != nil {
+			result = append(result, t)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].RequestCreatedAt != result[j].RequestCreatedAt {
+			return result[i].RequestCreatedAt < result[j].RequestCreatedAt
+		}
+		return result[i].RequestID < result[j].RequestID
+	})
+
+	return result, nil
+}
+
+func readTombstone(ctx context.Context, bkt objstore.BucketReader, tombstoneFile string) (*Tombstone, error) {
+	r, err := bkt.Get(ctx, tombstoneFile)
+	if err != nil {
+		if bkt.IsObjNotFoundErr(err) {
+			return nil, nil
+		}
+
+		return nil, errors.Wrapf(err, "failed to read tombstone object: %s", tombstoneFile)
+	}
+
+	t := &Tombstone{}
+	err = json.NewDecoder(r).Decode(t)
+
+	// Close reader before dealing with decode error.
+	if closeErr := r.Close(); closeErr != nil {
+		level.Warn(util_log.Logger).Log("msg", "failed to close bucket reader", "err", closeErr)
+	}
+
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to decode tombstone object: %s", tombstoneFile)
+	}
+
+	if err := t.parseSelectors(); err != nil {
+		return nil, errors.Wrapf(err, "failed to parse tombstone object: %s", tombstoneFile)
+	}
+
+	return t, nil
+}
+
+// TombstonesLoader loads the series deletion requests from the bucket and caches them
+// for a short period of time, to avoid listing the bucket for each query.
+type TombstonesLoader struct {
+	bkt                objstore.Bucket
+	cacheTTL           time.Duration
+	processedRetention time.Duration
+	logger             log.Logger
+
+	cacheMx sync.Mutex
+	cache   map[string]cachedTombstones
+
+	loadAttempts prometheus.Counter
+	loadFailures prometheus.Counter
+}
+
+type cachedTombstones struct {
+	tombstones []*Tombstone
+	loadedAt   time.Time
+}
+
+// NewTombstonesLoader makes a new TombstonesLoader. The processed series deletion requests are applied
+// for processedRetention since they've been processed, while the rewritten blocks replace the original ones.
+func NewTombstonesLoader(bkt objstore.Bucket, cacheTTL, processedRetention time.Duration, logger log.Logger, reg prometheus.Registerer) *TombstonesLoader {
+	return &TombstonesLoader{
+		bkt:                bkt,
+		cacheTTL:           cacheTTL,
+		processedRetention: processedRetention,
+		logger:             logger,
+		cache:              map[string]cachedTombstones{},
+
+		loadAttempts: promauto.With(reg).NewCounter(prometheus.CounterOpts{
+			Name: "cortex_tombstones_loads_total",
+			Help: "Total number of series deletion requests loading attempts.",
+		}),
+		loadFailures: promauto.With(reg).NewCounter(prometheus.CounterOpts{
+			Name: "cortex_tombstones_load_failures_total",
+			Help: "Total number of series deletion requests loading failures.",
+		}),
+	}
+}
+
+// GetTombstones returns the series deletion requests of the tenant which should be applied at query time:
+// the pending ones, and the ones processed less than the processed retention ago.
+func (l *TombstonesLoader) GetTombstones(ctx context.Context, userID string) ([]*Tombstone, error) {
+	l.cacheMx.Lock()
+	cached, ok := l.cache[userID]
+	l.cacheMx.Unlock()
+
+	if ok && time.Since(cached.loadedAt) < l.cacheTTL {
+		return cached.tombstones, nil
+	}
+
+	l.loadAttempts.Inc()
+	all, err := ReadTombstones(ctx, l.bkt, userID)
+	if err != nil {
+		l.loadFailures.Inc()
+
+		// Keep applying the previously loaded tombstones, if any, rather than failing the query.
+		if ok {
+			level.Warn(l.logger).Log("msg", "failed to reload series deletion requests, using the previously loaded ones", "user", userID, "err", err)
+			return cached.tombstones, nil
+		}
+		return nil, err
+	}
+
+	now := time.Now()
+	active := make([]*Tombstone, 0, len(all))
+	for _, t := range all {
+		if t.isActive(now, l.processedRetention) {
+			active = append(active, t)
+		}
+	}
+
+	l.cacheMx.Lock()
+	l.cache[userID] = cachedTombstones{tombstones: active, loadedAt: now}
+	l.cacheMx.Unlock()
+
+	return active, nil
+}
+
+// isActive returns whether the series deletion request should be applied at query time. The processed
+// requests have been physically applied to the blocks, but the original blocks can still be queried
+// until they're not loaded anymore, so they're applied for the processed retention too.
+func (t *Tombstone) isActive(now time.Time, processedRetention time.Duration) bool {
+	switch t.State {
+	case TombstonePending:
+		return true
+	case TombstoneProcessed:
+		return now.Sub(time.Unix(t.StateCreatedAt, 0)) < processedRetention
+	default:
+		return false
+	}
+}
@@ -0,0 +1,202 @@
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package tsdb
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/thanos-io/thanos/pkg/objstore"
+)
+
+func TestNewTombstone(t *testing.T) {
+	now := time.Now()
+
+	for name, tc := range map[string]struct {
+		start, end  int64
+		selectors   []string
+		expectedErr string
+	}{
+		"valid": {
+			start:     10,
+			end:       20,
+			selectors: []string{`up{job="a"}`, `{__name__=~"foo.*"}`},
+		},
+		"no selectors": {
+			start:       10,
+			end:         20,
+			expectedErr: ErrTombstoneNoSelectors.Error(),
+		},
+		"start after end": {
+			start:       20,
+			end:         10,
+			selectors:   []string{"up"},
+			expectedErr: ErrTombstoneInvalidTimeRange.Error(),
+		},
+		"invalid selector": {
+			start:       10,
+			end:         20,
+			selectors:   []string{"up{"},
+			expectedErr: "invalid series selector",
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			ts, err := NewTombstone(now, tc.start, tc.end, tc.selectors)
+			if tc.expectedErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tc.expectedErr)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, TombstonePending, ts.State)
+			assert.NotEmpty(t, ts.RequestID)
+			assert.Len(t, ts.Matchers(), len(tc.selectors))
+		})
+	}
+}
+
+func TestTombstone_RequestIDIsStable(t *testing.T) {
+	t1, err := NewTombstone(time.Now(), 10, 20, []string{"a", "b"})
+	require.NoError(t, err)
+	t2, err := NewTombstone(time.Now().Add(time.Hour), 10, 20, []string{"b", "a"})
+	require.NoError(t, err)
+	t3, err := NewTombstone(time.Now(), 10, 21, []string{"a", "b"})
+	require.NoError(t, err)
+
+	assert.Equal(t, t1.RequestID, t2.RequestID)
+	assert.NotEqual(t, t1.RequestID, t3.RequestID)
+}
+
+func TestTombstone_Matches(t *testing.T) {
+	ts, err := NewTombstone(time.Now(), 10, 20, []string{`up{job="a"}`, `{env="dev"}`})
+	require.NoError(t, err)
+
+	assert.True(t, ts.Matches(labels.FromStrings(labels.MetricName, "up", "job", "a")))
+	assert.True(t, ts.Matches(labels.FromStrings(labels.MetricName, "foo", "env", "dev")))
+	assert.False(t, ts.Matches(labels.FromStrings(labels.MetricName, "up", "job", "b")))
+	assert.False(t, ts.Matches(labels.FromStrings(labels.MetricName, "foo", "job", "a")))
+
+	assert.True(t, ts.Overlaps(0, 10))
+	assert.True(t, ts.Overlaps(20, 30))
+	assert.True(t, ts.Overlaps(12, 15))
+	assert.False(t, ts.Overlaps(0, 9))
+	assert.False(t, ts.Overlaps(21, 30))
+}
+
+func TestWriteAndReadTombstones(t *testing.T) {
+	const userID = "user"
+
+	ctx := context.Background()
+	bkt := objstore.NewInMemBucket()
+
+	t1, err := NewTombstone(time.Unix(200, 0), 10, 20, []string{"up"})
+	require.NoError(t, err)
+	t2, err := NewTombstone(time.Unix(100, 0), 30, 40, []string{`foo{bar="baz"}`})
+	require.NoError(t, err)
+
+	require.NoError(t, WriteTombstone(ctx, bkt, userID, nil, t1))
+	require.NoError(t, WriteTombstone(ctx, bkt, userID, nil, t2))
+
+	// Objects which are not tombstones should be ignored.
+	require.NoError(t, bkt.Upload(ctx, userID+"/"+TombstonesPath+"/other.txt", bytes.NewReader([]byte("data"))))
+
+	read, err := ReadTombstone(ctx, bkt, userID, t1.RequestID)
+	require.NoError(t, err)
+	require.NotNil(t, read)
+	assert.Equal(t, t1.Selectors, read.Selectors)
+	assert.Equal(t, t1.Matchers(), read.Matchers())
+
+	read, err = ReadTombstone(ctx, bkt, userID, "missing")
+	require.NoError(t, err)
+	assert.Nil(t, read)
+
+	all, err := ReadTombstones(ctx, bkt, userID)
+	require.NoError(t, err)
+	require.Len(t, all, 2)
+	assert.Equal(t, t2.RequestID, all[0].RequestID)
+	assert.Equal(t, t1.RequestID, all[1].RequestID)
+
+	// Overwriting a tombstone updates its state.
+	t1.UpdateState(TombstoneCancelled, time.Unix(300, 0))
+	require.NoError(t, WriteTombstone(ctx, bkt, userID, nil, t1))
+
+	read, err = ReadTombstone(ctx, bkt, userID, t1.RequestID)
+	require.NoError(t, err)
+	assert.Equal(t, TombstoneCancelled, read.State)
+	assert.Equal(t, int64(300), read.StateCreatedAt)
+
+	all, err = ReadTombstones(ctx, bkt, "other-user")
+	require.NoError(t, err)
+	assert.Empty(t, all)
+}
+
+func TestTombstonesLoader(t *testing.T) {
+	const userID = "user"
+
+	ctx := context.Background()
+	bkt := &failingBucket{Bucket: objstore.NewInMemBucket()}
+	reg := prometheus.NewPedanticRegistry()
+	loader := NewTombstonesLoader(bkt, time.Hour, time.Hour, log.NewNopLogger(), reg)
+
+	pending, err := NewTombstone(time.Now(), 0, 10, []string{"up"})
+	require.NoError(t, err)
+	cancelled, err := NewTombstone(time.Now(), 0, 20, []string{"up"})
+	require.NoError(t, err)
+	cancelled.UpdateState(TombstoneCancelled, time.Now())
+	recentlyProcessed, err := NewTombstone(time.Now(), 0, 30, []string{"up"})
+	require.NoError(t, err)
+	recentlyProcessed.UpdateState(TombstoneProcessed, time.Now().Add(-30*time.Minute))
+	processed, err := NewTombstone(time.Now(), 0, 40, []string{"up"})
+	require.NoError(t, err)
+	processed.UpdateState(TombstoneProcessed, time.Now().Add(-2*time.Hour))
+
+	for _, tombstone := range []*Tombstone{pending, cancelled, recentlyProcessed, processed} {
+		require.NoError(t, WriteTombstone(ctx, bkt, userID, nil, tombstone))
+	}
+
+	// Cancelled tombstones, and the ones processed for longer than the processed retention, are not returned.
+	actual, err := loader.GetTombstones(ctx, userID)
+	require.NoError(t, err)
+	require.Len(t, actual, 2)
+	assert.ElementsMatch(t, []string{pending.RequestID, recentlyProcessed.RequestID}, []string{actual[0].RequestID, actual[1].RequestID})
+
+	// Tombstones are cached.
+	bkt.err = errors.New("bucket unavailable")
+	actual, err = loader.GetTombstones(ctx, userID)
+	require.NoError(t, err)
+	require.Len(t, actual, 2)
+	assert.Equal(t, float64(1), testutil.ToFloat64(loader.loadAttempts))
+
+	// On reload failure, the previously loaded tombstones are returned.
+	loader.cacheTTL = 0
+	actual, err = loader.GetTombstones(ctx, userID)
+	require.NoError(t, err)
+	require.Len(t, actual, 2)
+	assert.Equal(t, float64(1), testutil.ToFloat64(loader.loadFailures))
+
+	// On failure without previously loaded tombstones, the error is returned.
+	_, err = loader.GetTombstones(ctx, "another-user")
+	require.Error(t, err)
+}
+
+type failingBucket struct {
+	objstore.Bucket
+	err error
+}
+
+func (b *failingBucket) Iter(ctx context.Context, dir string, f func(string) error, options ...objstore.IterOption) error {
+	if b.err != nil {
+		return b.err
+	}
+	return b.Bucket.Iter(ctx, dir, f, options...)
+}
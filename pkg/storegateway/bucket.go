@@ -55,10 +55,13 @@ import (
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 
+	"github.com/grafana/mimir/pkg/mimirpb"
 	"github.com/grafana/mimir/pkg/storage/sharding"
 	mimir_tsdb "github.com/grafana/mimir/pkg/storage/tsdb"
+	"github.com/grafana/mimir/pkg/storage/tsdb/exemplars"
 	"github.com/grafana/mimir/pkg/storegateway/indexcache"
 	"github.com/grafana/mimir/pkg/storegateway/indexheader"
+	"github.com/grafana/mimir/pkg/storegateway/storegatewaypb"
 	util_math "github.com/grafana/mimir/pkg/util/math"
 	"github.com/grafana/mimir/pkg/util/spanlogger"
 )
@@ -76,6 +79,9 @@ const (
 	chunkBytesPoolMinSize = 64 * 1024        // 64 KiB
 	chunkBytesPoolMaxSize = 64 * 1024 * 1024 // 64 MiB
 
+	// Maximum number of blocks whose exemplars are read concurrently by each Exemplars() call.
+	maxConcurrentExemplarsReads = 16
+
 	// Labels for metrics.
 	labelEncode = "encode"
 	labelDecode = "decode"
@@ -1362,6 +1368,84 @@ func (s *BucketStore) LabelValues(ctx context.Context, req *storepb.LabelValuesR
 	}, nil
 }
 
+// Exemplars returns the exemplars uploaded alongside the requested blocks. The requested blocks
+// which are not loaded by the store are not queried.
+func (s *BucketStore) Exemplars(ctx context.Context, req *storegatewaypb.ExemplarsRequest) (_ *storegatewaypb.ExemplarsResponse, err error) {
+	if s.queryGate != nil {
+		tracing.DoInSpan(ctx, "store_query_gate_ismyturn", func(ctx context.Context) {
+			err = s.queryGate.Start(ctx)
+		})
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to wait for turn")
+		}
+
+		defer s.queryGate.Done()
+	}
+
+	matchers := make([][]*labels.Matcher, 0, len(req.Matchers))
+	for _, set := range req.Matchers {
+		m, err := storepb.MatchersToPromMatchers(set.Matchers...)
+		if err != nil {
+			return nil, status.Error(codes.InvalidArgument, errors.Wrap(err, "translate request labels matchers").Error())
+		}
+		matchers = append(matchers, m)
+	}
+
+	var (
+		g, gctx = errgroup.WithContext(ctx)
+		mtx     sync.Mutex
+		files   []*exemplars.File
+		resp    = &storegatewaypb.ExemplarsResponse{}
+	)
+
+	// The exemplars of the blocks are read concurrently, but with a bounded number of requests to the bucket.
+	g.SetLimit(maxConcurrentExemplarsReads)
+
+	for _, id := range req.BlockIds {
+		blockID, err := ulid.Parse(id)
+		if err != nil {
+			return nil, status.Error(codes.InvalidArgument, errors.Wrapf(err, "parse block ID %s", id).Error())
+		}
+
+		b := s.getBlock(blockID)
+		if b == nil || !b.overlapsClosedInterval(req.StartTimestampMs, req.EndTimestampMs) {
+			continue
+		}
+		resp.QueriedBlocks = append(resp.QueriedBlocks, id)
+
+		g.Go(func() error {
+			f, err := exemplars.Read(gctx, s.bkt, blockID, s.logger)
+			if errors.Is(err, exemplars.ErrFileCorrupted) {
+				level.Warn(s.logger).Log("msg", "skipped corrupted block exemplars", "block", blockID, "err", err)
+				return nil
+			}
+			if err != nil || f == nil {
+				return err
+			}
+
+			mtx.Lock()
+			files = append(files, f)
+			mtx.Unlock()
+			return nil
+		})
+	}
+
+	if err := g.Wait(); err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	results := exemplars.Merge(files...).Select(req.StartTimestampMs, req.EndTimestampMs, matchers...)
+	resp.Timeseries = make([]mimirpb.TimeSeries, 0, len(results))
+	for _, r := range results {
+		resp.Timeseries = append(resp.Timeseries, mimirpb.TimeSeries{
+			Labels:    mimirpb.FromLabelsToLabelAdapters(r.SeriesLabels),
+			Exemplars: mimirpb.FromExemplarsToExemplarProtos(r.Exemplars),
+		})
+	}
+
+	return resp, nil
+}
+
 // blockLabelValues provides the values of the label with requested name,
 // optionally restricting the search to the series that match the matchers provided.
 // - First we fetch all possible values for this label from the index.
@@ -34,9 +34,12 @@ import (
 	"github.com/weaveworks/common/logging"
 	"google.golang.org/grpc/metadata"
 
+	"github.com/grafana/mimir/pkg/mimirpb"
 	"github.com/grafana/mimir/pkg/storage/bucket"
 	"github.com/grafana/mimir/pkg/storage/tsdb"
+	"github.com/grafana/mimir/pkg/storage/tsdb/metricmetadata"
 	"github.com/grafana/mimir/pkg/storegateway/indexcache"
+	"github.com/grafana/mimir/pkg/storegateway/storegatewaypb"
 	util_log "github.com/grafana/mimir/pkg/util/log"
 	"github.com/grafana/mimir/pkg/util/spanlogger"
 	"github.com/grafana/mimir/pkg/util/validation"
@@ -73,10 +76,17 @@ type BucketStores struct {
 	// Gate used to limit query concurrency across all tenants.
 	queryGate gate.Gate
 
+	// Series deletion requests loader, nil if series deletion is disabled.
+	tombstones *tsdb.TombstonesLoader
+
 	// Keeps a bucket store for each tenant.
 	storesMu sync.RWMutex
 	stores   map[string]*BucketStore
 
+	// Keeps the metric metadata of each tenant, loaded from the metric metadata index at each sync.
+	metricsMetadataMu sync.RWMutex
+	metricsMetadata   map[string][]*mimirpb.MetricMetadata
+
 	// Metrics.
 	syncTimes         prometheus.Histogram
 	syncLastSuccess   prometheus.Gauge
@@ -107,6 +117,7 @@ func NewBucketStores(cfg tsdb.BlocksStorageConfig, shardingStrategy ShardingStra
 		bucket:             cachingBucket,
 		shardingStrategy:   shardingStrategy,
 		stores:             map[string]*BucketStore{},
+		metricsMetadata:    map[string][]*mimirpb.MetricMetadata{},
 		logLevel:           logLevel,
 		bucketStoreMetrics: NewBucketStoreMetrics(reg),
 		metaFetcherMetrics: NewMetadataFetcherMetrics(),
@@ -153,6 +164,11 @@ func NewBucketStores(cfg tsdb.BlocksStorageConfig, shardingStrategy ShardingStra
 		return nil, errors.Wrap(err, "create chunks bytes pool")
 	}
 
+	// The series deletion requests are not cached by the caching bucket, since they're already cached by the loader.
+	if cfg.BucketStore.SeriesDeletionEnabled {
+		u.tombstones = tsdb.NewTombstonesLoader(bucketClient, cfg.BucketStore.SeriesDeletionCacheTTL, cfg.BucketStore.ProcessedTombstonesRetention(), logger, reg)
+	}
+
 	if reg != nil {
 		reg.MustRegister(u.metaFetcherMetrics)
 	}
@@ -255,6 +271,8 @@ func (u *BucketStores) syncUsersBlocks(ctx context.Context, f func(context.Conte
 					errs.Add(errors.Wrapf(err, "failed to synchronize TSDB blocks for user %s", job.userID))
 					errsMx.Unlock()
 				}
+
+				u.syncMetricsMetadata(ctx, job.userID)
 			}
 		}()
 	}
@@ -290,10 +308,41 @@ func (u *BucketStores) syncUsersBlocks(ctx context.Context, f func(context.Conte
 	wg.Wait()
 
 	u.closeBucketStoreAndDeleteLocalFilesForExcludedTenants(includeUserIDs)
+	u.deleteMetricsMetadataForExcludedTenants(includeUserIDs)
 
 	return errs.Err()
 }
 
+// syncMetricsMetadata loads the metric metadata index of the tenant in memory. If the index can't
+// be read, the metadata previously loaded is kept, so that a transient failure doesn't hide it.
+func (u *BucketStores) syncMetricsMetadata(ctx context.Context, userID string) {
+	idx, err := metricmetadata.ReadIndex(ctx, u.bucket, userID, u.limits, u.logger)
+	if err != nil && !errors.Is(err, metricmetadata.ErrIndexNotFound) {
+		level.Warn(u.logger).Log("msg", "failed to load metric metadata index", "user", userID, "err", err)
+		return
+	}
+
+	u.metricsMetadataMu.Lock()
+	defer u.metricsMetadataMu.Unlock()
+
+	if idx == nil {
+		delete(u.metricsMetadata, userID)
+		return
+	}
+	u.metricsMetadata[userID] = idx.MetricMetadata()
+}
+
+func (u *BucketStores) deleteMetricsMetadataForExcludedTenants(includeUserIDs map[string]struct{}) {
+	u.metricsMetadataMu.Lock()
+	defer u.metricsMetadataMu.Unlock()
+
+	for userID := range u.metricsMetadata {
+		if _, included := includeUserIDs[userID]; !included {
+			delete(u.metricsMetadata, userID)
+		}
+	}
+}
+
 // Series makes a series request to the underlying user bucket store.
 func (u *BucketStores) Series(req *storepb.SeriesRequest, srv storepb.Store_SeriesServer) error {
 	spanLog, spanCtx := spanlogger.NewWithLogger(srv.Context(), u.logger, "BucketStores.Series")
@@ -309,10 +358,33 @@ func (u *BucketStores) Series(req *storepb.SeriesRequest, srv storepb.Store_Seri
 		return nil
 	}
 
-	return store.Series(req, spanSeriesServer{
+	var seriesSrv storepb.Store_SeriesServer = spanSeriesServer{
 		Store_SeriesServer: srv,
 		ctx:                spanCtx,
-	})
+	}
+
+	tombstones, err := u.getTombstones(spanCtx, userID, req.MinTime, req.MaxTime)
+	if err != nil {
+		return err
+	}
+	if len(tombstones) > 0 {
+		seriesSrv = newTombstonesSeriesServer(seriesSrv, tombstones, req)
+	}
+
+	return store.Series(req, seriesSrv)
+}
+
+// getTombstones returns the series deletion requests of the tenant overlapping the time range.
+func (u *BucketStores) getTombstones(ctx context.Context, userID string, mint, maxt int64) ([]*tsdb.Tombstone, error) {
+	if u.tombstones == nil {
+		return nil, nil
+	}
+
+	tombstones, err := u.tombstones.GetTombstones(ctx, userID)
+	if err != nil {
+		return nil, errors.Wrap(err, "load series deletion requests")
+	}
+	return filterTombstonesByTimeRange(tombstones, mint, maxt), nil
 }
 
 // LabelNames implements the Storegateway proto service.
@@ -330,6 +402,14 @@ func (u *BucketStores) LabelNames(ctx context.Context, req *storepb.LabelNamesRe
 		return &storepb.LabelNamesResponse{}, nil
 	}
 
+	tombstones, err := u.getTombstones(spanCtx, userID, req.Start, req.End)
+	if err != nil {
+		return nil, err
+	}
+	if len(tombstones) > 0 {
+		return labelNamesWithTombstones(ctx, store, tombstones, req)
+	}
+
 	return store.LabelNames(ctx, req)
 }
 
@@ -348,9 +428,49 @@ func (u *BucketStores) LabelValues(ctx context.Context, req *storepb.LabelValues
 		return &storepb.LabelValuesResponse{}, nil
 	}
 
+	tombstones, err := u.getTombstones(spanCtx, userID, req.Start, req.End)
+	if err != nil {
+		return nil, err
+	}
+	if len(tombstones) > 0 {
+		return labelValuesWithTombstones(ctx, store, tombstones, req)
+	}
+
 	return store.LabelValues(ctx, req)
 }
 
+// MetricsMetadata returns the metric metadata of the tenant, as loaded at the last sync from the
+// metric metadata index maintained by the compactor.
+func (u *BucketStores) MetricsMetadata(ctx context.Context, _ *storegatewaypb.MetricsMetadataRequest) (*storegatewaypb.MetricsMetadataResponse, error) {
+	userID := getUserIDFromGRPCContext(ctx)
+	if userID == "" {
+		return nil, fmt.Errorf("no userID")
+	}
+
+	u.metricsMetadataMu.RLock()
+	defer u.metricsMetadataMu.RUnlock()
+
+	return &storegatewaypb.MetricsMetadataResponse{Metadata: u.metricsMetadata[userID]}, nil
+}
+
+// Exemplars returns the exemplars uploaded alongside the requested blocks of the tenant.
+func (u *BucketStores) Exemplars(ctx context.Context, req *storegatewaypb.ExemplarsRequest) (*storegatewaypb.ExemplarsResponse, error) {
+	spanLog, spanCtx := spanlogger.NewWithLogger(ctx, u.logger, "BucketStores.Exemplars")
+	defer spanLog.Span.Finish()
+
+	userID := getUserIDFromGRPCContext(spanCtx)
+	if userID == "" {
+		return nil, fmt.Errorf("no userID")
+	}
+
+	store := u.getStore(userID)
+	if store == nil {
+		return &storegatewaypb.ExemplarsResponse{}, nil
+	}
+
+	return store.Exemplars(spanCtx, req)
+}
+
 // scanUsers in the bucket and return the list of found users. If an error occurs while
 // iterating the bucket, it may return both an error and a subset of the users in the bucket.
 func (u *BucketStores) scanUsers(ctx context.Context) ([]string, error) {
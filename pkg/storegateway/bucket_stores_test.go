@@ -13,6 +13,7 @@ import (
 	"io/ioutil"
 	"math"
 	"os"
+	"path"
 	"path/filepath"
 	"sort"
 	"strconv"
@@ -21,6 +22,7 @@ import (
 	"time"
 
 	"github.com/go-kit/log"
+	"github.com/gogo/protobuf/types"
 	"github.com/grafana/dskit/flagext"
 	"github.com/oklog/ulid"
 	"github.com/prometheus/client_golang/prometheus"
@@ -38,17 +40,22 @@ import (
 	"github.com/thanos-io/thanos/pkg/extprom"
 	"github.com/thanos-io/thanos/pkg/objstore"
 	filesystemstore "github.com/thanos-io/thanos/pkg/objstore/filesystem"
+	"github.com/thanos-io/thanos/pkg/store/hintspb"
 	"github.com/thanos-io/thanos/pkg/store/labelpb"
 	"github.com/thanos-io/thanos/pkg/store/storepb"
 	"github.com/weaveworks/common/logging"
 	"go.uber.org/atomic"
 	"google.golang.org/grpc/metadata"
 
+	"github.com/grafana/mimir/pkg/mimirpb"
 	"github.com/grafana/mimir/pkg/storage/bucket"
 	"github.com/grafana/mimir/pkg/storage/bucket/filesystem"
 	mimir_tsdb "github.com/grafana/mimir/pkg/storage/tsdb"
+	"github.com/grafana/mimir/pkg/storage/tsdb/exemplars"
+	"github.com/grafana/mimir/pkg/storage/tsdb/metricmetadata"
 	mimir_testutil "github.com/grafana/mimir/pkg/storage/tsdb/testutil"
 	"github.com/grafana/mimir/pkg/storegateway/indexcache"
+	"github.com/grafana/mimir/pkg/storegateway/storegatewaypb"
 	"github.com/grafana/mimir/pkg/util"
 	"github.com/grafana/mimir/pkg/util/test"
 )
@@ -296,6 +303,9 @@ func TestBucketStores_syncUsersBlocks(t *testing.T) {
 
 			bucketClient := &bucket.ClientMock{}
 			bucketClient.MockIter("", allUsers, nil)
+			for _, userID := range allUsers {
+				bucketClient.MockGet(path.Join(userID, metricmetadata.IndexPath()), "", nil)
+			}
 
 			stores, err := NewBucketStores(cfg, testData.shardingStrategy, bucketClient, defaultLimitsOverrides(t), mockLoggingLevel(), log.NewNopLogger(), nil)
 			require.NoError(t, err)
@@ -390,6 +400,57 @@ func testBucketStoresSeriesShouldCorrectlyQuerySeriesSpanningMultipleChunks(t *t
 	}
 }
 
+func TestBucketStores_LabelNamesAndValuesWithTombstones(t *testing.T) {
+	const userID = "user-1"
+
+	ctx := context.Background()
+	cfg := prepareStorageConfig(t)
+	cfg.BucketStore.SeriesDeletionEnabled = true
+
+	storageDir := t.TempDir()
+	generateStorageBlock(t, storageDir, userID, "series_1", 0, 100, 15)
+	generateStorageBlock(t, storageDir, userID, "series_2", 0, 100, 15)
+
+	bucket, err := filesystem.NewBucketClient(filesystem.Config{Directory: storageDir})
+	require.NoError(t, err)
+
+	tombstone, err := mimir_tsdb.NewTombstone(time.Now(), 0, 200, []string{`{__name__="series_2"}`})
+	require.NoError(t, err)
+	require.NoError(t, mimir_tsdb.WriteTombstone(ctx, bucket, userID, nil, tombstone))
+
+	stores, err := NewBucketStores(cfg, newNoShardingStrategy(), bucket, defaultLimitsOverrides(t), mockLoggingLevel(), log.NewNopLogger(), nil)
+	require.NoError(t, err)
+	require.NoError(t, stores.InitialSync(ctx))
+
+	userCtx := setUserIDToGRPCContext(ctx, userID)
+
+	t.Run("label values of the deleted series are not returned", func(t *testing.T) {
+		resp, err := stores.LabelValues(userCtx, &storepb.LabelValuesRequest{Label: labels.MetricName, Start: 0, End: 100})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"series_1"}, resp.Values)
+
+		hints := &hintspb.LabelValuesResponseHints{}
+		require.NoError(t, types.UnmarshalAny(resp.Hints, hints))
+		assert.Len(t, hints.QueriedBlocks, 2)
+	})
+
+	t.Run("label names of the deleted series are not returned", func(t *testing.T) {
+		resp, err := stores.LabelNames(userCtx, &storepb.LabelNamesRequest{
+			Start:    0,
+			End:      100,
+			Matchers: []storepb.LabelMatcher{{Type: storepb.LabelMatcher_EQ, Name: labels.MetricName, Value: "series_2"}},
+		})
+		require.NoError(t, err)
+		assert.Empty(t, resp.Names)
+	})
+
+	t.Run("series only partially deleted are returned", func(t *testing.T) {
+		resp, err := stores.LabelValues(userCtx, &storepb.LabelValuesRequest{Label: labels.MetricName, Start: 0, End: 300})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"series_1", "series_2"}, resp.Values)
+	})
+}
+
 func TestBucketStore_Series_ShouldQueryBlockWithOutOfOrderChunks(t *testing.T) {
 	const (
 		userID     = "user-1"
@@ -500,6 +561,121 @@ func TestBucketStore_Series_ShouldQueryBlockWithOutOfOrderChunks(t *testing.T) {
 	}
 }
 
+func TestBucketStores_MetricsMetadata(t *testing.T) {
+	test.VerifyNoLeak(t)
+
+	ctx := context.Background()
+	cfg := prepareStorageConfig(t)
+
+	storageDir := t.TempDir()
+	generateStorageBlock(t, storageDir, "user-1", "series_1", 10, 100, 15)
+	generateStorageBlock(t, storageDir, "user-2", "series_2", 10, 100, 15)
+
+	bucket, err := filesystem.NewBucketClient(filesystem.Config{Directory: storageDir})
+	require.NoError(t, err)
+
+	// Only user-1 has a metric metadata index.
+	expected := []*mimirpb.MetricMetadata{{MetricFamilyName: "series_1", Type: mimirpb.COUNTER, Help: "series_1 help"}}
+	require.NoError(t, metricmetadata.WriteIndex(ctx, bucket, "user-1", nil, metricmetadata.NewFile(map[mimirpb.MetricMetadata]time.Time{
+		*expected[0]: time.Now(),
+	}, time.Now())))
+
+	stores, err := NewBucketStores(cfg, newNoShardingStrategy(), bucket, defaultLimitsOverrides(t), mockLoggingLevel(), log.NewNopLogger(), nil)
+	require.NoError(t, err)
+	require.NoError(t, stores.InitialSync(ctx))
+
+	resp, err := stores.MetricsMetadata(setUserIDToGRPCContext(ctx, "user-1"), &storegatewaypb.MetricsMetadataRequest{})
+	require.NoError(t, err)
+	assert.Equal(t, expected, resp.Metadata)
+
+	resp, err = stores.MetricsMetadata(setUserIDToGRPCContext(ctx, "user-2"), &storegatewaypb.MetricsMetadataRequest{})
+	require.NoError(t, err)
+	assert.Empty(t, resp.Metadata)
+
+	// The metadata is served from memory until the next sync.
+	require.NoError(t, bucket.Delete(ctx, path.Join("user-1", metricmetadata.IndexPath())))
+
+	resp, err = stores.MetricsMetadata(setUserIDToGRPCContext(ctx, "user-1"), &storegatewaypb.MetricsMetadataRequest{})
+	require.NoError(t, err)
+	assert.Equal(t, expected, resp.Metadata)
+
+	require.NoError(t, stores.SyncBlocks(ctx))
+
+	resp, err = stores.MetricsMetadata(setUserIDToGRPCContext(ctx, "user-1"), &storegatewaypb.MetricsMetadataRequest{})
+	require.NoError(t, err)
+	assert.Empty(t, resp.Metadata)
+}
+
+func TestBucketStores_Exemplars(t *testing.T) {
+	test.VerifyNoLeak(t)
+
+	const userID = "user-1"
+
+	ctx := context.Background()
+	cfg := prepareStorageConfig(t)
+
+	storageDir := t.TempDir()
+	generateStorageBlock(t, storageDir, userID, "series_1", 10, 100, 15)
+
+	entries, err := os.ReadDir(filepath.Join(storageDir, userID))
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	blockID, err := ulid.Parse(entries[0].Name())
+	require.NoError(t, err)
+
+	bkt, err := filesystem.NewBucketClient(filesystem.Config{Directory: storageDir})
+	require.NoError(t, err)
+
+	series1 := labels.FromStrings(labels.MetricName, "series_1")
+	require.NoError(t, exemplars.Upload(ctx, bucket.NewUserBucketClient(userID, bkt, nil), blockID, &exemplars.File{
+		Version: exemplars.FileVersion1,
+		Series: []exemplars.Series{{
+			Labels: series1,
+			Exemplars: []exemplars.Exemplar{
+				{Labels: labels.FromStrings("trace_id", "1"), Value: 1, Timestamp: 20},
+				{Labels: labels.FromStrings("trace_id", "2"), Value: 2, Timestamp: 50},
+			},
+		}, {
+			Labels: labels.FromStrings(labels.MetricName, "series_2"),
+			Exemplars: []exemplars.Exemplar{
+				{Labels: labels.FromStrings("trace_id", "3"), Value: 3, Timestamp: 20},
+			},
+		}},
+	}))
+
+	stores, err := NewBucketStores(cfg, newNoShardingStrategy(), bkt, defaultLimitsOverrides(t), mockLoggingLevel(), log.NewNopLogger(), nil)
+	require.NoError(t, err)
+	require.NoError(t, stores.InitialSync(ctx))
+
+	// Blocks not loaded by the store-gateway are not queried.
+	unknownBlockID := ulid.MustNew(1, nil)
+
+	resp, err := stores.Exemplars(setUserIDToGRPCContext(ctx, userID), &storegatewaypb.ExemplarsRequest{
+		StartTimestampMs: 10,
+		EndTimestampMs:   40,
+		Matchers: []storegatewaypb.ExemplarsMatchers{{
+			Matchers: []storepb.LabelMatcher{{Type: storepb.LabelMatcher_EQ, Name: labels.MetricName, Value: "series_1"}},
+		}},
+		BlockIds: []string{blockID.String(), unknownBlockID.String()},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{blockID.String()}, resp.QueriedBlocks)
+	assert.Equal(t, []mimirpb.TimeSeries{{
+		Labels:    mimirpb.FromLabelsToLabelAdapters(series1),
+		Exemplars: []mimirpb.Exemplar{{Labels: mimirpb.FromLabelsToLabelAdapters(labels.FromStrings("trace_id", "1")), Value: 1, TimestampMs: 20}},
+	}}, resp.Timeseries)
+
+	// Tenants without blocks have no exemplars.
+	resp, err = stores.Exemplars(setUserIDToGRPCContext(ctx, "user-2"), &storegatewaypb.ExemplarsRequest{
+		StartTimestampMs: 10,
+		EndTimestampMs:   40,
+		BlockIds:         []string{blockID.String()},
+	})
+	require.NoError(t, err)
+	assert.Empty(t, resp.Timeseries)
+	assert.Empty(t, resp.QueriedBlocks)
+}
+
 func prepareStorageConfig(t *testing.T) mimir_tsdb.BlocksStorageConfig {
 	tmpDir := t.TempDir()
 
@@ -324,6 +324,26 @@ func (g *StoreGateway) LabelValues(ctx context.Context, req *storepb.LabelValues
 	return g.stores.LabelValues(ctx, req)
 }
 
+// MetricsMetadata implements the Storegateway proto service.
+func (g *StoreGateway) MetricsMetadata(ctx context.Context, req *storegatewaypb.MetricsMetadataRequest) (*storegatewaypb.MetricsMetadataResponse, error) {
+	ix := g.tracker.Insert(func() string {
+		return requestActivity(ctx, "StoreGateway/MetricsMetadata", req)
+	})
+	defer g.tracker.Delete(ix)
+
+	return g.stores.MetricsMetadata(ctx, req)
+}
+
+// Exemplars implements the Storegateway proto service.
+func (g *StoreGateway) Exemplars(ctx context.Context, req *storegatewaypb.ExemplarsRequest) (*storegatewaypb.ExemplarsResponse, error) {
+	ix := g.tracker.Insert(func() string {
+		return requestActivity(ctx, "StoreGateway/Exemplars", req)
+	})
+	defer g.tracker.Delete(ix)
+
+	return g.stores.Exemplars(ctx, req)
+}
+
 func requestActivity(ctx context.Context, name string, req interface{}) string {
 	user := getUserIDFromGRPCContext(ctx)
 	traceID, _ := tracing.ExtractSampledTraceID(ctx)
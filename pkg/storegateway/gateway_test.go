@@ -52,6 +52,7 @@ import (
 	"github.com/grafana/mimir/pkg/storage/sharding"
 	mimir_tsdb "github.com/grafana/mimir/pkg/storage/tsdb"
 	"github.com/grafana/mimir/pkg/storage/tsdb/bucketindex"
+	"github.com/grafana/mimir/pkg/storage/tsdb/metricmetadata"
 	mimir_testutil "github.com/grafana/mimir/pkg/storage/tsdb/testutil"
 	"github.com/grafana/mimir/pkg/util"
 	"github.com/grafana/mimir/pkg/util/test"
@@ -160,6 +161,8 @@ func TestStoreGateway_InitialSyncWithDefaultShardingEnabled(t *testing.T) {
 			})
 			bucketClient.MockIter("user-1/", []string{}, nil)
 			bucketClient.MockIter("user-2/", []string{}, nil)
+			bucketClient.MockGet(path.Join("user-1", metricmetadata.IndexPath()), "", nil)
+			bucketClient.MockGet(path.Join("user-2", metricmetadata.IndexPath()), "", nil)
 
 			// Once successfully started, the instance should be ACTIVE in the ring.
 			require.NoError(t, services.StartAndAwaitRunning(ctx, g))
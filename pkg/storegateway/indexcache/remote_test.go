@@ -26,7 +26,7 @@ import (
 	"github.com/grafana/mimir/pkg/storage/sharding"
 )
 
-func TestMemcachedIndexCache_FetchMultiPostings(t *testing.T) {
+func TestRemoteIndexCache_FetchMultiPostings(t *testing.T) {
 	t.Parallel()
 
 	// Init some data to conveniently define test cases later one.
@@ -84,7 +84,7 @@ func TestMemcachedIndexCache_FetchMultiPostings(t *testing.T) {
 			expectedHits:   map[labels.Label][]byte{label1: value1},
 			expectedMisses: []labels.Label{label2},
 		},
-		"should return no hits on memcached error": {
+		"should return no hits on remote cache error": {
 			setup: []mockedPostings{
 				{userID: user1, block: block1, label: label1, value: value1},
 				{userID: user1, block: block1, label: label2, value: value2},
@@ -101,8 +101,8 @@ func TestMemcachedIndexCache_FetchMultiPostings(t *testing.T) {
 
 	for testName, testData := range tests {
 		t.Run(testName, func(t *testing.T) {
-			memcached := newMockedMemcachedClient(testData.mockedErr)
-			c, err := NewMemcachedIndexCache(log.NewNopLogger(), memcached, nil)
+			client := newMockedRemoteCacheClient(testData.mockedErr)
+			c, err := NewRemoteIndexCache(log.NewNopLogger(), client, nil)
 			assert.NoError(t, err)
 
 			// Store the postings expected before running the test.
@@ -127,7 +127,7 @@ func TestMemcachedIndexCache_FetchMultiPostings(t *testing.T) {
 	}
 }
 
-func TestMemcachedIndexCache_FetchMultiSeriesForRef(t *testing.T) {
+func TestRemoteIndexCache_FetchMultiSeriesForRef(t *testing.T) {
 	t.Parallel()
 
 	// Init some data to conveniently define test cases later one.
@@ -184,7 +184,7 @@ func TestMemcachedIndexCache_FetchMultiSeriesForRef(t *testing.T) {
 			expectedHits:   map[storage.SeriesRef][]byte{1: value1},
 			expectedMisses: []storage.SeriesRef{2},
 		},
-		"should return no hits on memcached error": {
+		"should return no hits on remote cache error": {
 			setup: []mockedSeriesForRef{
 				{userID: user1, block: block1, id: 1, value: value1},
 				{userID: user1, block: block1, id: 2, value: value2},
@@ -201,8 +201,8 @@ func TestMemcachedIndexCache_FetchMultiSeriesForRef(t *testing.T) {
 
 	for testName, testData := range tests {
 		t.Run(testName, func(t *testing.T) {
-			memcached := newMockedMemcachedClient(testData.mockedErr)
-			c, err := NewMemcachedIndexCache(log.NewNopLogger(), memcached, nil)
+			client := newMockedRemoteCacheClient(testData.mockedErr)
+			c, err := NewRemoteIndexCache(log.NewNopLogger(), client, nil)
 			assert.NoError(t, err)
 
 			// Store the series expected before running the test.
@@ -227,7 +227,7 @@ func TestMemcachedIndexCache_FetchMultiSeriesForRef(t *testing.T) {
 	}
 }
 
-func TestMemcachedIndexCache_FetchExpandedPostings(t *testing.T) {
+func TestRemoteIndexCache_FetchExpandedPostings(t *testing.T) {
 	t.Parallel()
 
 	// Init some data to conveniently define test cases later one.
@@ -271,7 +271,7 @@ func TestMemcachedIndexCache_FetchExpandedPostings(t *testing.T) {
 			expectedData: value1,
 			expectedOk:   true,
 		},
-		"should return no hit on memcached error": {
+		"should return no hit on remote cache error": {
 			setup: []mockedExpandedPostings{
 				{userID: user1, block: block1, matchers: matchers1, value: value1},
 				{userID: user1, block: block1, matchers: matchers2, value: value2},
@@ -288,8 +288,8 @@ func TestMemcachedIndexCache_FetchExpandedPostings(t *testing.T) {
 
 	for testName, testData := range tests {
 		t.Run(testName, func(t *testing.T) {
-			memcached := newMockedMemcachedClient(testData.mockedErr)
-			c, err := NewMemcachedIndexCache(log.NewNopLogger(), memcached, nil)
+			client := newMockedRemoteCacheClient(testData.mockedErr)
+			c, err := NewRemoteIndexCache(log.NewNopLogger(), client, nil)
 			assert.NoError(t, err)
 
 			// Store the postings expected before running the test.
@@ -318,7 +318,7 @@ func TestMemcachedIndexCache_FetchExpandedPostings(t *testing.T) {
 	}
 }
 
-func TestMemcachedIndexCache_FetchSeries(t *testing.T) {
+func TestRemoteIndexCache_FetchSeries(t *testing.T) {
 	t.Parallel()
 
 	// Init some data to conveniently define test cases later one.
@@ -368,7 +368,7 @@ func TestMemcachedIndexCache_FetchSeries(t *testing.T) {
 			expectedData: value1,
 			expectedOk:   true,
 		},
-		"should return no hit on memcached error": {
+		"should return no hit on remote cache error": {
 			setup: []mockedSeries{
 				{userID: user1, block: block1, matchers: matchers1, shard: shard1, value: value1},
 				{userID: user1, block: block1, matchers: matchers2, shard: shard1, value: value2},
@@ -386,8 +386,8 @@ func TestMemcachedIndexCache_FetchSeries(t *testing.T) {
 
 	for testName, testData := range tests {
 		t.Run(testName, func(t *testing.T) {
-			memcached := newMockedMemcachedClient(testData.mockedErr)
-			c, err := NewMemcachedIndexCache(log.NewNopLogger(), memcached, nil)
+			client := newMockedRemoteCacheClient(testData.mockedErr)
+			c, err := NewRemoteIndexCache(log.NewNopLogger(), client, nil)
 			assert.NoError(t, err)
 
 			// Store the postings expected before running the test.
@@ -416,7 +416,7 @@ func TestMemcachedIndexCache_FetchSeries(t *testing.T) {
 	}
 }
 
-func TestMemcachedIndexCache_FetchLabelNames(t *testing.T) {
+func TestRemoteIndexCache_FetchLabelNames(t *testing.T) {
 	t.Parallel()
 
 	// Init some data to conveniently define test cases later one.
@@ -460,7 +460,7 @@ func TestMemcachedIndexCache_FetchLabelNames(t *testing.T) {
 			expectedData: value1,
 			expectedOk:   true,
 		},
-		"should return no hit on memcached error": {
+		"should return no hit on remote cache error": {
 			setup: []mockedLabelNames{
 				{userID: user1, block: block1, matchers: matchers1, value: value1},
 				{userID: user1, block: block1, matchers: matchers2, value: value2},
@@ -477,8 +477,8 @@ func TestMemcachedIndexCache_FetchLabelNames(t *testing.T) {
 
 	for testName, testData := range tests {
 		t.Run(testName, func(t *testing.T) {
-			memcached := newMockedMemcachedClient(testData.mockedErr)
-			c, err := NewMemcachedIndexCache(log.NewNopLogger(), memcached, nil)
+			client := newMockedRemoteCacheClient(testData.mockedErr)
+			c, err := NewRemoteIndexCache(log.NewNopLogger(), client, nil)
 			assert.NoError(t, err)
 
 			// Store the postings expected before running the test.
@@ -507,7 +507,7 @@ func TestMemcachedIndexCache_FetchLabelNames(t *testing.T) {
 	}
 }
 
-func TestMemcachedIndexCache_FetchLabelValues(t *testing.T) {
+func TestRemoteIndexCache_FetchLabelValues(t *testing.T) {
 	t.Parallel()
 
 	// Init some data to conveniently define test cases later one.
@@ -557,7 +557,7 @@ func TestMemcachedIndexCache_FetchLabelValues(t *testing.T) {
 			expectedData:   value1,
 			expectedOk:     true,
 		},
-		"should return no hit on memcached error": {
+		"should return no hit on remote cache error": {
 			setup: []mockedLabelValues{
 				{userID: user1, block: block1, labelName: labelName1, matchers: matchers1, value: value1},
 				{userID: user1, block: block1, labelName: labelName2, matchers: matchers2, value: value2},
@@ -576,8 +576,8 @@ func TestMemcachedIndexCache_FetchLabelValues(t *testing.T) {
 
 	for testName, testData := range tests {
 		t.Run(testName, func(t *testing.T) {
-			memcached := newMockedMemcachedClient(testData.mockedErr)
-			c, err := NewMemcachedIndexCache(log.NewNopLogger(), memcached, nil)
+			client := newMockedRemoteCacheClient(testData.mockedErr)
+			c, err := NewRemoteIndexCache(log.NewNopLogger(), client, nil)
 			assert.NoError(t, err)
 
 			// Store the postings expected before running the test.
@@ -742,19 +742,19 @@ type mockedLabelValues struct {
 	value     []byte
 }
 
-type mockedMemcachedClient struct {
+type mockedRemoteCacheClient struct {
 	cache             map[string][]byte
 	mockedGetMultiErr error
 }
 
-func newMockedMemcachedClient(mockedGetMultiErr error) *mockedMemcachedClient {
-	return &mockedMemcachedClient{
+func newMockedRemoteCacheClient(mockedGetMultiErr error) *mockedRemoteCacheClient {
+	return &mockedRemoteCacheClient{
 		cache:             map[string][]byte{},
 		mockedGetMultiErr: mockedGetMultiErr,
 	}
 }
 
-func (c *mockedMemcachedClient) GetMulti(ctx context.Context, keys []string) map[string][]byte {
+func (c *mockedRemoteCacheClient) GetMulti(ctx context.Context, keys []string) map[string][]byte {
 	if c.mockedGetMultiErr != nil {
 		return nil
 	}
@@ -770,13 +770,13 @@ func (c *mockedMemcachedClient) GetMulti(ctx context.Context, keys []string) map
 	return hits
 }
 
-func (c *mockedMemcachedClient) SetAsync(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+func (c *mockedRemoteCacheClient) SetAsync(ctx context.Context, key string, value []byte, ttl time.Duration) error {
 	c.cache[key] = value
 
 	return nil
 }
 
-func (c *mockedMemcachedClient) Stop() {
+func (c *mockedRemoteCacheClient) Stop() {
 	// Nothing to do.
 }
 
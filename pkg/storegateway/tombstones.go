@@ -0,0 +1,281 @@
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package storegateway
+
+import (
+	"context"
+	"sort"
+
+	"github.com/gogo/protobuf/types"
+	"github.com/pkg/errors"
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/prometheus/prometheus/tsdb/chunkenc"
+	"github.com/prometheus/prometheus/tsdb/tombstones"
+	"github.com/thanos-io/thanos/pkg/store/hintspb"
+	"github.com/thanos-io/thanos/pkg/store/labelpb"
+	"github.com/thanos-io/thanos/pkg/store/storepb"
+
+	mimir_tsdb "github.com/grafana/mimir/pkg/storage/tsdb"
+)
+
+func filterTombstonesByTimeRange(all []*mimir_tsdb.Tombstone, mint, maxt int64) []*mimir_tsdb.Tombstone {
+	var filtered []*mimir_tsdb.Tombstone
+	for _, t := range all {
+		if t.Overlaps(mint, maxt) {
+			filtered = append(filtered, t)
+		}
+	}
+	return filtered
+}
+
+// labelNamesWithTombstones returns the label names of the series matching the request, excluding the series
+// deleted for the whole requested time range. The label names are resolved from the series, since the blocks
+// index doesn't know about the series deletion requests.
+func labelNamesWithTombstones(ctx context.Context, store *BucketStore, tombstones []*mimir_tsdb.Tombstone, req *storepb.LabelNamesRequest) (*storepb.LabelNamesResponse, error) {
+	var blockMatchers []storepb.LabelMatcher
+	if req.Hints != nil {
+		reqHints := &hintspb.LabelNamesRequestHints{}
+		if err := types.UnmarshalAny(req.Hints, reqHints); err != nil {
+			return nil, errors.Wrap(err, "unmarshal label names request hints")
+		}
+		blockMatchers = reqHints.BlockMatchers
+	}
+
+	matchers := req.Matchers
+	if len(matchers) == 0 {
+		matchers = []storepb.LabelMatcher{{Type: storepb.LabelMatcher_RE, Name: labels.MetricName, Value: ".+"}}
+	}
+
+	srv, err := seriesWithTombstones(ctx, store, tombstones, req.Start, req.End, matchers, blockMatchers)
+	if err != nil {
+		return nil, err
+	}
+
+	names := map[string]struct{}{}
+	for _, series := range srv.SeriesSet {
+		for _, l := range series.Labels {
+			names[l.Name] = struct{}{}
+		}
+	}
+
+	anyHints, err := types.MarshalAny(&hintspb.LabelNamesResponseHints{QueriedBlocks: srv.Hints.QueriedBlocks})
+	if err != nil {
+		return nil, errors.Wrap(err, "marshal label names response hints")
+	}
+
+	return &storepb.LabelNamesResponse{
+		Names:    sortedKeys(names),
+		Warnings: warningsToStrings(srv),
+		Hints:    anyHints,
+	}, nil
+}
+
+// labelValuesWithTombstones returns the label values of the series matching the request, excluding the series
+// deleted for the whole requested time range, like labelNamesWithTombstones.
+func labelValuesWithTombstones(ctx context.Context, store *BucketStore, tombstones []*mimir_tsdb.Tombstone, req *storepb.LabelValuesRequest) (*storepb.LabelValuesResponse, error) {
+	var blockMatchers []storepb.LabelMatcher
+	if req.Hints != nil {
+		reqHints := &hintspb.LabelValuesRequestHints{}
+		if err := types.UnmarshalAny(req.Hints, reqHints); err != nil {
+			return nil, errors.Wrap(err, "unmarshal label values request hints")
+		}
+		blockMatchers = reqHints.BlockMatchers
+	}
+
+	matchers := append([]storepb.LabelMatcher{{Type: storepb.LabelMatcher_RE, Name: req.Label, Value: ".+"}}, req.Matchers...)
+
+	srv, err := seriesWithTombstones(ctx, store, tombstones, req.Start, req.End, matchers, blockMatchers)
+	if err != nil {
+		return nil, err
+	}
+
+	values := map[string]struct{}{}
+	for _, series := range srv.SeriesSet {
+		for _, l := range series.Labels {
+			if l.Name == req.Label {
+				values[l.Value] = struct{}{}
+			}
+		}
+	}
+
+	anyHints, err := types.MarshalAny(&hintspb.LabelValuesResponseHints{QueriedBlocks: srv.Hints.QueriedBlocks})
+	if err != nil {
+		return nil, errors.Wrap(err, "marshal label values response hints")
+	}
+
+	return &storepb.LabelValuesResponse{
+		Values:   sortedKeys(values),
+		Warnings: warningsToStrings(srv),
+		Hints:    anyHints,
+	}, nil
+}
+
+// seriesWithTombstones returns the labels of the series matching the matchers, without the series deleted
+// for the whole time range.
+func seriesWithTombstones(ctx context.Context, store *BucketStore, tombstones []*mimir_tsdb.Tombstone, mint, maxt int64, matchers, blockMatchers []storepb.LabelMatcher) (*bucketStoreSeriesServer, error) {
+	req := &storepb.SeriesRequest{
+		MinTime:    mint,
+		MaxTime:    maxt,
+		Matchers:   matchers,
+		SkipChunks: true,
+	}
+
+	if len(blockMatchers) > 0 {
+		anyHints, err := types.MarshalAny(&hintspb.SeriesRequestHints{BlockMatchers: blockMatchers})
+		if err != nil {
+			return nil, errors.Wrap(err, "marshal series request hints")
+		}
+		req.Hints = anyHints
+	}
+
+	srv := newBucketStoreSeriesServer(ctx)
+	if err := store.Series(req, newTombstonesSeriesServer(srv, tombstones, req)); err != nil {
+		return nil, err
+	}
+	return srv, nil
+}
+
+func warningsToStrings(srv *bucketStoreSeriesServer) []string {
+	var warnings []string
+	for _, w := range srv.Warnings {
+		warnings = append(warnings, w.Error())
+	}
+	return warnings
+}
+
+func sortedKeys(m map[string]struct{}) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// tombstonesSeriesServer removes the samples matching the series deletion requests from the series
+// sent to the client. Series whose samples have been deleted for the whole requested time range are
+// not sent at all.
+type tombstonesSeriesServer struct {
+	storepb.Store_SeriesServer
+
+	tombstones []*mimir_tsdb.Tombstone
+	mint, maxt int64
+	skipChunks bool
+}
+
+func newTombstonesSeriesServer(srv storepb.Store_SeriesServer, tombstones []*mimir_tsdb.Tombstone, req *storepb.SeriesRequest) *tombstonesSeriesServer {
+	return &tombstonesSeriesServer{
+		Store_SeriesServer: srv,
+		tombstones:         tombstones,
+		mint:               req.MinTime,
+		maxt:               req.MaxTime,
+		skipChunks:         req.SkipChunks,
+	}
+}
+
+func (s *tombstonesSeriesServer) Send(resp *storepb.SeriesResponse) error {
+	series := resp.GetSeries()
+	if series == nil {
+		return s.Store_SeriesServer.Send(resp)
+	}
+
+	lbls := labelpb.ZLabelsToPromLabels(series.Labels)
+
+	var intervals tombstones.Intervals
+	for _, t := range s.tombstones {
+		if t.Matches(lbls) {
+			intervals = intervals.Add(t.Interval())
+		}
+	}
+
+	if len(intervals) == 0 {
+		return s.Store_SeriesServer.Send(resp)
+	}
+	if (tombstones.Interval{Mint: s.mint, Maxt: s.maxt}).IsSubrange(intervals) {
+		return nil
+	}
+
+	// Without chunks we can only tell whether the series has been deleted for the whole time range.
+	if s.skipChunks {
+		return s.Store_SeriesServer.Send(resp)
+	}
+
+	chunks, err := deleteChunksIntervals(series.Chunks, intervals)
+	if err != nil {
+		return err
+	}
+	if len(chunks) == 0 {
+		return nil
+	}
+
+	series.Chunks = chunks
+	return s.Store_SeriesServer.Send(resp)
+}
+
+// deleteChunksIntervals returns the chunks without the samples within the deleted intervals.
+// The chunks overlapping with the intervals are re-encoded, and the chunks left empty are removed.
+func deleteChunksIntervals(chunks []storepb.AggrChunk, intervals tombstones.Intervals) ([]storepb.AggrChunk, error) {
+	result := make([]storepb.AggrChunk, 0, len(chunks))
+
+	for _, c := range chunks {
+		chunkInterval := tombstones.Interval{Mint: c.MinTime, Maxt: c.MaxTime}
+		if chunkInterval.IsSubrange(intervals) {
+			continue
+		}
+		if !overlapsAny(chunkInterval, intervals) {
+			result = append(result, c)
+			continue
+		}
+
+		if c.Raw == nil || c.Raw.Type != storepb.Chunk_XOR {
+			return nil, errors.New("unsupported chunk encoding")
+		}
+		src, err := chunkenc.FromData(chunkenc.EncXOR, c.Raw.Data)
+		if err != nil {
+			return nil, errors.Wrap(err, "decode chunk")
+		}
+
+		dst := chunkenc.NewXORChunk()
+		app, err := dst.Appender()
+		if err != nil {
+			return nil, err
+		}
+
+		minT, maxT := int64(0), int64(0)
+		it := src.Iterator(nil)
+		for it.Next() {
+			ts, v := it.At()
+			if (tombstones.Interval{Mint: ts, Maxt: ts}).IsSubrange(intervals) {
+				continue
+			}
+			if dst.NumSamples() == 0 {
+				minT = ts
+			}
+			maxT = ts
+			app.Append(ts, v)
+		}
+		if err := it.Err(); err != nil {
+			return nil, errors.Wrap(err, "iterate chunk")
+		}
+
+		if dst.NumSamples() == 0 {
+			continue
+		}
+		result = append(result, storepb.AggrChunk{
+			MinTime: minT,
+			MaxTime: maxT,
+			Raw:     &storepb.Chunk{Type: storepb.Chunk_XOR, Data: dst.Bytes()},
+		})
+	}
+
+	return result, nil
+}
+
+func overlapsAny(interval tombstones.Interval, intervals tombstones.Intervals) bool {
+	for _, i := range intervals {
+		if interval.Mint <= i.Maxt && i.Mint <= interval.Maxt {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,114 @@
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package storegateway
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/prometheus/prometheus/tsdb/chunkenc"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/thanos-io/thanos/pkg/store/labelpb"
+	"github.com/thanos-io/thanos/pkg/store/storepb"
+
+	mimir_tsdb "github.com/grafana/mimir/pkg/storage/tsdb"
+)
+
+func TestTombstonesSeriesServer(t *testing.T) {
+	newChunk := func(minT, maxT int64) storepb.AggrChunk {
+		c := chunkenc.NewXORChunk()
+		app, err := c.Appender()
+		require.NoError(t, err)
+		for ts := minT; ts <= maxT; ts++ {
+			app.Append(ts, float64(ts))
+		}
+		return storepb.AggrChunk{MinTime: minT, MaxTime: maxT, Raw: &storepb.Chunk{Type: storepb.Chunk_XOR, Data: c.Bytes()}}
+	}
+
+	newSeries := func(metric string, chunks ...storepb.AggrChunk) *storepb.Series {
+		return &storepb.Series{
+			Labels: labelpb.ZLabelsFromPromLabels(labels.FromStrings(labels.MetricName, metric)),
+			Chunks: chunks,
+		}
+	}
+
+	readSamples := func(chunks []storepb.AggrChunk) (timestamps []int64) {
+		for _, c := range chunks {
+			chk, err := chunkenc.FromData(chunkenc.EncXOR, c.Raw.Data)
+			require.NoError(t, err)
+			it := chk.Iterator(nil)
+			for it.Next() {
+				ts, _ := it.At()
+				timestamps = append(timestamps, ts)
+			}
+			require.NoError(t, it.Err())
+		}
+		return timestamps
+	}
+
+	newTombstone := func(start, end int64, selector string) *mimir_tsdb.Tombstone {
+		tombstone, err := mimir_tsdb.NewTombstone(time.Now(), start, end, []string{selector})
+		require.NoError(t, err)
+		return tombstone
+	}
+
+	tests := map[string]struct {
+		tombstones         []*mimir_tsdb.Tombstone
+		skipChunks         bool
+		input              *storepb.Series
+		expectedSent       bool
+		expectedTimestamps []int64
+	}{
+		"series not matching any request": {
+			tombstones:         []*mimir_tsdb.Tombstone{newTombstone(0, 100, "other")},
+			input:              newSeries("up", newChunk(0, 4)),
+			expectedSent:       true,
+			expectedTimestamps: []int64{0, 1, 2, 3, 4},
+		},
+		"series deleted for the whole requested time range": {
+			tombstones: []*mimir_tsdb.Tombstone{newTombstone(0, 100, "up")},
+			input:      newSeries("up", newChunk(0, 4)),
+		},
+		"series partially deleted": {
+			tombstones:         []*mimir_tsdb.Tombstone{newTombstone(2, 3, "up"), newTombstone(5, 9, "up")},
+			input:              newSeries("up", newChunk(0, 4), newChunk(5, 9), newChunk(10, 12)),
+			expectedSent:       true,
+			expectedTimestamps: []int64{0, 1, 4, 10, 11, 12},
+		},
+		"series whose chunks have all been deleted": {
+			tombstones: []*mimir_tsdb.Tombstone{newTombstone(0, 9, "up")},
+			input:      newSeries("up", newChunk(0, 4), newChunk(5, 9)),
+		},
+		"series partially deleted without chunks": {
+			tombstones:   []*mimir_tsdb.Tombstone{newTombstone(2, 3, "up")},
+			skipChunks:   true,
+			input:        newSeries("up"),
+			expectedSent: true,
+		},
+	}
+
+	for testName, testData := range tests {
+		t.Run(testName, func(t *testing.T) {
+			srv := newBucketStoreSeriesServer(context.Background())
+			req := &storepb.SeriesRequest{MinTime: 0, MaxTime: 20, SkipChunks: testData.skipChunks}
+
+			require.NoError(t, newTombstonesSeriesServer(srv, testData.tombstones, req).Send(storepb.NewSeriesResponse(testData.input)))
+
+			if !testData.expectedSent {
+				assert.Empty(t, srv.SeriesSet)
+				return
+			}
+
+			require.Len(t, srv.SeriesSet, 1)
+			assert.Equal(t, testData.expectedTimestamps, readSamples(srv.SeriesSet[0].Chunks))
+			for _, c := range srv.SeriesSet[0].Chunks {
+				timestamps := readSamples([]storepb.AggrChunk{c})
+				assert.Equal(t, timestamps[0], c.MinTime)
+				assert.Equal(t, timestamps[len(timestamps)-1], c.MaxTime)
+			}
+		})
+	}
+}
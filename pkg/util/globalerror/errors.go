@@ -23,7 +23,11 @@ const (
 	SeriesWithDuplicateLabelNames ID = "duplicate-label-names"
 	SeriesLabelsNotSorted         ID = "labels-not-sorted"
 	SampleTooFarInFuture          ID = "too-far-in-future"
+	SeriesMissingRequiredLabel    ID = "missing-required-label"
+	SeriesLabelValueNotAllowed    ID = "label-value-not-allowed"
 	MaxSeriesPerMetric            ID = "max-series-per-metric"
+	MaxLabelValuesPerLabelName    ID = "max-label-values-per-label-name"
+	MaxSeriesPerLabelValue        ID = "max-series-per-label-value"
 	MaxMetadataPerMetric          ID = "max-metadata-per-metric"
 	MaxSeriesPerUser              ID = "max-series-per-user"
 	MaxMetadataPerUser            ID = "max-metadata-per-user"
@@ -48,10 +52,13 @@ const (
 	MetricMetadataHelpTooLong       ID = "help-too-long"
 	MetricMetadataUnitTooLong       ID = "unit-too-long"
 
-	MaxQueryLength       ID = "max-query-length"
-	RequestRateLimited   ID = "tenant-max-request-rate"
-	IngestionRateLimited ID = "tenant-max-ingestion-rate"
-	TooManyHAClusters    ID = "tenant-too-many-ha-clusters"
+	MaxQueryLength            ID = "max-query-length"
+	QueryBlocked              ID = "query-blocked"
+	RequestRateLimited        ID = "tenant-max-request-rate"
+	IngestionRateLimited      ID = "tenant-max-ingestion-rate"
+	IngestionBytesRateLimited ID = "tenant-max-ingestion-bytes-rate"
+	NewSeriesRateLimited      ID = "tenant-max-new-series-rate"
+	TooManyHAClusters         ID = "tenant-too-many-ha-clusters"
 
 	SampleTimestampTooOld    ID = "sample-timestamp-too-old"
 	SampleOutOfOrder         ID = "sample-out-of-order"
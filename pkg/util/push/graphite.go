@@ -0,0 +1,186 @@
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package push
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"math"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/grafana/dskit/tenant"
+	"github.com/weaveworks/common/middleware"
+
+	"github.com/grafana/mimir/pkg/mimirpb"
+	"github.com/grafana/mimir/pkg/util/log"
+	"github.com/grafana/mimir/pkg/util/validation"
+)
+
+const graphiteParseError = "graphite_parse_error"
+
+// GraphiteLimits are the per-tenant limits used to convert Graphite metrics.
+type GraphiteLimits interface {
+	GraphiteMappingRules(userID string) []validation.GraphiteMappingRule
+}
+
+// GraphiteHandler is a http.Handler which accepts metrics in the Graphite plaintext protocol, one per line:
+// <path> <value> [<timestamp>]. The path can be tagged, like <path>;<tag>=<value>;<tag>=<value>, in which
+// case the tags are converted to labels. The path is converted to a metric name and labels by the first
+// matching mapping rule of the tenant, or named after the path with invalid characters replaced otherwise.
+func GraphiteHandler(
+	maxRecvMsgSize int,
+	sourceIPs *middleware.SourceIPExtractor,
+	limits GraphiteLimits,
+	push Func,
+) http.Handler {
+	return handler(maxRecvMsgSize, sourceIPs, false, push, func(ctx context.Context, r *http.Request, maxRecvMsgSize int, _ []byte, req *mimirpb.PreallocWriteRequest) ([]byte, error) {
+		userID, err := tenant.TenantID(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		body, err := readTextBody(r, maxRecvMsgSize)
+		if err != nil {
+			return nil, err
+		}
+
+		mapper := graphiteMapper(limits.GraphiteMappingRules(userID))
+		builder := newSeriesBuilder()
+		errs, dropped := parseGraphiteLines(body, mapper, time.Now(), builder)
+		req.Timeseries = builder.series
+
+		logger := log.WithContext(ctx, log.Logger)
+		return body, textParseErrors(ctx, logger, graphiteParseError, builder.samples, dropped, errs)
+	})
+}
+
+// parseGraphiteLines parses the lines of the Graphite plaintext protocol in body, and adds their samples to the builder.
+// The lines which can't be parsed are skipped, and their errors and number are returned.
+func parseGraphiteLines(body []byte, mapper graphiteMapper, now time.Time, builder *seriesBuilder) ([]error, int) {
+	var (
+		errs    []error
+		dropped int
+	)
+
+	for lineNum, line := range bytes.Split(body, []byte("\n")) {
+		line = bytes.TrimSpace(line)
+		if len(line) == 0 {
+			continue
+		}
+
+		lbls, timestampMs, value, err := parseGraphiteLine(string(line), mapper, now)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("line %d: %w", lineNum+1, err))
+			dropped++
+			continue
+		}
+		builder.add(lbls, timestampMs, value)
+	}
+
+	return errs, dropped
+}
+
+func parseGraphiteLine(line string, mapper graphiteMapper, now time.Time) ([]mimirpb.LabelAdapter, int64, float64, error) {
+	parts := strings.Fields(line)
+	if len(parts) < 2 || len(parts) > 3 {
+		return nil, 0, 0, fmt.Errorf("expected <path> <value> [<timestamp>]")
+	}
+
+	value, err := strconv.ParseFloat(parts[1], 64)
+	if err != nil {
+		return nil, 0, 0, fmt.Errorf("invalid value: %w", err)
+	}
+
+	timestampMs := now.UnixMilli()
+	if len(parts) == 3 {
+		seconds, err := strconv.ParseFloat(parts[2], 64)
+		if err != nil {
+			return nil, 0, 0, fmt.Errorf("invalid timestamp: %w", err)
+		}
+		// Graphite uses -1 for the timestamps to be set on receipt.
+		if seconds != -1 {
+			timestampMs = int64(math.Round(seconds * 1000))
+		}
+	}
+
+	path, tags, err := parseGraphitePath(parts[0])
+	if err != nil {
+		return nil, 0, 0, err
+	}
+
+	return mapper.labels(path, tags), timestampMs, value, nil
+}
+
+// parseGraphitePath splits a tagged Graphite path into the path and the tags.
+func parseGraphitePath(taggedPath string) (string, map[string]string, error) {
+	parts := strings.Split(taggedPath, ";")
+	if parts[0] == "" {
+		return "", nil, fmt.Errorf("missing path")
+	}
+	if len(parts) == 1 {
+		return parts[0], nil, nil
+	}
+
+	tags := make(map[string]string, len(parts)-1)
+	for _, tag := range parts[1:] {
+		nameValue := strings.SplitN(tag, "=", 2)
+		if len(nameValue) != 2 || nameValue[0] == "" || nameValue[1] == "" {
+			return "", nil, fmt.Errorf("invalid tag %q", tag)
+		}
+		tags[nameValue[0]] = nameValue[1]
+	}
+	return parts[0], tags, nil
+}
+
+// graphiteMapper converts Graphite paths to metric names and labels, using the mapping rules of a tenant.
+type graphiteMapper []validation.GraphiteMappingRule
+
+// labels returns the labels of the series with the given path and tags. The labels of the mapping
+// rule take precedence over the tags with the same name.
+func (m graphiteMapper) labels(path string, tags map[string]string) []mimirpb.LabelAdapter {
+	lbls := make([]mimirpb.LabelAdapter, 0, len(tags)+1)
+
+	var rule *validation.GraphiteMappingRule
+	var match *regexp.Regexp
+	var submatches []int
+	for i := range m {
+		// The rules are compiled when loading the limits, so a rule without regexp is not expected.
+		if match = m[i].MatchRegexp(); match == nil {
+			continue
+		}
+		if submatches = match.FindStringSubmatchIndex(path); submatches != nil {
+			rule = &m[i]
+			break
+		}
+	}
+
+	if rule == nil {
+		lbls = append(lbls, mimirpb.LabelAdapter{Name: "__name__", Value: sanitizeName(path)})
+		for name, value := range tags {
+			lbls = append(lbls, mimirpb.LabelAdapter{Name: sanitizeName(name), Value: value})
+		}
+		return lbls
+	}
+
+	expand := func(template string) string {
+		return string(match.ExpandString(nil, template, path, submatches))
+	}
+
+	lbls = append(lbls, mimirpb.LabelAdapter{Name: "__name__", Value: sanitizeName(expand(rule.Name))})
+	for name, value := range tags {
+		if _, ok := rule.Labels[sanitizeName(name)]; !ok {
+			lbls = append(lbls, mimirpb.LabelAdapter{Name: sanitizeName(name), Value: value})
+		}
+	}
+	for name, template := range rule.Labels {
+		if value := expand(template); value != "" {
+			lbls = append(lbls, mimirpb.LabelAdapter{Name: name, Value: value})
+		}
+	}
+	return lbls
+}
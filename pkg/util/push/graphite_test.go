@@ -0,0 +1,112 @@
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package push
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/mimir/pkg/mimirpb"
+	"github.com/grafana/mimir/pkg/util/validation"
+)
+
+func TestParseGraphiteLines(t *testing.T) {
+	now := time.UnixMilli(5000)
+
+	var rules []validation.GraphiteMappingRule
+	for _, r := range []struct {
+		match, name string
+		labels      map[string]string
+	}{
+		{match: "servers.*.cpu.*", name: "server_cpu_${2}_seconds", labels: map[string]string{"host": "$1"}},
+		{match: "apps.*.requests", name: "app_requests_total", labels: map[string]string{"app": "$1", "env": "prod"}},
+	} {
+		rule, err := validation.NewGraphiteMappingRule(r.match, r.name, r.labels)
+		require.NoError(t, err)
+		rules = append(rules, rule)
+	}
+
+	tests := map[string]struct {
+		body           string
+		expectedSeries []testSeries
+		expectedErrors int
+	}{
+		"metrics not matching any rule are named after their path": {
+			body: "foo.bar-baz.count 1.5 10\nfoo.bar-baz.count 2 20",
+			expectedSeries: []testSeries{
+				newTestSeries(`{__name__="foo_bar_baz_count"}`, mimirpb.Sample{TimestampMs: 10000, Value: 1.5}, mimirpb.Sample{TimestampMs: 20000, Value: 2}),
+			},
+		},
+		"metrics matching a rule are mapped to name and labels": {
+			body: "servers.web-1.cpu.user 3 10\napps.checkout.requests 4",
+			expectedSeries: []testSeries{
+				newTestSeries(`{__name__="server_cpu_user_seconds", host="web-1"}`, mimirpb.Sample{TimestampMs: 10000, Value: 3}),
+				newTestSeries(`{__name__="app_requests_total", app="checkout", env="prod"}`, mimirpb.Sample{TimestampMs: 5000, Value: 4}),
+			},
+		},
+		"rules match whole nodes only": {
+			body: "servers.web.1.cpu.user 3 -1",
+			expectedSeries: []testSeries{
+				newTestSeries(`{__name__="servers_web_1_cpu_user"}`, mimirpb.Sample{TimestampMs: 5000, Value: 3}),
+			},
+		},
+		"tags are converted to labels, and the rule labels take precedence": {
+			body: "apps.checkout.requests;env=dev;region=eu-west 4 10\nfoo.count;dc-name=a 1 10",
+			expectedSeries: []testSeries{
+				newTestSeries(`{__name__="app_requests_total", app="checkout", env="prod", region="eu-west"}`, mimirpb.Sample{TimestampMs: 10000, Value: 4}),
+				newTestSeries(`{__name__="foo_count", dc_name="a"}`, mimirpb.Sample{TimestampMs: 10000, Value: 1}),
+			},
+		},
+		"invalid lines are skipped": {
+			body: "foo\nfoo abc 10\nfoo 1 abc\nfoo;tag 1 10\nfoo 1 2 3\nbar 1 10",
+			expectedSeries: []testSeries{
+				newTestSeries(`{__name__="bar"}`, mimirpb.Sample{TimestampMs: 10000, Value: 1}),
+			},
+			expectedErrors: 5,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			builder := newSeriesBuilder()
+			errs, dropped := parseGraphiteLines([]byte(tc.body), graphiteMapper(rules), now, builder)
+			assert.Len(t, errs, tc.expectedErrors)
+			assert.Equal(t, tc.expectedErrors, dropped)
+			assert.Equal(t, tc.expectedSeries, toTestSeries(builder.series))
+		})
+	}
+}
+
+func TestGraphiteHandler(t *testing.T) {
+	rule, err := validation.NewGraphiteMappingRule("servers.*.load", "server_load", map[string]string{"host": "$1"})
+	require.NoError(t, err)
+	limits := graphiteLimitsMock{"test": {rule}}
+
+	pushed := false
+	handler := GraphiteHandler(1024, nil, limits, func(_ context.Context, req *mimirpb.WriteRequest, cleanup func()) (*mimirpb.WriteResponse, error) {
+		defer cleanup()
+		pushed = true
+		assert.Equal(t, []testSeries{
+			newTestSeries(`{__name__="server_load", host="web-1"}`, mimirpb.Sample{TimestampMs: 10000, Value: 0.5}),
+		}, toTestSeries(req.Timeseries))
+		return &mimirpb.WriteResponse{}, nil
+	})
+
+	resp := httptest.NewRecorder()
+	handler.ServeHTTP(resp, newTextRequest(t, "/api/v1/push/graphite", strings.NewReader("servers.web-1.load 0.5 10\n"), ""))
+	require.Equal(t, http.StatusOK, resp.Code)
+	require.True(t, pushed)
+}
+
+type graphiteLimitsMock map[string][]validation.GraphiteMappingRule
+
+func (m graphiteLimitsMock) GraphiteMappingRules(userID string) []validation.GraphiteMappingRule {
+	return m[userID]
+}
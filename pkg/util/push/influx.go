@@ -0,0 +1,246 @@
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package push
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/weaveworks/common/middleware"
+
+	"github.com/grafana/mimir/pkg/mimirpb"
+	"github.com/grafana/mimir/pkg/util/log"
+)
+
+const influxParseError = "influx_parse_error"
+
+// InfluxHandler is a http.Handler which accepts metrics in the InfluxDB line protocol, like the InfluxDB /write API.
+// Each field of a point is converted to a series named <measurement>_<field>, or just <measurement> if the field
+// is named "value", and labeled with the tags of the point. String fields are ignored.
+func InfluxHandler(
+	maxRecvMsgSize int,
+	sourceIPs *middleware.SourceIPExtractor,
+	push Func,
+) http.Handler {
+	return handler(maxRecvMsgSize, sourceIPs, false, push, func(ctx context.Context, r *http.Request, maxRecvMsgSize int, _ []byte, req *mimirpb.PreallocWriteRequest) ([]byte, error) {
+		precision, err := influxPrecision(r.URL.Query().Get("precision"))
+		if err != nil {
+			return nil, err
+		}
+
+		body, err := readTextBody(r, maxRecvMsgSize)
+		if err != nil {
+			return nil, err
+		}
+
+		builder := newSeriesBuilder()
+		errs, dropped := parseInfluxLines(body, precision, time.Now(), builder)
+		req.Timeseries = builder.series
+
+		logger := log.WithContext(ctx, log.Logger)
+		return body, textParseErrors(ctx, logger, influxParseError, builder.samples, dropped, errs)
+	})
+}
+
+// influxPrecision returns the duration of a unit of the timestamps with the given precision.
+// Both the InfluxDB v1 and v2 precisions are supported.
+func influxPrecision(precision string) (time.Duration, error) {
+	switch precision {
+	case "", "n", "ns":
+		return time.Nanosecond, nil
+	case "u", "us", "µ":
+		return time.Microsecond, nil
+	case "ms":
+		return time.Millisecond, nil
+	case "s":
+		return time.Second, nil
+	case "m":
+		return time.Minute, nil
+	case "h":
+		return time.Hour, nil
+	default:
+		return 0, fmt.Errorf("unsupported precision: %s", precision)
+	}
+}
+
+// parseInfluxLines parses the lines of the InfluxDB line protocol in body, and adds their samples to the builder.
+// The lines which can't be parsed are skipped, and their errors and number of samples are returned.
+func parseInfluxLines(body []byte, precision time.Duration, now time.Time, builder *seriesBuilder) ([]error, int) {
+	var (
+		errs    []error
+		dropped int
+	)
+
+	for lineNum, line := range bytes.Split(body, []byte("\n")) {
+		line = bytes.TrimSpace(line)
+		if len(line) == 0 || line[0] == '#' {
+			continue
+		}
+
+		p, err := parseInfluxLine(line)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("line %d: %w", lineNum+1, err))
+			dropped++
+			continue
+		}
+
+		timestampMs := now.UnixMilli()
+		if p.timestamp != nil {
+			timestampMs = time.Duration(*p.timestamp * int64(precision)).Milliseconds()
+		}
+
+		for _, f := range p.fields {
+			name := p.measurement
+			if f.key != "value" {
+				name += "_" + f.key
+			}
+
+			lbls := make([]mimirpb.LabelAdapter, 0, len(p.tags)+1)
+			lbls = append(lbls, mimirpb.LabelAdapter{Name: "__name__", Value: sanitizeName(name)})
+			for _, tag := range p.tags {
+				lbls = append(lbls, mimirpb.LabelAdapter{Name: sanitizeName(tag.Name), Value: tag.Value})
+			}
+			builder.add(lbls, timestampMs, f.value)
+		}
+	}
+
+	return errs, dropped
+}
+
+type influxField struct {
+	key   string
+	value float64
+}
+
+type influxPoint struct {
+	measurement string
+	tags        []mimirpb.LabelAdapter
+	fields      []influxField
+	timestamp   *int64
+}
+
+// parseInfluxLine parses a single line of the InfluxDB line protocol, whose syntax is:
+// <measurement>[,<tag_key>=<tag_value>...] <field_key>=<field_value>[,<field_key>=<field_value>...] [<timestamp>]
+func parseInfluxLine(line []byte) (influxPoint, error) {
+	var p influxPoint
+
+	measurement, pos := scanInfluxToken(line, 0, ", ")
+	if measurement == "" {
+		return p, fmt.Errorf("missing measurement")
+	}
+	p.measurement = measurement
+
+	for pos < len(line) && line[pos] == ',' {
+		var key, value string
+		key, pos = scanInfluxToken(line, pos+1, "=, ")
+		if pos >= len(line) || line[pos] != '=' || key == "" {
+			return p, fmt.Errorf("invalid tag")
+		}
+		value, pos = scanInfluxToken(line, pos+1, ", ")
+		if value == "" {
+			return p, fmt.Errorf("missing value of tag %s", key)
+		}
+		p.tags = append(p.tags, mimirpb.LabelAdapter{Name: key, Value: value})
+	}
+
+	if pos >= len(line) || line[pos] != ' ' {
+		return p, fmt.Errorf("missing fields")
+	}
+
+	for {
+		var key string
+		key, pos = scanInfluxToken(line, pos+1, "=, ")
+		if pos >= len(line) || line[pos] != '=' || key == "" {
+			return p, fmt.Errorf("invalid field")
+		}
+		pos++
+
+		if pos < len(line) && line[pos] == '"' {
+			// String fields can't be converted to samples, so they're skipped.
+			end, ok := skipInfluxString(line, pos)
+			if !ok {
+				return p, fmt.Errorf("unterminated string value of field %s", key)
+			}
+			pos = end
+		} else {
+			var raw string
+			raw, pos = scanInfluxToken(line, pos, ", ")
+			value, err := parseInfluxFieldValue(raw)
+			if err != nil {
+				return p, fmt.Errorf("invalid value of field %s: %w", key, err)
+			}
+			p.fields = append(p.fields, influxField{key: key, value: value})
+		}
+
+		if pos >= len(line) || line[pos] != ',' {
+			break
+		}
+	}
+
+	if rest := strings.TrimSpace(string(line[pos:])); rest != "" {
+		timestamp, err := strconv.ParseInt(rest, 10, 64)
+		if err != nil {
+			return p, fmt.Errorf("invalid timestamp: %w", err)
+		}
+		p.timestamp = &timestamp
+	}
+
+	return p, nil
+}
+
+// scanInfluxToken returns the unescaped token starting at pos and ending right before the first unescaped
+// byte in stops, along with the position of that byte. A backslash escapes the bytes in stops and itself.
+func scanInfluxToken(line []byte, pos int, stops string) (string, int) {
+	var sb strings.Builder
+	for ; pos < len(line); pos++ {
+		c := line[pos]
+		if c == '\\' && pos+1 < len(line) && (line[pos+1] == '\\' || strings.IndexByte(stops, line[pos+1]) >= 0) {
+			pos++
+			sb.WriteByte(line[pos])
+			continue
+		}
+		if strings.IndexByte(stops, c) >= 0 {
+			break
+		}
+		sb.WriteByte(c)
+	}
+	return sb.String(), pos
+}
+
+// skipInfluxString returns the position right after the double-quoted string starting at pos.
+func skipInfluxString(line []byte, pos int) (int, bool) {
+	for pos++; pos < len(line); pos++ {
+		switch line[pos] {
+		case '\\':
+			pos++
+		case '"':
+			return pos + 1, true
+		}
+	}
+	return pos, false
+}
+
+func parseInfluxFieldValue(raw string) (float64, error) {
+	switch raw {
+	case "t", "T", "true", "True", "TRUE":
+		return 1, nil
+	case "f", "F", "false", "False", "FALSE":
+		return 0, nil
+	}
+
+	switch {
+	case strings.HasSuffix(raw, "i"):
+		v, err := strconv.ParseInt(strings.TrimSuffix(raw, "i"), 10, 64)
+		return float64(v), err
+	case strings.HasSuffix(raw, "u"):
+		v, err := strconv.ParseUint(strings.TrimSuffix(raw, "u"), 10, 64)
+		return float64(v), err
+	default:
+		return strconv.ParseFloat(raw, 64)
+	}
+}
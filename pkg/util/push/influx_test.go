@@ -0,0 +1,170 @@
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package push
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/weaveworks/common/user"
+
+	"github.com/grafana/mimir/pkg/mimirpb"
+)
+
+func TestParseInfluxLines(t *testing.T) {
+	now := time.UnixMilli(1000)
+
+	tests := map[string]struct {
+		body           string
+		precision      time.Duration
+		expectedSeries []testSeries
+		expectedErrors int
+	}{
+		"point without tags and timestamp": {
+			body:      "cpu value=1.5",
+			precision: time.Nanosecond,
+			expectedSeries: []testSeries{
+				newTestSeries(`{__name__="cpu"}`, mimirpb.Sample{TimestampMs: 1000, Value: 1.5}),
+			},
+		},
+		"point with tags, multiple fields and timestamp": {
+			body:      "cpu,host=server01,region=us-west usage_idle=10,usage_user=2i,online=true,status=\"ok, fine\" 1465839830100400200",
+			precision: time.Nanosecond,
+			expectedSeries: []testSeries{
+				newTestSeries(`{__name__="cpu_usage_idle", host="server01", region="us-west"}`, mimirpb.Sample{TimestampMs: 1465839830100, Value: 10}),
+				newTestSeries(`{__name__="cpu_usage_user", host="server01", region="us-west"}`, mimirpb.Sample{TimestampMs: 1465839830100, Value: 2}),
+				newTestSeries(`{__name__="cpu_online", host="server01", region="us-west"}`, mimirpb.Sample{TimestampMs: 1465839830100, Value: 1}),
+			},
+		},
+		"escaped characters and invalid names": {
+			body:      `disk\ io,mount\,point=/data\ 1,dev-name=sda used\=bytes=5u 10`,
+			precision: time.Second,
+			expectedSeries: []testSeries{
+				newTestSeries(`{__name__="disk_io_used_bytes", dev_name="sda", mount_point="/data 1"}`, mimirpb.Sample{TimestampMs: 10000, Value: 5}),
+			},
+		},
+		"samples of the same series are grouped": {
+			body:      "cpu,host=a value=1 1\ncpu,host=b value=2 1\n\n# comment\ncpu,host=a value=3 2",
+			precision: time.Millisecond,
+			expectedSeries: []testSeries{
+				newTestSeries(`{__name__="cpu", host="a"}`, mimirpb.Sample{TimestampMs: 1, Value: 1}, mimirpb.Sample{TimestampMs: 2, Value: 3}),
+				newTestSeries(`{__name__="cpu", host="b"}`, mimirpb.Sample{TimestampMs: 1, Value: 2}),
+			},
+		},
+		"invalid lines are skipped": {
+			body:      "cpu\ncpu,host value=1\ncpu value=abc\ncpu value=1 abc\ncpu value=\"unterminated\nmem value=4",
+			precision: time.Nanosecond,
+			expectedSeries: []testSeries{
+				newTestSeries(`{__name__="mem"}`, mimirpb.Sample{TimestampMs: 1000, Value: 4}),
+			},
+			expectedErrors: 5,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			builder := newSeriesBuilder()
+			errs, dropped := parseInfluxLines([]byte(tc.body), tc.precision, now, builder)
+			assert.Len(t, errs, tc.expectedErrors)
+			assert.Equal(t, tc.expectedErrors, dropped)
+			assert.Equal(t, tc.expectedSeries, toTestSeries(builder.series))
+		})
+	}
+}
+
+func TestInfluxHandler(t *testing.T) {
+	const body = "cpu,host=a value=1 1\ncpu,host=b value=2 1\n"
+
+	gzipped := bytes.Buffer{}
+	gz := gzip.NewWriter(&gzipped)
+	_, err := gz.Write([]byte(body))
+	require.NoError(t, err)
+	require.NoError(t, gz.Close())
+
+	tests := map[string]struct {
+		req                *http.Request
+		maxRecvMsgSize     int
+		expectedStatusCode int
+	}{
+		"uncompressed request": {
+			req:                newTextRequest(t, "/api/v1/push/influx/write?precision=s", strings.NewReader(body), ""),
+			maxRecvMsgSize:     1024,
+			expectedStatusCode: http.StatusOK,
+		},
+		"gzip compressed request": {
+			req:                newTextRequest(t, "/api/v1/push/influx/write?precision=s", bytes.NewReader(gzipped.Bytes()), "gzip"),
+			maxRecvMsgSize:     1024,
+			expectedStatusCode: http.StatusOK,
+		},
+		"request larger than the max size once decompressed": {
+			req:                newTextRequest(t, "/api/v1/push/influx/write?precision=s", bytes.NewReader(gzipped.Bytes()), "gzip"),
+			maxRecvMsgSize:     len(body) - 1,
+			expectedStatusCode: http.StatusBadRequest,
+		},
+		"unsupported precision": {
+			req:                newTextRequest(t, "/api/v1/push/influx/write?precision=d", strings.NewReader(body), ""),
+			maxRecvMsgSize:     1024,
+			expectedStatusCode: http.StatusBadRequest,
+		},
+		"no valid line": {
+			req:                newTextRequest(t, "/api/v1/push/influx/write", strings.NewReader("cpu"), ""),
+			maxRecvMsgSize:     1024,
+			expectedStatusCode: http.StatusBadRequest,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			pushed := false
+			handler := InfluxHandler(tc.maxRecvMsgSize, nil, func(_ context.Context, req *mimirpb.WriteRequest, cleanup func()) (*mimirpb.WriteResponse, error) {
+				defer cleanup()
+				pushed = true
+				require.Len(t, req.Timeseries, 2)
+				assert.Equal(t, mimirpb.API, req.Source)
+				assert.Equal(t, []mimirpb.Sample{{TimestampMs: 1000, Value: 1}}, req.Timeseries[0].Samples)
+				return &mimirpb.WriteResponse{}, nil
+			})
+
+			resp := httptest.NewRecorder()
+			handler.ServeHTTP(resp, tc.req)
+			assert.Equal(t, tc.expectedStatusCode, resp.Code)
+			assert.Equal(t, tc.expectedStatusCode == http.StatusOK, pushed)
+		})
+	}
+}
+
+// testSeries is a series with its labels formatted as a string, to easily compare the parsed series.
+type testSeries struct {
+	labels  string
+	samples []mimirpb.Sample
+}
+
+func newTestSeries(labels string, samples ...mimirpb.Sample) testSeries {
+	return testSeries{labels: labels, samples: samples}
+}
+
+func toTestSeries(series []mimirpb.PreallocTimeseries) []testSeries {
+	result := make([]testSeries, 0, len(series))
+	for _, s := range series {
+		result = append(result, newTestSeries(mimirpb.FromLabelAdaptersToLabels(s.Labels).String(), s.Samples...))
+	}
+	return result
+}
+
+func newTextRequest(t *testing.T, url string, body io.Reader, contentEncoding string) *http.Request {
+	req, err := http.NewRequest("POST", url, body)
+	require.NoError(t, err)
+	if contentEncoding != "" {
+		req.Header.Set("Content-Encoding", contentEncoding)
+	}
+	return req.WithContext(user.InjectOrgID(req.Context(), "test"))
+}
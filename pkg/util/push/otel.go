@@ -8,12 +8,15 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"strings"
 	"time"
 
 	kitlog "github.com/go-kit/log"
 	"github.com/go-kit/log/level"
+	"github.com/grafana/dskit/multierror"
 	"github.com/grafana/dskit/tenant"
 	"github.com/open-telemetry/opentelemetry-collector-contrib/pkg/translator/prometheusremotewrite"
+	"github.com/prometheus/prometheus/model/labels"
 	"github.com/prometheus/prometheus/prompb"
 	"github.com/weaveworks/common/middleware"
 	"go.opentelemetry.io/collector/pdata/pcommon"
@@ -39,6 +42,7 @@ func OTLPHandler(
 	maxRecvMsgSize int,
 	sourceIPs *middleware.SourceIPExtractor,
 	allowSkipLabelNameValidation bool,
+	limits OTLPLimits,
 	push Func,
 ) http.Handler {
 	return handler(maxRecvMsgSize, sourceIPs, allowSkipLabelNameValidation, push, func(ctx context.Context, r *http.Request, maxRecvMsgSize int, dst []byte, req *mimirpb.PreallocWriteRequest) ([]byte, error) {
@@ -84,7 +88,7 @@ func OTLPHandler(
 			return body, err
 		}
 
-		metrics, err := otelMetricsToTimeseries(ctx, logger, otlpReq.Metrics())
+		metrics, err := otelMetricsToTimeseries(ctx, logger, otlpReq.Metrics(), limits)
 		if err != nil {
 			return body, err
 		}
@@ -94,38 +98,71 @@ func OTLPHandler(
 	})
 }
 
-func otelMetricsToTimeseries(ctx context.Context, logger kitlog.Logger, md pmetric.Metrics) ([]mimirpb.PreallocTimeseries, error) {
-	tsMap, errs := prometheusremotewrite.FromMetrics(md, prometheusremotewrite.Settings{})
+func otelMetricsToTimeseries(ctx context.Context, logger kitlog.Logger, md pmetric.Metrics, limits OTLPLimits) ([]mimirpb.PreallocTimeseries, error) {
+	userID, err := tenant.TenantID(ctx)
+	if err != nil {
+		return nil, err
+	}
 
-	if errs != nil {
-		userID, err := tenant.TenantID(ctx)
-		if err != nil {
-			return nil, err
+	dropped := prepareOTelMetrics(userID, md, limits)
+	for reason, count := range dropped.counts {
+		validation.DiscardedSamples.WithLabelValues(reason, userID).Add(float64(count))
+	}
+	errs := multierror.New(dropped.errs...)
+
+	tsMap, convErrs := prometheusremotewrite.FromMetrics(md, prometheusremotewrite.Settings{})
+	if convErrs != nil {
+		if count := md.MetricCount() - len(tsMap); count > 0 {
+			validation.DiscardedSamples.WithLabelValues(otelParseError, userID).Add(float64(count))
 		}
+		errs.Add(convErrs)
+	}
 
-		dropped := md.MetricCount() - len(tsMap)
-		validation.DiscardedSamples.WithLabelValues(otelParseError, userID).Add(float64(dropped))
+	createTargetInfo := limits.OTelCreateTargetInfo(userID)
+
+	mimirTs := mimirpb.PreallocTimeseriesSliceFromPool()
+	translated := 0
+	for signature, promTs := range tsMap {
+		// The series created for the attributes of the resources are the only ones with the info type.
+		if strings.HasPrefix(signature, otelInfoSignaturePrefix) {
+			if !createTargetInfo {
+				continue
+			}
+			renameTargetInfo(promTs)
+		} else {
+			translated++
+		}
+		mimirTs = append(mimirTs, promToMimirTimeseries(promTs))
+	}
 
-		parseErrs := errs.Error()
+	if err := errs.Err(); err != nil {
+		parseErrs := err.Error()
 		if len(parseErrs) > maxErrMsgLen {
 			parseErrs = parseErrs[:maxErrMsgLen]
 		}
 
-		if len(tsMap) == 0 {
+		if translated == 0 {
+			mimirpb.ReuseSlice(mimirTs)
 			return nil, errors.New(parseErrs)
 		}
 
 		level.Warn(logger).Log("msg", "OTLP parse error", "err", parseErrs)
 	}
 
-	mimirTs := mimirpb.PreallocTimeseriesSliceFromPool()
-	for _, promTs := range tsMap {
-		mimirTs = append(mimirTs, promToMimirTimeseries(promTs))
-	}
-
 	return mimirTs, nil
 }
 
+// renameTargetInfo renames the series created for the attributes of an OTLP resource to target_info,
+// as defined by the OpenTelemetry specification, since the translation names it target.
+func renameTargetInfo(ts *prompb.TimeSeries) {
+	for i := range ts.Labels {
+		if ts.Labels[i].Name == labels.MetricName {
+			ts.Labels[i].Value = otelTargetInfoMetricName
+			return
+		}
+	}
+}
+
 func promToMimirTimeseries(promTs *prompb.TimeSeries) mimirpb.PreallocTimeseries {
 	labels := make([]mimirpb.LabelAdapter, 0, len(promTs.Labels))
 	for _, label := range promTs.Labels {
@@ -0,0 +1,248 @@
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package push
+
+import (
+	"context"
+	"math"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/weaveworks/common/user"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+
+	"github.com/grafana/mimir/pkg/mimirpb"
+	"github.com/grafana/mimir/pkg/util/validation"
+)
+
+func TestOTelMetricsToTimeseries(t *testing.T) {
+	ts := time.UnixMilli(10000)
+
+	tests := map[string]struct {
+		limits         otlpLimitsMock
+		metrics        func(md pmetric.Metrics)
+		expectedSeries []testSeries
+		expectedErr    bool
+		expectedDrops  map[string]float64
+	}{
+		"resource attributes are converted to target_info": {
+			limits: otlpLimitsMock{createTargetInfo: true},
+			metrics: func(md pmetric.Metrics) {
+				rm := newOTelResourceMetrics(md, "service.name", "checkout", "host.name", "web-1")
+				addOTelGauge(rm, "temperature", ts, 21.5, "room", "kitchen")
+			},
+			expectedSeries: []testSeries{
+				newTestSeries(`{__name__="target_info", host_name="web-1", job="checkout"}`, mimirpb.Sample{TimestampMs: 10000, Value: 1}),
+				newTestSeries(`{__name__="temperature", job="checkout", room="kitchen"}`, mimirpb.Sample{TimestampMs: 10000, Value: 21.5}),
+			},
+		},
+		"resource attributes are promoted to labels, and data point attributes take precedence": {
+			limits: otlpLimitsMock{promote: []string{"host.name", "room", "missing"}},
+			metrics: func(md pmetric.Metrics) {
+				rm := newOTelResourceMetrics(md, "service.name", "checkout", "host.name", "web-1", "room", "garage")
+				addOTelGauge(rm, "temperature", ts, 21.5, "room", "kitchen")
+			},
+			expectedSeries: []testSeries{
+				newTestSeries(`{__name__="temperature", host_name="web-1", job="checkout", room="kitchen"}`, mimirpb.Sample{TimestampMs: 10000, Value: 21.5}),
+			},
+		},
+		"exponential histograms are converted to histograms with explicit buckets": {
+			limits: otlpLimitsMock{},
+			metrics: func(md pmetric.Metrics) {
+				rm := newOTelResourceMetrics(md)
+				m := rm.ScopeMetrics().AppendEmpty().Metrics().AppendEmpty()
+				m.SetName("latency")
+				m.SetDataType(pmetric.MetricDataTypeExponentialHistogram)
+				m.ExponentialHistogram().SetAggregationTemporality(pmetric.MetricAggregationTemporalityCumulative)
+				pt := m.ExponentialHistogram().DataPoints().AppendEmpty()
+				pt.SetTimestamp(pcommon.NewTimestampFromTime(ts))
+				pt.SetScale(0)
+				pt.SetCount(6)
+				pt.SetSum(10)
+				pt.SetZeroCount(1)
+				pt.Positive().SetOffset(0)
+				pt.Positive().SetBucketCounts(pcommon.NewImmutableUInt64Slice([]uint64{2, 3}))
+			},
+			expectedSeries: []testSeries{
+				newTestSeries(`{__name__="latency_bucket", le="+Inf"}`, mimirpb.Sample{TimestampMs: 10000, Value: 6}),
+				newTestSeries(`{__name__="latency_bucket", le="0"}`, mimirpb.Sample{TimestampMs: 10000, Value: 1}),
+				newTestSeries(`{__name__="latency_bucket", le="2"}`, mimirpb.Sample{TimestampMs: 10000, Value: 3}),
+				newTestSeries(`{__name__="latency_bucket", le="4"}`, mimirpb.Sample{TimestampMs: 10000, Value: 6}),
+				newTestSeries(`{__name__="latency_count"}`, mimirpb.Sample{TimestampMs: 10000, Value: 6}),
+				newTestSeries(`{__name__="latency_sum"}`, mimirpb.Sample{TimestampMs: 10000, Value: 10}),
+			},
+		},
+		"delta data points are dropped": {
+			limits: otlpLimitsMock{},
+			metrics: func(md pmetric.Metrics) {
+				rm := newOTelResourceMetrics(md)
+				addOTelSum(rm, "requests_total", pmetric.MetricAggregationTemporalityDelta, ts, 1)
+				addOTelGauge(rm, "temperature", ts, 21.5)
+			},
+			expectedSeries: []testSeries{
+				newTestSeries(`{__name__="temperature"}`, mimirpb.Sample{TimestampMs: 10000, Value: 21.5}),
+			},
+			expectedDrops: map[string]float64{otelDeltaTemporality: 1},
+		},
+		"unsupported metrics are dropped": {
+			limits: otlpLimitsMock{},
+			metrics: func(md pmetric.Metrics) {
+				rm := newOTelResourceMetrics(md)
+				rm.ScopeMetrics().AppendEmpty().Metrics().AppendEmpty().SetName("unknown")
+				addOTelGauge(rm, "temperature", ts, 21.5)
+			},
+			expectedSeries: []testSeries{
+				newTestSeries(`{__name__="temperature"}`, mimirpb.Sample{TimestampMs: 10000, Value: 21.5}),
+			},
+			expectedDrops: map[string]float64{otelUnsupportedMetricType: 1},
+		},
+		"an error is returned if no data point can be translated": {
+			limits: otlpLimitsMock{createTargetInfo: true},
+			metrics: func(md pmetric.Metrics) {
+				rm := newOTelResourceMetrics(md, "service.name", "checkout")
+				addOTelSum(rm, "requests_total", pmetric.MetricAggregationTemporalityDelta, ts, 1)
+			},
+			expectedErr:   true,
+			expectedDrops: map[string]float64{otelDeltaTemporality: 1},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			userID := name
+			ctx := user.InjectOrgID(context.Background(), userID)
+			defer validation.DeletePerUserValidationMetrics(userID, log.NewNopLogger())
+
+			md := pmetric.NewMetrics()
+			tc.metrics(md)
+
+			series, err := otelMetricsToTimeseries(ctx, log.NewNopLogger(), md, tc.limits)
+			if tc.expectedErr {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, tc.expectedSeries, sortedTestSeries(series))
+			}
+
+			for reason, expected := range tc.expectedDrops {
+				assert.Equal(t, expected, testutil.ToFloat64(validation.DiscardedSamples.WithLabelValues(reason, userID)), reason)
+			}
+		})
+	}
+}
+
+func TestExplicitBucketsFromExponential(t *testing.T) {
+	tests := map[string]struct {
+		scale          int32
+		zeroCount      uint64
+		negative       []uint64
+		negativeOffset int32
+		positive       []uint64
+		positiveOffset int32
+		expectedBounds []float64
+		expectedCounts []uint64
+	}{
+		"no buckets": {
+			expectedBounds: []float64{0},
+			expectedCounts: []uint64{0, 0},
+		},
+		"positive buckets": {
+			scale:          1,
+			positive:       []uint64{1, 2, 3},
+			positiveOffset: -1,
+			expectedBounds: []float64{0, 1, math.Sqrt2, 2},
+			expectedCounts: []uint64{0, 1, 2, 3, 0},
+		},
+		"positive buckets with empty buckets": {
+			scale:          1,
+			positive:       []uint64{0, 2, 0},
+			positiveOffset: -1,
+			expectedBounds: []float64{0, 1, math.Sqrt2, 2},
+			expectedCounts: []uint64{0, 0, 2, 0, 0},
+		},
+		"negative, zero and positive buckets": {
+			scale:          -1,
+			zeroCount:      4,
+			negative:       []uint64{1, 2},
+			positive:       []uint64{3},
+			positiveOffset: 1,
+			expectedBounds: []float64{-4, -1, 0, 4, 16},
+			expectedCounts: []uint64{2, 1, 4, 0, 3, 0},
+		},
+		"negative buckets only": {
+			scale:          0,
+			negative:       []uint64{1},
+			negativeOffset: 1,
+			expectedBounds: []float64{-2, 0, 4},
+			expectedCounts: []uint64{1, 0, 0, 0},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			pt := pmetric.NewExponentialHistogramDataPoint()
+			pt.SetScale(tc.scale)
+			pt.SetZeroCount(tc.zeroCount)
+			pt.Negative().SetOffset(tc.negativeOffset)
+			pt.Negative().SetBucketCounts(pcommon.NewImmutableUInt64Slice(tc.negative))
+			pt.Positive().SetOffset(tc.positiveOffset)
+			pt.Positive().SetBucketCounts(pcommon.NewImmutableUInt64Slice(tc.positive))
+
+			bounds, counts := explicitBucketsFromExponential(pt)
+			assert.InDeltaSlice(t, tc.expectedBounds, bounds, 1e-12)
+			assert.Equal(t, tc.expectedCounts, counts)
+		})
+	}
+}
+
+type otlpLimitsMock struct {
+	promote          []string
+	createTargetInfo bool
+}
+
+func (m otlpLimitsMock) OTelPromoteResourceAttributes(string) []string { return m.promote }
+func (m otlpLimitsMock) OTelCreateTargetInfo(string) bool              { return m.createTargetInfo }
+
+func newOTelResourceMetrics(md pmetric.Metrics, attrs ...string) pmetric.ResourceMetrics {
+	rm := md.ResourceMetrics().AppendEmpty()
+	for i := 0; i < len(attrs); i += 2 {
+		rm.Resource().Attributes().InsertString(attrs[i], attrs[i+1])
+	}
+	return rm
+}
+
+func addOTelGauge(rm pmetric.ResourceMetrics, name string, ts time.Time, value float64, attrs ...string) {
+	m := rm.ScopeMetrics().AppendEmpty().Metrics().AppendEmpty()
+	m.SetName(name)
+	m.SetDataType(pmetric.MetricDataTypeGauge)
+	pt := m.Gauge().DataPoints().AppendEmpty()
+	pt.SetTimestamp(pcommon.NewTimestampFromTime(ts))
+	pt.SetDoubleVal(value)
+	for i := 0; i < len(attrs); i += 2 {
+		pt.Attributes().InsertString(attrs[i], attrs[i+1])
+	}
+}
+
+func addOTelSum(rm pmetric.ResourceMetrics, name string, temporality pmetric.MetricAggregationTemporality, ts time.Time, value float64) {
+	m := rm.ScopeMetrics().AppendEmpty().Metrics().AppendEmpty()
+	m.SetName(name)
+	m.SetDataType(pmetric.MetricDataTypeSum)
+	m.Sum().SetIsMonotonic(true)
+	m.Sum().SetAggregationTemporality(temporality)
+	pt := m.Sum().DataPoints().AppendEmpty()
+	pt.SetTimestamp(pcommon.NewTimestampFromTime(ts))
+	pt.SetDoubleVal(value)
+}
+
+// sortedTestSeries returns the series sorted by labels, since the OTLP translation doesn't preserve their order.
+func sortedTestSeries(series []mimirpb.PreallocTimeseries) []testSeries {
+	result := toTestSeries(series)
+	sort.Slice(result, func(i, j int) bool { return result[i].labels < result[j].labels })
+	return result
+}
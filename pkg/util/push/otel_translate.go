@@ -0,0 +1,243 @@
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package push
+
+import (
+	"fmt"
+	"math"
+
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+)
+
+const (
+	otelUnsupportedMetricType = "otlp_unsupported_metric_type"
+	otelDeltaTemporality      = "otlp_delta_temporality"
+
+	otelTargetInfoMetricName = "target_info"
+	otelInfoSignaturePrefix  = "info-"
+)
+
+// OTLPLimits are the per-tenant limits used to translate OTLP metrics.
+type OTLPLimits interface {
+	OTelPromoteResourceAttributes(userID string) []string
+	OTelCreateTargetInfo(userID string) bool
+}
+
+// otelDroppedPoints tracks the OTLP data points which can't be translated, by reason.
+type otelDroppedPoints struct {
+	counts map[string]int
+	errs   []error
+}
+
+func (d *otelDroppedPoints) add(reason string, count int, err error) {
+	if d.counts == nil {
+		d.counts = map[string]int{}
+	}
+	d.counts[reason] += count
+	d.errs = append(d.errs, err)
+}
+
+// prepareOTelMetrics rewrites in place the OTLP metrics of a tenant which the Prometheus translation can't handle
+// as-is: the configured resource attributes are promoted to data point attributes, the exponential histograms are
+// converted to histograms with explicit buckets. The metrics which still can't be translated, like the ones with delta
+// temporality, are removed, and their data points are returned as dropped.
+//
+// Delta data points are not converted to cumulative ones, since each distributor would only accumulate the data points
+// it receives, producing different cumulative values for the same series.
+func prepareOTelMetrics(userID string, md pmetric.Metrics, limits OTLPLimits) otelDroppedPoints {
+	var dropped otelDroppedPoints
+
+	promoted := limits.OTelPromoteResourceAttributes(userID)
+
+	resourceMetricsSlice := md.ResourceMetrics()
+	for i := 0; i < resourceMetricsSlice.Len(); i++ {
+		resourceMetrics := resourceMetricsSlice.At(i)
+		resourceAttrs := resourceMetrics.Resource().Attributes()
+
+		promotedAttrs := pcommon.NewMap()
+		for _, name := range promoted {
+			if value, ok := resourceAttrs.Get(name); ok {
+				promotedAttrs.Insert(name, value)
+			}
+		}
+
+		scopeMetricsSlice := resourceMetrics.ScopeMetrics()
+		for j := 0; j < scopeMetricsSlice.Len(); j++ {
+			scopeMetricsSlice.At(j).Metrics().RemoveIf(func(metric pmetric.Metric) bool {
+				switch metric.DataType() {
+				case pmetric.MetricDataTypeGauge, pmetric.MetricDataTypeSum, pmetric.MetricDataTypeHistogram, pmetric.MetricDataTypeSummary:
+				case pmetric.MetricDataTypeExponentialHistogram:
+					convertExponentialHistogram(metric)
+				default:
+					dropped.add(otelUnsupportedMetricType, 1, fmt.Errorf("unsupported metric type %s of metric %s", metric.DataType(), metric.Name()))
+					return true
+				}
+
+				if isOTelDelta(metric) {
+					dropped.add(otelDeltaTemporality, otelDataPointsCount(metric), fmt.Errorf("delta temporality of metric %s is not supported", metric.Name()))
+					return true
+				}
+
+				if promotedAttrs.Len() > 0 {
+					forEachOTelDataPointAttributes(metric, func(attrs pcommon.Map) {
+						// The attributes of the data points take precedence over the resource ones.
+						promotedAttrs.Range(func(name string, value pcommon.Value) bool {
+							attrs.Insert(name, value)
+							return true
+						})
+					})
+				}
+				return false
+			})
+		}
+	}
+
+	return dropped
+}
+
+func isOTelDelta(metric pmetric.Metric) bool {
+	switch metric.DataType() {
+	case pmetric.MetricDataTypeSum:
+		return metric.Sum().AggregationTemporality() == pmetric.MetricAggregationTemporalityDelta
+	case pmetric.MetricDataTypeHistogram:
+		return metric.Histogram().AggregationTemporality() == pmetric.MetricAggregationTemporalityDelta
+	default:
+		return false
+	}
+}
+
+func otelDataPointsCount(metric pmetric.Metric) int {
+	switch metric.DataType() {
+	case pmetric.MetricDataTypeGauge:
+		return metric.Gauge().DataPoints().Len()
+	case pmetric.MetricDataTypeSum:
+		return metric.Sum().DataPoints().Len()
+	case pmetric.MetricDataTypeHistogram:
+		return metric.Histogram().DataPoints().Len()
+	case pmetric.MetricDataTypeExponentialHistogram:
+		return metric.ExponentialHistogram().DataPoints().Len()
+	case pmetric.MetricDataTypeSummary:
+		return metric.Summary().DataPoints().Len()
+	default:
+		return 0
+	}
+}
+
+func forEachOTelDataPointAttributes(metric pmetric.Metric, f func(attrs pcommon.Map)) {
+	switch metric.DataType() {
+	case pmetric.MetricDataTypeGauge:
+		for i := 0; i < metric.Gauge().DataPoints().Len(); i++ {
+			f(metric.Gauge().DataPoints().At(i).Attributes())
+		}
+	case pmetric.MetricDataTypeSum:
+		for i := 0; i < metric.Sum().DataPoints().Len(); i++ {
+			f(metric.Sum().DataPoints().At(i).Attributes())
+		}
+	case pmetric.MetricDataTypeHistogram:
+		for i := 0; i < metric.Histogram().DataPoints().Len(); i++ {
+			f(metric.Histogram().DataPoints().At(i).Attributes())
+		}
+	case pmetric.MetricDataTypeSummary:
+		for i := 0; i < metric.Summary().DataPoints().Len(); i++ {
+			f(metric.Summary().DataPoints().At(i).Attributes())
+		}
+	}
+}
+
+// convertExponentialHistogram converts in place an exponential histogram to a histogram with explicit buckets,
+// whose bounds are the boundaries of the exponential buckets.
+func convertExponentialHistogram(metric pmetric.Metric) {
+	exp := pmetric.NewExponentialHistogram()
+	metric.ExponentialHistogram().MoveTo(exp)
+
+	metric.SetDataType(pmetric.MetricDataTypeHistogram)
+	histogram := metric.Histogram()
+	histogram.SetAggregationTemporality(exp.AggregationTemporality())
+
+	for i := 0; i < exp.DataPoints().Len(); i++ {
+		ep := exp.DataPoints().At(i)
+		hp := histogram.DataPoints().AppendEmpty()
+
+		ep.Attributes().CopyTo(hp.Attributes())
+		ep.Exemplars().CopyTo(hp.Exemplars())
+		hp.SetStartTimestamp(ep.StartTimestamp())
+		hp.SetTimestamp(ep.Timestamp())
+		hp.SetFlags(ep.Flags())
+		hp.SetCount(ep.Count())
+		if ep.HasSum() {
+			hp.SetSum(ep.Sum())
+		}
+
+		bounds, counts := explicitBucketsFromExponential(ep)
+		hp.SetExplicitBounds(pcommon.NewImmutableFloat64Slice(bounds))
+		hp.SetBucketCounts(pcommon.NewImmutableUInt64Slice(counts))
+	}
+}
+
+// explicitBucketsFromExponential returns the upper bounds and the counts of the buckets of an exponential histogram
+// data point, ordered by bound. The exponential bucket with index i covers the values in (base^i, base^(i+1)], where
+// base is 2^(2^-scale), and the negative buckets mirror the positive ones. The last count is the one of the +Inf bucket.
+//
+// The bounds only depend on the scale and on the range of bucket indexes covered by the positive and negative buckets,
+// not on which buckets are populated, so that the data points of a series have the same bounds as long as the range
+// doesn't change: the zero bound is always present, and both the positive and the negative buckets (if any) cover
+// the whole range of indexes.
+func explicitBucketsFromExponential(pt pmetric.ExponentialHistogramDataPoint) ([]float64, []uint64) {
+	boundary := func(index int32) float64 {
+		return math.Exp2(float64(index) * math.Exp2(-float64(pt.Scale())))
+	}
+
+	negative := pt.Negative().BucketCounts().AsRaw()
+	positive := pt.Positive().BucketCounts().AsRaw()
+	negativeOffset := pt.Negative().Offset()
+	positiveOffset := pt.Positive().Offset()
+
+	// The range [minIndex, maxIndex) of bucket indexes covered by both the negative and positive buckets.
+	var minIndex, maxIndex int32
+	switch {
+	case len(negative) > 0 && len(positive) > 0:
+		minIndex, maxIndex = negativeOffset, negativeOffset+int32(len(negative))
+		if positiveOffset < minIndex {
+			minIndex = positiveOffset
+		}
+		if end := positiveOffset + int32(len(positive)); end > maxIndex {
+			maxIndex = end
+		}
+	case len(negative) > 0:
+		minIndex, maxIndex = negativeOffset, negativeOffset+int32(len(negative))
+	default:
+		minIndex, maxIndex = positiveOffset, positiveOffset+int32(len(positive))
+	}
+
+	countAt := func(counts []uint64, offset, index int32) uint64 {
+		if i := index - offset; i >= 0 && int(i) < len(counts) {
+			return counts[i]
+		}
+		return 0
+	}
+
+	size := int(maxIndex - minIndex)
+	if len(negative) > 0 {
+		size *= 2
+	}
+	bounds := make([]float64, 0, size+1)
+	counts := make([]uint64, 0, size+2)
+
+	if len(negative) > 0 {
+		for index := maxIndex - 1; index >= minIndex; index-- {
+			bounds = append(bounds, -boundary(index))
+			counts = append(counts, countAt(negative, negativeOffset, index))
+		}
+	}
+	bounds = append(bounds, 0)
+	counts = append(counts, pt.ZeroCount())
+	for index := minIndex; index < maxIndex; index++ {
+		bounds = append(bounds, boundary(index+1))
+		counts = append(counts, countAt(positive, positiveOffset, index))
+	}
+
+	// All the values are in the buckets above, so the +Inf bucket is empty.
+	counts = append(counts, 0)
+	return bounds, counts
+}
@@ -8,16 +8,21 @@ package push
 import (
 	"context"
 	"errors"
+	"fmt"
+	"mime"
 	"net/http"
+	"strconv"
 	"sync"
 
 	"github.com/go-kit/log/level"
+	"github.com/grafana/dskit/tenant"
 	"github.com/weaveworks/common/httpgrpc"
 	"github.com/weaveworks/common/middleware"
 
 	"github.com/grafana/mimir/pkg/mimirpb"
 	"github.com/grafana/mimir/pkg/util"
 	"github.com/grafana/mimir/pkg/util/log"
+	"github.com/grafana/mimir/pkg/util/validation"
 )
 
 // Func defines the type of the push. It is similar to http.HandlerFunc.
@@ -38,16 +43,94 @@ var bufferPool = sync.Pool{
 const SkipLabelNameValidationHeader = "X-Mimir-SkipLabelNameValidation"
 const statusClientClosedRequest = 499
 
-// Handler is a http.Handler which accepts WriteRequests.
+const (
+	// Protobuf messages of the remote write protocol versions, negotiated via the "proto" parameter of the content type.
+	remoteWriteV1ProtoMessage = "prometheus.WriteRequest"
+	remoteWriteV2ProtoMessage = "io.prometheus.write.v2.Request"
+
+	// Response headers of the remote write 2.0 protocol.
+	remoteWriteV2SamplesWrittenHeader    = "X-Prometheus-Remote-Write-Samples-Written"
+	remoteWriteV2HistogramsWrittenHeader = "X-Prometheus-Remote-Write-Histograms-Written"
+	remoteWriteV2ExemplarsWrittenHeader  = "X-Prometheus-Remote-Write-Exemplars-Written"
+
+	// Reason of the native histograms of the remote write 2.0 requests discarded, since they aren't supported.
+	remoteWriteV2NativeHistogramUnsupported = "native_histogram_unsupported"
+)
+
+// Handler is a http.Handler which accepts WriteRequests, or remote write 2.0 requests
+// if the content type is "application/x-protobuf;proto=io.prometheus.write.v2.Request".
 func Handler(
 	maxRecvMsgSize int,
 	sourceIPs *middleware.SourceIPExtractor,
 	allowSkipLabelNameValidation bool,
 	push Func,
 ) http.Handler {
-	return handler(maxRecvMsgSize, sourceIPs, allowSkipLabelNameValidation, push, func(ctx context.Context, r *http.Request, maxRecvMsgSize int, dst []byte, req *mimirpb.PreallocWriteRequest) ([]byte, error) {
+	h := handler(maxRecvMsgSize, sourceIPs, allowSkipLabelNameValidation, push, func(ctx context.Context, r *http.Request, maxRecvMsgSize int, dst []byte, req *mimirpb.PreallocWriteRequest) ([]byte, error) {
+		protoMessage, err := remoteWriteProtoMessage(r)
+		if err != nil {
+			return nil, err
+		}
+		if protoMessage == remoteWriteV2ProtoMessage {
+			reqV2 := &mimirpb.PreallocWriteRequestV2{PreallocWriteRequest: req}
+			buf, err := util.ParseProtoReader(ctx, r.Body, int(r.ContentLength), maxRecvMsgSize, dst, reqV2, util.RawSnappy)
+			if err == nil && reqV2.SkippedHistograms > 0 {
+				if userID, err := tenant.TenantID(ctx); err == nil {
+					validation.DiscardedSamples.WithLabelValues(remoteWriteV2NativeHistogramUnsupported, userID).Add(float64(reqV2.SkippedHistograms))
+				}
+			}
+			return buf, err
+		}
 		return util.ParseProtoReader(ctx, r.Body, int(r.ContentLength), maxRecvMsgSize, dst, req, util.RawSnappy)
 	})
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, err := remoteWriteProtoMessage(r); err != nil {
+			http.Error(w, err.Error(), http.StatusUnsupportedMediaType)
+			return
+		}
+		h.ServeHTTP(w, r)
+	})
+}
+
+// remoteWriteProtoMessage returns the protobuf message of the remote write request, negotiated via the content type.
+// Requests without a "proto" parameter in the content type are WriteRequests, like the ones sent by the clients
+// not supporting the remote write 2.0 protocol.
+func remoteWriteProtoMessage(r *http.Request) (string, error) {
+	mediaType, params, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if err != nil || mediaType != pbContentType {
+		return remoteWriteV1ProtoMessage, nil
+	}
+
+	switch protoMessage := params["proto"]; protoMessage {
+	case "", remoteWriteV1ProtoMessage:
+		return remoteWriteV1ProtoMessage, nil
+	case remoteWriteV2ProtoMessage:
+		return remoteWriteV2ProtoMessage, nil
+	default:
+		return "", fmt.Errorf("unsupported remote write protobuf message: %s, supported: [%s, %s]", protoMessage, remoteWriteV1ProtoMessage, remoteWriteV2ProtoMessage)
+	}
+}
+
+// remoteWriteV2Stats holds the number of samples, histograms and exemplars written, reported to the remote write 2.0 clients.
+type remoteWriteV2Stats struct {
+	samples, histograms, exemplars int
+}
+
+// newRemoteWriteV2Stats returns the stats of the series in the request. The native histograms are discarded
+// when decoding the request, so the request doesn't contain any, and they're reported as not written.
+func newRemoteWriteV2Stats(req *mimirpb.WriteRequest) *remoteWriteV2Stats {
+	stats := &remoteWriteV2Stats{}
+	for _, ts := range req.Timeseries {
+		stats.samples += len(ts.Samples)
+		stats.exemplars += len(ts.Exemplars)
+	}
+	return stats
+}
+
+func (s *remoteWriteV2Stats) setHeaders(h http.Header) {
+	h.Set(remoteWriteV2SamplesWrittenHeader, strconv.Itoa(s.samples))
+	h.Set(remoteWriteV2HistogramsWrittenHeader, strconv.Itoa(s.histograms))
+	h.Set(remoteWriteV2ExemplarsWrittenHeader, strconv.Itoa(s.exemplars))
 }
 
 // handler requires an additional parser argument.
@@ -96,6 +179,12 @@ func handler(maxRecvMsgSize int,
 			req.Source = mimirpb.API
 		}
 
+		// The request can't be accessed anymore once pushed, so the written stats are computed upfront.
+		var writtenStats *remoteWriteV2Stats
+		if protoMessage, err := remoteWriteProtoMessage(r); err == nil && protoMessage == remoteWriteV2ProtoMessage {
+			writtenStats = newRemoteWriteV2Stats(&req.WriteRequest)
+		}
+
 		if _, err := push(ctx, &req.WriteRequest, cleanup); err != nil {
 			if errors.Is(err, context.Canceled) {
 				http.Error(w, err.Error(), statusClientClosedRequest)
@@ -111,6 +200,11 @@ func handler(maxRecvMsgSize int,
 				level.Error(logger).Log("msg", "push error", "err", err)
 			}
 			http.Error(w, string(resp.Body), int(resp.Code))
+			return
+		}
+
+		if writtenStats != nil {
+			writtenStats.setHeaders(w.Header())
 		}
 	})
 }
@@ -9,6 +9,7 @@ import (
 	"bytes"
 	"context"
 	"fmt"
+	"math"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -16,13 +17,17 @@ import (
 
 	"github.com/gogo/protobuf/proto"
 	"github.com/golang/snappy"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/prometheus/prometheus/prompb"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"github.com/weaveworks/common/middleware"
+	"github.com/weaveworks/common/user"
 	"go.opentelemetry.io/collector/pdata/pmetric/pmetricotlp"
+	"google.golang.org/protobuf/encoding/protowire"
 
 	"github.com/grafana/mimir/pkg/mimirpb"
+	"github.com/grafana/mimir/pkg/util/validation"
 )
 
 func TestHandler_remoteWrite(t *testing.T) {
@@ -33,10 +38,82 @@ func TestHandler_remoteWrite(t *testing.T) {
 	assert.Equal(t, 200, resp.Code)
 }
 
+func TestHandler_remoteWriteV2(t *testing.T) {
+	const userID = "test-remote-write-v2"
+	req := createRequest(t, createRemoteWriteV2Protobuf())
+	req = req.WithContext(user.InjectOrgID(req.Context(), userID))
+	req.Header.Set("Content-Type", "application/x-protobuf;proto=io.prometheus.write.v2.Request")
+	req.Header.Set("X-Prometheus-Remote-Write-Version", "2.0.0")
+	resp := httptest.NewRecorder()
+	handler := Handler(100000, nil, false, func(ctx context.Context, request *mimirpb.WriteRequest, cleanup func()) (*mimirpb.WriteResponse, error) {
+		defer cleanup()
+		require.Len(t, request.Timeseries, 1)
+		assert.Equal(t, []mimirpb.LabelAdapter{{Name: "__name__", Value: "foo"}, {Name: "job", Value: "bar"}}, request.Timeseries[0].Labels)
+		assert.Equal(t, []mimirpb.Sample{{TimestampMs: 2000, Value: 1}}, request.Timeseries[0].Samples)
+		assert.Equal(t, []*mimirpb.MetricMetadata{{Type: mimirpb.COUNTER, MetricFamilyName: "foo", Help: "bar"}}, request.Metadata)
+		assert.Equal(t, mimirpb.API, request.Source)
+		return &mimirpb.WriteResponse{}, nil
+	})
+	handler.ServeHTTP(resp, req)
+	assert.Equal(t, 200, resp.Code)
+	assert.Equal(t, "1", resp.Header().Get("X-Prometheus-Remote-Write-Samples-Written"))
+	assert.Equal(t, "0", resp.Header().Get("X-Prometheus-Remote-Write-Histograms-Written"))
+	assert.Equal(t, "0", resp.Header().Get("X-Prometheus-Remote-Write-Exemplars-Written"))
+	assert.Equal(t, float64(1), testutil.ToFloat64(validation.DiscardedSamples.WithLabelValues(remoteWriteV2NativeHistogramUnsupported, userID)))
+}
+
+func TestHandler_remoteWriteContentTypeNegotiation(t *testing.T) {
+	tests := map[string]struct {
+		contentType  string
+		protobuf     []byte
+		expectedCode int
+	}{
+		"no content type": {
+			protobuf:     createPrometheusRemoteWriteProtobuf(t),
+			expectedCode: http.StatusOK,
+		},
+		"remote write 1.0 without proto parameter": {
+			contentType:  "application/x-protobuf",
+			protobuf:     createPrometheusRemoteWriteProtobuf(t),
+			expectedCode: http.StatusOK,
+		},
+		"remote write 1.0 with proto parameter": {
+			contentType:  "application/x-protobuf;proto=prometheus.WriteRequest",
+			protobuf:     createPrometheusRemoteWriteProtobuf(t),
+			expectedCode: http.StatusOK,
+		},
+		"remote write 2.0": {
+			contentType:  "application/x-protobuf; proto=io.prometheus.write.v2.Request",
+			protobuf:     createRemoteWriteV2Protobuf(),
+			expectedCode: http.StatusOK,
+		},
+		"unsupported protobuf message": {
+			contentType:  "application/x-protobuf;proto=io.prometheus.write.v3.Request",
+			protobuf:     createRemoteWriteV2Protobuf(),
+			expectedCode: http.StatusUnsupportedMediaType,
+		},
+	}
+
+	for testName, testData := range tests {
+		t.Run(testName, func(t *testing.T) {
+			req := createRequest(t, testData.protobuf)
+			req.Header.Del("Content-Type")
+			if testData.contentType != "" {
+				req.Header.Set("Content-Type", testData.contentType)
+			}
+			resp := httptest.NewRecorder()
+			handler := Handler(100000, nil, false, verifyWriteRequestHandler(t, mimirpb.API))
+			handler.ServeHTTP(resp, req)
+			assert.Equal(t, testData.expectedCode, resp.Code)
+		})
+	}
+}
+
 func TestHandler_otlpWrite(t *testing.T) {
 	req := createOTLPRequest(t, createOTLPMetricRequest(t))
+	req = req.WithContext(user.InjectOrgID(req.Context(), "test"))
 	resp := httptest.NewRecorder()
-	handler := OTLPHandler(100000, nil, false, verifyWriteRequestHandler(t, mimirpb.API))
+	handler := OTLPHandler(100000, nil, false, otlpLimitsMock{createTargetInfo: true}, verifyWriteRequestHandler(t, mimirpb.API))
 	handler.ServeHTTP(resp, req)
 	assert.Equal(t, 200, resp.Code)
 }
@@ -226,6 +303,43 @@ func createPrometheusRemoteWriteProtobuf(t testing.TB) []byte {
 	return inoutBytes
 }
 
+// createRemoteWriteV2Protobuf returns a remote write 2.0 request with the series foo{job="bar"}.
+func createRemoteWriteV2Protobuf() []byte {
+	var b []byte
+	for _, symbol := range []string{"", "__name__", "foo", "job", "bar"} {
+		b = protowire.AppendTag(b, 4, protowire.BytesType)
+		b = protowire.AppendString(b, symbol)
+	}
+
+	var refs, sample, metadata, series []byte
+	for _, ref := range []uint64{1, 2, 3, 4} {
+		refs = protowire.AppendVarint(refs, ref)
+	}
+	sample = protowire.AppendTag(sample, 1, protowire.Fixed64Type)
+	sample = protowire.AppendFixed64(sample, math.Float64bits(1))
+	sample = protowire.AppendTag(sample, 2, protowire.VarintType)
+	sample = protowire.AppendVarint(sample, 2000)
+	metadata = protowire.AppendTag(metadata, 1, protowire.VarintType)
+	metadata = protowire.AppendVarint(metadata, uint64(mimirpb.COUNTER))
+	metadata = protowire.AppendTag(metadata, 3, protowire.VarintType)
+	metadata = protowire.AppendVarint(metadata, 4)
+
+	series = protowire.AppendTag(series, 1, protowire.BytesType)
+	series = protowire.AppendBytes(series, refs)
+	series = protowire.AppendTag(series, 2, protowire.BytesType)
+	series = protowire.AppendBytes(series, sample)
+	// Native histograms aren't supported, and are discarded.
+	series = protowire.AppendTag(series, 3, protowire.BytesType)
+	series = protowire.AppendBytes(series, nil)
+	series = protowire.AppendTag(series, 5, protowire.BytesType)
+	series = protowire.AppendBytes(series, metadata)
+	series = protowire.AppendTag(series, 6, protowire.VarintType)
+	series = protowire.AppendVarint(series, 1000)
+
+	b = protowire.AppendTag(b, 5, protowire.BytesType)
+	return protowire.AppendBytes(b, series)
+}
+
 func createMimirWriteRequestProtobuf(t *testing.T, skipLabelNameValidation bool) []byte {
 	t.Helper()
 	ts := mimirpb.PreallocTimeseries{
@@ -0,0 +1,134 @@
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package push
+
+import (
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+
+	kitlog "github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/grafana/dskit/tenant"
+
+	"github.com/grafana/mimir/pkg/mimirpb"
+	"github.com/grafana/mimir/pkg/util/validation"
+)
+
+// readTextBody reads the body of a push request in a text format, like the InfluxDB line protocol
+// or the Graphite plaintext protocol, which may be gzip compressed.
+func readTextBody(r *http.Request, maxRecvMsgSize int) ([]byte, error) {
+	if r.ContentLength > int64(maxRecvMsgSize) {
+		return nil, fmt.Errorf(messageSizeLargerErrFmt, r.ContentLength, maxRecvMsgSize)
+	}
+
+	defer r.Body.Close()
+
+	var reader io.Reader = http.MaxBytesReader(nil, r.Body, int64(maxRecvMsgSize))
+	if r.Header.Get("Content-Encoding") == "gzip" {
+		gzReader, err := gzip.NewReader(reader)
+		if err != nil {
+			return nil, err
+		}
+		defer gzReader.Close()
+
+		// The decompressed body is subject to the size limit too.
+		reader = io.LimitReader(gzReader, int64(maxRecvMsgSize)+1)
+	}
+
+	body, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, err
+	}
+	if len(body) > maxRecvMsgSize {
+		return nil, fmt.Errorf(messageSizeLargerErrFmt, len(body), maxRecvMsgSize)
+	}
+	return body, nil
+}
+
+// textParseErrors handles the lines of a push request in a text format which couldn't be parsed.
+// The dropped samples are tracked as discarded, and an error is returned only if no sample could be parsed.
+func textParseErrors(ctx context.Context, logger kitlog.Logger, reason string, parsed, dropped int, errs []error) error {
+	if len(errs) == 0 {
+		return nil
+	}
+
+	userID, err := tenant.TenantID(ctx)
+	if err != nil {
+		return err
+	}
+	validation.DiscardedSamples.WithLabelValues(reason, userID).Add(float64(dropped))
+
+	msgs := make([]string, 0, len(errs))
+	for _, err := range errs {
+		msgs = append(msgs, err.Error())
+	}
+	parseErrs := strings.Join(msgs, "; ")
+	if len(parseErrs) > maxErrMsgLen {
+		parseErrs = parseErrs[:maxErrMsgLen]
+	}
+
+	if parsed == 0 {
+		return errors.New(parseErrs)
+	}
+
+	level.Warn(logger).Log("msg", "push request parse error", "reason", reason, "err", parseErrs)
+	return nil
+}
+
+// seriesBuilder groups the samples parsed from a push request in a text format by series.
+type seriesBuilder struct {
+	series  []mimirpb.PreallocTimeseries
+	byKey   map[string]int
+	samples int
+}
+
+func newSeriesBuilder() *seriesBuilder {
+	return &seriesBuilder{
+		series: mimirpb.PreallocTimeseriesSliceFromPool(),
+		byKey:  map[string]int{},
+	}
+}
+
+// add adds a sample to the series with the given labels, which are sorted in place.
+func (b *seriesBuilder) add(lbls []mimirpb.LabelAdapter, timestampMs int64, value float64) {
+	sort.Slice(lbls, func(i, j int) bool { return lbls[i].Name < lbls[j].Name })
+
+	key := strings.Builder{}
+	for _, l := range lbls {
+		key.WriteString(l.Name)
+		key.WriteByte(0)
+		key.WriteString(l.Value)
+		key.WriteByte(0)
+	}
+
+	b.samples++
+	sample := mimirpb.Sample{TimestampMs: timestampMs, Value: value}
+	if i, ok := b.byKey[key.String()]; ok {
+		b.series[i].Samples = append(b.series[i].Samples, sample)
+		return
+	}
+
+	ts := mimirpb.TimeseriesFromPool()
+	ts.Labels = append(ts.Labels, lbls...)
+	ts.Samples = append(ts.Samples, sample)
+	b.byKey[key.String()] = len(b.series)
+	b.series = append(b.series, mimirpb.PreallocTimeseries{TimeSeries: ts})
+}
+
+// sanitizeName replaces the characters which are not valid in a metric or label name with underscores.
+// Colons are replaced too, because they're reserved to recording rules.
+func sanitizeName(name string) string {
+	sanitized := []byte(name)
+	for i, c := range sanitized {
+		if !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c == '_' || c >= '0' && c <= '9' && i > 0) {
+			sanitized[i] = '_'
+		}
+	}
+	return string(sanitized)
+}
@@ -98,6 +98,38 @@ func newLabelsNotSortedError(series []mimirpb.LabelAdapter, labelName string) Va
 	}
 }
 
+var missingRequiredLabelMsgFormat = globalerror.SeriesMissingRequiredLabel.MessageWithLimitConfig(
+	"received a series without the required label: '%.200s' series: '%.200s'",
+	requiredLabelsFlag)
+
+func newMissingRequiredLabelError(series []mimirpb.LabelAdapter, labelName string) ValidationError {
+	return genericValidationError{
+		message: missingRequiredLabelMsgFormat,
+		cause:   labelName,
+		series:  series,
+	}
+}
+
+// labelValueNotAllowedError is a customized ValidationError, in that both the label name and value are formatted.
+type labelValueNotAllowedError struct {
+	labelName  string
+	labelValue string
+	series     []mimirpb.LabelAdapter
+}
+
+func (e labelValueNotAllowedError) Error() string {
+	return globalerror.SeriesLabelValueNotAllowed.Message(
+		fmt.Sprintf("received a series whose label value is not allowed, label: '%.200s' value: '%.200s' series: '%.200s'", e.labelName, e.labelValue, formatLabelSet(e.series)))
+}
+
+func newLabelValueNotAllowedError(series []mimirpb.LabelAdapter, labelName, labelValue string) ValidationError {
+	return labelValueNotAllowedError{
+		labelName:  labelName,
+		labelValue: labelValue,
+		series:     series,
+	}
+}
+
 type tooManyLabelsError struct {
 	series []mimirpb.LabelAdapter
 	limit  int
@@ -275,6 +307,10 @@ func NewMaxQueryLengthError(actualQueryLen, maxQueryLength time.Duration) LimitE
 		maxQueryLengthFlag))
 }
 
+func NewQueryBlockedError() LimitError {
+	return LimitError(globalerror.QueryBlocked.Message("the request has been blocked by the cluster administrator"))
+}
+
 func NewRequestRateLimitedError(limit float64, burst int) LimitError {
 	return LimitError(globalerror.RequestRateLimited.MessageWithLimitConfig(
 		fmt.Sprintf("the request has been rejected because the tenant exceeded the request rate limit, set to %v requests/s across all distributors with a maximum allowed burst of %d", limit, burst),
@@ -287,6 +323,12 @@ func NewIngestionRateLimitedError(limit float64, burst int) LimitError {
 		ingestionRateFlag, ingestionBurstSizeFlag))
 }
 
+func NewIngestionBytesRateLimitedError(limit float64, burst int) LimitError {
+	return LimitError(globalerror.IngestionBytesRateLimited.MessageWithLimitConfig(
+		fmt.Sprintf("the request has been rejected because the tenant exceeded the ingestion bytes rate limit, set to %v bytes/s with a maximum allowed burst of %d. This limit is applied on the total size of the series, samples, exemplars and metadata received across all distributors", limit, burst),
+		ingestionBytesRateFlag, ingestionBytesBurstFlag))
+}
+
 // formatLabelSet formats label adapters as a metric name with labels, while preserving
 // label order, and keeping duplicates. If there are multiple "__name__" labels, only
 // first one is used as metric name, other ones will be included as regular labels.
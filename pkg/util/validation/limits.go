@@ -11,16 +11,21 @@ import (
 	"flag"
 	"fmt"
 	"math"
+	"regexp"
 	"strings"
 	"time"
 
 	"github.com/grafana/dskit/flagext"
 	"github.com/prometheus/common/model"
+	"github.com/prometheus/prometheus/model/labels"
 	"github.com/prometheus/prometheus/model/relabel"
+	"github.com/prometheus/prometheus/promql/parser"
 	"github.com/thanos-io/thanos/pkg/block"
 	"golang.org/x/time/rate"
+	"gopkg.in/yaml.v2"
 
 	"github.com/grafana/mimir/pkg/ingester/activeseries"
+	"github.com/grafana/mimir/pkg/util"
 )
 
 const (
@@ -36,14 +41,33 @@ const (
 	maxLabelValueLengthFlag    = "validation.max-length-label-value"
 	maxMetadataLengthFlag      = "validation.max-metadata-length"
 	creationGracePeriodFlag    = "validation.create-grace-period"
+	requiredLabelsFlag         = "validation.required-labels"
 	maxQueryLengthFlag         = "store.max-query-length"
 	requestRateFlag            = "distributor.request-rate-limit"
 	requestBurstSizeFlag       = "distributor.request-burst-size"
 	ingestionRateFlag          = "distributor.ingestion-rate-limit"
 	ingestionBurstSizeFlag     = "distributor.ingestion-burst-size"
+	ingestionBytesRateFlag     = "distributor.ingestion-bytes-rate-limit"
+	ingestionBytesBurstFlag    = "distributor.ingestion-bytes-burst-size"
 	HATrackerMaxClustersFlag   = "distributor.ha-tracker.max-clusters"
+	haDeduplicationModeFlag    = "distributor.ha-tracker.deduplication-mode"
+
+	MaxLabelValuesPerLabelNameFlag = "ingester.max-global-label-values-per-label-name"
+	MaxSeriesPerLabelValueFlag     = "ingester.max-global-series-per-label-value"
+	NewSeriesRateFlag              = "ingester.new-series-rate-limit"
+	NewSeriesBurstSizeFlag         = "ingester.new-series-burst-size"
+)
+
+const (
+	// HADeduplicationModeDistributor is the HA deduplication mode where the distributor accepts the samples of the elected replica only.
+	HADeduplicationModeDistributor = "distributor"
+	// HADeduplicationModeIngester is the HA deduplication mode where the ingesters deduplicate the samples of all the replicas.
+	HADeduplicationModeIngester = "ingester"
 )
 
+// HADeduplicationModes are the supported HA deduplication modes.
+var HADeduplicationModes = []string{HADeduplicationModeDistributor, HADeduplicationModeIngester}
+
 // LimitError are errors that do not comply with the limits specified.
 type LimitError string
 
@@ -57,11 +81,318 @@ type ForwardingRule struct {
 
 	// Endpoint is the URL of the remote_write endpoint to which a metric should be forwarded.
 	Endpoint string `yaml:"endpoint" json:"endpoint"`
+
+	// Endpoints are additional remote_write endpoints to which a metric should be forwarded.
+	Endpoints []ForwardingEndpoint `yaml:"endpoints,omitempty" json:"endpoints,omitempty"`
+
+	// The matchers of the series selector the rule is keyed by.
+	matchers []*labels.Matcher
+}
+
+// Matchers returns the matchers of the series selector the rule is keyed by, or nil if the rules haven't been compiled.
+func (r ForwardingRule) Matchers() []*labels.Matcher {
+	return r.matchers
 }
 
-// ForwardingRules are keyed by metric names, excluding labels.
+// ForwardingEndpoint is a remote_write endpoint along with the options used when forwarding metrics to it.
+type ForwardingEndpoint struct {
+	// URL is the URL of the remote_write endpoint.
+	URL string `yaml:"url" json:"url"`
+
+	// Headers are added to each request sent to the endpoint.
+	Headers map[string]string `yaml:"headers,omitempty" json:"headers,omitempty"`
+
+	// BasicAuth are the credentials sent to the endpoint, if the username is not empty.
+	BasicAuth ForwardingBasicAuth `yaml:"basic_auth,omitempty" json:"basic_auth,omitempty"`
+
+	// BearerToken is sent to the endpoint in the Authorization header, if not empty.
+	BearerToken Secret `yaml:"bearer_token,omitempty" json:"bearer_token,omitempty"`
+
+	// WriteRelabelConfigs are applied to the series before forwarding them to the endpoint.
+	WriteRelabelConfigs []*relabel.Config `yaml:"write_relabel_configs,omitempty" json:"write_relabel_configs,omitempty"`
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface. The write relabel configs are unmarshalled
+// as YAML, which JSON is a subset of, since they're only validated and compiled when unmarshalled from YAML.
+func (e *ForwardingEndpoint) UnmarshalJSON(data []byte) error {
+	type plain ForwardingEndpoint
+	var raw struct {
+		*plain
+		WriteRelabelConfigs json.RawMessage `json:"write_relabel_configs,omitempty"`
+	}
+	raw.plain = (*plain)(e)
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+
+	e.WriteRelabelConfigs = nil
+	if len(raw.WriteRelabelConfigs) == 0 {
+		return nil
+	}
+	return yaml.UnmarshalStrict(raw.WriteRelabelConfigs, &e.WriteRelabelConfigs)
+}
+
+type ForwardingBasicAuth struct {
+	Username string `yaml:"username" json:"username"`
+	Password Secret `yaml:"password" json:"password"`
+}
+
+// Secret is a flagext.Secret which can be unmarshalled from JSON too. It's masked when marshalled
+// to either YAML or JSON, so it's not exposed by the runtime config endpoint.
+type Secret struct {
+	flagext.Secret
+}
+
+// NewSecret returns a Secret with the given value.
+func NewSecret(value string) Secret {
+	return Secret{flagext.SecretWithValue(value)}
+}
+
+// IsZero implements yaml.IsZeroer, so that the secret is omitted only if it's empty.
+func (s Secret) IsZero() bool {
+	return s.String() == ""
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (s *Secret) UnmarshalJSON(data []byte) error {
+	var value string
+	if err := json.Unmarshal(data, &value); err != nil {
+		return err
+	}
+	return s.Set(value)
+}
+
+// MarshalJSON implements json.Marshaler.
+func (s Secret) MarshalJSON() ([]byte, error) {
+	masked, err := s.MarshalYAML()
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(masked)
+}
+
+// ForwardingRules are keyed by series selectors, like `{team="payments"}` or `up{job=~"edge.*"}`.
+// A metric name is a valid series selector, so rules can be keyed by metric names too.
+// They must be created with NewForwardingRules, or unmarshalled, which compiles the series selectors.
 type ForwardingRules map[string]ForwardingRule
 
+// NewForwardingRules returns the ForwardingRules with the compiled series selectors, or an error if the rules
+// are keyed by an invalid series selector, or forward metrics to an invalid endpoint.
+func NewForwardingRules(rules map[string]ForwardingRule) (ForwardingRules, error) {
+	r := ForwardingRules(rules)
+	return r, r.compile()
+}
+
+func (r ForwardingRules) compile() error {
+	for selector, rule := range r {
+		matchers, err := parser.ParseMetricSelector(selector)
+		if err != nil {
+			return fmt.Errorf("invalid forwarding rule selector %q: %w", selector, err)
+		}
+		for _, endpoint := range rule.Endpoints {
+			if endpoint.URL == "" {
+				return fmt.Errorf("forwarding rule %q has an endpoint without URL", selector)
+			}
+			if endpoint.BasicAuth.Username != "" && endpoint.BearerToken.String() != "" {
+				return fmt.Errorf("forwarding rule %q has an endpoint with both basic_auth and bearer_token", selector)
+			}
+		}
+
+		rule.matchers = matchers
+		r[selector] = rule
+	}
+	return nil
+}
+
+// UnmarshalYAML implements the yaml.Unmarshaler interface.
+func (r *ForwardingRules) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	type plain ForwardingRules
+	if err := unmarshal((*plain)(r)); err != nil {
+		return err
+	}
+	return r.compile()
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface.
+func (r *ForwardingRules) UnmarshalJSON(data []byte) error {
+	type plain ForwardingRules
+	if err := json.Unmarshal(data, (*plain)(r)); err != nil {
+		return err
+	}
+	return r.compile()
+}
+
+// GraphiteMappingRule maps the Graphite metrics whose path matches a glob pattern to a metric name and labels.
+// It must be created with NewGraphiteMappingRule, or unmarshalled, which compiles the glob pattern.
+type GraphiteMappingRule struct {
+	// Match is a glob pattern matching the whole Graphite path, where each `*` matches a non-empty part of a node.
+	Match string `yaml:"match" json:"match"`
+
+	// Name is the metric name, which can reference the text matched by each `*` as $1, $2 and so on.
+	Name string `yaml:"name" json:"name"`
+
+	// Labels are added to the series, and their values can reference the text matched like Name.
+	Labels map[string]string `yaml:"labels,omitempty" json:"labels,omitempty"`
+
+	// The regular expression equivalent to the Match glob pattern.
+	matchRegexp *regexp.Regexp
+}
+
+// NewGraphiteMappingRule returns a GraphiteMappingRule with the compiled glob pattern.
+func NewGraphiteMappingRule(match, name string, labels map[string]string) (GraphiteMappingRule, error) {
+	r := GraphiteMappingRule{Match: match, Name: name, Labels: labels}
+	return r, r.compile()
+}
+
+func (r *GraphiteMappingRule) compile() error {
+	re, err := regexp.Compile("^" + strings.ReplaceAll(regexp.QuoteMeta(r.Match), `\*`, `([^.]+)`) + "$")
+	if err != nil {
+		return fmt.Errorf("invalid graphite mapping rule match %q: %w", r.Match, err)
+	}
+	r.matchRegexp = re
+	return nil
+}
+
+// UnmarshalYAML implements the yaml.Unmarshaler interface.
+func (r *GraphiteMappingRule) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	type plain GraphiteMappingRule
+	if err := unmarshal((*plain)(r)); err != nil {
+		return err
+	}
+	return r.compile()
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface.
+func (r *GraphiteMappingRule) UnmarshalJSON(data []byte) error {
+	type plain GraphiteMappingRule
+	if err := json.Unmarshal(data, (*plain)(r)); err != nil {
+		return err
+	}
+	return r.compile()
+}
+
+// MatchRegexp returns the regular expression equivalent to the Match glob pattern, where each `*` is a capturing
+// group, or nil if the rule hasn't been compiled.
+func (r GraphiteMappingRule) MatchRegexp() *regexp.Regexp {
+	return r.matchRegexp
+}
+
+func validateGraphiteMappingRules(rules []GraphiteMappingRule) error {
+	for _, rule := range rules {
+		if rule.Match == "" || rule.Name == "" {
+			return fmt.Errorf("graphite mapping rule %q must have both match and name", rule.Match)
+		}
+		for name := range rule.Labels {
+			if !model.LabelName(name).IsValid() {
+				return fmt.Errorf("graphite mapping rule %q has an invalid label name %q", rule.Match, name)
+			}
+		}
+	}
+	return nil
+}
+
+// ValidateHADeduplicationMode returns an error if the HA deduplication mode is not supported.
+// An empty mode is equivalent to the distributor mode.
+func (l *Limits) ValidateHADeduplicationMode() error {
+	if l.HADeduplicationMode != "" && !util.StringsContain(HADeduplicationModes, l.HADeduplicationMode) {
+		return fmt.Errorf("unsupported HA deduplication mode %q, supported values are: %s", l.HADeduplicationMode, strings.Join(HADeduplicationModes, ", "))
+	}
+	return nil
+}
+
+// validateLabelPolicies checks the label names and values of the required, default and allowed labels.
+func (l *Limits) validateLabelPolicies() error {
+	for _, name := range l.RequiredLabels {
+		if !model.LabelName(name).IsValid() {
+			return fmt.Errorf("invalid required label name %q", name)
+		}
+	}
+	for name, value := range l.DefaultLabels {
+		if !model.LabelName(name).IsValid() {
+			return fmt.Errorf("invalid default label name %q", name)
+		}
+		if value == "" {
+			return fmt.Errorf("default label %q must have a value", name)
+		}
+	}
+	for name, values := range l.AllowedLabelValues {
+		if !model.LabelName(name).IsValid() {
+			return fmt.Errorf("invalid allowed label values name %q", name)
+		}
+		if len(values) == 0 {
+			return fmt.Errorf("allowed label values of %q must have at least one value", name)
+		}
+	}
+	return nil
+}
+
+// BlockedQuery is a query rejected by the query-frontend. It must be created with NewBlockedQuery,
+// or unmarshalled, which compiles the pattern.
+type BlockedQuery struct {
+	// Pattern is the PromQL expression to block, or a regular expression matching it if Regex is true.
+	Pattern string `yaml:"pattern" json:"pattern"`
+
+	// Regex defines whether Pattern is a regular expression.
+	Regex bool `yaml:"regex" json:"regex"`
+
+	// The regular expression matching the whole query if Regex is true, otherwise the normalised PromQL expression.
+	regex *regexp.Regexp
+	expr  string
+}
+
+// NewBlockedQuery returns a BlockedQuery with the compiled pattern, or an error if the pattern isn't
+// a valid regular expression, or PromQL expression.
+func NewBlockedQuery(pattern string, regex bool) (BlockedQuery, error) {
+	q := BlockedQuery{Pattern: pattern, Regex: regex}
+	return q, q.compile()
+}
+
+func (q *BlockedQuery) compile() error {
+	if q.Regex {
+		re, err := regexp.Compile("^(?:" + q.Pattern + ")$")
+		if err != nil {
+			return fmt.Errorf("invalid blocked query regex %q: %w", q.Pattern, err)
+		}
+		q.regex = re
+		return nil
+	}
+
+	expr, err := parser.ParseExpr(q.Pattern)
+	if err != nil {
+		return fmt.Errorf("invalid blocked query %q: %w", q.Pattern, err)
+	}
+	q.expr = expr.String()
+	return nil
+}
+
+// UnmarshalYAML implements the yaml.Unmarshaler interface.
+func (q *BlockedQuery) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	type plain BlockedQuery
+	if err := unmarshal((*plain)(q)); err != nil {
+		return err
+	}
+	return q.compile()
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface.
+func (q *BlockedQuery) UnmarshalJSON(data []byte) error {
+	type plain BlockedQuery
+	if err := json.Unmarshal(data, (*plain)(q)); err != nil {
+		return err
+	}
+	return q.compile()
+}
+
+// Matches returns whether the blocked query matches the query. The regular expression is matched against the
+// query as it's been received, while the PromQL expression is matched against normalisedQuery, which is the
+// String() of the parsed query, or empty if the query can't be parsed.
+func (q BlockedQuery) Matches(query, normalisedQuery string) bool {
+	if q.Regex {
+		return q.regex != nil && q.regex.MatchString(query)
+	}
+	return q.expr != "" && q.expr == normalisedQuery
+}
+
 // Limits describe all the limits for users; can be used to describe global default
 // limits via flags, or per-user limits via yaml config.
 type Limits struct {
@@ -70,6 +401,8 @@ type Limits struct {
 	RequestBurstSize          int                 `yaml:"request_burst_size" json:"request_burst_size" category:"experimental"`
 	IngestionRate             float64             `yaml:"ingestion_rate" json:"ingestion_rate"`
 	IngestionBurstSize        int                 `yaml:"ingestion_burst_size" json:"ingestion_burst_size"`
+	IngestionBytesRate        float64             `yaml:"ingestion_bytes_rate" json:"ingestion_bytes_rate" category:"experimental"`
+	IngestionBytesBurstSize   int                 `yaml:"ingestion_bytes_burst_size" json:"ingestion_bytes_burst_size" category:"experimental"`
 	AcceptHASamples           bool                `yaml:"accept_ha_samples" json:"accept_ha_samples"`
 	HAClusterLabel            string              `yaml:"ha_cluster_label" json:"ha_cluster_label"`
 	HAReplicaLabel            string              `yaml:"ha_replica_label" json:"ha_replica_label"`
@@ -84,10 +417,28 @@ type Limits struct {
 	IngestionTenantShardSize  int                 `yaml:"ingestion_tenant_shard_size" json:"ingestion_tenant_shard_size"`
 	MetricRelabelConfigs      []*relabel.Config   `yaml:"metric_relabel_configs,omitempty" json:"metric_relabel_configs,omitempty" doc:"nocli|description=List of metric relabel configurations. Note that in most situations, it is more effective to use metrics relabeling directly in the Prometheus server, e.g. remote_write.write_relabel_configs." category:"experimental"`
 
+	GraphiteMappingRules []GraphiteMappingRule `yaml:"graphite_mapping_rules,omitempty" json:"graphite_mapping_rules,omitempty" doc:"nocli|description=List of rules mapping the Graphite metrics pushed to the distributor to metric names and labels. Each rule has a match glob pattern, where each * matches a non-empty part of a node, a name and optional labels, whose values can reference the matched text as $1, $2 and so on. The first matching rule is used. The metrics which don't match any rule are named after their path, with the invalid characters replaced by underscores." category:"experimental"`
+
+	OTelPromoteResourceAttributes flagext.StringSliceCSV `yaml:"otel_promote_resource_attributes" json:"otel_promote_resource_attributes" category:"experimental"`
+	OTelCreateTargetInfo          bool                   `yaml:"otel_create_target_info" json:"otel_create_target_info" category:"experimental"`
+
+	RequiredLabels     flagext.StringSlice `yaml:"required_labels" json:"required_labels" category:"experimental"`
+	DefaultLabels      map[string]string   `yaml:"default_labels,omitempty" json:"default_labels,omitempty" doc:"nocli|description=Labels added by the distributor to the received series which don't have them, keyed by label name. The default labels are added after dropping the labels configured by drop_labels, and before enforcing required_labels and allowed_label_values." category:"experimental"`
+	AllowedLabelValues map[string][]string `yaml:"allowed_label_values,omitempty" json:"allowed_label_values,omitempty" doc:"nocli|description=Allowed values of labels, keyed by label name. The series with a label whose value is not in the list of its allowed values are discarded. The series without the label are not affected." category:"experimental"`
+
+	HADeduplicationMode            string         `yaml:"ha_deduplication_mode" json:"ha_deduplication_mode" category:"experimental"`
+	HADeduplicationFailoverTimeout model.Duration `yaml:"ha_deduplication_failover_timeout" json:"ha_deduplication_failover_timeout" category:"experimental"`
+
 	// Ingester enforced limits.
 	// Series
 	MaxGlobalSeriesPerUser   int `yaml:"max_global_series_per_user" json:"max_global_series_per_user"`
 	MaxGlobalSeriesPerMetric int `yaml:"max_global_series_per_metric" json:"max_global_series_per_metric"`
+	// New series creation rate
+	NewSeriesRate      float64 `yaml:"new_series_rate" json:"new_series_rate" category:"experimental"`
+	NewSeriesBurstSize int     `yaml:"new_series_burst_size" json:"new_series_burst_size" category:"experimental"`
+	// Label cardinality
+	MaxGlobalLabelValuesPerLabelName int `yaml:"max_global_label_values_per_label_name" json:"max_global_label_values_per_label_name" category:"experimental"`
+	MaxGlobalSeriesPerLabelValue     int `yaml:"max_global_series_per_label_value" json:"max_global_series_per_label_value" category:"experimental"`
 	// Metadata
 	MaxGlobalMetricsWithMetadataPerUser int `yaml:"max_global_metadata_per_user" json:"max_global_metadata_per_user"`
 	MaxGlobalMetadataPerMetric          int `yaml:"max_global_metadata_per_metric" json:"max_global_metadata_per_metric"`
@@ -110,12 +461,15 @@ type Limits struct {
 	MaxLabelsQueryLength           model.Duration `yaml:"max_labels_query_length" json:"max_labels_query_length"`
 	MaxCacheFreshness              model.Duration `yaml:"max_cache_freshness" json:"max_cache_freshness" category:"advanced"`
 	MaxQueriersPerTenant           int            `yaml:"max_queriers_per_tenant" json:"max_queriers_per_tenant"`
+	MaxInflightQueryCost           int            `yaml:"max_inflight_query_cost" json:"max_inflight_query_cost" category:"experimental"`
 	QueryShardingTotalShards       int            `yaml:"query_sharding_total_shards" json:"query_sharding_total_shards"`
 	QueryShardingMaxShardedQueries int            `yaml:"query_sharding_max_sharded_queries" json:"query_sharding_max_sharded_queries"`
+	BlockedQueries                 []BlockedQuery `yaml:"blocked_queries,omitempty" json:"blocked_queries,omitempty" doc:"nocli|description=List of queries to block. Each entry has a pattern, which is the query to block, and a regex boolean. If regex is false, the pattern is a PromQL expression matched regardless of formatting differences. If regex is true, the pattern is a regular expression that must match the whole query." category:"experimental"`
 	// Cardinality
 	CardinalityAnalysisEnabled                    bool `yaml:"cardinality_analysis_enabled" json:"cardinality_analysis_enabled"`
 	LabelNamesAndValuesResultsMaxSizeBytes        int  `yaml:"label_names_and_values_results_max_size_bytes" json:"label_names_and_values_results_max_size_bytes"`
 	LabelValuesMaxCardinalityLabelNamesPerRequest int  `yaml:"label_values_max_cardinality_label_names_per_request" json:"label_values_max_cardinality_label_names_per_request"`
+	ActiveSeriesResultsMaxSizeBytes               int  `yaml:"active_series_results_max_size_bytes" json:"active_series_results_max_size_bytes" category:"experimental"`
 
 	// Ruler defaults and limits.
 	RulerEvaluationDelay        model.Duration `yaml:"ruler_evaluation_delay_duration" json:"ruler_evaluation_delay_duration"`
@@ -154,7 +508,7 @@ type Limits struct {
 	AlertmanagerMaxAlertsCount                 int `yaml:"alertmanager_max_alerts_count" json:"alertmanager_max_alerts_count"`
 	AlertmanagerMaxAlertsSizeBytes             int `yaml:"alertmanager_max_alerts_size_bytes" json:"alertmanager_max_alerts_size_bytes"`
 
-	ForwardingRules ForwardingRules `yaml:"forwarding_rules" json:"forwarding_rules" doc:"nocli|description=Rules based on which the Distributor decides whether a metric should be forwarded to an alternative remote_write API endpoint."`
+	ForwardingRules ForwardingRules `yaml:"forwarding_rules" json:"forwarding_rules" doc:"nocli|description=Rules based on which the Distributor decides whether a metric should be forwarded to alternative remote_write API endpoints. The rules are keyed by series selectors, and each rule can forward the matching series to multiple endpoints with their own headers, credentials and write relabel configs."`
 }
 
 // RegisterFlags adds the flags required to config this to the given FlagSet
@@ -164,11 +518,19 @@ func (l *Limits) RegisterFlags(f *flag.FlagSet) {
 	f.IntVar(&l.RequestBurstSize, requestBurstSizeFlag, 0, "Per-tenant allowed request burst size. 0 to disable.")
 	f.Float64Var(&l.IngestionRate, ingestionRateFlag, 10000, "Per-tenant ingestion rate limit in samples per second.")
 	f.IntVar(&l.IngestionBurstSize, ingestionBurstSizeFlag, 200000, "Per-tenant allowed ingestion burst size (in number of samples).")
+	f.Float64Var(&l.IngestionBytesRate, ingestionBytesRateFlag, 0, "Per-tenant ingestion rate limit in bytes per second, computed on the size of the series, samples, exemplars and metadata received by the distributor. 0 to disable.")
+	f.IntVar(&l.IngestionBytesBurstSize, ingestionBytesBurstFlag, 0, "Per-tenant allowed ingestion burst size (in bytes). 0 to disable.")
 	f.BoolVar(&l.AcceptHASamples, "distributor.ha-tracker.enable-for-all-users", false, "Flag to enable, for all tenants, handling of samples with external labels identifying replicas in an HA Prometheus setup.")
 	f.StringVar(&l.HAClusterLabel, "distributor.ha-tracker.cluster", "cluster", "Prometheus label to look for in samples to identify a Prometheus HA cluster.")
 	f.StringVar(&l.HAReplicaLabel, "distributor.ha-tracker.replica", "__replica__", "Prometheus label to look for in samples to identify a Prometheus HA replica.")
 	f.IntVar(&l.HAMaxClusters, HATrackerMaxClustersFlag, 0, "Maximum number of clusters that HA tracker will keep track of for a single tenant. 0 to disable the limit.")
+	f.StringVar(&l.HADeduplicationMode, haDeduplicationModeFlag, HADeduplicationModeDistributor, fmt.Sprintf("How the samples of HA replicas are deduplicated. Supported values are: %s. In the %s mode, the HA tracker elects one replica per cluster and the samples of the other replicas are discarded by the distributor. In the %s mode, the samples of all the replicas are sent to the ingesters, which ingest the samples of one replica per series and fail over to another replica as soon as the samples of the former stop, without gaps larger than the failover timeout.", strings.Join(HADeduplicationModes, ", "), HADeduplicationModeDistributor, HADeduplicationModeIngester))
+	_ = l.HADeduplicationFailoverTimeout.Set("30s")
+	f.Var(&l.HADeduplicationFailoverTimeout, "ingester.ha-deduplication-failover-timeout", "In the ingester HA deduplication mode, the ingester fails over to the samples of another replica of a series once they're newer than the latest sample of the current replica by more than this timeout. The samples of the other replica newer than the latest sample of the current replica are held until then, and ingested on failover. It should be greater than the scrape interval.")
 	f.Var(&l.DropLabels, "distributor.drop-label", "This flag can be used to specify label names that to drop during sample ingestion within the distributor and can be repeated in order to drop multiple labels.")
+	f.Var(&l.OTelPromoteResourceAttributes, "distributor.otel-promote-resource-attributes", "Comma-separated list of OTLP resource attributes to promote to labels of all the series of the resource, in addition to the job and instance labels. The attributes of the data points take precedence over the promoted resource attributes.")
+	f.BoolVar(&l.OTelCreateTargetInfo, "distributor.otel-create-target-info", true, "Whether to create a target_info series with the attributes of each OTLP resource.")
+	f.Var(&l.RequiredLabels, requiredLabelsFlag, "Label name which every series must have. The series missing any of the required labels, or having an empty value for them, are discarded. This flag can be repeated to require multiple labels.")
 	f.IntVar(&l.MaxLabelNameLength, maxLabelNameLengthFlag, 1024, "Maximum length accepted for label names")
 	f.IntVar(&l.MaxLabelValueLength, maxLabelValueLengthFlag, 2048, "Maximum length accepted for label value. This setting also applies to the metric name")
 	f.IntVar(&l.MaxLabelNamesPerSeries, maxLabelNamesPerSeriesFlag, 30, "Maximum number of label names per series.")
@@ -179,6 +541,10 @@ func (l *Limits) RegisterFlags(f *flag.FlagSet) {
 
 	f.IntVar(&l.MaxGlobalSeriesPerUser, MaxSeriesPerUserFlag, 150000, "The maximum number of active series per tenant, across the cluster before replication. 0 to disable.")
 	f.IntVar(&l.MaxGlobalSeriesPerMetric, MaxSeriesPerMetricFlag, 20000, "The maximum number of active series per metric name, across the cluster before replication. 0 to disable.")
+	f.Float64Var(&l.NewSeriesRate, NewSeriesRateFlag, 0, "Per-tenant rate limit of the series created in the ingesters, in series per second across the cluster before replication. 0 to disable.")
+	f.IntVar(&l.NewSeriesBurstSize, NewSeriesBurstSizeFlag, 0, "Per-tenant allowed burst of series created in the ingesters. 0 to disable.")
+	f.IntVar(&l.MaxGlobalLabelValuesPerLabelName, MaxLabelValuesPerLabelNameFlag, 0, "The maximum number of distinct values of each label name in the active series of a tenant. Since the values of a label are not partitioned across ingesters, the limit is enforced as is by each ingester on the series it holds. The metric name is not subject to this limit. 0 to disable.")
+	f.IntVar(&l.MaxGlobalSeriesPerLabelValue, MaxSeriesPerLabelValueFlag, 0, "The maximum number of active series with the same value of each label name, across the cluster before replication. The metric name is not subject to this limit. 0 to disable.")
 
 	f.IntVar(&l.MaxGlobalMetricsWithMetadataPerUser, MaxMetadataPerUserFlag, 0, "The maximum number of active metrics with metadata per tenant, across the cluster. 0 to disable.")
 	f.IntVar(&l.MaxGlobalMetadataPerMetric, MaxMetadataPerMetricFlag, 0, "The maximum number of metadata per metric, across the cluster. 0 to disable.")
@@ -192,13 +558,15 @@ func (l *Limits) RegisterFlags(f *flag.FlagSet) {
 	f.Var(&l.MaxQueryLength, maxQueryLengthFlag, "Limit the query time range (end - start time). This limit is enforced in the query-frontend (on the received query), in the querier (on the query possibly split by the query-frontend) and ruler. 0 to disable.")
 	f.Var(&l.MaxQueryLookback, "querier.max-query-lookback", "Limit how long back data (series and metadata) can be queried, up until <lookback> duration ago. This limit is enforced in the query-frontend, querier and ruler. If the requested time range is outside the allowed range, the request will not fail but will be manipulated to only query data within the allowed time range. 0 to disable.")
 	f.IntVar(&l.MaxQueryParallelism, "querier.max-query-parallelism", 14, "Maximum number of split (by time) or partial (by shard) queries that will be scheduled in parallel by the query-frontend for a single input query. This limit is introduced to have a fairer query scheduling and avoid a single query over a large time range saturating all available queriers.")
-	f.Var(&l.MaxLabelsQueryLength, "store.max-labels-query-length", "Limit the time range (end - start time) of series, label names and values queries. This limit is enforced in the querier. If the requested time range is outside the allowed range, the request will not fail but will be manipulated to only query data within the allowed time range. 0 to disable.")
+	f.Var(&l.MaxLabelsQueryLength, "store.max-labels-query-length", "Limit the time range (end - start time) of series, label names and values queries. This limit is enforced in the query-frontend and querier. If the requested time range is outside the allowed range, the request will not fail but will be manipulated to only query data within the allowed time range. 0 to disable.")
 	f.IntVar(&l.LabelNamesAndValuesResultsMaxSizeBytes, "querier.label-names-and-values-results-max-size-bytes", 400*1024*1024, "Maximum size in bytes of distinct label names and values. When querier receives response from ingester, it merges the response with responses from other ingesters. This maximum size limit is applied to the merged(distinct) results. If the limit is reached, an error is returned.")
 	f.BoolVar(&l.CardinalityAnalysisEnabled, "querier.cardinality-analysis-enabled", false, "Enables endpoints used for cardinality analysis.")
 	f.IntVar(&l.LabelValuesMaxCardinalityLabelNamesPerRequest, "querier.label-values-max-cardinality-label-names-per-request", 100, "Maximum number of label names allowed to be queried in a single /api/v1/cardinality/label_values API call.")
+	f.IntVar(&l.ActiveSeriesResultsMaxSizeBytes, "querier.active-series-results-max-size-bytes", 400*1024*1024, "Maximum size in bytes of distinct active series returned by a single /api/v1/cardinality/active_series API call. When querier receives response from ingester, it merges the response with responses from other ingesters. This maximum size limit is applied to the merged(distinct) results. If the limit is reached, an error is returned.")
 	_ = l.MaxCacheFreshness.Set("1m")
 	f.Var(&l.MaxCacheFreshness, "query-frontend.max-cache-freshness", "Most recent allowed cacheable result per-tenant, to prevent caching very recent results that might still be in flux.")
 	f.IntVar(&l.MaxQueriersPerTenant, "query-frontend.max-queriers-per-tenant", 0, "Maximum number of queriers that can handle requests for a single tenant. If set to 0 or value higher than number of available queriers, *all* queriers will handle requests for the tenant. Each frontend (or query-scheduler, if used) will select the same set of queriers for the same tenant (given that all queriers are connected to all frontends / query-schedulers). This option only works with queriers connecting to the query-frontend / query-scheduler, not when using downstream URL.")
+	f.IntVar(&l.MaxInflightQueryCost, "query-scheduler.max-inflight-query-cost", 0, "Maximum total estimated cost of the queries of a single tenant that the query-scheduler dispatches to queriers at the same time. A cost unit is roughly a series selector querying one day of data. A query is always dispatched if the tenant has no other query in-flight. This option only works with queriers connecting to the query-scheduler. 0 to disable.")
 	f.IntVar(&l.QueryShardingTotalShards, "query-frontend.query-sharding-total-shards", 16, "The amount of shards to use when doing parallelisation via query sharding by tenant. 0 to disable query sharding for tenant. Query sharding implementation will adjust the number of query shards based on compactor shards. This allows querier to not search the blocks which cannot possibly have the series for given query shard.")
 	f.IntVar(&l.QueryShardingMaxShardedQueries, "query-frontend.query-sharding-max-sharded-queries", 128, "The max number of sharded queries that can be run for a given received query. 0 to disable limit.")
 
@@ -258,7 +626,14 @@ func (l *Limits) UnmarshalYAML(unmarshal func(interface{}) error) error {
 		l.ActiveSeriesCustomTrackersConfig = l.ActiveSeriesCustomTrackersConfigOld
 		l.ActiveSeriesCustomTrackersConfigOld = activeseries.CustomTrackersConfig{}
 	}
-	return nil
+
+	if err := validateGraphiteMappingRules(l.GraphiteMappingRules); err != nil {
+		return err
+	}
+	if err := l.validateLabelPolicies(); err != nil {
+		return err
+	}
+	return l.ValidateHADeduplicationMode()
 }
 
 // UnmarshalJSON implements the json.Unmarshaler interface.
@@ -285,7 +660,13 @@ func (l *Limits) UnmarshalJSON(data []byte) error {
 		l.ActiveSeriesCustomTrackersConfig = l.ActiveSeriesCustomTrackersConfigOld
 		l.ActiveSeriesCustomTrackersConfigOld = activeseries.CustomTrackersConfig{}
 	}
-	return nil
+	if err := validateGraphiteMappingRules(l.GraphiteMappingRules); err != nil {
+		return err
+	}
+	if err := l.validateLabelPolicies(); err != nil {
+		return err
+	}
+	return l.ValidateHADeduplicationMode()
 }
 
 func (l *Limits) copyNotificationIntegrationLimits(defaults NotificationRateLimitMap) {
@@ -347,6 +728,11 @@ func (o *Overrides) IngestionRate(userID string) float64 {
 	return o.getOverridesForUser(userID).IngestionRate
 }
 
+// ActiveSeriesResultsMaxSizeBytes returns the maximum size in bytes of distinct active series
+func (o *Overrides) ActiveSeriesResultsMaxSizeBytes(userID string) int {
+	return o.getOverridesForUser(userID).ActiveSeriesResultsMaxSizeBytes
+}
+
 // LabelNamesAndValuesResultsMaxSizeBytes returns the maximum size in bytes of distinct label names and values
 func (o *Overrides) LabelNamesAndValuesResultsMaxSizeBytes(userID string) int {
 	return o.getOverridesForUser(userID).LabelNamesAndValuesResultsMaxSizeBytes
@@ -366,6 +752,16 @@ func (o *Overrides) IngestionBurstSize(userID string) int {
 	return o.getOverridesForUser(userID).IngestionBurstSize
 }
 
+// IngestionBytesRate returns the limit on ingestion rate (bytes per second).
+func (o *Overrides) IngestionBytesRate(userID string) float64 {
+	return o.getOverridesForUser(userID).IngestionBytesRate
+}
+
+// IngestionBytesBurstSize returns the burst size for ingestion bytes rate.
+func (o *Overrides) IngestionBytesBurstSize(userID string) int {
+	return o.getOverridesForUser(userID).IngestionBytesBurstSize
+}
+
 // AcceptHASamples returns whether the distributor should track and accept samples from HA replicas for this user.
 func (o *Overrides) AcceptHASamples(userID string) bool {
 	return o.getOverridesForUser(userID).AcceptHASamples
@@ -424,6 +820,26 @@ func (o *Overrides) MaxGlobalSeriesPerMetric(userID string) int {
 	return o.getOverridesForUser(userID).MaxGlobalSeriesPerMetric
 }
 
+// NewSeriesRate returns the limit on the rate of series created across the cluster (series per second).
+func (o *Overrides) NewSeriesRate(userID string) float64 {
+	return o.getOverridesForUser(userID).NewSeriesRate
+}
+
+// NewSeriesBurstSize returns the burst size for the new series rate.
+func (o *Overrides) NewSeriesBurstSize(userID string) int {
+	return o.getOverridesForUser(userID).NewSeriesBurstSize
+}
+
+// MaxGlobalLabelValuesPerLabelName returns the maximum number of distinct values allowed per label name, enforced by each ingester.
+func (o *Overrides) MaxGlobalLabelValuesPerLabelName(userID string) int {
+	return o.getOverridesForUser(userID).MaxGlobalLabelValuesPerLabelName
+}
+
+// MaxGlobalSeriesPerLabelValue returns the maximum number of series allowed per label value across the cluster.
+func (o *Overrides) MaxGlobalSeriesPerLabelValue(userID string) int {
+	return o.getOverridesForUser(userID).MaxGlobalSeriesPerLabelValue
+}
+
 func (o *Overrides) MaxChunksPerQuery(userID string) int {
 	return o.getOverridesForUser(userID).MaxChunksPerQuery
 }
@@ -455,6 +871,11 @@ func (o *Overrides) MaxLabelsQueryLength(userID string) time.Duration {
 	return time.Duration(o.getOverridesForUser(userID).MaxLabelsQueryLength)
 }
 
+// BlockedQueries returns the queries the query-frontend should reject for this user.
+func (o *Overrides) BlockedQueries(userID string) []BlockedQuery {
+	return o.getOverridesForUser(userID).BlockedQueries
+}
+
 // MaxCacheFreshness returns the period after which results are cacheable,
 // to prevent caching of very recent results.
 func (o *Overrides) MaxCacheFreshness(userID string) time.Duration {
@@ -466,6 +887,12 @@ func (o *Overrides) MaxQueriersPerUser(userID string) int {
 	return o.getOverridesForUser(userID).MaxQueriersPerTenant
 }
 
+// MaxInflightQueryCost returns the max total estimated cost of the queries of this user that the
+// query-scheduler can dispatch to queriers at the same time.
+func (o *Overrides) MaxInflightQueryCost(userID string) int {
+	return o.getOverridesForUser(userID).MaxInflightQueryCost
+}
+
 // MaxQueryParallelism returns the limit to the number of split queries the
 // frontend will process in parallel.
 func (o *Overrides) MaxQueryParallelism(userID string) int {
@@ -558,6 +985,36 @@ func (o *Overrides) MetricRelabelConfigs(userID string) []*relabel.Config {
 	return o.getOverridesForUser(userID).MetricRelabelConfigs
 }
 
+// GraphiteMappingRules returns the rules mapping the Graphite metrics to metric names and labels for a given user.
+func (o *Overrides) GraphiteMappingRules(userID string) []GraphiteMappingRule {
+	return o.getOverridesForUser(userID).GraphiteMappingRules
+}
+
+// OTelPromoteResourceAttributes returns the OTLP resource attributes to promote to labels for a given user.
+func (o *Overrides) OTelPromoteResourceAttributes(userID string) []string {
+	return o.getOverridesForUser(userID).OTelPromoteResourceAttributes
+}
+
+// OTelCreateTargetInfo returns whether to create the target_info series of OTLP resources for a given user.
+func (o *Overrides) OTelCreateTargetInfo(userID string) bool {
+	return o.getOverridesForUser(userID).OTelCreateTargetInfo
+}
+
+// RequiredLabels returns the label names which every series must have for a given user.
+func (o *Overrides) RequiredLabels(userID string) []string {
+	return o.getOverridesForUser(userID).RequiredLabels
+}
+
+// DefaultLabels returns the labels to add to the series which don't have them for a given user.
+func (o *Overrides) DefaultLabels(userID string) map[string]string {
+	return o.getOverridesForUser(userID).DefaultLabels
+}
+
+// AllowedLabelValues returns the allowed values of labels, keyed by label name, for a given user.
+func (o *Overrides) AllowedLabelValues(userID string) map[string][]string {
+	return o.getOverridesForUser(userID).AllowedLabelValues
+}
+
 // RulerTenantShardSize returns shard size (number of rulers) used by this tenant when using shuffle-sharding strategy.
 func (o *Overrides) RulerTenantShardSize(userID string) int {
 	return o.getOverridesForUser(userID).RulerTenantShardSize
@@ -578,6 +1035,16 @@ func (o *Overrides) StoreGatewayTenantShardSize(userID string) int {
 	return o.getOverridesForUser(userID).StoreGatewayTenantShardSize
 }
 
+// HADeduplicationMode returns how the samples of HA replicas are deduplicated for a user.
+func (o *Overrides) HADeduplicationMode(userID string) string {
+	return o.getOverridesForUser(userID).HADeduplicationMode
+}
+
+// HADeduplicationFailoverTimeout returns the timeout after which the ingester fails over to the samples of another HA replica of a series.
+func (o *Overrides) HADeduplicationFailoverTimeout(userID string) time.Duration {
+	return time.Duration(o.getOverridesForUser(userID).HADeduplicationFailoverTimeout)
+}
+
 // MaxHAClusters returns maximum number of clusters that HA tracker will track for a user.
 func (o *Overrides) MaxHAClusters(user string) int {
 	return o.getOverridesForUser(user).HAMaxClusters
@@ -12,7 +12,9 @@ import (
 	"testing"
 	"time"
 
+	"github.com/grafana/dskit/flagext"
 	"github.com/prometheus/common/model"
+	"github.com/prometheus/prometheus/model/labels"
 	"github.com/prometheus/prometheus/model/relabel"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -188,6 +190,274 @@ metric_relabel_configs:
 	assert.Equal(t, []*relabel.Config{&exp}, l.MetricRelabelConfigs)
 }
 
+func TestBlockedQueriesLimitsLoadingFromYaml(t *testing.T) {
+	SetDefaultLimitsForYAMLUnmarshalling(Limits{})
+
+	t.Run("valid blocked queries", func(t *testing.T) {
+		inp := `
+blocked_queries:
+- pattern: sum(rate(up[1m]))
+- pattern: .*expensive_metric.*
+  regex: true
+`
+		l := Limits{}
+		require.NoError(t, yaml.UnmarshalStrict([]byte(inp), &l))
+		require.Len(t, l.BlockedQueries, 2)
+		assert.Equal(t, "sum(rate(up[1m]))", l.BlockedQueries[0].Pattern)
+		assert.False(t, l.BlockedQueries[0].Regex)
+		assert.Equal(t, ".*expensive_metric.*", l.BlockedQueries[1].Pattern)
+		assert.True(t, l.BlockedQueries[1].Regex)
+
+		// The patterns are compiled when the limits are loaded.
+		assert.True(t, l.BlockedQueries[0].Matches("sum(rate(up[1m]))", "sum(rate(up[1m]))"))
+		assert.True(t, l.BlockedQueries[1].Matches("sum(expensive_metric)", ""))
+	})
+
+	t.Run("valid blocked queries from json", func(t *testing.T) {
+		l := Limits{}
+		require.NoError(t, json.Unmarshal([]byte(`{"blocked_queries": [{"pattern": "up"}, {"pattern": ".*down.*", "regex": true}]}`), &l))
+		require.Len(t, l.BlockedQueries, 2)
+		assert.True(t, l.BlockedQueries[0].Matches("up", "up"))
+		assert.True(t, l.BlockedQueries[1].Matches("sum(down)", "sum(down)"))
+	})
+
+	t.Run("invalid blocked query regex", func(t *testing.T) {
+		inp := `
+blocked_queries:
+- pattern: (up
+  regex: true
+`
+		l := Limits{}
+		err := yaml.UnmarshalStrict([]byte(inp), &l)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "invalid blocked query regex")
+	})
+
+	t.Run("invalid blocked query expression", func(t *testing.T) {
+		inp := `
+blocked_queries:
+- pattern: sum(up
+`
+		l := Limits{}
+		err := yaml.UnmarshalStrict([]byte(inp), &l)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "invalid blocked query")
+	})
+}
+
+func TestForwardingRulesLimitsLoadingFromYaml(t *testing.T) {
+	SetDefaultLimitsForYAMLUnmarshalling(Limits{})
+
+	t.Run("valid forwarding rules", func(t *testing.T) {
+		inp := `
+forwarding_rules:
+  metric1:
+    endpoint: http://remote1/
+  '{team="payments"}':
+    ingest: true
+    endpoints:
+    - url: http://remote2/
+      headers:
+        X-Scope-OrgID: payments
+      basic_auth:
+        username: user
+        password: pass
+      write_relabel_configs:
+      - regex: internal_.*
+        action: labeldrop
+`
+		l := Limits{}
+		require.NoError(t, yaml.UnmarshalStrict([]byte(inp), &l))
+		require.Len(t, l.ForwardingRules, 2)
+		assert.Equal(t, "http://remote1/", l.ForwardingRules["metric1"].Endpoint)
+		assert.Equal(t, []*labels.Matcher{labels.MustNewMatcher(labels.MatchEqual, model.MetricNameLabel, "metric1")}, l.ForwardingRules["metric1"].Matchers())
+
+		rule := l.ForwardingRules[`{team="payments"}`]
+		assert.True(t, rule.Ingest)
+		assert.Equal(t, []*labels.Matcher{labels.MustNewMatcher(labels.MatchEqual, "team", "payments")}, rule.Matchers())
+		require.Len(t, rule.Endpoints, 1)
+		assert.Equal(t, "http://remote2/", rule.Endpoints[0].URL)
+		assert.Equal(t, map[string]string{"X-Scope-OrgID": "payments"}, rule.Endpoints[0].Headers)
+		assert.Equal(t, ForwardingBasicAuth{Username: "user", Password: NewSecret("pass")}, rule.Endpoints[0].BasicAuth)
+		require.Len(t, rule.Endpoints[0].WriteRelabelConfigs, 1)
+		assert.Equal(t, relabel.LabelDrop, rule.Endpoints[0].WriteRelabelConfigs[0].Action)
+	})
+
+	for name, inp := range map[string]string{
+		"invalid selector": `
+forwarding_rules:
+  '{team=payments}':
+    endpoint: http://remote/
+`,
+		"endpoint without URL": `
+forwarding_rules:
+  metric1:
+    endpoints:
+    - headers:
+        X-Scope-OrgID: payments
+`,
+		"endpoint with both basic auth and bearer token": `
+forwarding_rules:
+  metric1:
+    endpoints:
+    - url: http://remote/
+      basic_auth:
+        username: user
+      bearer_token: token
+`,
+	} {
+		t.Run(name, func(t *testing.T) {
+			l := Limits{}
+			err := yaml.UnmarshalStrict([]byte(inp), &l)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), "forwarding rule")
+		})
+	}
+}
+
+func TestForwardingRulesLimitsLoadingFromJson(t *testing.T) {
+	SetDefaultLimitsForYAMLUnmarshalling(Limits{})
+
+	inp := `{"forwarding_rules": {"{team=\"payments\"}": {"endpoints": [{"url": "http://remote/", "write_relabel_configs": [{"regex": "internal_.*", "action": "labeldrop"}]}]}}}`
+
+	l := Limits{}
+	require.NoError(t, json.Unmarshal([]byte(inp), &l))
+	rule := l.ForwardingRules[`{team="payments"}`]
+	assert.Equal(t, []*labels.Matcher{labels.MustNewMatcher(labels.MatchEqual, "team", "payments")}, rule.Matchers())
+	require.Len(t, rule.Endpoints, 1)
+	require.Len(t, rule.Endpoints[0].WriteRelabelConfigs, 1)
+	assert.Equal(t, relabel.LabelDrop, rule.Endpoints[0].WriteRelabelConfigs[0].Action)
+	assert.Equal(t, "internal_.*", rule.Endpoints[0].WriteRelabelConfigs[0].Regex.String())
+
+	err := json.Unmarshal([]byte(`{"forwarding_rules": {"{team=payments}": {"endpoint": "http://remote/"}}}`), &l)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid forwarding rule selector")
+}
+
+func TestForwardingEndpointCredentialsAreMasked(t *testing.T) {
+	endpoint := ForwardingEndpoint{
+		URL:         "http://remote/",
+		BasicAuth:   ForwardingBasicAuth{Username: "user", Password: NewSecret("secret-pass")},
+		BearerToken: NewSecret("secret-token"),
+	}
+
+	out, err := yaml.Marshal(endpoint)
+	require.NoError(t, err)
+	assert.NotContains(t, string(out), "secret-")
+	assert.Equal(t, 2, strings.Count(string(out), "********"))
+
+	out, err = json.Marshal(endpoint)
+	require.NoError(t, err)
+	assert.NotContains(t, string(out), "secret-")
+	assert.Equal(t, 2, strings.Count(string(out), "********"))
+
+	var unmarshalled ForwardingEndpoint
+	require.NoError(t, json.Unmarshal([]byte(`{"url": "http://remote/", "basic_auth": {"username": "user", "password": "secret-pass"}, "bearer_token": "secret-token"}`), &unmarshalled))
+	assert.Equal(t, endpoint, unmarshalled)
+}
+
+func TestGraphiteMappingRulesLimitsLoadingFromYaml(t *testing.T) {
+	SetDefaultLimitsForYAMLUnmarshalling(Limits{})
+
+	t.Run("valid rules", func(t *testing.T) {
+		inp := `
+graphite_mapping_rules:
+- match: servers.*.cpu.*
+  name: server_cpu_${2}_seconds
+  labels:
+    host: $1
+`
+		l := Limits{}
+		require.NoError(t, yaml.UnmarshalStrict([]byte(inp), &l))
+		require.Len(t, l.GraphiteMappingRules, 1)
+		assert.Equal(t, "servers.*.cpu.*", l.GraphiteMappingRules[0].Match)
+		assert.Equal(t, "server_cpu_${2}_seconds", l.GraphiteMappingRules[0].Name)
+		assert.Equal(t, map[string]string{"host": "$1"}, l.GraphiteMappingRules[0].Labels)
+
+		// The glob pattern is compiled when the limits are loaded.
+		require.NotNil(t, l.GraphiteMappingRules[0].MatchRegexp())
+		assert.Equal(t, []string{"servers.web-1.cpu.user", "web-1", "user"}, l.GraphiteMappingRules[0].MatchRegexp().FindStringSubmatch("servers.web-1.cpu.user"))
+	})
+
+	t.Run("valid rules from json", func(t *testing.T) {
+		l := Limits{}
+		require.NoError(t, json.Unmarshal([]byte(`{"graphite_mapping_rules": [{"match": "apps.*.requests", "name": "app_requests_total"}]}`), &l))
+		require.Len(t, l.GraphiteMappingRules, 1)
+		require.NotNil(t, l.GraphiteMappingRules[0].MatchRegexp())
+		assert.True(t, l.GraphiteMappingRules[0].MatchRegexp().MatchString("apps.checkout.requests"))
+	})
+
+	for name, inp := range map[string]string{
+		"missing name": `
+graphite_mapping_rules:
+- match: servers.*.cpu
+`,
+		"invalid label name": `
+graphite_mapping_rules:
+- match: servers.*.cpu
+  name: server_cpu
+  labels:
+    host-name: $1
+`,
+	} {
+		t.Run(name, func(t *testing.T) {
+			l := Limits{}
+			err := yaml.UnmarshalStrict([]byte(inp), &l)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), "graphite mapping rule")
+		})
+	}
+}
+
+func TestLabelPoliciesLimitsLoadingFromYaml(t *testing.T) {
+	SetDefaultLimitsForYAMLUnmarshalling(Limits{})
+
+	t.Run("valid policies", func(t *testing.T) {
+		inp := `
+required_labels: [team, env]
+default_labels:
+  env: prod
+allowed_label_values:
+  env: [prod, staging]
+`
+		l := Limits{}
+		require.NoError(t, yaml.UnmarshalStrict([]byte(inp), &l))
+		assert.Equal(t, flagext.StringSlice{"team", "env"}, l.RequiredLabels)
+		assert.Equal(t, map[string]string{"env": "prod"}, l.DefaultLabels)
+		assert.Equal(t, map[string][]string{"env": {"prod", "staging"}}, l.AllowedLabelValues)
+	})
+
+	for name, inp := range map[string]string{
+		"invalid required label name": `
+required_labels: [team-name]
+`,
+		"empty default label value": `
+default_labels:
+  env: ""
+`,
+		"empty allowed label values": `
+allowed_label_values:
+  env: []
+`,
+	} {
+		t.Run(name, func(t *testing.T) {
+			l := Limits{}
+			require.Error(t, yaml.UnmarshalStrict([]byte(inp), &l))
+		})
+	}
+}
+
+func TestHADeduplicationModeLoadingFromYaml(t *testing.T) {
+	SetDefaultLimitsForYAMLUnmarshalling(Limits{})
+
+	l := Limits{}
+	require.NoError(t, yaml.UnmarshalStrict([]byte("ha_deduplication_mode: ingester"), &l))
+	assert.Equal(t, HADeduplicationModeIngester, l.HADeduplicationMode)
+
+	l = Limits{}
+	require.EqualError(t, yaml.UnmarshalStrict([]byte("ha_deduplication_mode: querier"), &l), `unsupported HA deduplication mode "querier", supported values are: distributor, ingester`)
+}
+
 func TestSmallestPositiveIntPerTenant(t *testing.T) {
 	tenantLimits := map[string]*Limits{
 		"tenant-a": {
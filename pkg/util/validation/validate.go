@@ -40,6 +40,8 @@ var (
 	reasonDuplicateLabelNames    = metricReasonFromErrorID(globalerror.SeriesWithDuplicateLabelNames)
 	reasonLabelsNotSorted        = metricReasonFromErrorID(globalerror.SeriesLabelsNotSorted)
 	reasonTooFarInFuture         = metricReasonFromErrorID(globalerror.SampleTooFarInFuture)
+	reasonMissingRequiredLabel   = metricReasonFromErrorID(globalerror.SeriesMissingRequiredLabel)
+	reasonLabelValueNotAllowed   = metricReasonFromErrorID(globalerror.SeriesLabelValueNotAllowed)
 
 	// Discarded exemplars reasons.
 	reasonExemplarLabelsMissing    = metricReasonFromErrorID(globalerror.ExemplarLabelsMissing)
@@ -57,6 +59,9 @@ var (
 	// Declared here to avoid duplication in ingester and distributor.
 	ReasonRateLimited = "rate_limited" // same for request and ingestion which are separate errors, so not using metricReasonFromErrorID with global error
 
+	// ReasonBytesRateLimited is one of the reasons for discarding samples, when the ingestion bytes rate limit is exceeded.
+	ReasonBytesRateLimited = "bytes_rate_limited"
+
 	// ReasonTooManyHAClusters is one of the reasons for discarding samples.
 	ReasonTooManyHAClusters = "too_many_ha_clusters"
 )
@@ -240,6 +245,46 @@ func ValidateLabels(cfg LabelValidationConfig, userID string, ls []mimirpb.Label
 	return nil
 }
 
+// LabelPolicyConfig helps with getting required config to enforce the label policies.
+type LabelPolicyConfig interface {
+	RequiredLabels(userID string) []string
+	AllowedLabelValues(userID string) map[string][]string
+}
+
+// ValidateLabelPolicies returns an err if the labels don't have all the required labels,
+// or have a label value which is not allowed.
+// The returned error may retain the provided series labels.
+func ValidateLabelPolicies(cfg LabelPolicyConfig, userID string, ls []mimirpb.LabelAdapter) ValidationError {
+	for _, name := range cfg.RequiredLabels(userID) {
+		if !hasLabel(ls, name) {
+			DiscardedSamples.WithLabelValues(reasonMissingRequiredLabel, userID).Inc()
+			return newMissingRequiredLabelError(ls, name)
+		}
+	}
+
+	allowedValues := cfg.AllowedLabelValues(userID)
+	if len(allowedValues) == 0 {
+		return nil
+	}
+	for _, l := range ls {
+		if values, ok := allowedValues[l.Name]; ok && !util.StringsContain(values, l.Value) {
+			DiscardedSamples.WithLabelValues(reasonLabelValueNotAllowed, userID).Inc()
+			return newLabelValueNotAllowedError(ls, l.Name, l.Value)
+		}
+	}
+	return nil
+}
+
+// hasLabel returns whether the labels have the given label with a non-empty value.
+func hasLabel(ls []mimirpb.LabelAdapter, name string) bool {
+	for _, l := range ls {
+		if l.Name == name {
+			return l.Value != ""
+		}
+	}
+	return false
+}
+
 // MetadataValidationConfig helps with getting required config to validate metadata.
 type MetadataValidationConfig interface {
 	EnforceMetadataMetricName(userID string) bool
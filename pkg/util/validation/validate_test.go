@@ -333,3 +333,65 @@ func TestValidateLabelDuplication(t *testing.T) {
 	}, "a")
 	assert.Equal(t, expected, actual)
 }
+
+type validateLabelPoliciesCfg struct {
+	requiredLabels     []string
+	allowedLabelValues map[string][]string
+}
+
+func (v validateLabelPoliciesCfg) RequiredLabels(userID string) []string {
+	return v.requiredLabels
+}
+
+func (v validateLabelPoliciesCfg) AllowedLabelValues(userID string) map[string][]string {
+	return v.allowedLabelValues
+}
+
+func TestValidateLabelPolicies(t *testing.T) {
+	cfg := validateLabelPoliciesCfg{
+		requiredLabels:     []string{"team", "env"},
+		allowedLabelValues: map[string][]string{"env": {"prod", "staging"}},
+	}
+	userID := "labelPoliciesUser"
+
+	for _, c := range []struct {
+		metric   model.Metric
+		expected error
+	}{
+		{
+			map[model.LabelName]model.LabelValue{model.MetricNameLabel: "valid", "team": "a", "env": "prod"},
+			nil,
+		},
+		{
+			map[model.LabelName]model.LabelValue{model.MetricNameLabel: "missing", "env": "prod"},
+			newMissingRequiredLabelError([]mimirpb.LabelAdapter{
+				{Name: model.MetricNameLabel, Value: "missing"},
+				{Name: "env", Value: "prod"},
+			}, "team"),
+		},
+		{
+			map[model.LabelName]model.LabelValue{model.MetricNameLabel: "empty", "team": "", "env": "prod"},
+			newMissingRequiredLabelError([]mimirpb.LabelAdapter{
+				{Name: model.MetricNameLabel, Value: "empty"},
+				{Name: "env", Value: "prod"},
+				{Name: "team", Value: ""},
+			}, "team"),
+		},
+		{
+			map[model.LabelName]model.LabelValue{model.MetricNameLabel: "not_allowed", "team": "a", "env": "qa"},
+			newLabelValueNotAllowedError([]mimirpb.LabelAdapter{
+				{Name: model.MetricNameLabel, Value: "not_allowed"},
+				{Name: "env", Value: "qa"},
+				{Name: "team", Value: "a"},
+			}, "env", "qa"),
+		},
+	} {
+		err := ValidateLabelPolicies(cfg, userID, mimirpb.FromMetricsToLabelAdapters(c.metric))
+		assert.Equal(t, c.expected, err, "wrong error")
+	}
+
+	assert.Equal(t, float64(2), testutil.ToFloat64(DiscardedSamples.WithLabelValues(reasonMissingRequiredLabel, userID)))
+	assert.Equal(t, float64(1), testutil.ToFloat64(DiscardedSamples.WithLabelValues(reasonLabelValueNotAllowed, userID)))
+
+	DeletePerUserValidationMetrics(userID, util_log.Logger)
+}
@@ -459,6 +459,8 @@ func ReflectType(typ string) reflect.Type {
 		return reflect.TypeOf([]*relabel.Config{})
 	case "map of string to float64":
 		return reflect.TypeOf(map[string]float64{})
+	case "map of string to []string":
+		return reflect.TypeOf(map[string][]string{})
 	case "list of duration":
 		return reflect.TypeOf(tsdb.DurationList{})
 	case "map of string to validation.ForwardingRule":
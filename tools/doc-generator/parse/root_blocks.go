@@ -149,6 +149,11 @@ var (
 			StructType: reflect.TypeOf(cache.MemcachedConfig{}),
 			Desc:       "The memcached block configures the Memcached-based caching backend.",
 		},
+		{
+			Name:       "redis",
+			StructType: reflect.TypeOf(cache.RedisConfig{}),
+			Desc:       "The redis block configures the Redis-based caching backend.",
+		},
 		{
 			Name:       "s3_storage_backend",
 			StructType: reflect.TypeOf(s3.Config{}),